@@ -0,0 +1,120 @@
+// api/audit/chain.go
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+)
+
+// ChainHeadStore keeps the head of the audit hash chain, the latest log written to it
+type ChainHeadStore interface {
+	GetHead(ctx context.Context) (*model.AuditChainHead, error) // nil before the first log
+	SetHead(ctx context.Context, head model.AuditChainHead) error
+}
+
+// RedisChainHeadStore keeps the chain head in Redis, where every instance finds it
+type RedisChainHeadStore struct{}
+
+func (RedisChainHeadStore) GetHead(ctx context.Context) (*model.AuditChainHead, error) {
+	return db.GetAuditChainHead(ctx)
+}
+
+func (RedisChainHeadStore) SetHead(ctx context.Context, head model.AuditChainHead) error {
+	return db.SetAuditChainHead(ctx, head)
+}
+
+// ComputeHash returns the SHA-256 of the log's content, its sequence and PrevHash included and its
+// Hash left out, so that changing any of them, or linking the log elsewhere, changes the hash
+func ComputeHash(log AuditLog) (string, error) {
+	log.Hash = ""
+	log.Timestamp = log.Timestamp.UTC()
+	content, err := json.Marshal(log)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit log: %w", err)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// link makes log the next one of the chain after head, which is nil for the first log
+func link(log AuditLog, head *model.AuditChainHead) (AuditLog, error) {
+	log.Timestamp = log.Timestamp.UTC()
+	log.Sequence, log.PrevHash = 1, ""
+	if head != nil {
+		log.Sequence, log.PrevHash = head.Sequence+1, head.Hash
+	}
+	hash, err := ComputeHash(log)
+	if err != nil {
+		return AuditLog{}, err
+	}
+	log.Hash = hash
+	return log, nil
+}
+
+// verifyChain checks the chained logs among logs: each must hash to its Hash and link to the log of
+// the sequence before it, which must be there unless it is the first of the range. The head, when
+// given and written within the range, must be there as recorded, so logs cut off the end of the chain
+// are noticed too. Logs written before the chain began carry no sequence and are skipped; those written
+// unchained while the head was unreadable are counted.
+func verifyChain(logs []AuditLog, head *model.AuditChainHead, from, to time.Time) *ChainVerification {
+	verification := &ChainVerification{From: from, To: to, Breaks: []ChainBreak{}}
+
+	var chained []AuditLog
+	for _, log := range logs {
+		switch {
+		case log.Sequence > 0:
+			chained = append(chained, log)
+		case log.Unchained:
+			verification.Unchained++
+		}
+	}
+	sort.SliceStable(chained, func(i, j int) bool { return chained[i].Sequence < chained[j].Sequence })
+
+	fail := func(sequence int64, format string, args ...any) {
+		verification.Breaks = append(verification.Breaks, ChainBreak{Sequence: sequence, Reason: fmt.Sprintf(format, args...)})
+	}
+	for i, log := range chained {
+		verification.Verified++
+		if hash, err := ComputeHash(log); err != nil || hash != log.Hash {
+			fail(log.Sequence, "content does not match its hash")
+		}
+		if i == 0 {
+			continue
+		}
+		previous := chained[i-1]
+		switch {
+		case log.Sequence == previous.Sequence:
+			fail(log.Sequence, "sequence is used by more than one log")
+		case log.Sequence != previous.Sequence+1:
+			fail(log.Sequence, "logs %d to %d are missing", previous.Sequence+1, log.Sequence-1)
+		case log.PrevHash != previous.Hash:
+			fail(log.Sequence, "does not link to log %d", previous.Sequence)
+		}
+	}
+
+	if head != nil && !head.Timestamp.Before(from) && !head.Timestamp.After(to) {
+		found := false
+		for _, log := range chained {
+			if log.Sequence == head.Sequence {
+				found = true
+				if log.Hash != head.Hash {
+					fail(log.Sequence, "differs from the chain head")
+				}
+			}
+		}
+		if !found {
+			fail(head.Sequence, "the chain head is missing")
+		}
+	}
+
+	verification.Valid = len(verification.Breaks) == 0
+	return verification
+}
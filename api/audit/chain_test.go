@@ -0,0 +1,238 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestAuditHashChain(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	mock.StartRedisServer(t)
+	ctx := context.Background()
+	from := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	// Logs are written at once, so the service has to serialize them to keep the chain whole
+	var (
+		mu      sync.Mutex
+		written []audit.AuditLog
+	)
+	writer := &mock.MockAuditRepository{}
+	writer.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Run(func(args testify_mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		written = append(written, args.Get(1).(audit.AuditLog))
+	}).Return(nil)
+	auditService := audit.NewService(writer, audit.RedisChainHeadStore{})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, auditService.LogAccess(ctx, audit.AuditLog{
+				Timestamp: from.Add(time.Duration(i) * time.Hour), UserID: "alice", Action: "UPDATE_POLICY", ResourceID: "p1",
+			}))
+		}(i)
+	}
+	wg.Wait()
+	require.Len(t, written, 5)
+	for i, log := range written {
+		assert.Equal(t, int64(i+1), log.Sequence)
+		if i > 0 {
+			assert.Equal(t, written[i-1].Hash, log.PrevHash)
+		}
+	}
+
+	// verify checks the chain over the logs tamper leaves of the written ones
+	verify := func(t *testing.T, tamper func(logs []audit.AuditLog) []audit.AuditLog) *audit.ChainVerification {
+		logs := tamper(append([]audit.AuditLog(nil), written...))
+		reader := &mock.MockAuditRepository{}
+		reader.On("StreamLogs", testify_mock.Anything, from, to, "", "").Return(logs, nil)
+		verification, err := audit.NewService(reader, audit.RedisChainHeadStore{}).VerifyChain(ctx, from, to)
+		require.NoError(t, err)
+		return verification
+	}
+
+	t.Run("An intact chain verifies", func(t *testing.T) {
+		verification := verify(t, func(logs []audit.AuditLog) []audit.AuditLog { return logs })
+
+		assert.True(t, verification.Valid)
+		assert.Equal(t, 5, verification.Verified)
+		assert.Empty(t, verification.Breaks)
+	})
+
+	t.Run("Logs written before the chain are skipped", func(t *testing.T) {
+		verification := verify(t, func(logs []audit.AuditLog) []audit.AuditLog {
+			return append([]audit.AuditLog{{Timestamp: from, UserID: "bob", Action: "CREATE_POLICY"}}, logs...)
+		})
+
+		assert.True(t, verification.Valid)
+		assert.Equal(t, 5, verification.Verified)
+	})
+
+	tests := []struct {
+		name   string
+		tamper func(logs []audit.AuditLog) []audit.AuditLog
+		broken audit.ChainBreak
+	}{
+		{"An altered log breaks verification", func(logs []audit.AuditLog) []audit.AuditLog {
+			logs[2].Action = "DELETE_POLICY"
+			return logs
+		}, audit.ChainBreak{Sequence: 3, Reason: "content does not match its hash"}},
+		{"A rehashed log no longer links", func(logs []audit.AuditLog) []audit.AuditLog {
+			logs[2].UserID = "mallory"
+			logs[2].Hash, _ = audit.ComputeHash(logs[2])
+			return logs
+		}, audit.ChainBreak{Sequence: 4, Reason: "does not link to log 3"}},
+		{"A removed log leaves a gap", func(logs []audit.AuditLog) []audit.AuditLog {
+			return append(logs[:2], logs[3:]...)
+		}, audit.ChainBreak{Sequence: 4, Reason: "logs 3 to 3 are missing"}},
+		{"A log cut off the end is missed", func(logs []audit.AuditLog) []audit.AuditLog {
+			return logs[:4]
+		}, audit.ChainBreak{Sequence: 5, Reason: "the chain head is missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification := verify(t, tt.tamper)
+
+			assert.False(t, verification.Valid)
+			assert.Equal(t, []audit.ChainBreak{tt.broken}, verification.Breaks)
+		})
+	}
+}
+
+// failingHeads is a chain head store that fails to read the head, or to move it, on demand
+type failingHeads struct {
+	audit.RedisChainHeadStore
+	getErr, setErr error
+}
+
+func (h *failingHeads) GetHead(ctx context.Context) (*model.AuditChainHead, error) {
+	if h.getErr != nil {
+		return nil, h.getErr
+	}
+	return h.RedisChainHeadStore.GetHead(ctx)
+}
+
+func (h *failingHeads) SetHead(ctx context.Context, head model.AuditChainHead) error {
+	if h.setErr != nil {
+		return h.setErr
+	}
+	return h.RedisChainHeadStore.SetHead(ctx, head)
+}
+
+func TestAuditChainFailures(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	from := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	// record returns a repository keeping the logs written to it in written
+	record := func(written *[]audit.AuditLog) *mock.MockAuditRepository {
+		repo := &mock.MockAuditRepository{}
+		repo.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Run(func(args testify_mock.Arguments) {
+			*written = append(*written, args.Get(1).(audit.AuditLog))
+		}).Return(nil)
+		return repo
+	}
+
+	t.Run("Logs are written unchained while the head is unreadable", func(t *testing.T) {
+		mock.StartRedisServer(t)
+		var written []audit.AuditLog
+		heads := &failingHeads{getErr: errors.New("redis unavailable")}
+
+		err := audit.NewService(record(&written), heads).LogAccess(ctx, audit.AuditLog{Timestamp: from, UserID: "alice", Action: "UPDATE_POLICY"})
+
+		assert.NoError(t, err)
+		require.Len(t, written, 1)
+		assert.True(t, written[0].Unchained)
+		assert.Zero(t, written[0].Sequence)
+		assert.Empty(t, written[0].Hash)
+
+		reader := &mock.MockAuditRepository{}
+		reader.On("StreamLogs", testify_mock.Anything, from, to, "", "").Return(written, nil)
+		verification, err := audit.NewService(reader, audit.RedisChainHeadStore{}).VerifyChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.True(t, verification.Valid)
+		assert.Equal(t, 1, verification.Unchained)
+		assert.Zero(t, verification.Verified)
+	})
+
+	t.Run("A head not moved leaves two logs on one sequence", func(t *testing.T) {
+		mock.StartRedisServer(t)
+		var written []audit.AuditLog
+		heads := &failingHeads{setErr: errors.New("redis unavailable")}
+		service := audit.NewService(record(&written), heads)
+
+		assert.Error(t, service.LogAccess(ctx, audit.AuditLog{Timestamp: from, UserID: "alice", Action: "UPDATE_POLICY"}))
+		heads.setErr = nil
+		assert.NoError(t, service.LogAccess(ctx, audit.AuditLog{Timestamp: from.Add(time.Hour), UserID: "bob", Action: "DELETE_POLICY"}))
+
+		require.Len(t, written, 2)
+		assert.Equal(t, written[0].Sequence, written[1].Sequence)
+		reader := &mock.MockAuditRepository{}
+		reader.On("StreamLogs", testify_mock.Anything, from, to, "", "").Return(written, nil)
+		verification, err := audit.NewService(reader, audit.RedisChainHeadStore{}).VerifyChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.False(t, verification.Valid)
+		assert.Contains(t, verification.Breaks, audit.ChainBreak{Sequence: 1, Reason: "sequence is used by more than one log"})
+	})
+
+	t.Run("Elasticsearch documents are created, never overwritten", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			requests []*http.Request
+			stored   = map[string]bool{}
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			requests = append(requests, r)
+			w.Header().Set("X-Elastic-Product", "Elasticsearch")
+			w.Header().Set("Content-Type", "application/json")
+			if stored[r.URL.Path] {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"error":{"type":"version_conflict_engine_exception"},"status":409}`))
+				return
+			}
+			stored[r.URL.Path] = true
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"result":"created"}`))
+		}))
+		defer server.Close()
+		repo, err := audit.NewElasticsearchRepository(server.URL)
+		require.NoError(t, err)
+
+		first := audit.AuditLog{Timestamp: from, UserID: "alice", Sequence: 7, Hash: "aaaa"}
+		forked := audit.AuditLog{Timestamp: from, UserID: "bob", Sequence: 7, Hash: "bbbb"}
+
+		assert.NoError(t, repo.LogAccess(ctx, first))
+		assert.NoError(t, repo.LogAccess(ctx, forked))
+		assert.ErrorIs(t, repo.LogAccess(ctx, first), audit.ErrLogExists)
+
+		require.Len(t, requests, 3)
+		assert.Equal(t, "/audit-2024.06/_doc/7-aaaa", requests[0].URL.Path)
+		assert.Equal(t, "/audit-2024.06/_doc/7-bbbb", requests[1].URL.Path)
+		for _, request := range requests {
+			assert.Equal(t, "create", request.URL.Query().Get("op_type"))
+		}
+	})
+}
@@ -14,4 +14,29 @@ type AuditLog struct {
 	AccessGranted bool            `json:"access_granted"`
 	PolicyID      string          `json:"policy_id"`
 	ChangeDetails json.RawMessage `json:"change_details,omitempty"`
+
+	// Hash chain, set as the log is written: each log links to the one written before it
+	Sequence int64  `json:"sequence,omitempty"`  // Position in the chain, from 1
+	PrevHash string `json:"prev_hash,omitempty"` // Hash of the log before it; empty for the first
+	Hash     string `json:"hash,omitempty"`      // Hash of this log's content, PrevHash included
+
+	// Unchained is set on logs written while the chain head could not be read. They are kept rather
+	// than lost, but no hash protects them.
+	Unchained bool `json:"unchained,omitempty"`
+}
+
+// ChainVerification is the outcome of checking the hash chain over a time range
+type ChainVerification struct {
+	From      time.Time    `json:"from"`
+	To        time.Time    `json:"to"`
+	Verified  int          `json:"verified"`  // Chained logs checked
+	Unchained int          `json:"unchained"` // Logs of the range written outside the chain, which nothing verifies
+	Valid     bool         `json:"valid"`     // No log was altered or found missing
+	Breaks    []ChainBreak `json:"breaks"`
+}
+
+// ChainBreak is a place the hash chain does not hold
+type ChainBreak struct {
+	Sequence int64  `json:"sequence"` // Of the altered log, or of the first log after a gap
+	Reason   string `json:"reason"`
 }
@@ -4,7 +4,10 @@ package audit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,11 +15,23 @@ import (
 	"github.com/elastic/go-elasticsearch/v8/esapi"
 )
 
+// ErrLogExists is returned when an audit log is written to a document that already holds one
+var ErrLogExists = errors.New("audit log already exists")
+
 type Repository interface {
 	LogAccess(ctx context.Context, log AuditLog) error
 	QueryLogs(ctx context.Context, from, to time.Time, userID, resourceID string) ([]AuditLog, error)
+	StreamLogs(ctx context.Context, from, to time.Time, userID, resourceID string, visit func(AuditLog) error) error
+	ListIndices(ctx context.Context) ([]string, error)
+	DeleteIndex(ctx context.Context, index string) error
 }
 
+// streamBatchSize is how many audit logs StreamLogs fetches per round trip
+const streamBatchSize = 500
+
+// streamScrollTTL is how long Elasticsearch keeps a StreamLogs scroll alive between batches
+const streamScrollTTL = time.Minute
+
 type ElasticsearchRepository struct {
 	esClient *elasticsearch.Client
 }
@@ -33,16 +48,19 @@ func NewElasticsearchRepository(esURL string) (*ElasticsearchRepository, error)
 	return &ElasticsearchRepository{esClient: esClient}, nil
 }
 
-// LogAccess logs an audit action to Elasticsearch.
+// LogAccess logs an audit action to Elasticsearch, in the index of the month it happened in.
 func (r *ElasticsearchRepository) LogAccess(ctx context.Context, log AuditLog) error {
 	data, err := json.Marshal(log)
 	if err != nil {
 		return err
 	}
 
+	// Logs are only ever created, never overwritten, so two logs given the same place in the chain are
+	// both kept for VerifyChain to report
 	req := esapi.IndexRequest{
-		Index:      "audit-logs",
-		DocumentID: fmt.Sprintf("%d-%s", log.Timestamp.Unix(), log.UserID), // Example ID format
+		Index:      IndexName(log.Timestamp),
+		DocumentID: documentID(log),
+		OpType:     "create",
 		Body:       strings.NewReader(string(data)),
 		Refresh:    "true",
 	}
@@ -53,6 +71,9 @@ func (r *ElasticsearchRepository) LogAccess(ctx context.Context, log AuditLog) e
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: %s", ErrLogExists, req.DocumentID)
+	}
 	if res.IsError() {
 		return fmt.Errorf("error indexing document: %s", res.String())
 	}
@@ -60,6 +81,15 @@ func (r *ElasticsearchRepository) LogAccess(ctx context.Context, log AuditLog) e
 	return nil
 }
 
+// documentID identifies a chained log by its place in the chain and its hash, so logs forked onto the
+// same place each get a document of their own. Unchained logs are left for Elasticsearch to name.
+func documentID(log AuditLog) string {
+	if log.Sequence > 0 {
+		return strconv.FormatInt(log.Sequence, 10) + "-" + log.Hash
+	}
+	return ""
+}
+
 // QueryLogs searches for audit logs in Elasticsearch within a specific time frame and optionally filters by userID and resourceID.
 func (r *ElasticsearchRepository) QueryLogs(ctx context.Context, from, to time.Time, userID, resourceID string) ([]AuditLog, error) {
 	var buf strings.Builder
@@ -102,7 +132,7 @@ func (r *ElasticsearchRepository) QueryLogs(ctx context.Context, from, to time.T
 
 	res, err := r.esClient.Search(
 		r.esClient.Search.WithContext(ctx),
-		r.esClient.Search.WithIndex("audit-logs"),
+		r.esClient.Search.WithIndex(indexPattern),
 		r.esClient.Search.WithBody(strings.NewReader(buf.String())),
 		r.esClient.Search.WithPretty(),
 	)
@@ -131,3 +161,151 @@ func (r *ElasticsearchRepository) QueryLogs(ctx context.Context, from, to time.T
 
 	return logs, nil
 }
+
+// StreamLogs visits the audit logs QueryLogs would match, oldest first, fetching them in batches with
+// the scroll API so that exports never hold more than one batch in memory. It stops at the first error
+// visit returns.
+func (r *ElasticsearchRepository) StreamLogs(ctx context.Context, from, to time.Time, userID, resourceID string, visit func(AuditLog) error) error {
+	must := []interface{}{
+		map[string]interface{}{
+			"range": map[string]interface{}{
+				"timestamp": map[string]interface{}{
+					"gte": from.Format(time.RFC3339),
+					"lte": to.Format(time.RFC3339),
+				},
+			},
+		},
+	}
+	if userID != "" {
+		must = append(must, map[string]interface{}{"match": map[string]interface{}{"user_id": userID}})
+	}
+	if resourceID != "" {
+		must = append(must, map[string]interface{}{"match": map[string]interface{}{"resource_id": resourceID}})
+	}
+	query := map[string]interface{}{
+		"query": map[string]interface{}{"bool": map[string]interface{}{"must": must}},
+		"sort":  []interface{}{map[string]interface{}{"timestamp": "asc"}},
+	}
+
+	var buf strings.Builder
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return err
+	}
+
+	res, err := r.esClient.Search(
+		r.esClient.Search.WithContext(ctx),
+		r.esClient.Search.WithIndex(indexPattern),
+		r.esClient.Search.WithBody(strings.NewReader(buf.String())),
+		r.esClient.Search.WithSize(streamBatchSize),
+		r.esClient.Search.WithScroll(streamScrollTTL),
+	)
+	var scrollID string
+	defer func() {
+		if scrollID != "" {
+			r.clearScroll(scrollID)
+		}
+	}()
+
+	for {
+		if err != nil {
+			return err
+		}
+		pageScrollID, logs, err := decodeScrollPage(res)
+		if err != nil {
+			return err
+		}
+		scrollID = pageScrollID
+		if len(logs) == 0 {
+			return nil
+		}
+		for _, log := range logs {
+			if err := visit(log); err != nil {
+				return err
+			}
+		}
+
+		res, err = r.esClient.Scroll(
+			r.esClient.Scroll.WithContext(ctx),
+			r.esClient.Scroll.WithScrollID(scrollID),
+			r.esClient.Scroll.WithScroll(streamScrollTTL),
+		)
+	}
+}
+
+// decodeScrollPage reads one page of a scrolled search and closes its body
+func decodeScrollPage(res *esapi.Response) (string, []AuditLog, error) {
+	defer res.Body.Close()
+	if res.IsError() {
+		return "", nil, fmt.Errorf("error searching documents: %s", res.String())
+	}
+
+	var page struct {
+		ScrollID string `json:"_scroll_id"`
+		Hits     struct {
+			Hits []struct {
+				Source AuditLog `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		return "", nil, err
+	}
+
+	logs := make([]AuditLog, len(page.Hits.Hits))
+	for i, hit := range page.Hits.Hits {
+		logs[i] = hit.Source
+	}
+	return page.ScrollID, logs, nil
+}
+
+// clearScroll releases a scroll once StreamLogs is done with it
+func (r *ElasticsearchRepository) clearScroll(scrollID string) {
+	res, err := r.esClient.ClearScroll(r.esClient.ClearScroll.WithScrollID(scrollID))
+	if err == nil {
+		res.Body.Close()
+	}
+}
+
+// ListIndices returns the names of the audit indices
+func (r *ElasticsearchRepository) ListIndices(ctx context.Context) ([]string, error) {
+	res, err := r.esClient.Cat.Indices(
+		r.esClient.Cat.Indices.WithContext(ctx),
+		r.esClient.Cat.Indices.WithIndex(indexPattern),
+		r.esClient.Cat.Indices.WithFormat("json"),
+		r.esClient.Cat.Indices.WithH("index"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("error listing indices: %s", res.String())
+	}
+
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rows); err != nil {
+		return nil, err
+	}
+	indices := make([]string, len(rows))
+	for i, row := range rows {
+		indices[i] = row.Index
+	}
+	return indices, nil
+}
+
+// DeleteIndex drops an audit index along with every log in it
+func (r *ElasticsearchRepository) DeleteIndex(ctx context.Context, index string) error {
+	res, err := r.esClient.Indices.Delete([]string{index}, r.esClient.Indices.Delete.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("error deleting index %s: %s", index, res.String())
+	}
+	return nil
+}
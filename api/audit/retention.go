@@ -0,0 +1,146 @@
+// api/audit/retention.go
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// Audit logs are kept in one index per month, named after it, so that retention drops whole indices
+// rather than deleting logs one by one
+const (
+	indexPrefix      = "audit-"
+	indexMonthLayout = "2006.01"
+	indexPattern     = indexPrefix + "*" // Also matches "audit-logs", where logs went before monthly indices
+)
+
+// IndexName returns the index of the audit logs written at t, such as "audit-2024.06"
+func IndexName(t time.Time) string {
+	return indexPrefix + t.UTC().Format(indexMonthLayout)
+}
+
+// indexMonth returns the month an audit index holds the logs of, and false for indices not named after one
+func indexMonth(index string) (time.Time, bool) {
+	if !strings.HasPrefix(index, indexPrefix) {
+		return time.Time{}, false
+	}
+	month, err := time.Parse(indexMonthLayout, strings.TrimPrefix(index, indexPrefix))
+	return month, err == nil
+}
+
+// RetentionPolicy sets how long audit logs are kept
+type RetentionPolicy struct {
+	Days   int  // Logs older than this many days are dropped; zero keeps them all
+	DryRun bool // Only reports the indices that would be dropped
+}
+
+// ExpiredIndices returns, oldest first, the monthly indices of indices whose every log is older than
+// the retention at now. A month's index expires once its last day has.
+func (p RetentionPolicy) ExpiredIndices(indices []string, now time.Time) []string {
+	if p.Days <= 0 {
+		return nil
+	}
+	cutoff := now.UTC().AddDate(0, 0, -p.Days)
+
+	var expired []string
+	for _, index := range indices {
+		month, ok := indexMonth(index)
+		if ok && !month.AddDate(0, 1, 0).After(cutoff) {
+			expired = append(expired, index)
+		}
+	}
+	sort.Strings(expired)
+	return expired
+}
+
+// Apply drops the expired audit indices of repo and returns them. In a dry run they are returned and
+// logged, but kept.
+func (p RetentionPolicy) Apply(ctx context.Context, repo Repository, now time.Time) ([]string, error) {
+	if p.Days <= 0 {
+		return nil, nil
+	}
+	indices, err := repo.ListIndices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit indices: %w", err)
+	}
+
+	expired := p.ExpiredIndices(indices, now)
+	if p.DryRun {
+		if len(expired) > 0 {
+			logger.Info("Audit retention dry run: indices would be dropped", zap.Strings("indices", expired), zap.Int("retentionDays", p.Days))
+		}
+		return expired, nil
+	}
+
+	for i, index := range expired {
+		if err := repo.DeleteIndex(ctx, index); err != nil {
+			return expired[:i], fmt.Errorf("failed to drop audit index %s: %w", index, err)
+		}
+		logger.Info("Expired audit index dropped", zap.String("index", index), zap.Int("retentionDays", p.Days))
+	}
+	return expired, nil
+}
+
+// retentionInterval is how long StartRetention waits between two applications of its policy, and
+// retentionIntervalChanged tells it the interval was changed while it waits
+var (
+	retentionInterval        atomic.Int64 // time.Duration
+	retentionIntervalChanged = make(chan struct{}, 1)
+)
+
+// SetRetentionInterval changes how often StartRetention applies its policy. A wait under way starts
+// over with the new interval. Non-positive values keep the current interval.
+func SetRetentionInterval(interval time.Duration) {
+	if interval <= 0 || time.Duration(retentionInterval.Swap(int64(interval))) == interval {
+		return
+	}
+	select {
+	case retentionIntervalChanged <- struct{}{}:
+	default:
+	}
+}
+
+// StartRetention applies policy to repo at once and then every interval, until ctx is done. The
+// interval may be changed with SetRetentionInterval while it runs.
+func StartRetention(ctx context.Context, repo Repository, policy RetentionPolicy, interval time.Duration) {
+	if policy.Days <= 0 || interval <= 0 {
+		return
+	}
+	SetRetentionInterval(interval)
+
+	go func() {
+		for {
+			if _, err := policy.Apply(ctx, repo, time.Now()); err != nil {
+				logger.Error("Audit retention failed", zap.Error(err))
+			}
+			if !waitRetentionInterval(ctx) {
+				return
+			}
+		}
+	}()
+}
+
+// waitRetentionInterval waits for the retention interval to pass, starting over whenever it is
+// changed, and reports false if ctx is done first
+func waitRetentionInterval(ctx context.Context) bool {
+	for {
+		timer := time.NewTimer(time.Duration(retentionInterval.Load()))
+		select {
+		case <-timer.C:
+			return true
+		case <-retentionIntervalChanged:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+}
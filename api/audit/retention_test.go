@@ -0,0 +1,109 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	now := time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+	// Thirty days before now is May 16, so May's index still holds logs to keep
+	indices := []string{"audit-2024.06", "audit-logs", "audit-2024.01", "audit-2023.12", "audit-2024.05", "audit-2024.04", "other-2020.01"}
+	expired := []string{"audit-2023.12", "audit-2024.01", "audit-2024.04"}
+
+	t.Run("Old indices are dropped", func(t *testing.T) {
+		repo := &mock.MockAuditRepository{}
+		repo.On("ListIndices", testify_mock.Anything).Return(indices, nil)
+		repo.On("DeleteIndex", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+		dropped, err := audit.RetentionPolicy{Days: 30}.Apply(context.Background(), repo, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expired, dropped)
+		repo.AssertNumberOfCalls(t, "DeleteIndex", 3)
+		for _, index := range expired {
+			repo.AssertCalled(t, "DeleteIndex", testify_mock.Anything, index)
+		}
+	})
+
+	t.Run("A dry run only reports them", func(t *testing.T) {
+		repo := &mock.MockAuditRepository{}
+		repo.On("ListIndices", testify_mock.Anything).Return(indices, nil)
+
+		dropped, err := audit.RetentionPolicy{Days: 30, DryRun: true}.Apply(context.Background(), repo, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expired, dropped)
+		repo.AssertNotCalled(t, "DeleteIndex", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("No retention keeps every index", func(t *testing.T) {
+		repo := &mock.MockAuditRepository{}
+
+		dropped, err := audit.RetentionPolicy{}.Apply(context.Background(), repo, now)
+
+		assert.NoError(t, err)
+		assert.Empty(t, dropped)
+		repo.AssertNotCalled(t, "ListIndices", testify_mock.Anything)
+	})
+
+	t.Run("A failed drop stops the run", func(t *testing.T) {
+		repo := &mock.MockAuditRepository{}
+		repo.On("ListIndices", testify_mock.Anything).Return(indices, nil)
+		repo.On("DeleteIndex", testify_mock.Anything, "audit-2023.12").Return(nil)
+		repo.On("DeleteIndex", testify_mock.Anything, "audit-2024.01").Return(errors.New("cluster unavailable"))
+
+		dropped, err := audit.RetentionPolicy{Days: 30}.Apply(context.Background(), repo, now)
+
+		assert.ErrorContains(t, err, "audit-2024.01")
+		assert.Equal(t, []string{"audit-2023.12"}, dropped)
+		repo.AssertNotCalled(t, "DeleteIndex", testify_mock.Anything, "audit-2024.04")
+	})
+
+	t.Run("Logs go to the index of their month", func(t *testing.T) {
+		assert.Equal(t, "audit-2024.06", audit.IndexName(now))
+		// Months are UTC's, wherever the log was written
+		assert.Equal(t, "audit-2024.02", audit.IndexName(time.Date(2024, time.January, 31, 23, 30, 0, 0, time.FixedZone("", -2*3600))))
+	})
+}
+
+func TestRetentionIntervalIsHotReloadable(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	applied := make(chan struct{}, 10)
+	repo := &mock.MockAuditRepository{}
+	repo.On("ListIndices", testify_mock.Anything).Run(func(testify_mock.Arguments) {
+		applied <- struct{}{}
+	}).Return([]string{}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	waitApplied := func() bool {
+		select {
+		case <-applied:
+			return true
+		case <-time.After(time.Second):
+			return false
+		}
+	}
+
+	audit.StartRetention(ctx, repo, audit.RetentionPolicy{Days: 30}, time.Hour)
+	assert.True(t, waitApplied())
+
+	// The hour-long wait under way starts over with the new interval
+	audit.SetRetentionInterval(10 * time.Millisecond)
+
+	assert.True(t, waitApplied())
+}
@@ -3,26 +3,90 @@ package audit
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
 )
 
 type Service interface {
 	LogAccess(ctx context.Context, log AuditLog) error
 	QueryLogs(ctx context.Context, from, to time.Time, userID, resourceID string) ([]AuditLog, error)
+	StreamLogs(ctx context.Context, from, to time.Time, userID, resourceID string, visit func(AuditLog) error) error
+	VerifyChain(ctx context.Context, from, to time.Time) (*ChainVerification, error)
 }
 
 type service struct {
-	repo Repository
+	repo  Repository
+	heads ChainHeadStore
+
+	// writeMu serializes the writes of this instance, so that each log links to the one written just
+	// before it. Instances sharing the chain head continue each other's chain, but two of them writing
+	// at the same moment may fork it. Both forked logs are kept, and VerifyChain reports them.
+	writeMu sync.Mutex
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// NewService creates an audit Service writing to repo and chaining every log it writes to the chain
+// whose head heads keeps
+func NewService(repo Repository, heads ChainHeadStore) Service {
+	return &service{repo: repo, heads: heads}
 }
 
+// LogAccess links the log to the hash chain and writes it. The chain head only moves on once the log
+// is written, so a failed write leaves no gap. When the chain head cannot be read, the log is written
+// unchained rather than lost.
 func (s *service) LogAccess(ctx context.Context, log AuditLog) error {
-	return s.repo.LogAccess(ctx, log)
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	head, err := s.heads.GetHead(ctx)
+	if err != nil {
+		logger.Warn("Audit chain head unreadable, writing the log unchained", zap.Error(err), zap.String("action", log.Action))
+		log.Sequence, log.PrevHash, log.Hash, log.Unchained = 0, "", "", true
+		return s.repo.LogAccess(ctx, log)
+	}
+	chained, err := link(log, head)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.LogAccess(ctx, chained); err != nil {
+		return err
+	}
+	if err := s.heads.SetHead(ctx, model.AuditChainHead{Sequence: chained.Sequence, Hash: chained.Hash, Timestamp: chained.Timestamp}); err != nil {
+		return fmt.Errorf("audit log %d written but the chain head not moved: %w", chained.Sequence, err)
+	}
+	return nil
 }
 
 func (s *service) QueryLogs(ctx context.Context, from, to time.Time, userID, resourceID string) ([]AuditLog, error) {
 	return s.repo.QueryLogs(ctx, from, to, userID, resourceID)
 }
+
+func (s *service) StreamLogs(ctx context.Context, from, to time.Time, userID, resourceID string, visit func(AuditLog) error) error {
+	return s.repo.StreamLogs(ctx, from, to, userID, resourceID, visit)
+}
+
+// VerifyChain checks that the audit logs written between from and to were neither altered nor
+// removed: each must match its hash and link to the log before it, and the newest must be the chain
+// head when it falls within the range. The logs of the range are held in memory to be put in chain
+// order, so long ranges are better verified a piece at a time.
+func (s *service) VerifyChain(ctx context.Context, from, to time.Time) (*ChainVerification, error) {
+	head, err := s.heads.GetHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the audit chain head: %w", err)
+	}
+
+	var logs []AuditLog
+	if err := s.repo.StreamLogs(ctx, from, to, "", "", func(log AuditLog) error {
+		logs = append(logs, log)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read audit logs: %w", err)
+	}
+
+	return verifyChain(logs, head, from, to), nil
+}
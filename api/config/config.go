@@ -3,9 +3,18 @@ package config
 
 import (
 	"log"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 )
 
 // Configuration stores all the configurations
@@ -44,15 +53,90 @@ func InitConfig() error {
 	viper.SetConfigName("config") // name of the config file (without extension)
 	viper.SetConfigType("yaml")   // REQUIRED if the config file does not have the extension in the name
 
-	viper.AutomaticEnv() // read in environment variables that match
+	viper.AutomaticEnv()                                   // read in environment variables that match
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_")) // so BOOTSTRAP_ADMIN_EMAIL sets bootstrap.admin_email
 
 	// Set default configurations
 	viper.SetDefault("server.port", "8080")
+	viper.SetDefault("server.trusted_proxies", []string{})
+	viper.SetDefault("server.drain_timeout", "10s")
 	viper.SetDefault("neo4j.uri", "bolt://localhost:7687")
+	viper.SetDefault("neo4j.query_timeout", "30s")
+	viper.SetDefault("neo4j.pool.max_size", 50)
+	viper.SetDefault("neo4j.pool.acquisition_timeout", "1m")
+	viper.SetDefault("neo4j.pool.max_lifetime", "30m")
+	viper.SetDefault("neo4j.pool.slow_acquisition_threshold", "1s")
+	viper.SetDefault("neo4j.routing.enabled", false)
+	viper.SetDefault("neo4j.migrate_on_startup", true)
 	viper.SetDefault("redis.addr", "localhost:6379")
 	viper.SetDefault("elasticsearch.url", "http://localhost:9200")
+	viper.SetDefault("audit.retention.days", 0)
+	viper.SetDefault("audit.retention.dry_run", false)
+	viper.SetDefault("audit.retention.interval", "24h")
 	viper.SetDefault("redis.defaultCacheTTL", "10m")
+	viper.SetDefault("redis.circuit_breaker.failure_threshold", 5)
+	viper.SetDefault("redis.circuit_breaker.cooldown", "30s")
 	viper.SetDefault("log.file", "logging/api.log")
+	viper.SetDefault("access_log.level", "info")
+	viper.SetDefault("access_log.skip_paths", []string{"/health", "/healthz"})
+	viper.SetDefault("access_log.sampling", map[string]int{})
+	viper.SetDefault("compression.min_size", 1024)
+	viper.SetDefault("compression.content_types", []string{"application/json", "text/csv"})
+	viper.SetDefault("cors.allowed_origins", []string{})
+	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"})
+	viper.SetDefault("cors.allowed_headers", []string{"Authorization", "Content-Type", "X-Request-ID"})
+	viper.SetDefault("cors.allow_credentials", false)
+	viper.SetDefault("cors.max_age", "10m")
+	viper.SetDefault("requests.max_body_size", 1<<20)
+	viper.SetDefault("requests.route_max_body_sizes", map[string]int{"/api/v1/resources/bulk-tag": 10 << 20, "/api/v1/policies/import": 10 << 20})
+	viper.SetDefault("requests.timeout", "30s")
+	viper.SetDefault("requests.timeout_exempt", []string{"/api/v1/audit/export", "/api/v1/resources/export", "/api/v1/changes", "/api/v1/policies/stream"})
+	viper.SetDefault("pagination.max_limit", 200)
+	viper.SetDefault("resources.bulk_tag_limit", 1000)
+	viper.SetDefault("resources.max_search_attributes", 10)
+	viper.SetDefault("resources.classification_levels", []string{"public", "internal", "confidential", "restricted"})
+	viper.SetDefault("tenancy.isolated_entities", []string{})
+	viper.SetDefault("policies.allowed_actions", []string{})
+	viper.SetDefault("policies.timezone", "UTC")
+	viper.SetDefault("policies.require_approval", false)
+	viper.SetDefault("policies.enforce_clearance", false)
+	viper.SetDefault("policies.decision_cache_ttl", "30s")
+	viper.SetDefault("policies.bundle_signing_key", "")
+	viper.SetDefault("policies.priority_mode", "tiebreak")
+	viper.SetDefault("pdp.defaultEffect", "deny")
+	viper.SetDefault("pdp.inheritanceMaxDepth", 5)
+	viper.SetDefault("tenancy.global_admin_role", "global-admin")
+	viper.SetDefault("tenancy.scoped_admin_role", "department-admin")
+	viper.SetDefault("tenancy.include_sub_organizations", false)
+	viper.SetDefault("auth.login_record_interval", "5m")
+	viper.SetDefault("sod.enforcement", "off")
+	viper.SetDefault("notifications.digest_window", "0s")
+	viper.SetDefault("notifications.routes", map[string][]string{})
+	viper.SetDefault("notifications.webhook.url", "")
+	viper.SetDefault("notifications.chat.webhook_url", "")
+	viper.SetDefault("notifications.email.host", "")
+	viper.SetDefault("notifications.email.port", 587)
+	viper.SetDefault("notifications.email.username", "")
+	viper.SetDefault("notifications.email.password", "")
+	viper.SetDefault("notifications.email.tls", "starttls")
+	viper.SetDefault("notifications.email.from", "")
+	viper.SetDefault("notifications.email.to", []string{})
+	viper.SetDefault("notifications.email.timeout", "10s")
+	viper.SetDefault("notifications.email.max_retries", 3)
+	viper.SetDefault("notifications.email.retry_backoff", "2s")
+	viper.SetDefault("notifications.email.queue_size", 100)
+	viper.SetDefault("notifications.email.templates", map[string]interface{}{})
+	viper.SetDefault("jobs.workers", 4)
+	viper.SetDefault("jobs.queue_size", 100)
+	viper.SetDefault("jobs.ttl", "24h")
+	viper.SetDefault("bootstrap.organization_id", "root")
+	viper.SetDefault("bootstrap.organization_name", "Root Organization")
+	viper.SetDefault("bootstrap.admin_role_id", "admin")
+	viper.SetDefault("bootstrap.admin_role_name", "Administrator")
+	viper.SetDefault("bootstrap.admin_user_id", "")
+	viper.SetDefault("bootstrap.admin_username", "admin")
+	viper.SetDefault("bootstrap.admin_name", "Administrator")
+	viper.SetDefault("bootstrap.admin_email", "")
 
 	// Attempt to read the config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -72,6 +156,109 @@ func InitConfig() error {
 	return nil
 }
 
+// HotReloadableKeys are the settings that take effect while the server runs when the config file
+// changes. Changing any other key, such as the database addresses, takes a restart.
+var HotReloadableKeys = []string{
+	"log.level",
+	"rate_limit.requests",
+	"rate_limit.duration",
+	"redis.defaultCacheTTL",
+	"audit.retention.interval",
+}
+
+// settingsMu guards viper, which is not safe for concurrent use, against the reloads of WatchConfig:
+// reloads hold it for writing, and every getter of this package for reading
+var settingsMu sync.RWMutex
+
+// WatchConfig re-reads the config file whenever it changes and calls apply to put the hot-reloadable
+// settings into effect. Changes to any other key are logged as waiting for a restart. The file is
+// watched here rather than by viper.WatchConfig, which re-reads it without a lock while the getters
+// may be reading.
+func WatchConfig(apply func()) {
+	settingsMu.RLock()
+	file := viper.ConfigFileUsed()
+	previous := settingsSnapshot()
+	settingsMu.RUnlock()
+	if file == "" {
+		logger.Info("No config file to watch; configuration changes take a restart")
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Error("Failed to watch the config file", zap.Error(err), zap.String("file", file))
+		return
+	}
+	configFile := filepath.Clean(file)
+	realConfigFile, _ := filepath.EvalSymlinks(configFile)
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// The file is rewritten, or, as with a Kubernetes ConfigMap, the link to it is swapped
+				currentConfigFile, _ := filepath.EvalSymlinks(configFile)
+				written := filepath.Clean(event.Name) == configFile && (event.Has(fsnotify.Write) || event.Has(fsnotify.Create))
+				if !written && (currentConfigFile == "" || currentConfigFile == realConfigFile) {
+					continue
+				}
+				realConfigFile = currentConfigFile
+
+				settingsMu.Lock()
+				err := viper.ReadInConfig()
+				current := settingsSnapshot()
+				settingsMu.Unlock()
+				if err != nil {
+					logger.Error("Failed to reload the config file", zap.Error(err), zap.String("file", event.Name))
+					continue
+				}
+
+				for key, value := range current {
+					if !reflect.DeepEqual(previous[key], value) && !isHotReloadable(key) {
+						logger.Warn("Configuration change takes effect after a restart", zap.String("key", key))
+					}
+				}
+				previous = current
+
+				logger.Info("Configuration reloaded", zap.String("file", event.Name))
+				apply()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Error("Config file watch failed", zap.Error(err))
+			}
+		}
+	}()
+	// The directory is watched, as editors and ConfigMaps replace the file rather than write to it
+	if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+		logger.Error("Failed to watch the config file", zap.Error(err), zap.String("file", file))
+		watcher.Close()
+	}
+}
+
+// settingsSnapshot returns every setting; the caller holds settingsMu
+func settingsSnapshot() map[string]interface{} {
+	settings := make(map[string]interface{})
+	for _, key := range viper.AllKeys() {
+		settings[key] = viper.Get(key)
+	}
+	return settings
+}
+
+func isHotReloadable(key string) bool {
+	for _, reloadable := range HotReloadableKeys {
+		if strings.EqualFold(key, reloadable) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetConfig returns the loaded configuration
 func GetConfig() *Configuration {
 	return config
@@ -79,24 +266,62 @@ func GetConfig() *Configuration {
 
 // GetString retrieves a string value from the configuration
 func GetString(key string) string {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
 	return viper.GetString(key)
 }
 
 // GetInt retrieves an integer value from the configuration
 func GetInt(key string) int {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
 	return viper.GetInt(key)
 }
 
 // GetBool retrieves a boolean value from the configuration
 func GetBool(key string) bool {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
 	return viper.GetBool(key)
 }
 
 // GetFloat64 retrieves a float64 value from the configuration
 func GetFloat64(key string) float64 {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
 	return viper.GetFloat64(key)
 }
 
 func GetDuration(key string) time.Duration {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
 	return viper.GetDuration(key)
 }
+
+// GetStringSlice retrieves a list of strings from the configuration
+func GetStringSlice(key string) []string {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return viper.GetStringSlice(key)
+}
+
+// GetIntMap retrieves a map of integers from the configuration. Viper lowercases its keys.
+func GetIntMap(key string) map[string]int {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return cast.ToStringMapInt(viper.Get(key))
+}
+
+// GetStringSliceMap retrieves a map of string lists from the configuration. Viper lowercases its keys.
+func GetStringSliceMap(key string) map[string][]string {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return cast.ToStringMapStringSlice(viper.Get(key))
+}
+
+// UnmarshalKey decodes the configuration under key, such as a map of structs, into target
+func UnmarshalKey(key string, target interface{}) error {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return viper.UnmarshalKey(key, target)
+}
@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/dev-mohitbeniwal/echo/api/config"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+func TestWatchConfigReloadsLogLevel(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer viper.Reset()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(configFile, []byte("log:\n  level: info\n"), 0644))
+	viper.SetConfigFile(configFile)
+	assert.NoError(t, viper.ReadInConfig())
+
+	config.WatchConfig(func() {
+		logger.SetLevel(config.GetString("log.level"))
+	})
+	defer logger.SetLevel("info")
+	assert.False(t, logger.Log.Core().Enabled(zapcore.DebugLevel))
+
+	assert.NoError(t, os.WriteFile(configFile, []byte("log:\n  level: debug\n"), 0644))
+
+	assert.Eventually(t, func() bool {
+		return logger.Log.Core().Enabled(zapcore.DebugLevel)
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestWatchConfigReloadsWhileSettingsAreRead(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer viper.Reset()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(configFile, []byte("rate_limit:\n  duration: 1m\n"), 0644))
+	viper.SetConfigFile(configFile)
+	assert.NoError(t, viper.ReadInConfig())
+
+	config.WatchConfig(func() {})
+
+	// Requests keep reading settings while the file is reloaded
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				config.GetDuration("rate_limit.duration")
+			}
+		}
+	}()
+
+	assert.NoError(t, os.WriteFile(configFile, []byte("rate_limit:\n  duration: 2m\n"), 0644))
+
+	assert.Eventually(t, func() bool {
+		return config.GetDuration("rate_limit.duration") == 2*time.Minute
+	}, 5*time.Second, 20*time.Millisecond)
+	close(stop)
+	<-done
+}
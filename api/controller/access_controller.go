@@ -0,0 +1,117 @@
+// api/controller/access_controller.go
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+type AccessController struct {
+	accessService service.IAccessService
+}
+
+func NewAccessController(accessService service.IAccessService) *AccessController {
+	return &AccessController{
+		accessService: accessService,
+	}
+}
+
+// RegisterRoutes registers the API routes for access evaluation
+func (ac *AccessController) RegisterRoutes(r *gin.RouterGroup) {
+	access := r.Group("/access")
+	{
+		access.POST("/evaluate", ac.EvaluateAccess)
+	}
+	r.GET("/organizations/:id/access-review", ac.GetAccessReview)
+	r.POST("/organizations/:id/access-review/jobs", ac.SubmitAccessReview)
+	r.GET("/organizations/:id/policy-bundle", ac.ExportPolicyBundle)
+}
+
+// EvaluateAccess endpoint. With explain=true the decision carries the trace of the evaluation.
+func (ac *AccessController) EvaluateAccess(c *gin.Context) {
+	var request model.AccessRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid access request", echo_errors.ErrInvalidAccessRequest)
+		return
+	}
+	explain, err := strconv.ParseBool(c.DefaultQuery("explain", "false"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid explain parameter", err)
+		return
+	}
+	request.Explain = explain
+
+	// The client address comes from the connection, never the body, so ipInCidr conditions cannot be talked around
+	if request.Context == nil {
+		request.Context = map[string]interface{}{}
+	}
+	request.Context["client_ip"] = helper_util.ClientIP(c.Request)
+
+	decision, err := ac.accessService.EvaluateAccess(c, request)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrInvalidAccessRequest) {
+			util.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		} else if errors.Is(err, echo_errors.ErrUserNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, "Subject not found", err)
+		} else if errors.Is(err, echo_errors.ErrResourceNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, "Resource not found", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to evaluate access", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// GetAccessReview endpoint reports a page of the organization's users with their roles, groups,
+// permissions and the resources they can access
+func (ac *AccessController) GetAccessReview(c *gin.Context) {
+	limit, offset, err := helper_util.GetPaginationParams(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	report, err := ac.accessService.GenerateAccessReviewPage(c, c.Param("id"), limit, offset)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(report.Total, 10))
+	c.JSON(http.StatusOK, report)
+}
+
+// SubmitAccessReview endpoint starts reviewing all of the organization's users in a background job,
+// whose result is the whole access review
+func (ac *AccessController) SubmitAccessReview(c *gin.Context) {
+	job, err := ac.accessService.SubmitAccessReview(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	respondWithJob(c, job)
+}
+
+// ExportPolicyBundle endpoint returns the organization's signed policy bundle, for evaluating access
+// without the database
+func (ac *AccessController) ExportPolicyBundle(c *gin.Context) {
+	bundle, err := ac.accessService.ExportPolicyBundle(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", bundle)
+}
@@ -0,0 +1,182 @@
+// api/controller/admin_controller.go
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// LogLevel is the body of the log level endpoints
+type LogLevel struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// ReadOnlyMode is the body of the read-only mode endpoints
+type ReadOnlyMode struct {
+	ReadOnly *bool `json:"read_only" binding:"required"`
+}
+
+// CacheFlush is the body of the cache flush endpoint. Without an ID, the whole namespace is flushed.
+type CacheFlush struct {
+	Namespace string `json:"namespace" binding:"required"`
+	ID        string `json:"id,omitempty"`
+}
+
+// CacheFlushResult reports what the cache flush endpoint dropped
+type CacheFlushResult struct {
+	Namespace string `json:"namespace"`
+	ID        string `json:"id,omitempty"`
+	Deleted   int    `json:"deleted"` // Keys dropped
+}
+
+// Metrics is the body of the metrics endpoint
+type Metrics struct {
+	Neo4jPool    *db.PoolStats             `json:"neo4j_pool"`    // Null before the server connected to Neo4j
+	RedisBreaker *util.CircuitBreakerStats `json:"redis_breaker"` // Null when Redis calls are not guarded
+}
+
+// AdminController serves operational endpoints. The router restricts them to administrators.
+type AdminController struct {
+	resourceService service.IResourceService
+	cacheService    *util.CacheService
+}
+
+func NewAdminController(resourceService service.IResourceService, cacheService *util.CacheService) *AdminController {
+	return &AdminController{resourceService: resourceService, cacheService: cacheService}
+}
+
+// RegisterRoutes registers the API routes for administration
+func (ac *AdminController) RegisterRoutes(r *gin.RouterGroup) {
+	admin := r.Group("/admin")
+	{
+		admin.GET("/log-level", ac.GetLogLevel)
+		admin.PUT("/log-level", ac.SetLogLevel)
+		admin.GET("/integrity/resources", ac.CheckResourceIntegrity)
+		admin.GET("/metrics", ac.GetMetrics)
+		admin.GET("/read-only", ac.GetReadOnly)
+		admin.PUT("/read-only", ac.SetReadOnly)
+		admin.POST("/cache/flush", ac.FlushCache)
+	}
+}
+
+// GetLogLevel endpoint
+func (ac *AdminController) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, LogLevel{Level: logger.GetLevel()})
+}
+
+// SetLogLevel endpoint changes the minimum level logged until the next restart or config reload
+func (ac *AdminController) SetLogLevel(c *gin.Context) {
+	var request LogLevel
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithBindError(c, "Invalid log level", err)
+		return
+	}
+
+	previous := logger.GetLevel()
+	if err := logger.SetLevel(request.Level); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid log level", err)
+		return
+	}
+
+	logger.Warn("Log level changed",
+		zap.String("from", previous),
+		zap.String("to", logger.GetLevel()),
+		zap.Any("userID", c.Value("requestingUserID")))
+	c.JSON(http.StatusOK, LogLevel{Level: logger.GetLevel()})
+}
+
+// GetReadOnly endpoint reports whether the API is read-only for maintenance
+func (ac *AdminController) GetReadOnly(c *gin.Context) {
+	readOnly := util.IsReadOnly(c)
+	c.JSON(http.StatusOK, ReadOnlyMode{ReadOnly: &readOnly})
+}
+
+// SetReadOnly endpoint turns the read-only mode on or off. The mode is kept in Redis, so every instance
+// observes it.
+func (ac *AdminController) SetReadOnly(c *gin.Context) {
+	var request ReadOnlyMode
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithBindError(c, "Invalid read-only mode", err)
+		return
+	}
+
+	if err := util.SetReadOnly(c, *request.ReadOnly); err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to set the read-only mode", err)
+		return
+	}
+
+	logger.Warn("Read-only mode changed",
+		zap.Bool("readOnly", *request.ReadOnly),
+		zap.Any("userID", c.Value("requestingUserID")))
+	c.JSON(http.StatusOK, request)
+}
+
+// CheckResourceIntegrity endpoint reports resources whose reference properties disagree with their
+// relationships. With ?repair=true the missing relationships are recreated from the properties.
+func (ac *AdminController) CheckResourceIntegrity(c *gin.Context) {
+	repair, err := strconv.ParseBool(c.DefaultQuery("repair", "false"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid repair parameter", err)
+		return
+	}
+
+	report, err := ac.resourceService.CheckIntegrity(c, repair)
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to check resource integrity", err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetMetrics endpoint reports the usage of the Neo4j connection pool and the state of the circuit
+// breaker guarding Redis
+func (ac *AdminController) GetMetrics(c *gin.Context) {
+	var metrics Metrics
+	if stats, ok := db.Neo4jPoolStats(); ok {
+		metrics.Neo4jPool = &stats
+	}
+	if ac.cacheService != nil {
+		if stats, ok := ac.cacheService.BreakerStats(); ok {
+			metrics.RedisBreaker = &stats
+		}
+	}
+	c.JSON(http.StatusOK, metrics)
+}
+
+// FlushCache endpoint drops the entries cached under a namespace, such as every cached policy after an
+// out-of-band change to Neo4j, or a single entry of it
+func (ac *AdminController) FlushCache(c *gin.Context) {
+	var request CacheFlush
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithBindError(c, "Invalid cache flush", err)
+		return
+	}
+	if !db.IsCacheKind(request.Namespace) {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid cache namespace",
+			fmt.Errorf("unknown namespace %q, expected one of %s", request.Namespace, strings.Join(db.CacheKinds(), ", ")))
+		return
+	}
+
+	deleted, err := ac.cacheService.Flush(c, request.Namespace, request.ID)
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to flush the cache", err)
+		return
+	}
+
+	logger.Warn("Cache flushed",
+		zap.String("namespace", request.Namespace),
+		zap.String("id", request.ID),
+		zap.Int("deleted", deleted),
+		zap.Any("userID", c.Value("requestingUserID")))
+	c.JSON(http.StatusOK, CacheFlushResult{Namespace: request.Namespace, ID: request.ID, Deleted: deleted})
+}
@@ -0,0 +1,163 @@
+// api/controller/admin_controller_test.go
+package controller_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/controller"
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/middleware"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestAdminControllerLogLevel(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer logger.SetLevel("info")
+
+	newRouter := func(groups ...string) http.Handler {
+		router := setupRouter()
+		router.Use(func(c *gin.Context) {
+			c.Set("requestingRoles", groups)
+		})
+		controller.NewAdminController(nil, nil).RegisterRoutes(router.Group("", middleware.RequireGroups("global-admin")))
+		return router
+	}
+	send := func(router http.Handler, method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/admin/log-level", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	// debugLogged reports whether a debug line logged now reaches the log file
+	debugLogged := func() bool {
+		probe := "log level probe " + uuid.NewString()
+		logger.Debug(probe)
+		logger.Sync()
+		contents, err := os.ReadFile("../logging/api.log")
+		assert.NoError(t, err)
+		return strings.Contains(string(contents), probe)
+	}
+
+	t.Run("AdminTogglesLevel", func(t *testing.T) {
+		router := newRouter("alive-admin", "global-admin")
+		assert.False(t, debugLogged())
+
+		w := send(router, http.MethodPut, `{"level":"debug"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"level":"debug"}`, w.Body.String())
+		assert.True(t, debugLogged())
+
+		w = send(router, http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"level":"debug"}`, w.Body.String())
+
+		w = send(router, http.MethodPut, `{"level":"info"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, debugLogged())
+	})
+
+	t.Run("UnknownLevel", func(t *testing.T) {
+		w := send(newRouter("global-admin"), http.MethodPut, `{"level":"verbose"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "info", logger.GetLevel())
+	})
+
+	t.Run("NonAdminIsForbidden", func(t *testing.T) {
+		w := send(newRouter("alive-admin"), http.MethodPut, `{"level":"debug"}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "info", logger.GetLevel())
+	})
+}
+
+func TestAdminControllerCacheFlush(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	cacheService := util.NewCacheService(nil)
+	newRouter := func(groups ...string) http.Handler {
+		router := setupRouter()
+		router.Use(func(c *gin.Context) {
+			c.Set("requestingRoles", groups)
+		})
+		controller.NewAdminController(nil, cacheService).RegisterRoutes(router.Group("", middleware.RequireGroups("global-admin")))
+		return router
+	}
+	send := func(router http.Handler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/admin/cache/flush", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	// populate caches more policies than one SCAN batch covers, along with a few users
+	populate := func(t *testing.T) *mock.RedisServer {
+		server := mock.StartRedisServer(t)
+		for i := 0; i < 1200; i++ {
+			require.NoError(t, db.RedisClient.Set(ctx, fmt.Sprintf("policy:p%d", i), "cached", 0).Err())
+		}
+		require.NoError(t, cacheService.SetMany(ctx, &model.User{ID: "u1"}, &model.User{ID: "u2"}))
+		return server
+	}
+
+	t.Run("FlushingANamespaceKeepsTheOthers", func(t *testing.T) {
+		server := populate(t)
+
+		w := send(newRouter("global-admin"), `{"namespace":"policy"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"namespace":"policy","deleted":1200}`, w.Body.String())
+		assert.False(t, server.Has("policy:p0"))
+		assert.False(t, server.Has("policy:p1199"))
+		assert.True(t, server.Has("user:u1"))
+		assert.True(t, server.Has("user:u2"))
+	})
+
+	t.Run("FlushingOneEntry", func(t *testing.T) {
+		server := populate(t)
+
+		w := send(newRouter("global-admin"), `{"namespace":"user","id":"u1"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"namespace":"user","id":"u1","deleted":1}`, w.Body.String())
+		assert.False(t, server.Has("user:u1"))
+		assert.True(t, server.Has("user:u2"))
+		assert.True(t, server.Has("policy:p0"))
+	})
+
+	t.Run("UnknownNamespace", func(t *testing.T) {
+		server := populate(t)
+
+		w := send(newRouter("global-admin"), `{"namespace":"policies"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.True(t, server.Has("policy:p0"))
+	})
+
+	t.Run("NonAdminIsForbidden", func(t *testing.T) {
+		server := populate(t)
+
+		w := send(newRouter("alive-admin"), `{"namespace":"policy"}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.True(t, server.Has("policy:p0"))
+	})
+}
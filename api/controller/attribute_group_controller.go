@@ -4,6 +4,7 @@ package controller
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -33,6 +34,7 @@ func (agc *AttributeGroupController) RegisterRoutes(r *gin.RouterGroup) {
 		attributeGroups.DELETE("/:id", agc.DeleteAttributeGroup)
 		attributeGroups.GET("/:id", agc.GetAttributeGroup)
 		attributeGroups.GET("", agc.ListAttributeGroups)
+		attributeGroups.POST("/search", agc.SearchAttributeGroups)
 	}
 }
 
@@ -59,7 +61,7 @@ func (agc *AttributeGroupController) CreateAttributeGroup(c *gin.Context) {
 		case echo_errors.ErrInternalServer:
 			util.RespondWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create attribute group", echo_errors.ErrInternalServer)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -87,7 +89,7 @@ func (agc *AttributeGroupController) UpdateAttributeGroup(c *gin.Context) {
 		if err == echo_errors.ErrAttributeGroupNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Attribute group not found", err)
 		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update attribute group", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -104,9 +106,17 @@ func (agc *AttributeGroupController) DeleteAttributeGroup(c *gin.Context) {
 		return
 	}
 
-	if err := agc.attributeGroupService.DeleteAttributeGroup(c, attributeGroupID, deleterID); err != nil {
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid force parameter", err)
+		return
+	}
+
+	if err := agc.attributeGroupService.DeleteAttributeGroup(c, attributeGroupID, deleterID, force); err != nil {
 		if err == echo_errors.ErrAttributeGroupNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Attribute group not found", err)
+		} else if err == echo_errors.ErrAttributeGroupInUse {
+			util.RespondWithError(c, http.StatusConflict, "Attribute group is still in use", err)
 		} else {
 			util.RespondWithError(c, http.StatusInternalServerError, "Failed to delete attribute group", err)
 		}
@@ -130,7 +140,7 @@ func (agc *AttributeGroupController) GetAttributeGroup(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, attributeGroup)
+	util.RespondWithEntity(c, attributeGroup)
 }
 
 // ListAttributeGroups endpoint
@@ -149,3 +159,26 @@ func (agc *AttributeGroupController) ListAttributeGroups(c *gin.Context) {
 
 	c.JSON(http.StatusOK, attributeGroups)
 }
+
+// SearchAttributeGroups endpoint
+func (agc *AttributeGroupController) SearchAttributeGroups(c *gin.Context) {
+	var criteria model.AttributeGroupSearchCriteria
+
+	if err := c.ShouldBindJSON(&criteria); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
+		return
+	}
+
+	attributeGroups, total, err := agc.attributeGroupService.SearchAttributeGroups(c, criteria)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to search attribute groups", err)
+		}
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, attributeGroups)
+}
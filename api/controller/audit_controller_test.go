@@ -0,0 +1,81 @@
+// api/controller/audit_controller_test.go
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/controller"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestAuditController(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	newRouter := func(auditService *mock.MockAuditService) http.Handler {
+		router := setupRouter()
+		controller.NewAuditController(auditService).RegisterRoutes(router.Group("/"))
+		return router
+	}
+
+	t.Run("ExportAuditLogs_CSV", func(t *testing.T) {
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		auditService := &mock.MockAuditService{}
+		auditService.On("StreamLogs", testify_mock.Anything, from, to, "u1", "").Return([]audit.AuditLog{
+			{Timestamp: time.Date(2024, 1, 5, 9, 30, 0, 0, time.UTC), UserID: "u1", Action: "CREATE_POLICY", ResourceID: "p1", AccessGranted: true, PolicyID: "p1"},
+			{Timestamp: time.Date(2024, 1, 6, 10, 0, 0, 0, time.UTC), UserID: "u1", Action: "UPDATE_RESOURCE", ResourceID: "r1", AccessGranted: true, ChangeDetails: json.RawMessage(`{"name":"Q1, final"}`)},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/audit/export?format=csv&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&user_id=u1", nil)
+		newRouter(auditService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "timestamp,user_id,action,resource_id,access_granted,policy_id,change_details\n"+
+			"2024-01-05T09:30:00Z,u1,CREATE_POLICY,p1,true,p1,\n"+
+			`2024-01-06T10:00:00Z,u1,UPDATE_RESOURCE,r1,true,,"{""name"":""Q1, final""}"`+"\n", w.Body.String())
+	})
+
+	t.Run("ExportAuditLogs_UnsupportedFormat", func(t *testing.T) {
+		auditService := &mock.MockAuditService{}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/audit/export?format=xml", nil)
+		newRouter(auditService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		auditService.AssertNotCalled(t, "StreamLogs", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("VerifyAuditChain", func(t *testing.T) {
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		auditService := &mock.MockAuditService{}
+		auditService.On("VerifyChain", testify_mock.Anything, from, to).Return(&audit.ChainVerification{
+			From: from, To: to, Verified: 3,
+			Breaks: []audit.ChainBreak{{Sequence: 2, Reason: "content does not match its hash"}},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/audit/verify?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z", nil)
+		newRouter(auditService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var verification audit.ChainVerification
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &verification))
+		assert.False(t, verification.Valid)
+		assert.Equal(t, 3, verification.Verified)
+		assert.Equal(t, []audit.ChainBreak{{Sequence: 2, Reason: "content does not match its hash"}}, verification.Breaks)
+	})
+}
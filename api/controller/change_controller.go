@@ -0,0 +1,60 @@
+// api/controller/change_controller.go
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+type ChangeController struct {
+	changeFeedService service.IChangeFeedService
+}
+
+func NewChangeController(changeFeedService service.IChangeFeedService) *ChangeController {
+	return &ChangeController{
+		changeFeedService: changeFeedService,
+	}
+}
+
+// RegisterRoutes registers the API routes for the change feed
+func (cc *ChangeController) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/changes", cc.GetChanges)
+}
+
+// GetChanges endpoint returns the entity changes following the since cursor, oldest first. Passing
+// wait, such as 20s, long-polls: with no change yet the request is held open until one is recorded or
+// wait passes.
+func (cc *ChangeController) GetChanges(c *gin.Context) {
+	limit, _, err := helper_util.GetPaginationParams(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+	var wait time.Duration
+	if value := c.Query("wait"); value != "" {
+		if wait, err = time.ParseDuration(value); err != nil || wait < 0 {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid wait, expected a duration such as 20s", echo_errors.ErrInvalidSearchCriteria)
+			return
+		}
+	}
+
+	page, err := cc.changeFeedService.GetChanges(c, c.Query("since"), limit, wait)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid cursor", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to list changes", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
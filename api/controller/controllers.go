@@ -14,6 +14,13 @@ type Controllers struct {
 	Resource       *ResourceController
 	ResourceType   *ResourceTypeController
 	AttributeGroup *AttributeGroupController
+	Access         *AccessController
+	Audit          *AuditController
+	SoD            *SoDController
+	Admin          *AdminController
+	Change         *ChangeController
+	Search         *SearchController
+	Job            *JobController
 }
 
 func InitializeControllers(services *service.Services) *Controllers {
@@ -28,5 +35,12 @@ func InitializeControllers(services *service.Services) *Controllers {
 		Resource:       NewResourceController(services.Resource),
 		ResourceType:   NewResourceTypeController(services.ResourceTypeService),
 		AttributeGroup: NewAttributeGroupController(services.AttributeGroupService),
+		Access:         NewAccessController(services.Access),
+		Audit:          NewAuditController(services.Audit),
+		SoD:            NewSoDController(services.SoD),
+		Admin:          NewAdminController(services.Resource, services.Cache),
+		Change:         NewChangeController(services.ChangeFeed),
+		Search:         NewSearchController(services.Search),
+		Job:            NewJobController(services.Jobs),
 	}
 }
@@ -0,0 +1,100 @@
+// api/controller/csv_export.go
+package controller
+
+import (
+	"encoding/csv"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// ExportFormatCSV is the only export format served so far
+const ExportFormatCSV = "csv"
+
+// csvFlushRows is how many rows a CSV export buffers before pushing them to the client
+const csvFlushRows = 100
+
+// csvStream writes a CSV download row by row. The response only starts with the first row, so a failure
+// before any data is ready can still be answered with an error status.
+type csvStream struct {
+	c        *gin.Context
+	filename string
+	header   []string
+	writer   *csv.Writer
+	rows     int
+}
+
+func newCSVStream(c *gin.Context, filename string, header []string) *csvStream {
+	return &csvStream{c: c, filename: filename, header: header}
+}
+
+// started reports whether the response has begun, after which errors can no longer be reported
+func (s *csvStream) started() bool {
+	return s.writer != nil
+}
+
+func (s *csvStream) start() error {
+	s.c.Header("Content-Type", "text/csv; charset=utf-8")
+	s.c.Header("Content-Disposition", `attachment; filename="`+s.filename+`"`)
+	s.c.Status(http.StatusOK)
+	s.writer = csv.NewWriter(s.c.Writer)
+	return s.writer.Write(s.header)
+}
+
+// write adds a row, flushing every csvFlushRows rows
+func (s *csvStream) write(row []string) error {
+	if !s.started() {
+		if err := s.start(); err != nil {
+			return err
+		}
+	}
+	if err := s.writer.Write(row); err != nil {
+		return err
+	}
+	s.rows++
+	if s.rows%csvFlushRows == 0 {
+		return s.flush()
+	}
+	return nil
+}
+
+// close finishes the download; an export without rows still carries the header
+func (s *csvStream) close() error {
+	if !s.started() {
+		if err := s.start(); err != nil {
+			return err
+		}
+	}
+	return s.flush()
+}
+
+func (s *csvStream) flush() error {
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+// checkExportFormat answers a request for an unsupported export format with 400 and reports whether
+// the format is served
+func checkExportFormat(c *gin.Context) bool {
+	if format := c.DefaultQuery("format", ExportFormatCSV); format != ExportFormatCSV {
+		util.RespondWithError(c, http.StatusBadRequest, "Unsupported export format: "+format, echo_errors.ErrInvalidSearchCriteria)
+		return false
+	}
+	return true
+}
+
+// formatExportTime renders a timestamp for an export, leaving unset times empty
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -64,7 +64,7 @@ func (dc *DepartmentController) CreateDepartment(c *gin.Context) {
 		case echo_errors.ErrInternalServer:
 			util.RespondWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create department", echo_errors.ErrInternalServer)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -92,7 +92,7 @@ func (dc *DepartmentController) UpdateDepartment(c *gin.Context) {
 		if err == echo_errors.ErrDepartmentNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Department not found", err)
 		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update department", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -109,16 +109,23 @@ func (dc *DepartmentController) DeleteDepartment(c *gin.Context) {
 		return
 	}
 
-	if err := dc.departmentService.DeleteDepartment(c, deptID, userID); err != nil {
-		if err == echo_errors.ErrDepartmentNotFound {
+	mode := c.DefaultQuery("mode", model.DeptDeleteModeRestrict)
+	targetDeptID := c.Query("targetDeptId")
+
+	summary, err := dc.departmentService.DeleteDepartment(c, deptID, userID, mode, targetDeptID)
+	if err != nil {
+		switch {
+		case errors.Is(err, echo_errors.ErrDepartmentNotFound):
 			util.RespondWithError(c, http.StatusNotFound, "Department not found", err)
-		} else {
+		case errors.Is(err, echo_errors.ErrDepartmentHasDependents), errors.Is(err, echo_errors.ErrInvalidDeptDeleteMode):
+			util.RespondWithMappedError(c, err)
+		default:
 			util.RespondWithError(c, http.StatusInternalServerError, "Failed to delete department", err)
 		}
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	c.JSON(http.StatusOK, summary)
 }
 
 // GetDepartment endpoint
@@ -135,7 +142,7 @@ func (dc *DepartmentController) GetDepartment(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, dept)
+	util.RespondWithEntity(c, dept)
 }
 
 // ListDepartments endpoint
@@ -166,7 +173,11 @@ func (dc *DepartmentController) SearchDepartments(c *gin.Context) {
 
 	depts, err := dc.departmentService.SearchDepartments(c, criteria)
 	if err != nil {
-		util.RespondWithError(c, http.StatusInternalServerError, "Failed to search organizations", err)
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to search organizations", err)
+		}
 		return
 	}
 
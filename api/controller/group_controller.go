@@ -36,6 +36,9 @@ func (gc *GroupController) RegisterRoutes(r *gin.RouterGroup) {
 		groups.GET("/:id", gc.GetGroup)
 		groups.GET("", gc.ListGroups)
 		groups.GET("/search", gc.SearchGroups)
+		groups.PUT("/:id/parent", gc.SetParentGroup)
+		groups.GET("/:id/hierarchy", gc.GetGroupHierarchy)
+		groups.GET("/:id/effective-members", gc.GetEffectiveGroupMembers)
 	}
 }
 
@@ -62,7 +65,7 @@ func (gc *GroupController) CreateGroup(c *gin.Context) {
 		case echo_errors.ErrInternalServer:
 			util.RespondWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create group", echo_errors.ErrInternalServer)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -93,7 +96,7 @@ func (gc *GroupController) UpdateGroup(c *gin.Context) {
 		if err == echo_errors.ErrGroupNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Group not found", err)
 		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update group", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -136,7 +139,7 @@ func (gc *GroupController) GetGroup(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, group)
+	util.RespondWithEntity(c, group)
 }
 
 // ListGroups endpoint
@@ -179,3 +182,63 @@ func (gc *GroupController) SearchGroups(c *gin.Context) {
 
 	c.JSON(http.StatusOK, groups)
 }
+
+// SetParentGroup endpoint
+func (gc *GroupController) SetParentGroup(c *gin.Context) {
+	groupID := c.Param("id")
+	var body struct {
+		ParentGroupID string `json:"parent_group_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid group data", echo_errors.ErrInvalidGroupData)
+		return
+	}
+	updaterID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if err := gc.groupService.SetParentGroup(c, groupID, body.ParentGroupID, updaterID); err != nil {
+		if errors.Is(err, echo_errors.ErrGroupCycle) {
+			util.RespondWithError(c, http.StatusConflict, "Group hierarchy would contain a cycle", err)
+		} else if errors.Is(err, echo_errors.ErrGroupNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to set parent group", err)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetGroupHierarchy endpoint
+func (gc *GroupController) GetGroupHierarchy(c *gin.Context) {
+	groupID := c.Param("id")
+
+	hierarchy, err := gc.groupService.GetGroupHierarchy(c, groupID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrGroupNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, "Group not found", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve group hierarchy", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, hierarchy)
+}
+
+// GetEffectiveGroupMembers endpoint
+func (gc *GroupController) GetEffectiveGroupMembers(c *gin.Context) {
+	groupID := c.Param("id")
+
+	members, err := gc.groupService.GetEffectiveGroupMembers(c, groupID)
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve effective group members", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
@@ -0,0 +1,44 @@
+// api/controller/job_controller.go
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+type JobController struct {
+	jobRunner *util.JobRunner
+}
+
+func NewJobController(jobRunner *util.JobRunner) *JobController {
+	return &JobController{
+		jobRunner: jobRunner,
+	}
+}
+
+// RegisterRoutes registers the API routes for background jobs
+func (jc *JobController) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/jobs/:id", jc.GetJob)
+}
+
+// GetJob endpoint returns the status and progress of a background job and, once it has ended, its result
+func (jc *JobController) GetJob(c *gin.Context) {
+	job, err := jc.jobRunner.Get(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// respondWithJob answers a request that submitted a background job with the job as queued, pointing
+// the client at where to poll it
+func respondWithJob(c *gin.Context, job *model.Job) {
+	c.Header("Location", "/api/v1/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
@@ -34,6 +34,9 @@ func (oc *OrganizationController) RegisterRoutes(r *gin.RouterGroup) {
 		organizations.GET("/:id", oc.GetOrganization)
 		organizations.GET("", oc.ListOrganizations)
 		organizations.POST("/search", oc.SearchOrganizations)
+		organizations.PUT("/:id/parent", oc.SetParentOrganization)
+		organizations.GET("/:id/hierarchy", oc.GetOrganizationHierarchy)
+		organizations.GET("/:id/sub-organizations", oc.GetSubOrganizations)
 	}
 }
 
@@ -60,7 +63,7 @@ func (oc *OrganizationController) CreateOrganization(c *gin.Context) {
 		case echo_errors.ErrInternalServer:
 			util.RespondWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create organization", echo_errors.ErrInternalServer)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -88,7 +91,7 @@ func (oc *OrganizationController) UpdateOrganization(c *gin.Context) {
 		if err == echo_errors.ErrOrganizationNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Organization not found", err)
 		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update organization", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -105,16 +108,25 @@ func (oc *OrganizationController) DeleteOrganization(c *gin.Context) {
 		return
 	}
 
-	if err := oc.organizationService.DeleteOrganization(c, orgID, userID); err != nil {
-		if err == echo_errors.ErrOrganizationNotFound {
+	mode := c.DefaultQuery("mode", model.OrgDeleteModeRestrict)
+	targetOrgID := c.Query("targetOrgId")
+
+	summary, err := oc.organizationService.DeleteOrganization(c, orgID, userID, mode, targetOrgID)
+	if err != nil {
+		switch err {
+		case echo_errors.ErrOrganizationNotFound:
 			util.RespondWithError(c, http.StatusNotFound, "Organization not found", err)
-		} else {
+		case echo_errors.ErrOrganizationInUse:
+			util.RespondWithError(c, http.StatusConflict, "Organization still has dependent entities", err)
+		case echo_errors.ErrInvalidOrgDeleteMode:
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid delete mode", err)
+		default:
 			util.RespondWithError(c, http.StatusInternalServerError, "Failed to delete organization", err)
 		}
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	c.JSON(http.StatusOK, summary)
 }
 
 // GetOrganization endpoint
@@ -131,7 +143,7 @@ func (oc *OrganizationController) GetOrganization(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, org)
+	util.RespondWithEntity(c, org)
 }
 
 // ListOrganizations endpoint
@@ -161,9 +173,78 @@ func (oc *OrganizationController) SearchOrganizations(c *gin.Context) {
 
 	orgs, err := oc.organizationService.SearchOrganizations(c, criteria)
 	if err != nil {
-		util.RespondWithError(c, http.StatusInternalServerError, "Failed to search organizations", err)
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to search organizations", err)
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, orgs)
 }
+
+// SetParentOrganization endpoint
+func (oc *OrganizationController) SetParentOrganization(c *gin.Context) {
+	orgID := c.Param("id")
+	var body struct {
+		ParentID string `json:"parent_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid organization data", echo_errors.ErrInvalidOrganizationData)
+		return
+	}
+	userID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	org, err := oc.organizationService.SetParentOrganization(c, orgID, body.ParentID, userID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrOrganizationCycle) {
+			util.RespondWithError(c, http.StatusConflict, "Organization hierarchy would contain a cycle", err)
+		} else if errors.Is(err, echo_errors.ErrOrganizationNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+		} else {
+			util.RespondWithMappedError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+// GetOrganizationHierarchy endpoint
+func (oc *OrganizationController) GetOrganizationHierarchy(c *gin.Context) {
+	orgID := c.Param("id")
+
+	hierarchy, err := oc.organizationService.GetOrganizationHierarchy(c, orgID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrOrganizationNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, "Organization not found", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve organization hierarchy", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, hierarchy)
+}
+
+// GetSubOrganizations endpoint
+func (oc *OrganizationController) GetSubOrganizations(c *gin.Context) {
+	orgID := c.Param("id")
+
+	subOrgs, err := oc.organizationService.GetSubOrganizations(c, orgID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrOrganizationNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, "Organization not found", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve sub-organizations", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, subOrgs)
+}
@@ -4,6 +4,7 @@ package controller
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -31,9 +32,12 @@ func (pc *PermissionController) RegisterRoutes(r *gin.RouterGroup) {
 		permissions.POST("", pc.CreatePermission)
 		permissions.PUT("/:id", pc.UpdatePermission)
 		permissions.DELETE("/:id", pc.DeletePermission)
+		permissions.GET("/orphaned", pc.ListOrphanedPermissions)
+		permissions.DELETE("/orphaned", pc.CleanupOrphanedPermissions)
 		permissions.GET("/:id", pc.GetPermission)
 		permissions.GET("", pc.ListPermissions)
-		permissions.GET("/search", pc.SearchPermissions)
+		permissions.POST("/search", pc.SearchPermissions)
+		permissions.GET("/action/:action", pc.GetPermissionsByAction)
 	}
 }
 
@@ -60,7 +64,7 @@ func (pc *PermissionController) CreatePermission(c *gin.Context) {
 		case echo_errors.ErrInternalServer:
 			util.RespondWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create permission", echo_errors.ErrInternalServer)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -88,7 +92,7 @@ func (pc *PermissionController) UpdatePermission(c *gin.Context) {
 		if err == echo_errors.ErrPermissionNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Permission not found", err)
 		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update permission", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -117,6 +121,43 @@ func (pc *PermissionController) DeletePermission(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ListOrphanedPermissions endpoint
+func (pc *PermissionController) ListOrphanedPermissions(c *gin.Context) {
+	permissions, err := pc.permissionService.ListOrphanedPermissions(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to list orphaned permissions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// CleanupOrphanedPermissions endpoint; olderThan is a duration such as "720h"
+func (pc *PermissionController) CleanupOrphanedPermissions(c *gin.Context) {
+	deleterID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var maxAge time.Duration
+	if olderThan := c.Query("olderThan"); olderThan != "" {
+		maxAge, err = time.ParseDuration(olderThan)
+		if err != nil || maxAge <= 0 {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid olderThan parameter", err)
+			return
+		}
+	}
+
+	deletedIDs, err := pc.permissionService.CleanupOrphanedPermissions(c, maxAge, deleterID)
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to clean up orphaned permissions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deletedIDs)
+}
+
 // GetPermission endpoint
 func (pc *PermissionController) GetPermission(c *gin.Context) {
 	permissionID := c.Param("id")
@@ -131,7 +172,7 @@ func (pc *PermissionController) GetPermission(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, permission)
+	util.RespondWithEntity(c, permission)
 }
 
 // ListPermissions endpoint
@@ -153,22 +194,37 @@ func (pc *PermissionController) ListPermissions(c *gin.Context) {
 
 // SearchPermissions endpoint
 func (pc *PermissionController) SearchPermissions(c *gin.Context) {
-	query := c.Query("query")
+	var criteria model.PermissionSearchCriteria
 
-	limit, offset, err := helper_util.GetPaginationParams(c)
-	if err != nil {
-		util.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters", err)
+	if err := c.ShouldBindJSON(&criteria); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
 		return
 	}
 
-	if query == "" {
-		util.RespondWithError(c, http.StatusBadRequest, "Query parameter is required", nil)
+	permissions, err := pc.permissionService.SearchPermissions(c, criteria)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to search permissions", err)
+		}
 		return
 	}
 
-	permissions, err := pc.permissionService.SearchPermissions(c, query, limit, offset)
+	c.JSON(http.StatusOK, permissions)
+}
+
+// GetPermissionsByAction endpoint
+func (pc *PermissionController) GetPermissionsByAction(c *gin.Context) {
+	action := c.Param("action")
+
+	permissions, err := pc.permissionService.GetPermissionsByAction(c, action)
 	if err != nil {
-		util.RespondWithError(c, http.StatusInternalServerError, "Failed to search permissions", err)
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, "Action is required", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve permissions by action", err)
+		}
 		return
 	}
 
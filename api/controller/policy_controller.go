@@ -2,8 +2,11 @@
 package controller
 
 import (
+	"context"
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -14,6 +17,10 @@ import (
 	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
+// policyStreamHeartbeat is how often an idle policy stream is sent a comment, so proxies and clients
+// keep the connection open
+const policyStreamHeartbeat = 15 * time.Second
+
 type PolicyController struct {
 	policyService service.IPolicyService
 }
@@ -32,17 +39,34 @@ func (pc *PolicyController) RegisterRoutes(r *gin.RouterGroup) {
 		policies.PUT("/:id", pc.UpdatePolicy)
 		policies.DELETE("/:id", pc.DeletePolicy)
 		policies.GET("/:id", pc.GetPolicy)
+		policies.HEAD("/:id", pc.PolicyExists)
 		policies.GET("", pc.ListPolicies)
 		policies.POST("/search", pc.SearchPolicies)
+		policies.POST("/batch-get", pc.BatchGetPolicies)
+		policies.POST("/import", pc.ImportPolicies)
+		policies.GET("/analyze", pc.AnalyzePolicySet)
+		policies.GET("/coverage", pc.PolicyCoverage)
+		policies.GET("/stream", pc.StreamPolicies)
 		policies.GET("/:id/usage", pc.AnalyzePolicyUsage)
+		policies.GET("/:id/subjects", pc.GetPolicyWithSubjects)
+		policies.POST("/:id/submit", pc.SubmitForApproval)
+		policies.POST("/:id/approve", pc.ApprovePolicy)
+		policies.POST("/:id/reject", pc.RejectPolicy)
+	}
+
+	templates := r.Group("/policy-templates")
+	{
+		templates.POST("", pc.CreatePolicyTemplate)
+		templates.GET("/:id", pc.GetPolicyTemplate)
+		templates.POST("/:id/instantiate", pc.InstantiatePolicy)
 	}
 }
 
 // CreatePolicy endpoint
 func (pc *PolicyController) CreatePolicy(c *gin.Context) {
 	var policy model.Policy
-	if err := c.ShouldBindJSON(&policy); err != nil {
-		util.RespondWithError(c, http.StatusBadRequest, "Invalid policy data", echo_errors.ErrInvalidPolicyData)
+	if err := util.BindJSONWithSchema(c, util.SchemaPolicy, &policy); err != nil {
+		util.RespondWithBindError(c, "Invalid policy data", err)
 		return
 	}
 	userID, err := util.GetUserIDFromContext(c)
@@ -51,30 +75,52 @@ func (pc *PolicyController) CreatePolicy(c *gin.Context) {
 		return
 	}
 
-	createdPolicy, err := pc.policyService.CreatePolicy(c, policy, userID)
+	// The policy is answered as stored, read back with the create's bookmarks so a replica serving the
+	// read has caught up with it
+	ctx := helper_util.DryRunContext(c)
+	createdPolicy, err := pc.policyService.CreatePolicyAndFetch(ctx, policy, userID)
 	if err != nil {
-		switch err {
-		case echo_errors.ErrPolicyConflict:
-			util.RespondWithError(c, http.StatusConflict, "Policy already exists", err)
-		case echo_errors.ErrDatabaseOperation:
-			util.RespondWithError(c, http.StatusInternalServerError, "Database operation failed", err)
-		case echo_errors.ErrInternalServer:
-			util.RespondWithError(c, http.StatusInternalServerError, "Internal server error", err)
-		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create policy", echo_errors.ErrInternalServer)
-		}
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	// A dry run created nothing
+	status := http.StatusCreated
+	if helper_util.IsDryRun(ctx) {
+		status = http.StatusOK
+	}
+	c.JSON(status, createdPolicy)
+}
+
+// ImportPolicies endpoint creates a list of policies in a background job, answering with the job to
+// poll for their progress
+func (pc *PolicyController) ImportPolicies(c *gin.Context) {
+	var policies []model.Policy
+	if err := util.BindJSONWithSchema(c, util.SchemaPolicyList, &policies); err != nil {
+		util.RespondWithBindError(c, "Invalid policy import, expected a list of policies", err)
+		return
+	}
+	userID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", echo_errors.ErrUnauthorized)
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdPolicy)
+	job, err := pc.policyService.ImportPolicies(helper_util.DryRunContext(c), policies, userID)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	respondWithJob(c, job)
 }
 
 // UpdatePolicy endpoint
 func (pc *PolicyController) UpdatePolicy(c *gin.Context) {
 	policyID := c.Param("id")
 	var policy model.Policy
-	if err := c.ShouldBindJSON(&policy); err != nil {
-		util.RespondWithError(c, http.StatusBadRequest, "Invalid policy data", err)
+	if err := util.BindJSONWithSchema(c, util.SchemaPolicy, &policy); err != nil {
+		util.RespondWithBindError(c, "Invalid policy data", err)
 		return
 	}
 	policy.ID = policyID
@@ -84,13 +130,10 @@ func (pc *PolicyController) UpdatePolicy(c *gin.Context) {
 		return
 	}
 
-	updatedPolicy, err := pc.policyService.UpdatePolicy(c, policy, userID)
+	ctx := helper_util.DryRunContext(c)
+	updatedPolicy, err := pc.policyService.UpdatePolicy(ctx, policy, userID)
 	if err != nil {
-		if err == echo_errors.ErrPolicyNotFound {
-			util.RespondWithError(c, http.StatusNotFound, "Policy not found", err)
-		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update policy", err)
-		}
+		util.RespondWithMappedError(c, err)
 		return
 	}
 
@@ -107,35 +150,145 @@ func (pc *PolicyController) DeletePolicy(c *gin.Context) {
 	}
 
 	if err := pc.policyService.DeletePolicy(c, policyID, userID); err != nil {
-		if err == echo_errors.ErrPolicyNotFound {
-			util.RespondWithError(c, http.StatusNotFound, "Policy not found", err)
-		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to delete policy", err)
-		}
+		util.RespondWithMappedError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// SubmitForApproval endpoint
+func (pc *PolicyController) SubmitForApproval(c *gin.Context) {
+	pc.changePolicyStatus(c, pc.policyService.SubmitForApproval)
+}
+
+// ApprovePolicy endpoint
+func (pc *PolicyController) ApprovePolicy(c *gin.Context) {
+	pc.changePolicyStatus(c, pc.policyService.ApprovePolicy)
+}
+
+// RejectPolicy endpoint
+func (pc *PolicyController) RejectPolicy(c *gin.Context) {
+	pc.changePolicyStatus(c, pc.policyService.RejectPolicy)
+}
+
+// changePolicyStatus runs a review transition on the policy, with the comment of the optional body
+func (pc *PolicyController) changePolicyStatus(c *gin.Context, transition func(ctx context.Context, policyID string, actorID string, comment string) (*model.Policy, error)) {
+	policyID := c.Param("id")
+	var request model.PolicyReviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid review data", echo_errors.ErrInvalidPolicyData)
+		return
+	}
+	actorID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", echo_errors.ErrUnauthorized)
+		return
+	}
+
+	policy, err := transition(c, policyID, actorID, request.Comment)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
 // GetPolicy endpoint
 func (pc *PolicyController) GetPolicy(c *gin.Context) {
 	policyID := c.Param("id")
 
 	policy, err := pc.policyService.GetPolicy(c, policyID)
 	if err != nil {
-		if errors.Is(err, echo_errors.ErrPolicyNotFound) {
-			util.RespondWithError(c, http.StatusNotFound, "Policy not found", err)
-		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve policy", err)
-		}
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	util.RespondWithEntity(c, policy)
+}
+
+// GetPolicyWithSubjects endpoint returns a policy along with its user subjects resolved through the graph
+func (pc *PolicyController) GetPolicyWithSubjects(c *gin.Context) {
+	policy, err := pc.policyService.GetPolicyWithSubjects(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithMappedError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, policy)
 }
 
-// ListPolicies endpoint
+// StreamPolicies endpoint pushes every policy created, updated or deleted as a Server-Sent Event named
+// after the change, such as policy.updated, carrying the policy ID and action. The stream stays open
+// until the client disconnects.
+func (pc *PolicyController) StreamPolicies(c *gin.Context) {
+	ctx := c.Request.Context()
+	changes := pc.policyService.WatchPolicyChanges(ctx)
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(policyStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			c.SSEvent("policy."+change.Action, change)
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// BatchGetPolicies endpoint returns the policies whose IDs are posted, at most service.MaxBatchGetIDs of them,
+// along with the IDs not found
+func (pc *PolicyController) BatchGetPolicies(c *gin.Context) {
+	var request model.BatchGetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid batch get request", echo_errors.ErrInvalidSearchCriteria)
+		return
+	}
+
+	batch, err := pc.policyService.GetPoliciesByIDs(c, request.IDs)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// PolicyExists endpoint answers HEAD requests with 200 or 404 and no body
+func (pc *PolicyController) PolicyExists(c *gin.Context) {
+	exists, err := pc.policyService.PolicyExists(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to check policy", err)
+		return
+	}
+	if !exists {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ListPolicies endpoint. Passing a cursor parameter, empty for the first page, switches to cursor
+// pagination and wraps the policies in a page carrying next_cursor.
 func (pc *PolicyController) ListPolicies(c *gin.Context) {
 	limit, offset, err := helper_util.GetPaginationParams(c)
 	if err != nil {
@@ -143,6 +296,20 @@ func (pc *PolicyController) ListPolicies(c *gin.Context) {
 		return
 	}
 
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		page, err := pc.policyService.ListPoliciesByCursor(c, cursor, limit)
+		if err != nil {
+			if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+				util.RespondWithError(c, http.StatusBadRequest, "Invalid cursor", err)
+			} else {
+				util.RespondWithError(c, http.StatusInternalServerError, "Failed to list policies", err)
+			}
+			return
+		}
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
 	policies, err := pc.policyService.ListPolicies(c, limit, offset)
 	if err != nil {
 		util.RespondWithError(c, http.StatusInternalServerError, "Failed to list policies", err)
@@ -162,7 +329,11 @@ func (pc *PolicyController) SearchPolicies(c *gin.Context) {
 
 	policies, err := pc.policyService.SearchPolicies(c, criteria)
 	if err != nil {
-		util.RespondWithError(c, http.StatusInternalServerError, "Failed to search policies", err)
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to search policies", err)
+		}
 		return
 	}
 
@@ -181,3 +352,110 @@ func (pc *PolicyController) AnalyzePolicyUsage(c *gin.Context) {
 
 	c.JSON(http.StatusOK, analysis)
 }
+
+// AnalyzePolicySet endpoint reports unreachable and duplicate policies and conditions whose outcome is fixed
+func (pc *PolicyController) AnalyzePolicySet(c *gin.Context) {
+	analysis, err := pc.policyService.AnalyzePolicySet(c)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// PolicyCoverage endpoint reports the policy set of the organization given by orgId and the resources
+// and users it leaves uncovered
+func (pc *PolicyController) PolicyCoverage(c *gin.Context) {
+	orgID := c.Query("orgId")
+	if orgID == "" {
+		util.RespondWithError(c, http.StatusBadRequest, "Organization ID is required", echo_errors.ErrInvalidOrganizationData)
+		return
+	}
+
+	report, err := pc.policyService.CoverageReport(c, orgID)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// CreatePolicyTemplate endpoint
+func (pc *PolicyController) CreatePolicyTemplate(c *gin.Context) {
+	var template model.PolicyTemplate
+	if err := c.ShouldBindJSON(&template); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid policy template data", echo_errors.ErrInvalidPolicyData)
+		return
+	}
+	userID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", echo_errors.ErrUnauthorized)
+		return
+	}
+
+	createdTemplate, err := pc.policyService.CreatePolicyTemplate(c, template, userID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrInvalidPolicyData) {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid policy template", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create policy template", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdTemplate)
+}
+
+// GetPolicyTemplate endpoint
+func (pc *PolicyController) GetPolicyTemplate(c *gin.Context) {
+	templateID := c.Param("id")
+
+	template, err := pc.policyService.GetPolicyTemplate(c, templateID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrPolicyTemplateNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, "Policy template not found", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve policy template", err)
+		}
+		return
+	}
+
+	util.RespondWithEntity(c, template)
+}
+
+// InstantiatePolicy endpoint
+func (pc *PolicyController) InstantiatePolicy(c *gin.Context) {
+	templateID := c.Param("id")
+	var request model.InstantiatePolicyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid instantiation data", echo_errors.ErrInvalidPolicyData)
+		return
+	}
+	userID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", echo_errors.ErrUnauthorized)
+		return
+	}
+
+	policy, err := pc.policyService.InstantiatePolicy(c, templateID, request.Variables, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, echo_errors.ErrPolicyTemplateNotFound):
+			util.RespondWithError(c, http.StatusNotFound, "Policy template not found", err)
+		case errors.Is(err, echo_errors.ErrMissingTemplateVariables):
+			util.RespondWithError(c, http.StatusBadRequest, "Missing template variables", err)
+		case errors.Is(err, echo_errors.ErrPolicyConflict):
+			util.RespondWithError(c, http.StatusConflict, "Policy already exists", err)
+		case errors.Is(err, echo_errors.ErrForbidden):
+			util.RespondWithError(c, http.StatusForbidden, "Policy belongs to another organization", err)
+		default:
+			// Such as a template instantiating to an invalid policy
+			util.RespondWithMappedError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, policy)
+}
@@ -2,7 +2,10 @@
 package controller_test
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,10 +13,14 @@ import (
 	"time"
 
 	"github.com/dev-mohitbeniwal/echo/api/controller"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/service"
 	mock_service "github.com/dev-mohitbeniwal/echo/api/test/service_mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -40,7 +47,7 @@ func TestPolicyController(t *testing.T) {
 
 	t.Run("CreatePolicy_Success", func(t *testing.T) {
 		mockPolicyService.EXPECT().
-			CreatePolicy(gomock.Any(), gomock.Any(), gomock.Any()).
+			CreatePolicyAndFetch(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(&model.Policy{ID: "1", Name: "Test Policy"}, nil)
 
 		body := strings.NewReader(`{"name":"Test Policy"}`)
@@ -51,6 +58,90 @@ func TestPolicyController(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, w.Code)
 	})
 
+	t.Run("CreatePolicy_DryRun", func(t *testing.T) {
+		mockPolicyService.EXPECT().
+			CreatePolicyAndFetch(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error) {
+				assert.True(t, helper_util.IsDryRun(ctx))
+				return &policy, nil
+			})
+
+		body := strings.NewReader(`{"name":"Test Policy"}`)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/policies?dryRun=true", body)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("CreatePolicy_SchemaViolation", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Test Policy","effect":"permit","subjects":[{"type":"robot"}]}`)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/policies", body)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response util.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, util.CodeValidationFailed, response.Error.Code)
+		assert.Equal(t, "Invalid policy data", response.Error.Message)
+		if assert.Len(t, response.Error.Details, 2) {
+			assert.Equal(t, "/effect", response.Error.Details[0].Field)
+			assert.Equal(t, "/subjects/0/type", response.Error.Details[1].Field)
+		}
+	})
+
+	t.Run("CreatePolicy_ValidationFailure", func(t *testing.T) {
+		invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidPolicyData)
+		invalid.Add("subjects", "must have at least one subject")
+		mockPolicyService.EXPECT().
+			CreatePolicyAndFetch(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("invalid policy: %w", invalid.Err()))
+
+		body := strings.NewReader(`{"name":"Test Policy"}`)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/policies", body)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response util.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, util.CodeValidationFailed, response.Error.Code)
+		assert.Equal(t, []echo_errors.FieldError{{Field: "subjects", Reason: "must have at least one subject"}}, response.Error.Details)
+	})
+
+	t.Run("CreatePolicy_DatabaseFailure", func(t *testing.T) {
+		mockPolicyService.EXPECT().
+			CreatePolicyAndFetch(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("failed to create policy: %w", echo_errors.ErrDatabaseOperation))
+
+		body := strings.NewReader(`{"name":"Test Policy"}`)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/policies", body)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		var response util.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "DATABASE_ERROR", response.Error.Code)
+		assert.Empty(t, response.Error.Details)
+	})
+
+	t.Run("ImportPolicies_SchemaViolation", func(t *testing.T) {
+		body := strings.NewReader(`[{"name":"Docs","effect":"allow"},{"name":"Wiki","effect":"permit"}]`)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/policies/import", body)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response util.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, util.CodeValidationFailed, response.Error.Code)
+		if assert.Len(t, response.Error.Details, 1) {
+			assert.Equal(t, "/1/effect", response.Error.Details[0].Field)
+		}
+	})
+
 	t.Run("UpdatePolicy_Success", func(t *testing.T) {
 		mockPolicyService.EXPECT().
 			UpdatePolicy(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -113,6 +204,66 @@ func TestPolicyController(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
+	t.Run("GetPolicy_ConditionalGet", func(t *testing.T) {
+		policy := &model.Policy{ID: "1", Name: "Test Policy", Version: 1}
+		mockPolicyService.EXPECT().
+			GetPolicy(gomock.Any(), "1").
+			Return(policy, nil).
+			Times(3)
+
+		get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/policies/1", nil)
+			if ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", ifNoneMatch)
+			}
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		first := get("")
+		etag := first.Header().Get("ETag")
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.NotEmpty(t, etag)
+
+		cached := get(etag)
+		assert.Equal(t, http.StatusNotModified, cached.Code)
+		assert.Empty(t, cached.Body.String())
+
+		// Once the policy changes, the old tag no longer matches
+		policy.Version = 2
+		policy.UpdatedAt = time.Now()
+		stale := get(etag)
+		assert.Equal(t, http.StatusOK, stale.Code)
+		assert.NotEqual(t, etag, stale.Header().Get("ETag"))
+		var body model.Policy
+		assert.NoError(t, json.Unmarshal(stale.Body.Bytes(), &body))
+		assert.Equal(t, 2, body.Version)
+	})
+
+	t.Run("GetPolicyWithSubjects_Success", func(t *testing.T) {
+		mockPolicyService.EXPECT().
+			GetPolicyWithSubjects(gomock.Any(), "1").
+			Return(&model.PolicyWithSubjects{
+				Policy: &model.Policy{ID: "1", Name: "Test Policy"},
+				ResolvedSubjects: []model.ResolvedSubject{{
+					Subject: model.Subject{Type: "user", UserID: "u1"},
+					User:    &model.User{ID: "u1"},
+					Roles:   []*model.Role{{ID: "editor"}},
+				}},
+			}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/policies/1/subjects", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "Test Policy", body["name"])
+		assert.Len(t, body["resolved_subjects"], 1)
+	})
+
 	t.Run("GetPolicy_Failure_NotFound", func(t *testing.T) {
 		mockPolicyService.EXPECT().
 			GetPolicy(gomock.Any(), gomock.Any()).
@@ -125,6 +276,32 @@ func TestPolicyController(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 
+	t.Run("HeadPolicy_Exists", func(t *testing.T) {
+		mockPolicyService.EXPECT().
+			PolicyExists(gomock.Any(), "1").
+			Return(true, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("HEAD", "/policies/1", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("HeadPolicy_Absent", func(t *testing.T) {
+		mockPolicyService.EXPECT().
+			PolicyExists(gomock.Any(), "missing").
+			Return(false, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("HEAD", "/policies/missing", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
 	t.Run("ListPolicies_Success", func(t *testing.T) {
 		policies := []*model.Policy{
 			{ID: "1", Name: "Policy 1"},
@@ -198,4 +375,102 @@ func TestPolicyController(t *testing.T) {
 		assert.True(t, analysis.LastUpdatedAt.Equal(responseAnalysis.LastUpdatedAt))
 	})
 
+	t.Run("PolicyCoverage_Success", func(t *testing.T) {
+		mockPolicyService.EXPECT().
+			CoverageReport(gomock.Any(), "org1").
+			Return(&model.CoverageReport{OrganizationID: "org1", PolicyCount: 2, UncoveredResourceIDs: []string{"r2"}}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/policies/coverage?orgId=org1", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var report model.CoverageReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, 2, report.PolicyCount)
+		assert.Equal(t, []string{"r2"}, report.UncoveredResourceIDs)
+	})
+
+	t.Run("InstantiatePolicy_InvalidPolicy", func(t *testing.T) {
+		mockPolicyService.EXPECT().
+			InstantiatePolicy(gomock.Any(), "tpl1", map[string]string{"role": "editor"}, gomock.Any()).
+			Return(nil, fmt.Errorf("%w: effect must be allow or deny", echo_errors.ErrInvalidPolicyData))
+
+		body := strings.NewReader(`{"variables":{"role":"editor"}}`)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/policy-templates/tpl1/instantiate", body)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response util.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_POLICY_DATA", response.Error.Code)
+	})
+
+	t.Run("PolicyCoverage_MissingOrganization", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/policies/coverage", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+}
+
+func TestStreamPolicies(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	eventBus := util.NewEventBus()
+	policyService := service.NewPolicyService(&dao.PolicyDAO{}, nil, nil, util.NewValidationUtil(), nil, util.NewNotificationService(0, nil), eventBus, nil)
+	router := setupRouter()
+	controller.NewPolicyController(policyService).RegisterRoutes(router.Group("/"))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/policies/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The headers arrive once the stream is watching, so the event cannot be missed
+	eventBus.Publish(context.WithValue(context.Background(), "requestingUserID", "admin"), "policy.deleted", "p1")
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	var event, data string
+	for event == "" || data == "" {
+		select {
+		case line, ok := <-lines:
+			if !assert.True(t, ok, "stream ended before the event arrived") {
+				return
+			}
+			if value, found := strings.CutPrefix(line, "event:"); found {
+				event = value
+			} else if value, found := strings.CutPrefix(line, "data:"); found {
+				data = value
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("event not delivered")
+		}
+	}
+
+	assert.Equal(t, "policy.deleted", event)
+	var change model.PolicyChange
+	assert.NoError(t, json.Unmarshal([]byte(data), &change))
+	assert.Equal(t, "p1", change.PolicyID)
+	assert.Equal(t, "deleted", change.Action)
+	assert.Equal(t, "admin", change.Actor)
 }
@@ -2,12 +2,18 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/service"
 	"github.com/dev-mohitbeniwal/echo/api/util"
@@ -32,16 +38,28 @@ func (rc *ResourceController) RegisterRoutes(r *gin.RouterGroup) {
 		resources.PUT("/:id", rc.UpdateResource)
 		resources.DELETE("/:id", rc.DeleteResource)
 		resources.GET("/:id", rc.GetResource)
+		resources.HEAD("/:id", rc.ResourceExists)
 		resources.GET("", rc.ListResources)
+		resources.GET("/export", rc.ExportResources)
+		resources.GET("/classified", rc.GetResourcesByMinClassification)
 		resources.POST("/search", rc.SearchResources)
+		resources.POST("/batch-get", rc.BatchGetResources)
+		resources.POST("/:id/tags", rc.AddResourceTags)
+		resources.DELETE("/:id/tags", rc.RemoveResourceTags)
+		resources.POST("/bulk-tag", rc.BulkTagResources)
+		resources.POST("/:id/transfer-ownership", rc.TransferResourceOwnership)
+		resources.GET("/:id/diff", rc.GetResourceDiff)
 	}
+
+	r.GET("/tags", rc.ListAllTags)
+	r.GET("/organizations/:id/resources", rc.GetResourcesByOrganization)
 }
 
 // CreateResource endpoint
 func (rc *ResourceController) CreateResource(c *gin.Context) {
 	var resource model.Resource
-	if err := c.ShouldBindJSON(&resource); err != nil {
-		util.RespondWithError(c, http.StatusBadRequest, "Invalid resource data", echo_errors.ErrInvalidResourceData)
+	if err := util.BindJSONWithSchema(c, util.SchemaResource, &resource); err != nil {
+		util.RespondWithBindError(c, "Invalid resource data", err)
 		return
 	}
 	creatorID, err := util.GetUserIDFromContext(c)
@@ -50,30 +68,46 @@ func (rc *ResourceController) CreateResource(c *gin.Context) {
 		return
 	}
 
-	createdResource, err := rc.resourceService.CreateResource(c, resource, creatorID)
+	ctx := helper_util.DryRunContext(c)
+	createdResource, err := rc.resourceService.CreateResource(ctx, resource, creatorID)
 	if err != nil {
+		if errors.Is(err, echo_errors.ErrAttributeSchemaViolation) || errors.Is(err, echo_errors.ErrInvalidResourceType) {
+			util.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
 		switch err {
+		case echo_errors.ErrOrganizationNotFound, echo_errors.ErrOwnerNotFound, echo_errors.ErrResourceTypeNotFound,
+			echo_errors.ErrAttributeGroupNotFound, echo_errors.ErrDepartmentNotFound, echo_errors.ErrParentResourceNotFound,
+			echo_errors.ErrRelatedResourceNotFound:
+			util.RespondWithError(c, http.StatusBadRequest, "Referenced entity does not exist: "+err.Error(), err)
 		case echo_errors.ErrResourceConflict:
 			util.RespondWithError(c, http.StatusConflict, "Resource already exists", err)
+		case echo_errors.ErrForbidden:
+			util.RespondWithError(c, http.StatusForbidden, "Resource is outside your organization or admin scope", err)
 		case echo_errors.ErrDatabaseOperation:
 			util.RespondWithError(c, http.StatusInternalServerError, "Database operation failed", err)
 		case echo_errors.ErrInternalServer:
 			util.RespondWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create resource", echo_errors.ErrInternalServer)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdResource)
+	// A dry run created nothing
+	status := http.StatusCreated
+	if helper_util.IsDryRun(ctx) {
+		status = http.StatusOK
+	}
+	c.JSON(status, createdResource)
 }
 
 // UpdateResource endpoint
 func (rc *ResourceController) UpdateResource(c *gin.Context) {
 	resourceID := c.Param("id")
 	var resource model.Resource
-	if err := c.ShouldBindJSON(&resource); err != nil {
-		util.RespondWithError(c, http.StatusBadRequest, "Invalid resource data", err)
+	if err := util.BindJSONWithSchema(c, util.SchemaResource, &resource); err != nil {
+		util.RespondWithBindError(c, "Invalid resource data", err)
 		return
 	}
 	resource.ID = resourceID
@@ -83,12 +117,21 @@ func (rc *ResourceController) UpdateResource(c *gin.Context) {
 		return
 	}
 
-	updatedResource, err := rc.resourceService.UpdateResource(c, resource, updaterID)
+	ctx := helper_util.DryRunContext(c)
+	updatedResource, err := rc.resourceService.UpdateResource(ctx, resource, updaterID)
 	if err != nil {
 		if err == echo_errors.ErrResourceNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Resource not found", err)
+		} else if errors.Is(err, echo_errors.ErrAttributeSchemaViolation) || errors.Is(err, echo_errors.ErrInvalidResourceType) {
+			util.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		} else if err == echo_errors.ErrAttributeGroupNotFound {
+			util.RespondWithError(c, http.StatusBadRequest, "Attribute group not found", err)
+		} else if err == echo_errors.ErrResourceTypeNotFound {
+			util.RespondWithError(c, http.StatusBadRequest, "Resource type not found", err)
+		} else if err == echo_errors.ErrForbidden {
+			util.RespondWithError(c, http.StatusForbidden, "Resource is outside your organization or admin scope", err)
 		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update resource", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -108,6 +151,8 @@ func (rc *ResourceController) DeleteResource(c *gin.Context) {
 	if err := rc.resourceService.DeleteResource(c, resourceID, deleterID); err != nil {
 		if err == echo_errors.ErrResourceNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Resource not found", err)
+		} else if err == echo_errors.ErrForbidden {
+			util.RespondWithError(c, http.StatusForbidden, "Resource is outside your organization or admin scope", err)
 		} else {
 			util.RespondWithError(c, http.StatusInternalServerError, "Failed to delete resource", err)
 		}
@@ -125,16 +170,52 @@ func (rc *ResourceController) GetResource(c *gin.Context) {
 	if err != nil {
 		if errors.Is(err, echo_errors.ErrResourceNotFound) {
 			util.RespondWithError(c, http.StatusNotFound, "Resource not found", err)
+		} else if errors.Is(err, echo_errors.ErrForbidden) {
+			util.RespondWithError(c, http.StatusForbidden, "Resource is outside your organization or admin scope", err)
 		} else {
 			util.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve resource", err)
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, resource)
+	util.RespondWithEntity(c, resource)
+}
+
+// ResourceExists endpoint answers HEAD requests with 200 or 404 and no body
+func (rc *ResourceController) ResourceExists(c *gin.Context) {
+	exists, err := rc.resourceService.ResourceExists(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to check resource", err)
+		return
+	}
+	if !exists {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// BatchGetResources endpoint returns the resources whose IDs are posted, at most service.MaxBatchGetIDs of them,
+// along with the IDs not found
+func (rc *ResourceController) BatchGetResources(c *gin.Context) {
+	var request model.BatchGetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid batch get request", echo_errors.ErrInvalidSearchCriteria)
+		return
+	}
+
+	batch, err := rc.resourceService.GetResourcesByIDs(c, request.IDs)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
 }
 
-// ListResources endpoint
+// ListResources endpoint. Passing a cursor parameter, empty for the first page, switches to cursor
+// pagination and wraps the resources in a page carrying next_cursor.
 func (rc *ResourceController) ListResources(c *gin.Context) {
 	limit, offset, err := helper_util.GetPaginationParams(c)
 	if err != nil {
@@ -142,15 +223,132 @@ func (rc *ResourceController) ListResources(c *gin.Context) {
 		return
 	}
 
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		page, err := rc.resourceService.ListResourcesByCursor(c, cursor, limit)
+		if err != nil {
+			if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+				util.RespondWithError(c, http.StatusBadRequest, "Invalid cursor", err)
+			} else {
+				util.RespondWithMappedError(c, err)
+			}
+			return
+		}
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
 	resources, err := rc.resourceService.ListResources(c, limit, offset)
 	if err != nil {
-		util.RespondWithError(c, http.StatusInternalServerError, "Failed to list resources", err)
+		util.RespondWithMappedError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, resources)
 }
 
+var resourceCSVHeader = []string{
+	"id", "name", "type", "type_id", "organization_id", "department_id", "owner_id", "status", "version",
+	"sensitivity", "classification", "tags", "created_by", "updated_by", "created_at", "updated_at",
+}
+
+// ExportResources endpoint streams the resources matching the search criteria given as query parameters
+// as a CSV download
+func (rc *ResourceController) ExportResources(c *gin.Context) {
+	if !checkExportFormat(c) {
+		return
+	}
+	criteria, ok := resourceExportCriteria(c)
+	if !ok {
+		return
+	}
+
+	stream := newCSVStream(c, "resources.csv", resourceCSVHeader)
+	err := rc.resourceService.ExportResources(c, criteria, func(resource *model.Resource) error {
+		return stream.write([]string{
+			resource.ID,
+			resource.Name,
+			resource.Type,
+			resource.TypeID,
+			resource.OrganizationID,
+			resource.DepartmentID,
+			resource.OwnerID,
+			resource.Status,
+			strconv.Itoa(resource.Version),
+			resource.Sensitivity,
+			resource.Classification,
+			strings.Join(resource.Tags, ";"),
+			resource.CreatedBy,
+			resource.UpdatedBy,
+			formatExportTime(resource.CreatedAt),
+			formatExportTime(resource.UpdatedAt),
+		})
+	})
+	if err == nil {
+		err = stream.close()
+	}
+	if err != nil {
+		if !stream.started() {
+			switch {
+			case errors.Is(err, echo_errors.ErrInvalidSearchCriteria):
+				util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
+			case errors.Is(err, echo_errors.ErrForbidden):
+				util.RespondWithError(c, http.StatusForbidden, "Criteria reach beyond your organization or admin scope", err)
+			default:
+				util.RespondWithError(c, http.StatusInternalServerError, "Failed to export resources", err)
+			}
+			return
+		}
+		// The download is under way, so the client can only notice the truncated file
+		logger.Error("Resource export aborted", zap.Error(err), zap.Int("rows", stream.rows))
+	}
+}
+
+// resourceExportCriteria reads the search criteria of a resource export from its query parameters, named
+// as the fields of a search's JSON body. Tags may be repeated, attributes are given as attributes[key]
+// and times in RFC3339. It answers the request itself when a time is invalid.
+func resourceExportCriteria(c *gin.Context) (model.ResourceSearchCriteria, bool) {
+	criteria := model.ResourceSearchCriteria{
+		ID:             c.Query("id"),
+		Name:           c.Query("name"),
+		Type:           c.Query("type"),
+		OrganizationID: c.Query("organization_id"),
+		DepartmentID:   c.Query("department_id"),
+		OwnerID:        c.Query("owner_id"),
+		Status:         c.Query("status"),
+		Sensitivity:    c.Query("sensitivity"),
+		Classification: c.Query("classification"),
+		Tags:           c.QueryArray("tags"),
+	}
+	if attributes := c.QueryMap("attributes"); len(attributes) > 0 {
+		criteria.Attributes = make(map[string]interface{}, len(attributes))
+		for key, value := range attributes {
+			criteria.Attributes[key] = value
+		}
+	}
+
+	for _, bound := range []struct {
+		name  string
+		field **time.Time
+	}{
+		{"created_after", &criteria.CreatedAfter},
+		{"created_before", &criteria.CreatedBefore},
+		{"updated_after", &criteria.UpdatedAfter},
+		{"updated_before", &criteria.UpdatedBefore},
+	} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := helper_util.ParseTime(value)
+		if err != nil {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid "+bound.name+" time, expected RFC3339", echo_errors.ErrInvalidSearchCriteria)
+			return criteria, false
+		}
+		*bound.field = &parsed
+	}
+	return criteria, true
+}
+
 // SearchResources endpoint
 func (rc *ResourceController) SearchResources(c *gin.Context) {
 	var criteria model.ResourceSearchCriteria
@@ -162,9 +360,213 @@ func (rc *ResourceController) SearchResources(c *gin.Context) {
 
 	resources, err := rc.resourceService.SearchResources(c, criteria)
 	if err != nil {
-		util.RespondWithError(c, http.StatusInternalServerError, "Failed to search resources", err)
+		switch {
+		case errors.Is(err, echo_errors.ErrInvalidSearchCriteria):
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
+		case errors.Is(err, echo_errors.ErrForbidden):
+			util.RespondWithError(c, http.StatusForbidden, "Criteria reach beyond your organization or admin scope", err)
+		default:
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to search resources", err)
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, resources)
 }
+
+// AddResourceTags endpoint
+func (rc *ResourceController) AddResourceTags(c *gin.Context) {
+	rc.updateResourceTags(c, rc.resourceService.AddResourceTags)
+}
+
+// RemoveResourceTags endpoint
+func (rc *ResourceController) RemoveResourceTags(c *gin.Context) {
+	rc.updateResourceTags(c, rc.resourceService.RemoveResourceTags)
+}
+
+func (rc *ResourceController) updateResourceTags(c *gin.Context, update func(ctx context.Context, resourceID string, tags []string, updaterID string) ([]string, error)) {
+	resourceID := c.Param("id")
+	var request model.ResourceTags
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid tag data", echo_errors.ErrInvalidResourceData)
+		return
+	}
+	updaterID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	tags, err := update(c, resourceID, request.Tags, updaterID)
+	if err != nil {
+		switch {
+		case errors.Is(err, echo_errors.ErrResourceNotFound):
+			util.RespondWithError(c, http.StatusNotFound, "Resource not found", err)
+		case errors.Is(err, echo_errors.ErrInvalidResourceData):
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid tag data", err)
+		case errors.Is(err, echo_errors.ErrForbidden):
+			util.RespondWithError(c, http.StatusForbidden, "Resource is outside your organization or admin scope", err)
+		default:
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update resource tags", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ResourceTags{Tags: tags})
+}
+
+// ListAllTags endpoint
+func (rc *ResourceController) ListAllTags(c *gin.Context) {
+	tagCounts, err := rc.resourceService.ListAllTags(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to list tags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tagCounts)
+}
+
+// GetResourcesByMinClassification endpoint
+func (rc *ResourceController) GetResourcesByMinClassification(c *gin.Context) {
+	level := c.Query("min_level")
+	if level == "" {
+		util.RespondWithError(c, http.StatusBadRequest, "min_level is required", echo_errors.ErrInvalidSearchCriteria)
+		return
+	}
+
+	resources, err := rc.resourceService.GetResourcesByMinClassification(c, level)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resources)
+}
+
+// BulkTagResources endpoint
+func (rc *ResourceController) BulkTagResources(c *gin.Context) {
+	var request model.BulkTagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid bulk tag data", echo_errors.ErrInvalidResourceData)
+		return
+	}
+	userID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	affected, err := rc.resourceService.BulkTagResources(c, request.Criteria, request.Tags, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, echo_errors.ErrInvalidSearchCriteria), errors.Is(err, echo_errors.ErrInvalidResourceData):
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid bulk tag request", err)
+		case errors.Is(err, echo_errors.ErrBulkLimitExceeded):
+			util.RespondWithError(c, http.StatusUnprocessableEntity, "Criteria match too many resources", err)
+		case errors.Is(err, echo_errors.ErrForbidden):
+			util.RespondWithError(c, http.StatusForbidden, "Criteria reach beyond your organization or admin scope", err)
+		default:
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to bulk tag resources", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.BulkTagResult{Affected: affected})
+}
+
+// TransferResourceOwnership endpoint
+func (rc *ResourceController) TransferResourceOwnership(c *gin.Context) {
+	resourceID := c.Param("id")
+	var request model.OwnershipTransferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid ownership transfer data", echo_errors.ErrInvalidResourceData)
+		return
+	}
+	userID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if err := rc.resourceService.TransferResourceOwnership(c, resourceID, request.NewOwnerID, userID); err != nil {
+		switch {
+		case errors.Is(err, echo_errors.ErrResourceNotFound):
+			util.RespondWithError(c, http.StatusNotFound, "Resource not found", err)
+		case errors.Is(err, echo_errors.ErrUserNotFound):
+			util.RespondWithError(c, http.StatusBadRequest, "New owner not found", err)
+		case errors.Is(err, echo_errors.ErrInvalidResourceData):
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid ownership transfer data", err)
+		case errors.Is(err, echo_errors.ErrForbidden):
+			util.RespondWithError(c, http.StatusForbidden, "Resource is outside your organization or admin scope", err)
+		default:
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to transfer resource ownership", err)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetResourcesByOrganization endpoint
+func (rc *ResourceController) GetResourcesByOrganization(c *gin.Context) {
+	orgID := c.Param("id")
+	limit, offset, err := helper_util.GetPaginationParams(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	includeSubOrgs, err := strconv.ParseBool(c.DefaultQuery("includeSubOrgs", "false"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid includeSubOrgs parameter", err)
+		return
+	}
+
+	resources, total, err := rc.resourceService.GetResourcesByOrganization(c, orgID, includeSubOrgs, limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, echo_errors.ErrOrganizationNotFound):
+			util.RespondWithError(c, http.StatusNotFound, "Organization not found", err)
+		case errors.Is(err, echo_errors.ErrInvalidSearchCriteria):
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters", err)
+		case errors.Is(err, echo_errors.ErrForbidden):
+			util.RespondWithError(c, http.StatusForbidden, "Organization is not the requesting user's", err)
+		default:
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to list organization resources", err)
+		}
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, resources)
+}
+
+// GetResourceDiff endpoint compares two versions of a resource, given as the from and to query
+// parameters
+func (rc *ResourceController) GetResourceDiff(c *gin.Context) {
+	fromVersion, fromErr := strconv.Atoi(c.Query("from"))
+	toVersion, toErr := strconv.Atoi(c.Query("to"))
+	if fromErr != nil || toErr != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid from or to version", echo_errors.ErrInvalidResourceData)
+		return
+	}
+
+	diff, err := rc.resourceService.GetResourceDiff(c, c.Param("id"), fromVersion, toVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, echo_errors.ErrResourceNotFound):
+			util.RespondWithError(c, http.StatusNotFound, "Resource not found", err)
+		case errors.Is(err, echo_errors.ErrResourceVersionNotFound):
+			util.RespondWithError(c, http.StatusNotFound, "Resource version not found", err)
+		case errors.Is(err, echo_errors.ErrInvalidResourceData):
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid from or to version", err)
+		case errors.Is(err, echo_errors.ErrForbidden):
+			util.RespondWithError(c, http.StatusForbidden, "Resource is outside your organization or admin scope", err)
+		default:
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to compare resource versions", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
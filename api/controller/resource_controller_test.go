@@ -0,0 +1,108 @@
+// api/controller/resource_controller_test.go
+package controller_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/controller"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+func exportedResource(id, name, createdAt string) *neo4j.Record {
+	return &neo4j.Record{
+		Keys: []string{"r", "organizationID", "departmentID", "ownerID"},
+		Values: []any{mock.ResourceNode(id, map[string]any{
+			"name":        name,
+			"version":     int64(2),
+			"sensitivity": "internal",
+			"createdAt":   createdAt,
+			"updatedAt":   createdAt,
+		}), "org1", nil, "u1"},
+	}
+}
+
+func TestResourceController(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	t.Run("ExportResources_CSV", func(t *testing.T) {
+		// Two resources per page, so the export has to follow the cursor to the second page
+		helper_util.SetMaxPageLimit(2)
+		defer helper_util.SetMaxPageLimit(helper_util.DefaultMaxPageLimit)
+
+		firstPage := &mock.MockResult{}
+		firstPage.On("Next").Return(true).Times(3)
+		firstPage.On("Next").Return(false)
+		firstPage.On("Record").Return(exportedResource("r3", "Roadmap", "2024-03-01T00:00:00Z")).Once()
+		firstPage.On("Record").Return(exportedResource("r2", "Budget, 2024", "2024-02-01T00:00:00Z")).Once()
+		firstPage.On("Record").Return(exportedResource("r1", "Handbook", "2024-01-01T00:00:00Z")).Once()
+		secondPage := &mock.MockResult{}
+		secondPage.On("Next").Return(true).Once()
+		secondPage.On("Next").Return(false)
+		secondPage.On("Record").Return(exportedResource("r1", "Handbook", "2024-01-01T00:00:00Z"))
+
+		session := &mock.MockSession{}
+		session.On("Run", testify_mock.Anything, testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["afterID"] == nil
+		}), testify_mock.Anything).Return(firstPage, nil)
+		session.On("Run", testify_mock.Anything, testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["afterID"] == "r2"
+		}), testify_mock.Anything).Return(secondPage, nil)
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		resourceService := mock.NewResourceService(driver, nil)
+		router := setupRouter()
+		controller.NewResourceController(resourceService).RegisterRoutes(router.Group("/"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/resources/export?format=csv", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="resources.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,name,type,type_id,organization_id,department_id,owner_id,status,version,sensitivity,classification,tags,created_by,updated_by,created_at,updated_at\n"+
+			"r3,Roadmap,DOCUMENT,rt1,org1,,u1,active,2,internal,,,u1,u1,2024-03-01T00:00:00Z,2024-03-01T00:00:00Z\n"+
+			`r2,"Budget, 2024",DOCUMENT,rt1,org1,,u1,active,2,internal,,,u1,u1,2024-02-01T00:00:00Z,2024-02-01T00:00:00Z`+"\n"+
+			"r1,Handbook,DOCUMENT,rt1,org1,,u1,active,2,internal,,,u1,u1,2024-01-01T00:00:00Z,2024-01-01T00:00:00Z\n", w.Body.String())
+	})
+
+	t.Run("ExportResources_Filters", func(t *testing.T) {
+		driver := mock.NewFakeDriver()
+		router := setupRouter()
+		controller.NewResourceController(mock.NewResourceService(driver, nil)).RegisterRoutes(router.Group("/"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/resources/export?format=csv&type=DOCUMENT&tags=finance&tags=q3&created_after=2024-01-01T00:00:00Z", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		queries := driver.QueriesContaining("r.createdAt < $afterCreatedAt")
+		if assert.Len(t, queries, 1) {
+			assert.Equal(t, "DOCUMENT", queries[0].Params["type"])
+			assert.Equal(t, []string{"finance", "q3"}, queries[0].Params["tags"])
+			assert.Equal(t, "2024-01-01T00:00:00Z", queries[0].Params["createdAfter"])
+		}
+	})
+
+	t.Run("ExportResources_InvalidTime", func(t *testing.T) {
+		driver := mock.NewFakeDriver()
+		router := setupRouter()
+		controller.NewResourceController(mock.NewResourceService(driver, nil)).RegisterRoutes(router.Group("/"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/resources/export?format=csv&updated_before=yesterday", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, driver.Queries())
+	})
+}
@@ -57,7 +57,7 @@ func (rtc *ResourceTypeController) CreateResourceType(c *gin.Context) {
 		case errors.Is(err, echo_errors.ErrDatabaseOperation):
 			util.RespondWithError(c, http.StatusInternalServerError, "Database operation failed", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create resource type", echo_errors.ErrInternalServer)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -88,7 +88,7 @@ func (rtc *ResourceTypeController) UpdateResourceType(c *gin.Context) {
 		case errors.Is(err, echo_errors.ErrInvalidResourceType):
 			util.RespondWithError(c, http.StatusBadRequest, "Invalid resource type", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update resource type", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -133,7 +133,7 @@ func (rtc *ResourceTypeController) GetResourceType(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resourceType)
+	util.RespondWithEntity(c, resourceType)
 }
 
 // ListResourceTypes endpoint
@@ -2,8 +2,10 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -34,6 +36,12 @@ func (rc *RoleController) RegisterRoutes(r *gin.RouterGroup) {
 		roles.GET("/:id", rc.GetRole)
 		roles.GET("", rc.ListRoles)
 		roles.GET("/search", rc.SearchRoles)
+		roles.GET("/:id/users", rc.GetUsersByRole)
+		roles.GET("/:id/groups", rc.GetGroupsByRole)
+		roles.GET("/:id/usage", rc.AnalyzeRoleUsage)
+		roles.POST("/:id/assign", rc.AssignRoleToUsers)
+		roles.POST("/:id/unassign", rc.UnassignRoleFromUsers)
+		roles.PUT("/:id/permissions", rc.UpdateRolePermissions)
 	}
 }
 
@@ -60,7 +68,7 @@ func (rc *RoleController) CreateRole(c *gin.Context) {
 		case echo_errors.ErrInternalServer:
 			util.RespondWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create role", echo_errors.ErrInternalServer)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -88,7 +96,7 @@ func (rc *RoleController) UpdateRole(c *gin.Context) {
 		if err == echo_errors.ErrRoleNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Role not found", err)
 		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update role", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -105,9 +113,17 @@ func (rc *RoleController) DeleteRole(c *gin.Context) {
 		return
 	}
 
-	if err := rc.roleService.DeleteRole(c, roleID, deleterID); err != nil {
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid force parameter", err)
+		return
+	}
+
+	if err := rc.roleService.DeleteRole(c, roleID, deleterID, force); err != nil {
 		if err == echo_errors.ErrRoleNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "Role not found", err)
+		} else if err == echo_errors.ErrRoleInUse {
+			util.RespondWithError(c, http.StatusConflict, "Role is still in use", err)
 		} else {
 			util.RespondWithError(c, http.StatusInternalServerError, "Failed to delete role", err)
 		}
@@ -131,7 +147,7 @@ func (rc *RoleController) GetRole(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, role)
+	util.RespondWithEntity(c, role)
 }
 
 // ListRoles endpoint
@@ -174,3 +190,106 @@ func (rc *RoleController) SearchRoles(c *gin.Context) {
 
 	c.JSON(http.StatusOK, roles)
 }
+
+// GetUsersByRole endpoint
+func (rc *RoleController) GetUsersByRole(c *gin.Context) {
+	roleID := c.Param("id")
+
+	includeSuspended, err := strconv.ParseBool(c.DefaultQuery("includeSuspended", "false"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid includeSuspended parameter", err)
+		return
+	}
+
+	users, err := rc.roleService.GetUsersByRole(c, roleID, includeSuspended)
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to get users by role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetGroupsByRole endpoint
+func (rc *RoleController) GetGroupsByRole(c *gin.Context) {
+	roleID := c.Param("id")
+
+	groups, err := rc.roleService.GetGroupsByRole(c, roleID)
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to get groups by role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// AnalyzeRoleUsage endpoint
+func (rc *RoleController) AnalyzeRoleUsage(c *gin.Context) {
+	roleID := c.Param("id")
+
+	analysis, err := rc.roleService.AnalyzeRoleUsage(c, roleID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrRoleNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, "Role not found", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to analyze role usage", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// AssignRoleToUsers endpoint gives the role to every user listed in the body
+func (rc *RoleController) AssignRoleToUsers(c *gin.Context) {
+	rc.changeRoleAssignment(c, rc.roleService.AssignRoleToUsers)
+}
+
+// UnassignRoleFromUsers endpoint takes the role away from every user listed in the body
+func (rc *RoleController) UnassignRoleFromUsers(c *gin.Context) {
+	rc.changeRoleAssignment(c, rc.roleService.UnassignRoleFromUsers)
+}
+
+func (rc *RoleController) changeRoleAssignment(c *gin.Context, change func(context.Context, string, []string, string) (*model.RoleAssignment, error)) {
+	var request model.RoleAssignment
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithBindError(c, "Invalid role assignment data", err)
+		return
+	}
+	actorID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	assignment, err := change(c, c.Param("id"), request.UserIDs, actorID)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// UpdateRolePermissions endpoint grants the role the permissions listed under add and revokes those
+// listed under remove
+func (rc *RoleController) UpdateRolePermissions(c *gin.Context) {
+	var change model.RolePermissionsChange
+	if err := c.ShouldBindJSON(&change); err != nil {
+		util.RespondWithBindError(c, "Invalid role permissions data", err)
+		return
+	}
+	actorID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	permissions, err := rc.roleService.UpdateRolePermissions(c, c.Param("id"), change, actorID)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
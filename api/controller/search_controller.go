@@ -0,0 +1,62 @@
+// api/controller/search_controller.go
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+type SearchController struct {
+	searchService service.ISearchService
+}
+
+func NewSearchController(searchService service.ISearchService) *SearchController {
+	return &SearchController{
+		searchService: searchService,
+	}
+}
+
+// RegisterRoutes registers the API routes for global search
+func (sc *SearchController) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/search", sc.Search)
+}
+
+// Search endpoint looks for q in the names of users, resources and policies, or of the comma-separated
+// types given, taking up to limit hits from each
+func (sc *SearchController) Search(c *gin.Context) {
+	limit := service.DefaultSearchTypeLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid limit", err)
+			return
+		}
+		limit = parsed
+	}
+	var types []string
+	if value := c.Query("types"); value != "" {
+		for _, entityType := range strings.Split(value, ",") {
+			types = append(types, strings.TrimSpace(entityType))
+		}
+	}
+
+	results, err := sc.searchService.Search(c, c.Query("q"), types, limit)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to search", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
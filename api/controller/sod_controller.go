@@ -0,0 +1,142 @@
+// api/controller/sod_controller.go
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+type SoDController struct {
+	sodService service.ISoDService
+}
+
+func NewSoDController(sodService service.ISoDService) *SoDController {
+	return &SoDController{
+		sodService: sodService,
+	}
+}
+
+// RegisterRoutes registers the API routes for separation of duties rules and violations
+func (sc *SoDController) RegisterRoutes(r *gin.RouterGroup) {
+	rules := r.Group("/sod-rules")
+	{
+		rules.POST("", sc.CreateSoDRule)
+		rules.PUT("/:id", sc.UpdateSoDRule)
+		rules.DELETE("/:id", sc.DeleteSoDRule)
+		rules.GET("/:id", sc.GetSoDRule)
+		rules.GET("", sc.ListSoDRules)
+	}
+	r.GET("/users/:id/sod-violations", sc.GetUserSoDViolations)
+	r.GET("/organizations/:id/sod-violations", sc.ScanSoDViolations)
+}
+
+// CreateSoDRule endpoint
+func (sc *SoDController) CreateSoDRule(c *gin.Context) {
+	var rule model.SoDRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid SoD rule data", echo_errors.ErrInvalidSoDRuleData)
+		return
+	}
+	creatorID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", echo_errors.ErrUnauthorized)
+		return
+	}
+
+	createdRule, err := sc.sodService.CreateSoDRule(c, rule, creatorID)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdRule)
+}
+
+// UpdateSoDRule endpoint
+func (sc *SoDController) UpdateSoDRule(c *gin.Context) {
+	var rule model.SoDRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid SoD rule data", echo_errors.ErrInvalidSoDRuleData)
+		return
+	}
+	rule.ID = c.Param("id")
+	updaterID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", echo_errors.ErrUnauthorized)
+		return
+	}
+
+	updatedRule, err := sc.sodService.UpdateSoDRule(c, rule, updaterID)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedRule)
+}
+
+// DeleteSoDRule endpoint
+func (sc *SoDController) DeleteSoDRule(c *gin.Context) {
+	deleterID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", echo_errors.ErrUnauthorized)
+		return
+	}
+
+	if err := sc.sodService.DeleteSoDRule(c, c.Param("id"), deleterID); err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSoDRule endpoint
+func (sc *SoDController) GetSoDRule(c *gin.Context) {
+	rule, err := sc.sodService.GetSoDRule(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	util.RespondWithEntity(c, rule)
+}
+
+// ListSoDRules endpoint
+func (sc *SoDController) ListSoDRules(c *gin.Context) {
+	rules, err := sc.sodService.ListSoDRules(c)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetUserSoDViolations endpoint reports the SoD rules the user breaks with the roles they hold
+func (sc *SoDController) GetUserSoDViolations(c *gin.Context) {
+	violations, err := sc.sodService.CheckSoDViolations(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, violations)
+}
+
+// ScanSoDViolations endpoint reports the SoD rules broken by any user of the organization
+func (sc *SoDController) ScanSoDViolations(c *gin.Context) {
+	violations, err := sc.sodService.ScanSoDViolations(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, violations)
+}
@@ -2,8 +2,10 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -31,17 +33,27 @@ func (uc *UserController) RegisterRoutes(r *gin.RouterGroup) {
 		users.POST("", uc.CreateUser)
 		users.PUT("/:id", uc.UpdateUser)
 		users.DELETE("/:id", uc.DeleteUser)
+		users.GET("/inactive", uc.GetInactiveUsers)
 		users.GET("/:id", uc.GetUser)
+		users.HEAD("/:id", uc.UserExists)
+		users.GET("/:id/effective-permissions", uc.GetEffectivePermissions)
 		users.GET("", uc.ListUsers)
 		users.POST("/search", uc.SearchUsers)
+		users.POST("/batch-get", uc.BatchGetUsers)
+		users.POST("/:id/activate", uc.ActivateUser)
+		users.POST("/:id/suspend", uc.SuspendUser)
+		users.POST("/:id/disable", uc.DisableUser)
+		users.PUT("/:id/admin-scope", uc.SetAdminScope)
+		users.GET("/:id/admin-scope", uc.GetAdminScope)
+		users.DELETE("/:id/admin-scope", uc.RemoveAdminScope)
 	}
 }
 
 // CreateUser endpoint
 func (uc *UserController) CreateUser(c *gin.Context) {
 	var user model.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		util.RespondWithError(c, http.StatusBadRequest, "Invalid user data", echo_errors.ErrInvalidUserData)
+	if err := util.BindJSONWithSchema(c, util.SchemaUser, &user); err != nil {
+		util.RespondWithBindError(c, "Invalid user data", err)
 		return
 	}
 	creatorID, err := util.GetUserIDFromContext(c)
@@ -50,9 +62,16 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 		return
 	}
 
-	createdUser, err := uc.userService.CreateUser(c, user, creatorID)
+	ctx := helper_util.DryRunContext(c)
+	createdUser, err := uc.userService.CreateUser(ctx, user, creatorID)
 	if err != nil {
+		if errors.Is(err, echo_errors.ErrAttributeSchemaViolation) || errors.Is(err, echo_errors.ErrInvalidUserStatus) {
+			util.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
 		switch err {
+		case echo_errors.ErrAttributeGroupNotFound:
+			util.RespondWithError(c, http.StatusBadRequest, "Attribute group not found", err)
 		case echo_errors.ErrUserConflict:
 			util.RespondWithError(c, http.StatusConflict, "User already exists", err)
 		case echo_errors.ErrDatabaseOperation:
@@ -60,20 +79,26 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 		case echo_errors.ErrInternalServer:
 			util.RespondWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		default:
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to create user", echo_errors.ErrInternalServer)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdUser)
+	// A dry run created nothing
+	status := http.StatusCreated
+	if helper_util.IsDryRun(ctx) {
+		status = http.StatusOK
+	}
+	c.JSON(status, createdUser)
 }
 
-// UpdateUser endpoint
+// UpdateUser endpoint. Leaving role_ids or group_ids out of the body keeps the user's roles or groups,
+// while an empty list removes them all.
 func (uc *UserController) UpdateUser(c *gin.Context) {
 	userID := c.Param("id")
 	var user model.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		util.RespondWithError(c, http.StatusBadRequest, "Invalid user data", err)
+	if err := util.BindJSONWithSchema(c, util.SchemaUser, &user); err != nil {
+		util.RespondWithBindError(c, "Invalid user data", err)
 		return
 	}
 	user.ID = userID
@@ -83,12 +108,17 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	updatedUser, err := uc.userService.UpdateUser(c, user, updaterID)
+	ctx := helper_util.DryRunContext(c)
+	updatedUser, err := uc.userService.UpdateUser(ctx, user, updaterID)
 	if err != nil {
 		if err == echo_errors.ErrUserNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "User not found", err)
+		} else if errors.Is(err, echo_errors.ErrAttributeSchemaViolation) {
+			util.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		} else if err == echo_errors.ErrAttributeGroupNotFound {
+			util.RespondWithError(c, http.StatusBadRequest, "Attribute group not found", err)
 		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to update user", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -109,7 +139,7 @@ func (uc *UserController) DeleteUser(c *gin.Context) {
 		if err == echo_errors.ErrUserNotFound {
 			util.RespondWithError(c, http.StatusNotFound, "User not found", err)
 		} else {
-			util.RespondWithError(c, http.StatusInternalServerError, "Failed to delete user", err)
+			util.RespondWithMappedError(c, err)
 		}
 		return
 	}
@@ -131,7 +161,40 @@ func (uc *UserController) GetUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	util.RespondWithEntity(c, user)
+}
+
+// UserExists endpoint answers HEAD requests with 200 or 404 and no body
+func (uc *UserController) UserExists(c *gin.Context) {
+	exists, err := uc.userService.UserExists(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusInternalServerError, "Failed to check user", err)
+		return
+	}
+	if !exists {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// BatchGetUsers endpoint returns the users whose IDs are posted, at most service.MaxBatchGetIDs of them,
+// along with the IDs not found
+func (uc *UserController) BatchGetUsers(c *gin.Context) {
+	var request model.BatchGetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid batch get request", echo_errors.ErrInvalidSearchCriteria)
+		return
+	}
+
+	batch, err := uc.userService.GetUsersByIDs(c, request.IDs)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
 }
 
 // ListUsers endpoint
@@ -144,7 +207,7 @@ func (uc *UserController) ListUsers(c *gin.Context) {
 
 	users, err := uc.userService.ListUsers(c, limit, offset)
 	if err != nil {
-		util.RespondWithError(c, http.StatusInternalServerError, "Failed to list users", err)
+		util.RespondWithMappedError(c, err)
 		return
 	}
 
@@ -162,9 +225,146 @@ func (uc *UserController) SearchUsers(c *gin.Context) {
 
 	users, err := uc.userService.SearchUsers(c, criteria)
 	if err != nil {
-		util.RespondWithError(c, http.StatusInternalServerError, "Failed to search users", err)
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, "Invalid search criteria", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to search users", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetInactiveUsers endpoint
+func (uc *UserController) GetInactiveUsers(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid days parameter", err)
+		return
+	}
+
+	limit, offset, err := helper_util.GetPaginationParams(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	users, err := uc.userService.GetInactiveUsers(c, days, limit, offset)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			util.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to list inactive users", err)
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, users)
 }
+
+// GetEffectivePermissions endpoint
+func (uc *UserController) GetEffectivePermissions(c *gin.Context) {
+	userID := c.Param("id")
+
+	effective, err := uc.userService.GetEffectivePermissions(c, userID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrUserNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, "User not found", err)
+		} else {
+			util.RespondWithError(c, http.StatusInternalServerError, "Failed to resolve effective permissions", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, effective)
+}
+
+// ActivateUser endpoint
+func (uc *UserController) ActivateUser(c *gin.Context) {
+	uc.changeUserStatus(c, uc.userService.ActivateUser)
+}
+
+// SuspendUser endpoint
+func (uc *UserController) SuspendUser(c *gin.Context) {
+	uc.changeUserStatus(c, uc.userService.SuspendUser)
+}
+
+// DisableUser endpoint
+func (uc *UserController) DisableUser(c *gin.Context) {
+	uc.changeUserStatus(c, uc.userService.DisableUser)
+}
+
+func (uc *UserController) changeUserStatus(c *gin.Context, transition func(ctx context.Context, userID string, actorID string) (*model.User, error)) {
+	userID := c.Param("id")
+	actorID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	user, err := transition(c, userID, actorID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrUserNotFound) {
+			util.RespondWithError(c, http.StatusNotFound, "User not found", err)
+		} else if errors.Is(err, echo_errors.ErrInvalidUserStatusTransition) {
+			util.RespondWithError(c, http.StatusConflict, err.Error(), err)
+		} else {
+			util.RespondWithMappedError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// SetAdminScope endpoint
+func (uc *UserController) SetAdminScope(c *gin.Context) {
+	var scope model.ScopedAdmin
+	if err := c.ShouldBindJSON(&scope); err != nil {
+		util.RespondWithBindError(c, "Invalid admin scope", err)
+		return
+	}
+	scope.UserID = c.Param("id")
+
+	actorID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	updatedScope, err := uc.userService.SetAdminScope(c, scope, actorID)
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedScope)
+}
+
+// GetAdminScope endpoint
+func (uc *UserController) GetAdminScope(c *gin.Context) {
+	scope, err := uc.userService.GetAdminScope(c, c.Param("id"))
+	if err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scope)
+}
+
+// RemoveAdminScope endpoint
+func (uc *UserController) RemoveAdminScope(c *gin.Context) {
+	actorID, err := util.GetUserIDFromContext(c)
+	if err != nil {
+		util.RespondWithError(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if err := uc.userService.RemoveAdminScope(c, c.Param("id"), actorID); err != nil {
+		util.RespondWithMappedError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
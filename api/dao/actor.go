@@ -0,0 +1,32 @@
+// api/dao/actor.go
+package dao
+
+import (
+	"context"
+	"encoding/json"
+
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+// changedBy returns whom a change is recorded as made by: userID when the caller names the acting
+// user, otherwise the user ctx acts on behalf of. The changed node's createdBy or updatedBy and the
+// change's audit log both take it, so the two always agree. The createdBy and updatedBy of the entity
+// being written are never passed as userID: they come from the request body, so any client could set
+// them.
+func changedBy(ctx context.Context, userID string) string {
+	if userID != "" {
+		return userID
+	}
+	return helper_util.ActorFromContext(ctx)
+}
+
+// deletedDetails returns the change details of an entity deleted by actor. The node goes, and its
+// createdBy and updatedBy with it, so the audit log is where deletedBy is kept.
+func deletedDetails(actor string, details map[string]interface{}) json.RawMessage {
+	changes := map[string]interface{}{"action": "deleted", "deletedBy": actor}
+	for key, value := range details {
+		changes[key] = value
+	}
+	encoded, _ := json.Marshal(changes)
+	return encoded
+}
@@ -0,0 +1,244 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+// entityNode is a node every entity maps from, carrying the properties each of them requires
+func entityNode(id string) neo4j.Node {
+	node := policyNode(id, "Entity "+id)
+	for key, value := range resourceNode(id, "2024-01-01T00:00:00Z").Props {
+		if _, ok := node.Props[key]; !ok {
+			node.Props[key] = value
+		}
+	}
+	for key, value := range map[string]any{
+		"action":         "read",
+		"roleA":          "r1",
+		"roleB":          "r2",
+		"username":       "entity",
+		"email":          "entity@example.com",
+		"userType":       "EMPLOYEE",
+		"attributes":     "{}",
+		"organizationID": "org1",
+		"departmentID":   "",
+		"parentID":       "",
+	} {
+		node.Props[key] = value
+	}
+	return node
+}
+
+// persistedUpdatedBy returns the updatedBy a write query sets, whether as a parameter of its own or
+// among the properties it sets
+func persistedUpdatedBy(query mock.FakeQuery) (any, bool) {
+	if updatedBy, ok := query.Params["updatedBy"]; ok {
+		return updatedBy, true
+	}
+	if props, ok := query.Params["props"].(map[string]any); ok {
+		updatedBy, ok := props[echo_neo4j.AttrUpdatedBy]
+		return updatedBy, ok
+	}
+	return nil, false
+}
+
+func TestUpdatesRecordTheActingUser(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := helper_util.WithActor(context.Background(), "alice")
+
+	tests := []struct {
+		name   string
+		action string
+		answer func(driver *mock.FakeDriver) // Answers the queries that do not return the entity
+		update func(driver dao.Neo4jDriver, auditService audit.Service) error
+	}{
+		{"attribute group", "UPDATE_ATTRIBUTE_GROUP", nil, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.AttributeGroupDAO{Driver: driver, AuditService: auditService}).UpdateAttributeGroup(ctx, model.AttributeGroup{ID: "e1", Name: "Entity"})
+			return err
+		}},
+		{"department", "UPDATE_DEPARTMENT", nil, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.DepartmentDAO{Driver: driver, AuditService: auditService}).UpdateDepartment(ctx, model.Department{ID: "e1", Name: "Entity", OrganizationID: "org1"})
+			return err
+		}},
+		{"group", "UPDATE_GROUP", nil, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.GroupDAO{Driver: driver, AuditService: auditService}).UpdateGroup(ctx, model.Group{ID: "e1", Name: "Entity", OrganizationID: "org1"})
+			return err
+		}},
+		{"organization", "UPDATE_ORGANIZATION", nil, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.OrganizationDAO{Driver: driver, AuditService: auditService}).UpdateOrganization(ctx, model.Organization{ID: "e1", Name: "Entity"})
+			return err
+		}},
+		{"permission", "UPDATE_" + echo_neo4j.LabelPermission, nil, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.PermissionDAO{Driver: driver, AuditService: auditService}).UpdatePermission(ctx, model.Permission{ID: "e1", Name: "Entity", Action: "read"})
+			return err
+		}},
+		{"policy", "UPDATE_POLICY", nil, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.PolicyDAO{Driver: driver, AuditService: auditService}).UpdatePolicy(ctx, model.Policy{ID: "e1", Name: "Entity"}, "")
+			return err
+		}},
+		{"resource", "UPDATE_RESOURCE", nil, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.ResourceDAO{Driver: driver, AuditService: auditService}).UpdateResource(ctx, model.Resource{ID: "e1", Name: "Entity", OrganizationID: "org1"})
+			return err
+		}},
+		{"resource type", "UPDATE_RESOURCE_TYPE", nil, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.ResourceTypeDAO{Driver: driver, AuditService: auditService}).UpdateResourceType(ctx, model.ResourceType{ID: "e1", Name: "Entity"})
+			return err
+		}},
+		{"role", "UPDATE_ROLE", func(driver *mock.FakeDriver) { driver.Returns("RETURN p.id") }, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.RoleDAO{Driver: driver, AuditService: auditService}).UpdateRole(ctx, model.Role{ID: "e1", Name: "Entity", OrganizationID: "org1"})
+			return err
+		}},
+		{"SoD rule", "UPDATE_" + echo_neo4j.LabelSoDRule, func(driver *mock.FakeDriver) {
+			driver.Returns("AS roleAExists", &neo4j.Record{Values: []any{true, true, false}})
+		}, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.SoDRuleDAO{Driver: driver, AuditService: auditService}).UpdateSoDRule(ctx, model.SoDRule{ID: "e1", RoleA: "r1", RoleB: "r2"})
+			return err
+		}},
+		{"user", "UPDATE_USER", nil, func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.UserDAO{Driver: driver, AuditService: auditService}).UpdateUser(ctx, model.User{ID: "e1", Name: "Entity", OrganizationID: "org1"})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := mock.NewFakeDriver()
+			if tt.answer != nil {
+				tt.answer(driver)
+			}
+			driver.Returns("", &neo4j.Record{
+				Keys:   []string{"n", "a", "b", "c"},
+				Values: []any{entityNode("e1"), []any{}, nil, []any{}},
+			})
+			auditService := &mock.MockAuditService{}
+			auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+				return log.Action == tt.action
+			})).Return(nil).Once()
+
+			err := tt.update(driver, auditService)
+
+			assert.NoError(t, err)
+			var persisted []any
+			for _, query := range driver.Queries() {
+				if updatedBy, ok := persistedUpdatedBy(query); ok && query.Write {
+					persisted = append(persisted, updatedBy)
+				}
+			}
+			if assert.NotEmpty(t, persisted, "no write set updatedBy") {
+				for _, updatedBy := range persisted {
+					assert.Equal(t, "alice", updatedBy)
+				}
+			}
+			auditService.AssertExpectations(t)
+			for _, call := range auditService.Calls {
+				assert.Equal(t, "alice", call.Arguments.Get(1).(audit.AuditLog).UserID)
+			}
+		})
+	}
+}
+
+func TestChangesIgnoreTheActorOfTheBody(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := helper_util.WithActor(context.Background(), "alice")
+
+	// Each entity is written with the createdBy and updatedBy a client put in the request body
+	tests := []struct {
+		name   string
+		action string
+		write  func(driver dao.Neo4jDriver, auditService audit.Service) error
+	}{
+		{"attribute group", "UPDATE_ATTRIBUTE_GROUP", func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.AttributeGroupDAO{Driver: driver, AuditService: auditService}).UpdateAttributeGroup(ctx, model.AttributeGroup{ID: "e1", Name: "Entity", UpdatedBy: "mallory"})
+			return err
+		}},
+		{"resource", "UPDATE_RESOURCE", func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.ResourceDAO{Driver: driver, AuditService: auditService}).UpdateResource(ctx, model.Resource{ID: "e1", Name: "Entity", OrganizationID: "org1", UpdatedBy: "mallory"})
+			return err
+		}},
+		{"resource type", "UPDATE_RESOURCE_TYPE", func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.ResourceTypeDAO{Driver: driver, AuditService: auditService}).UpdateResourceType(ctx, model.ResourceType{ID: "e1", Name: "Entity", UpdatedBy: "mallory"})
+			return err
+		}},
+		{"user", "UPDATE_USER", func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.UserDAO{Driver: driver, AuditService: auditService}).UpdateUser(ctx, model.User{ID: "e1", Name: "Entity", OrganizationID: "org1", UpdatedBy: "mallory"})
+			return err
+		}},
+		{"created user", "CREATE_USER", func(driver dao.Neo4jDriver, auditService audit.Service) error {
+			_, err := (&dao.UserDAO{Driver: driver, AuditService: auditService}).CreateUser(ctx, model.User{ID: "e1", Name: "Entity", CreatedBy: "mallory", UpdatedBy: "mallory"})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := mock.NewFakeDriver()
+			driver.Returns("RETURN u.id as id", &neo4j.Record{Keys: []string{"id"}, Values: []any{"e1"}})
+			driver.Returns("", &neo4j.Record{
+				Keys:   []string{"n", "a", "b", "c"},
+				Values: []any{entityNode("e1"), []any{}, nil, []any{}},
+			})
+			auditService := &mock.MockAuditService{}
+			auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+			err := tt.write(driver, auditService)
+
+			assert.NoError(t, err)
+			for _, query := range driver.Queries() {
+				if !query.Write {
+					continue
+				}
+				if updatedBy, ok := persistedUpdatedBy(query); ok {
+					assert.Equal(t, "alice", updatedBy)
+				}
+				if props, ok := query.Params["props"].(map[string]any); ok {
+					if createdBy, ok := props[echo_neo4j.AttrCreatedBy]; ok {
+						assert.Equal(t, "alice", createdBy)
+					}
+				}
+			}
+			for _, call := range auditService.Calls {
+				if log := call.Arguments.Get(1).(audit.AuditLog); log.Action == tt.action {
+					assert.Equal(t, "alice", log.UserID)
+				}
+			}
+		})
+	}
+}
+
+func TestDeletesRecordTheDeletingUser(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := helper_util.WithActor(context.Background(), "alice")
+	driver := mock.NewFakeDriver()
+	driver.On("DETACH DELETE", mock.FakeResponse{NodesDeleted: 1})
+	auditService := &mock.MockAuditService{}
+	auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+		return log.Action == "DELETE_USER"
+	})).Return(nil).Once()
+
+	err := (&dao.UserDAO{Driver: driver, AuditService: auditService}).DeleteUser(ctx, "e1")
+
+	assert.NoError(t, err)
+	auditService.AssertExpectations(t)
+	log := auditService.Calls[0].Arguments.Get(1).(audit.AuditLog)
+	assert.Equal(t, "alice", log.UserID)
+	assert.JSONEq(t, `{"action":"deleted","deletedBy":"alice"}`, string(log.ChangeDetails))
+}
@@ -0,0 +1,216 @@
+// api/dao/admin_scope.go
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+)
+
+// SetAdminScope ties a user to the organization, or the department of that organization, they
+// administer, replacing any scope they had. It returns ErrUserNotFound for an unknown user and
+// ErrInvalidAdminScope when the organization or department does not exist or the department belongs to
+// another organization.
+func (dao *UserDAO) SetAdminScope(ctx context.Context, scope model.ScopedAdmin, actorID string) error {
+	start := time.Now()
+	logger.Info("Setting admin scope",
+		zap.String("userID", scope.UserID),
+		zap.String("organizationID", scope.OrganizationID),
+		zap.String("departmentID", scope.DepartmentID))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		userResult, err := transaction.Run(`
+        MATCH (u:`+echo_neo4j.LabelUser+` {id: $userID})
+        RETURN u.id
+        `, map[string]interface{}{"userID": scope.UserID})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if !userResult.Next() {
+			return nil, echo_errors.ErrUserNotFound
+		}
+
+		target := `MATCH (s:` + echo_neo4j.LabelOrganization + ` {id: $organizationID})`
+		if scope.DepartmentID != "" {
+			target = `MATCH (s:` + echo_neo4j.LabelDepartment + ` {id: $departmentID, organizationID: $organizationID})`
+		}
+		result, err := transaction.Run(`
+        MATCH (u:`+echo_neo4j.LabelUser+` {id: $userID})
+        `+target+`
+        OPTIONAL MATCH (u)-[old:`+echo_neo4j.RelAdministers+`]->()
+        DELETE old
+        MERGE (u)-[:`+echo_neo4j.RelAdministers+`]->(s)
+        RETURN s.id
+        `, map[string]interface{}{
+			"userID":         scope.UserID,
+			"organizationID": scope.OrganizationID,
+			"departmentID":   scope.DepartmentID,
+		})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if !result.Next() {
+			return nil, echo_errors.ErrInvalidAdminScope
+		}
+		return nil, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to set admin scope",
+			zap.Error(err),
+			zap.String("userID", scope.UserID),
+			zap.Duration("duration", duration))
+		return classifyNeo4jError(err)
+	}
+
+	logger.Info("Admin scope set successfully",
+		zap.String("userID", scope.UserID),
+		zap.Duration("duration", duration))
+
+	// Audit trail
+	changeDetails, _ := json.Marshal(map[string]interface{}{
+		"action":          "admin_scope_set",
+		"organization_id": scope.OrganizationID,
+		"department_id":   scope.DepartmentID,
+	})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        actorID,
+		Action:        "SET_ADMIN_SCOPE",
+		ResourceID:    scope.UserID,
+		AccessGranted: true,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return nil
+}
+
+// GetAdminScope returns the scope a user administers, with the department's subtree resolved through
+// the department hierarchy. It returns ErrAdminScopeNotFound when the user has no scope.
+func (dao *UserDAO) GetAdminScope(ctx context.Context, userID string) (*model.ScopedAdmin, error) {
+	start := time.Now()
+	logger.Info("Retrieving admin scope", zap.String("userID", userID))
+
+	query := `
+    MATCH (u:` + echo_neo4j.LabelUser + ` {id: $userID})-[:` + echo_neo4j.RelAdministers + `]->(s)
+    WHERE s:` + echo_neo4j.LabelOrganization + ` OR s:` + echo_neo4j.LabelDepartment + `
+    RETURN
+        CASE WHEN s:` + echo_neo4j.LabelDepartment + ` THEN s.organizationID ELSE s.id END AS organizationID,
+        CASE WHEN s:` + echo_neo4j.LabelDepartment + ` THEN s.id ELSE '' END AS departmentID,
+        [(d:` + echo_neo4j.LabelDepartment + `)-[:` + echo_neo4j.RelChildOf + `*0..]->(s) | d.id] AS departmentIDs
+    LIMIT 1
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"userID": userID})
+	if err != nil {
+		logger.Error("Failed to execute get admin scope query",
+			zap.Error(err),
+			zap.String("userID", userID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+	if len(records) == 0 {
+		return nil, echo_errors.ErrAdminScopeNotFound
+	}
+
+	record := records[0]
+	scope := &model.ScopedAdmin{UserID: userID}
+	scope.OrganizationID, _ = record.Values[0].(string)
+	scope.DepartmentID, _ = record.Values[1].(string)
+	if scope.DepartmentID != "" {
+		departmentIDs, _ := record.Values[2].([]interface{})
+		for _, departmentID := range departmentIDs {
+			if id, ok := departmentID.(string); ok {
+				scope.DepartmentIDs = append(scope.DepartmentIDs, id)
+			}
+		}
+	}
+
+	logger.Info("Admin scope retrieved successfully",
+		zap.String("userID", userID),
+		zap.Int("departmentCount", len(scope.DepartmentIDs)),
+		zap.Duration("duration", time.Since(start)))
+
+	return scope, nil
+}
+
+// RemoveAdminScope unties a user from the scope they administer. It returns ErrAdminScopeNotFound when
+// the user has no scope.
+func (dao *UserDAO) RemoveAdminScope(ctx context.Context, userID string, actorID string) error {
+	start := time.Now()
+	logger.Info("Removing admin scope", zap.String("userID", userID))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		result, err := transaction.Run(`
+        MATCH (u:`+echo_neo4j.LabelUser+` {id: $userID})-[scope:`+echo_neo4j.RelAdministers+`]->()
+        DELETE scope
+        RETURN count(scope) AS removed
+        `, map[string]interface{}{"userID": userID})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if !result.Next() {
+			return nil, echo_errors.ErrAdminScopeNotFound
+		}
+		if removed, _ := result.Record().Values[0].(int64); removed == 0 {
+			return nil, echo_errors.ErrAdminScopeNotFound
+		}
+		return nil, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to remove admin scope",
+			zap.Error(err),
+			zap.String("userID", userID),
+			zap.Duration("duration", duration))
+		return classifyNeo4jError(err)
+	}
+
+	logger.Info("Admin scope removed successfully",
+		zap.String("userID", userID),
+		zap.Duration("duration", duration))
+
+	// Audit trail
+	changeDetails, _ := json.Marshal(map[string]interface{}{"action": "admin_scope_removed"})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        actorID,
+		Action:        "REMOVE_ADMIN_SCOPE",
+		ResourceID:    userID,
+		AccessGranted: true,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return nil
+}
+
+// scopeDepartmentIDs is the departmentIDs parameter of the queries listing what a scope covers: null
+// for an organization scope, which covers every department, and otherwise the department subtree
+func scopeDepartmentIDs(scope model.ScopedAdmin) interface{} {
+	if scope.DepartmentID == "" {
+		return nil
+	}
+	return append([]string{}, scope.DepartmentIDs...)
+}
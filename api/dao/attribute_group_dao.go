@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,14 +18,15 @@ import (
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 type AttributeGroupDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewAttributeGroupDAO(driver neo4j.Driver, auditService audit.Service) *AttributeGroupDAO {
+func NewAttributeGroupDAO(driver Neo4jDriver, auditService audit.Service) *AttributeGroupDAO {
 	dao := &AttributeGroupDAO{Driver: driver, AuditService: auditService}
 	ctx := context.Background()
 	if err := dao.EnsureUniqueConstraint(ctx); err != nil {
@@ -68,6 +70,8 @@ func (dao *AttributeGroupDAO) CreateAttributeGroup(ctx context.Context, attribut
 
 	attributeGroup.CreatedAt = time.Now()
 	attributeGroup.UpdatedAt = time.Now()
+	attributeGroup.CreatedBy = changedBy(ctx, "")
+	attributeGroup.UpdatedBy = attributeGroup.CreatedBy
 
 	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		attributesJSON, err := json.Marshal(attributeGroup.Attributes)
@@ -75,11 +79,17 @@ func (dao *AttributeGroupDAO) CreateAttributeGroup(ctx context.Context, attribut
 			return nil, fmt.Errorf("failed to marshal attributes: %w", err)
 		}
 
+		schemaJSON, err := json.Marshal(attributeGroup.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema: %w", err)
+		}
+
 		query := `
         CREATE (ag:` + echo_neo4j.LabelAttributeGroup + ` {
             id: $id,
             name: $name,
             attributes: $attributes,
+            schema: $schema,
             createdBy: $createdBy,
             updatedBy: $updatedBy,
             createdAt: $createdAt,
@@ -92,6 +102,7 @@ func (dao *AttributeGroupDAO) CreateAttributeGroup(ctx context.Context, attribut
 			"id":         attributeGroup.ID,
 			"name":       attributeGroup.Name,
 			"attributes": string(attributesJSON),
+			"schema":     string(schemaJSON),
 			"createdBy":  attributeGroup.CreatedBy,
 			"updatedBy":  attributeGroup.UpdatedBy,
 			"createdAt":  attributeGroup.CreatedAt.Format(time.RFC3339),
@@ -116,7 +127,7 @@ func (dao *AttributeGroupDAO) CreateAttributeGroup(ctx context.Context, attribut
 			zap.Error(err),
 			zap.String("name", attributeGroup.Name),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrAttributeGroupConflict, attributeGroup.ID)
 	}
 
 	attributeGroupID := fmt.Sprintf("%v", result)
@@ -127,7 +138,7 @@ func (dao *AttributeGroupDAO) CreateAttributeGroup(ctx context.Context, attribut
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        attributeGroup.CreatedBy,
 		Action:        "CREATE_ATTRIBUTE_GROUP",
 		ResourceID:    attributeGroupID,
 		AccessGranted: true,
@@ -139,6 +150,11 @@ func (dao *AttributeGroupDAO) CreateAttributeGroup(ctx context.Context, attribut
 	return attributeGroupID, nil
 }
 
+// Exists reports whether an attribute group with the given ID exists, without reading the whole node
+func (dao *AttributeGroupDAO) Exists(ctx context.Context, attributeGroupID string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelAttributeGroup, attributeGroupID)
+}
+
 func (dao *AttributeGroupDAO) GetAttributeGroup(ctx context.Context, id string) (*model.AttributeGroup, error) {
 	start := time.Now()
 	logger.Info("Retrieving attribute group", zap.String("id", id))
@@ -189,6 +205,7 @@ func (dao *AttributeGroupDAO) UpdateAttributeGroup(ctx context.Context, attribut
 	defer session.Close()
 
 	attributeGroup.UpdatedAt = time.Now()
+	attributeGroup.UpdatedBy = changedBy(ctx, "")
 
 	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		attributesJSON, err := json.Marshal(attributeGroup.Attributes)
@@ -196,10 +213,16 @@ func (dao *AttributeGroupDAO) UpdateAttributeGroup(ctx context.Context, attribut
 			return nil, fmt.Errorf("failed to marshal attributes: %w", err)
 		}
 
+		schemaJSON, err := json.Marshal(attributeGroup.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema: %w", err)
+		}
+
 		query := `
         MATCH (ag:` + echo_neo4j.LabelAttributeGroup + ` {id: $id})
         SET ag.name = $name,
             ag.attributes = $attributes,
+            ag.schema = $schema,
             ag.updatedBy = $updatedBy,
             ag.updatedAt = $updatedAt
         RETURN ag
@@ -209,6 +232,7 @@ func (dao *AttributeGroupDAO) UpdateAttributeGroup(ctx context.Context, attribut
 			"id":         attributeGroup.ID,
 			"name":       attributeGroup.Name,
 			"attributes": string(attributesJSON),
+			"schema":     string(schemaJSON),
 			"updatedBy":  attributeGroup.UpdatedBy,
 			"updatedAt":  attributeGroup.UpdatedAt.Format(time.RFC3339),
 		}
@@ -232,7 +256,7 @@ func (dao *AttributeGroupDAO) UpdateAttributeGroup(ctx context.Context, attribut
 			zap.Error(err),
 			zap.String("id", attributeGroup.ID),
 			zap.Duration("duration", duration))
-		return nil, err
+		return nil, classifyNeo4jError(err)
 	}
 
 	updatedAttributeGroup := result.(*model.AttributeGroup)
@@ -243,7 +267,7 @@ func (dao *AttributeGroupDAO) UpdateAttributeGroup(ctx context.Context, attribut
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        attributeGroup.UpdatedBy,
 		Action:        "UPDATE_ATTRIBUTE_GROUP",
 		ResourceID:    updatedAttributeGroup.ID,
 		AccessGranted: true,
@@ -309,6 +333,93 @@ func (dao *AttributeGroupDAO) ListAttributeGroups(ctx context.Context, limit int
 	return attributeGroups, nil
 }
 
+// attributeGroupSortFields lists the properties attribute group searches may be ordered by
+var attributeGroupSortFields = []string{"name", "createdAt", "updatedAt", "createdBy"}
+
+// SearchAttributeGroups searches attribute groups by criteria and returns the matching page along with
+// the total number of matches ignoring pagination
+func (dao *AttributeGroupDAO) SearchAttributeGroups(ctx context.Context, criteria model.AttributeGroupSearchCriteria) ([]*model.AttributeGroup, int64, error) {
+	start := time.Now()
+	logger.Info("Searching attribute groups", zap.Any("criteria", criteria))
+
+	sortClause, err := helper_util.SafeSortClause("ag", criteria.SortBy, criteria.SortOrder, attributeGroupSortFields)
+	if err != nil {
+		logger.Warn("Invalid sort field for attribute group search", zap.String("sortBy", criteria.SortBy))
+		return nil, 0, err
+	}
+	if sortClause == "" {
+		sortClause = " ORDER BY ag.name ASC"
+	}
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close()
+
+	var whereBuilder strings.Builder
+	whereBuilder.WriteString("MATCH (ag:" + echo_neo4j.LabelAttributeGroup + ") WHERE 1=1")
+
+	params := make(map[string]interface{})
+
+	if criteria.Name != "" {
+		whereBuilder.WriteString(" AND toLower(ag.name) CONTAINS toLower($name)")
+		params["name"] = criteria.Name
+	}
+
+	if criteria.CreatedBy != "" {
+		whereBuilder.WriteString(" AND ag.createdBy = $createdBy")
+		params["createdBy"] = criteria.CreatedBy
+	}
+
+	countQuery := whereBuilder.String() + " RETURN count(ag) AS total"
+	paginationClause, paginationParams := helper_util.BuildPagination(criteria.Limit, criteria.Offset)
+	searchQuery := whereBuilder.String() + " RETURN ag" + sortClause + paginationClause
+	helper_util.MergeParams(params, paginationParams)
+
+	var total int64
+	result, err := session.ReadTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		countResult, err := transaction.Run(countQuery, params)
+		if err != nil {
+			return nil, err
+		}
+		if countResult.Next() {
+			total, _ = countResult.Record().Values[0].(int64)
+		}
+
+		result, err := transaction.Run(searchQuery, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var attributeGroups []*model.AttributeGroup
+		for result.Next() {
+			node := result.Record().Values[0].(neo4j.Node)
+			attributeGroup, err := mapNodeToAttributeGroup(node)
+			if err != nil {
+				return nil, err
+			}
+			attributeGroups = append(attributeGroups, attributeGroup)
+		}
+
+		return attributeGroups, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to search attribute groups",
+			zap.Error(err),
+			zap.Any("criteria", criteria),
+			zap.Duration("duration", duration))
+		return nil, 0, echo_errors.ErrDatabaseOperation
+	}
+
+	attributeGroups := result.([]*model.AttributeGroup)
+	logger.Info("Attribute groups searched successfully",
+		zap.Int("count", len(attributeGroups)),
+		zap.Int64("total", total),
+		zap.Duration("duration", duration))
+
+	return attributeGroups, total, nil
+}
+
 // Helper function to map Neo4j Node to AttributeGroup struct
 func mapNodeToAttributeGroup(node neo4j.Node) (*model.AttributeGroup, error) {
 	attributeGroup := &model.AttributeGroup{
@@ -323,6 +434,13 @@ func mapNodeToAttributeGroup(node neo4j.Node) (*model.AttributeGroup, error) {
 		return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
 	}
 
+	// Groups created before schemas were introduced have no schema property
+	if schemaJSON, ok := node.Props["schema"].(string); ok && schemaJSON != "" {
+		if err := json.Unmarshal([]byte(schemaJSON), &attributeGroup.Schema); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+		}
+	}
+
 	createdAt, err := time.Parse(time.RFC3339, node.Props["createdAt"].(string))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse createdAt: %w", err)
@@ -338,33 +456,56 @@ func mapNodeToAttributeGroup(node neo4j.Node) (*model.AttributeGroup, error) {
 	return attributeGroup, nil
 }
 
-func (dao *AttributeGroupDAO) DeleteAttributeGroup(ctx context.Context, id string) error {
+// DeleteAttributeGroup removes an attribute group. It refuses with ErrAttributeGroupInUse while resources
+// still reference the group, unless force is set, in which case those references are removed as well.
+func (dao *AttributeGroupDAO) DeleteAttributeGroup(ctx context.Context, id string, force bool) error {
 	start := time.Now()
-	logger.Info("Deleting attribute group", zap.String("id", id))
+	logger.Info("Deleting attribute group", zap.String("id", id), zap.Bool("force", force))
 
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
-	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
-		query := `
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		checkQuery := `
         MATCH (ag:` + echo_neo4j.LabelAttributeGroup + ` {id: $id})
-        DETACH DELETE ag
+        OPTIONAL MATCH (r:` + echo_neo4j.LabelResource + `)-[:` + echo_neo4j.RelInGroup + `]->(ag)
+        RETURN ag.id AS id, count(r) AS resourceCount
         `
-		result, err := transaction.Run(query, map[string]interface{}{"id": id})
+		result, err := transaction.Run(checkQuery, map[string]interface{}{"id": id})
 		if err != nil {
 			return nil, err
 		}
 
-		summary, err := result.Consume()
+		if !result.Next() {
+			return nil, echo_errors.ErrAttributeGroupNotFound
+		}
+
+		resourceCount, _ := result.Record().Values[1].(int64)
+		if resourceCount > 0 && !force {
+			logger.Warn("Attribute group is still in use",
+				zap.String("id", id),
+				zap.Int64("resourceCount", resourceCount))
+			return nil, echo_errors.ErrAttributeGroupInUse
+		}
+
+		deleteQuery := `
+        MATCH (ag:` + echo_neo4j.LabelAttributeGroup + ` {id: $id})
+        OPTIONAL MATCH (r:` + echo_neo4j.LabelResource + `)-[:` + echo_neo4j.RelInGroup + `]->(ag)
+        SET r.attributeGroupID = ''
+        WITH ag, count(r) AS detached
+        DETACH DELETE ag
+        RETURN detached
+        `
+		result, err = transaction.Run(deleteQuery, map[string]interface{}{"id": id})
 		if err != nil {
 			return nil, err
 		}
 
-		if summary.Counters().NodesDeleted() == 0 {
+		if !result.Next() {
 			return nil, echo_errors.ErrAttributeGroupNotFound
 		}
 
-		return nil, nil
+		return result.Record().Values[0], nil
 	})
 
 	duration := time.Since(start)
@@ -373,20 +514,27 @@ func (dao *AttributeGroupDAO) DeleteAttributeGroup(ctx context.Context, id strin
 			zap.Error(err),
 			zap.String("id", id),
 			zap.Duration("duration", duration))
-		return err
+		return classifyNeo4jError(err)
 	}
 
+	detached, _ := result.(int64)
 	logger.Info("Attribute group deleted successfully",
 		zap.String("id", id),
+		zap.Int64("detachedResources", detached),
 		zap.Duration("duration", duration))
 
 	// Audit trail
+	changeDetails := deletedDetails(helper_util.ActorFromContext(ctx), map[string]interface{}{
+		"force":             force,
+		"detachedResources": detached,
+	})
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        helper_util.ActorFromContext(ctx),
 		Action:        "DELETE_ATTRIBUTE_GROUP",
 		ResourceID:    id,
 		AccessGranted: true,
+		ChangeDetails: changeDetails,
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
@@ -0,0 +1,175 @@
+package dao_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func queryContaining(fragment string) interface{} {
+	return testify_mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, fragment)
+	})
+}
+
+func resultWithRecord(values ...interface{}) *mock.MockResult {
+	result := &mock.MockResult{}
+	result.On("Next").Return(true).Once()
+	result.On("Record").Return(&neo4j.Record{Values: values})
+	return result
+}
+
+func TestDeleteAttributeGroup(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newDAO := func() (*dao.AttributeGroupDAO, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		return &dao.AttributeGroupDAO{Driver: driver, AuditService: auditService}, tx, auditService
+	}
+
+	t.Run("DeleteAttributeGroup_InUse", func(t *testing.T) {
+		attributeGroupDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("resourceCount"), testify_mock.Anything).
+			Return(resultWithRecord("ag1", int64(2)), nil)
+
+		err := attributeGroupDAO.DeleteAttributeGroup(ctx, "ag1", false)
+
+		assert.Equal(t, echo_errors.ErrAttributeGroupInUse, err)
+		tx.AssertNotCalled(t, "Run", queryContaining("DETACH DELETE"), testify_mock.Anything)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("DeleteAttributeGroup_Forced", func(t *testing.T) {
+		attributeGroupDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("resourceCount"), testify_mock.Anything).
+			Return(resultWithRecord("ag1", int64(2)), nil)
+		tx.On("Run", queryContaining("DETACH DELETE"), testify_mock.Anything).
+			Return(resultWithRecord(int64(2)), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "DELETE_ATTRIBUTE_GROUP" && log.ResourceID == "ag1"
+		})).Return(nil)
+
+		err := attributeGroupDAO.DeleteAttributeGroup(ctx, "ag1", true)
+
+		assert.NoError(t, err)
+		tx.AssertCalled(t, "Run", queryContaining("DETACH DELETE"), testify_mock.Anything)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("DeleteAttributeGroup_ForcedLeavesResourcesReadable", func(t *testing.T) {
+		attributeGroupDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("resourceCount"), testify_mock.Anything).
+			Return(resultWithRecord("ag1", int64(1)), nil)
+		tx.On("Run", queryContaining("DETACH DELETE"), testify_mock.Anything).
+			Return(resultWithRecord(int64(1)), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+		err := attributeGroupDAO.DeleteAttributeGroup(ctx, "ag1", true)
+
+		assert.NoError(t, err)
+		// The detached resources keep an empty attributeGroupID rather than none, which reads fail on
+		tx.AssertCalled(t, "Run", queryContaining("SET r.attributeGroupID = ''"), testify_mock.Anything)
+		tx.AssertNotCalled(t, "Run", queryContaining("REMOVE r.attributeGroupID"), testify_mock.Anything)
+
+		detached := resourceNode("r1", "2024-01-01T00:00:00Z")
+		detached.Props["attributeGroupID"] = ""
+		driver := mock.NewFakeDriver().Returns("MATCH (r:", &neo4j.Record{
+			Keys:   []string{"r", "parentID", "relatedIDs"},
+			Values: []any{detached, nil, []any{}},
+		})
+		resource, err := (&dao.ResourceDAO{Driver: driver, AuditService: auditService}).GetResource(ctx, "r1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "r1", resource.ID)
+		assert.Empty(t, resource.AttributeGroupID)
+	})
+
+	t.Run("DeleteAttributeGroup_NotFound", func(t *testing.T) {
+		attributeGroupDAO, tx, _ := newDAO()
+		result := &mock.MockResult{}
+		result.On("Next").Return(false)
+		tx.On("Run", queryContaining("resourceCount"), testify_mock.Anything).Return(result, nil)
+
+		err := attributeGroupDAO.DeleteAttributeGroup(ctx, "missing", true)
+
+		assert.Equal(t, echo_errors.ErrAttributeGroupNotFound, err)
+	})
+}
+
+func TestSearchAttributeGroups(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	t.Run("SearchAttributeGroups_NameAndCreatedBy", func(t *testing.T) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		attributeGroupDAO := &dao.AttributeGroupDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		filtered := testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["name"] == "doc" && params["createdBy"] == "u1"
+		})
+
+		tx.On("Run", queryContaining("count(ag)"), filtered).
+			Return(resultWithRecord(int64(3)), nil)
+
+		searchResult := &mock.MockResult{}
+		searchResult.On("Next").Return(true).Once()
+		searchResult.On("Next").Return(false)
+		searchResult.On("Record").Return(&neo4j.Record{Values: []interface{}{neo4j.Node{Props: map[string]interface{}{
+			"id":         "ag1",
+			"name":       "Documents",
+			"attributes": "{}",
+			"createdBy":  "u1",
+			"updatedBy":  "u1",
+			"createdAt":  "2024-01-01T00:00:00Z",
+			"updatedAt":  "2024-01-01T00:00:00Z",
+		}}}})
+		tx.On("Run", queryContaining("ORDER BY ag.createdAt DESC"), filtered).Return(searchResult, nil)
+
+		attributeGroups, total, err := attributeGroupDAO.SearchAttributeGroups(ctx, model.AttributeGroupSearchCriteria{
+			Name:      "doc",
+			CreatedBy: "u1",
+			Limit:     1,
+			SortBy:    "createdAt",
+			SortOrder: "desc",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Len(t, attributeGroups, 1)
+		assert.Equal(t, "ag1", attributeGroups[0].ID)
+		tx.AssertCalled(t, "Run", queryContaining("toLower(ag.name) CONTAINS toLower($name) AND ag.createdBy = $createdBy"), filtered)
+	})
+
+	t.Run("SearchAttributeGroups_InvalidSortField", func(t *testing.T) {
+		attributeGroupDAO := &dao.AttributeGroupDAO{Driver: &mock.MockDriver{}, AuditService: &mock.MockAuditService{}}
+
+		_, _, err := attributeGroupDAO.SearchAttributeGroups(ctx, model.AttributeGroupSearchCriteria{SortBy: "name DETACH DELETE ag"})
+
+		assert.Equal(t, echo_errors.ErrInvalidSearchCriteria, err)
+	})
+}
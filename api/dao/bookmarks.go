@@ -0,0 +1,58 @@
+// api/dao/bookmarks.go
+package dao
+
+import (
+	"context"
+	"sync"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+type bookmarksKey struct{}
+
+// bookmarkHolder collects the bookmarks of the writes made under one context
+type bookmarkHolder struct {
+	mu        sync.Mutex
+	bookmarks neo4j.Bookmarks
+}
+
+// WithReadYourWrites returns a context under which reads observe every write made earlier under the
+// same context. Writes record their session's bookmarks on it and reads start their session from
+// them, so a read served by a replica waits until the replica has caught up with those writes.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(bookmarksKey{}).(*bookmarkHolder); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, bookmarksKey{}, &bookmarkHolder{})
+}
+
+// BookmarksFromContext returns the bookmarks recorded on a context prepared by WithReadYourWrites
+func BookmarksFromContext(ctx context.Context) neo4j.Bookmarks {
+	holder, ok := ctx.Value(bookmarksKey{}).(*bookmarkHolder)
+	if !ok {
+		return nil
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	return holder.bookmarks
+}
+
+// recordBookmarks stores the bookmarks of a write session on ctx, if ctx asks for read-your-writes
+func recordBookmarks(ctx context.Context, session neo4j.Session) {
+	holder, ok := ctx.Value(bookmarksKey{}).(*bookmarkHolder)
+	if !ok {
+		return
+	}
+	bookmarks := session.LastBookmarks()
+	if len(bookmarks) == 0 {
+		return
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	holder.bookmarks = bookmarks
+}
+
+// readSessionConfig returns the configuration of a read session that observes the writes recorded on ctx
+func readSessionConfig(ctx context.Context) neo4j.SessionConfig {
+	return neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead, Bookmarks: BookmarksFromContext(ctx)}
+}
@@ -0,0 +1,96 @@
+// api/dao/change_details.go
+package dao
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/dev-mohitbeniwal/echo/api/model"
+)
+
+// fieldChanges compares two states of an entity through their JSON form and returns the fields that
+// differ, by JSON name and in name order. Fields in ignored, such as "updated_at", are left out; a
+// field omitted from one side is reported as nil there.
+func fieldChanges(oldEntity, newEntity interface{}, ignored ...string) []model.FieldChange {
+	oldFields, newFields := jsonFields(oldEntity), jsonFields(newEntity)
+
+	skip := make(map[string]bool, len(ignored))
+	for _, field := range ignored {
+		skip[field] = true
+	}
+	var names []string
+	for name := range oldFields {
+		names = append(names, name)
+	}
+	for name := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []model.FieldChange{}
+	for _, name := range names {
+		if skip[name] || reflect.DeepEqual(oldFields[name], newFields[name]) {
+			continue
+		}
+		changes = append(changes, model.FieldChange{Field: name, Old: oldFields[name], New: newFields[name]})
+	}
+	return changes
+}
+
+func jsonFields(entity interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// changeDetails describes an update for the audit log: the action plus, per changed field, "old"
+// and "new" for values and "added" and "removed" for lists, so a long list with one new entry does
+// not repeat itself twice
+func changeDetails(oldEntity, newEntity interface{}, ignored ...string) json.RawMessage {
+	changes := map[string]interface{}{"action": "updated"}
+	for _, change := range fieldChanges(oldEntity, newEntity, ignored...) {
+		oldList, oldIsList := change.Old.([]interface{})
+		newList, newIsList := change.New.([]interface{})
+		if (oldIsList || change.Old == nil) && (newIsList || change.New == nil) && (oldIsList || newIsList) {
+			listChange := map[string]interface{}{}
+			if added := missingFrom(oldList, newList); len(added) > 0 {
+				listChange["added"] = added
+			}
+			if removed := missingFrom(newList, oldList); len(removed) > 0 {
+				listChange["removed"] = removed
+			}
+			if len(listChange) > 0 {
+				changes[change.Field] = listChange
+			}
+			continue
+		}
+		changes[change.Field] = map[string]interface{}{"old": change.Old, "new": change.New}
+	}
+	details, _ := json.Marshal(changes)
+	return details
+}
+
+// missingFrom returns the entries of values that list does not hold
+func missingFrom(list, values []interface{}) []interface{} {
+	var missing []interface{}
+	for _, value := range values {
+		found := false
+		for _, entry := range list {
+			if reflect.DeepEqual(entry, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,177 @@
+// api/dao/change_event_dao.go
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.uber.org/zap"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+)
+
+// ChangeEventDAO stores the change feed. The feed is itself a record of changes, so unlike the
+// other DAOs it writes no audit logs.
+type ChangeEventDAO struct {
+	Driver Neo4jDriver
+}
+
+func NewChangeEventDAO(driver Neo4jDriver) *ChangeEventDAO {
+	dao := &ChangeEventDAO{Driver: driver}
+	if err := dao.EnsureUniqueConstraint(context.Background()); err != nil {
+		logger.Fatal("Failed to ensure unique constraint for ChangeEvent", zap.Error(err))
+	}
+	return dao
+}
+
+func (dao *ChangeEventDAO) EnsureUniqueConstraint(ctx context.Context) error {
+	logger.Info("Ensuring unique constraints on ChangeEvent sequence and ChangeFeed ID")
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		queries := []string{`
+        CREATE CONSTRAINT unique_change_event_sequence IF NOT EXISTS
+        FOR (e:` + echo_neo4j.LabelChangeEvent + `) REQUIRE e.sequence IS UNIQUE
+        `, `
+        CREATE CONSTRAINT unique_change_feed_id IF NOT EXISTS
+        FOR (f:` + echo_neo4j.LabelChangeFeed + `) REQUIRE f.id IS UNIQUE
+        `}
+		for _, query := range queries {
+			if _, err := transaction.Run(query, nil); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+
+	if err != nil {
+		logger.Error("Failed to ensure unique constraints on ChangeEvent sequence and ChangeFeed ID", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully ensured unique constraints on ChangeEvent sequence and ChangeFeed ID")
+	return nil
+}
+
+// RecordChange appends a change to the feed and returns the sequence number it was given. Numbers come
+// from a single ChangeFeed node, whose write lock hands them out in commit order.
+func (dao *ChangeEventDAO) RecordChange(ctx context.Context, change model.ChangeEvent) (int64, error) {
+	start := time.Now()
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		query := `
+        MERGE (f:` + echo_neo4j.LabelChangeFeed + ` {id: 'changes'})
+        SET f.sequence = coalesce(f.sequence, 0) + 1
+        CREATE (e:` + echo_neo4j.LabelChangeEvent + ` {sequence: f.sequence})
+        SET e += $props
+        RETURN e.sequence AS sequence
+        `
+		params := map[string]interface{}{
+			"props": map[string]interface{}{
+				"entityType": change.EntityType,
+				"entityID":   change.EntityID,
+				"action":     change.Action,
+				"timestamp":  change.Timestamp.UTC().Format(time.RFC3339Nano),
+				"actor":      change.Actor,
+			},
+		}
+
+		result, err := transaction.Run(query, params)
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if result.Next() {
+			return result.Record().Values[0], nil
+		}
+		return nil, echo_errors.ErrInternalServer
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to record change",
+			zap.Error(err),
+			zap.String("entityType", change.EntityType),
+			zap.String("entityID", change.EntityID),
+			zap.String("action", change.Action),
+			zap.Duration("duration", duration))
+		return 0, classifyNeo4jError(err)
+	}
+
+	sequence, _ := result.(int64)
+	logger.Debug("Change recorded",
+		zap.Int64("sequence", sequence),
+		zap.String("entityType", change.EntityType),
+		zap.String("entityID", change.EntityID),
+		zap.Duration("duration", duration))
+	return sequence, nil
+}
+
+// ListChangesSince returns up to limit changes with a sequence number above after, oldest first
+func (dao *ChangeEventDAO) ListChangesSince(ctx context.Context, after int64, limit int) ([]*model.ChangeEvent, error) {
+	start := time.Now()
+
+	query := `
+    MATCH (e:` + echo_neo4j.LabelChangeEvent + `)
+    WHERE e.sequence > $after
+    RETURN e
+    ORDER BY e.sequence
+    LIMIT $limit
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"after": after, "limit": limit})
+	if err != nil {
+		logger.Error("Failed to execute list changes query",
+			zap.Error(err),
+			zap.Int64("after", after),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	changes := make([]*model.ChangeEvent, 0, len(records))
+	for _, record := range records {
+		change, err := mapNodeToChangeEvent(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map change event node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		changes = append(changes, change)
+	}
+
+	logger.Debug("Changes listed successfully",
+		zap.Int64("after", after),
+		zap.Int("count", len(changes)),
+		zap.Duration("duration", time.Since(start)))
+	return changes, nil
+}
+
+// Helper function to map Neo4j Node to ChangeEvent struct
+func mapNodeToChangeEvent(node neo4j.Node) (*model.ChangeEvent, error) {
+	props := node.Props
+	change := &model.ChangeEvent{}
+
+	var ok bool
+	if change.Sequence, ok = props["sequence"].(int64); !ok {
+		return nil, fmt.Errorf("invalid or missing 'sequence' property")
+	}
+	if change.EntityType, ok = props["entityType"].(string); !ok {
+		return nil, fmt.Errorf("invalid or missing 'entityType' property")
+	}
+	if change.Action, ok = props["action"].(string); !ok {
+		return nil, fmt.Errorf("invalid or missing 'action' property")
+	}
+	change.EntityID, _ = props["entityID"].(string)
+	change.Actor, _ = props["actor"].(string)
+	if timestamp, ok := props["timestamp"].(string); ok {
+		change.Timestamp = parseTime(timestamp)
+	}
+	return change, nil
+}
@@ -20,11 +20,11 @@ import (
 )
 
 type DepartmentDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewDepartmentDAO(driver neo4j.Driver, auditService audit.Service) *DepartmentDAO {
+func NewDepartmentDAO(driver Neo4jDriver, auditService audit.Service) *DepartmentDAO {
 	dao := &DepartmentDAO{Driver: driver, AuditService: auditService}
 	ctx := context.Background()
 	if err := dao.EnsureUniqueConstraint(ctx); err != nil {
@@ -65,10 +65,12 @@ func (dao *DepartmentDAO) CreateDepartment(ctx context.Context, department model
 	if department.ID == "" {
 		department.ID = uuid.New().String()
 	}
+	department.CreatedBy = changedBy(ctx, "")
+	department.UpdatedBy = department.CreatedBy
 
 	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
-		CREATE (d:` + echo_neo4j.LabelDepartment + ` {` + echo_neo4j.AttrID + `: $id, ` + echo_neo4j.AttrName + `: $name, ` + echo_neo4j.AttrOrganizationID + `: $orgId, ` + echo_neo4j.AttrParentID + `: $parentId, ` + echo_neo4j.AttrCreatedAt + `: $createdAt, ` + echo_neo4j.AttrUpdatedAt + `: $updatedAt})
+		CREATE (d:` + echo_neo4j.LabelDepartment + ` {` + echo_neo4j.AttrID + `: $id, ` + echo_neo4j.AttrName + `: $name, ` + echo_neo4j.AttrOrganizationID + `: $orgId, ` + echo_neo4j.AttrParentID + `: $parentId, ` + echo_neo4j.AttrCreatedAt + `: $createdAt, ` + echo_neo4j.AttrUpdatedAt + `: $updatedAt, ` + echo_neo4j.AttrCreatedBy + `: $createdBy, ` + echo_neo4j.AttrUpdatedBy + `: $updatedBy})
 		WITH d
 		MATCH (o:` + echo_neo4j.LabelOrganization + ` {` + echo_neo4j.AttrID + `: $orgId})
 		CREATE (d)-[:` + echo_neo4j.RelPartOf + `]->(o)
@@ -87,6 +89,8 @@ func (dao *DepartmentDAO) CreateDepartment(ctx context.Context, department model
 			"parentId":  department.ParentID,
 			"createdAt": time.Now().Format(time.RFC3339),
 			"updatedAt": time.Now().Format(time.RFC3339),
+			"createdBy": department.CreatedBy,
+			"updatedBy": department.UpdatedBy,
 		}
 
 		result, err := transaction.Run(query, params)
@@ -107,7 +111,7 @@ func (dao *DepartmentDAO) CreateDepartment(ctx context.Context, department model
 			zap.Error(err),
 			zap.String("deptName", department.Name),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrDepartmentConflict, department.ID)
 	}
 
 	deptID := fmt.Sprintf("%v", result)
@@ -127,7 +131,7 @@ func (dao *DepartmentDAO) CreateDepartment(ctx context.Context, department model
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        department.CreatedBy,
 		Action:        "CREATE_DEPARTMENT",
 		ResourceID:    deptID,
 		AccessGranted: true,
@@ -145,9 +149,9 @@ func (dao *DepartmentDAO) verifyRelationships(ctx context.Context, deptID, orgID
 	defer session.Close()
 
 	query := `
-    MATCH (d:DEPARTMENT {id: $deptID})
-    OPTIONAL MATCH (d)-[r:BELONGS_TO]->(o:ORGANIZATION)
-    OPTIONAL MATCH (d)-[p:CHILD_OF]->(parent:DEPARTMENT)
+    MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $deptID})
+    OPTIONAL MATCH (d)-[r:` + echo_neo4j.RelPartOf + `]->(o:` + echo_neo4j.LabelOrganization + `)
+    OPTIONAL MATCH (d)-[p:` + echo_neo4j.RelChildOf + `]->(parent:` + echo_neo4j.LabelDepartment + `)
     RETURN r, p, o.id as orgId, parent.id as parentId
     `
 
@@ -166,9 +170,9 @@ func (dao *DepartmentDAO) verifyRelationships(ctx context.Context, deptID, orgID
 		returnedParentID, _ := record.Get("parentId")
 
 		if orgRel == nil {
-			logger.Error("BELONGS_TO relationship not created", zap.String("deptID", deptID), zap.String("orgID", orgID))
+			logger.Error("PART_OF relationship not created", zap.String("deptID", deptID), zap.String("orgID", orgID))
 		} else {
-			logger.Info("BELONGS_TO relationship verified", zap.String("deptID", deptID), zap.String("orgID", returnedOrgID.(string)))
+			logger.Info("PART_OF relationship verified", zap.String("deptID", deptID), zap.String("orgID", returnedOrgID.(string)))
 		}
 
 		if parentID != "" && parentRel == nil {
@@ -190,6 +194,7 @@ func (dao *DepartmentDAO) UpdateDepartment(ctx context.Context, department model
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
+	department.UpdatedBy = changedBy(ctx, "")
 	var updatedDept *model.Department
 	oldDept, err := dao.GetDepartment(ctx, department.ID)
 	if err != nil {
@@ -198,28 +203,41 @@ func (dao *DepartmentDAO) UpdateDepartment(ctx context.Context, department model
 
 	_, err = session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
-        MATCH (d:DEPARTMENT {id: $id})
+        MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $id})
         SET d += $props
         WITH d
-        MATCH (o:ORGANIZATION {id: $orgId})
-        MERGE (d)-[:BELONGS_TO]->(o)
+        OPTIONAL MATCH (d)-[oldOrgRel:` + echo_neo4j.RelPartOf + `]->(:` + echo_neo4j.LabelOrganization + `)
+        DELETE oldOrgRel
+        WITH DISTINCT d
+        MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $orgId})
+        MERGE (d)-[:` + echo_neo4j.RelPartOf + `]->(o)
+        WITH d
+        OPTIONAL MATCH (d)-[oldParentRel:` + echo_neo4j.RelChildOf + `]->(:` + echo_neo4j.LabelDepartment + `)
+        DELETE oldParentRel
+        WITH DISTINCT d
+        OPTIONAL MATCH (parent:` + echo_neo4j.LabelDepartment + ` {id: $parentId})
+        FOREACH (_ IN CASE WHEN parent IS NOT NULL THEN [1] ELSE [] END |
+            MERGE (d)-[:` + echo_neo4j.RelChildOf + `]->(parent)
+        )
         RETURN d
         `
 
 		params := map[string]interface{}{
-			"id":    department.ID,
-			"orgId": department.OrganizationID,
+			"id":       department.ID,
+			"orgId":    department.OrganizationID,
+			"parentId": department.ParentID,
 			"props": map[string]interface{}{
 				"name":           department.Name,
 				"organizationID": department.OrganizationID,
 				"parentID":       department.ParentID,
 				"updatedAt":      time.Now().Format(time.RFC3339),
+				"updatedBy":      department.UpdatedBy,
 			},
 		}
 
 		result, err := transaction.Run(query, params)
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
@@ -240,7 +258,7 @@ func (dao *DepartmentDAO) UpdateDepartment(ctx context.Context, department model
 			zap.Error(err),
 			zap.String("deptID", department.ID),
 			zap.Duration("duration", duration))
-		return nil, err
+		return nil, classifyNeo4jError(err)
 	}
 
 	logger.Info("Department updated successfully",
@@ -250,7 +268,7 @@ func (dao *DepartmentDAO) UpdateDepartment(ctx context.Context, department model
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        department.UpdatedBy,
 		Action:        "UPDATE_DEPARTMENT",
 		ResourceID:    department.ID,
 		AccessGranted: true,
@@ -263,33 +281,114 @@ func (dao *DepartmentDAO) UpdateDepartment(ctx context.Context, department model
 	return updatedDept, nil
 }
 
-func (dao *DepartmentDAO) DeleteDepartment(ctx context.Context, departmentID string) error {
+// DeleteDepartment deletes a department, handling its child departments and member users according to
+// mode: restrict refuses while any exist with ErrDepartmentHasDependents, while cascade and reassign
+// move the child departments under the deleted department's parent, or make them top-level. Cascade
+// leaves the members without a department and reassign moves them to targetDeptID.
+func (dao *DepartmentDAO) DeleteDepartment(ctx context.Context, departmentID string, mode string, targetDeptID string) (*model.DepartmentDeletionSummary, error) {
 	start := time.Now()
-	logger.Info("Deleting department", zap.String("deptID", departmentID))
+	logger.Info("Deleting department",
+		zap.String("deptID", departmentID),
+		zap.String("mode", mode),
+		zap.String("targetDeptID", targetDeptID))
+
+	switch mode {
+	case model.DeptDeleteModeRestrict, model.DeptDeleteModeCascade:
+	case model.DeptDeleteModeReassign:
+		if targetDeptID == "" || targetDeptID == departmentID {
+			return nil, echo_errors.ErrInvalidDeptDeleteMode
+		}
+	default:
+		return nil, echo_errors.ErrInvalidDeptDeleteMode
+	}
+
+	actor := changedBy(ctx, "")
 
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
-	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
-		query := `
-        MATCH (d:DEPARTMENT {id: $id})
-        DETACH DELETE d
-        `
-		result, err := transaction.Run(query, map[string]interface{}{"id": departmentID})
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		summary, err := countDepartmentDependents(transaction, departmentID)
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, err
 		}
+		summary.Mode, summary.DeletedBy = mode, actor
+		updatedAt := time.Now().Format(time.RFC3339)
 
-		summary, err := result.Consume()
-		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+		if mode == model.DeptDeleteModeRestrict {
+			if len(summary.ChildDepartmentIDs) > 0 || summary.Users > 0 {
+				return nil, fmt.Errorf("%w: %d child departments %v and %d member users",
+					echo_errors.ErrDepartmentHasDependents, len(summary.ChildDepartmentIDs), summary.ChildDepartmentIDs, summary.Users)
+			}
 		}
 
-		if summary.Counters().NodesDeleted() == 0 {
-			return nil, echo_errors.ErrDepartmentNotFound
+		if mode == model.DeptDeleteModeReassign {
+			targetQuery := `
+            MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $id}), (t:` + echo_neo4j.LabelDepartment + ` {id: $targetID})
+            RETURN t.organizationID = d.organizationID AS sameOrganization
+            `
+			targetResult, err := transaction.Run(targetQuery, map[string]interface{}{"id": departmentID, "targetID": targetDeptID})
+			if err != nil {
+				return nil, classifyNeo4jError(err)
+			}
+			if !targetResult.Next() {
+				return nil, fmt.Errorf("%w: target department %s", echo_errors.ErrDepartmentNotFound, targetDeptID)
+			}
+			if sameOrganization, _ := targetResult.Record().Values[0].(bool); !sameOrganization {
+				return nil, fmt.Errorf("%w: target department %s belongs to another organization", echo_errors.ErrInvalidDeptDeleteMode, targetDeptID)
+			}
+			summary.TargetDepartmentID = targetDeptID
 		}
 
-		return nil, nil
+		if len(summary.ChildDepartmentIDs) > 0 {
+			query := `
+            MATCH (child:` + echo_neo4j.LabelDepartment + `)-[old:` + echo_neo4j.RelChildOf + `]->(d:` + echo_neo4j.LabelDepartment + ` {id: $id})
+            OPTIONAL MATCH (d)-[:` + echo_neo4j.RelChildOf + `]->(p:` + echo_neo4j.LabelDepartment + `)
+            DELETE old
+            SET child.parentID = coalesce(p.id, ''), child.updatedAt = $updatedAt, child.updatedBy = $updatedBy
+            FOREACH (parent IN CASE WHEN p IS NULL THEN [] ELSE [p] END |
+                MERGE (child)-[:` + echo_neo4j.RelChildOf + `]->(parent))
+            `
+			if _, err := transaction.Run(query, map[string]interface{}{"id": departmentID, "updatedAt": updatedAt, "updatedBy": actor}); err != nil {
+				return nil, classifyNeo4jError(err)
+			}
+		}
+
+		if summary.Users > 0 {
+			query := `
+            MATCH (u:` + echo_neo4j.LabelUser + `)
+            WHERE u.departmentID = $id
+            OPTIONAL MATCH (u)-[old:` + echo_neo4j.RelMemberOf + `]->(:` + echo_neo4j.LabelDepartment + ` {id: $id})
+            DELETE old
+            SET u.departmentID = $targetID, u.updatedAt = $updatedAt, u.updatedBy = $updatedBy
+            `
+			if mode == model.DeptDeleteModeReassign {
+				query += `
+                WITH u
+                MATCH (t:` + echo_neo4j.LabelDepartment + ` {id: $targetID})
+                MERGE (u)-[:` + echo_neo4j.RelMemberOf + `]->(t)
+                `
+			}
+			params := map[string]interface{}{
+				"id":        departmentID,
+				"targetID":  summary.TargetDepartmentID,
+				"updatedAt": updatedAt,
+				"updatedBy": actor,
+			}
+			if _, err := transaction.Run(query, params); err != nil {
+				return nil, classifyNeo4jError(err)
+			}
+		}
+
+		query := `
+        MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $id})
+        DETACH DELETE d
+        `
+		if _, err := transaction.Run(query, map[string]interface{}{"id": departmentID}); err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+
+		return summary, nil
 	})
 
 	duration := time.Since(start)
@@ -297,27 +396,74 @@ func (dao *DepartmentDAO) DeleteDepartment(ctx context.Context, departmentID str
 		logger.Error("Failed to delete department",
 			zap.Error(err),
 			zap.String("deptID", departmentID),
+			zap.String("mode", mode),
 			zap.Duration("duration", duration))
-		return err
+		return nil, classifyNeo4jError(err)
 	}
 
+	summary := result.(*model.DepartmentDeletionSummary)
 	logger.Info("Department deleted successfully",
 		zap.String("deptID", departmentID),
+		zap.Any("summary", summary),
 		zap.Duration("duration", duration))
 
 	// Audit trail
+	changeDetails, _ := json.Marshal(summary)
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        actor,
 		Action:        "DELETE_DEPARTMENT",
 		ResourceID:    departmentID,
 		AccessGranted: true,
+		ChangeDetails: changeDetails,
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
 	}
 
-	return nil
+	return summary, nil
+}
+
+// countDepartmentDependents lists the child departments of a department and counts its members,
+// returning ErrDepartmentNotFound if the department does not exist
+func countDepartmentDependents(transaction neo4j.Transaction, departmentID string) (*model.DepartmentDeletionSummary, error) {
+	query := `
+    MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $id})
+    OPTIONAL MATCH (d)-[:` + echo_neo4j.RelChildOf + `]->(parent:` + echo_neo4j.LabelDepartment + `)
+    RETURN parent.id AS parentID,
+           [(child:` + echo_neo4j.LabelDepartment + `)-[:` + echo_neo4j.RelChildOf + `]->(d) | child.id] AS childIDs,
+           COUNT { MATCH (u:` + echo_neo4j.LabelUser + `) WHERE u.departmentID = $id } AS users
+    `
+	result, err := transaction.Run(query, map[string]interface{}{"id": departmentID})
+	if err != nil {
+		return nil, echo_errors.ErrDatabaseOperation
+	}
+
+	if !result.Next() {
+		return nil, echo_errors.ErrDepartmentNotFound
+	}
+
+	record := result.Record()
+	summary := &model.DepartmentDeletionSummary{DepartmentID: departmentID, ChildDepartmentIDs: []string{}}
+	if parentID, _ := record.Get("parentID"); parentID != nil {
+		summary.NewParentID, _ = parentID.(string)
+	}
+	childIDs, _ := record.Get("childIDs")
+	if childIDs, ok := childIDs.([]interface{}); ok {
+		for _, childID := range childIDs {
+			if id, ok := childID.(string); ok {
+				summary.ChildDepartmentIDs = append(summary.ChildDepartmentIDs, id)
+			}
+		}
+	}
+	users, _ := record.Get("users")
+	summary.Users, _ = users.(int64)
+	return summary, nil
+}
+
+// Exists reports whether a department with the given ID exists, without reading the whole node
+func (dao *DepartmentDAO) Exists(ctx context.Context, deptID string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelDepartment, deptID)
 }
 
 func (dao *DepartmentDAO) GetDepartment(ctx context.Context, departmentID string) (*model.Department, error) {
@@ -328,7 +474,7 @@ func (dao *DepartmentDAO) GetDepartment(ctx context.Context, departmentID string
 	defer session.Close()
 
 	query := `
-    MATCH (d:DEPARTMENT {id: $id})
+    MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $id})
     RETURN d
     `
 	result, err := session.Run(query, map[string]interface{}{"id": departmentID})
@@ -370,7 +516,7 @@ func (dao *DepartmentDAO) ListDepartments(ctx context.Context, limit int, offset
 	defer session.Close()
 
 	query := `
-    MATCH (d:DEPARTMENT)
+    MATCH (d:` + echo_neo4j.LabelDepartment + `)
     RETURN d
     ORDER BY d.createdAt DESC
     SKIP $offset
@@ -420,6 +566,8 @@ func mapNodeToDepartment(node neo4j.Node) (*model.Department, error) {
 	}
 	dept.CreatedAt, _ = helper_util.ParseTime(props["createdAt"].(string))
 	dept.UpdatedAt, _ = helper_util.ParseTime(props["updatedAt"].(string))
+	dept.CreatedBy, _ = props["createdBy"].(string)
+	dept.UpdatedBy, _ = props["updatedBy"].(string)
 
 	return dept, nil
 }
@@ -458,7 +606,7 @@ func (dao *DepartmentDAO) GetDepartmentsByOrganization(ctx context.Context, orgI
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
 	defer session.Close()
 
-	query := `MATCH (d:DEPARTMENT)-[:BELONGS_TO]->(o:ORGANIZATION {id: $orgId})
+	query := `MATCH (d:` + echo_neo4j.LabelDepartment + `)-[:` + echo_neo4j.RelPartOf + `]->(o:` + echo_neo4j.LabelOrganization + ` {id: $orgId})
     RETURN d
     ORDER BY d.name
     `
@@ -497,27 +645,24 @@ func (dao *DepartmentDAO) GetDepartmentHierarchy(ctx context.Context, deptID str
 	start := time.Now()
 	logger.Info("Retrieving department hierarchy", zap.String("deptID", deptID))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	query := `
-    MATCH (d:DEPARTMENT {id: $deptId})
-    MATCH (d)-[:BELONGS_TO*0..]->(parent:DEPARTMENT)
+    MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $deptId})
+    MATCH path = (d)-[:` + echo_neo4j.RelChildOf + `*0..]->(parent:` + echo_neo4j.LabelDepartment + `)
     RETURN parent
-    ORDER BY length(((d)-[:BELONGS_TO*]->(parent))) DESC
+    ORDER BY length(path) DESC
     `
-	result, err := session.Run(query, map[string]interface{}{"deptId": deptID})
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"deptId": deptID})
 	if err != nil {
 		logger.Error("Failed to execute get department hierarchy query",
 			zap.Error(err),
 			zap.String("deptID", deptID),
 			zap.Duration("duration", time.Since(start)))
-		return nil, echo_errors.ErrDatabaseOperation
+		return nil, readFailure(ctx)
 	}
 
 	var hierarchy []*model.Department
-	for result.Next() {
-		node := result.Record().Values[0].(neo4j.Node)
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
 		dept, err := mapNodeToDepartment(node)
 		if err != nil {
 			logger.Error("Failed to map department node to struct",
@@ -541,26 +686,23 @@ func (dao *DepartmentDAO) GetChildDepartments(ctx context.Context, parentDeptID
 	start := time.Now()
 	logger.Info("Retrieving child departments", zap.String("parentDeptID", parentDeptID))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	query := `
-    MATCH (parent:DEPARTMENT {id: $parentId})<-[:BELONGS_TO]-(child:DEPARTMENT)
+    MATCH (parent:` + echo_neo4j.LabelDepartment + ` {id: $parentId})<-[:` + echo_neo4j.RelChildOf + `]-(child:` + echo_neo4j.LabelDepartment + `)
     RETURN child
     ORDER BY child.name
     `
-	result, err := session.Run(query, map[string]interface{}{"parentId": parentDeptID})
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"parentId": parentDeptID})
 	if err != nil {
 		logger.Error("Failed to execute get child departments query",
 			zap.Error(err),
 			zap.String("parentDeptID", parentDeptID),
 			zap.Duration("duration", time.Since(start)))
-		return nil, echo_errors.ErrDatabaseOperation
+		return nil, readFailure(ctx)
 	}
 
 	var childDepartments []*model.Department
-	for result.Next() {
-		node := result.Record().Values[0].(neo4j.Node)
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
 		dept, err := mapNodeToDepartment(node)
 		if err != nil {
 			logger.Error("Failed to map department node to struct",
@@ -587,6 +729,7 @@ func (dao *DepartmentDAO) MoveDepartment(ctx context.Context, deptID string, new
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
+	updatedBy := changedBy(ctx, "")
 	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
 		MATCH (d:` + echo_neo4j.LabelDepartment + ` {` + echo_neo4j.AttrID + `: $deptId})
@@ -594,18 +737,19 @@ func (dao *DepartmentDAO) MoveDepartment(ctx context.Context, deptID string, new
 		OPTIONAL MATCH (d)-[r:` + echo_neo4j.RelChildOf + `]->(:` + echo_neo4j.LabelDepartment + `)
 		DELETE r
 		MERGE (d)-[:` + echo_neo4j.RelChildOf + `]->(newParent)
-		SET d.` + echo_neo4j.AttrParentID + ` = $newParentId, d.` + echo_neo4j.AttrUpdatedAt + ` = $updatedAt
+		SET d.` + echo_neo4j.AttrParentID + ` = $newParentId, d.` + echo_neo4j.AttrUpdatedAt + ` = $updatedAt, d.` + echo_neo4j.AttrUpdatedBy + ` = $updatedBy
 		RETURN d
 		`
 		params := map[string]interface{}{
 			"deptId":      deptID,
 			"newParentId": newParentID,
 			"updatedAt":   time.Now().Format(time.RFC3339),
+			"updatedBy":   updatedBy,
 		}
 
 		result, err := transaction.Run(query, params)
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if !result.Next() {
@@ -622,7 +766,7 @@ func (dao *DepartmentDAO) MoveDepartment(ctx context.Context, deptID string, new
 			zap.String("deptID", deptID),
 			zap.String("newParentID", newParentID),
 			zap.Duration("duration", duration))
-		return err
+		return classifyNeo4jError(err)
 	}
 
 	logger.Info("Department moved successfully",
@@ -633,7 +777,7 @@ func (dao *DepartmentDAO) MoveDepartment(ctx context.Context, deptID string, new
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        updatedBy,
 		Action:        "MOVE_DEPARTMENT",
 		ResourceID:    deptID,
 		AccessGranted: true,
@@ -646,16 +790,16 @@ func (dao *DepartmentDAO) MoveDepartment(ctx context.Context, deptID string, new
 	return nil
 }
 
+// departmentSortFields lists the properties department searches may be ordered by
+var departmentSortFields = []string{"name", "createdAt", "updatedAt"}
+
 // SearchDepartments searches for departments based on a name pattern
 func (dao *DepartmentDAO) SearchDepartments(ctx context.Context, criteria model.DepartmentSearchCriteria) ([]*model.Department, error) {
 	start := time.Now()
 	logger.Info("Searching departments", zap.Any("criteria", criteria))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	var queryBuilder strings.Builder
-	queryBuilder.WriteString("MATCH (d:DEPARTMENT) WHERE 1=1")
+	queryBuilder.WriteString("MATCH (d:" + echo_neo4j.LabelDepartment + ") WHERE 1=1")
 
 	params := make(map[string]interface{})
 
@@ -691,40 +835,33 @@ func (dao *DepartmentDAO) SearchDepartments(ctx context.Context, criteria model.
 
 	queryBuilder.WriteString(" RETURN d")
 
-	if criteria.SortBy != "" {
-		queryBuilder.WriteString(fmt.Sprintf(" ORDER BY d.%s", criteria.SortBy))
-		if criteria.SortOrder != "" {
-			queryBuilder.WriteString(" " + strings.ToUpper(criteria.SortOrder))
-		} else {
-			queryBuilder.WriteString(" ASC")
-		}
-	} else {
-		queryBuilder.WriteString(" ORDER BY d.name ASC")
+	sortClause, err := helper_util.SafeSortClause("d", criteria.SortBy, criteria.SortOrder, departmentSortFields)
+	if err != nil {
+		logger.Warn("Invalid sort field for department search", zap.String("sortBy", criteria.SortBy))
+		return nil, err
 	}
-
-	if criteria.Offset > 0 {
-		queryBuilder.WriteString(" SKIP $offset")
-		params["offset"] = criteria.Offset
+	if sortClause == "" {
+		sortClause = " ORDER BY d.name ASC"
 	}
+	queryBuilder.WriteString(sortClause)
 
-	if criteria.Limit > 0 {
-		queryBuilder.WriteString(" LIMIT $limit")
-		params["limit"] = criteria.Limit
-	}
+	paginationClause, paginationParams := helper_util.BuildPagination(criteria.Limit, criteria.Offset)
+	queryBuilder.WriteString(paginationClause)
+	helper_util.MergeParams(params, paginationParams)
 
 	logger.Info("Executing query", zap.String("query", queryBuilder.String()), zap.Any("params", params))
 
-	result, err := session.Run(queryBuilder.String(), params)
+	records, err := readRecords(ctx, dao.Driver, queryBuilder.String(), params)
 	if err != nil {
 		logger.Error("Failed to execute search departments query",
 			zap.Error(err),
 			zap.Duration("duration", time.Since(start)))
-		return nil, echo_errors.ErrDatabaseOperation
+		return nil, readFailure(ctx)
 	}
 
 	var departments []*model.Department
-	for result.Next() {
-		node := result.Record().Values[0].(neo4j.Node)
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
 		dept, err := mapNodeToDepartment(node)
 		if err != nil {
 			logger.Error("Failed to map department node to struct",
@@ -0,0 +1,181 @@
+package dao_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestDeleteDepartmentModes(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	// Engineering sits under R&D, with Backend and Frontend below it and four members
+	newDAO := func() (*dao.DepartmentDAO, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+
+		countResult := &mock.MockResult{}
+		countResult.On("Next").Return(true).Once()
+		countResult.On("Record").Return(&neo4j.Record{
+			Keys:   []string{"parentID", "childIDs", "users"},
+			Values: []interface{}{"rnd", []interface{}{"backend", "frontend"}, int64(4)},
+		})
+		tx.On("Run", queryContaining("AS childIDs"), testify_mock.Anything).Return(countResult, nil)
+
+		return &dao.DepartmentDAO{Driver: driver, AuditService: auditService}, tx, auditService
+	}
+
+	t.Run("DeleteDepartment_Restrict", func(t *testing.T) {
+		deptDAO, tx, auditService := newDAO()
+
+		summary, err := deptDAO.DeleteDepartment(ctx, "engineering", model.DeptDeleteModeRestrict, "")
+
+		assert.Nil(t, summary)
+		assert.True(t, errors.Is(err, echo_errors.ErrDepartmentHasDependents))
+		assert.Contains(t, err.Error(), "2 child departments [backend frontend] and 4 member users")
+		tx.AssertNotCalled(t, "Run", queryContaining("DETACH DELETE d"), testify_mock.Anything)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("DeleteDepartment_CascadeReparentsChildren", func(t *testing.T) {
+		deptDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("SET child.parentID = coalesce(p.id, '')"), testify_mock.Anything).Return(&mock.MockResult{}, nil)
+		tx.On("Run", queryContaining("SET u.departmentID = $targetID"), testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["targetID"] == ""
+		})).Return(&mock.MockResult{}, nil)
+		tx.On("Run", queryContaining("DETACH DELETE d"), testify_mock.Anything).Return(&mock.MockResult{}, nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "DELETE_DEPARTMENT" && log.ResourceID == "engineering" &&
+				string(log.ChangeDetails) == `{"department_id":"engineering","mode":"cascade","new_parent_id":"rnd","child_department_ids":["backend","frontend"],"users":4,"deleted_by":"admin"}`
+		})).Return(nil)
+
+		summary, err := deptDAO.DeleteDepartment(ctx, "engineering", model.DeptDeleteModeCascade, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "rnd", summary.NewParentID)
+		assert.Equal(t, []string{"backend", "frontend"}, summary.ChildDepartmentIDs)
+		assert.Equal(t, int64(4), summary.Users)
+		tx.AssertNotCalled(t, "Run", queryContaining("MERGE (u)-[:MEMBER_OF]->(t)"), testify_mock.Anything)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("DeleteDepartment_ReassignMovesMembers", func(t *testing.T) {
+		deptDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("AS sameOrganization"), testify_mock.Anything).Return(resultWithRecord(true), nil)
+		tx.On("Run", queryContaining("SET child.parentID = coalesce(p.id, '')"), testify_mock.Anything).Return(&mock.MockResult{}, nil)
+		tx.On("Run", queryContaining("MERGE (u)-[:MEMBER_OF]->(t)"), testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["targetID"] == "platform"
+		})).Return(&mock.MockResult{}, nil)
+		tx.On("Run", queryContaining("DETACH DELETE d"), testify_mock.Anything).Return(&mock.MockResult{}, nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+		summary, err := deptDAO.DeleteDepartment(ctx, "engineering", model.DeptDeleteModeReassign, "platform")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "platform", summary.TargetDepartmentID)
+		tx.AssertCalled(t, "Run", queryContaining("MERGE (u)-[:MEMBER_OF]->(t)"), testify_mock.Anything)
+	})
+
+	t.Run("DeleteDepartment_ReassignAcrossOrganizations", func(t *testing.T) {
+		deptDAO, tx, _ := newDAO()
+		tx.On("Run", queryContaining("AS sameOrganization"), testify_mock.Anything).Return(resultWithRecord(false), nil)
+
+		_, err := deptDAO.DeleteDepartment(ctx, "engineering", model.DeptDeleteModeReassign, "elsewhere")
+
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidDeptDeleteMode))
+		tx.AssertNotCalled(t, "Run", queryContaining("DETACH DELETE d"), testify_mock.Anything)
+	})
+
+	t.Run("DeleteDepartment_ReassignWithoutTarget", func(t *testing.T) {
+		deptDAO, _, _ := newDAO()
+
+		_, err := deptDAO.DeleteDepartment(ctx, "engineering", model.DeptDeleteModeReassign, "")
+
+		assert.Equal(t, echo_errors.ErrInvalidDeptDeleteMode, err)
+	})
+}
+
+func TestDepartmentHierarchy(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	// Backend is a child of Engineering, itself a child of the top-level Company department
+	deptNode := func(id, parentID string) neo4j.Node {
+		return neo4j.Node{Props: map[string]any{
+			"id":             id,
+			"name":           id,
+			"organizationID": "org1",
+			"parentID":       parentID,
+			"createdAt":      "2024-01-01T00:00:00Z",
+			"updatedAt":      "2024-01-01T00:00:00Z",
+		}}
+	}
+	newDAO := func() (*dao.DepartmentDAO, *mock.MockTxSession, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		return &dao.DepartmentDAO{Driver: driver, AuditService: auditService}, session, tx, auditService
+	}
+
+	t.Run("GetDepartmentHierarchy_FollowsChildOf", func(t *testing.T) {
+		deptDAO, session, _, _ := newDAO()
+		ancestry := &mock.MockResult{}
+		for _, node := range []neo4j.Node{deptNode("company", ""), deptNode("engineering", "company"), deptNode("backend", "engineering")} {
+			ancestry.On("Next").Return(true).Once()
+			ancestry.On("Record").Return(&neo4j.Record{Values: []any{node}}).Once()
+		}
+		ancestry.On("Next").Return(false)
+		session.On("Run", queryContaining("-[:"+echo_neo4j.RelChildOf+"*0..]->(parent:"), testify_mock.Anything, testify_mock.Anything).
+			Return(ancestry, nil)
+
+		hierarchy, err := deptDAO.GetDepartmentHierarchy(ctx, "backend")
+
+		assert.NoError(t, err)
+		var ids []string
+		for _, dept := range hierarchy {
+			ids = append(ids, dept.ID)
+		}
+		assert.Equal(t, []string{"company", "engineering", "backend"}, ids)
+		session.AssertNotCalled(t, "Run", queryContaining(echo_neo4j.RelBelongsTo), testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("UpdateDepartment_RelinksParent", func(t *testing.T) {
+		deptDAO, session, tx, auditService := newDAO()
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).
+			Return(resultWithRecord(deptNode("backend", "engineering")), nil)
+		tx.On("Run", queryContaining("MERGE (d)-[:"+echo_neo4j.RelChildOf+"]->(parent)"), testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["parentId"] == "platform"
+		})).Return(resultWithRecord(deptNode("backend", "platform")), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+		dept, err := deptDAO.UpdateDepartment(ctx, model.Department{ID: "backend", Name: "backend", OrganizationID: "org1", ParentID: "platform"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "platform", dept.ParentID)
+		tx.AssertCalled(t, "Run", queryContaining("DELETE oldParentRel"), testify_mock.Anything)
+	})
+}
@@ -0,0 +1,21 @@
+// api/dao/driver.go
+package dao
+
+import (
+	"context"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Neo4jDriver is what the DAOs need of a Neo4j driver: sessions to run their queries in. neo4j.Driver
+// implements it, as does the in-memory mock.FakeDriver the DAOs are tested against.
+type Neo4jDriver interface {
+	NewSession(config neo4j.SessionConfig) neo4j.Session
+}
+
+// ContextDriver is implemented by the drivers that can also open sessions honoring a context, as the
+// driver db.InitNeo4j creates does. readRecords runs its queries in those when it can, so a done
+// context stops them. NewContextSession reports false when the driver it wraps cannot open one.
+type ContextDriver interface {
+	NewContextSession(ctx context.Context, config neo4j.SessionConfig) (neo4j.SessionWithContext, bool)
+}
@@ -0,0 +1,37 @@
+// api/dao/dry_run.go
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+// errDryRunRollback makes the driver roll back a transaction whose work succeeded during a dry run
+var errDryRunRollback = errors.New("dry run: transaction rolled back")
+
+// writeTransaction runs work in a write transaction. Under a context prepared by helper_util.WithDryRun
+// the work runs with all its checks but the transaction is rolled back instead of committed, and the
+// result the work produced is returned as if it had been.
+func writeTransaction(ctx context.Context, session neo4j.Session, work neo4j.TransactionWork) (interface{}, error) {
+	if !helper_util.IsDryRun(ctx) {
+		return session.WriteTransaction(work)
+	}
+
+	var result interface{}
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		var workErr error
+		result, workErr = work(transaction)
+		if workErr != nil {
+			return nil, workErr
+		}
+		return nil, errDryRunRollback
+	})
+	if err != nil && !errors.Is(err, errDryRunRollback) {
+		return nil, err
+	}
+	return result, nil
+}
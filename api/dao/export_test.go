@@ -0,0 +1,4 @@
+package dao
+
+// ClassifyNeo4jError exposes classifyNeo4jError to the tests of the dao_test package
+var ClassifyNeo4jError = classifyNeo4jError
@@ -0,0 +1,83 @@
+package dao_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// graphNames returns the labels and relationship types declared in the echo_neo4j package
+func graphNames(t *testing.T) []string {
+	var names []string
+	for _, file := range []string{"nodes.go", "relationships.go"} {
+		parsed, err := parser.ParseFile(token.NewFileSet(), filepath.Join("..", "model", "neo4j", file), nil, 0)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", file, err)
+		}
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			if spec, ok := node.(*ast.ValueSpec); ok {
+				for _, value := range spec.Values {
+					if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						name, _ := strconv.Unquote(lit.Value)
+						names = append(names, name)
+					}
+				}
+			}
+			return true
+		})
+	}
+	// Longer names first, so BELONGS_TO_GROUP is not reported as BELONGS_TO
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	return names
+}
+
+// TestQueriesUseGraphConstants fails on any query in the package spelling out a label or relationship
+// type, such as "(u)-[:MEMBER_OF]->(d:DEPARTMENT)", instead of naming it through echo_neo4j: a typo or
+// a renamed relationship then silently matches nothing.
+func TestQueriesUseGraphConstants(t *testing.T) {
+	names := graphNames(t)
+	if len(names) == 0 {
+		t.Fatal("no labels or relationship types found in the echo_neo4j package")
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	literal := regexp.MustCompile(`[\w(\[]:(` + strings.Join(quoted, "|") + `)[\s(){}\[\]*|]`)
+
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileSet := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fileSet, file, nil, 0)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", file, err)
+		}
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			lit, ok := node.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return true
+			}
+			// A literal ending with the name is followed by more of the query, so pad it
+			for _, match := range literal.FindAllStringSubmatch(value+" ", -1) {
+				t.Errorf("%s: %q is spelled out rather than named through echo_neo4j", fileSet.Position(lit.Pos()), match[1])
+			}
+			return true
+		})
+	}
+}
@@ -19,11 +19,11 @@ import (
 )
 
 type GroupDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewGroupDAO(driver neo4j.Driver, auditService audit.Service) *GroupDAO {
+func NewGroupDAO(driver Neo4jDriver, auditService audit.Service) *GroupDAO {
 	dao := &GroupDAO{Driver: driver, AuditService: auditService}
 	ctx := context.Background()
 	if err := dao.EnsureUniqueConstraint(ctx); err != nil {
@@ -64,16 +64,20 @@ func (dao *GroupDAO) CreateGroup(ctx context.Context, group model.Group) (string
 	if group.ID == "" {
 		group.ID = uuid.New().String()
 	}
+	group.CreatedBy = changedBy(ctx, "")
+	group.UpdatedBy = group.CreatedBy
 
 	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
-			MERGE (g:` + echo_neo4j.LabelGroup + ` {id: $id})
-			ON CREATE SET 
+			CREATE (g:` + echo_neo4j.LabelGroup + ` {id: $id})
+			SET
 				g.name = $name,
 				g.description = $description,
 				g.organizationID = $organizationID,
 				g.createdAt = $createdAt,
-				g.updatedAt = $updatedAt
+				g.updatedAt = $updatedAt,
+				g.createdBy = $createdBy,
+				g.updatedBy = $updatedBy
 		`
 
 		if group.DepartmentID != "" {
@@ -123,6 +127,8 @@ func (dao *GroupDAO) CreateGroup(ctx context.Context, group model.Group) (string
 			"organizationID": group.OrganizationID,
 			"createdAt":      now,
 			"updatedAt":      now,
+			"createdBy":      group.CreatedBy,
+			"updatedBy":      group.UpdatedBy,
 		}
 
 		if group.DepartmentID != "" {
@@ -147,7 +153,7 @@ func (dao *GroupDAO) CreateGroup(ctx context.Context, group model.Group) (string
 		result, err := transaction.Run(query, params)
 		if err != nil {
 			logger.Error("Failed to execute create group query", zap.Error(err))
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
@@ -168,7 +174,7 @@ func (dao *GroupDAO) CreateGroup(ctx context.Context, group model.Group) (string
 			zap.Error(err),
 			zap.String("groupName", group.Name),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrGroupConflict, group.ID)
 	}
 
 	groupID := fmt.Sprintf("%v", result)
@@ -179,7 +185,7 @@ func (dao *GroupDAO) CreateGroup(ctx context.Context, group model.Group) (string
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        group.CreatedBy,
 		Action:        "CREATE_GROUP",
 		ResourceID:    groupID,
 		AccessGranted: true,
@@ -195,7 +201,7 @@ func (dao *GroupDAO) CreateGroup(ctx context.Context, group model.Group) (string
 func (dao *GroupDAO) createAuditLog(ctx context.Context, action, resourceID string, oldGroup, newGroup *model.Group) error {
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        helper_util.ActorFromContext(ctx),
 		Action:        action,
 		ResourceID:    resourceID,
 		AccessGranted: true,
@@ -211,6 +217,7 @@ func (dao *GroupDAO) UpdateGroup(ctx context.Context, group model.Group) (*model
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
+	group.UpdatedBy = changedBy(ctx, "")
 	var updatedGroup *model.Group
 	oldGroup, err := dao.GetGroup(ctx, group.ID)
 	if err != nil {
@@ -240,6 +247,7 @@ func (dao *GroupDAO) UpdateGroup(ctx context.Context, group model.Group) (*model
 				echo_neo4j.AttrName:        group.Name,
 				echo_neo4j.AttrDescription: group.Description,
 				echo_neo4j.AttrUpdatedAt:   time.Now().Format(time.RFC3339),
+				echo_neo4j.AttrUpdatedBy:   group.UpdatedBy,
 			},
 		}
 
@@ -310,7 +318,7 @@ func (dao *GroupDAO) UpdateGroup(ctx context.Context, group model.Group) (*model
 			zap.Error(err),
 			zap.String(echo_neo4j.AttrID, group.ID),
 			zap.Duration("duration", duration))
-		return nil, err
+		return nil, classifyNeo4jError(err)
 	}
 
 	logger.Info("Group updated successfully",
@@ -338,12 +346,12 @@ func (dao *GroupDAO) DeleteGroup(ctx context.Context, groupID string) error {
         `
 		result, err := transaction.Run(query, map[string]interface{}{"id": groupID})
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		summary, err := result.Consume()
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if summary.Counters().NodesDeleted() == 0 {
@@ -359,7 +367,7 @@ func (dao *GroupDAO) DeleteGroup(ctx context.Context, groupID string) error {
 			zap.Error(err),
 			zap.String("groupID", groupID),
 			zap.Duration("duration", duration))
-		return err
+		return classifyNeo4jError(err)
 	}
 
 	logger.Info("Group deleted successfully",
@@ -369,10 +377,11 @@ func (dao *GroupDAO) DeleteGroup(ctx context.Context, groupID string) error {
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        helper_util.ActorFromContext(ctx),
 		Action:        "DELETE_GROUP",
 		ResourceID:    groupID,
 		AccessGranted: true,
+		ChangeDetails: deletedDetails(helper_util.ActorFromContext(ctx), nil),
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
@@ -381,6 +390,11 @@ func (dao *GroupDAO) DeleteGroup(ctx context.Context, groupID string) error {
 	return nil
 }
 
+// Exists reports whether a group with the given ID exists, without reading the whole node
+func (dao *GroupDAO) Exists(ctx context.Context, groupID string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelGroup, groupID)
+}
+
 func (dao *GroupDAO) GetGroup(ctx context.Context, groupID string) (*model.Group, error) {
 	start := time.Now()
 	logger.Info("Retrieving group", zap.String("groupID", groupID))
@@ -391,8 +405,9 @@ func (dao *GroupDAO) GetGroup(ctx context.Context, groupID string) (*model.Group
 	query := `
     MATCH (g:` + echo_neo4j.LabelGroup + ` {id: $id})
     OPTIONAL MATCH (g)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + `)
-    WITH g, COLLECT(r.id) AS roleIds
-    RETURN g, roleIds
+    OPTIONAL MATCH (g)-[:` + echo_neo4j.RelSubgroupOf + `]->(parent:` + echo_neo4j.LabelGroup + `)
+    WITH g, COLLECT(r.id) AS roleIds, parent.id AS parentGroupID
+    RETURN g, roleIds, parentGroupID
     `
 	result, err := session.Run(query, map[string]interface{}{"id": groupID})
 	if err != nil {
@@ -423,6 +438,10 @@ func (dao *GroupDAO) GetGroup(ctx context.Context, groupID string) (*model.Group
 			group.Roles[i] = roleID.(string)
 		}
 
+		if parentGroupID, ok := record.Values[2].(string); ok {
+			group.ParentGroupID = parentGroupID
+		}
+
 		// Get attributes from the node
 		if attrs, exists := node.Props["attributes"].(map[string]interface{}); exists {
 			group.Attributes = make(map[string]string)
@@ -497,6 +516,194 @@ func (dao *GroupDAO) ListGroups(ctx context.Context, limit int, offset int) ([]*
 	return groups, nil
 }
 
+// SetParentGroup nests a group under parentGroupID, or makes it a top-level group when parentGroupID is empty.
+// Links that would make a group its own ancestor are rejected with ErrGroupCycle.
+func (dao *GroupDAO) SetParentGroup(ctx context.Context, groupID string, parentGroupID string) error {
+	start := time.Now()
+	logger.Info("Setting parent group", zap.String("groupID", groupID), zap.String("parentGroupID", parentGroupID))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		if parentGroupID != "" {
+			checkQuery := `
+            MATCH (g:` + echo_neo4j.LabelGroup + ` {id: $groupID})
+            OPTIONAL MATCH (p:` + echo_neo4j.LabelGroup + ` {id: $parentGroupID})
+            OPTIONAL MATCH cycle = (p)-[:` + echo_neo4j.RelSubgroupOf + `*0..]->(g)
+            RETURN p IS NOT NULL AS parentExists, count(cycle) > 0 AS createsCycle
+            `
+			result, err := transaction.Run(checkQuery, map[string]interface{}{
+				"groupID":       groupID,
+				"parentGroupID": parentGroupID,
+			})
+			if err != nil {
+				return nil, classifyNeo4jError(err)
+			}
+			if !result.Next() {
+				return nil, echo_errors.ErrGroupNotFound
+			}
+
+			record := result.Record()
+			if parentExists, _ := record.Values[0].(bool); !parentExists {
+				return nil, fmt.Errorf("%w: parent group %s", echo_errors.ErrGroupNotFound, parentGroupID)
+			}
+			if createsCycle, _ := record.Values[1].(bool); createsCycle {
+				return nil, echo_errors.ErrGroupCycle
+			}
+		}
+
+		query := `
+        MATCH (g:` + echo_neo4j.LabelGroup + ` {id: $groupID})
+        OPTIONAL MATCH (g)-[old:` + echo_neo4j.RelSubgroupOf + `]->(:` + echo_neo4j.LabelGroup + `)
+        DELETE old
+        WITH DISTINCT g
+        `
+		if parentGroupID != "" {
+			query += `
+            MATCH (p:` + echo_neo4j.LabelGroup + ` {id: $parentGroupID})
+            MERGE (g)-[:` + echo_neo4j.RelSubgroupOf + `]->(p)
+            WITH g
+            `
+		}
+		query += `
+        RETURN g.id
+        `
+
+		result, err := transaction.Run(query, map[string]interface{}{
+			"groupID":       groupID,
+			"parentGroupID": parentGroupID,
+		})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if !result.Next() {
+			return nil, echo_errors.ErrGroupNotFound
+		}
+		return nil, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to set parent group",
+			zap.Error(err),
+			zap.String("groupID", groupID),
+			zap.String("parentGroupID", parentGroupID),
+			zap.Duration("duration", duration))
+		return classifyNeo4jError(err)
+	}
+
+	logger.Info("Parent group set successfully",
+		zap.String("groupID", groupID),
+		zap.String("parentGroupID", parentGroupID),
+		zap.Duration("duration", duration))
+
+	// Audit trail
+	changeDetails, _ := json.Marshal(map[string]interface{}{
+		"action":        "updated",
+		"parentGroupID": parentGroupID,
+	})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        helper_util.ActorFromContext(ctx),
+		Action:        "SET_PARENT_GROUP",
+		ResourceID:    groupID,
+		AccessGranted: true,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return nil
+}
+
+// GetGroupHierarchy retrieves the chain of groups from the top-level ancestor down to the given group
+func (dao *GroupDAO) GetGroupHierarchy(ctx context.Context, groupID string) ([]*model.Group, error) {
+	start := time.Now()
+	logger.Info("Retrieving group hierarchy", zap.String("groupID", groupID))
+
+	query := `
+    MATCH path = (g:` + echo_neo4j.LabelGroup + ` {id: $groupID})-[:` + echo_neo4j.RelSubgroupOf + `*0..]->(ancestor:` + echo_neo4j.LabelGroup + `)
+    OPTIONAL MATCH (ancestor)-[:` + echo_neo4j.RelSubgroupOf + `]->(parent:` + echo_neo4j.LabelGroup + `)
+    RETURN ancestor, parent.id AS parentGroupID
+    ORDER BY length(path) DESC
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"groupID": groupID})
+	if err != nil {
+		logger.Error("Failed to execute get group hierarchy query",
+			zap.Error(err),
+			zap.String("groupID", groupID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	var hierarchy []*model.Group
+	for _, record := range records {
+		group, err := mapNodeToGroup(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map group node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		if parentGroupID, ok := record.Values[1].(string); ok {
+			group.ParentGroupID = parentGroupID
+		}
+		hierarchy = append(hierarchy, group)
+	}
+
+	if len(hierarchy) == 0 {
+		return nil, echo_errors.ErrGroupNotFound
+	}
+
+	logger.Info("Group hierarchy retrieved successfully",
+		zap.String("groupID", groupID),
+		zap.Int("hierarchyDepth", len(hierarchy)),
+		zap.Duration("duration", time.Since(start)))
+
+	return hierarchy, nil
+}
+
+// GetEffectiveGroupMembers retrieves the users who belong to the group directly or through any nested subgroup
+func (dao *GroupDAO) GetEffectiveGroupMembers(ctx context.Context, groupID string) ([]*model.User, error) {
+	start := time.Now()
+	logger.Info("Retrieving effective group members", zap.String("groupID", groupID))
+
+	query := `
+    MATCH (u:` + echo_neo4j.LabelUser + `)-[:` + echo_neo4j.RelBelongsToGroup + `]->(:` + echo_neo4j.LabelGroup + `)-[:` + echo_neo4j.RelSubgroupOf + `*0..]->(g:` + echo_neo4j.LabelGroup + ` {id: $groupID})
+    RETURN DISTINCT u
+    ORDER BY u.name
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"groupID": groupID})
+	if err != nil {
+		logger.Error("Failed to execute get effective group members query",
+			zap.Error(err),
+			zap.String("groupID", groupID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	var users []*model.User
+	for _, record := range records {
+		user, err := mapNodeToUser(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map user node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		users = append(users, user)
+	}
+
+	logger.Info("Effective group members retrieved successfully",
+		zap.String("groupID", groupID),
+		zap.Int("count", len(users)),
+		zap.Duration("duration", time.Since(start)))
+
+	return users, nil
+}
+
 // Helper function to map Neo4j Node to Group struct
 func mapNodeToGroup(node neo4j.Node) (*model.Group, error) {
 	props := node.Props
@@ -598,6 +805,10 @@ func mapNodeToGroup(node neo4j.Node) (*model.Group, error) {
 		return nil, fmt.Errorf("failed to parse UpdatedAt: %w", err)
 	}
 
+	// CreatedBy and UpdatedBy (optional, missing on groups stored before they were recorded)
+	group.CreatedBy, _ = props[echo_neo4j.AttrCreatedBy].(string)
+	group.UpdatedBy, _ = props[echo_neo4j.AttrUpdatedBy].(string)
+
 	logger.Debug("Successfully mapped node to group", zap.Any("group", group))
 	return group, nil
 }
@@ -0,0 +1,94 @@
+package dao_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestNestedGroups(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newDriver := func() (*mock.MockDriver, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return driver, tx, &mock.MockAuditService{}
+	}
+
+	t.Run("SetParentGroup_Nests", func(t *testing.T) {
+		driver, tx, auditService := newDriver()
+		groupDAO := &dao.GroupDAO{Driver: driver, AuditService: auditService}
+		tx.On("Run", queryContaining("createsCycle"), testify_mock.Anything).
+			Return(resultWithRecord(true, false), nil)
+		tx.On("Run", queryContaining("MERGE (g)-[:"+echo_neo4j.RelSubgroupOf+"]->(p)"), testify_mock.Anything).
+			Return(resultWithRecord("inner"), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "SET_PARENT_GROUP" && log.ResourceID == "inner"
+		})).Return(nil)
+
+		err := groupDAO.SetParentGroup(ctx, "inner", "outer")
+
+		assert.NoError(t, err)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("SetParentGroup_RejectsCycle", func(t *testing.T) {
+		driver, tx, auditService := newDriver()
+		groupDAO := &dao.GroupDAO{Driver: driver, AuditService: auditService}
+		tx.On("Run", queryContaining("createsCycle"), testify_mock.Anything).
+			Return(resultWithRecord(true, true), nil)
+
+		err := groupDAO.SetParentGroup(ctx, "outer", "inner")
+
+		assert.Equal(t, echo_errors.ErrGroupCycle, err)
+		tx.AssertNotCalled(t, "Run", queryContaining("MERGE"), testify_mock.Anything)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("InnerGroupMemberInheritsOuterGroupRole", func(t *testing.T) {
+		// u1 belongs to "inner", which is a SUBGROUP_OF "outer"; only "outer" holds role "editor". The
+		// fake driver runs no Cypher, so the traversal reaching "outer" is checked on the query itself.
+		driver := mock.NewFakeDriver().Returns("AS permissionIDs", &neo4j.Record{Values: []any{
+			[]interface{}{"inner", "outer"},
+			[]interface{}{"editor"},
+			[]interface{}{"perm-edit"},
+		}})
+		userDAO := &dao.UserDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		effective, err := userDAO.GetEffectivePermissions(ctx, "u1")
+
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"inner", "outer"}, effective.GroupIDs)
+		assert.Equal(t, []string{"editor"}, effective.RoleIDs)
+		assert.Equal(t, []string{"perm-edit"}, effective.PermissionIDs)
+
+		queries := driver.QueriesContaining("AS permissionIDs")
+		if !assert.Len(t, queries, 1) {
+			return
+		}
+		query := strings.Join(strings.Fields(queries[0].Cypher), " ")
+		assert.Equal(t, map[string]any{"id": "u1"}, queries[0].Params)
+		// Every group above the user's own, the own included, is collected through any depth of nesting
+		assert.Contains(t, query, "(u)-[:"+echo_neo4j.RelBelongsToGroup+"]->(:"+echo_neo4j.LabelGroup+")-[:"+echo_neo4j.RelSubgroupOf+"*0..]->(g:"+echo_neo4j.LabelGroup+") WITH u, collect(DISTINCT g.id) AS groupIDs")
+		// The roles held by any of those groups are inherited, and their permissions granted
+		assert.Contains(t, query, "(g:"+echo_neo4j.LabelGroup+")-[:"+echo_neo4j.RelHasRole+"]->(inherited:"+echo_neo4j.LabelRole+") WHERE g.id IN groupIDs")
+		assert.Contains(t, query, "roleIDs OPTIONAL MATCH (r:"+echo_neo4j.LabelRole+")-[:"+echo_neo4j.RelHasPermission+"]->(p:"+echo_neo4j.LabelPermission+") WHERE r.id IN roleIDs")
+	})
+}
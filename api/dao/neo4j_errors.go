@@ -0,0 +1,79 @@
+// api/dao/neo4j_errors.go
+package dao
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+)
+
+// Neo4j status codes of writes rejected by a uniqueness or existence constraint
+var constraintErrorCodes = map[string]bool{
+	"Neo.ClientError.Schema.ConstraintValidationFailed":      true,
+	"Neo.ClientError.Schema.ConstraintViolation":             true,
+	"Neo.ClientError.Statement.ConstraintVerificationFailed": true,
+}
+
+// Neo4j status codes of queries the server could not make sense of, which are bugs rather than failures
+var syntaxErrorCodes = map[string]bool{
+	"Neo.ClientError.Statement.SyntaxError":      true,
+	"Neo.ClientError.Statement.SemanticError":    true,
+	"Neo.ClientError.Statement.ParameterMissing": true,
+}
+
+// classifyNeo4jError tells apart the failures the driver reports, so a duplicate ID is answered with a
+// conflict rather than a server error: err is wrapped with ErrConstraintViolation, ErrSyntax,
+// ErrTransient for failures worth retrying such as a lost connection or a deadlock, and
+// ErrDatabaseOperation for any other driver failure. The driver's error stays in the chain, so the
+// driver keeps retrying transient failures of transaction work. Errors that did not come from the
+// driver, such as the sentinels transaction work returns, and errors already classified pass through.
+func classifyNeo4jError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, classified := range []error{echo_errors.ErrConstraintViolation, echo_errors.ErrSyntax, echo_errors.ErrTransient, echo_errors.ErrDatabaseOperation} {
+		if errors.Is(err, classified) {
+			return err
+		}
+	}
+
+	var sentinel error
+	var neo4jErr *neo4j.Neo4jError
+	var connectivityErr *neo4j.ConnectivityError
+	var limitErr *neo4j.TransactionExecutionLimit
+	var usageErr *neo4j.UsageError
+	switch {
+	case errors.As(err, &neo4jErr):
+		switch {
+		case constraintErrorCodes[neo4jErr.Code]:
+			sentinel = echo_errors.ErrConstraintViolation
+		case syntaxErrorCodes[neo4jErr.Code]:
+			sentinel = echo_errors.ErrSyntax
+		case neo4jErr.IsRetriable():
+			sentinel = echo_errors.ErrTransient
+		default:
+			sentinel = echo_errors.ErrDatabaseOperation
+		}
+	case errors.As(err, &connectivityErr), errors.As(err, &limitErr), neo4j.IsRetryable(err):
+		sentinel = echo_errors.ErrTransient
+	case errors.As(err, &usageErr):
+		sentinel = echo_errors.ErrDatabaseOperation
+	default:
+		return err
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
+
+// duplicateIDConflict reports a create rejected by the uniqueness constraint on IDs as conflict, the
+// sentinel of the entity created, so a client reusing an ID is answered with that entity's 409 rather
+// than a server error. Other errors are classified as classifyNeo4jError does.
+func duplicateIDConflict(err error, conflict error, id string) error {
+	err = classifyNeo4jError(err)
+	if errors.Is(err, echo_errors.ErrConstraintViolation) && !errors.Is(err, conflict) {
+		return fmt.Errorf("%w: id %q is already in use: %w", conflict, id, err)
+	}
+	return err
+}
@@ -0,0 +1,178 @@
+package dao_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestClassifyNeo4jError(t *testing.T) {
+	duplicateID := &neo4j.Neo4jError{
+		Code: "Neo.ClientError.Schema.ConstraintValidationFailed",
+		Msg:  "Node(12) already exists with label `Department` and property `id` = 'd1'",
+	}
+	classified := map[string]struct {
+		err      error
+		sentinel error
+	}{
+		"Duplicate ID":         {duplicateID, echo_errors.ErrConstraintViolation},
+		"Wrapped duplicate ID": {fmt.Errorf("failed to execute query: %w", duplicateID), echo_errors.ErrConstraintViolation},
+		"Syntax error":         {&neo4j.Neo4jError{Code: "Neo.ClientError.Statement.SyntaxError"}, echo_errors.ErrSyntax},
+		"Missing parameter":    {&neo4j.Neo4jError{Code: "Neo.ClientError.Statement.ParameterMissing"}, echo_errors.ErrSyntax},
+		"Deadlock":             {&neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.DeadlockDetected"}, echo_errors.ErrTransient},
+		"Leader switch":        {&neo4j.Neo4jError{Code: "Neo.ClientError.Cluster.NotALeader"}, echo_errors.ErrTransient},
+		"Lost connection":      {&neo4j.ConnectivityError{Inner: errors.New("connection reset")}, echo_errors.ErrTransient},
+		"Retries exhausted":    {&neo4j.TransactionExecutionLimit{Cause: "timeout"}, echo_errors.ErrTransient},
+		"Database failure":     {&neo4j.Neo4jError{Code: "Neo.DatabaseError.General.UnknownError"}, echo_errors.ErrDatabaseOperation},
+		"Driver misuse":        {&neo4j.UsageError{Message: "session closed"}, echo_errors.ErrDatabaseOperation},
+	}
+	for name, c := range classified {
+		t.Run(name, func(t *testing.T) {
+			err := dao.ClassifyNeo4jError(c.err)
+
+			assert.ErrorIs(t, err, c.sentinel)
+			assert.ErrorIs(t, err, c.err)
+		})
+	}
+
+	t.Run("Transient errors stay retryable", func(t *testing.T) {
+		err := dao.ClassifyNeo4jError(&neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.DeadlockDetected"})
+
+		assert.True(t, neo4j.IsRetryable(err))
+	})
+
+	t.Run("Other errors pass through", func(t *testing.T) {
+		assert.Nil(t, dao.ClassifyNeo4jError(nil))
+		assert.Equal(t, echo_errors.ErrDepartmentConflict, dao.ClassifyNeo4jError(echo_errors.ErrDepartmentConflict))
+		classified := dao.ClassifyNeo4jError(duplicateID)
+		assert.Equal(t, classified, dao.ClassifyNeo4jError(classified))
+	})
+}
+
+func TestWriteErrorClassification(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+	newDAO := func(runErr error) *dao.DepartmentDAO {
+		tx := &mock.MockTransaction{}
+		tx.On("Run", testify_mock.Anything, testify_mock.Anything).Return(nil, runErr)
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return &dao.DepartmentDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+	}
+	department := model.Department{ID: "d1", Name: "Engineering", OrganizationID: "o1"}
+
+	t.Run("A duplicate ID is a conflict", func(t *testing.T) {
+		_, err := newDAO(&neo4j.Neo4jError{Code: "Neo.ClientError.Schema.ConstraintValidationFailed"}).CreateDepartment(ctx, department)
+
+		assert.ErrorIs(t, err, echo_errors.ErrDepartmentConflict)
+		assert.ErrorIs(t, err, echo_errors.ErrConstraintViolation)
+		status, code := util.MapError(err)
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, "DEPARTMENT_CONFLICT", code)
+	})
+
+	t.Run("A lost connection is a transient failure", func(t *testing.T) {
+		_, err := newDAO(&neo4j.ConnectivityError{Inner: errors.New("connection reset")}).CreateDepartment(ctx, department)
+
+		status, code := util.MapError(err)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, "DATABASE_UNAVAILABLE", code)
+	})
+
+	t.Run("Any other failure is a server error", func(t *testing.T) {
+		_, err := newDAO(&neo4j.Neo4jError{Code: "Neo.DatabaseError.General.UnknownError"}).CreateDepartment(ctx, department)
+
+		status, code := util.MapError(err)
+		assert.Equal(t, http.StatusInternalServerError, status)
+		assert.Equal(t, "DATABASE_ERROR", code)
+	})
+}
+
+func TestCreateWithDuplicateID(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+	// newDriver serves a database where every ID is already taken: creating a node trips the uniqueness
+	// constraint, while other queries find nothing
+	newDriver := func() *mock.MockDriver {
+		tx := &mock.MockTransaction{}
+		tx.On("Run", queryContaining("CREATE ("), testify_mock.Anything).
+			Return(nil, &neo4j.Neo4jError{Code: "Neo.ClientError.Schema.ConstraintValidationFailed"})
+		empty := &mock.MockResult{}
+		empty.On("Next").Return(false)
+		tx.On("Run", testify_mock.Anything, testify_mock.Anything).Return(empty, nil)
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return driver
+	}
+
+	creates := map[string]struct {
+		create   func(driver *mock.MockDriver) error
+		conflict error
+		code     string
+	}{
+		"Organization": {func(driver *mock.MockDriver) error {
+			_, err := (&dao.OrganizationDAO{Driver: driver}).CreateOrganization(ctx, model.Organization{ID: "taken", Name: "Acme"})
+			return err
+		}, echo_errors.ErrOrganizationConflict, "ORGANIZATION_CONFLICT"},
+		"Role": {func(driver *mock.MockDriver) error {
+			_, err := (&dao.RoleDAO{Driver: driver}).CreateRole(ctx, model.Role{ID: "taken", Name: "Editor"})
+			return err
+		}, echo_errors.ErrRoleConflict, "ROLE_CONFLICT"},
+		"Group": {func(driver *mock.MockDriver) error {
+			_, err := (&dao.GroupDAO{Driver: driver}).CreateGroup(ctx, model.Group{ID: "taken", Name: "Editors"})
+			return err
+		}, echo_errors.ErrGroupConflict, "GROUP_CONFLICT"},
+		"Permission": {func(driver *mock.MockDriver) error {
+			_, err := (&dao.PermissionDAO{Driver: driver}).CreatePermission(ctx, model.Permission{ID: "taken", Name: "Read", Action: "read"})
+			return err
+		}, echo_errors.ErrPermissionConflict, "PERMISSION_CONFLICT"},
+		"User": {func(driver *mock.MockDriver) error {
+			_, err := (&dao.UserDAO{Driver: driver}).CreateUser(ctx, model.User{ID: "taken", Username: "jdoe"})
+			return err
+		}, echo_errors.ErrUserConflict, "USER_CONFLICT"},
+		"Attribute group": {func(driver *mock.MockDriver) error {
+			_, err := (&dao.AttributeGroupDAO{Driver: driver}).CreateAttributeGroup(ctx, model.AttributeGroup{ID: "taken", Name: "Finance"})
+			return err
+		}, echo_errors.ErrAttributeGroupConflict, "ATTRIBUTE_GROUP_CONFLICT"},
+		"Resource type": {func(driver *mock.MockDriver) error {
+			_, err := (&dao.ResourceTypeDAO{Driver: driver}).CreateResourceType(ctx, model.ResourceType{ID: "taken", Name: "Document"})
+			return err
+		}, echo_errors.ErrResourceTypeConflict, "RESOURCE_TYPE_CONFLICT"},
+		"Policy": {func(driver *mock.MockDriver) error {
+			_, err := (&dao.PolicyDAO{Driver: driver}).CreatePolicy(ctx, model.Policy{ID: "taken", Name: "Readers"}, "admin")
+			return err
+		}, echo_errors.ErrPolicyConflict, "POLICY_CONFLICT"},
+	}
+	for name, c := range creates {
+		t.Run(name, func(t *testing.T) {
+			err := c.create(newDriver())
+
+			assert.ErrorIs(t, err, c.conflict)
+			assert.Contains(t, err.Error(), `"taken" is already in use`)
+			status, code := util.MapError(err)
+			assert.Equal(t, http.StatusConflict, status)
+			assert.Equal(t, c.code, code)
+		})
+	}
+}
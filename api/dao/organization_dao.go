@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -19,12 +20,15 @@ import (
 	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
+// orgChildIDs lists the IDs of the direct sub-organizations of the organization bound to o
+const orgChildIDs = `[(o)-[:` + echo_neo4j.RelParentOf + `]->(child:` + echo_neo4j.LabelOrganization + `) | child.id] AS childIDs`
+
 type OrganizationDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewOrganizationDAO(driver neo4j.Driver, auditService audit.Service) *OrganizationDAO {
+func NewOrganizationDAO(driver Neo4jDriver, auditService audit.Service) *OrganizationDAO {
 	dao := &OrganizationDAO{Driver: driver, AuditService: auditService}
 	ctx := context.Background()
 	if err := dao.EnsureUniqueConstraint(ctx); err != nil {
@@ -65,26 +69,67 @@ func (dao *OrganizationDAO) CreateOrganization(ctx context.Context, org model.Or
 	if org.ID == "" {
 		org.ID = uuid.New().String()
 	}
+	if org.Status == "" {
+		org.Status = model.OrgStatusActive
+	}
+	org.CreatedBy = changedBy(ctx, "")
+	org.UpdatedBy = org.CreatedBy
+	externalIDsJSON, err := marshalExternalIDs(org.ExternalIDs)
+	if err != nil {
+		return "", err
+	}
 
 	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		if org.ParentID != "" {
+			parentQuery := `
+            MATCH (p:` + echo_neo4j.LabelOrganization + ` {id: $parentID})
+            RETURN p.id
+            `
+			parentResult, err := transaction.Run(parentQuery, map[string]interface{}{"parentID": org.ParentID})
+			if err != nil {
+				return nil, classifyNeo4jError(err)
+			}
+			if !parentResult.Next() {
+				return nil, fmt.Errorf("%w: parent organization %s", echo_errors.ErrOrganizationNotFound, org.ParentID)
+			}
+		}
+
 		query := `
-        MERGE (o:` + echo_neo4j.LabelOrganization + ` {id: $id})
-        ON CREATE SET o += $props
+        CREATE (o:` + echo_neo4j.LabelOrganization + ` {id: $id})
+        SET o += $props
+        `
+		if org.ParentID != "" {
+			query += `
+            WITH o
+            MATCH (p:` + echo_neo4j.LabelOrganization + ` {id: $parentID})
+            MERGE (p)-[:` + echo_neo4j.RelParentOf + `]->(o)
+            `
+		}
+		query += `
         RETURN o.id as id
         `
 
 		params := map[string]interface{}{
-			"id": org.ID,
+			"id":       org.ID,
+			"parentID": org.ParentID,
 			"props": map[string]interface{}{
-				"name":      org.Name,
-				"createdAt": time.Now().Format(time.RFC3339),
-				"updatedAt": time.Now().Format(time.RFC3339),
+				"name":         org.Name,
+				"description":  org.Description,
+				"status":       org.Status,
+				"contactEmail": org.ContactEmail,
+				"contactPhone": org.ContactPhone,
+				"externalIDs":  externalIDsJSON,
+				"parentID":     org.ParentID,
+				"createdAt":    time.Now().Format(time.RFC3339),
+				"updatedAt":    time.Now().Format(time.RFC3339),
+				"createdBy":    org.CreatedBy,
+				"updatedBy":    org.UpdatedBy,
 			},
 		}
 
 		result, err := transaction.Run(query, params)
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
@@ -100,7 +145,7 @@ func (dao *OrganizationDAO) CreateOrganization(ctx context.Context, org model.Or
 			zap.Error(err),
 			zap.String("orgName", org.Name),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrOrganizationConflict, org.ID)
 	}
 
 	orgID := fmt.Sprintf("%v", result)
@@ -111,7 +156,7 @@ func (dao *OrganizationDAO) CreateOrganization(ctx context.Context, org model.Or
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        org.CreatedBy,
 		Action:        "CREATE_ORGANIZATION",
 		ResourceID:    orgID,
 		AccessGranted: true,
@@ -136,30 +181,43 @@ func (dao *OrganizationDAO) UpdateOrganization(ctx context.Context, org model.Or
 	if err != nil {
 		return nil, fmt.Errorf("failed to get organization: %w", err)
 	}
+	if org.Status == "" {
+		org.Status = oldOrg.Status
+	}
+	org.UpdatedBy = changedBy(ctx, "")
+	externalIDsJSON, err := marshalExternalIDs(org.ExternalIDs)
+	if err != nil {
+		return nil, err
+	}
 
 	_, err = session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
         MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $id})
         SET o += $props
-        RETURN o
+        RETURN o, ` + orgChildIDs + `
         `
 
 		params := map[string]interface{}{
 			"id": org.ID,
 			"props": map[string]interface{}{
-				"name":      org.Name,
-				"updatedAt": time.Now().Format(time.RFC3339),
+				"name":         org.Name,
+				"description":  org.Description,
+				"status":       org.Status,
+				"contactEmail": org.ContactEmail,
+				"contactPhone": org.ContactPhone,
+				"externalIDs":  externalIDsJSON,
+				"updatedAt":    time.Now().Format(time.RFC3339),
+				"updatedBy":    org.UpdatedBy,
 			},
 		}
 
 		result, err := transaction.Run(query, params)
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
-			node := result.Record().Values[0].(neo4j.Node)
-			updatedOrg, err = mapNodeToOrganization(node)
+			updatedOrg, err = mapRecordToOrganization(result.Record())
 			if err != nil {
 				return nil, fmt.Errorf("failed to map organization node to struct: %w", err)
 			}
@@ -175,7 +233,7 @@ func (dao *OrganizationDAO) UpdateOrganization(ctx context.Context, org model.Or
 			zap.Error(err),
 			zap.String("orgID", org.ID),
 			zap.Duration("duration", duration))
-		return nil, err
+		return nil, classifyNeo4jError(err)
 	}
 
 	logger.Info("Organization updated successfully",
@@ -185,7 +243,7 @@ func (dao *OrganizationDAO) UpdateOrganization(ctx context.Context, org model.Or
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        org.UpdatedBy,
 		Action:        "UPDATE_ORGANIZATION",
 		ResourceID:    org.ID,
 		AccessGranted: true,
@@ -198,33 +256,327 @@ func (dao *OrganizationDAO) UpdateOrganization(ctx context.Context, org model.Or
 	return updatedOrg, nil
 }
 
-func (dao *OrganizationDAO) DeleteOrganization(ctx context.Context, orgID string) error {
+// SetParentOrganization makes an organization a sub-organization of parentID, or a top-level
+// organization when parentID is empty. Links that would make an organization its own ancestor are
+// rejected with ErrOrganizationCycle.
+func (dao *OrganizationDAO) SetParentOrganization(ctx context.Context, orgID string, parentID string) (*model.Organization, error) {
 	start := time.Now()
-	logger.Info("Deleting organization", zap.String("orgID", orgID))
+	logger.Info("Setting parent organization", zap.String("orgID", orgID), zap.String("parentID", parentID))
+
+	oldOrg, err := dao.GetOrganization(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	updatedBy := changedBy(ctx, "")
 
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
-	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+	var updatedOrg *model.Organization
+	_, err = session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		if parentID != "" {
+			checkQuery := `
+            MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $orgID})
+            OPTIONAL MATCH (p:` + echo_neo4j.LabelOrganization + ` {id: $parentID})
+            OPTIONAL MATCH cycle = (o)-[:` + echo_neo4j.RelParentOf + `*0..]->(p)
+            RETURN p IS NOT NULL AS parentExists, count(cycle) > 0 AS createsCycle
+            `
+			result, err := transaction.Run(checkQuery, map[string]interface{}{
+				"orgID":    orgID,
+				"parentID": parentID,
+			})
+			if err != nil {
+				return nil, classifyNeo4jError(err)
+			}
+			if !result.Next() {
+				return nil, echo_errors.ErrOrganizationNotFound
+			}
+
+			record := result.Record()
+			if parentExists, _ := record.Values[0].(bool); !parentExists {
+				return nil, fmt.Errorf("%w: parent organization %s", echo_errors.ErrOrganizationNotFound, parentID)
+			}
+			if createsCycle, _ := record.Values[1].(bool); createsCycle {
+				return nil, echo_errors.ErrOrganizationCycle
+			}
+		}
+
 		query := `
-        MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $id})
-        DETACH DELETE o
+        MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $orgID})
+        OPTIONAL MATCH (:` + echo_neo4j.LabelOrganization + `)-[old:` + echo_neo4j.RelParentOf + `]->(o)
+        DELETE old
+        WITH DISTINCT o
+        SET o.parentID = $parentID, o.updatedAt = $updatedAt, o.updatedBy = $updatedBy
+        `
+		if parentID != "" {
+			query += `
+            WITH o
+            MATCH (p:` + echo_neo4j.LabelOrganization + ` {id: $parentID})
+            MERGE (p)-[:` + echo_neo4j.RelParentOf + `]->(o)
+            `
+		}
+		query += `
+        RETURN o, ` + orgChildIDs + `
         `
-		result, err := transaction.Run(query, map[string]interface{}{"id": orgID})
+
+		result, err := transaction.Run(query, map[string]interface{}{
+			"orgID":     orgID,
+			"parentID":  parentID,
+			"updatedAt": time.Now().Format(time.RFC3339),
+			"updatedBy": updatedBy,
+		})
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
+		}
+		if !result.Next() {
+			return nil, echo_errors.ErrOrganizationNotFound
+		}
+		updatedOrg, err = mapRecordToOrganization(result.Record())
+		if err != nil {
+			return nil, fmt.Errorf("failed to map organization node to struct: %w", err)
+		}
+		return nil, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to set parent organization",
+			zap.Error(err),
+			zap.String("orgID", orgID),
+			zap.String("parentID", parentID),
+			zap.Duration("duration", duration))
+		return nil, classifyNeo4jError(err)
+	}
+
+	logger.Info("Parent organization set successfully",
+		zap.String("orgID", orgID),
+		zap.String("parentID", parentID),
+		zap.Duration("duration", duration))
+
+	// Audit trail
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        updatedBy,
+		Action:        "SET_PARENT_ORGANIZATION",
+		ResourceID:    orgID,
+		AccessGranted: true,
+		ChangeDetails: createOrgChangeDetails(oldOrg, updatedOrg),
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return updatedOrg, nil
+}
+
+// GetOrganizationHierarchy retrieves the chain of organizations from the top-level ancestor down to the
+// given organization
+func (dao *OrganizationDAO) GetOrganizationHierarchy(ctx context.Context, orgID string) ([]*model.Organization, error) {
+	start := time.Now()
+	logger.Info("Retrieving organization hierarchy", zap.String("orgID", orgID))
+
+	query := `
+    MATCH path = (o:` + echo_neo4j.LabelOrganization + `)-[:` + echo_neo4j.RelParentOf + `*0..]->(:` + echo_neo4j.LabelOrganization + ` {id: $orgID})
+    RETURN o, ` + orgChildIDs + `
+    ORDER BY length(path) DESC
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"orgID": orgID})
+	if err != nil {
+		logger.Error("Failed to execute get organization hierarchy query",
+			zap.Error(err),
+			zap.String("orgID", orgID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+	if len(records) == 0 {
+		return nil, echo_errors.ErrOrganizationNotFound
+	}
+
+	hierarchy, err := mapRecordsToOrganizations(records)
+	if err != nil {
+		logger.Error("Failed to map organization node to struct",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrInternalServer
+	}
+
+	logger.Info("Organization hierarchy retrieved successfully",
+		zap.String("orgID", orgID),
+		zap.Int("hierarchyDepth", len(hierarchy)),
+		zap.Duration("duration", time.Since(start)))
+
+	return hierarchy, nil
+}
+
+// GetSubOrganizations retrieves every organization below the given one, nearest first
+func (dao *OrganizationDAO) GetSubOrganizations(ctx context.Context, orgID string) ([]*model.Organization, error) {
+	start := time.Now()
+	logger.Info("Retrieving sub-organizations", zap.String("orgID", orgID))
+
+	exists, err := dao.Exists(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, echo_errors.ErrOrganizationNotFound
+	}
+
+	query := `
+    MATCH path = (:` + echo_neo4j.LabelOrganization + ` {id: $orgID})-[:` + echo_neo4j.RelParentOf + `*1..]->(o:` + echo_neo4j.LabelOrganization + `)
+    RETURN o, ` + orgChildIDs + `
+    ORDER BY length(path), o.name
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"orgID": orgID})
+	if err != nil {
+		logger.Error("Failed to execute get sub-organizations query",
+			zap.Error(err),
+			zap.String("orgID", orgID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	subOrgs, err := mapRecordsToOrganizations(records)
+	if err != nil {
+		logger.Error("Failed to map organization node to struct",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrInternalServer
+	}
+
+	logger.Info("Sub-organizations retrieved successfully",
+		zap.String("orgID", orgID),
+		zap.Int("count", len(subOrgs)),
+		zap.Duration("duration", time.Since(start)))
+
+	return subOrgs, nil
+}
+
+// orgDependentRelationships maps each label that may depend on an organization to the relationship
+// it uses to point at that organization
+var orgDependentRelationships = []struct {
+	label        string
+	relationship string
+}{
+	{echo_neo4j.LabelDepartment, echo_neo4j.RelPartOf},
+	{echo_neo4j.LabelUser, echo_neo4j.RelWorksFor},
+	{echo_neo4j.LabelRole, echo_neo4j.RelPartOf},
+	{echo_neo4j.LabelGroup, echo_neo4j.RelPartOf},
+	{echo_neo4j.LabelResource, echo_neo4j.RelBelongsTo},
+}
+
+// DeleteOrganization deletes an organization, handling its dependents according to mode:
+// restrict refuses while dependents exist, cascade deletes them, and reassign moves them to targetOrgID.
+// Sub-organizations count as dependents; unless restricted, they are moved under the deleted
+// organization's parent, or become top-level organizations.
+func (dao *OrganizationDAO) DeleteOrganization(ctx context.Context, orgID string, mode string, targetOrgID string) (*model.OrganizationDeletionSummary, error) {
+	start := time.Now()
+	logger.Info("Deleting organization",
+		zap.String("orgID", orgID),
+		zap.String("mode", mode),
+		zap.String("targetOrgID", targetOrgID))
+
+	switch mode {
+	case model.OrgDeleteModeRestrict, model.OrgDeleteModeCascade:
+	case model.OrgDeleteModeReassign:
+		if targetOrgID == "" || targetOrgID == orgID {
+			return nil, echo_errors.ErrInvalidOrgDeleteMode
 		}
+	default:
+		return nil, echo_errors.ErrInvalidOrgDeleteMode
+	}
+	actor := changedBy(ctx, "")
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
 
-		summary, err := result.Consume()
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		summary, err := countOrganizationDependents(transaction, orgID)
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, err
 		}
+		summary.Mode, summary.DeletedBy = mode, actor
 
-		if summary.Counters().NodesDeleted() == 0 {
-			return nil, echo_errors.ErrOrganizationNotFound
+		switch mode {
+		case model.OrgDeleteModeRestrict:
+			if summary.Total() > 0 {
+				return nil, echo_errors.ErrOrganizationInUse
+			}
+		case model.OrgDeleteModeCascade:
+			query := `
+            MATCH (n)
+            WHERE n.organizationID = $id
+              AND (n:` + echo_neo4j.LabelDepartment + ` OR n:` + echo_neo4j.LabelUser + ` OR n:` + echo_neo4j.LabelRole + ` OR n:` + echo_neo4j.LabelGroup + ` OR n:` + echo_neo4j.LabelResource + `)
+            DETACH DELETE n
+            `
+			if _, err := transaction.Run(query, map[string]interface{}{"id": orgID}); err != nil {
+				return nil, classifyNeo4jError(err)
+			}
+		case model.OrgDeleteModeReassign:
+			targetQuery := `
+            MATCH (t:` + echo_neo4j.LabelOrganization + ` {id: $targetID})
+            RETURN t.id
+            `
+			targetResult, err := transaction.Run(targetQuery, map[string]interface{}{"targetID": targetOrgID})
+			if err != nil {
+				return nil, classifyNeo4jError(err)
+			}
+			if !targetResult.Next() {
+				return nil, echo_errors.ErrOrganizationNotFound
+			}
+
+			for _, dependent := range orgDependentRelationships {
+				query := `
+                MATCH (n:` + dependent.label + `)
+                WHERE n.organizationID = $id
+                MATCH (t:` + echo_neo4j.LabelOrganization + ` {id: $targetID})
+                OPTIONAL MATCH (n)-[old:` + dependent.relationship + `]->(:` + echo_neo4j.LabelOrganization + ` {id: $id})
+                DELETE old
+                SET n.organizationID = $targetID, n.updatedAt = $updatedAt, n.updatedBy = $updatedBy
+                MERGE (n)-[:` + dependent.relationship + `]->(t)
+                `
+				params := map[string]interface{}{
+					"id":        orgID,
+					"targetID":  targetOrgID,
+					"updatedAt": time.Now().Format(time.RFC3339),
+					"updatedBy": actor,
+				}
+				if _, err := transaction.Run(query, params); err != nil {
+					return nil, classifyNeo4jError(err)
+				}
+			}
+			summary.TargetOrganizationID = targetOrgID
 		}
 
-		return nil, nil
+		if summary.SubOrganizations > 0 {
+			// Sub-organizations keep their place in the tree, moving up a level. Moving them under the
+			// reassign target instead could make the target its own ancestor.
+			query := `
+            MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $id})-[old:` + echo_neo4j.RelParentOf + `]->(c:` + echo_neo4j.LabelOrganization + `)
+            OPTIONAL MATCH (p:` + echo_neo4j.LabelOrganization + `)-[:` + echo_neo4j.RelParentOf + `]->(o)
+            DELETE old
+            SET c.parentID = coalesce(p.id, ''), c.updatedAt = $updatedAt, c.updatedBy = $updatedBy
+            FOREACH (parent IN CASE WHEN p IS NULL THEN [] ELSE [p] END |
+                MERGE (parent)-[:` + echo_neo4j.RelParentOf + `]->(c))
+            `
+			params := map[string]interface{}{
+				"id":        orgID,
+				"updatedAt": time.Now().Format(time.RFC3339),
+				"updatedBy": actor,
+			}
+			if _, err := transaction.Run(query, params); err != nil {
+				return nil, classifyNeo4jError(err)
+			}
+		}
+
+		query := `
+        MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $id})
+        DETACH DELETE o
+        `
+		if _, err := transaction.Run(query, map[string]interface{}{"id": orgID}); err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+
+		return summary, nil
 	})
 
 	duration := time.Since(start)
@@ -232,27 +584,76 @@ func (dao *OrganizationDAO) DeleteOrganization(ctx context.Context, orgID string
 		logger.Error("Failed to delete organization",
 			zap.Error(err),
 			zap.String("orgID", orgID),
+			zap.String("mode", mode),
 			zap.Duration("duration", duration))
-		return err
+		return nil, classifyNeo4jError(err)
 	}
 
+	summary := result.(*model.OrganizationDeletionSummary)
 	logger.Info("Organization deleted successfully",
 		zap.String("orgID", orgID),
+		zap.Any("summary", summary),
 		zap.Duration("duration", duration))
 
 	// Audit trail
+	changeDetails, _ := json.Marshal(summary)
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        actor,
 		Action:        "DELETE_ORGANIZATION",
 		ResourceID:    orgID,
 		AccessGranted: true,
+		ChangeDetails: changeDetails,
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
 	}
 
-	return nil
+	return summary, nil
+}
+
+// countOrganizationDependents counts the entities referencing an organization, returning
+// ErrOrganizationNotFound if the organization does not exist
+func countOrganizationDependents(transaction neo4j.Transaction, orgID string) (*model.OrganizationDeletionSummary, error) {
+	query := `
+    MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $id})
+    RETURN COUNT { MATCH (d:` + echo_neo4j.LabelDepartment + `) WHERE d.organizationID = $id } AS departments,
+           COUNT { MATCH (u:` + echo_neo4j.LabelUser + `) WHERE u.organizationID = $id } AS users,
+           COUNT { MATCH (r:` + echo_neo4j.LabelRole + `) WHERE r.organizationID = $id } AS roles,
+           COUNT { MATCH (g:` + echo_neo4j.LabelGroup + `) WHERE g.organizationID = $id } AS groups,
+           COUNT { MATCH (res:` + echo_neo4j.LabelResource + `) WHERE res.organizationID = $id } AS resources,
+           COUNT { MATCH (o)-[:` + echo_neo4j.RelParentOf + `]->(:` + echo_neo4j.LabelOrganization + `) } AS subOrganizations
+    `
+	result, err := transaction.Run(query, map[string]interface{}{"id": orgID})
+	if err != nil {
+		return nil, echo_errors.ErrDatabaseOperation
+	}
+
+	if !result.Next() {
+		return nil, echo_errors.ErrOrganizationNotFound
+	}
+
+	record := result.Record()
+	count := func(key string) int64 {
+		value, _ := record.Get(key)
+		n, _ := value.(int64)
+		return n
+	}
+
+	return &model.OrganizationDeletionSummary{
+		OrganizationID:   orgID,
+		Departments:      count("departments"),
+		Users:            count("users"),
+		Roles:            count("roles"),
+		Groups:           count("groups"),
+		Resources:        count("resources"),
+		SubOrganizations: count("subOrganizations"),
+	}, nil
+}
+
+// Exists reports whether an organization with the given ID exists, without reading the whole node
+func (dao *OrganizationDAO) Exists(ctx context.Context, orgID string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelOrganization, orgID)
 }
 
 func (dao *OrganizationDAO) GetOrganization(ctx context.Context, orgID string) (*model.Organization, error) {
@@ -264,7 +665,7 @@ func (dao *OrganizationDAO) GetOrganization(ctx context.Context, orgID string) (
 
 	query := `
     MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $id})
-    RETURN o
+    RETURN o, ` + orgChildIDs + `
     `
 	result, err := session.Run(query, map[string]interface{}{"id": orgID})
 	if err != nil {
@@ -276,8 +677,7 @@ func (dao *OrganizationDAO) GetOrganization(ctx context.Context, orgID string) (
 	}
 
 	if result.Next() {
-		node := result.Record().Values[0].(neo4j.Node)
-		org, err := mapNodeToOrganization(node)
+		org, err := mapRecordToOrganization(result.Record())
 		if err != nil {
 			logger.Error("Failed to map organization node to struct",
 				zap.Error(err),
@@ -306,7 +706,7 @@ func (dao *OrganizationDAO) ListOrganizations(ctx context.Context, limit int, of
 
 	query := `
     MATCH (o:` + echo_neo4j.LabelOrganization + `)
-    RETURN o
+    RETURN o, ` + orgChildIDs + `
     ORDER BY o.createdAt DESC
     SKIP $offset
     LIMIT $limit
@@ -324,8 +724,7 @@ func (dao *OrganizationDAO) ListOrganizations(ctx context.Context, limit int, of
 
 	var orgs []*model.Organization
 	for result.Next() {
-		node := result.Record().Values[0].(neo4j.Node)
-		org, err := mapNodeToOrganization(node)
+		org, err := mapRecordToOrganization(result.Record())
 		if err != nil {
 			logger.Error("Failed to map organization node to struct",
 				zap.Error(err),
@@ -342,13 +741,13 @@ func (dao *OrganizationDAO) ListOrganizations(ctx context.Context, limit int, of
 	return orgs, nil
 }
 
+// organizationSortFields lists the properties organization searches may be ordered by
+var organizationSortFields = []string{"name", "createdAt", "updatedAt"}
+
 func (dao *OrganizationDAO) SearchOrganizations(ctx context.Context, criteria model.OrganizationSearchCriteria) ([]*model.Organization, error) {
 	start := time.Now()
 	logger.Info("Searching organizations", zap.Any("criteria", criteria))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	var queryBuilder strings.Builder
 	queryBuilder.WriteString(fmt.Sprintf("MATCH (o:%s) WHERE 1=1", echo_neo4j.LabelOrganization))
 
@@ -364,6 +763,13 @@ func (dao *OrganizationDAO) SearchOrganizations(ctx context.Context, criteria mo
 		params["id"] = criteria.ID
 	}
 
+	if criteria.Status != "" {
+		// Organizations created before statuses existed are active
+		queryBuilder.WriteString(" AND coalesce(o.status, $activeStatus) = $status")
+		params["status"] = criteria.Status
+		params["activeStatus"] = model.OrgStatusActive
+	}
+
 	if criteria.FromDate != nil {
 		queryBuilder.WriteString(" AND o.createdAt >= $fromDate")
 		params["fromDate"] = criteria.FromDate.Format(time.RFC3339)
@@ -374,43 +780,35 @@ func (dao *OrganizationDAO) SearchOrganizations(ctx context.Context, criteria mo
 		params["toDate"] = criteria.ToDate.Format(time.RFC3339)
 	}
 
-	queryBuilder.WriteString(" RETURN o")
+	queryBuilder.WriteString(" RETURN o, " + orgChildIDs)
 
-	if criteria.SortBy != "" {
-		queryBuilder.WriteString(fmt.Sprintf(" ORDER BY o.%s", criteria.SortBy))
-		if criteria.SortOrder != "" {
-			queryBuilder.WriteString(" " + strings.ToUpper(criteria.SortOrder))
-		} else {
-			queryBuilder.WriteString(" ASC")
-		}
-	} else {
-		queryBuilder.WriteString(" ORDER BY o.createdAt DESC")
+	sortClause, err := helper_util.SafeSortClause("o", criteria.SortBy, criteria.SortOrder, organizationSortFields)
+	if err != nil {
+		logger.Warn("Invalid sort field for organization search", zap.String("sortBy", criteria.SortBy))
+		return nil, err
 	}
-
-	if criteria.Offset > 0 {
-		queryBuilder.WriteString(" SKIP $offset")
-		params["offset"] = criteria.Offset
+	if sortClause == "" {
+		sortClause = " ORDER BY o.createdAt DESC"
 	}
+	queryBuilder.WriteString(sortClause)
 
-	if criteria.Limit > 0 {
-		queryBuilder.WriteString(" LIMIT $limit")
-		params["limit"] = criteria.Limit
-	}
+	paginationClause, paginationParams := helper_util.BuildPagination(criteria.Limit, criteria.Offset)
+	queryBuilder.WriteString(paginationClause)
+	helper_util.MergeParams(params, paginationParams)
 
 	logger.Info("Executing query", zap.String("query", queryBuilder.String()), zap.Any("params", params))
 
-	result, err := session.Run(queryBuilder.String(), params)
+	records, err := readRecords(ctx, dao.Driver, queryBuilder.String(), params)
 	if err != nil {
 		logger.Error("Failed to execute search organizations query",
 			zap.Error(err),
 			zap.Duration("duration", time.Since(start)))
-		return nil, echo_errors.ErrDatabaseOperation
+		return nil, readFailure(ctx)
 	}
 
 	var orgs []*model.Organization
-	for result.Next() {
-		node := result.Record().Values[0].(neo4j.Node)
-		org, err := mapNodeToOrganization(node)
+	for _, record := range records {
+		org, err := mapRecordToOrganization(record)
 		if err != nil {
 			logger.Error("Failed to map organization node to struct",
 				zap.Error(err),
@@ -434,12 +832,74 @@ func mapNodeToOrganization(node neo4j.Node) (*model.Organization, error) {
 
 	org.ID = props["id"].(string)
 	org.Name = props["name"].(string)
+	// Organizations created before these properties existed lack them
+	org.Description, _ = props["description"].(string)
+	org.Status, _ = props["status"].(string)
+	if org.Status == "" {
+		org.Status = model.OrgStatusActive
+	}
+	org.ContactEmail, _ = props["contactEmail"].(string)
+	org.ContactPhone, _ = props["contactPhone"].(string)
+	if externalIDsJSON, _ := props["externalIDs"].(string); externalIDsJSON != "" {
+		if err := json.Unmarshal([]byte(externalIDsJSON), &org.ExternalIDs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal organization external IDs: %w", err)
+		}
+	}
+	org.ParentID, _ = props["parentID"].(string)
 	org.CreatedAt, _ = helper_util.ParseTime(props["createdAt"].(string))
 	org.UpdatedAt, _ = helper_util.ParseTime(props["updatedAt"].(string))
+	org.CreatedBy, _ = props["createdBy"].(string)
+	org.UpdatedBy, _ = props["updatedBy"].(string)
 
 	return org, nil
 }
 
+// marshalExternalIDs encodes an organization's external IDs for storage as a node property
+func marshalExternalIDs(externalIDs map[string]string) (string, error) {
+	if externalIDs == nil {
+		externalIDs = map[string]string{}
+	}
+	externalIDsJSON, err := json.Marshal(externalIDs)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal organization external IDs: %w", err)
+	}
+	return string(externalIDsJSON), nil
+}
+
+// mapRecordToOrganization maps a record holding an organization node and, when present, the IDs of its
+// sub-organizations
+func mapRecordToOrganization(record *neo4j.Record) (*model.Organization, error) {
+	node, ok := record.Values[0].(neo4j.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected organization value %T", record.Values[0])
+	}
+	org, err := mapNodeToOrganization(node)
+	if err != nil {
+		return nil, err
+	}
+	if len(record.Values) > 1 {
+		childIDs, _ := record.Values[1].([]interface{})
+		for _, childID := range childIDs {
+			if id, ok := childID.(string); ok {
+				org.ChildIDs = append(org.ChildIDs, id)
+			}
+		}
+	}
+	return org, nil
+}
+
+func mapRecordsToOrganizations(records []*neo4j.Record) ([]*model.Organization, error) {
+	orgs := make([]*model.Organization, 0, len(records))
+	for _, record := range records {
+		org, err := mapRecordToOrganization(record)
+		if err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, nil
+}
+
 // Helper function to create change details for audit log
 func createOrgChangeDetails(oldOrg, newOrg *model.Organization) json.RawMessage {
 	changes := make(map[string]interface{})
@@ -452,6 +912,22 @@ func createOrgChangeDetails(oldOrg, newOrg *model.Organization) json.RawMessage
 		if oldOrg.Name != newOrg.Name {
 			changes["name"] = map[string]string{"old": oldOrg.Name, "new": newOrg.Name}
 		}
+		for field, values := range map[string][2]string{
+			"description":  {oldOrg.Description, newOrg.Description},
+			"status":       {oldOrg.Status, newOrg.Status},
+			"contactEmail": {oldOrg.ContactEmail, newOrg.ContactEmail},
+			"contactPhone": {oldOrg.ContactPhone, newOrg.ContactPhone},
+		} {
+			if values[0] != values[1] {
+				changes[field] = map[string]string{"old": values[0], "new": values[1]}
+			}
+		}
+		if !reflect.DeepEqual(oldOrg.ExternalIDs, newOrg.ExternalIDs) {
+			changes["externalIDs"] = map[string]interface{}{"old": oldOrg.ExternalIDs, "new": newOrg.ExternalIDs}
+		}
+		if oldOrg.ParentID != newOrg.ParentID {
+			changes["parentID"] = map[string]string{"old": oldOrg.ParentID, "new": newOrg.ParentID}
+		}
 		// Add more fields as needed
 	}
 	changeDetails, _ := json.Marshal(changes)
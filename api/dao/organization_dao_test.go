@@ -0,0 +1,330 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestDeleteOrganizationModes(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	// Seeded organization with two departments and three users
+	newDAO := func() (*dao.OrganizationDAO, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+
+		countResult := &mock.MockResult{}
+		countResult.On("Next").Return(true).Once()
+		countResult.On("Record").Return(&neo4j.Record{
+			Keys:   []string{"departments", "users", "roles", "groups", "resources"},
+			Values: []interface{}{int64(2), int64(3), int64(0), int64(0), int64(0)},
+		})
+		tx.On("Run", queryContaining("AS departments"), testify_mock.Anything).Return(countResult, nil)
+
+		return &dao.OrganizationDAO{Driver: driver, AuditService: auditService}, tx, auditService
+	}
+
+	t.Run("DeleteOrganization_Restrict", func(t *testing.T) {
+		orgDAO, tx, auditService := newDAO()
+
+		summary, err := orgDAO.DeleteOrganization(ctx, "org1", model.OrgDeleteModeRestrict, "")
+
+		assert.Nil(t, summary)
+		assert.Equal(t, echo_errors.ErrOrganizationInUse, err)
+		tx.AssertNotCalled(t, "Run", queryContaining("DETACH DELETE"), testify_mock.Anything)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("DeleteOrganization_Cascade", func(t *testing.T) {
+		orgDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("DETACH DELETE n"), testify_mock.Anything).Return(&mock.MockResult{}, nil)
+		tx.On("Run", queryContaining("DETACH DELETE o"), testify_mock.Anything).Return(&mock.MockResult{}, nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+		summary, err := orgDAO.DeleteOrganization(ctx, "org1", model.OrgDeleteModeCascade, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), summary.Departments)
+		assert.Equal(t, int64(3), summary.Users)
+		assert.Equal(t, model.OrgDeleteModeCascade, summary.Mode)
+		tx.AssertCalled(t, "Run", queryContaining("DETACH DELETE n"), testify_mock.Anything)
+		tx.AssertCalled(t, "Run", queryContaining("DETACH DELETE o"), testify_mock.Anything)
+	})
+
+	t.Run("DeleteOrganization_Reassign", func(t *testing.T) {
+		orgDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("RETURN t.id"), testify_mock.Anything).Return(resultWithRecord("org2"), nil)
+		tx.On("Run", queryContaining("SET n.organizationID = $targetID"), testify_mock.Anything).Return(&mock.MockResult{}, nil)
+		tx.On("Run", queryContaining("DETACH DELETE o"), testify_mock.Anything).Return(&mock.MockResult{}, nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+		summary, err := orgDAO.DeleteOrganization(ctx, "org1", model.OrgDeleteModeReassign, "org2")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "org2", summary.TargetOrganizationID)
+		assert.Equal(t, int64(5), summary.Total())
+		tx.AssertNotCalled(t, "Run", queryContaining("DETACH DELETE n"), testify_mock.Anything)
+		tx.AssertCalled(t, "Run", queryContaining("MERGE (n)-[:WORKS_FOR]->(t)"), testify_mock.Anything)
+		tx.AssertCalled(t, "Run", queryContaining("MERGE (n)-[:PART_OF]->(t)"), testify_mock.Anything)
+	})
+
+	t.Run("DeleteOrganization_ReassignWithoutTarget", func(t *testing.T) {
+		orgDAO, _, _ := newDAO()
+
+		_, err := orgDAO.DeleteOrganization(ctx, "org1", model.OrgDeleteModeReassign, "")
+
+		assert.Equal(t, echo_errors.ErrInvalidOrgDeleteMode, err)
+	})
+}
+
+func TestOrganizationHierarchy(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	// Acme owns Acme Europe, which owns Acme Germany
+	orgNode := func(id, name, parentID string) neo4j.Node {
+		return neo4j.Node{Props: map[string]any{
+			"id":        id,
+			"name":      name,
+			"parentID":  parentID,
+			"createdAt": "2024-01-01T00:00:00Z",
+			"updatedAt": "2024-01-01T00:00:00Z",
+		}}
+	}
+	acme := orgNode("acme", "Acme", "")
+	europe := orgNode("acme-eu", "Acme Europe", "acme")
+	germany := orgNode("acme-de", "Acme Germany", "acme-eu")
+
+	// orgRecords answers with one record per list of values, such as an organization and its child IDs
+	orgRecords := func(values ...[]any) *mock.MockResult {
+		result := &mock.MockResult{}
+		for _, record := range values {
+			result.On("Next").Return(true).Once()
+			result.On("Record").Return(&neo4j.Record{Values: record}).Once()
+		}
+		result.On("Next").Return(false)
+		return result
+	}
+	newDAO := func() (*dao.OrganizationDAO, *mock.MockTxSession, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		return &dao.OrganizationDAO{Driver: driver, AuditService: auditService}, session, tx, auditService
+	}
+	ids := func(orgs []*model.Organization) []string {
+		var ids []string
+		for _, org := range orgs {
+			ids = append(ids, org.ID)
+		}
+		return ids
+	}
+
+	t.Run("GetOrganizationHierarchy_RootFirst", func(t *testing.T) {
+		orgDAO, session, _, _ := newDAO()
+		session.On("Run", queryContaining("*0..]->(:"+echo_neo4j.LabelOrganization+" {id: $orgID})"), testify_mock.Anything, testify_mock.Anything).
+			Return(orgRecords(
+				[]any{acme, []any{"acme-eu"}},
+				[]any{europe, []any{"acme-de"}},
+				[]any{germany, []any{}},
+			), nil)
+
+		hierarchy, err := orgDAO.GetOrganizationHierarchy(ctx, "acme-de")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"acme", "acme-eu", "acme-de"}, ids(hierarchy))
+		assert.Empty(t, hierarchy[0].ParentID)
+		assert.Equal(t, []string{"acme-eu"}, hierarchy[0].ChildIDs)
+		assert.Equal(t, "acme", hierarchy[1].ParentID)
+		assert.Equal(t, []string{"acme-de"}, hierarchy[1].ChildIDs)
+		assert.Empty(t, hierarchy[2].ChildIDs)
+	})
+
+	t.Run("GetOrganizationHierarchy_NotFound", func(t *testing.T) {
+		orgDAO, session, _, _ := newDAO()
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(orgRecords(), nil)
+
+		_, err := orgDAO.GetOrganizationHierarchy(ctx, "missing")
+
+		assert.Equal(t, echo_errors.ErrOrganizationNotFound, err)
+	})
+
+	t.Run("GetSubOrganizations_EveryLevel", func(t *testing.T) {
+		orgDAO, session, _, _ := newDAO()
+		session.On("Run", queryContaining("AS found"), testify_mock.Anything, testify_mock.Anything).
+			Return(orgRecords([]any{true}), nil)
+		session.On("Run", queryContaining("*1..]->(o:"+echo_neo4j.LabelOrganization+")"), testify_mock.Anything, testify_mock.Anything).
+			Return(orgRecords(
+				[]any{europe, []any{"acme-de"}},
+				[]any{germany, []any{}},
+			), nil)
+
+		subOrgs, err := orgDAO.GetSubOrganizations(ctx, "acme")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"acme-eu", "acme-de"}, ids(subOrgs))
+		assert.Equal(t, "acme-eu", subOrgs[1].ParentID)
+	})
+
+	t.Run("SetParentOrganization_Nests", func(t *testing.T) {
+		orgDAO, session, tx, auditService := newDAO()
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).
+			Return(orgRecords([]any{orgNode("acme-fr", "Acme France", ""), []any{}}), nil)
+		tx.On("Run", queryContaining("createsCycle"), testify_mock.Anything).
+			Return(resultWithRecord(true, false), nil)
+		tx.On("Run", queryContaining("MERGE (p)-[:"+echo_neo4j.RelParentOf+"]->(o)"), testify_mock.Anything).
+			Return(resultWithRecord(orgNode("acme-fr", "Acme France", "acme-eu"), []any{}), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "SET_PARENT_ORGANIZATION" && log.ResourceID == "acme-fr"
+		})).Return(nil)
+
+		org, err := orgDAO.SetParentOrganization(ctx, "acme-fr", "acme-eu")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "acme-eu", org.ParentID)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("SetParentOrganization_RejectsCycle", func(t *testing.T) {
+		orgDAO, session, tx, auditService := newDAO()
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).
+			Return(orgRecords([]any{acme, []any{"acme-eu"}}), nil)
+		// Acme Germany lies below Acme, so Acme may not move under it
+		tx.On("Run", queryContaining("createsCycle"), testify_mock.Anything).
+			Return(resultWithRecord(true, true), nil)
+
+		org, err := orgDAO.SetParentOrganization(ctx, "acme", "acme-de")
+
+		assert.Nil(t, org)
+		assert.Equal(t, echo_errors.ErrOrganizationCycle, err)
+		tx.AssertNotCalled(t, "Run", queryContaining("MERGE"), testify_mock.Anything)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("DeleteOrganization_RestrictsWithSubOrganizations", func(t *testing.T) {
+		orgDAO, _, tx, auditService := newDAO()
+		countResult := &mock.MockResult{}
+		countResult.On("Next").Return(true).Once()
+		countResult.On("Record").Return(&neo4j.Record{
+			Keys:   []string{"departments", "users", "roles", "groups", "resources", "subOrganizations"},
+			Values: []interface{}{int64(0), int64(0), int64(0), int64(0), int64(0), int64(1)},
+		})
+		tx.On("Run", queryContaining("AS subOrganizations"), testify_mock.Anything).Return(countResult, nil)
+
+		summary, err := orgDAO.DeleteOrganization(ctx, "acme-eu", model.OrgDeleteModeRestrict, "")
+
+		assert.Nil(t, summary)
+		assert.Equal(t, echo_errors.ErrOrganizationInUse, err)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+}
+
+func TestOrganizationDetails(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newDAO := func() (*dao.OrganizationDAO, *mock.MockTxSession, *mock.MockTransaction) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+		return &dao.OrganizationDAO{Driver: driver, AuditService: auditService}, session, tx
+	}
+	// readBack serves node to the organization reads
+	readBack := func(session *mock.MockTxSession, node neo4j.Node) {
+		result := &mock.MockResult{}
+		result.On("Next").Return(true).Once()
+		result.On("Record").Return(&neo4j.Record{Values: []any{node, []any{}}})
+		session.On("Run", queryContaining("RETURN o"), testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+	}
+
+	t.Run("CreatedDetailsReadBack", func(t *testing.T) {
+		orgDAO, session, tx := newDAO()
+		var stored map[string]any
+		tx.On("Run", queryContaining("SET o += $props"), testify_mock.Anything).
+			Run(func(args testify_mock.Arguments) {
+				stored = args.Get(1).(map[string]any)["props"].(map[string]any)
+				stored["id"] = args.Get(1).(map[string]any)["id"]
+			}).
+			Return(resultWithRecord("acme"), nil)
+
+		_, err := orgDAO.CreateOrganization(ctx, model.Organization{
+			ID:           "acme",
+			Name:         "Acme",
+			Description:  "Makes everything",
+			Status:       model.OrgStatusSuspended,
+			ContactEmail: "it@acme.example",
+			ContactPhone: "+1 555 010 0199",
+			ExternalIDs:  map[string]string{"salesforce": "0015g00000XyZ", "erp": "A-1"},
+		})
+		assert.NoError(t, err)
+
+		readBack(session, neo4j.Node{Props: stored})
+		org, err := orgDAO.GetOrganization(ctx, "acme")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Makes everything", org.Description)
+		assert.Equal(t, model.OrgStatusSuspended, org.Status)
+		assert.Equal(t, "it@acme.example", org.ContactEmail)
+		assert.Equal(t, "+1 555 010 0199", org.ContactPhone)
+		assert.Equal(t, map[string]string{"salesforce": "0015g00000XyZ", "erp": "A-1"}, org.ExternalIDs)
+	})
+
+	t.Run("CreatedWithoutStatusIsActive", func(t *testing.T) {
+		orgDAO, _, tx := newDAO()
+		tx.On("Run", queryContaining("SET o += $props"), testify_mock.MatchedBy(func(params map[string]any) bool {
+			props := params["props"].(map[string]any)
+			return props["status"] == model.OrgStatusActive && props["externalIDs"] == "{}"
+		})).Return(resultWithRecord("acme"), nil)
+
+		_, err := orgDAO.CreateOrganization(ctx, model.Organization{ID: "acme", Name: "Acme"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("LegacyNodeGetsDefaults", func(t *testing.T) {
+		orgDAO, session, _ := newDAO()
+		readBack(session, neo4j.Node{Props: map[string]any{
+			"id":        "legacy",
+			"name":      "Legacy Co",
+			"createdAt": "2023-01-01T00:00:00Z",
+			"updatedAt": "2023-01-01T00:00:00Z",
+		}})
+
+		org, err := orgDAO.GetOrganization(ctx, "legacy")
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.OrgStatusActive, org.Status)
+		assert.Empty(t, org.Description)
+		assert.Empty(t, org.ContactEmail)
+		assert.Nil(t, org.ExternalIDs)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,19 +16,23 @@ import (
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 type PermissionDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewPermissionDAO(driver neo4j.Driver, auditService audit.Service) *PermissionDAO {
+func NewPermissionDAO(driver Neo4jDriver, auditService audit.Service) *PermissionDAO {
 	dao := &PermissionDAO{Driver: driver, AuditService: auditService}
 	ctx := context.Background()
 	if err := dao.EnsureUniqueConstraint(ctx); err != nil {
 		logger.Fatal("Failed to ensure unique constraint for Permission", zap.Error(err))
 	}
+	if err := dao.EnsureActionIndex(ctx); err != nil {
+		logger.Fatal("Failed to ensure action index for Permission", zap.Error(err))
+	}
 	return dao
 }
 
@@ -54,6 +59,30 @@ func (dao *PermissionDAO) EnsureUniqueConstraint(ctx context.Context) error {
 	return nil
 }
 
+// EnsureActionIndex indexes permissions by action, which is how authorization looks them up
+func (dao *PermissionDAO) EnsureActionIndex(ctx context.Context) error {
+	logger.Info("Ensuring index on Permission action")
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		query := `
+        CREATE INDEX permission_action IF NOT EXISTS
+        FOR (p:` + echo_neo4j.LabelPermission + `) ON (p.action)
+        `
+		_, err := transaction.Run(query, nil)
+		return nil, err
+	})
+
+	if err != nil {
+		logger.Error("Failed to ensure index on Permission action", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully ensured index on Permission action")
+	return nil
+}
+
 func (dao *PermissionDAO) CreatePermission(ctx context.Context, permission model.Permission) (string, error) {
 	start := time.Now()
 	logger.Info("Creating new permission", zap.String("permissionName", permission.Name))
@@ -63,11 +92,13 @@ func (dao *PermissionDAO) CreatePermission(ctx context.Context, permission model
 	if permission.ID == "" {
 		permission.ID = uuid.New().String()
 	}
+	permission.CreatedBy = changedBy(ctx, "")
+	permission.UpdatedBy = permission.CreatedBy
 
 	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
-        MERGE (p:` + echo_neo4j.LabelPermission + ` {id: $id})
-        ON CREATE SET p += $props
+        CREATE (p:` + echo_neo4j.LabelPermission + ` {id: $id})
+        SET p += $props
         RETURN p.id as id
         `
 
@@ -77,12 +108,15 @@ func (dao *PermissionDAO) CreatePermission(ctx context.Context, permission model
 				"name":        permission.Name,
 				"description": permission.Description,
 				"action":      permission.Action,
+				"createdAt":   time.Now().UTC().Format(time.RFC3339),
+				"createdBy":   permission.CreatedBy,
+				"updatedBy":   permission.UpdatedBy,
 			},
 		}
 
 		result, err := transaction.Run(query, params)
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
@@ -98,7 +132,7 @@ func (dao *PermissionDAO) CreatePermission(ctx context.Context, permission model
 			zap.Error(err),
 			zap.String("permissionName", permission.Name),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrPermissionConflict, permission.ID)
 	}
 
 	permissionID := fmt.Sprintf("%v", result)
@@ -109,7 +143,7 @@ func (dao *PermissionDAO) CreatePermission(ctx context.Context, permission model
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        permission.CreatedBy,
 		Action:        "CREATE_" + echo_neo4j.LabelPermission,
 		ResourceID:    permissionID,
 		AccessGranted: true,
@@ -129,6 +163,7 @@ func (dao *PermissionDAO) UpdatePermission(ctx context.Context, permission model
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
+	permission.UpdatedBy = changedBy(ctx, "")
 	var updatedPermission *model.Permission
 	oldPermission, err := dao.GetPermission(ctx, permission.ID)
 	if err != nil {
@@ -150,6 +185,7 @@ func (dao *PermissionDAO) UpdatePermission(ctx context.Context, permission model
 				"name":        permission.Name,
 				"description": permission.Description,
 				"action":      permission.Action,
+				"updatedBy":   permission.UpdatedBy,
 			},
 		}
 
@@ -157,7 +193,7 @@ func (dao *PermissionDAO) UpdatePermission(ctx context.Context, permission model
 		if err != nil {
 			// Log the error
 			logger.Error("Failed to execute update permission query", zap.Error(err))
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
@@ -178,7 +214,7 @@ func (dao *PermissionDAO) UpdatePermission(ctx context.Context, permission model
 			zap.Error(err),
 			zap.String("permissionID", permission.ID),
 			zap.Duration("duration", duration))
-		return nil, err
+		return nil, classifyNeo4jError(err)
 	}
 
 	logger.Info("Permission updated successfully",
@@ -188,7 +224,7 @@ func (dao *PermissionDAO) UpdatePermission(ctx context.Context, permission model
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        permission.UpdatedBy,
 		Action:        "UPDATE_" + echo_neo4j.LabelPermission,
 		ResourceID:    permission.ID,
 		AccessGranted: true,
@@ -215,12 +251,12 @@ func (dao *PermissionDAO) DeletePermission(ctx context.Context, permissionID str
         `
 		result, err := transaction.Run(query, map[string]interface{}{"id": permissionID})
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		summary, err := result.Consume()
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if summary.Counters().NodesDeleted() == 0 {
@@ -236,7 +272,7 @@ func (dao *PermissionDAO) DeletePermission(ctx context.Context, permissionID str
 			zap.Error(err),
 			zap.String("permissionID", permissionID),
 			zap.Duration("duration", duration))
-		return err
+		return classifyNeo4jError(err)
 	}
 
 	logger.Info("Permission deleted successfully",
@@ -246,10 +282,11 @@ func (dao *PermissionDAO) DeletePermission(ctx context.Context, permissionID str
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        helper_util.ActorFromContext(ctx),
 		Action:        "DELETE_" + echo_neo4j.LabelPermission,
 		ResourceID:    permissionID,
 		AccessGranted: true,
+		ChangeDetails: deletedDetails(helper_util.ActorFromContext(ctx), nil),
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
@@ -258,6 +295,11 @@ func (dao *PermissionDAO) DeletePermission(ctx context.Context, permissionID str
 	return nil
 }
 
+// Exists reports whether a permission with the given ID exists, without reading the whole node
+func (dao *PermissionDAO) Exists(ctx context.Context, permissionID string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelPermission, permissionID)
+}
+
 func (dao *PermissionDAO) GetPermission(ctx context.Context, permissionID string) (*model.Permission, error) {
 	start := time.Now()
 	logger.Info("Retrieving permission", zap.String("permissionID", permissionID))
@@ -345,6 +387,205 @@ func (dao *PermissionDAO) ListPermissions(ctx context.Context, limit int, offset
 	return permissions, nil
 }
 
+func (dao *PermissionDAO) SearchPermissions(ctx context.Context, criteria model.PermissionSearchCriteria) ([]*model.Permission, error) {
+	start := time.Now()
+	logger.Info("Searching permissions", zap.Any("criteria", criteria))
+
+	var queryBuilder strings.Builder
+	queryBuilder.WriteString("MATCH (p:" + echo_neo4j.LabelPermission + ") WHERE 1=1")
+
+	params := make(map[string]interface{})
+
+	if criteria.Name != "" {
+		queryBuilder.WriteString(" AND toLower(p.name) CONTAINS toLower($name)")
+		params["name"] = criteria.Name
+	}
+
+	if criteria.Action != "" {
+		queryBuilder.WriteString(" AND toLower(p.action) CONTAINS toLower($action)")
+		params["action"] = criteria.Action
+	}
+
+	paginationClause, paginationParams := helper_util.BuildPagination(criteria.Limit, criteria.Offset)
+	queryBuilder.WriteString(" RETURN p ORDER BY p.name" + paginationClause)
+	helper_util.MergeParams(params, paginationParams)
+
+	records, err := readRecords(ctx, dao.Driver, queryBuilder.String(), params)
+	if err != nil {
+		logger.Error("Failed to execute search permissions query",
+			zap.Error(err),
+			zap.Any("criteria", criteria),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	permissions, err := collectPermissions(records)
+	if err != nil {
+		logger.Error("Failed to map permission node to struct",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrInternalServer
+	}
+
+	logger.Info("Permissions searched successfully",
+		zap.Int("count", len(permissions)),
+		zap.Duration("duration", time.Since(start)))
+
+	return permissions, nil
+}
+
+// GetPermissionsByAction returns every permission that grants exactly the given action
+func (dao *PermissionDAO) GetPermissionsByAction(ctx context.Context, action string) ([]*model.Permission, error) {
+	start := time.Now()
+	logger.Info("Retrieving permissions by action", zap.String("action", action))
+
+	query := `
+    MATCH (p:` + echo_neo4j.LabelPermission + ` {action: $action})
+    RETURN p
+    ORDER BY p.name
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"action": action})
+	if err != nil {
+		logger.Error("Failed to execute get permissions by action query",
+			zap.Error(err),
+			zap.String("action", action),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	permissions, err := collectPermissions(records)
+	if err != nil {
+		logger.Error("Failed to map permission node to struct",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrInternalServer
+	}
+
+	logger.Info("Permissions retrieved by action successfully",
+		zap.String("action", action),
+		zap.Int("count", len(permissions)),
+		zap.Duration("duration", time.Since(start)))
+
+	return permissions, nil
+}
+
+// ListOrphanedPermissions returns permissions that no role grants
+func (dao *PermissionDAO) ListOrphanedPermissions(ctx context.Context) ([]*model.Permission, error) {
+	start := time.Now()
+	logger.Info("Listing orphaned permissions")
+
+	query := `
+    MATCH (p:` + echo_neo4j.LabelPermission + `)
+    WHERE NOT (:` + echo_neo4j.LabelRole + `)-[:` + echo_neo4j.RelHasPermission + `]->(p)
+    RETURN p
+    ORDER BY p.name
+    `
+	records, err := readRecords(ctx, dao.Driver, query, nil)
+	if err != nil {
+		logger.Error("Failed to execute list orphaned permissions query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	permissions, err := collectPermissions(records)
+	if err != nil {
+		logger.Error("Failed to map permission node to struct",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrInternalServer
+	}
+
+	logger.Info("Orphaned permissions listed successfully",
+		zap.Int("count", len(permissions)),
+		zap.Duration("duration", time.Since(start)))
+
+	return permissions, nil
+}
+
+// DeleteOrphanedPermissions removes permissions that no role grants and that were created before
+// createdBefore. Permissions created before creation times were recorded count as old enough.
+func (dao *PermissionDAO) DeleteOrphanedPermissions(ctx context.Context, createdBefore time.Time) ([]string, error) {
+	start := time.Now()
+	logger.Info("Deleting orphaned permissions", zap.Time("createdBefore", createdBefore))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		query := `
+        MATCH (p:` + echo_neo4j.LabelPermission + `)
+        WHERE NOT (:` + echo_neo4j.LabelRole + `)-[:` + echo_neo4j.RelHasPermission + `]->(p)
+          AND (p.createdAt IS NULL OR datetime(p.createdAt) < datetime($createdBefore))
+        WITH p, p.id AS id
+        DETACH DELETE p
+        RETURN id
+        `
+		result, err := transaction.Run(query, map[string]interface{}{
+			"createdBefore": createdBefore.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		deletedIDs := []string{}
+		for result.Next() {
+			if id, ok := result.Record().Values[0].(string); ok {
+				deletedIDs = append(deletedIDs, id)
+			}
+		}
+		return deletedIDs, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to delete orphaned permissions",
+			zap.Error(err),
+			zap.Duration("duration", duration))
+		return nil, classifyNeo4jError(err)
+	}
+
+	deletedIDs := result.([]string)
+	logger.Info("Orphaned permissions deleted successfully",
+		zap.Int("count", len(deletedIDs)),
+		zap.Duration("duration", duration))
+
+	if len(deletedIDs) == 0 {
+		return deletedIDs, nil
+	}
+
+	// Audit trail
+	changeDetails := deletedDetails(helper_util.ActorFromContext(ctx), map[string]interface{}{
+		"permissionIDs": deletedIDs,
+		"createdBefore": createdBefore.UTC().Format(time.RFC3339),
+	})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        helper_util.ActorFromContext(ctx),
+		Action:        "DELETE_ORPHANED_" + echo_neo4j.LabelPermission,
+		AccessGranted: true,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return deletedIDs, nil
+}
+
+func collectPermissions(records []*neo4j.Record) ([]*model.Permission, error) {
+	var permissions []*model.Permission
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
+		permission, err := mapNodeToPermission(node)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, nil
+}
+
 // Helper function to map Neo4j Node to Permission struct
 func mapNodeToPermission(node neo4j.Node) (*model.Permission, error) {
 	props := node.Props
@@ -363,6 +604,8 @@ func mapNodeToPermission(node neo4j.Node) (*model.Permission, error) {
 	if permission.Action, ok = props["action"].(string); !ok {
 		return nil, fmt.Errorf("invalid or missing 'action' property")
 	}
+	permission.CreatedBy, _ = props[echo_neo4j.AttrCreatedBy].(string)
+	permission.UpdatedBy, _ = props[echo_neo4j.AttrUpdatedBy].(string)
 
 	return permission, nil
 }
@@ -0,0 +1,158 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func permissionNode(id, name, action string) neo4j.Node {
+	return neo4j.Node{Props: map[string]any{
+		"id":          id,
+		"name":        name,
+		"description": name + " permission",
+		"action":      action,
+	}}
+}
+
+func TestPermissionsByAction(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	newDAO := func() (*dao.PermissionDAO, *mock.MockSession) {
+		session := &mock.MockSession{}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return &dao.PermissionDAO{Driver: driver, AuditService: &mock.MockAuditService{}}, session
+	}
+
+	t.Run("GetPermissionsByAction_ReturnsAllGrantingPermissions", func(t *testing.T) {
+		permissionDAO, session := newDAO()
+		result := &mock.MockResult{}
+		result.On("Next").Return(true).Twice()
+		result.On("Next").Return(false)
+		result.On("Record").Return(&neo4j.Record{Values: []any{permissionNode("p1", "Read documents", "read")}}).Once()
+		result.On("Record").Return(&neo4j.Record{Values: []any{permissionNode("p2", "Read reports", "read")}}).Once()
+		session.On("Run", queryContaining("{action: $action}"), map[string]interface{}{"action": "read"}, testify_mock.Anything).
+			Return(result, nil)
+
+		permissions, err := permissionDAO.GetPermissionsByAction(ctx, "read")
+
+		assert.NoError(t, err)
+		if assert.Len(t, permissions, 2) {
+			assert.Equal(t, "p1", permissions[0].ID)
+			assert.Equal(t, "p2", permissions[1].ID)
+			for _, permission := range permissions {
+				assert.Equal(t, "read", permission.Action)
+			}
+		}
+	})
+
+	t.Run("GetPermissionsByAction_NoMatches", func(t *testing.T) {
+		permissionDAO, session := newDAO()
+		result := &mock.MockResult{}
+		result.On("Next").Return(false)
+		session.On("Run", queryContaining("{action: $action}"), testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+
+		permissions, err := permissionDAO.GetPermissionsByAction(ctx, "approve")
+
+		assert.NoError(t, err)
+		assert.Empty(t, permissions)
+	})
+
+	t.Run("SearchPermissions_FiltersByNameAndAction", func(t *testing.T) {
+		permissionDAO, session := newDAO()
+		result := resultWithRecord(permissionNode("p3", "Write reports", "write"))
+		result.On("Next").Return(false)
+		session.On("Run", queryContaining("toLower(p.action) CONTAINS toLower($action)"), map[string]interface{}{
+			"name":   "report",
+			"action": "writ",
+			"limit":  10,
+		}, testify_mock.Anything).Return(result, nil)
+
+		permissions, err := permissionDAO.SearchPermissions(ctx, model.PermissionSearchCriteria{Name: "report", Action: "writ", Limit: 10})
+
+		assert.NoError(t, err)
+		if assert.Len(t, permissions, 1) {
+			assert.Equal(t, "write", permissions[0].Action)
+		}
+	})
+}
+
+func TestOrphanedPermissions(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+	orphanFilter := "WHERE NOT (:" + echo_neo4j.LabelRole + ")-[:" + echo_neo4j.RelHasPermission + "]->(p)"
+
+	newDAO := func() (*dao.PermissionDAO, *mock.MockTxSession, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		return &dao.PermissionDAO{Driver: driver, AuditService: auditService}, session, tx, auditService
+	}
+
+	t.Run("ListOrphanedPermissions_ExcludesAttached", func(t *testing.T) {
+		permissionDAO, session, _, _ := newDAO()
+		// Of "read", "write" and "export", only "export" is not granted by any role
+		result := resultWithRecord(permissionNode("p3", "Export reports", "export"))
+		result.On("Next").Return(false)
+		session.On("Run", queryContaining(orphanFilter), testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+
+		permissions, err := permissionDAO.ListOrphanedPermissions(ctx)
+
+		assert.NoError(t, err)
+		if assert.Len(t, permissions, 1) {
+			assert.Equal(t, "p3", permissions[0].ID)
+		}
+	})
+
+	t.Run("DeleteOrphanedPermissions_AuditsDeletedIDs", func(t *testing.T) {
+		permissionDAO, _, tx, auditService := newDAO()
+		createdBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		result := resultWithRecord("p3")
+		result.On("Next").Return(false)
+		tx.On("Run", queryContaining("DETACH DELETE p"), map[string]interface{}{"createdBefore": "2024-01-01T00:00:00Z"}).
+			Return(result, nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "DELETE_ORPHANED_"+echo_neo4j.LabelPermission
+		})).Return(nil)
+
+		deletedIDs, err := permissionDAO.DeleteOrphanedPermissions(ctx, createdBefore)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"p3"}, deletedIDs)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("DeleteOrphanedPermissions_NothingToDelete", func(t *testing.T) {
+		permissionDAO, _, tx, auditService := newDAO()
+		result := &mock.MockResult{}
+		result.On("Next").Return(false)
+		tx.On("Run", queryContaining("DETACH DELETE p"), testify_mock.Anything).Return(result, nil)
+
+		deletedIDs, err := permissionDAO.DeleteOrphanedPermissions(ctx, time.Now())
+
+		assert.NoError(t, err)
+		assert.Empty(t, deletedIDs)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+}
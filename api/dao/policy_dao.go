@@ -17,14 +17,15 @@ import (
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 type PolicyDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewPolicyDAO(driver neo4j.Driver, auditService audit.Service) *PolicyDAO {
+func NewPolicyDAO(driver Neo4jDriver, auditService audit.Service) *PolicyDAO {
 	dao := &PolicyDAO{Driver: driver, AuditService: auditService}
 	// Ensure unique constraint on Policy ID
 	ctx := context.Background()
@@ -75,8 +76,11 @@ func (dao *PolicyDAO) CreatePolicy(ctx context.Context, policy model.Policy, use
 	if policy.ID == "" {
 		policy.ID = uuid.New().String() // Generate a new UUID if ID is not provided
 	}
+	userID = changedBy(ctx, userID)
+	policy.CreatedBy = userID
+	policy.UpdatedBy = userID
 
-	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+	result, err := writeTransaction(ctx, session, func(transaction neo4j.Transaction) (interface{}, error) {
 		// First, check if the policy already exists
 		checkQuery := `
         MATCH (p:` + echo_neo4j.LabelPolicy + ` {id: $id})
@@ -84,7 +88,7 @@ func (dao *PolicyDAO) CreatePolicy(ctx context.Context, policy model.Policy, use
         `
 		checkResult, err := transaction.Run(checkQuery, map[string]interface{}{"id": policy.ID})
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 		if checkResult.Next() {
 			return nil, echo_errors.ErrPolicyConflict
@@ -92,19 +96,25 @@ func (dao *PolicyDAO) CreatePolicy(ctx context.Context, policy model.Policy, use
 
 		// If we get here, the policy doesn't exist, so create it
 		createQuery := `
-            MERGE (p:` + echo_neo4j.LabelPolicy + ` {id: $id})
-            ON CREATE SET p += $props
-            ON MATCH SET p += $props
+            CREATE (p:` + echo_neo4j.LabelPolicy + ` {id: $id})
+            SET p += $props
             RETURN p.id as id
         `
 
-		// Convert subjects, resourceTypes, attributeGroups, actions, conditions, and dynamicAttributes to JSON strings
+		// Convert subjects, resourceTypes, attributeGroups, actions, conditions, dynamicAttributes and obligations to JSON strings
 		subjectsJSON, _ := json.Marshal(policy.Subjects)
 		resourceTypesJSON, _ := json.Marshal(policy.ResourceTypes)
 		attributeGroupsJSON, _ := json.Marshal(policy.AttributeGroups)
 		actionsJSON, _ := json.Marshal(policy.Actions)
 		conditionsJSON, _ := json.Marshal(policy.Conditions)
 		dynamicAttributesJSON, _ := json.Marshal(policy.DynamicAttributes)
+		obligationsJSON, _ := json.Marshal(policy.Obligations)
+
+		// Policies created without going through approval take effect at once
+		status := policy.Status
+		if status == "" {
+			status = model.PolicyStatusActive
+		}
 
 		parameters := map[string]interface{}{
 			"id": policy.ID,
@@ -117,6 +127,8 @@ func (dao *PolicyDAO) CreatePolicy(ctx context.Context, policy model.Policy, use
 				"parentPolicyID":    policy.ParentPolicyID,
 				"createdAt":         policy.CreatedAt.Format(time.RFC3339),
 				"updatedAt":         policy.UpdatedAt.Format(time.RFC3339),
+				"createdBy":         policy.CreatedBy,
+				"updatedBy":         policy.UpdatedBy,
 				"active":            policy.Active,
 				"activationDate":    formatNullableTime(policy.ActivationDate),
 				"deactivationDate":  formatNullableTime(policy.DeactivationDate),
@@ -126,11 +138,15 @@ func (dao *PolicyDAO) CreatePolicy(ctx context.Context, policy model.Policy, use
 				"actions":           string(actionsJSON),
 				"conditions":        string(conditionsJSON),
 				"dynamicAttributes": string(dynamicAttributesJSON),
+				"obligations":       string(obligationsJSON),
+				"templateID":        policy.TemplateID,
+				"organizationID":    policy.OrganizationID,
+				"status":            status,
 			},
 		}
 		createResult, err := transaction.Run(createQuery, parameters)
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 		if !createResult.Next() {
 			return nil, echo_errors.ErrInternalServer
@@ -149,14 +165,6 @@ func (dao *PolicyDAO) CreatePolicy(ctx context.Context, policy model.Policy, use
 					MATCH (d:`+echo_neo4j.LabelDepartment+` {id: u.departmentID})
 					MERGE (u)-[:`+echo_neo4j.RelWorksFor+`]->(o)
 					MERGE (u)-[:`+echo_neo4j.RelMemberOf+`]->(d)
-					WITH u
-					UNWIND u.roleIds AS roleId
-					MATCH (r:`+echo_neo4j.LabelRole+` {id: roleId})
-					MERGE (u)-[:`+echo_neo4j.RelHasRole+`]->(r)
-					WITH u
-					UNWIND u.groupIds AS groupId
-					MATCH (g:`+echo_neo4j.LabelGroup+` {id: groupId})
-					MERGE (u)-[:`+echo_neo4j.RelBelongsToGroup+`]->(g)
 				`, map[string]interface{}{
 					"userID": subject.UserID,
 				})
@@ -166,23 +174,28 @@ func (dao *PolicyDAO) CreatePolicy(ctx context.Context, policy model.Policy, use
 			}
 		}
 
-		// Create relationships for resource types and attribute groups
-		_, err = transaction.Run(`
-			MATCH (p:`+echo_neo4j.LabelPolicy+` {id: $policyID})
-			UNWIND $resourceTypes AS resourceTypeID
-			MATCH (rt:`+echo_neo4j.LabelResourceType+` {id: resourceTypeID})
-			MERGE (p)-[:`+echo_neo4j.RelAppliesTo+`]->(rt)
-			WITH p
-			UNWIND $attributeGroups AS attributeGroupID
-			MATCH (ag:`+echo_neo4j.LabelAttributeGroup+` {id: attributeGroupID})
-			MERGE (p)-[:`+echo_neo4j.RelAppliesTo+`]->(ag)
-		`, map[string]interface{}{
-			"policyID":        policy.ID,
-			"resourceTypes":   policy.ResourceTypes,
-			"attributeGroups": policy.AttributeGroups,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to create resource type and attribute group relationships: %w", err)
+		// Link the policy to its resource types, attribute groups, subjects and conditions
+		if err := linkPolicy(transaction, policy); err != nil {
+			return nil, fmt.Errorf("failed to create policy relationships: %w", err)
+		}
+
+		// Link the policy to the template it was instantiated from
+		if policy.TemplateID != "" {
+			linkResult, err := transaction.Run(`
+				MATCH (p:`+echo_neo4j.LabelPolicy+` {id: $policyID})
+				MATCH (t:`+echo_neo4j.LabelPolicyTemplate+` {id: $templateID})
+				MERGE (p)-[:`+echo_neo4j.RelInstantiatedFrom+`]->(t)
+				RETURN t.id
+			`, map[string]interface{}{
+				"policyID":   policy.ID,
+				"templateID": policy.TemplateID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to link policy to template: %w", err)
+			}
+			if !linkResult.Next() {
+				return nil, echo_errors.ErrPolicyTemplateNotFound
+			}
 		}
 
 		return id, nil
@@ -194,10 +207,16 @@ func (dao *PolicyDAO) CreatePolicy(ctx context.Context, policy model.Policy, use
 			zap.Error(err),
 			zap.String("policyName", policy.Name),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrPolicyConflict, policy.ID)
 	}
+	recordBookmarks(ctx, session)
 
 	policyID := fmt.Sprintf("%v", result)
+	if helper_util.IsDryRun(ctx) {
+		logger.Info("Dry run: policy creation rolled back", zap.String("policyID", policyID))
+		return policyID, nil
+	}
+
 	logger.Info("Policy created successfully",
 		zap.String("policyID", policyID),
 		zap.Duration("duration", duration))
@@ -226,21 +245,23 @@ func (dao *PolicyDAO) UpdatePolicy(ctx context.Context, policy model.Policy, use
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
+	userID = changedBy(ctx, userID)
 	var updatedPolicy *model.Policy
 	oldPolicy, err := dao.GetPolicy(ctx, policy.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get policy: %w", err)
 	}
 
-	_, err = session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+	_, err = writeTransaction(ctx, session, func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
 				MATCH (p:` + echo_neo4j.LabelPolicy + ` {id: $id})
 				SET p.name = $name, p.description = $description, p.effect = $effect,
-					p.priority = $priority, p.version = $version, p.updatedAt = $updatedAt,
+					p.priority = $priority, p.version = $version, p.updatedAt = $updatedAt, p.updatedBy = $updatedBy,
 					p.active = $active, p.activationDate = $activationDate, p.deactivationDate = $deactivationDate,
 					p.subjects = $subjects, p.resourceTypes = $resourceTypes, p.attributeGroups = $attributeGroups, 
 					p.actions = $actions, p.conditions = $conditions, p.dynamicAttributes = $dynamicAttributes,
-					p.parentPolicyID = $parentPolicyID
+					p.obligations = $obligations, p.parentPolicyID = $parentPolicyID,
+					p.status = coalesce($status, p.status)
 				RETURN p
 				`
 
@@ -251,12 +272,13 @@ func (dao *PolicyDAO) UpdatePolicy(ctx context.Context, policy model.Policy, use
 		actionsJSON, _ := json.Marshal(policy.Actions)
 		conditionsJSON, _ := json.Marshal(policy.Conditions)
 		dynamicAttributesJSON, _ := json.Marshal(policy.DynamicAttributes)
+		obligationsJSON, _ := json.Marshal(policy.Obligations)
 
 		parameters := map[string]interface{}{
 			"id": policy.ID, "name": policy.Name, "description": policy.Description,
 			"effect": policy.Effect, "priority": policy.Priority, "version": policy.Version,
-			"updatedAt": time.Now().Format(time.RFC3339),
-			"active":    policy.Active, "activationDate": formatNullableTime(policy.ActivationDate),
+			"updatedAt": time.Now().Format(time.RFC3339), "updatedBy": userID,
+			"active": policy.Active, "activationDate": formatNullableTime(policy.ActivationDate),
 			"deactivationDate":  formatNullableTime(policy.DeactivationDate),
 			"subjects":          string(subjectsJSON),
 			"resourceTypes":     string(resourceTypesJSON),
@@ -264,7 +286,12 @@ func (dao *PolicyDAO) UpdatePolicy(ctx context.Context, policy model.Policy, use
 			"actions":           string(actionsJSON),
 			"conditions":        string(conditionsJSON),
 			"dynamicAttributes": string(dynamicAttributesJSON),
+			"obligations":       string(obligationsJSON),
 			"parentPolicyID":    policy.ParentPolicyID,
+			"status":            nil, // A policy updated without a status keeps its own
+		}
+		if policy.Status != "" {
+			parameters["status"] = policy.Status
 		}
 		result, err := transaction.Run(query, parameters)
 		if err != nil {
@@ -288,14 +315,6 @@ func (dao *PolicyDAO) UpdatePolicy(ctx context.Context, policy model.Policy, use
 					MATCH (d:`+echo_neo4j.LabelDepartment+` {id: u.departmentID})
 					MERGE (u)-[:`+echo_neo4j.RelWorksFor+`]->(o)
 					MERGE (u)-[:`+echo_neo4j.RelMemberOf+`]->(d)
-					WITH u
-					UNWIND u.roleIds AS roleId
-					MATCH (r:`+echo_neo4j.LabelRole+` {id: roleId})
-					MERGE (u)-[:`+echo_neo4j.RelHasRole+`]->(r)
-					WITH u
-					UNWIND u.groupIds AS groupId
-					MATCH (g:`+echo_neo4j.LabelGroup+` {id: groupId})
-					MERGE (u)-[:`+echo_neo4j.RelBelongsToGroup+`]->(g)
 				`, map[string]interface{}{
 					"userID": subject.UserID,
 				})
@@ -305,28 +324,12 @@ func (dao *PolicyDAO) UpdatePolicy(ctx context.Context, policy model.Policy, use
 			}
 		}
 
-		// Update relationships for resource types and attribute groups
-		_, err = transaction.Run(`
-			MATCH (p:`+echo_neo4j.LabelPolicy+` {id: $policyID})
-			// Remove old relationships
-			OPTIONAL MATCH (p)-[r:`+echo_neo4j.RelAppliesTo+`]->()
-			DELETE r
-			// Create new relationships
-			WITH p
-			UNWIND $resourceTypes AS resourceTypeID
-			MATCH (rt:`+echo_neo4j.LabelResourceType+` {id: resourceTypeID})
-			MERGE (p)-[:`+echo_neo4j.RelAppliesTo+`]->(rt)
-			WITH p
-			UNWIND $attributeGroups AS attributeGroupID
-			MATCH (ag:`+echo_neo4j.LabelAttributeGroup+` {id: attributeGroupID})
-			MERGE (p)-[:`+echo_neo4j.RelAppliesTo+`]->(ag)
-		`, map[string]interface{}{
-			"policyID":        policy.ID,
-			"resourceTypes":   policy.ResourceTypes,
-			"attributeGroups": policy.AttributeGroups,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to update resource type and attribute group relationships: %w", err)
+		// Replace the resource types, attribute groups, subjects and conditions the policy is linked to
+		if err := unlinkPolicy(transaction, policy.ID); err != nil {
+			return nil, fmt.Errorf("failed to remove policy relationships: %w", err)
+		}
+		if err := linkPolicy(transaction, policy); err != nil {
+			return nil, fmt.Errorf("failed to update policy relationships: %w", err)
 		}
 
 		return nil, nil
@@ -338,7 +341,13 @@ func (dao *PolicyDAO) UpdatePolicy(ctx context.Context, policy model.Policy, use
 			zap.Error(err),
 			zap.String("policyID", policy.ID),
 			zap.Duration("duration", duration))
-		return nil, fmt.Errorf("failed to update policy: %w", err)
+		return nil, fmt.Errorf("failed to update policy: %w", classifyNeo4jError(err))
+	}
+	recordBookmarks(ctx, session)
+
+	if helper_util.IsDryRun(ctx) {
+		logger.Info("Dry run: policy update rolled back", zap.String("policyID", policy.ID))
+		return updatedPolicy, nil
 	}
 
 	logger.Info("Policy updated successfully",
@@ -362,6 +371,234 @@ func (dao *PolicyDAO) UpdatePolicy(ctx context.Context, policy model.Policy, use
 	return updatedPolicy, nil
 }
 
+// policyReviewActions names the audit action of each status a policy may be moved to
+var policyReviewActions = map[string]string{
+	model.PolicyStatusPendingApproval: "SUBMIT_POLICY",
+	model.PolicyStatusActive:          "APPROVE_POLICY",
+	model.PolicyStatusRejected:        "REJECT_POLICY",
+}
+
+// SetPolicyStatus moves a policy from status from to status to, recording the actor and comment: a
+// submission for approval records its submitter and clears the last review, an approval or rejection
+// records its reviewer. The policy is only moved while it still has status from, so of two reviewers
+// acting at once only one succeeds; the other gets ErrInvalidPolicyStatusTransition.
+func (dao *PolicyDAO) SetPolicyStatus(ctx context.Context, policyID string, from string, to string, actorID string, comment string) (*model.Policy, error) {
+	start := time.Now()
+	logger.Info("Setting policy status", zap.String("policyID", policyID), zap.String("status", to))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	review := `p.reviewedBy = $actorID, p.reviewedAt = $now, p.reviewComment = $comment`
+	if to == model.PolicyStatusPendingApproval {
+		review = `p.submittedBy = $actorID, p.submittedAt = $now, p.reviewedBy = null, p.reviewedAt = null, p.reviewComment = null`
+	}
+
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		query := `
+        MATCH (p:` + echo_neo4j.LabelPolicy + ` {id: $id})
+        WITH p, coalesce(p.status, '` + model.PolicyStatusActive + `') AS oldStatus
+        WHERE oldStatus = $from
+        SET p.status = $status,
+            p.updatedAt = $now,
+            p.updatedBy = $actorID,
+            ` + review + `
+        RETURN p
+        `
+		result, err := transaction.Run(query, map[string]interface{}{
+			"id":      policyID,
+			"from":    from,
+			"status":  to,
+			"actorID": actorID,
+			"comment": comment,
+			"now":     time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if result.Next() {
+			return mapNodeToPolicy(result.Record().Values[0].(neo4j.Node))
+		}
+
+		// Either the policy is gone or its status changed since it was read
+		checkResult, err := transaction.Run(`
+        MATCH (p:`+echo_neo4j.LabelPolicy+` {id: $id})
+        RETURN coalesce(p.status, '`+model.PolicyStatusActive+`')
+        `, map[string]interface{}{"id": policyID})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if !checkResult.Next() {
+			return nil, echo_errors.ErrPolicyNotFound
+		}
+		return nil, fmt.Errorf("%w: %v to %s", echo_errors.ErrInvalidPolicyStatusTransition, checkResult.Record().Values[0], to)
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to set policy status",
+			zap.Error(err),
+			zap.String("policyID", policyID),
+			zap.String("status", to),
+			zap.Duration("duration", duration))
+		return nil, classifyNeo4jError(err)
+	}
+
+	logger.Info("Policy status updated successfully",
+		zap.String("policyID", policyID),
+		zap.String("oldStatus", from),
+		zap.String("newStatus", to),
+		zap.Duration("duration", duration))
+
+	// Audit trail
+	changeDetails, _ := json.Marshal(map[string]interface{}{
+		"action":  "status_changed",
+		"status":  map[string]string{"old": from, "new": to},
+		"comment": comment,
+	})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        actorID,
+		Action:        policyReviewActions[to],
+		ResourceID:    policyID,
+		AccessGranted: true,
+		PolicyID:      policyID,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return result.(*model.Policy), nil
+}
+
+// linkPolicy links a policy into the graph: APPLIES_TO relationships to the resource types and
+// attribute groups it names and to a POLICY_SUBJECT node per subject, and HAS_CONDITION relationships
+// to a CONDITION node per top-level condition. The policy is still read from its JSON properties; the
+// graph serves traversals such as the usage analysis.
+func linkPolicy(transaction neo4j.Transaction, policy model.Policy) error {
+	targets := []struct {
+		label string
+		ids   []string
+	}{
+		{echo_neo4j.LabelResourceType, policy.ResourceTypes},
+		{echo_neo4j.LabelAttributeGroup, policy.AttributeGroups},
+	}
+	for _, target := range targets {
+		_, err := transaction.Run(`
+			MATCH (p:`+echo_neo4j.LabelPolicy+` {id: $policyID})
+			UNWIND $ids AS targetID
+			MATCH (t:`+target.label+` {id: targetID})
+			MERGE (p)-[:`+echo_neo4j.RelAppliesTo+`]->(t)
+		`, map[string]interface{}{"policyID": policy.ID, "ids": target.ids})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(policy.Subjects) > 0 {
+		subjects := make([]map[string]interface{}, len(policy.Subjects))
+		for i, subject := range policy.Subjects {
+			attributesJSON, _ := json.Marshal(subject.Attributes)
+			refID := subject.Attributes["id"]
+			if subject.Type == "user" {
+				refID = subject.UserID
+			}
+			subjects[i] = map[string]interface{}{
+				"position":   i,
+				"type":       strings.ToLower(subject.Type),
+				"refID":      refID,
+				"attributes": string(attributesJSON),
+			}
+		}
+		_, err := transaction.Run(`
+			MATCH (p:`+echo_neo4j.LabelPolicy+` {id: $policyID})
+			UNWIND $subjects AS subject
+			CREATE (p)-[:`+echo_neo4j.RelAppliesTo+`]->(:`+echo_neo4j.LabelPolicySubject+` {
+				policyID: $policyID, position: subject.position, type: subject.type,
+				refID: subject.refID, attributes: subject.attributes
+			})
+		`, map[string]interface{}{"policyID": policy.ID, "subjects": subjects})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(policy.Conditions) > 0 {
+		conditions := make([]map[string]interface{}, len(policy.Conditions))
+		for i, condition := range policy.Conditions {
+			valueJSON, _ := json.Marshal(condition.Value)
+			subConditionsJSON, _ := json.Marshal(condition.SubConditions)
+			conditions[i] = map[string]interface{}{
+				"position":      i,
+				"attribute":     condition.Attribute,
+				"operator":      condition.Operator,
+				"value":         string(valueJSON),
+				"subConditions": string(subConditionsJSON),
+				"isDynamic":     condition.IsDynamic,
+			}
+		}
+		_, err := transaction.Run(`
+			MATCH (p:`+echo_neo4j.LabelPolicy+` {id: $policyID})
+			UNWIND $conditions AS condition
+			CREATE (p)-[:`+echo_neo4j.RelHasCondition+`]->(:`+echo_neo4j.LabelCondition+` {
+				policyID: $policyID, position: condition.position, attribute: condition.attribute,
+				operator: condition.operator, value: condition.value,
+				subConditions: condition.subConditions, isDynamic: condition.isDynamic
+			})
+		`, map[string]interface{}{"policyID": policy.ID, "conditions": conditions})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unlinkPolicy removes what linkPolicy created for a policy, deleting the subject and condition nodes
+// the policy owns
+func unlinkPolicy(transaction neo4j.Transaction, policyID string) error {
+	_, err := transaction.Run(`
+		MATCH (p:`+echo_neo4j.LabelPolicy+` {id: $policyID})
+		OPTIONAL MATCH (p)-[:`+echo_neo4j.RelAppliesTo+`|`+echo_neo4j.RelHasCondition+`]->(owned)
+		WHERE owned:`+echo_neo4j.LabelPolicySubject+` OR owned:`+echo_neo4j.LabelCondition+`
+		DETACH DELETE owned
+		WITH DISTINCT p
+		OPTIONAL MATCH (p)-[old:`+echo_neo4j.RelAppliesTo+`]->()
+		DELETE old
+	`, map[string]interface{}{"policyID": policyID})
+	return err
+}
+
+// RelinkPolicies rebuilds the graph links of every policy from its JSON properties, for policies
+// stored before they were linked. It runs in the caller's transaction, such as a migration's.
+func RelinkPolicies(transaction neo4j.Transaction) error {
+	result, err := transaction.Run(`
+		MATCH (p:`+echo_neo4j.LabelPolicy+`)
+		RETURN p
+	`, nil)
+	if err != nil {
+		return err
+	}
+	records, err := result.Collect()
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		policy, err := mapNodeToPolicy(record.Values[0].(neo4j.Node))
+		if err != nil {
+			return fmt.Errorf("failed to map policy node to struct: %w", err)
+		}
+		if err := unlinkPolicy(transaction, policy.ID); err != nil {
+			return fmt.Errorf("failed to remove relationships of policy %s: %w", policy.ID, err)
+		}
+		if err := linkPolicy(transaction, *policy); err != nil {
+			return fmt.Errorf("failed to link policy %s: %w", policy.ID, err)
+		}
+	}
+	return nil
+}
+
 // DeletePolicy deletes a policy from Neo4j
 func (dao *PolicyDAO) DeletePolicy(ctx context.Context, policyID string, userID string) error {
 	start := time.Now()
@@ -371,6 +608,10 @@ func (dao *PolicyDAO) DeletePolicy(ctx context.Context, policyID string, userID
 	defer session.Close()
 
 	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		// The subject and condition nodes belong to the policy and go with it
+		if err := unlinkPolicy(transaction, policyID); err != nil {
+			return nil, fmt.Errorf("failed to remove policy relationships: %w", err)
+		}
 		query := `
         MATCH (p:` + echo_neo4j.LabelPolicy + ` {id: $id})
         DETACH DELETE p
@@ -395,7 +636,7 @@ func (dao *PolicyDAO) DeletePolicy(ctx context.Context, policyID string, userID
 			zap.Error(err),
 			zap.String("policyID", policyID),
 			zap.Duration("duration", duration))
-		return fmt.Errorf("failed to delete policy: %w", err)
+		return fmt.Errorf("failed to delete policy: %w", classifyNeo4jError(err))
 	}
 
 	logger.Info("Policy deleted successfully",
@@ -410,6 +651,7 @@ func (dao *PolicyDAO) DeletePolicy(ctx context.Context, policyID string, userID
 		ResourceID:    policyID,
 		AccessGranted: true,
 		PolicyID:      policyID,
+		ChangeDetails: deletedDetails(userID, nil),
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
@@ -418,12 +660,17 @@ func (dao *PolicyDAO) DeletePolicy(ctx context.Context, policyID string, userID
 	return nil
 }
 
+// Exists reports whether a policy with the given ID exists, without reading the whole node
+func (dao *PolicyDAO) Exists(ctx context.Context, policyID string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelPolicy, policyID)
+}
+
 // GetPolicy retrieves a policy from Neo4j by its ID
 func (dao *PolicyDAO) GetPolicy(ctx context.Context, policyID string) (*model.Policy, error) {
 	start := time.Now()
 	logger.Info("Retrieving policy", zap.String("policyID", policyID))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	session := dao.Driver.NewSession(readSessionConfig(ctx))
 	defer session.Close()
 
 	query := `
@@ -461,14 +708,49 @@ func (dao *PolicyDAO) GetPolicy(ctx context.Context, policyID string) (*model.Po
 	return nil, echo_errors.ErrPolicyNotFound
 }
 
+// GetPoliciesByIDs retrieves the policies among policyIDs in one query. IDs with no policy are left
+// out of the result, which follows no particular order.
+func (dao *PolicyDAO) GetPoliciesByIDs(ctx context.Context, policyIDs []string) ([]*model.Policy, error) {
+	start := time.Now()
+	logger.Info("Retrieving policies by ID", zap.Int("count", len(policyIDs)))
+
+	query := `
+    MATCH (p:` + echo_neo4j.LabelPolicy + `)
+    WHERE p.id IN $ids
+    RETURN p
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"ids": policyIDs})
+	if err != nil {
+		logger.Error("Failed to execute get policies by ID query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	policies := make([]*model.Policy, 0, len(records))
+	for _, record := range records {
+		policy, err := mapNodeToPolicy(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map policy node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		policies = append(policies, policy)
+	}
+
+	logger.Info("Policies retrieved by ID successfully",
+		zap.Int("requested", len(policyIDs)),
+		zap.Int("found", len(policies)),
+		zap.Duration("duration", time.Since(start)))
+	return policies, nil
+}
+
 // ListPolicies retrieves all policies from Neo4j with pagination
 func (dao *PolicyDAO) ListPolicies(ctx context.Context, limit int, offset int) ([]*model.Policy, error) {
 	start := time.Now()
 	logger.Info("Listing policies", zap.Int("limit", limit), zap.Int("offset", offset))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	query := `
     MATCH (p:` + echo_neo4j.LabelPolicy + `)
     RETURN p
@@ -476,7 +758,7 @@ func (dao *PolicyDAO) ListPolicies(ctx context.Context, limit int, offset int) (
     SKIP $offset
     LIMIT $limit
     `
-	result, err := session.Run(query, map[string]interface{}{
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{
 		"limit":  limit,
 		"offset": offset,
 	})
@@ -487,6 +769,101 @@ func (dao *PolicyDAO) ListPolicies(ctx context.Context, limit int, offset int) (
 		return nil, fmt.Errorf("failed to execute list policies query: %w", err)
 	}
 
+	var policies []*model.Policy
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
+		policy, err := mapNodeToPolicy(node)
+		if err != nil {
+			logger.Error("Failed to map policy node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, fmt.Errorf("failed to map policy node to struct: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	logger.Info("Policies listed successfully",
+		zap.Int("count", len(policies)),
+		zap.Duration("duration", time.Since(start)))
+
+	return policies, nil
+}
+
+// ListPoliciesAfter lists policies newest first, continuing after the given cursor.
+// The returned cursor is nil on the last page.
+func (dao *PolicyDAO) ListPoliciesAfter(ctx context.Context, after *helper_util.Cursor, limit int) ([]*model.Policy, *helper_util.Cursor, error) {
+	start := time.Now()
+	logger.Info("Listing policies by cursor", zap.Any("after", after), zap.Int("limit", limit))
+
+	query := `
+    MATCH (p:` + echo_neo4j.LabelPolicy + `)
+    WHERE $afterCreatedAt IS NULL
+       OR p.createdAt < $afterCreatedAt
+       OR (p.createdAt = $afterCreatedAt AND p.id < $afterID)
+    RETURN p
+    ORDER BY p.createdAt DESC, p.id DESC
+    LIMIT $limit
+    `
+	records, err := readRecords(ctx, dao.Driver, query, helper_util.CursorParams(after, limit))
+	if err != nil {
+		logger.Error("Failed to execute list policies by cursor query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, nil, fmt.Errorf("failed to execute list policies by cursor query: %w", err)
+	}
+
+	var policies []*model.Policy
+	var next *helper_util.Cursor
+	var lastCreatedAt string
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
+		if len(policies) == limit {
+			// The extra row only signals that another page follows
+			next = &helper_util.Cursor{CreatedAt: lastCreatedAt, ID: policies[len(policies)-1].ID}
+			break
+		}
+
+		policy, err := mapNodeToPolicy(node)
+		if err != nil {
+			logger.Error("Failed to map policy node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, nil, fmt.Errorf("failed to map policy node to struct: %w", err)
+		}
+		lastCreatedAt, _ = node.Props["createdAt"].(string)
+		policies = append(policies, policy)
+	}
+
+	logger.Info("Policies listed by cursor successfully",
+		zap.Int("count", len(policies)),
+		zap.Bool("hasMore", next != nil),
+		zap.Duration("duration", time.Since(start)))
+
+	return policies, next, nil
+}
+
+// GetActivePolicies returns every active, approved policy ordered by descending priority, for use by the access evaluation service
+func (dao *PolicyDAO) GetActivePolicies(ctx context.Context) ([]*model.Policy, error) {
+	start := time.Now()
+	logger.Info("Listing active policies")
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close()
+
+	query := `
+    MATCH (p:` + echo_neo4j.LabelPolicy + `)
+    WHERE p.active = true AND coalesce(p.status, '` + model.PolicyStatusActive + `') = '` + model.PolicyStatusActive + `'
+    RETURN p
+    ORDER BY p.priority DESC
+    `
+	result, err := session.Run(query, nil)
+	if err != nil {
+		logger.Error("Failed to execute active policies query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to execute active policies query: %w", err)
+	}
+
 	var policies []*model.Policy
 	for result.Next() {
 		node := result.Record().Values[0].(neo4j.Node)
@@ -500,7 +877,112 @@ func (dao *PolicyDAO) ListPolicies(ctx context.Context, limit int, offset int) (
 		policies = append(policies, policy)
 	}
 
-	logger.Info("Policies listed successfully",
+	logger.Info("Active policies listed successfully",
+		zap.Int("count", len(policies)),
+		zap.Duration("duration", time.Since(start)))
+
+	return policies, nil
+}
+
+// GetPoliciesForSubject returns the active, approved policies in their activation window that name the user
+// as a subject, directly or through the roles, groups, department and organization the user is linked
+// to, ordered by descending priority. Subjects are matched on their type and id as the access
+// evaluation does, including "*" roles and groups and user subjects without an id; any further subject
+// attributes are left to the evaluation, so the result is the candidate set it starts from.
+func (dao *PolicyDAO) GetPoliciesForSubject(ctx context.Context, userID string) ([]*model.Policy, error) {
+	start := time.Now()
+	logger.Info("Listing policies for subject", zap.String("userID", userID))
+
+	query := `
+    MATCH (u:` + echo_neo4j.LabelUser + ` {id: $userID})
+    WITH u,
+        [(u)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + `) | r.id] AS roleIDs,
+        [(u)-[:` + echo_neo4j.RelBelongsToGroup + `]->(g:` + echo_neo4j.LabelGroup + `) | g.id] AS groupIDs,
+        [(u)-[:` + echo_neo4j.RelMemberOf + `]->(d:` + echo_neo4j.LabelDepartment + `) | d.id] AS departmentIDs,
+        [(u)-[:` + echo_neo4j.RelWorksFor + `]->(o:` + echo_neo4j.LabelOrganization + `) | o.id] AS organizationIDs
+    MATCH (p:` + echo_neo4j.LabelPolicy + `)-[:` + echo_neo4j.RelAppliesTo + `]->(s:` + echo_neo4j.LabelPolicySubject + `)
+    WHERE p.active = true AND coalesce(p.status, '` + model.PolicyStatusActive + `') = '` + model.PolicyStatusActive + `'
+        AND (p.activationDate IS NULL OR datetime(p.activationDate) <= datetime($now))
+        AND (p.deactivationDate IS NULL OR datetime(p.deactivationDate) > datetime($now))
+        AND ((s.type = 'user' AND s.refID IN ['', u.id])
+            OR (s.type = 'role' AND (s.refID = '*' OR s.refID IN roleIDs))
+            OR (s.type = 'group' AND (s.refID = '*' OR s.refID IN groupIDs))
+            OR (s.type = 'department' AND s.refID IN departmentIDs)
+            OR (s.type = 'organization' AND s.refID IN organizationIDs))
+    RETURN DISTINCT p
+    ORDER BY p.priority DESC
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{
+		"userID": userID,
+		"now":    time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		logger.Error("Failed to execute policies for subject query",
+			zap.Error(err),
+			zap.String("userID", userID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to execute policies for subject query: %w", err)
+	}
+
+	policies := []*model.Policy{}
+	for _, record := range records {
+		policy, err := mapNodeToPolicy(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map policy node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, fmt.Errorf("failed to map policy node to struct: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	logger.Info("Policies for subject listed successfully",
+		zap.String("userID", userID),
+		zap.Int("count", len(policies)),
+		zap.Duration("duration", time.Since(start)))
+
+	return policies, nil
+}
+
+// GetPoliciesForResource returns the policies applying to a resource directly or through its resource
+// type or attribute group, ordered by descending priority. A resource with InheritedACL set also gets
+// the policies of its parent, and so on up for as long as each resource inherits.
+func (dao *PolicyDAO) GetPoliciesForResource(ctx context.Context, resourceID string) ([]*model.Policy, error) {
+	start := time.Now()
+	logger.Info("Listing policies for resource", zap.String("resourceID", resourceID))
+
+	query := `
+    MATCH path = (r:` + echo_neo4j.LabelResource + ` {id: $resourceID})-[:` + echo_neo4j.RelChildOf + `*0..]->(source:` + echo_neo4j.LabelResource + `)
+    WHERE all(child IN nodes(path)[0..length(path)] WHERE child.inheritedACL = true)
+    WITH DISTINCT source
+    UNWIND [source] + [(source)-[:` + echo_neo4j.RelHasType + `|` + echo_neo4j.RelInGroup + `]->(t) | t] AS target
+    MATCH (p:` + echo_neo4j.LabelPolicy + `)-[:` + echo_neo4j.RelAppliesTo + `]->(target)
+    RETURN DISTINCT p
+    ORDER BY p.priority DESC, p.id
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"resourceID": resourceID})
+	if err != nil {
+		logger.Error("Failed to execute policies for resource query",
+			zap.Error(err),
+			zap.String("resourceID", resourceID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to execute policies for resource query: %w", err)
+	}
+
+	policies := []*model.Policy{}
+	for _, record := range records {
+		policy, err := mapNodeToPolicy(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map policy node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, fmt.Errorf("failed to map policy node to struct: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	logger.Info("Policies for resource listed successfully",
+		zap.String("resourceID", resourceID),
 		zap.Int("count", len(policies)),
 		zap.Duration("duration", time.Since(start)))
 
@@ -512,16 +994,13 @@ func (dao *PolicyDAO) SearchPolicies(ctx context.Context, criteria model.PolicyS
 	start := time.Now()
 	logger.Info("Searching policies", zap.Any("criteria", criteria))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	var queryBuilder strings.Builder
-	queryBuilder.WriteString("MATCH (p:` + echo_neo4j.LabelPolicy + `) WHERE 1=1")
+	queryBuilder.WriteString("MATCH (p:" + echo_neo4j.LabelPolicy + ") WHERE 1=1")
 
 	params := make(map[string]interface{})
 
 	if criteria.Name != "" {
-		queryBuilder.WriteString(" AND p.name = $name")
+		queryBuilder.WriteString(" AND p.name CONTAINS $name")
 		params["name"] = criteria.Name
 	}
 
@@ -557,14 +1036,13 @@ func (dao *PolicyDAO) SearchPolicies(ctx context.Context, criteria model.PolicyS
 
 	queryBuilder.WriteString(" RETURN p ORDER BY p.createdAt DESC")
 
-	if criteria.Limit > 0 {
-		queryBuilder.WriteString(" LIMIT $limit")
-		params["limit"] = criteria.Limit
-	}
+	paginationClause, paginationParams := helper_util.BuildPagination(criteria.Limit, criteria.Offset)
+	queryBuilder.WriteString(paginationClause)
+	helper_util.MergeParams(params, paginationParams)
 
 	logger.Info("Executing query", zap.String("query", queryBuilder.String()), zap.Any("params", params))
 
-	result, err := session.Run(queryBuilder.String(), params)
+	records, err := readRecords(ctx, dao.Driver, queryBuilder.String(), params)
 	if err != nil {
 		logger.Error("Failed to execute search policies query",
 			zap.Error(err),
@@ -573,8 +1051,8 @@ func (dao *PolicyDAO) SearchPolicies(ctx context.Context, criteria model.PolicyS
 	}
 
 	var policies []*model.Policy
-	for result.Next() {
-		node := result.Record().Values[0].(neo4j.Node)
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
 		policy, err := mapNodeToPolicy(node)
 		if err != nil {
 			logger.Error("Failed to map policy node to struct",
@@ -592,6 +1070,100 @@ func (dao *PolicyDAO) SearchPolicies(ctx context.Context, criteria model.PolicyS
 	return policies, nil
 }
 
+// ResolveUserSubjects looks up the users among subjects along with the organization, department,
+// roles and groups they are linked to, in one query. Subjects of other types, and users that do not
+// exist, are skipped.
+func (dao *PolicyDAO) ResolveUserSubjects(ctx context.Context, subjects []model.Subject) ([]model.ResolvedSubject, error) {
+	start := time.Now()
+
+	var userIDs []string
+	for _, subject := range subjects {
+		if subject.Type == "user" && subject.UserID != "" {
+			userIDs = append(userIDs, subject.UserID)
+		}
+	}
+	resolved := []model.ResolvedSubject{}
+	if len(userIDs) == 0 {
+		return resolved, nil
+	}
+	logger.Info("Resolving policy subjects", zap.Strings("userIDs", userIDs))
+
+	query := `
+    UNWIND $userIDs AS userID
+    MATCH (u:` + echo_neo4j.LabelUser + ` {id: userID})
+    RETURN u,
+        head([(u)-[:` + echo_neo4j.RelWorksFor + `]->(o:` + echo_neo4j.LabelOrganization + `) | o]) AS organization,
+        head([(u)-[:` + echo_neo4j.RelMemberOf + `]->(d:` + echo_neo4j.LabelDepartment + `) | d]) AS department,
+        [(u)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + `) | r] AS roles,
+        [(u)-[:` + echo_neo4j.RelBelongsToGroup + `]->(g:` + echo_neo4j.LabelGroup + `) | g] AS groups
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"userIDs": userIDs})
+	if err != nil {
+		logger.Error("Failed to execute resolve policy subjects query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	byUser := make(map[string]model.ResolvedSubject, len(records))
+	for _, record := range records {
+		entry, err := mapRecordToResolvedSubject(record)
+		if err != nil {
+			logger.Error("Failed to map policy subject",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		byUser[entry.User.ID] = entry
+	}
+	for _, subject := range subjects {
+		if entry, ok := byUser[subject.UserID]; ok && subject.Type == "user" {
+			entry.Subject = subject
+			resolved = append(resolved, entry)
+		}
+	}
+
+	logger.Info("Policy subjects resolved",
+		zap.Int("count", len(resolved)),
+		zap.Duration("duration", time.Since(start)))
+	return resolved, nil
+}
+
+func mapRecordToResolvedSubject(record *neo4j.Record) (model.ResolvedSubject, error) {
+	entry := model.ResolvedSubject{Roles: []*model.Role{}, Groups: []*model.Group{}}
+	var err error
+	if entry.User, err = mapNodeToUser(record.Values[0].(neo4j.Node)); err != nil {
+		return entry, err
+	}
+	if node, ok := record.Values[1].(neo4j.Node); ok {
+		if entry.Organization, err = mapNodeToOrganization(node); err != nil {
+			return entry, err
+		}
+	}
+	if node, ok := record.Values[2].(neo4j.Node); ok {
+		if entry.Department, err = mapNodeToDepartment(node); err != nil {
+			return entry, err
+		}
+	}
+	roles, _ := record.Values[3].([]interface{})
+	for _, value := range roles {
+		role, err := mapNodeToRole(value.(neo4j.Node))
+		if err != nil {
+			return entry, err
+		}
+		entry.Roles = append(entry.Roles, role)
+	}
+	groups, _ := record.Values[4].([]interface{})
+	for _, value := range groups {
+		group, err := mapNodeToGroup(value.(neo4j.Node))
+		if err != nil {
+			return entry, err
+		}
+		entry.Groups = append(entry.Groups, group)
+	}
+	return entry, nil
+}
+
 // AnalyzePolicyUsage analyzes the usage of a policy
 func (dao *PolicyDAO) AnalyzePolicyUsage(ctx context.Context, policyID string) (*model.PolicyUsageAnalysis, error) {
 	start := time.Now()
@@ -602,15 +1174,12 @@ func (dao *PolicyDAO) AnalyzePolicyUsage(ctx context.Context, policyID string) (
 
 	query := `
 		MATCH (p:` + echo_neo4j.LabelPolicy + ` {id: $policyID})
-		OPTIONAL MATCH (p)-[:` + echo_neo4j.RelAppliesTo + `]->(r:Resource)
-		OPTIONAL MATCH (p)-[:` + echo_neo4j.RelAppliesTo + `]->(s:Subject)
-		OPTIONAL MATCH (p)-[:` + echo_neo4j.RelHasCondition + `]->(c:Condition)
-		RETURN 
+		RETURN
 			p.id AS policyID,
 			p.name AS policyName,
-			COUNT(DISTINCT r) AS resourceCount,
-			COUNT(DISTINCT s) AS subjectCount,
-			COUNT(DISTINCT c) AS conditionCount,
+			size([(p)-[:` + echo_neo4j.RelAppliesTo + `]->(r) WHERE r:` + echo_neo4j.LabelResourceType + ` OR r:` + echo_neo4j.LabelAttributeGroup + ` | r]) AS resourceCount,
+			size([(p)-[:` + echo_neo4j.RelAppliesTo + `]->(s:` + echo_neo4j.LabelPolicySubject + `) | s]) AS subjectCount,
+			size([(p)-[:` + echo_neo4j.RelHasCondition + `]->(c:` + echo_neo4j.LabelCondition + `) | c]) AS conditionCount,
 			p.createdAt AS createdAt,
 			p.updatedAt AS updatedAt
     `
@@ -649,6 +1218,132 @@ func (dao *PolicyDAO) AnalyzePolicyUsage(ctx context.Context, policyID string) (
 	return nil, echo_errors.ErrPolicyNotFound
 }
 
+// CreatePolicyTemplate stores a policy template. The template's policy is kept as a JSON document, since
+// its placeholders make it an incomplete policy that must not match any access request.
+func (dao *PolicyDAO) CreatePolicyTemplate(ctx context.Context, template model.PolicyTemplate) (string, error) {
+	start := time.Now()
+	logger.Info("Creating new policy template", zap.String("templateName", template.Name))
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	if template.ID == "" {
+		template.ID = uuid.New().String()
+	}
+
+	policyJSON, err := json.Marshal(template.Policy)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal template policy: %w", err)
+	}
+
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		query := `
+		CREATE (t:` + echo_neo4j.LabelPolicyTemplate + ` {
+			id: $id,
+			name: $name,
+			description: $description,
+			policy: $policy,
+			variables: $variables,
+			createdBy: $createdBy,
+			createdAt: $createdAt,
+			updatedAt: $updatedAt
+		})
+		RETURN t.id as id
+		`
+		result, err := transaction.Run(query, map[string]interface{}{
+			"id":          template.ID,
+			"name":        template.Name,
+			"description": template.Description,
+			"policy":      string(policyJSON),
+			"variables":   template.Variables,
+			"createdBy":   template.CreatedBy,
+			"createdAt":   template.CreatedAt.Format(time.RFC3339),
+			"updatedAt":   template.UpdatedAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if result.Next() {
+			return result.Record().Values[0], nil
+		}
+		return nil, echo_errors.ErrInternalServer
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to create policy template",
+			zap.Error(err),
+			zap.String("templateName", template.Name),
+			zap.Duration("duration", duration))
+		return "", fmt.Errorf("failed to create policy template: %w", classifyNeo4jError(err))
+	}
+
+	templateID := fmt.Sprintf("%v", result)
+	logger.Info("Policy template created successfully",
+		zap.String("templateID", templateID),
+		zap.Duration("duration", duration))
+
+	changeDetails, _ := json.Marshal(map[string]interface{}{
+		"action":    "created",
+		"name":      template.Name,
+		"variables": template.Variables,
+	})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        template.CreatedBy,
+		Action:        "CREATE_" + echo_neo4j.LabelPolicyTemplate,
+		ResourceID:    templateID,
+		AccessGranted: true,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+	return templateID, nil
+}
+
+// GetPolicyTemplate retrieves a policy template by its ID
+func (dao *PolicyDAO) GetPolicyTemplate(ctx context.Context, templateID string) (*model.PolicyTemplate, error) {
+	start := time.Now()
+	logger.Info("Retrieving policy template", zap.String("templateID", templateID))
+
+	session := dao.Driver.NewSession(readSessionConfig(ctx))
+	defer session.Close()
+
+	query := `
+	MATCH (t:` + echo_neo4j.LabelPolicyTemplate + ` {id: $id})
+	RETURN t
+	`
+	result, err := session.Run(query, map[string]interface{}{"id": templateID})
+	if err != nil {
+		logger.Error("Failed to execute get policy template query",
+			zap.Error(err),
+			zap.String("templateID", templateID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to execute get policy template query: %w", err)
+	}
+
+	if !result.Next() {
+		logger.Warn("Policy template not found",
+			zap.String("templateID", templateID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrPolicyTemplateNotFound
+	}
+
+	template, err := mapNodeToPolicyTemplate(result.Record().Values[0].(neo4j.Node))
+	if err != nil {
+		logger.Error("Failed to map policy template node to struct",
+			zap.Error(err),
+			zap.String("templateID", templateID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, err
+	}
+
+	logger.Info("Policy template retrieved successfully",
+		zap.String("templateID", templateID),
+		zap.Duration("duration", time.Since(start)))
+	return template, nil
+}
+
 // Helper function to create change details for audit log
 func createChangeDetails(oldPolicy, newPolicy *model.Policy) json.RawMessage {
 	changes := make(map[string]interface{})
@@ -657,14 +1352,10 @@ func createChangeDetails(oldPolicy, newPolicy *model.Policy) json.RawMessage {
 	} else if newPolicy == nil {
 		changes["action"] = "deleted"
 	} else {
-		changes["action"] = "updated"
-		if oldPolicy.Name != newPolicy.Name {
-			changes["name"] = map[string]string{"old": oldPolicy.Name, "new": newPolicy.Name}
-		}
-		// Add more fields as needed
+		return changeDetails(oldPolicy, newPolicy, "updated_at")
 	}
-	changeDetails, _ := json.Marshal(changes)
-	return changeDetails
+	details, _ := json.Marshal(changes)
+	return details
 }
 
 // Helper function to map Neo4j Node to Policy struct
@@ -695,7 +1386,7 @@ func mapNodeToPolicy(node neo4j.Node) (*model.Policy, error) {
 
 	// Effect
 	if effect, ok := props["effect"].(string); ok {
-		if effect == echo_neo4j.PolicyEffectAllow || effect == echo_neo4j.PolicyEffectDeny {
+		if strings.EqualFold(effect, echo_neo4j.PolicyEffectAllow) || strings.EqualFold(effect, echo_neo4j.PolicyEffectDeny) {
 			policy.Effect = effect
 		} else {
 			return nil, fmt.Errorf("invalid policy effect: %v", effect)
@@ -739,6 +1430,10 @@ func mapNodeToPolicy(node neo4j.Node) (*model.Policy, error) {
 		return nil, fmt.Errorf("failed to assert type for policy updatedAt: %v", props["updatedAt"])
 	}
 
+	// CreatedBy and UpdatedBy, missing from policies written before they were recorded
+	policy.CreatedBy, _ = props["createdBy"].(string)
+	policy.UpdatedBy, _ = props["updatedBy"].(string)
+
 	// Active
 	if active, ok := props["active"].(bool); ok {
 		policy.Active = active
@@ -814,9 +1509,69 @@ func mapNodeToPolicy(node neo4j.Node) (*model.Policy, error) {
 		logger.Warn("Dynamic attributes not found or null", zap.Any("DynamicAttributes", props["dynamicAttributes"]))
 	}
 
+	// Obligations; policies stored before obligations existed have none
+	if obligationsJSON, ok := props["obligations"].(string); ok {
+		if err := json.Unmarshal([]byte(obligationsJSON), &policy.Obligations); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal policy obligations: %w", err)
+		}
+	}
+
+	// TemplateID
+	if templateID, ok := props["templateID"].(string); ok {
+		policy.TemplateID = templateID
+	}
+
+	// OrganizationID
+	if organizationID, ok := props["organizationID"].(string); ok {
+		policy.OrganizationID = organizationID
+	}
+
+	// Status and review; policies stored before approvals existed are active
+	policy.Status = model.PolicyStatusActive
+	if status, ok := props["status"].(string); ok && status != "" {
+		policy.Status = status
+	}
+	if submittedBy, ok := props["submittedBy"].(string); ok {
+		policy.SubmittedBy = submittedBy
+	}
+	policy.SubmittedAt = parseNullableTime(props["submittedAt"])
+	if reviewedBy, ok := props["reviewedBy"].(string); ok {
+		policy.ReviewedBy = reviewedBy
+	}
+	policy.ReviewedAt = parseNullableTime(props["reviewedAt"])
+	if reviewComment, ok := props["reviewComment"].(string); ok {
+		policy.ReviewComment = reviewComment
+	}
+
 	return policy, nil
 }
 
+// Helper function to map Neo4j Node to PolicyTemplate struct
+func mapNodeToPolicyTemplate(node neo4j.Node) (*model.PolicyTemplate, error) {
+	props := node.Props
+	template := &model.PolicyTemplate{
+		ID:          props["id"].(string),
+		Name:        props["name"].(string),
+		Description: props["description"].(string),
+		CreatedBy:   props["createdBy"].(string),
+		CreatedAt:   parseTime(props["createdAt"].(string)),
+		UpdatedAt:   parseTime(props["updatedAt"].(string)),
+	}
+
+	if policyJSON, ok := props["policy"].(string); ok {
+		if err := json.Unmarshal([]byte(policyJSON), &template.Policy); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal template policy: %w", err)
+		}
+	}
+	if variables, ok := props["variables"].([]interface{}); ok {
+		for _, variable := range variables {
+			template.Variables = append(template.Variables, variable.(string))
+		}
+	}
+
+	return template, nil
+}
+
 // Helper function to parse time
 func parseTime(s string) time.Time {
 	t, _ := time.Parse(time.RFC3339, s)
@@ -0,0 +1,584 @@
+package dao_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+func policyNode(id, name string) neo4j.Node {
+	return neo4j.Node{Props: map[string]any{
+		"id":                id,
+		"name":              name,
+		"description":       name + " policy",
+		"effect":            echo_neo4j.PolicyEffectAllow,
+		"priority":          int64(1),
+		"version":           int64(1),
+		"createdAt":         "2024-01-01T00:00:00Z",
+		"updatedAt":         "2024-01-01T00:00:00Z",
+		"active":            true,
+		"subjects":          "[]",
+		"resourceTypes":     "[]",
+		"attributeGroups":   "[]",
+		"actions":           "[]",
+		"conditions":        "[]",
+		"dynamicAttributes": "[]",
+	}}
+}
+
+func TestPolicyReadYourWrites(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	newDAO := func() (*dao.PolicyDAO, *mock.MockDriver, *mock.MockTxSession, *mock.MockSession) {
+		tx := &mock.MockTransaction{}
+		emptyResult := &mock.MockResult{}
+		emptyResult.On("Next").Return(false)
+		createResult := &mock.MockResult{}
+		createResult.On("Next").Return(true).Once()
+		createResult.On("Record").Return(&neo4j.Record{Keys: []string{"id"}, Values: []any{"p1"}})
+		tx.On("Run", queryContaining("RETURN p.id\n"), testify_mock.Anything).Return(emptyResult, nil)
+		tx.On("Run", queryContaining("CREATE (p:"+echo_neo4j.LabelPolicy), testify_mock.Anything).Return(createResult, nil)
+		tx.On("Run", queryContaining(echo_neo4j.RelAppliesTo), testify_mock.Anything).Return(emptyResult, nil)
+		writeSession := &mock.MockTxSession{Tx: tx}
+		writeSession.On("LastBookmarks").Return([]string{"bookmark:create"})
+		writeSession.On("Close").Return(nil)
+
+		readSession := &mock.MockSession{}
+		readSession.On("Run", queryContaining("RETURN p"), map[string]interface{}{"id": "p1"}, testify_mock.Anything).
+			Return(resultWithRecord(policyNode("p1", "Docs")), nil)
+		readSession.On("Close").Return(nil)
+
+		auditService := &mock.MockAuditService{}
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite}).Return(writeSession)
+		driver.On("NewSession", testify_mock.Anything).Return(readSession)
+		return &dao.PolicyDAO{Driver: driver, AuditService: auditService}, driver, writeSession, readSession
+	}
+
+	policy := model.Policy{ID: "p1", Name: "Docs", Effect: echo_neo4j.PolicyEffectAllow}
+
+	t.Run("Read after create carries the create's bookmarks", func(t *testing.T) {
+		policyDAO, driver, _, _ := newDAO()
+		ctx := dao.WithReadYourWrites(context.Background())
+
+		policyID, err := policyDAO.CreatePolicy(ctx, policy, "admin")
+		assert.NoError(t, err)
+		fetched, err := policyDAO.GetPolicy(ctx, policyID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "p1", fetched.ID)
+		assert.Equal(t, neo4j.Bookmarks{"bookmark:create"}, dao.BookmarksFromContext(ctx))
+		driver.AssertCalled(t, "NewSession", neo4j.SessionConfig{
+			AccessMode: neo4j.AccessModeRead,
+			Bookmarks:  neo4j.Bookmarks{"bookmark:create"},
+		})
+	})
+
+	t.Run("Plain contexts read without bookmarks", func(t *testing.T) {
+		policyDAO, driver, writeSession, _ := newDAO()
+		ctx := context.Background()
+
+		_, err := policyDAO.CreatePolicy(ctx, policy, "admin")
+		assert.NoError(t, err)
+		_, err = policyDAO.GetPolicy(ctx, "p1")
+
+		assert.NoError(t, err)
+		writeSession.AssertNotCalled(t, "LastBookmarks")
+		driver.AssertCalled(t, "NewSession", neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	})
+}
+
+func TestPolicyDryRun(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	newDAO := func() (*dao.PolicyDAO, *mock.MockTxSession, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		emptyResult := &mock.MockResult{}
+		emptyResult.On("Next").Return(false)
+		createResult := &mock.MockResult{}
+		createResult.On("Next").Return(true).Once()
+		createResult.On("Record").Return(&neo4j.Record{Keys: []string{"id"}, Values: []any{"p1"}})
+		tx.On("Run", queryContaining("RETURN p.id\n"), testify_mock.Anything).Return(emptyResult, nil)
+		tx.On("Run", queryContaining("CREATE (p:"+echo_neo4j.LabelPolicy), testify_mock.Anything).Return(createResult, nil)
+		tx.On("Run", queryContaining(echo_neo4j.RelAppliesTo), testify_mock.Anything).Return(emptyResult, nil)
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+
+		auditService := &mock.MockAuditService{}
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return &dao.PolicyDAO{Driver: driver, AuditService: auditService}, session, auditService
+	}
+
+	policy := model.Policy{ID: "p1", Name: "Docs", Effect: echo_neo4j.PolicyEffectAllow}
+
+	t.Run("Dry run rolls the create back", func(t *testing.T) {
+		policyDAO, session, auditService := newDAO()
+
+		policyID, err := policyDAO.CreatePolicy(helper_util.WithDryRun(context.Background()), policy, "admin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "p1", policyID)
+		assert.Equal(t, 0, session.Commits())
+		assert.Equal(t, 1, session.Rollbacks())
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("Normal create commits", func(t *testing.T) {
+		policyDAO, session, auditService := newDAO()
+
+		policyID, err := policyDAO.CreatePolicy(context.Background(), policy, "admin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "p1", policyID)
+		assert.Equal(t, 1, session.Commits())
+		assert.Equal(t, 0, session.Rollbacks())
+		auditService.AssertCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+}
+
+func TestResolveUserSubjects(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	timestamps := map[string]any{"createdAt": "2024-01-01T00:00:00Z", "updatedAt": "2024-01-01T00:00:00Z"}
+	node := func(props map[string]any) neo4j.Node {
+		for key, value := range timestamps {
+			props[key] = value
+		}
+		return neo4j.Node{Props: props}
+	}
+	user := node(map[string]any{
+		"id": "u1", "name": "Jane Doe", "username": "jdoe", "email": "jdoe@example.com", "userType": "DepartmentUser",
+		"organizationID": "org1", "departmentID": "dept1", "roleIds": []interface{}{"editor", "auditor"},
+		"groupIds": []interface{}{"g1"}, "attributes": "{}",
+	})
+	org := node(map[string]any{"id": "org1", "name": "Acme"})
+	dept := node(map[string]any{"id": "dept1", "name": "Finance", "organizationID": "org1"})
+	role := func(id string) neo4j.Node {
+		return node(map[string]any{"id": id, "name": id, "description": "", "organizationID": "org1"})
+	}
+	group := node(map[string]any{"id": "g1", "name": "Reviewers", "description": "", "organizationID": "org1"})
+
+	// Only u1 exists; the query returns nothing for the deleted user u2
+	result := &mock.MockResult{}
+	result.On("Next").Return(true).Once()
+	result.On("Record").Return(&neo4j.Record{Values: []any{
+		user, org, dept, []interface{}{role("editor"), role("auditor")}, []interface{}{group},
+	}}).Once()
+	result.On("Next").Return(false)
+	session := &mock.MockSession{}
+	session.On("Close").Return(nil)
+	session.On("Run", queryContaining("UNWIND $userIDs AS userID"), map[string]interface{}{"userIDs": []string{"u1", "u2"}}, testify_mock.Anything).
+		Return(result, nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	subjects := []model.Subject{
+		{Type: "user", UserID: "u1"},
+		{Type: "role", Attributes: map[string]string{"role": "editor"}},
+		{Type: "user", UserID: "u2"},
+	}
+	resolved, err := policyDAO.ResolveUserSubjects(ctx, subjects)
+
+	assert.NoError(t, err)
+	if assert.Len(t, resolved, 1) {
+		assert.Equal(t, subjects[0], resolved[0].Subject)
+		assert.Equal(t, "u1", resolved[0].User.ID)
+		assert.Equal(t, "org1", resolved[0].Organization.ID)
+		assert.Equal(t, "dept1", resolved[0].Department.ID)
+		if assert.Len(t, resolved[0].Roles, 2) {
+			assert.Equal(t, "editor", resolved[0].Roles[0].ID)
+			assert.Equal(t, "auditor", resolved[0].Roles[1].ID)
+		}
+		if assert.Len(t, resolved[0].Groups, 1) {
+			assert.Equal(t, "Reviewers", resolved[0].Groups[0].Name)
+		}
+	}
+
+	t.Run("NoUserSubjects", func(t *testing.T) {
+		resolved, err := policyDAO.ResolveUserSubjects(ctx, subjects[1:2])
+
+		assert.NoError(t, err)
+		assert.Empty(t, resolved)
+		session.AssertNumberOfCalls(t, "Run", 1)
+	})
+}
+
+func TestAnalyzePolicyUsageCountsLinkedNodes(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	// The transaction stands in for the graph: it records what the link statements create so the
+	// analysis read can count it back
+	resourceTypes := map[string]bool{"rt1": true, "rt2": true}
+	linked := map[string]int{}
+	tx := &mock.MockTransaction{}
+	emptyResult := &mock.MockResult{}
+	emptyResult.On("Next").Return(false)
+	createResult := &mock.MockResult{}
+	createResult.On("Next").Return(true).Once()
+	createResult.On("Record").Return(&neo4j.Record{Keys: []string{"id"}, Values: []any{"p1"}})
+	tx.On("Run", queryContaining("RETURN p.id\n"), testify_mock.Anything).Return(emptyResult, nil)
+	tx.On("Run", queryContaining("CREATE (p:"+echo_neo4j.LabelPolicy), testify_mock.Anything).Return(createResult, nil)
+	tx.On("Run", testify_mock.Anything, testify_mock.Anything).Run(func(args testify_mock.Arguments) {
+		query, params := args.String(0), args.Get(1).(map[string]interface{})
+		switch {
+		case strings.Contains(query, ":"+echo_neo4j.LabelResourceType+" "):
+			for _, id := range params["ids"].([]string) {
+				if resourceTypes[id] {
+					linked["resources"]++
+				}
+			}
+		case strings.Contains(query, ":"+echo_neo4j.LabelPolicySubject+" "):
+			linked["subjects"] += len(params["subjects"].([]map[string]interface{}))
+		case strings.Contains(query, ":"+echo_neo4j.LabelCondition+" "):
+			linked["conditions"] += len(params["conditions"].([]map[string]interface{}))
+		}
+	}).Return(emptyResult, nil)
+	writeSession := &mock.MockTxSession{Tx: tx}
+	writeSession.On("Close").Return(nil)
+
+	readSession := &mock.MockSession{}
+	readSession.On("Close").Return(nil)
+
+	auditService := &mock.MockAuditService{}
+	auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite}).Return(writeSession)
+	driver.On("NewSession", testify_mock.Anything).Return(readSession)
+	policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: auditService}
+
+	ctx := context.Background()
+	_, err := policyDAO.CreatePolicy(ctx, model.Policy{
+		ID:            "p1",
+		Name:          "Docs",
+		Effect:        echo_neo4j.PolicyEffectAllow,
+		Subjects:      []model.Subject{{Type: "role", Attributes: map[string]string{"id": "editor"}}},
+		ResourceTypes: []string{"rt1", "rt2"},
+		Conditions:    []model.Condition{{Attribute: "department", Operator: "equals", Value: "sales"}},
+	}, "admin")
+	assert.NoError(t, err)
+
+	readSession.On("Run", queryContaining("AS resourceCount"), map[string]interface{}{"policyID": "p1"}, testify_mock.Anything).
+		Return(resultWithRecord("p1", "Docs", int64(linked["resources"]), int64(linked["subjects"]),
+			int64(linked["conditions"]), "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z"), nil)
+	analysis, err := policyDAO.AnalyzePolicyUsage(ctx, "p1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, analysis.ResourceCount)
+	assert.Equal(t, 1, analysis.SubjectCount)
+	assert.Equal(t, 1, analysis.ConditionCount)
+}
+
+func TestGetPoliciesForResource(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	serve := func(policies ...neo4j.Node) *mock.MockResult {
+		result := &mock.MockResult{}
+		for _, policy := range policies {
+			result.On("Next").Return(true).Once()
+			result.On("Record").Return(&neo4j.Record{Values: []any{policy}}).Once()
+		}
+		result.On("Next").Return(false)
+		return result
+	}
+
+	// Policies reach a resource through the resource type or attribute group it is linked to, and
+	// through those of the parents it inherits from
+	traversal := testify_mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, echo_neo4j.RelChildOf+"*0..") &&
+			strings.Contains(query, "child.inheritedACL = true") &&
+			strings.Contains(query, echo_neo4j.RelHasType+"|"+echo_neo4j.RelInGroup) &&
+			strings.Contains(query, "(p:"+echo_neo4j.LabelPolicy+")-[:"+echo_neo4j.RelAppliesTo+"]->(target)")
+	})
+	session := &mock.MockSession{}
+	session.On("Run", traversal, map[string]interface{}{"resourceID": "r1"}, testify_mock.Anything).
+		Return(serve(policyNode("p2", "Reports"), policyNode("p1", "Docs")), nil)
+	session.On("Run", traversal, map[string]interface{}{"resourceID": "r1-child"}, testify_mock.Anything).
+		Return(serve(policyNode("p2", "Reports")), nil)
+	session.On("Run", traversal, map[string]interface{}{"resourceID": "r2"}, testify_mock.Anything).
+		Return(serve(), nil)
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	t.Run("Via type", func(t *testing.T) {
+		policies, err := policyDAO.GetPoliciesForResource(ctx, "r1")
+
+		assert.NoError(t, err)
+		if assert.Len(t, policies, 2) {
+			assert.Equal(t, "p2", policies[0].ID)
+			assert.Equal(t, "p1", policies[1].ID)
+		}
+	})
+
+	t.Run("Via parent", func(t *testing.T) {
+		policies, err := policyDAO.GetPoliciesForResource(ctx, "r1-child")
+
+		assert.NoError(t, err)
+		if assert.Len(t, policies, 1) {
+			assert.Equal(t, "p2", policies[0].ID)
+		}
+	})
+
+	t.Run("Unlinked resource", func(t *testing.T) {
+		policies, err := policyDAO.GetPoliciesForResource(ctx, "r2")
+
+		assert.NoError(t, err)
+		assert.Empty(t, policies)
+	})
+}
+
+func TestRelinkPolicies(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	stored := policyNode("p1", "Docs")
+	stored.Props["subjects"] = `[{"type":"group","attributes":{"id":"g1"}}]`
+	stored.Props["resourceTypes"] = `["rt1","rt2"]`
+	stored.Props["conditions"] = `[{"attribute":"department","operator":"equals","value":"sales"}]`
+	policies := &mock.MockResult{}
+	policies.On("Collect").Return([]*neo4j.Record{{Values: []any{stored}}}, nil)
+	emptyResult := &mock.MockResult{}
+
+	var statements []string
+	params := map[string]map[string]interface{}{}
+	tx := &mock.MockTransaction{}
+	tx.On("Run", queryContaining("RETURN p\n"), testify_mock.Anything).Return(policies, nil)
+	tx.On("Run", testify_mock.Anything, testify_mock.Anything).Run(func(args testify_mock.Arguments) {
+		query := args.String(0)
+		if strings.Contains(query, "DETACH DELETE") {
+			statements = append(statements, "unlink")
+			return
+		}
+		for _, label := range []string{echo_neo4j.LabelResourceType, echo_neo4j.LabelPolicySubject, echo_neo4j.LabelCondition} {
+			if strings.Contains(query, ":"+label+" ") {
+				statements = append(statements, label)
+				params[label] = args.Get(1).(map[string]interface{})
+			}
+		}
+	}).Return(emptyResult, nil)
+
+	err := dao.RelinkPolicies(tx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"unlink", echo_neo4j.LabelResourceType, echo_neo4j.LabelPolicySubject, echo_neo4j.LabelCondition}, statements)
+	assert.Equal(t, []string{"rt1", "rt2"}, params[echo_neo4j.LabelResourceType]["ids"])
+	subjects := params[echo_neo4j.LabelPolicySubject]["subjects"].([]map[string]interface{})
+	if assert.Len(t, subjects, 1) {
+		assert.Equal(t, "group", subjects[0]["type"])
+		assert.Equal(t, "g1", subjects[0]["refID"])
+	}
+	assert.Len(t, params[echo_neo4j.LabelCondition]["conditions"], 1)
+}
+
+func TestGetPoliciesForSubject(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	// u1 is not named by any policy, only its group g1 is
+	groupPolicy := policyNode("p1", "Team docs")
+	groupPolicy.Props["subjects"] = `[{"type":"group","attributes":{"id":"g1"}}]`
+	matched := &mock.MockResult{}
+	matched.On("Next").Return(true).Once()
+	matched.On("Next").Return(false)
+	matched.On("Record").Return(&neo4j.Record{Values: []any{groupPolicy}})
+
+	viaGroups := testify_mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "(u)-[:"+echo_neo4j.RelBelongsToGroup+"]->(g:"+echo_neo4j.LabelGroup+") | g.id] AS groupIDs") &&
+			strings.Contains(query, "s.refID IN groupIDs") &&
+			strings.Contains(query, "p.active = true")
+	})
+	inWindow := testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+		now, err := time.Parse(time.RFC3339, params["now"].(string))
+		return params["userID"] == "u1" && err == nil && time.Since(now) < time.Minute
+	})
+	session := &mock.MockSession{}
+	session.On("Run", viaGroups, inWindow, testify_mock.Anything).Return(matched, nil)
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	policies, err := policyDAO.GetPoliciesForSubject(context.Background(), "u1")
+
+	assert.NoError(t, err)
+	if assert.Len(t, policies, 1) {
+		assert.Equal(t, "p1", policies[0].ID)
+		assert.Equal(t, []model.Subject{{Type: "group", Attributes: map[string]string{"id": "g1"}}}, policies[0].Subjects)
+	}
+}
+
+func TestUpdatePolicyChangeDetails(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	stored := policyNode("p1", "Docs")
+	stored.Props["subjects"] = `[{"type":"role","attributes":{"id":"editor"}}]`
+	stored.Props["actions"] = `["read","write"]`
+	updated := policyNode("p1", "Docs")
+	updated.Props["effect"] = echo_neo4j.PolicyEffectDeny
+	updated.Props["subjects"] = `[{"type":"role","attributes":{"id":"editor"}},{"type":"group","attributes":{"id":"g1"}}]`
+	updated.Props["actions"] = `["read","write"]`
+	updated.Props["updatedAt"] = "2024-02-01T00:00:00Z"
+
+	readSession := &mock.MockSession{}
+	readSession.On("Run", queryContaining("RETURN p"), map[string]interface{}{"id": "p1"}, testify_mock.Anything).
+		Return(resultWithRecord(stored), nil)
+	readSession.On("Close").Return(nil)
+	tx := &mock.MockTransaction{}
+	tx.On("Run", queryContaining("SET p.name"), testify_mock.Anything).Return(resultWithRecord(updated), nil)
+	tx.On("Run", testify_mock.Anything, testify_mock.Anything).Return(&mock.MockResult{}, nil)
+	writeSession := &mock.MockTxSession{Tx: tx}
+	writeSession.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite}).Return(writeSession)
+	driver.On("NewSession", testify_mock.Anything).Return(readSession)
+
+	var logged audit.AuditLog
+	auditService := &mock.MockAuditService{}
+	auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) { logged = args.Get(1).(audit.AuditLog) }).
+		Return(nil)
+	policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: auditService}
+
+	_, err := policyDAO.UpdatePolicy(context.Background(), model.Policy{ID: "p1", Name: "Docs"}, "admin")
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"action": "updated",
+		"effect": {"old": "ALLOW", "new": "DENY"},
+		"subjects": {"added": [{"type": "group", "attributes": {"id": "g1"}}]}
+	}`, string(logged.ChangeDetails))
+}
+
+func TestPolicyDAOAgainstFakeDriver(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	getQuery := "MATCH (p:" + echo_neo4j.LabelPolicy + " {id: $id})"
+	deleteQuery := "DETACH DELETE p"
+
+	t.Run("GetPolicy maps the node", func(t *testing.T) {
+		node := policyNode("p1", "Editors")
+		node.Props["subjects"] = `[{"type":"role","attributes":{"id":"editor"}}]`
+		node.Props["actions"] = `["read","write"]`
+		node.Props["priority"] = int64(20)
+		driver := mock.NewFakeDriver().Returns(getQuery, &neo4j.Record{Keys: []string{"p"}, Values: []any{node}})
+		policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		policy, err := policyDAO.GetPolicy(ctx, "p1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Editors", policy.Name)
+		assert.Equal(t, 20, policy.Priority)
+		assert.Equal(t, []string{"read", "write"}, policy.Actions)
+		if assert.Len(t, policy.Subjects, 1) {
+			assert.Equal(t, "editor", policy.Subjects[0].Attributes["id"])
+		}
+		queries := driver.QueriesContaining(getQuery)
+		if assert.Len(t, queries, 1) {
+			assert.Equal(t, map[string]any{"id": "p1"}, queries[0].Params)
+		}
+	})
+
+	t.Run("GetPolicy reports a missing policy", func(t *testing.T) {
+		policyDAO := &dao.PolicyDAO{Driver: mock.NewFakeDriver(), AuditService: &mock.MockAuditService{}}
+
+		_, err := policyDAO.GetPolicy(ctx, "missing")
+
+		assert.ErrorIs(t, err, echo_errors.ErrPolicyNotFound)
+	})
+
+	t.Run("GetPolicy reports a node that does not map", func(t *testing.T) {
+		node := policyNode("p1", "Editors")
+		node.Props["conditions"] = "{not json"
+		driver := mock.NewFakeDriver().Returns(getQuery, &neo4j.Record{Keys: []string{"p"}, Values: []any{node}})
+		policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		_, err := policyDAO.GetPolicy(ctx, "p1")
+
+		assert.ErrorContains(t, err, "failed to map policy node")
+	})
+
+	t.Run("GetPolicy passes a failing query's error on", func(t *testing.T) {
+		failure := errors.New("connection reset")
+		policyDAO := &dao.PolicyDAO{Driver: mock.NewFakeDriver().Fails(getQuery, failure), AuditService: &mock.MockAuditService{}}
+
+		_, err := policyDAO.GetPolicy(ctx, "p1")
+
+		assert.ErrorIs(t, err, failure)
+	})
+
+	t.Run("DeletePolicy unlinks the policy before deleting it", func(t *testing.T) {
+		driver := mock.NewFakeDriver().On(deleteQuery, mock.FakeResponse{NodesDeleted: 1})
+		auditService := &mock.MockAuditService{}
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "DELETE_POLICY" && log.UserID == "admin"
+		})).Return(nil).Once()
+		policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: auditService}
+
+		err := policyDAO.DeletePolicy(ctx, "p1", "admin")
+
+		assert.NoError(t, err)
+		queries := driver.Queries()
+		if assert.Len(t, queries, 2) {
+			assert.Contains(t, queries[0].Cypher, "DETACH DELETE owned")
+			assert.Equal(t, map[string]any{"policyID": "p1"}, queries[0].Params)
+			assert.Contains(t, queries[1].Cypher, deleteQuery)
+		}
+		assert.Equal(t, 1, driver.Commits())
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("DeletePolicy reports a missing policy", func(t *testing.T) {
+		driver := mock.NewFakeDriver()
+		policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		err := policyDAO.DeletePolicy(ctx, "missing", "admin")
+
+		assert.ErrorIs(t, err, echo_errors.ErrPolicyNotFound)
+		assert.Equal(t, 1, driver.Rollbacks())
+	})
+
+	t.Run("DeletePolicy classifies a constraint failure", func(t *testing.T) {
+		driver := mock.NewFakeDriver().Fails("DETACH DELETE owned", &neo4j.Neo4jError{
+			Code: "Neo.ClientError.Schema.ConstraintValidationFailed",
+			Msg:  "Node(3) still has relationships",
+		})
+		policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		err := policyDAO.DeletePolicy(ctx, "p1", "admin")
+
+		assert.ErrorIs(t, err, echo_errors.ErrConstraintViolation)
+		assert.Len(t, driver.QueriesContaining(deleteQuery), 0)
+	})
+}
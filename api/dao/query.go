@@ -0,0 +1,142 @@
+// api/dao/query.go
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.uber.org/zap"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// DefaultQueryTimeout bounds how long the server lets a heavy read query run
+const DefaultQueryTimeout = 30 * time.Second
+
+var queryTimeout = DefaultQueryTimeout
+
+// SetQueryTimeout sets how long the server lets a heavy read query run. Non-positive values keep the
+// current timeout.
+func SetQueryTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		queryTimeout = timeout
+	}
+}
+
+// readRecords runs a read query and collects its records while honoring ctx. The query runs with a
+// server-side timeout of the configured query timeout or the time left until the ctx deadline,
+// whichever is sooner, in a session that observes the writes recorded on ctx by WithReadYourWrites.
+// On a ContextDriver the query runs in a session honoring ctx, so once ctx is done the driver stops
+// it and readRecords returns ctx.Err(). Other drivers take no context: the query runs in the
+// background and readRecords returns ctx.Err() as soon as ctx is done, leaving the server to abort the
+// query when its timeout expires.
+func readRecords(ctx context.Context, driver Neo4jDriver, query string, params map[string]interface{}) ([]*neo4j.Record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	timeout := queryTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	if contextDriver, ok := driver.(ContextDriver); ok {
+		if session, ok := contextDriver.NewContextSession(ctx, readSessionConfig(ctx)); ok {
+			return readRecordsWithContext(ctx, session, query, params, timeout)
+		}
+	}
+
+	type outcome struct {
+		records []*neo4j.Record
+		err     error
+	}
+	done := make(chan outcome, 1)
+
+	go func() {
+		session := driver.NewSession(readSessionConfig(ctx))
+		defer session.Close()
+
+		result, err := session.Run(query, params, neo4j.WithTxTimeout(timeout))
+		if err != nil {
+			done <- outcome{err: err}
+			return
+		}
+
+		var records []*neo4j.Record
+		for result.Next() {
+			records = append(records, result.Record())
+		}
+		// A stream cut off by the timeout or a server failure ends like a complete one
+		if err := result.Err(); err != nil {
+			done <- outcome{err: err}
+			return
+		}
+		done <- outcome{records: records}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case out := <-done:
+		return out.records, out.err
+	}
+}
+
+// readRecordsWithContext runs the query of readRecords in a session honoring ctx and closes it
+func readRecordsWithContext(ctx context.Context, session neo4j.SessionWithContext, query string, params map[string]interface{}, timeout time.Duration) ([]*neo4j.Record, error) {
+	// The session is closed even when ctx is done, to hand its connection back
+	defer session.Close(context.Background())
+
+	result, err := session.Run(ctx, query, params, neo4j.WithTxTimeout(timeout))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+
+	var records []*neo4j.Record
+	for result.Next(ctx) {
+		records = append(records, result.Record())
+	}
+	// A stream stopped because ctx is done ends like a complete one, as does one cut off by the timeout
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// readFailure returns the error a DAO reports when readRecords fails: a done context's error passes
+// through so callers can tell an abandoned request from a database failure
+func readFailure(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return echo_errors.ErrDatabaseOperation
+}
+
+// nodeExists reports whether a node with the given label and ID exists, without reading or mapping it
+func nodeExists(ctx context.Context, driver Neo4jDriver, label string, id string) (bool, error) {
+	query := `
+		MATCH (n:` + label + ` {id: $id})
+		RETURN count(n) > 0 AS found
+	`
+	records, err := readRecords(ctx, driver, query, map[string]interface{}{"id": id})
+	if err != nil {
+		logger.Error("Failed to check node existence", zap.Error(err), zap.String("label", label), zap.String("id", id))
+		return false, readFailure(ctx)
+	}
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	found, _ := records[0].Values[0].(bool)
+	return found, nil
+}
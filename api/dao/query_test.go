@@ -0,0 +1,209 @@
+package dao_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestHeavyReadsHonorContext(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	t.Run("CancelledMidQueryReturnsPromptly", func(t *testing.T) {
+		// The query only completes once released, long after the caller has given up
+		release := make(chan time.Time)
+		defer close(release)
+		result := &mock.MockResult{}
+		result.On("Next").Return(false)
+		session := &mock.MockSession{}
+		session.On("Close").Return(nil)
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).WaitUntil(release).Return(result, nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		start := time.Now()
+		resources, err := resourceDAO.SearchResources(ctx, model.ResourceSearchCriteria{Name: "report", Limit: 10})
+
+		assert.Nil(t, resources)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("AlreadyCancelledSkipsQuery", func(t *testing.T) {
+		session := &mock.MockSession{}
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		userDAO := &dao.UserDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		users, err := userDAO.SearchUsers(ctx, model.UserSearchCriteria{Limit: 10})
+
+		assert.Nil(t, users)
+		assert.ErrorIs(t, err, context.Canceled)
+		driver.AssertNotCalled(t, "NewSession", testify_mock.Anything)
+	})
+
+	t.Run("StreamCutOffMidwayFails", func(t *testing.T) {
+		// The first record arrives before the server gives up on the query
+		timedOut := errors.New("transaction timed out")
+		driver := mock.NewFakeDriver().On("", mock.FakeResponse{
+			Records:   []*neo4j.Record{{Values: []any{resourceNode("r1", "2024-01-01T00:00:00Z")}}},
+			StreamErr: timedOut,
+		})
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		resources, err := resourceDAO.SearchResources(context.Background(), model.ResourceSearchCriteria{Name: "report", Limit: 10})
+
+		assert.Nil(t, resources)
+		assert.ErrorIs(t, err, timedOut)
+	})
+
+	t.Run("DeadlineShortensServerTimeout", func(t *testing.T) {
+		result := &mock.MockResult{}
+		result.On("Next").Return(false)
+		session := &mock.MockSession{}
+		session.On("Close").Return(nil)
+		var timeout time.Duration
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).
+			Run(func(args testify_mock.Arguments) {
+				config := &neo4j.TransactionConfig{}
+				for _, configure := range args.Get(2).([]func(*neo4j.TransactionConfig)) {
+					configure(config)
+				}
+				timeout = config.Timeout
+			}).
+			Return(result, nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		groupDAO := &dao.GroupDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		_, err := groupDAO.GetEffectiveGroupMembers(ctx, "g1")
+
+		assert.NoError(t, err)
+		assert.Greater(t, timeout, time.Duration(0))
+		assert.LessOrEqual(t, timeout, 2*time.Second)
+	})
+
+	t.Run("ContextDriverStopsQueryOnceCancelled", func(t *testing.T) {
+		// The driver gives the query up once its context is done, breaking off the connection
+		session := &mock.MockContextSession{}
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).
+			Run(func(args testify_mock.Arguments) {
+				<-args.Get(0).(context.Context).Done()
+			}).
+			Return(nil, errors.New("connection interrupted"))
+		session.On("Close", testify_mock.Anything).Return(nil)
+		driver := &mock.MockContextDriver{}
+		driver.On("NewContextSession", testify_mock.Anything, testify_mock.Anything).Return(session, true)
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		resources, err := resourceDAO.SearchResources(ctx, model.ResourceSearchCriteria{Name: "report", Limit: 10})
+
+		assert.Nil(t, resources)
+		assert.ErrorIs(t, err, context.Canceled)
+		session.AssertCalled(t, "Close", testify_mock.Anything)
+		driver.AssertNotCalled(t, "NewSession", testify_mock.Anything)
+	})
+
+	t.Run("ContextDriverCollectsRecords", func(t *testing.T) {
+		result := &mock.MockContextResult{}
+		result.On("Next", testify_mock.Anything).Return(true).Once()
+		result.On("Next", testify_mock.Anything).Return(false)
+		result.On("Record").Return(&neo4j.Record{Values: []any{resourceNode("r1", "2024-01-01T00:00:00Z")}})
+		result.On("Err").Return(nil)
+		session := &mock.MockContextSession{}
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+		session.On("Close", testify_mock.Anything).Return(nil)
+		driver := &mock.MockContextDriver{}
+		driver.On("NewContextSession", testify_mock.Anything, testify_mock.Anything).Return(session, true)
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		resources, err := resourceDAO.SearchResources(context.Background(), model.ResourceSearchCriteria{Name: "report", Limit: 10})
+
+		assert.NoError(t, err)
+		if assert.Len(t, resources, 1) {
+			assert.Equal(t, "r1", resources[0].ID)
+		}
+		session.AssertCalled(t, "Close", testify_mock.Anything)
+	})
+}
+
+func TestExists(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	newDriver := func() (*mock.MockDriver, *mock.MockSession) {
+		session := &mock.MockSession{}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return driver, session
+	}
+	countResult := func(found bool) *mock.MockResult {
+		result := resultWithRecord(found)
+		result.On("Next").Return(false)
+		return result
+	}
+
+	t.Run("PresentID", func(t *testing.T) {
+		driver, session := newDriver()
+		session.On("Run", queryContaining("count(n) > 0"), map[string]interface{}{"id": "r1"}, testify_mock.Anything).
+			Return(countResult(true), nil)
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		exists, err := resourceDAO.Exists(context.Background(), "r1")
+
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		session.AssertCalled(t, "Run", queryContaining("(n:RESOURCE {id: $id})"), testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("AbsentID", func(t *testing.T) {
+		driver, session := newDriver()
+		session.On("Run", queryContaining("count(n) > 0"), map[string]interface{}{"id": "missing"}, testify_mock.Anything).
+			Return(countResult(false), nil)
+		policyDAO := &dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		exists, err := policyDAO.Exists(context.Background(), "missing")
+
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		session.AssertCalled(t, "Run", queryContaining("(n:POLICY {id: $id})"), testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("QueryFailure", func(t *testing.T) {
+		driver, session := newDriver()
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).
+			Return(&mock.MockResult{}, errors.New("connection reset"))
+		userDAO := &dao.UserDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		exists, err := userDAO.Exists(context.Background(), "u1")
+
+		assert.False(t, exists)
+		assert.ErrorIs(t, err, echo_errors.ErrDatabaseOperation)
+	})
+}
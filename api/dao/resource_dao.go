@@ -5,6 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,11 +24,11 @@ import (
 )
 
 type ResourceDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewResourceDAO(driver neo4j.Driver, auditService audit.Service) *ResourceDAO {
+func NewResourceDAO(driver Neo4jDriver, auditService audit.Service) *ResourceDAO {
 	dao := &ResourceDAO{Driver: driver, AuditService: auditService}
 	// Ensure unique constraint on Resource ID
 	ctx := context.Background()
@@ -67,44 +70,52 @@ func (dao *ResourceDAO) CreateResource(ctx context.Context, resource model.Resou
 	if resource.ID == "" {
 		resource.ID = uuid.New().String()
 	}
+	resource.CreatedBy = changedBy(ctx, "")
+	resource.UpdatedBy = resource.CreatedBy
+
+	result, err := writeTransaction(ctx, session, func(transaction neo4j.Transaction) (interface{}, error) {
+		// The create query MATCHes every referenced node, so verify they exist first;
+		// otherwise the resource would be created with its relationships silently missing
+		if err := verifyResourceReferences(transaction, resource); err != nil {
+			return nil, err
+		}
 
-	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
-            CREATE (r:RESOURCE {id: $id})
-            SET r += $props
+            CREATE (r:` + echo_neo4j.LabelResource + ` {id: $id})
+            SET r += $props` + snapshotResourceVersion + `
             WITH r
-            MATCH (o:ORGANIZATION {id: $organizationID})
-            CREATE (r)-[:BELONGS_TO]->(o)
+            MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $organizationID})
+            CREATE (r)-[:` + echo_neo4j.RelBelongsTo + `]->(o)
             WITH r
-            OPTIONAL MATCH (d:DEPARTMENT {id: $departmentID})
+            OPTIONAL MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $departmentID})
             FOREACH (_ IN CASE WHEN d IS NOT NULL THEN [1] ELSE [] END |
-                CREATE (r)-[:ASSIGNED_TO]->(d)
+                CREATE (r)-[:` + echo_neo4j.RelAssignedTo + `]->(d)
             )
             WITH r
-            MATCH (u:USER {id: $ownerID})
-            CREATE (r)-[:OWNED_BY]->(u)
+            MATCH (u:` + echo_neo4j.LabelUser + ` {id: $ownerID})
+            CREATE (r)-[:` + echo_neo4j.RelOwnedBy + `]->(u)
             WITH r
-            MATCH (rt:RESOURCE_TYPE {id: $typeID})
-            CREATE (r)-[:HAS_TYPE]->(rt)
+            MATCH (rt:` + echo_neo4j.LabelResourceType + ` {id: $typeID})
+            CREATE (r)-[:` + echo_neo4j.RelHasType + `]->(rt)
             WITH r
-            MATCH (ag:ATTRIBUTE_GROUP {id: $attributeGroupID})
-            CREATE (r)-[:IN_GROUP]->(ag)
+            MATCH (ag:` + echo_neo4j.LabelAttributeGroup + ` {id: $attributeGroupID})
+            CREATE (r)-[:` + echo_neo4j.RelInGroup + `]->(ag)
         `
 
 		// Add relationships for parent and related resources
 		if resource.ParentID != "" {
 			query += `
                 WITH r
-                MATCH (p:RESOURCE {id: $parentID})
-                CREATE (r)-[:CHILD_OF]->(p)
+                MATCH (p:` + echo_neo4j.LabelResource + ` {id: $parentID})
+                CREATE (r)-[:` + echo_neo4j.RelChildOf + `]->(p)
             `
 		}
 		if len(resource.RelatedIDs) > 0 {
 			query += `
                 WITH r
                 UNWIND $relatedIDs AS relatedID
-                MATCH (related:RESOURCE {id: relatedID})
-                CREATE (r)-[:RELATED_TO]->(related)
+                MATCH (related:` + echo_neo4j.LabelResource + ` {id: relatedID})
+                CREATE (r)-[:` + echo_neo4j.RelRelatedTo + `]->(related)
             `
 		}
 
@@ -135,7 +146,7 @@ func (dao *ResourceDAO) CreateResource(ctx context.Context, resource model.Resou
 				"departmentID":     resource.DepartmentID,
 				"ownerID":          resource.OwnerID,
 				"status":           resource.Status,
-				"version":          resource.Version,
+				"version":          1,
 				"tags":             resource.Tags,
 				"metadata":         string(metadataJSON),
 				"attributeGroupID": resource.AttributeGroupID,
@@ -194,10 +205,15 @@ func (dao *ResourceDAO) CreateResource(ctx context.Context, resource model.Resou
 			zap.Error(err),
 			zap.String("name", resource.Name),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrResourceConflict, resource.ID)
 	}
 
 	resourceID := fmt.Sprintf("%v", result)
+	if helper_util.IsDryRun(ctx) {
+		logger.Info("Dry run: resource creation rolled back", zap.String("resourceID", resourceID))
+		return resourceID, nil
+	}
+
 	logger.Info("Resource created successfully",
 		zap.String("resourceID", resourceID),
 		zap.Duration("duration", duration))
@@ -205,7 +221,7 @@ func (dao *ResourceDAO) CreateResource(ctx context.Context, resource model.Resou
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        resource.CreatedBy,
 		Action:        "CREATE_RESOURCE",
 		ResourceID:    resourceID,
 		AccessGranted: true,
@@ -218,6 +234,9 @@ func (dao *ResourceDAO) CreateResource(ctx context.Context, resource model.Resou
 	return resourceID, nil
 }
 
+// resourceDiffIgnored are the resource fields every write changes, left out of diffs
+var resourceDiffIgnored = []string{"updated_at", "version"}
+
 // Helper function to create change details for audit log
 func createResourceChangeDetails(oldResource, newResource *model.Resource) json.RawMessage {
 	changes := make(map[string]interface{})
@@ -226,17 +245,10 @@ func createResourceChangeDetails(oldResource, newResource *model.Resource) json.
 	} else if newResource == nil {
 		changes["action"] = "deleted"
 	} else {
-		changes["action"] = "updated"
-		if oldResource.Name != newResource.Name {
-			changes["name"] = map[string]string{"old": oldResource.Name, "new": newResource.Name}
-		}
-		if oldResource.Description != newResource.Description {
-			changes["description"] = map[string]string{"old": oldResource.Description, "new": newResource.Description}
-		}
-		// Add more fields as needed
+		return changeDetails(oldResource, newResource, resourceDiffIgnored...)
 	}
-	changeDetails, _ := json.Marshal(changes)
-	return changeDetails
+	details, _ := json.Marshal(changes)
+	return details
 }
 
 func (dao *ResourceDAO) UpdateResource(ctx context.Context, resource model.Resource) (*model.Resource, error) {
@@ -246,71 +258,72 @@ func (dao *ResourceDAO) UpdateResource(ctx context.Context, resource model.Resou
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
+	resource.UpdatedBy = changedBy(ctx, "")
 	var updatedResource *model.Resource
 	oldResource, err := dao.GetResource(ctx, resource.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get resource: %w", err)
 	}
 
-	_, err = session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+	_, err = writeTransaction(ctx, session, func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
         MATCH (r:` + echo_neo4j.LabelResource + ` {id: $id})
-        SET r += $props
+        SET r += $props, r.version = coalesce(r.version, 0) + 1` + snapshotResourceVersion + `
         WITH r
-        OPTIONAL MATCH (r)-[oldOrgRel:BELONGS_TO]->(:` + echo_neo4j.LabelOrganization + `)
+        OPTIONAL MATCH (r)-[oldOrgRel:` + echo_neo4j.RelBelongsTo + `]->(:` + echo_neo4j.LabelOrganization + `)
         DELETE oldOrgRel
         WITH r
         MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $organizationID})
-        CREATE (r)-[:BELONGS_TO]->(o)
+        CREATE (r)-[:` + echo_neo4j.RelBelongsTo + `]->(o)
         WITH r
-        OPTIONAL MATCH (r)-[oldDeptRel:ASSIGNED_TO]->(:` + echo_neo4j.LabelDepartment + `)
+        OPTIONAL MATCH (r)-[oldDeptRel:` + echo_neo4j.RelAssignedTo + `]->(:` + echo_neo4j.LabelDepartment + `)
         DELETE oldDeptRel
         WITH r
         OPTIONAL MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $departmentID})
         FOREACH (_ IN CASE WHEN d IS NOT NULL THEN [1] ELSE [] END |
-            CREATE (r)-[:ASSIGNED_TO]->(d)
+            CREATE (r)-[:` + echo_neo4j.RelAssignedTo + `]->(d)
         )
         WITH r
-        OPTIONAL MATCH (r)-[oldOwnerRel:OWNED_BY]->(:` + echo_neo4j.LabelUser + `)
+        OPTIONAL MATCH (r)-[oldOwnerRel:` + echo_neo4j.RelOwnedBy + `]->(:` + echo_neo4j.LabelUser + `)
         DELETE oldOwnerRel
         WITH r
         MATCH (u:` + echo_neo4j.LabelUser + ` {id: $ownerID})
-        CREATE (r)-[:OWNED_BY]->(u)
+        CREATE (r)-[:` + echo_neo4j.RelOwnedBy + `]->(u)
         WITH r
-        OPTIONAL MATCH (r)-[oldTypeRel:HAS_TYPE]->(:` + echo_neo4j.LabelResourceType + `)
+        OPTIONAL MATCH (r)-[oldTypeRel:` + echo_neo4j.RelHasType + `]->(:` + echo_neo4j.LabelResourceType + `)
         DELETE oldTypeRel
         WITH r
         MATCH (rt:` + echo_neo4j.LabelResourceType + ` {id: $typeID})
-        CREATE (r)-[:HAS_TYPE]->(rt)
+        CREATE (r)-[:` + echo_neo4j.RelHasType + `]->(rt)
         WITH r
-        OPTIONAL MATCH (r)-[oldGroupRel:IN_GROUP]->(:` + echo_neo4j.LabelAttributeGroup + `)
+        OPTIONAL MATCH (r)-[oldGroupRel:` + echo_neo4j.RelInGroup + `]->(:` + echo_neo4j.LabelAttributeGroup + `)
         DELETE oldGroupRel
         WITH r
         MATCH (ag:` + echo_neo4j.LabelAttributeGroup + ` {id: $attributeGroupID})
-        CREATE (r)-[:IN_GROUP]->(ag)
+        CREATE (r)-[:` + echo_neo4j.RelInGroup + `]->(ag)
         `
 
 		// Update parent relationship
 		query += `
         WITH r
-        OPTIONAL MATCH (r)-[oldParentRel:CHILD_OF]->(:` + echo_neo4j.LabelResource + `)
+        OPTIONAL MATCH (r)-[oldParentRel:` + echo_neo4j.RelChildOf + `]->(:` + echo_neo4j.LabelResource + `)
         DELETE oldParentRel
         WITH r
         OPTIONAL MATCH (p:` + echo_neo4j.LabelResource + ` {id: $parentID})
         FOREACH (_ IN CASE WHEN p IS NOT NULL THEN [1] ELSE [] END |
-            CREATE (r)-[:CHILD_OF]->(p)
+            CREATE (r)-[:` + echo_neo4j.RelChildOf + `]->(p)
         )
         `
 
 		// Update related resources
 		query += `
         WITH r
-        OPTIONAL MATCH (r)-[oldRelatedRel:RELATED_TO]->(:` + echo_neo4j.LabelResource + `)
+        OPTIONAL MATCH (r)-[oldRelatedRel:` + echo_neo4j.RelRelatedTo + `]->(:` + echo_neo4j.LabelResource + `)
         DELETE oldRelatedRel
         WITH r
-        UNWIND $relatedIDs AS relatedID
-        MATCH (related:` + echo_neo4j.LabelResource + ` {id: relatedID})
-        CREATE (r)-[:RELATED_TO]->(related)
+        OPTIONAL MATCH (related:` + echo_neo4j.LabelResource + `) WHERE related.id IN $relatedIDs
+        WITH r, collect(related) AS related
+        FOREACH (target IN related | CREATE (r)-[:` + echo_neo4j.RelRelatedTo + `]->(target))
         `
 
 		query += `
@@ -332,7 +345,6 @@ func (dao *ResourceDAO) UpdateResource(ctx context.Context, resource model.Resou
 				"departmentID":     resource.DepartmentID,
 				"ownerID":          resource.OwnerID,
 				"status":           resource.Status,
-				"version":          resource.Version,
 				"tags":             resource.Tags,
 				"metadata":         string(metadataJSON),
 				"attributeGroupID": resource.AttributeGroupID,
@@ -366,7 +378,7 @@ func (dao *ResourceDAO) UpdateResource(ctx context.Context, resource model.Resou
 		result, err := transaction.Run(query, params)
 		if err != nil {
 			logger.Error("Failed to execute query", zap.Error(err), zap.Any("params", params))
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
@@ -387,7 +399,12 @@ func (dao *ResourceDAO) UpdateResource(ctx context.Context, resource model.Resou
 			zap.Error(err),
 			zap.String("resourceID", resource.ID),
 			zap.Duration("duration", duration))
-		return nil, err
+		return nil, classifyNeo4jError(err)
+	}
+
+	if helper_util.IsDryRun(ctx) {
+		logger.Info("Dry run: resource update rolled back", zap.String("resourceID", resource.ID))
+		return updatedResource, nil
 	}
 
 	logger.Info("Resource updated successfully",
@@ -397,7 +414,7 @@ func (dao *ResourceDAO) UpdateResource(ctx context.Context, resource model.Resou
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        resource.UpdatedBy,
 		Action:        "UPDATE_RESOURCE",
 		ResourceID:    resource.ID,
 		AccessGranted: true,
@@ -410,6 +427,164 @@ func (dao *ResourceDAO) UpdateResource(ctx context.Context, resource model.Resou
 	return updatedResource, nil
 }
 
+// snapshotResourceVersion follows the SET of every write to a resource's properties, which bumps its
+// version, and snapshots the resource r at that version for GetResourceDiff
+const snapshotResourceVersion = `
+        WITH r
+        CREATE (r)-[:` + echo_neo4j.RelHasVersion + `]->(v:` + echo_neo4j.LabelResourceVersion + `)
+        SET v = properties(r), v.resourceID = r.id`
+
+// GetResourceDiff compares the snapshots of two versions of a resource, taken whenever it was created
+// or updated, and returns the fields that differ. Only the resource's properties are compared; its
+// parent and related resources are relationships and have no snapshot.
+func (dao *ResourceDAO) GetResourceDiff(ctx context.Context, resourceID string, fromVersion, toVersion int) (*model.ResourceDiff, error) {
+	start := time.Now()
+	logger.Info("Comparing resource versions",
+		zap.String("resourceID", resourceID),
+		zap.Int("fromVersion", fromVersion),
+		zap.Int("toVersion", toVersion))
+
+	query := `
+    MATCH (:` + echo_neo4j.LabelResource + ` {id: $resourceID})-[:` + echo_neo4j.RelHasVersion + `]->(v:` + echo_neo4j.LabelResourceVersion + `)
+    WHERE v.version IN [$fromVersion, $toVersion]
+    RETURN v.version AS version, v
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{
+		"resourceID":  resourceID,
+		"fromVersion": fromVersion,
+		"toVersion":   toVersion,
+	})
+	if err != nil {
+		logger.Error("Failed to execute resource versions query",
+			zap.Error(err),
+			zap.String("resourceID", resourceID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	versions := make(map[int]*model.Resource, len(records))
+	for _, record := range records {
+		snapshot, err := mapNodeToResource(record.Values[1].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map resource version",
+				zap.Error(err),
+				zap.String("resourceID", resourceID))
+			return nil, echo_errors.ErrInternalServer
+		}
+		versions[int(record.Values[0].(int64))] = snapshot
+	}
+	from, to := versions[fromVersion], versions[toVersion]
+	if from == nil || to == nil {
+		return nil, echo_errors.ErrResourceVersionNotFound
+	}
+
+	diff := &model.ResourceDiff{
+		ResourceID:  resourceID,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     fieldChanges(from, to, resourceDiffIgnored...),
+	}
+
+	logger.Info("Resource versions compared",
+		zap.String("resourceID", resourceID),
+		zap.Int("changes", len(diff.Changes)),
+		zap.Duration("duration", time.Since(start)))
+	return diff, nil
+}
+
+// TransferResourceOwnership makes newOwnerID the owner of a resource, replacing its OWNED_BY
+// relationship and ownerID in one transaction, and returns the updated resource
+func (dao *ResourceDAO) TransferResourceOwnership(ctx context.Context, resourceID string, newOwnerID string, updaterID string) (*model.Resource, error) {
+	start := time.Now()
+	logger.Info("Transferring resource ownership", zap.String("resourceID", resourceID), zap.String("newOwnerID", newOwnerID))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	updaterID = changedBy(ctx, updaterID)
+	var oldOwnerID string
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		checkQuery := `
+		OPTIONAL MATCH (r:` + echo_neo4j.LabelResource + ` {id: $id})
+		OPTIONAL MATCH (u:` + echo_neo4j.LabelUser + ` {id: $newOwnerID})
+		RETURN r IS NOT NULL AS resourceExists, u IS NOT NULL AS ownerExists, r.ownerID AS oldOwnerID
+		`
+		checkResult, err := transaction.Run(checkQuery, map[string]interface{}{"id": resourceID, "newOwnerID": newOwnerID})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if !checkResult.Next() {
+			return nil, echo_errors.ErrDatabaseOperation
+		}
+		record := checkResult.Record()
+		if exists, _ := record.Values[0].(bool); !exists {
+			return nil, echo_errors.ErrResourceNotFound
+		}
+		if exists, _ := record.Values[1].(bool); !exists {
+			return nil, echo_errors.ErrUserNotFound
+		}
+		oldOwnerID, _ = record.Values[2].(string)
+
+		transferQuery := `
+		MATCH (r:` + echo_neo4j.LabelResource + ` {id: $id})
+		OPTIONAL MATCH (r)-[oldOwnerRel:` + echo_neo4j.RelOwnedBy + `]->(:` + echo_neo4j.LabelUser + `)
+		DELETE oldOwnerRel
+		WITH DISTINCT r
+		MATCH (u:` + echo_neo4j.LabelUser + ` {id: $newOwnerID})
+		CREATE (r)-[:` + echo_neo4j.RelOwnedBy + `]->(u)
+		SET r.ownerID = $newOwnerID, r.updatedBy = $updatedBy, r.updatedAt = $updatedAt,
+			r.version = coalesce(r.version, 0) + 1` + snapshotResourceVersion + `
+		RETURN r
+		`
+		transferResult, err := transaction.Run(transferQuery, map[string]interface{}{
+			"id":         resourceID,
+			"newOwnerID": newOwnerID,
+			"updatedBy":  updaterID,
+			"updatedAt":  time.Now().Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if !transferResult.Next() {
+			return nil, echo_errors.ErrResourceNotFound
+		}
+		return mapNodeToResource(transferResult.Record().Values[0].(neo4j.Node))
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to transfer resource ownership",
+			zap.Error(err),
+			zap.String("resourceID", resourceID),
+			zap.String("newOwnerID", newOwnerID),
+			zap.Duration("duration", duration))
+		return nil, classifyNeo4jError(err)
+	}
+
+	logger.Info("Resource ownership transferred successfully",
+		zap.String("resourceID", resourceID),
+		zap.String("oldOwnerID", oldOwnerID),
+		zap.String("newOwnerID", newOwnerID),
+		zap.Duration("duration", duration))
+
+	changeDetails, _ := json.Marshal(map[string]interface{}{
+		"ownerID": map[string]string{"old": oldOwnerID, "new": newOwnerID},
+	})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        updaterID,
+		Action:        "TRANSFER_RESOURCE_OWNERSHIP",
+		ResourceID:    resourceID,
+		AccessGranted: true,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return result.(*model.Resource), nil
+}
+
 func (dao *ResourceDAO) DeleteResource(ctx context.Context, resourceID string) error {
 	start := time.Now()
 	logger.Info("Deleting resource", zap.String("resourceID", resourceID))
@@ -424,12 +599,12 @@ func (dao *ResourceDAO) DeleteResource(ctx context.Context, resourceID string) e
         `
 		result, err := transaction.Run(query, map[string]interface{}{"id": resourceID})
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		summary, err := result.Consume()
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if summary.Counters().NodesDeleted() == 0 {
@@ -445,7 +620,7 @@ func (dao *ResourceDAO) DeleteResource(ctx context.Context, resourceID string) e
 			zap.Error(err),
 			zap.String("resourceID", resourceID),
 			zap.Duration("duration", duration))
-		return err
+		return classifyNeo4jError(err)
 	}
 
 	logger.Info("Resource deleted successfully",
@@ -455,10 +630,11 @@ func (dao *ResourceDAO) DeleteResource(ctx context.Context, resourceID string) e
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        helper_util.ActorFromContext(ctx),
 		Action:        "DELETE_RESOURCE",
 		ResourceID:    resourceID,
 		AccessGranted: true,
+		ChangeDetails: deletedDetails(helper_util.ActorFromContext(ctx), nil),
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
@@ -467,6 +643,11 @@ func (dao *ResourceDAO) DeleteResource(ctx context.Context, resourceID string) e
 	return nil
 }
 
+// Exists reports whether a resource with the given ID exists, without reading the whole node
+func (dao *ResourceDAO) Exists(ctx context.Context, resourceID string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelResource, resourceID)
+}
+
 func (dao *ResourceDAO) GetResource(ctx context.Context, resourceID string) (*model.Resource, error) {
 	start := time.Now()
 	logger.Info("Retrieving resource", zap.String("resourceID", resourceID))
@@ -476,8 +657,8 @@ func (dao *ResourceDAO) GetResource(ctx context.Context, resourceID string) (*mo
 
 	query := `
 		MATCH (r:` + echo_neo4j.LabelResource + ` {id: $id})
-		OPTIONAL MATCH (r)-[:CHILD_OF]->(p:` + echo_neo4j.LabelResource + `)
-		OPTIONAL MATCH (r)-[:RELATED_TO]->(rel:` + echo_neo4j.LabelResource + `)
+		OPTIONAL MATCH (r)-[:` + echo_neo4j.RelChildOf + `]->(p:` + echo_neo4j.LabelResource + `)
+		OPTIONAL MATCH (r)-[:` + echo_neo4j.RelRelatedTo + `]->(rel:` + echo_neo4j.LabelResource + `)
 		RETURN r, p.id AS parentID, COLLECT(rel.id) AS relatedIDs
 	`
 	result, err := session.Run(query, map[string]interface{}{"id": resourceID})
@@ -526,26 +707,111 @@ func (dao *ResourceDAO) GetResource(ctx context.Context, resourceID string) (*mo
 	return nil, echo_errors.ErrResourceNotFound
 }
 
+// GetResourcesByIDs retrieves the resources among resourceIDs, with their parent and related IDs, in
+// one query. IDs with no resource are left out of the result, which follows no particular order.
+func (dao *ResourceDAO) GetResourcesByIDs(ctx context.Context, resourceIDs []string) ([]*model.Resource, error) {
+	start := time.Now()
+	logger.Info("Retrieving resources by ID", zap.Int("count", len(resourceIDs)))
+
+	query := `
+		MATCH (r:` + echo_neo4j.LabelResource + `)
+		WHERE r.id IN $ids
+		OPTIONAL MATCH (r)-[:` + echo_neo4j.RelChildOf + `]->(p:` + echo_neo4j.LabelResource + `)
+		OPTIONAL MATCH (r)-[:` + echo_neo4j.RelRelatedTo + `]->(rel:` + echo_neo4j.LabelResource + `)
+		RETURN r, p.id AS parentID, COLLECT(rel.id) AS relatedIDs
+	`
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"ids": resourceIDs})
+	if err != nil {
+		logger.Error("Failed to execute get resources by ID query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	resources := make([]*model.Resource, 0, len(records))
+	for _, record := range records {
+		resource, err := mapNodeToResource(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map resource node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		resource.ParentID, _ = record.Values[1].(string)
+		relatedIDs, _ := record.Values[2].([]interface{})
+		for _, id := range relatedIDs {
+			resource.RelatedIDs = append(resource.RelatedIDs, id.(string))
+		}
+		resources = append(resources, resource)
+	}
+
+	logger.Info("Resources retrieved by ID successfully",
+		zap.Int("requested", len(resourceIDs)),
+		zap.Int("found", len(resources)),
+		zap.Duration("duration", time.Since(start)))
+	return resources, nil
+}
+
+// GetInheritingAncestors retrieves the ancestors a resource inherits access from, nearest first: its
+// parent when it inherits its ACL, then the parent's parent when the parent inherits too, and so on, up
+// to maxDepth levels.
+func (dao *ResourceDAO) GetInheritingAncestors(ctx context.Context, resourceID string, maxDepth int) ([]*model.Resource, error) {
+	start := time.Now()
+	if maxDepth <= 0 {
+		return []*model.Resource{}, nil
+	}
+
+	query := `
+		MATCH path = (r:` + echo_neo4j.LabelResource + ` {id: $id})-[:` + echo_neo4j.RelChildOf + `*1..` + strconv.Itoa(maxDepth) + `]->(ancestor:` + echo_neo4j.LabelResource + `)
+		WHERE all(child IN nodes(path)[0..length(path)] WHERE child.inheritedACL = true)
+		RETURN ancestor
+		ORDER BY length(path)
+	`
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"id": resourceID})
+	if err != nil {
+		logger.Error("Failed to execute inheriting ancestors query",
+			zap.Error(err),
+			zap.String("resourceID", resourceID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	ancestors := make([]*model.Resource, 0, len(records))
+	for _, record := range records {
+		ancestor, err := mapNodeToResource(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map resource node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		ancestors = append(ancestors, ancestor)
+	}
+
+	logger.Debug("Inheriting ancestors retrieved",
+		zap.String("resourceID", resourceID),
+		zap.Int("count", len(ancestors)),
+		zap.Duration("duration", time.Since(start)))
+	return ancestors, nil
+}
+
 func (dao *ResourceDAO) ListResources(ctx context.Context, limit int, offset int) ([]*model.Resource, error) {
 	start := time.Now()
 	logger.Info("Listing resources", zap.Int("limit", limit), zap.Int("offset", offset))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	query := `
     MATCH (r:` + echo_neo4j.LabelResource + `)
     WITH r
-    OPTIONAL MATCH (r)-[:BELONGS_TO]->(o:` + echo_neo4j.LabelOrganization + `)
-    OPTIONAL MATCH (r)-[:ASSIGNED_TO]->(d:` + echo_neo4j.LabelDepartment + `)
-    OPTIONAL MATCH (r)-[:OWNED_BY]->(u:` + echo_neo4j.LabelUser + `)
+    OPTIONAL MATCH (r)-[:` + echo_neo4j.RelBelongsTo + `]->(o:` + echo_neo4j.LabelOrganization + `)
+    OPTIONAL MATCH (r)-[:` + echo_neo4j.RelAssignedTo + `]->(d:` + echo_neo4j.LabelDepartment + `)
+    OPTIONAL MATCH (r)-[:` + echo_neo4j.RelOwnedBy + `]->(u:` + echo_neo4j.LabelUser + `)
     RETURN r, o.id AS organizationID, d.id AS departmentID, u.id AS ownerID
     ORDER BY r.createdAt DESC
     SKIP $offset
     LIMIT $limit
     `
 
-	result, err := session.Run(query, map[string]interface{}{
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{
 		"limit":  limit,
 		"offset": offset,
 	})
@@ -553,12 +819,11 @@ func (dao *ResourceDAO) ListResources(ctx context.Context, limit int, offset int
 		logger.Error("Failed to execute list resources query",
 			zap.Error(err),
 			zap.Duration("duration", time.Since(start)))
-		return nil, echo_errors.ErrDatabaseOperation
+		return nil, readFailure(ctx)
 	}
 
 	var resources []*model.Resource
-	for result.Next() {
-		record := result.Record()
+	for _, record := range records {
 		node := record.Values[0].(neo4j.Node)
 		resource, err := mapNodeToResource(node)
 		if err != nil {
@@ -589,6 +854,218 @@ func (dao *ResourceDAO) ListResources(ctx context.Context, limit int, offset int
 	return resources, nil
 }
 
+// ListResourcesInScope lists, like ListResources, the resources a scoped admin manages: those of the
+// scope's organization and, for a department scope, assigned to the departments in its subtree
+func (dao *ResourceDAO) ListResourcesInScope(ctx context.Context, scope model.ScopedAdmin, limit int, offset int) ([]*model.Resource, error) {
+	start := time.Now()
+	logger.Info("Listing resources in admin scope",
+		zap.String("organizationID", scope.OrganizationID),
+		zap.String("departmentID", scope.DepartmentID),
+		zap.Int("limit", limit),
+		zap.Int("offset", offset))
+
+	query := `
+    MATCH (r:` + echo_neo4j.LabelResource + `)-[:` + echo_neo4j.RelBelongsTo + `]->(o:` + echo_neo4j.LabelOrganization + ` {id: $organizationID})
+    OPTIONAL MATCH (r)-[:` + echo_neo4j.RelAssignedTo + `]->(d:` + echo_neo4j.LabelDepartment + `)
+    WITH r, o, d
+    WHERE $departmentIDs IS NULL OR d.id IN $departmentIDs
+    OPTIONAL MATCH (r)-[:` + echo_neo4j.RelOwnedBy + `]->(u:` + echo_neo4j.LabelUser + `)
+    RETURN r, o.id AS organizationID, d.id AS departmentID, u.id AS ownerID
+    ORDER BY r.createdAt DESC
+    SKIP $offset
+    LIMIT $limit
+    `
+
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{
+		"organizationID": scope.OrganizationID,
+		"departmentIDs":  scopeDepartmentIDs(scope),
+		"limit":          limit,
+		"offset":         offset,
+	})
+	if err != nil {
+		logger.Error("Failed to execute list resources in scope query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	resources := []*model.Resource{}
+	for _, record := range records {
+		resource, err := mapNodeToResource(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map resource node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		resource.OrganizationID, _ = record.Values[1].(string)
+		resource.DepartmentID, _ = record.Values[2].(string)
+		resource.OwnerID, _ = record.Values[3].(string)
+		resources = append(resources, resource)
+	}
+
+	logger.Info("Resources in admin scope listed successfully",
+		zap.Int("count", len(resources)),
+		zap.Duration("duration", time.Since(start)))
+
+	return resources, nil
+}
+
+// ListResourcesAfter lists resources newest first, continuing after the given cursor. Unlike offset
+// paging, resources created while a client iterates sort before the cursor and cannot shift later pages.
+// The returned cursor is nil on the last page.
+func (dao *ResourceDAO) ListResourcesAfter(ctx context.Context, after *helper_util.Cursor, limit int) ([]*model.Resource, *helper_util.Cursor, error) {
+	return dao.SearchResourcesAfter(ctx, model.ResourceSearchCriteria{}, after, limit)
+}
+
+// SearchResourcesAfter lists the resources matching criteria newest first, continuing after the given
+// cursor, as ListResourcesAfter lists every resource. The criteria's limit, offset and sort are not
+// used. The returned cursor is nil on the last page.
+func (dao *ResourceDAO) SearchResourcesAfter(ctx context.Context, criteria model.ResourceSearchCriteria, after *helper_util.Cursor, limit int) ([]*model.Resource, *helper_util.Cursor, error) {
+	start := time.Now()
+	logger.Info("Listing resources by cursor", zap.Any("criteria", criteria), zap.Any("after", after), zap.Int("limit", limit))
+
+	filter, params := resourceSearchFilter(criteria)
+	helper_util.MergeParams(params, helper_util.CursorParams(after, limit))
+	query := filter + `
+    WITH r
+    WHERE $afterCreatedAt IS NULL
+       OR r.createdAt < $afterCreatedAt
+       OR (r.createdAt = $afterCreatedAt AND r.id < $afterID)
+    WITH r
+    ORDER BY r.createdAt DESC, r.id DESC
+    LIMIT $limit
+    OPTIONAL MATCH (r)-[:` + echo_neo4j.RelBelongsTo + `]->(o:` + echo_neo4j.LabelOrganization + `)
+    OPTIONAL MATCH (r)-[:` + echo_neo4j.RelAssignedTo + `]->(d:` + echo_neo4j.LabelDepartment + `)
+    OPTIONAL MATCH (r)-[:` + echo_neo4j.RelOwnedBy + `]->(u:` + echo_neo4j.LabelUser + `)
+    RETURN r, o.id AS organizationID, d.id AS departmentID, u.id AS ownerID
+    ORDER BY r.createdAt DESC, r.id DESC
+    `
+
+	records, err := readRecords(ctx, dao.Driver, query, params)
+	if err != nil {
+		logger.Error("Failed to execute list resources by cursor query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, nil, readFailure(ctx)
+	}
+
+	var resources []*model.Resource
+	var next *helper_util.Cursor
+	var lastCreatedAt string
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
+		if len(resources) == limit {
+			// The extra row only signals that another page follows; the cursor keeps the stored
+			// createdAt string so the next page compares against exactly what was persisted
+			next = &helper_util.Cursor{CreatedAt: lastCreatedAt, ID: resources[len(resources)-1].ID}
+			break
+		}
+
+		resource, err := mapNodeToResource(node)
+		if err != nil {
+			logger.Error("Failed to map resource node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, nil, echo_errors.ErrInternalServer
+		}
+
+		// Set organization, department, and owner IDs
+		if organizationID, ok := record.Get("organizationID"); ok && organizationID != nil {
+			resource.OrganizationID = organizationID.(string)
+		}
+		if departmentID, ok := record.Get("departmentID"); ok && departmentID != nil {
+			resource.DepartmentID = departmentID.(string)
+		}
+		if ownerID, ok := record.Get("ownerID"); ok && ownerID != nil {
+			resource.OwnerID = ownerID.(string)
+		}
+
+		lastCreatedAt, _ = node.Props["createdAt"].(string)
+		resources = append(resources, resource)
+	}
+
+	logger.Info("Resources listed by cursor successfully",
+		zap.Int("count", len(resources)),
+		zap.Bool("hasMore", next != nil),
+		zap.Duration("duration", time.Since(start)))
+
+	return resources, next, nil
+}
+
+// verifyResourceReferences checks that every node a resource references exists and returns a
+// specific not-found error for the first missing one
+func verifyResourceReferences(transaction neo4j.Transaction, resource model.Resource) error {
+	query := `
+    OPTIONAL MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $organizationID})
+    OPTIONAL MATCH (u:` + echo_neo4j.LabelUser + ` {id: $ownerID})
+    OPTIONAL MATCH (rt:` + echo_neo4j.LabelResourceType + ` {id: $typeID})
+    OPTIONAL MATCH (ag:` + echo_neo4j.LabelAttributeGroup + ` {id: $attributeGroupID})
+    OPTIONAL MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $departmentID})
+    OPTIONAL MATCH (p:` + echo_neo4j.LabelResource + ` {id: $parentID})
+    RETURN o IS NOT NULL AS orgExists,
+           u IS NOT NULL AS ownerExists,
+           rt IS NOT NULL AS typeExists,
+           ag IS NOT NULL AS attributeGroupExists,
+           d IS NOT NULL AS departmentExists,
+           p IS NOT NULL AS parentExists,
+           [relatedID IN $relatedIDs WHERE NOT EXISTS { MATCH (:` + echo_neo4j.LabelResource + ` {id: relatedID}) }] AS missingRelatedIDs
+    `
+
+	relatedIDs := resource.RelatedIDs
+	if relatedIDs == nil {
+		relatedIDs = []string{}
+	}
+
+	result, err := transaction.Run(query, map[string]interface{}{
+		"organizationID":   resource.OrganizationID,
+		"ownerID":          resource.OwnerID,
+		"typeID":           resource.TypeID,
+		"attributeGroupID": resource.AttributeGroupID,
+		"departmentID":     resource.DepartmentID,
+		"parentID":         resource.ParentID,
+		"relatedIDs":       relatedIDs,
+	})
+	if err != nil {
+		return echo_errors.ErrDatabaseOperation
+	}
+
+	if !result.Next() {
+		return echo_errors.ErrDatabaseOperation
+	}
+	record := result.Record()
+
+	exists := func(key string) bool {
+		value, _ := record.Get(key)
+		found, _ := value.(bool)
+		return found
+	}
+
+	switch {
+	case !exists("orgExists"):
+		return echo_errors.ErrOrganizationNotFound
+	case !exists("ownerExists"):
+		return echo_errors.ErrOwnerNotFound
+	case !exists("typeExists"):
+		return echo_errors.ErrResourceTypeNotFound
+	case !exists("attributeGroupExists"):
+		return echo_errors.ErrAttributeGroupNotFound
+	case resource.DepartmentID != "" && !exists("departmentExists"):
+		return echo_errors.ErrDepartmentNotFound
+	case resource.ParentID != "" && !exists("parentExists"):
+		return echo_errors.ErrParentResourceNotFound
+	}
+
+	if missing, _ := record.Get("missingRelatedIDs"); missing != nil {
+		if missingIDs, ok := missing.([]interface{}); ok && len(missingIDs) > 0 {
+			logger.Warn("Related resources not found", zap.Any("missingRelatedIDs", missingIDs))
+			return echo_errors.ErrRelatedResourceNotFound
+		}
+	}
+
+	return nil
+}
+
 // Helper function to map Neo4j Node to Resource struct
 func mapNodeToResource(node neo4j.Node) (*model.Resource, error) {
 	props := node.Props
@@ -668,26 +1145,193 @@ func mapNodeToResource(node neo4j.Node) (*model.Resource, error) {
 	return resource, nil
 }
 
+// resourceSortFields lists the properties resource searches may be ordered by
+var resourceSortFields = []string{"name", "type", "status", "sensitivity", "classification", "version", "size", "createdAt", "updatedAt", "lastAccessedAt", "expiresAt"}
+
 func (dao *ResourceDAO) SearchResources(ctx context.Context, criteria model.ResourceSearchCriteria) ([]*model.Resource, error) {
 	start := time.Now()
 	logger.Info("Searching resources", zap.Any("criteria", criteria))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	// Build the query dynamically based on the provided criteria
-	query := `MATCH (r:` + echo_neo4j.LabelResource + `)`
-	whereClauses := []string{}
-	params := map[string]interface{}{}
+	query, params := resourceSearchFilter(criteria)
 
-	// Add WHERE clauses for each non-empty criteria
-	if criteria.ID != "" {
-		whereClauses = append(whereClauses, "r.id = $id")
-		params["id"] = criteria.ID
+	// Add WITH clause
+	query += " WITH r"
+
+	// Add ORDER BY clause
+	sortClause, err := helper_util.SafeSortClause("r", criteria.SortBy, criteria.SortOrder, resourceSortFields)
+	if err != nil {
+		logger.Warn("Invalid sort field for resource search", zap.String("sortBy", criteria.SortBy))
+		return nil, err
 	}
-	if criteria.Name != "" {
-		whereClauses = append(whereClauses, "r.name CONTAINS $name")
-		params["name"] = criteria.Name
+	if sortClause == "" {
+		sortClause = " ORDER BY r.createdAt DESC"
+	}
+	query += sortClause
+
+	// Add SKIP and LIMIT clauses
+	paginationClause, paginationParams := helper_util.BuildPagination(criteria.Limit, criteria.Offset)
+	query += paginationClause
+	helper_util.MergeParams(params, paginationParams)
+
+	// Add RETURN clause
+	query += " RETURN r"
+
+	// Log the query
+	logger.Debug("Search resources query", zap.String("query", query), zap.Any("params", params))
+
+	// Execute the query
+	records, err := readRecords(ctx, dao.Driver, query, params)
+	if err != nil {
+		logger.Error("Failed to execute search resources query",
+			zap.Error(err),
+			zap.String("query", query),
+			zap.Any("params", params),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to execute search query: %w", err)
+	}
+
+	var resources []*model.Resource
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
+		resource, err := mapNodeToResource(node)
+		if err != nil {
+			logger.Error("Failed to map resource node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		resources = append(resources, resource)
+	}
+
+	logger.Info("Resources searched successfully",
+		zap.Int("count", len(resources)),
+		zap.Duration("duration", time.Since(start)))
+
+	return resources, nil
+}
+
+// GetResourcesByOrganization returns a page of the resources belonging to an organization, and to its
+// sub-organizations when includeSubOrgs is set, newest first, along with the total number of them.
+// Resources are reached through the organizations' BELONGS_TO relationships rather than a scan of every
+// resource.
+func (dao *ResourceDAO) GetResourcesByOrganization(ctx context.Context, orgID string, includeSubOrgs bool, limit int, offset int) ([]*model.Resource, int64, error) {
+	start := time.Now()
+	logger.Info("Listing organization resources",
+		zap.String("orgID", orgID),
+		zap.Bool("includeSubOrgs", includeSubOrgs),
+		zap.Int("limit", limit),
+		zap.Int("offset", offset))
+
+	owners := ""
+	if includeSubOrgs {
+		owners = `-[:` + echo_neo4j.RelParentOf + `*0..]->(:` + echo_neo4j.LabelOrganization + `)`
+	}
+
+	countQuery := `
+	OPTIONAL MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $orgID})
+	OPTIONAL MATCH (o)` + owners + `<-[:` + echo_neo4j.RelBelongsTo + `]-(r:` + echo_neo4j.LabelResource + `)
+	RETURN o IS NOT NULL AS orgExists, count(r) AS total
+	`
+	records, err := readRecords(ctx, dao.Driver, countQuery, map[string]interface{}{"orgID": orgID})
+	if err != nil || len(records) == 0 {
+		logger.Error("Failed to count organization resources",
+			zap.Error(err),
+			zap.String("orgID", orgID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, 0, readFailure(ctx)
+	}
+	if exists, _ := records[0].Values[0].(bool); !exists {
+		return nil, 0, echo_errors.ErrOrganizationNotFound
+	}
+	total, _ := records[0].Values[1].(int64)
+
+	paginationClause, params := helper_util.BuildPagination(limit, offset)
+	params["orgID"] = orgID
+	pageQuery := `
+	MATCH (:` + echo_neo4j.LabelOrganization + ` {id: $orgID})` + owners + `<-[:` + echo_neo4j.RelBelongsTo + `]-(r:` + echo_neo4j.LabelResource + `)
+	RETURN r
+	ORDER BY r.createdAt DESC, r.id DESC` + paginationClause
+
+	records, err = readRecords(ctx, dao.Driver, pageQuery, params)
+	if err != nil {
+		logger.Error("Failed to list organization resources",
+			zap.Error(err),
+			zap.String("orgID", orgID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, 0, readFailure(ctx)
+	}
+
+	resources := make([]*model.Resource, 0, len(records))
+	for _, record := range records {
+		resource, err := mapNodeToResource(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map resource node to struct", zap.Error(err))
+			return nil, 0, echo_errors.ErrInternalServer
+		}
+		resources = append(resources, resource)
+	}
+
+	logger.Info("Organization resources listed successfully",
+		zap.String("orgID", orgID),
+		zap.Int("count", len(resources)),
+		zap.Int64("total", total),
+		zap.Duration("duration", time.Since(start)))
+	return resources, total, nil
+}
+
+// GetResourcesByClassifications returns every resource classified at one of classifications, newest
+// first
+func (dao *ResourceDAO) GetResourcesByClassifications(ctx context.Context, classifications []string) ([]*model.Resource, error) {
+	start := time.Now()
+	logger.Info("Listing resources by classification", zap.Strings("classifications", classifications))
+
+	query := `
+	MATCH (r:` + echo_neo4j.LabelResource + `)
+	WHERE r.classification IN $classifications
+	RETURN r
+	ORDER BY r.createdAt DESC, r.id DESC
+	`
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"classifications": classifications})
+	if err != nil {
+		logger.Error("Failed to list resources by classification",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	resources := make([]*model.Resource, 0, len(records))
+	for _, record := range records {
+		resource, err := mapNodeToResource(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map resource node to struct", zap.Error(err))
+			return nil, echo_errors.ErrInternalServer
+		}
+		resources = append(resources, resource)
+	}
+
+	logger.Info("Resources listed by classification successfully",
+		zap.Int("count", len(resources)),
+		zap.Duration("duration", time.Since(start)))
+	return resources, nil
+}
+
+// resourceSearchFilter returns the MATCH and WHERE clauses selecting the resources that match criteria
+// as r, along with their parameters. Pagination and sorting are left to the caller. Every filter adds
+// a parameter, so empty parameters mean the criteria select every resource.
+func resourceSearchFilter(criteria model.ResourceSearchCriteria) (string, map[string]interface{}) {
+	query := `MATCH (r:` + echo_neo4j.LabelResource + `)`
+	whereClauses := []string{}
+	params := map[string]interface{}{}
+
+	// Add WHERE clauses for each non-empty criteria
+	if criteria.ID != "" {
+		whereClauses = append(whereClauses, "r.id = $id")
+		params["id"] = criteria.ID
+	}
+	if criteria.Name != "" {
+		whereClauses = append(whereClauses, "r.name CONTAINS $name")
+		params["name"] = criteria.Name
 	}
 	if criteria.Type != "" {
 		whereClauses = append(whereClauses, "r.type = $type")
@@ -706,17 +1350,22 @@ func (dao *ResourceDAO) SearchResources(ctx context.Context, criteria model.Reso
 		params["classification"] = criteria.Classification
 	}
 	if criteria.OrganizationID != "" {
-		query += ` MATCH (r)-[:BELONGS_TO]->(o:ORGANIZATION)`
+		query += ` MATCH (r)-[:` + echo_neo4j.RelBelongsTo + `]->(o:` + echo_neo4j.LabelOrganization + `)`
 		whereClauses = append(whereClauses, "o.id = $organizationId")
 		params["organizationId"] = criteria.OrganizationID
 	}
 	if criteria.DepartmentID != "" {
-		query += ` MATCH (r)-[:ASSIGNED_TO]->(d:DEPARTMENT)`
+		query += ` MATCH (r)-[:` + echo_neo4j.RelAssignedTo + `]->(d:` + echo_neo4j.LabelDepartment + `)`
 		whereClauses = append(whereClauses, "d.id = $departmentId")
 		params["departmentId"] = criteria.DepartmentID
 	}
+	if len(criteria.DepartmentIDs) > 0 {
+		query += ` MATCH (r)-[:` + echo_neo4j.RelAssignedTo + `]->(sd:` + echo_neo4j.LabelDepartment + `)`
+		whereClauses = append(whereClauses, "sd.id IN $departmentIds")
+		params["departmentIds"] = criteria.DepartmentIDs
+	}
 	if criteria.OwnerID != "" {
-		query += ` MATCH (r)-[:OWNED_BY]->(u:USER)`
+		query += ` MATCH (r)-[:` + echo_neo4j.RelOwnedBy + `]->(u:` + echo_neo4j.LabelUser + `)`
 		whereClauses = append(whereClauses, "u.id = $ownerId")
 		params["ownerId"] = criteria.OwnerID
 	}
@@ -741,13 +1390,18 @@ func (dao *ResourceDAO) SearchResources(ctx context.Context, criteria model.Reso
 		params["updatedBefore"] = criteria.UpdatedBefore.Format(time.RFC3339)
 	}
 
-	// Handle custom attributes
-	if len(criteria.Attributes) > 0 {
-		for key, value := range criteria.Attributes {
-			attrKey := "attr_" + key
-			whereClauses = append(whereClauses, "r.attributes CONTAINS $"+attrKey)
-			params[attrKey] = fmt.Sprintf(`"%s":"%v"`, key, value)
-		}
+	// Custom attributes are stored as one JSON property, so each filter is matched against its
+	// encoding with a CONTAINS that no index serves. Keys come in sorted so the same criteria always
+	// build the same query, and are never spliced into it.
+	keys := make([]string, 0, len(criteria.Attributes))
+	for key := range criteria.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		attrKey := fmt.Sprintf("attr%d", i)
+		whereClauses = append(whereClauses, "r.attributes CONTAINS $"+attrKey)
+		params[attrKey] = encodedAttribute(key, criteria.Attributes[key])
 	}
 
 	// Add WHERE clause if any conditions exist
@@ -755,59 +1409,443 @@ func (dao *ResourceDAO) SearchResources(ctx context.Context, criteria model.Reso
 		query += " WHERE " + strings.Join(whereClauses, " AND ")
 	}
 
-	// Add WITH clause
-	query += " WITH r"
+	return query, params
+}
 
-	// Add ORDER BY clause
-	if criteria.SortBy != "" {
-		query += ` ORDER BY r.` + criteria.SortBy
-		if strings.ToLower(criteria.SortOrder) == "desc" {
-			query += " DESC"
-		} else {
-			query += " ASC"
+// HasResourceFilter reports whether criteria filters resources on anything, pagination and sorting
+// aside
+func HasResourceFilter(criteria model.ResourceSearchCriteria) bool {
+	_, params := resourceSearchFilter(criteria)
+	return len(params) > 0
+}
+
+// encodedAttribute returns how an attribute appears in the JSON the resource attributes are stored as
+func encodedAttribute(key string, value interface{}) string {
+	keyJSON, _ := json.Marshal(key)
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		valueJSON, _ = json.Marshal(fmt.Sprint(value))
+	}
+	return string(keyJSON) + ":" + string(valueJSON)
+}
+
+// AddResourceTags adds tags to a resource, skipping any it already carries, and returns its tags
+func (dao *ResourceDAO) AddResourceTags(ctx context.Context, resourceID string, tags []string, updaterID string) ([]string, error) {
+	return dao.updateResourceTags(ctx, resourceID, tags, updaterID, "ADD_RESOURCE_TAGS", func(current []string) []string {
+		return mergeTags(current, tags)
+	})
+}
+
+// RemoveResourceTags removes tags from a resource, ignoring any it does not carry, and returns its tags
+func (dao *ResourceDAO) RemoveResourceTags(ctx context.Context, resourceID string, tags []string, updaterID string) ([]string, error) {
+	return dao.updateResourceTags(ctx, resourceID, tags, updaterID, "REMOVE_RESOURCE_TAGS", func(current []string) []string {
+		return subtractTags(current, tags)
+	})
+}
+
+// updateResourceTags replaces a resource's tags with apply(current tags) in one transaction, recording
+// updaterID and the time as the resource's last change. Reading the tags through a SET takes the node's
+// write lock first, so concurrent tag changes cannot overwrite each other.
+func (dao *ResourceDAO) updateResourceTags(ctx context.Context, resourceID string, tags []string, updaterID string, action string, apply func([]string) []string) ([]string, error) {
+	start := time.Now()
+	logger.Info("Updating resource tags", zap.String("resourceID", resourceID), zap.String("action", action), zap.Strings("tags", tags))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	actor := changedBy(ctx, updaterID)
+	var before, after []string
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		lockQuery := `
+		MATCH (r:` + echo_neo4j.LabelResource + ` {id: $id})
+		SET r.tags = coalesce(r.tags, [])
+		RETURN r.tags AS tags
+		`
+		result, err := transaction.Run(lockQuery, map[string]interface{}{"id": resourceID})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
 		}
-	} else {
-		query += " ORDER BY r.createdAt DESC"
+		if !result.Next() {
+			return nil, echo_errors.ErrResourceNotFound
+		}
+
+		before = nil
+		if current, ok := result.Record().Values[0].([]interface{}); ok {
+			for _, tag := range current {
+				before = append(before, tag.(string))
+			}
+		}
+		after = apply(before)
+		if slices.Equal(before, after) {
+			return nil, nil
+		}
+
+		updateQuery := `
+		MATCH (r:` + echo_neo4j.LabelResource + ` {id: $id})
+		SET r.tags = $tags, r.updatedBy = $updatedBy, r.updatedAt = $updatedAt,
+			r.version = coalesce(r.version, 0) + 1` + snapshotResourceVersion + `
+		`
+		if _, err := transaction.Run(updateQuery, map[string]interface{}{
+			"id":        resourceID,
+			"tags":      after,
+			"updatedBy": actor,
+			"updatedAt": time.Now().UTC().Format(time.RFC3339),
+		}); err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		return nil, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to update resource tags",
+			zap.Error(err),
+			zap.String("resourceID", resourceID),
+			zap.Duration("duration", duration))
+		return nil, classifyNeo4jError(err)
 	}
 
-	// Add SKIP and LIMIT clauses
-	query += ` SKIP $offset LIMIT $limit`
+	logger.Info("Resource tags updated successfully",
+		zap.String("resourceID", resourceID),
+		zap.Strings("tags", after),
+		zap.Duration("duration", duration))
 
-	// Add RETURN clause
-	query += " RETURN r"
-	params["offset"] = criteria.Offset
-	params["limit"] = criteria.Limit
+	changeDetails, _ := json.Marshal(map[string]interface{}{
+		"tags": map[string][]string{"old": before, "new": after},
+	})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        actor,
+		Action:        action,
+		ResourceID:    resourceID,
+		AccessGranted: true,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
 
-	// Log the query
-	logger.Debug("Search resources query", zap.String("query", query), zap.Any("params", params))
+	return after, nil
+}
 
-	// Execute the query
-	result, err := session.Run(query, params)
+// ListAllTags returns every tag in use on resources with the number of resources carrying it, most
+// used first
+func (dao *ResourceDAO) ListAllTags(ctx context.Context) ([]model.TagCount, error) {
+	start := time.Now()
+	logger.Info("Listing resource tags")
+
+	query := `
+	MATCH (r:` + echo_neo4j.LabelResource + `)
+	UNWIND coalesce(r.tags, []) AS tag
+	RETURN tag, count(DISTINCT r) AS count
+	ORDER BY count DESC, tag ASC
+	`
+	records, err := readRecords(ctx, dao.Driver, query, nil)
 	if err != nil {
-		logger.Error("Failed to execute search resources query",
+		logger.Error("Failed to execute list tags query",
 			zap.Error(err),
-			zap.String("query", query),
-			zap.Any("params", params),
 			zap.Duration("duration", time.Since(start)))
-		return nil, fmt.Errorf("failed to execute search query: %w", err)
+		return nil, readFailure(ctx)
 	}
 
-	var resources []*model.Resource
-	for result.Next() {
-		node := result.Record().Values[0].(neo4j.Node)
-		resource, err := mapNodeToResource(node)
+	tagCounts := make([]model.TagCount, 0, len(records))
+	for _, record := range records {
+		tagCounts = append(tagCounts, model.TagCount{
+			Tag:   record.Values[0].(string),
+			Count: int(record.Values[1].(int64)),
+		})
+	}
+
+	logger.Info("Resource tags listed successfully",
+		zap.Int("count", len(tagCounts)),
+		zap.Duration("duration", time.Since(start)))
+	return tagCounts, nil
+}
+
+// BulkTagResources adds tags to every resource matching criteria in one transaction and returns the IDs
+// of the resources that gained at least one tag. Criteria without any filter are refused with
+// ErrInvalidSearchCriteria, and when more than maxAffected resources match nothing is tagged and
+// ErrBulkLimitExceeded is returned. Pagination and sorting in criteria are ignored.
+func (dao *ResourceDAO) BulkTagResources(ctx context.Context, criteria model.ResourceSearchCriteria, tags []string, maxAffected int) ([]string, error) {
+	start := time.Now()
+	logger.Info("Bulk tagging resources", zap.Any("criteria", criteria), zap.Strings("tags", tags))
+
+	filter, params := resourceSearchFilter(criteria)
+	if len(params) == 0 {
+		logger.Warn("Refusing to bulk tag resources without any filter")
+		return nil, fmt.Errorf("%w: bulk tagging requires at least one filter", echo_errors.ErrInvalidSearchCriteria)
+	}
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	actor := changedBy(ctx, "")
+
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		countResult, err := transaction.Run(filter+" RETURN count(DISTINCT r) AS count", params)
 		if err != nil {
-			logger.Error("Failed to map resource node to struct",
-				zap.Error(err),
-				zap.Duration("duration", time.Since(start)))
-			return nil, echo_errors.ErrInternalServer
+			return nil, classifyNeo4jError(err)
 		}
-		resources = append(resources, resource)
+		if countResult.Next() {
+			if matched := countResult.Record().Values[0].(int64); matched > int64(maxAffected) {
+				return nil, fmt.Errorf("%w: %d resources match, the limit is %d", echo_errors.ErrBulkLimitExceeded, matched, maxAffected)
+			}
+		}
+
+		tagQuery := filter + `
+		WITH DISTINCT r
+		WHERE ANY(tag IN $bulkTags WHERE NOT tag IN coalesce(r.tags, []))
+		SET r.tags = reduce(acc = coalesce(r.tags, []), tag IN $bulkTags | CASE WHEN tag IN acc THEN acc ELSE acc + tag END),
+			r.updatedBy = $updatedBy, r.updatedAt = $updatedAt, r.version = coalesce(r.version, 0) + 1` + snapshotResourceVersion + `
+		RETURN r.id
+		`
+		tagParams := helper_util.MergeParams(map[string]interface{}{
+			"bulkTags":  tags,
+			"updatedBy": actor,
+			"updatedAt": time.Now().UTC().Format(time.RFC3339),
+		}, params)
+		tagResult, err := transaction.Run(tagQuery, tagParams)
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+
+		var resourceIDs []string
+		for tagResult.Next() {
+			resourceIDs = append(resourceIDs, tagResult.Record().Values[0].(string))
+		}
+		return resourceIDs, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to bulk tag resources",
+			zap.Error(err),
+			zap.Duration("duration", duration))
+		return nil, classifyNeo4jError(err)
 	}
 
-	logger.Info("Resources searched successfully",
-		zap.Int("count", len(resources)),
+	resourceIDs, _ := result.([]string)
+	logger.Info("Resources bulk tagged successfully",
+		zap.Int("count", len(resourceIDs)),
+		zap.Duration("duration", duration))
+
+	if len(resourceIDs) > 0 {
+		changeDetails, _ := json.Marshal(map[string]interface{}{
+			"criteria":    criteria,
+			"tags":        tags,
+			"resourceIDs": resourceIDs,
+		})
+		auditLog := audit.AuditLog{
+			Timestamp:     time.Now(),
+			UserID:        actor,
+			Action:        "BULK_TAG_RESOURCES",
+			AccessGranted: true,
+			ChangeDetails: changeDetails,
+		}
+		if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+			logger.Error("Failed to create audit log", zap.Error(err))
+		}
+	}
+
+	return resourceIDs, nil
+}
+
+// resourceReferences are the references a resource stores twice, as a property and as a relationship
+// to the node the property names
+var resourceReferences = []struct {
+	property     string
+	relationship string
+	label        string
+}{
+	{"organizationID", echo_neo4j.RelBelongsTo, echo_neo4j.LabelOrganization},
+	{"departmentID", echo_neo4j.RelAssignedTo, echo_neo4j.LabelDepartment},
+	{"ownerID", echo_neo4j.RelOwnedBy, echo_neo4j.LabelUser},
+	{"typeID", echo_neo4j.RelHasType, echo_neo4j.LabelResourceType},
+	{"attributeGroupID", echo_neo4j.RelInGroup, echo_neo4j.LabelAttributeGroup},
+}
+
+// CheckIntegrity compares the reference properties of every resource, such as organizationID, with
+// the relationships it has. A property naming a node the resource has no relationship to is reported
+// as missing, and relationships pointing anywhere else as a mismatch.
+func (dao *ResourceDAO) CheckIntegrity(ctx context.Context) (*model.IntegrityReport, error) {
+	start := time.Now()
+	logger.Info("Checking resource integrity")
+
+	query := `
+    MATCH (r:` + echo_neo4j.LabelResource + `)
+    RETURN r.id AS id`
+	for _, ref := range resourceReferences {
+		query += `,
+        r.` + ref.property + ` AS ` + ref.property + `,
+        [(r)-[:` + ref.relationship + `]->(n:` + ref.label + `) | n.id] AS ` + ref.property + `Links`
+	}
+	query += `
+    ORDER BY r.id
+    `
+
+	records, err := readRecords(ctx, dao.Driver, query, nil)
+	if err != nil {
+		logger.Error("Failed to execute resource integrity query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	report := &model.IntegrityReport{
+		CheckedAt:        time.Now().UTC(),
+		ResourcesChecked: len(records),
+		Issues:           []model.IntegrityIssue{},
+	}
+	for _, record := range records {
+		resourceID, _ := record.Values[0].(string)
+		for i, ref := range resourceReferences {
+			stored, _ := record.Values[1+2*i].(string)
+			linked := []string{}
+			links, _ := record.Values[2+2*i].([]interface{})
+			for _, link := range links {
+				if id, ok := link.(string); ok {
+					linked = append(linked, id)
+				}
+			}
+
+			kind := ""
+			switch {
+			case len(linked) == 0 && stored != "":
+				kind = model.IntegrityMissingRelationship
+			case len(linked) > 1 || (len(linked) == 1 && linked[0] != stored):
+				kind = model.IntegrityMismatch
+			}
+			if kind != "" {
+				report.Issues = append(report.Issues, model.IntegrityIssue{
+					ResourceID:   resourceID,
+					Property:     ref.property,
+					Relationship: ref.relationship,
+					Kind:         kind,
+					Stored:       stored,
+					Linked:       linked,
+				})
+			}
+		}
+	}
+
+	logger.Info("Resource integrity checked",
+		zap.Int("resourcesChecked", report.ResourcesChecked),
+		zap.Int("issues", len(report.Issues)),
 		zap.Duration("duration", time.Since(start)))
+	return report, nil
+}
 
-	return resources, nil
+// RepairMissingRelationships recreates, from the stored property, the relationships the report found
+// missing, and marks those issues repaired. A property naming a node that no longer exists, or one
+// changed since the check, is left alone. Mismatches are never repaired since which side is right
+// cannot be told.
+func (dao *ResourceDAO) RepairMissingRelationships(ctx context.Context, report *model.IntegrityReport) (int, error) {
+	start := time.Now()
+	logger.Info("Repairing missing resource relationships")
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		repaired := map[string]bool{}
+		for _, ref := range resourceReferences {
+			var links []map[string]interface{}
+			for _, issue := range report.Issues {
+				if issue.Kind == model.IntegrityMissingRelationship && issue.Property == ref.property {
+					links = append(links, map[string]interface{}{"resourceID": issue.ResourceID, "targetID": issue.Stored})
+				}
+			}
+			if len(links) == 0 {
+				continue
+			}
+
+			query := `
+            UNWIND $links AS link
+            MATCH (r:` + echo_neo4j.LabelResource + ` {id: link.resourceID})
+            WHERE r.` + ref.property + ` = link.targetID
+            MATCH (n:` + ref.label + ` {id: link.targetID})
+            MERGE (r)-[:` + ref.relationship + `]->(n)
+            RETURN r.id
+            `
+			linkResult, err := transaction.Run(query, map[string]interface{}{"links": links})
+			if err != nil {
+				return nil, err
+			}
+			for linkResult.Next() {
+				repaired[ref.property+"/"+linkResult.Record().Values[0].(string)] = true
+			}
+		}
+		return repaired, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to repair resource relationships",
+			zap.Error(err),
+			zap.Duration("duration", duration))
+		return 0, classifyNeo4jError(err)
+	}
+
+	repaired, _ := result.(map[string]bool)
+	var resourceIDs []string
+	for i, issue := range report.Issues {
+		if repaired[issue.Property+"/"+issue.ResourceID] {
+			report.Issues[i].Repaired = true
+			report.Repaired++
+			resourceIDs = append(resourceIDs, issue.ResourceID)
+		}
+	}
+
+	logger.Info("Resource relationships repaired",
+		zap.Int("count", report.Repaired),
+		zap.Duration("duration", duration))
+
+	if report.Repaired > 0 {
+		changeDetails, _ := json.Marshal(map[string]interface{}{
+			"resourceIDs": resourceIDs,
+		})
+		auditLog := audit.AuditLog{
+			Timestamp:     time.Now(),
+			UserID:        helper_util.ActorFromContext(ctx),
+			Action:        "REPAIR_RESOURCE_RELATIONSHIPS",
+			AccessGranted: true,
+			ChangeDetails: changeDetails,
+		}
+		if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+			logger.Error("Failed to create audit log", zap.Error(err))
+		}
+	}
+
+	return report.Repaired, nil
+}
+
+// mergeTags appends the tags not already present to current, keeping their order
+func mergeTags(current, tags []string) []string {
+	merged := append([]string{}, current...)
+	for _, tag := range tags {
+		if !containsTag(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// subtractTags returns current without tags
+func subtractTags(current, tags []string) []string {
+	remaining := []string{}
+	for _, tag := range current {
+		if !containsTag(tags, tag) {
+			remaining = append(remaining, tag)
+		}
+	}
+	return remaining
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, candidate := range tags {
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
 }
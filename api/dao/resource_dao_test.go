@@ -0,0 +1,899 @@
+package dao_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestCreateResourceReferenceChecks(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	resource := model.Resource{
+		ID:               "r1",
+		Name:             "Quarterly Report",
+		OrganizationID:   "org1",
+		DepartmentID:     "dept1",
+		OwnerID:          "u1",
+		TypeID:           "rt1",
+		AttributeGroupID: "ag1",
+		ParentID:         "r0",
+		RelatedIDs:       []string{"r2"},
+	}
+
+	keys := []string{"orgExists", "ownerExists", "typeExists", "attributeGroupExists", "departmentExists", "parentExists", "missingRelatedIDs"}
+	allPresent := func() []interface{} {
+		return []interface{}{true, true, true, true, true, true, []interface{}{}}
+	}
+
+	tests := []struct {
+		name     string
+		values   func() []interface{}
+		expected error
+	}{
+		{"MissingOrganization", func() []interface{} { v := allPresent(); v[0] = false; return v }, echo_errors.ErrOrganizationNotFound},
+		{"MissingOwner", func() []interface{} { v := allPresent(); v[1] = false; return v }, echo_errors.ErrOwnerNotFound},
+		{"MissingResourceType", func() []interface{} { v := allPresent(); v[2] = false; return v }, echo_errors.ErrResourceTypeNotFound},
+		{"MissingAttributeGroup", func() []interface{} { v := allPresent(); v[3] = false; return v }, echo_errors.ErrAttributeGroupNotFound},
+		{"MissingDepartment", func() []interface{} { v := allPresent(); v[4] = false; return v }, echo_errors.ErrDepartmentNotFound},
+		{"MissingParent", func() []interface{} { v := allPresent(); v[5] = false; return v }, echo_errors.ErrParentResourceNotFound},
+		{"MissingRelated", func() []interface{} { v := allPresent(); v[6] = []interface{}{"r2"}; return v }, echo_errors.ErrRelatedResourceNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run("CreateResource_"+tc.name, func(t *testing.T) {
+			tx := &mock.MockTransaction{}
+			session := &mock.MockTxSession{Tx: tx}
+			session.On("Close").Return(nil)
+			driver := &mock.MockDriver{}
+			driver.On("NewSession", testify_mock.Anything).Return(session)
+			auditService := &mock.MockAuditService{}
+			resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: auditService}
+
+			checkResult := &mock.MockResult{}
+			checkResult.On("Next").Return(true).Once()
+			checkResult.On("Record").Return(&neo4j.Record{Keys: keys, Values: tc.values()})
+			tx.On("Run", queryContaining("orgExists"), testify_mock.Anything).Return(checkResult, nil)
+
+			resourceID, err := resourceDAO.CreateResource(ctx, resource)
+
+			assert.Equal(t, "", resourceID)
+			assert.Equal(t, tc.expected, err)
+			tx.AssertNotCalled(t, "Run", queryContaining("CREATE (r:RESOURCE"), testify_mock.Anything)
+			auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+		})
+	}
+}
+
+func resourceNode(id, createdAt string) neo4j.Node {
+	return mock.ResourceNode(id, map[string]any{
+		"name":      "Resource " + id,
+		"createdAt": createdAt,
+		"updatedAt": createdAt,
+	})
+}
+
+func TestListResourcesAfterIsStableUnderInserts(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	stored := []neo4j.Node{
+		resourceNode("r1", "2024-01-01T00:01:00Z"),
+		resourceNode("r2", "2024-01-01T00:02:00Z"),
+		resourceNode("r3", "2024-01-01T00:03:00Z"),
+		resourceNode("r4", "2024-01-01T00:03:00Z"), // Same timestamp as r3, ordered by id
+		resourceNode("r5", "2024-01-01T00:05:00Z"),
+	}
+
+	// The session answers each page by applying the keyset predicate and ordering to whatever is stored
+	// at the time of the call, the way the database would
+	result := &mock.MockResult{}
+	session := &mock.MockSession{}
+	session.On("Close").Return(nil)
+	session.On("Run", queryContaining("r.createdAt < $afterCreatedAt"), testify_mock.Anything, testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) {
+			params := args.Get(1).(map[string]interface{})
+			var page []neo4j.Node
+			for _, node := range stored {
+				createdAt, id := node.Props["createdAt"].(string), node.Props["id"].(string)
+				afterCreatedAt, _ := params["afterCreatedAt"].(string)
+				afterID, _ := params["afterID"].(string)
+				if params["afterCreatedAt"] == nil || createdAt < afterCreatedAt || (createdAt == afterCreatedAt && id < afterID) {
+					page = append(page, node)
+				}
+			}
+			sort.Slice(page, func(i, j int) bool {
+				ci, cj := page[i].Props["createdAt"].(string), page[j].Props["createdAt"].(string)
+				if ci != cj {
+					return ci > cj
+				}
+				return page[i].Props["id"].(string) > page[j].Props["id"].(string)
+			})
+			if limit := params["limit"].(int); len(page) > limit {
+				page = page[:limit]
+			}
+
+			result.ExpectedCalls = nil
+			for _, node := range page {
+				result.On("Next").Return(true).Once()
+				result.On("Record").Return(&neo4j.Record{
+					Keys:   []string{"r", "organizationID", "departmentID", "ownerID"},
+					Values: []any{node, "org1", nil, "u1"},
+				}).Once()
+			}
+			result.On("Next").Return(false)
+		}).
+		Return(result, nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	var seen []string
+	firstPage, next, err := resourceDAO.ListResourcesAfter(ctx, nil, 2)
+	assert.NoError(t, err)
+	for _, resource := range firstPage {
+		seen = append(seen, resource.ID)
+	}
+	assert.Equal(t, []string{"r5", "r4"}, seen)
+	if !assert.NotNil(t, next) {
+		return
+	}
+
+	// A resource created mid-iteration would shift every later offset page by one
+	stored = append(stored, resourceNode("r6", "2024-01-01T00:06:00Z"))
+
+	for next != nil {
+		var page []*model.Resource
+		page, next, err = resourceDAO.ListResourcesAfter(ctx, next, 2)
+		assert.NoError(t, err)
+		for _, resource := range page {
+			seen = append(seen, resource.ID)
+		}
+	}
+
+	assert.Equal(t, []string{"r5", "r4", "r3", "r2", "r1"}, seen)
+}
+
+func TestListResourcesFollowsCreatedRelationships(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	tx := &mock.MockTransaction{}
+	session := &mock.MockTxSession{Tx: tx}
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	auditService := &mock.MockAuditService{}
+	auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+	resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: auditService}
+
+	checkResult := &mock.MockResult{}
+	checkResult.On("Next").Return(true).Once()
+	checkResult.On("Record").Return(&neo4j.Record{
+		Keys:   []string{"orgExists", "ownerExists", "typeExists", "attributeGroupExists", "departmentExists", "parentExists", "missingRelatedIDs"},
+		Values: []interface{}{true, true, true, true, true, true, []interface{}{}},
+	})
+	tx.On("Run", queryContaining("orgExists"), testify_mock.Anything).Return(checkResult, nil)
+	var createQuery string
+	createResult := &mock.MockResult{}
+	createResult.On("Next").Return(true).Once()
+	createResult.On("Record").Return(&neo4j.Record{Keys: []string{"id", "name"}, Values: []any{"r1", "Quarterly Report"}})
+	tx.On("Run", queryContaining("CREATE (r:RESOURCE"), testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) { createQuery = args.String(0) }).
+		Return(createResult, nil)
+
+	resourceID, err := resourceDAO.CreateResource(ctx, model.Resource{
+		ID:               "r1",
+		Name:             "Quarterly Report",
+		OrganizationID:   "org1",
+		DepartmentID:     "dept1",
+		OwnerID:          "u1",
+		TypeID:           "rt1",
+		AttributeGroupID: "ag1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "r1", resourceID)
+
+	// The listing reaches a referenced node only through a relationship the create query wrote, the
+	// way the database would. The node's own ID properties are cleared so the IDs must come from there.
+	listed := &mock.MockResult{}
+	session.On("Run", queryContaining("AS organizationID"), testify_mock.Anything, testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) {
+			query := args.String(0)
+			linked := func(alias, id string) interface{} {
+				match := regexp.MustCompile(`\(r\)-\[:(\w+)\]->\(` + alias + `:`).FindStringSubmatch(query)
+				if match == nil || !strings.Contains(createQuery, "CREATE (r)-[:"+match[1]+"]->") {
+					return nil
+				}
+				return id
+			}
+			node := resourceNode("r1", "2024-01-01T00:00:00Z")
+			node.Props["organizationID"], node.Props["departmentID"], node.Props["ownerID"] = "", "", ""
+
+			listed.ExpectedCalls = nil
+			listed.On("Next").Return(true).Once()
+			listed.On("Record").Return(&neo4j.Record{
+				Keys:   []string{"r", "organizationID", "departmentID", "ownerID"},
+				Values: []any{node, linked("o", "org1"), linked("d", "dept1"), linked("u", "u1")},
+			}).Once()
+			listed.On("Next").Return(false)
+		}).
+		Return(listed, nil)
+
+	list := map[string]func() ([]*model.Resource, error){
+		"ListResources": func() ([]*model.Resource, error) {
+			return resourceDAO.ListResources(ctx, 10, 0)
+		},
+		"ListResourcesAfter": func() ([]*model.Resource, error) {
+			resources, _, err := resourceDAO.ListResourcesAfter(ctx, nil, 10)
+			return resources, err
+		},
+	}
+	for name, listResources := range list {
+		t.Run(name, func(t *testing.T) {
+			resources, err := listResources()
+
+			assert.NoError(t, err)
+			if assert.Len(t, resources, 1) {
+				assert.Equal(t, "org1", resources[0].OrganizationID)
+				assert.Equal(t, "dept1", resources[0].DepartmentID)
+				assert.Equal(t, "u1", resources[0].OwnerID)
+			}
+		})
+	}
+}
+
+// snapshotsVersion matches the writes that bump a resource's version and snapshot it at that version
+var snapshotsVersion = testify_mock.MatchedBy(func(query string) bool {
+	return strings.Contains(query, "r.version = coalesce(r.version, 0) + 1") &&
+		strings.Contains(query, "CREATE (r)-[:HAS_VERSION]->(v:RESOURCE_VERSION)")
+})
+
+func TestResourceTags(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newDAO := func() (*dao.ResourceDAO, *mock.MockTxSession, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+		return &dao.ResourceDAO{Driver: driver, AuditService: auditService}, session, tx, auditService
+	}
+
+	storedTags := func(tags ...interface{}) *mock.MockResult {
+		return resultWithRecord(tags)
+	}
+	writtenTags := func(tags ...string) interface{} {
+		return testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return assert.ObjectsAreEqual(tags, params["tags"])
+		})
+	}
+
+	t.Run("AddResourceTags_SkipsTagsAlreadyPresent", func(t *testing.T) {
+		resourceDAO, _, tx, _ := newDAO()
+		tx.On("Run", queryContaining("coalesce(r.tags, [])"), testify_mock.Anything).Return(storedTags("finance", "pii"), nil)
+		tx.On("Run", queryContaining("SET r.tags = $tags"), writtenTags("finance", "pii", "archived")).Return(&mock.MockResult{}, nil)
+
+		tags, err := resourceDAO.AddResourceTags(ctx, "r1", []string{"pii", "archived"}, "admin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"finance", "pii", "archived"}, tags)
+		tx.AssertCalled(t, "Run", snapshotsVersion, testify_mock.Anything)
+	})
+
+	t.Run("RemoveResourceTags_RecordsTheChange", func(t *testing.T) {
+		resourceDAO, _, tx, _ := newDAO()
+		tx.On("Run", queryContaining("coalesce(r.tags, [])"), testify_mock.Anything).Return(storedTags("finance", "pii"), nil)
+		tx.On("Run", queryContaining("SET r.tags = $tags"), testify_mock.Anything).Return(&mock.MockResult{}, nil)
+
+		_, err := resourceDAO.RemoveResourceTags(context.Background(), "r1", []string{"pii"}, "u2")
+
+		assert.NoError(t, err)
+		tx.AssertCalled(t, "Run", queryContaining("r.updatedBy = $updatedBy, r.updatedAt = $updatedAt"), testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			updatedAt, _ := params["updatedAt"].(string)
+			_, err := time.Parse(time.RFC3339, updatedAt)
+			return params["updatedBy"] == "u2" && err == nil
+		}))
+	})
+
+	t.Run("AddResourceTags_AddingTwiceIsIdempotent", func(t *testing.T) {
+		resourceDAO, _, tx, _ := newDAO()
+		tx.On("Run", queryContaining("coalesce(r.tags, [])"), testify_mock.Anything).Return(storedTags("pii"), nil)
+
+		tags, err := resourceDAO.AddResourceTags(ctx, "r1", []string{"pii"}, "admin")
+
+		// Nothing changed, so no version is added
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"pii"}, tags)
+		tx.AssertNotCalled(t, "Run", queryContaining("SET r.tags = $tags"), testify_mock.Anything)
+	})
+
+	t.Run("RemoveResourceTags_IgnoresMissingTags", func(t *testing.T) {
+		resourceDAO, _, tx, _ := newDAO()
+		tx.On("Run", queryContaining("coalesce(r.tags, [])"), testify_mock.Anything).Return(storedTags("finance", "pii"), nil)
+		tx.On("Run", queryContaining("SET r.tags = $tags"), writtenTags("finance")).Return(&mock.MockResult{}, nil)
+
+		tags, err := resourceDAO.RemoveResourceTags(ctx, "r1", []string{"pii", "unknown"}, "admin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"finance"}, tags)
+	})
+
+	t.Run("AddResourceTags_ResourceNotFound", func(t *testing.T) {
+		resourceDAO, _, tx, auditService := newDAO()
+		result := &mock.MockResult{}
+		result.On("Next").Return(false)
+		tx.On("Run", queryContaining("coalesce(r.tags, [])"), testify_mock.Anything).Return(result, nil)
+
+		tags, err := resourceDAO.AddResourceTags(ctx, "missing", []string{"pii"}, "admin")
+
+		assert.Nil(t, tags)
+		assert.Equal(t, echo_errors.ErrResourceNotFound, err)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("ListAllTags_CountsResourcesPerTag", func(t *testing.T) {
+		resourceDAO, session, _, _ := newDAO()
+		result := &mock.MockResult{}
+		result.On("Next").Return(true).Twice()
+		result.On("Next").Return(false)
+		result.On("Record").Return(&neo4j.Record{Values: []any{"pii", int64(3)}}).Once()
+		result.On("Record").Return(&neo4j.Record{Values: []any{"finance", int64(1)}}).Once()
+		session.On("Run", queryContaining("count(DISTINCT r) AS count"), testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+
+		tagCounts, err := resourceDAO.ListAllTags(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TagCount{{Tag: "pii", Count: 3}, {Tag: "finance", Count: 1}}, tagCounts)
+	})
+}
+
+func TestBulkTagResources(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+	criteria := model.ResourceSearchCriteria{Type: "database"}
+
+	newDAO := func() (*dao.ResourceDAO, *mock.MockTxSession, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		return &dao.ResourceDAO{Driver: driver, AuditService: auditService}, session, tx, auditService
+	}
+
+	t.Run("TagsMatchingResources", func(t *testing.T) {
+		resourceDAO, _, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("RETURN count(DISTINCT r)"), map[string]interface{}{"type": "database"}).
+			Return(resultWithRecord(int64(3)), nil)
+		tagResult := &mock.MockResult{}
+		tagResult.On("Next").Return(true).Twice()
+		tagResult.On("Next").Return(false)
+		tagResult.On("Record").Return(&neo4j.Record{Values: []any{"r1"}}).Once()
+		tagResult.On("Record").Return(&neo4j.Record{Values: []any{"r2"}}).Once()
+		tx.On("Run", queryContaining("tag IN $bulkTags"), testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["type"] == "database" && assert.ObjectsAreEqual([]string{"pii-review"}, params["bulkTags"])
+		})).Return(tagResult, nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+		resourceIDs, err := resourceDAO.BulkTagResources(ctx, criteria, []string{"pii-review"}, 10)
+
+		// r3 matched but already carried the tag, so only r1 and r2 were affected
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"r1", "r2"}, resourceIDs)
+		tx.AssertCalled(t, "Run", snapshotsVersion, testify_mock.Anything)
+		auditService.AssertNumberOfCalls(t, "LogAccess", 1)
+	})
+
+	t.Run("MatchingMoreThanTheCapTagsNothing", func(t *testing.T) {
+		resourceDAO, _, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("RETURN count(DISTINCT r)"), testify_mock.Anything).
+			Return(resultWithRecord(int64(11)), nil)
+
+		resourceIDs, err := resourceDAO.BulkTagResources(ctx, criteria, []string{"pii-review"}, 10)
+
+		assert.Nil(t, resourceIDs)
+		assert.True(t, errors.Is(err, echo_errors.ErrBulkLimitExceeded))
+		tx.AssertNotCalled(t, "Run", queryContaining("tag IN $bulkTags"), testify_mock.Anything)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("CriteriaWithoutFiltersAreRefused", func(t *testing.T) {
+		resourceDAO, session, tx, _ := newDAO()
+
+		resourceIDs, err := resourceDAO.BulkTagResources(ctx, model.ResourceSearchCriteria{Limit: 50}, []string{"pii-review"}, 10)
+
+		assert.Nil(t, resourceIDs)
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidSearchCriteria))
+		session.AssertNotCalled(t, "Close")
+		tx.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything)
+	})
+}
+
+func TestTransferResourceOwnership(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newDAO := func() (*dao.ResourceDAO, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		return &dao.ResourceDAO{Driver: driver, AuditService: auditService}, tx, auditService
+	}
+
+	t.Run("TransferToNonexistentUser", func(t *testing.T) {
+		resourceDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("AS ownerExists"), map[string]interface{}{"id": "r1", "newOwnerID": "ghost"}).
+			Return(resultWithRecord(true, false, "u1"), nil)
+
+		resource, err := resourceDAO.TransferResourceOwnership(ctx, "r1", "ghost", "admin")
+
+		assert.Nil(t, resource)
+		assert.Equal(t, echo_errors.ErrUserNotFound, err)
+		tx.AssertNotCalled(t, "Run", queryContaining("DELETE oldOwnerRel"), testify_mock.Anything)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("TransferOfNonexistentResource", func(t *testing.T) {
+		resourceDAO, tx, _ := newDAO()
+		tx.On("Run", queryContaining("AS ownerExists"), testify_mock.Anything).
+			Return(resultWithRecord(false, true, nil), nil)
+
+		_, err := resourceDAO.TransferResourceOwnership(ctx, "missing", "u2", "admin")
+
+		assert.Equal(t, echo_errors.ErrResourceNotFound, err)
+	})
+
+	t.Run("SuccessfulTransfer", func(t *testing.T) {
+		resourceDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("AS ownerExists"), testify_mock.Anything).
+			Return(resultWithRecord(true, true, "u1"), nil)
+		transferred := resourceNode("r1", "2024-01-01T00:00:00Z")
+		transferred.Props["ownerID"] = "u2"
+		transferred.Props["updatedBy"] = "admin"
+		tx.On("Run", queryContaining("CREATE (r)-[:OWNED_BY]->(u)"), testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["newOwnerID"] == "u2" && params["updatedBy"] == "admin"
+		})).Return(resultWithRecord(transferred), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "TRANSFER_RESOURCE_OWNERSHIP" &&
+				string(log.ChangeDetails) == `{"ownerID":{"new":"u2","old":"u1"}}`
+		})).Return(nil)
+
+		resource, err := resourceDAO.TransferResourceOwnership(ctx, "r1", "u2", "admin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "u2", resource.OwnerID)
+		auditService.AssertExpectations(t)
+		tx.AssertCalled(t, "Run", snapshotsVersion, testify_mock.Anything)
+	})
+}
+
+func TestGetResourcesByOrganizationIsolation(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	stored := []neo4j.Node{
+		resourceNode("r1", "2024-01-01T00:01:00Z"),
+		resourceNode("r2", "2024-01-01T00:02:00Z"),
+		resourceNode("r3", "2024-01-01T00:03:00Z"),
+		resourceNode("r4", "2024-01-01T00:04:00Z"),
+	}
+	stored[1].Props["organizationID"] = "org2"
+	stored[3].Props["organizationID"] = "org2"
+
+	belongsTo := func(params map[string]interface{}) []neo4j.Node {
+		var nodes []neo4j.Node
+		for _, node := range stored {
+			if node.Props["organizationID"] == params["orgID"] {
+				nodes = append(nodes, node)
+			}
+		}
+		return nodes
+	}
+
+	// The session answers both queries by following BELONGS_TO from the requested organization only
+	result := &mock.MockResult{}
+	session := &mock.MockSession{}
+	session.On("Close").Return(nil)
+	session.On("Run", queryContaining("count(r) AS total"), testify_mock.Anything, testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) {
+			nodes := belongsTo(args.Get(1).(map[string]interface{}))
+			result.ExpectedCalls = nil
+			result.On("Next").Return(true).Once()
+			result.On("Next").Return(false)
+			result.On("Record").Return(&neo4j.Record{Values: []any{true, int64(len(nodes))}}).Once()
+		}).Return(result, nil)
+	session.On("Run", queryContaining("{id: $orgID})<-[:BELONGS_TO]-(r:"), testify_mock.Anything, testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) {
+			nodes := belongsTo(args.Get(1).(map[string]interface{}))
+			sort.Slice(nodes, func(i, j int) bool {
+				return nodes[i].Props["createdAt"].(string) > nodes[j].Props["createdAt"].(string)
+			})
+			result.ExpectedCalls = nil
+			for _, node := range nodes {
+				result.On("Next").Return(true).Once()
+				result.On("Record").Return(&neo4j.Record{Values: []any{node}}).Once()
+			}
+			result.On("Next").Return(false)
+		}).Return(result, nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	for orgID, expected := range map[string][]string{"org1": {"r3", "r1"}, "org2": {"r4", "r2"}} {
+		resources, total, err := resourceDAO.GetResourcesByOrganization(ctx, orgID, false, 10, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total, orgID)
+		var ids []string
+		for _, resource := range resources {
+			assert.Equal(t, orgID, resource.OrganizationID)
+			ids = append(ids, resource.ID)
+		}
+		assert.Equal(t, expected, ids, orgID)
+	}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	tx := &mock.MockTransaction{}
+	session := &mock.MockTxSession{Tx: tx}
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	auditService := &mock.MockAuditService{}
+	resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: auditService}
+
+	// r1 is consistent, r2 lost its BELONGS_TO relationship and r3 is owned by someone other than its
+	// ownerID says. Values follow the id, then the property and linked IDs of organization, department,
+	// owner, type and attribute group.
+	links := func(ids ...interface{}) []interface{} { return append([]interface{}{}, ids...) }
+	stored := [][]any{
+		{"r1", "org1", links("org1"), "", links(), "u1", links("u1"), "rt1", links("rt1"), "ag1", links("ag1")},
+		{"r2", "org1", links(), "dept1", links("dept1"), "u1", links("u1"), "rt1", links("rt1"), "ag1", links("ag1")},
+		{"r3", "org1", links("org1"), "", links(), "u1", links("u2"), "rt1", links("rt1"), "ag1", links("ag1")},
+	}
+	result := &mock.MockResult{}
+	session.On("Run", queryContaining("AS organizationIDLinks"), testify_mock.Anything, testify_mock.Anything).
+		Run(func(testify_mock.Arguments) {
+			result.ExpectedCalls = nil
+			for _, values := range stored {
+				result.On("Next").Return(true).Once()
+				result.On("Record").Return(&neo4j.Record{Values: values}).Once()
+			}
+			result.On("Next").Return(false)
+		}).
+		Return(result, nil)
+
+	report, err := resourceDAO.CheckIntegrity(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.ResourcesChecked)
+	assert.Equal(t, []model.IntegrityIssue{
+		{ResourceID: "r2", Property: "organizationID", Relationship: "BELONGS_TO", Kind: model.IntegrityMissingRelationship, Stored: "org1", Linked: []string{}},
+		{ResourceID: "r3", Property: "ownerID", Relationship: "OWNED_BY", Kind: model.IntegrityMismatch, Stored: "u1", Linked: []string{"u2"}},
+	}, report.Issues)
+
+	t.Run("Repair", func(t *testing.T) {
+		linked := resultWithRecord("r2")
+		linked.On("Next").Return(false)
+		tx.On("Run", queryContaining("MERGE (r)-[:BELONGS_TO]->(n)"), map[string]interface{}{
+			"links": []map[string]interface{}{{"resourceID": "r2", "targetID": "org1"}},
+		}).Return(linked, nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "REPAIR_RESOURCE_RELATIONSHIPS"
+		})).Return(nil)
+
+		repaired, err := resourceDAO.RepairMissingRelationships(ctx, report)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, repaired)
+		assert.True(t, report.Issues[0].Repaired)
+		assert.False(t, report.Issues[1].Repaired)
+		tx.AssertNotCalled(t, "Run", queryContaining("OWNED_BY"), testify_mock.Anything)
+		auditService.AssertExpectations(t)
+	})
+}
+
+func TestGetResourceDiff(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	// Snapshots carry the resource's properties at each version
+	first := resourceNode("r1", "2024-01-01T00:00:00Z")
+	first.Props["resourceID"] = "r1"
+	second := resourceNode("r1", "2024-01-01T00:00:00Z")
+	second.Props["resourceID"] = "r1"
+	second.Props["version"] = int64(2)
+	second.Props["name"] = "Quarterly Report"
+	second.Props["sensitivity"] = "confidential"
+	second.Props["updatedAt"] = "2024-02-01T00:00:00Z"
+
+	versions := func(nodes ...neo4j.Node) *mock.MockResult {
+		result := &mock.MockResult{}
+		for _, node := range nodes {
+			result.On("Next").Return(true).Once()
+			result.On("Record").Return(&neo4j.Record{Values: []any{node.Props["version"], node}}).Once()
+		}
+		result.On("Next").Return(false)
+		return result
+	}
+	session := &mock.MockSession{}
+	session.On("Run", queryContaining(echo_neo4j.RelHasVersion),
+		map[string]interface{}{"resourceID": "r1", "fromVersion": 1, "toVersion": 2}, testify_mock.Anything).
+		Return(versions(first, second), nil)
+	session.On("Run", queryContaining(echo_neo4j.RelHasVersion),
+		map[string]interface{}{"resourceID": "r1", "fromVersion": 1, "toVersion": 3}, testify_mock.Anything).
+		Return(versions(first), nil)
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	t.Run("Two versions", func(t *testing.T) {
+		diff, err := resourceDAO.GetResourceDiff(ctx, "r1", 1, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &model.ResourceDiff{
+			ResourceID:  "r1",
+			FromVersion: 1,
+			ToVersion:   2,
+			Changes: []model.FieldChange{
+				{Field: "name", Old: "Resource r1", New: "Quarterly Report"},
+				{Field: "sensitivity", Old: "", New: "confidential"},
+			},
+		}, diff)
+	})
+
+	t.Run("Missing version", func(t *testing.T) {
+		_, err := resourceDAO.GetResourceDiff(ctx, "r1", 1, 3)
+
+		assert.ErrorIs(t, err, echo_errors.ErrResourceVersionNotFound)
+	})
+}
+
+func TestGetInheritingAncestors(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ancestry := &mock.MockResult{}
+	for _, id := range []string{"alpha", "projects"} {
+		ancestry.On("Next").Return(true).Once()
+		ancestry.On("Record").Return(&neo4j.Record{Values: []any{resourceNode(id, "2024-01-01T00:00:00Z")}}).Once()
+	}
+	ancestry.On("Next").Return(false)
+	session := &mock.MockSession{}
+	session.On("Close").Return(nil)
+	// Only CHILD_OF paths through inheriting children, at most two levels up, are followed
+	session.On("Run", queryContaining("-[:"+echo_neo4j.RelChildOf+"*1..2]->(ancestor:"), testify_mock.Anything, testify_mock.Anything).
+		Return(ancestry, nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	ancestors, err := resourceDAO.GetInheritingAncestors(context.Background(), "plan", 2)
+
+	assert.NoError(t, err)
+	if assert.Len(t, ancestors, 2) {
+		assert.Equal(t, "alpha", ancestors[0].ID)
+		assert.Equal(t, "projects", ancestors[1].ID)
+	}
+	session.AssertCalled(t, "Run", queryContaining("child.inheritedACL = true"), testify_mock.Anything, testify_mock.Anything)
+
+	// Inheritance turned off never reaches the database
+	ancestors, err = resourceDAO.GetInheritingAncestors(context.Background(), "plan", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, ancestors)
+	session.AssertNumberOfCalls(t, "Run", 1)
+}
+
+func TestCreateResourceDuplicateID(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	tx := &mock.MockTransaction{}
+	session := &mock.MockTxSession{Tx: tx}
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	auditService := &mock.MockAuditService{}
+	auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+	resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: auditService}
+
+	checkResult := func() *mock.MockResult {
+		result := &mock.MockResult{}
+		result.On("Next").Return(true).Once()
+		result.On("Record").Return(&neo4j.Record{
+			Keys:   []string{"orgExists", "ownerExists", "typeExists", "attributeGroupExists", "departmentExists", "parentExists", "missingRelatedIDs"},
+			Values: []interface{}{true, true, true, true, true, true, []interface{}{}},
+		})
+		return result
+	}
+	tx.On("Run", queryContaining("orgExists"), testify_mock.Anything).Return(checkResult(), nil).Once()
+	tx.On("Run", queryContaining("orgExists"), testify_mock.Anything).Return(checkResult(), nil).Once()
+	// The first create takes the ID; the uniqueness constraint rejects the second
+	createResult := &mock.MockResult{}
+	createResult.On("Next").Return(true).Once()
+	createResult.On("Record").Return(&neo4j.Record{Keys: []string{"id", "name"}, Values: []any{"r1", "Quarterly Report"}})
+	tx.On("Run", queryContaining("CREATE (r:"+echo_neo4j.LabelResource), testify_mock.Anything).Return(createResult, nil).Once()
+	tx.On("Run", queryContaining("CREATE (r:"+echo_neo4j.LabelResource), testify_mock.Anything).
+		Return(nil, &neo4j.Neo4jError{
+			Code: "Neo.ClientError.Schema.ConstraintValidationFailed",
+			Msg:  "Node(7) already exists with label `RESOURCE` and property `id` = 'r1'",
+		}).Once()
+
+	resource := model.Resource{ID: "r1", Name: "Quarterly Report", OrganizationID: "org1", OwnerID: "u1", TypeID: "rt1", AttributeGroupID: "ag1"}
+	resourceID, err := resourceDAO.CreateResource(ctx, resource)
+	assert.NoError(t, err)
+	assert.Equal(t, "r1", resourceID)
+
+	resource.Name = "Another Report"
+	resourceID, err = resourceDAO.CreateResource(ctx, resource)
+
+	assert.Equal(t, "", resourceID)
+	assert.ErrorIs(t, err, echo_errors.ErrResourceConflict)
+	status, code := util.MapError(err)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "RESOURCE_CONFLICT", code)
+	auditService.AssertNumberOfCalls(t, "LogAccess", 1)
+}
+
+func TestResourceDAOAgainstFakeDriver(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+	getQuery := "OPTIONAL MATCH (r)-[:" + echo_neo4j.RelChildOf + "]->"
+	deleteQuery := "DETACH DELETE r"
+
+	t.Run("GetResource maps the node, its parent and related resources", func(t *testing.T) {
+		node := resourceNode("r1", "2024-01-01T00:00:00Z")
+		node.Props["tags"] = []any{"finance", "q1"}
+		node.Props["attributes"] = `{"project":"apollo"}`
+		driver := mock.NewFakeDriver().Returns(getQuery, &neo4j.Record{
+			Keys:   []string{"r", "parentID", "relatedIDs"},
+			Values: []any{node, "folder", []any{"r2", "r3"}},
+		})
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		resource, err := resourceDAO.GetResource(ctx, "r1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "r1", resource.ID)
+		assert.Equal(t, "DOCUMENT", resource.Type)
+		assert.Equal(t, []string{"finance", "q1"}, resource.Tags)
+		assert.Equal(t, "apollo", resource.Attributes["project"])
+		assert.Equal(t, "folder", resource.ParentID)
+		assert.Equal(t, []string{"r2", "r3"}, resource.RelatedIDs)
+		queries := driver.QueriesContaining(getQuery)
+		if assert.Len(t, queries, 1) {
+			assert.Equal(t, map[string]any{"id": "r1"}, queries[0].Params)
+			assert.False(t, queries[0].Write)
+		}
+	})
+
+	t.Run("GetResource reports a missing resource", func(t *testing.T) {
+		resourceDAO := &dao.ResourceDAO{Driver: mock.NewFakeDriver(), AuditService: &mock.MockAuditService{}}
+
+		_, err := resourceDAO.GetResource(ctx, "missing")
+
+		assert.ErrorIs(t, err, echo_errors.ErrResourceNotFound)
+	})
+
+	t.Run("GetResource reports a node that does not map", func(t *testing.T) {
+		node := resourceNode("r1", "2024-01-01T00:00:00Z")
+		node.Props["metadata"] = "{not json"
+		driver := mock.NewFakeDriver().Returns(getQuery, &neo4j.Record{
+			Keys:   []string{"r", "parentID", "relatedIDs"},
+			Values: []any{node, nil, []any{}},
+		})
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		_, err := resourceDAO.GetResource(ctx, "r1")
+
+		assert.ErrorIs(t, err, echo_errors.ErrInternalServer)
+	})
+
+	t.Run("GetResource reports a failing query", func(t *testing.T) {
+		driver := mock.NewFakeDriver().Fails(getQuery, errors.New("connection reset"))
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		_, err := resourceDAO.GetResource(ctx, "r1")
+
+		assert.ErrorIs(t, err, echo_errors.ErrDatabaseOperation)
+	})
+
+	t.Run("DeleteResource deletes the node and audits it", func(t *testing.T) {
+		driver := mock.NewFakeDriver().On(deleteQuery, mock.FakeResponse{NodesDeleted: 1})
+		auditService := &mock.MockAuditService{}
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "DELETE_RESOURCE" && log.ResourceID == "r1"
+		})).Return(nil).Once()
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: auditService}
+
+		err := resourceDAO.DeleteResource(ctx, "r1")
+
+		assert.NoError(t, err)
+		queries := driver.QueriesContaining(deleteQuery)
+		if assert.Len(t, queries, 1) {
+			assert.True(t, queries[0].Write)
+		}
+		assert.Equal(t, 1, driver.Commits())
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("DeleteResource reports a missing resource and rolls back", func(t *testing.T) {
+		driver := mock.NewFakeDriver()
+		auditService := &mock.MockAuditService{}
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: auditService}
+
+		err := resourceDAO.DeleteResource(ctx, "missing")
+
+		assert.ErrorIs(t, err, echo_errors.ErrResourceNotFound)
+		assert.Equal(t, 1, driver.Rollbacks())
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("DeleteResource classifies a transient failure", func(t *testing.T) {
+		driver := mock.NewFakeDriver().Fails(deleteQuery, &neo4j.Neo4jError{
+			Code: "Neo.TransientError.General.DatabaseUnavailable",
+			Msg:  "database unavailable",
+		})
+		resourceDAO := &dao.ResourceDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+		err := resourceDAO.DeleteResource(ctx, "r1")
+
+		assert.ErrorIs(t, err, echo_errors.ErrTransient)
+		status, code := util.MapError(err)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, "DATABASE_UNAVAILABLE", code)
+	})
+}
@@ -16,14 +16,15 @@ import (
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 type ResourceTypeDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewResourceTypeDAO(driver neo4j.Driver, auditService audit.Service) *ResourceTypeDAO {
+func NewResourceTypeDAO(driver Neo4jDriver, auditService audit.Service) *ResourceTypeDAO {
 	dao := &ResourceTypeDAO{Driver: driver, AuditService: auditService}
 	ctx := context.Background()
 	if err := dao.EnsureUniqueConstraint(ctx); err != nil {
@@ -67,6 +68,8 @@ func (dao *ResourceTypeDAO) CreateResourceType(ctx context.Context, resourceType
 
 	resourceType.CreatedAt = time.Now()
 	resourceType.UpdatedAt = time.Now()
+	resourceType.CreatedBy = changedBy(ctx, "")
+	resourceType.UpdatedBy = resourceType.CreatedBy
 
 	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
@@ -110,7 +113,7 @@ func (dao *ResourceTypeDAO) CreateResourceType(ctx context.Context, resourceType
 			zap.Error(err),
 			zap.String("name", resourceType.Name),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrResourceTypeConflict, resourceType.ID)
 	}
 
 	resourceTypeID := fmt.Sprintf("%v", result)
@@ -121,7 +124,7 @@ func (dao *ResourceTypeDAO) CreateResourceType(ctx context.Context, resourceType
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        resourceType.CreatedBy,
 		Action:        "CREATE_RESOURCE_TYPE",
 		ResourceID:    resourceTypeID,
 		AccessGranted: true,
@@ -141,6 +144,7 @@ func (dao *ResourceTypeDAO) UpdateResourceType(ctx context.Context, resourceType
 	defer session.Close()
 
 	resourceType.UpdatedAt = time.Now()
+	resourceType.UpdatedBy = changedBy(ctx, "")
 
 	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
@@ -179,7 +183,7 @@ func (dao *ResourceTypeDAO) UpdateResourceType(ctx context.Context, resourceType
 			zap.Error(err),
 			zap.String("id", resourceType.ID),
 			zap.Duration("duration", duration))
-		return nil, err
+		return nil, classifyNeo4jError(err)
 	}
 
 	updatedResourceType := result.(*model.ResourceType)
@@ -190,7 +194,7 @@ func (dao *ResourceTypeDAO) UpdateResourceType(ctx context.Context, resourceType
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        resourceType.UpdatedBy,
 		Action:        "UPDATE_RESOURCE_TYPE",
 		ResourceID:    updatedResourceType.ID,
 		AccessGranted: true,
@@ -202,6 +206,11 @@ func (dao *ResourceTypeDAO) UpdateResourceType(ctx context.Context, resourceType
 	return updatedResourceType, nil
 }
 
+// Exists reports whether a resource type with the given ID exists, without reading the whole node
+func (dao *ResourceTypeDAO) Exists(ctx context.Context, id string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelResourceType, id)
+}
+
 func (dao *ResourceTypeDAO) GetResourceType(ctx context.Context, id string) (*model.ResourceType, error) {
 	start := time.Now()
 	logger.Info("Retrieving resource type", zap.String("id", id))
@@ -356,7 +365,7 @@ func (dao *ResourceTypeDAO) DeleteResourceType(ctx context.Context, id string) e
 			zap.Error(err),
 			zap.String("id", id),
 			zap.Duration("duration", duration))
-		return err
+		return classifyNeo4jError(err)
 	}
 
 	logger.Info("Resource type deleted successfully",
@@ -366,10 +375,11 @@ func (dao *ResourceTypeDAO) DeleteResourceType(ctx context.Context, id string) e
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        helper_util.ActorFromContext(ctx),
 		Action:        "DELETE_RESOURCE_TYPE",
 		ResourceID:    id,
 		AccessGranted: true,
+		ChangeDetails: deletedDetails(helper_util.ActorFromContext(ctx), nil),
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
@@ -20,11 +20,11 @@ import (
 )
 
 type RoleDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewRoleDAO(driver neo4j.Driver, auditService audit.Service) *RoleDAO {
+func NewRoleDAO(driver Neo4jDriver, auditService audit.Service) *RoleDAO {
 	dao := &RoleDAO{Driver: driver, AuditService: auditService}
 	ctx := context.Background()
 	if err := dao.EnsureUniqueConstraint(ctx); err != nil {
@@ -65,16 +65,20 @@ func (dao *RoleDAO) CreateRole(ctx context.Context, role model.Role) (string, er
 	if role.ID == "" {
 		role.ID = uuid.New().String()
 	}
+	role.CreatedBy = changedBy(ctx, "")
+	role.UpdatedBy = role.CreatedBy
 
 	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
-			MERGE (r:` + echo_neo4j.LabelRole + ` {id: $id})
-			ON CREATE SET 
+			CREATE (r:` + echo_neo4j.LabelRole + ` {id: $id})
+			SET
 				r.name = $name,
 				r.description = $description,
 				r.organizationID = $organizationID,
 				r.createdAt = $createdAt,
-				r.updatedAt = $updatedAt
+				r.updatedAt = $updatedAt,
+				r.createdBy = $createdBy,
+				r.updatedBy = $updatedBy
 		`
 
 		if role.DepartmentID != "" {
@@ -124,6 +128,8 @@ func (dao *RoleDAO) CreateRole(ctx context.Context, role model.Role) (string, er
 			"organizationID": role.OrganizationID,
 			"createdAt":      now,
 			"updatedAt":      now,
+			"createdBy":      role.CreatedBy,
+			"updatedBy":      role.UpdatedBy,
 		}
 
 		if role.DepartmentID != "" {
@@ -148,7 +154,7 @@ func (dao *RoleDAO) CreateRole(ctx context.Context, role model.Role) (string, er
 		result, err := transaction.Run(query, params)
 		if err != nil {
 			logger.Error("Failed to execute create role query", zap.Error(err))
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
@@ -169,7 +175,7 @@ func (dao *RoleDAO) CreateRole(ctx context.Context, role model.Role) (string, er
 			zap.Error(err),
 			zap.String("roleName", role.Name),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrRoleConflict, role.ID)
 	}
 
 	roleID := fmt.Sprintf("%v", result)
@@ -180,7 +186,7 @@ func (dao *RoleDAO) CreateRole(ctx context.Context, role model.Role) (string, er
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        role.CreatedBy,
 		Action:        "CREATE_ROLE",
 		ResourceID:    roleID,
 		AccessGranted: true,
@@ -200,6 +206,7 @@ func (dao *RoleDAO) UpdateRole(ctx context.Context, role model.Role) (*model.Rol
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
+	role.UpdatedBy = changedBy(ctx, "")
 	var updatedRole *model.Role
 	oldRole, err := dao.GetRole(ctx, role.ID)
 	if err != nil {
@@ -245,6 +252,7 @@ func (dao *RoleDAO) UpdateRole(ctx context.Context, role model.Role) (*model.Rol
 				"departmentID":           role.DepartmentID,
 				"attributes":             string(attributesJSON),
 				echo_neo4j.AttrUpdatedAt: time.Now().Format(time.RFC3339),
+				echo_neo4j.AttrUpdatedBy: role.UpdatedBy,
 			},
 			"organizationID": role.OrganizationID,
 			"departmentID":   role.DepartmentID,
@@ -257,7 +265,7 @@ func (dao *RoleDAO) UpdateRole(ctx context.Context, role model.Role) (*model.Rol
 		if err != nil {
 			// Log the error
 			logger.Error("Failed to execute update role query", zap.Error(err))
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
@@ -295,7 +303,7 @@ func (dao *RoleDAO) UpdateRole(ctx context.Context, role model.Role) (*model.Rol
 			zap.Error(err),
 			zap.String("roleID", role.ID),
 			zap.Duration("duration", duration))
-		return nil, err
+		return nil, classifyNeo4jError(err)
 	}
 
 	logger.Info("Role updated successfully",
@@ -305,7 +313,7 @@ func (dao *RoleDAO) UpdateRole(ctx context.Context, role model.Role) (*model.Rol
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        role.UpdatedBy,
 		Action:        "UPDATE_ROLE",
 		ResourceID:    role.ID,
 		AccessGranted: true,
@@ -318,30 +326,50 @@ func (dao *RoleDAO) UpdateRole(ctx context.Context, role model.Role) (*model.Rol
 	return updatedRole, nil
 }
 
-func (dao *RoleDAO) DeleteRole(ctx context.Context, roleID string) error {
+// DeleteRole deletes a role. A role still assigned to users or groups is only deleted when force is set,
+// in which case those assignments are removed along with it.
+func (dao *RoleDAO) DeleteRole(ctx context.Context, roleID string, force bool) error {
 	start := time.Now()
-	logger.Info("Deleting role", zap.String("roleID", roleID))
+	logger.Info("Deleting role", zap.String("roleID", roleID), zap.Bool("force", force))
 
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
+	var userCount, groupCount int64
 	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
-		query := `
+		checkQuery := `
         MATCH (r:` + echo_neo4j.LabelRole + ` {id: $id})
-        DETACH DELETE r
+        RETURN r.id AS id,
+            COUNT { (:` + echo_neo4j.LabelUser + `)-[:` + echo_neo4j.RelHasRole + `]->(r) } AS userCount,
+            COUNT { (:` + echo_neo4j.LabelGroup + `)-[:` + echo_neo4j.RelHasRole + `]->(r) } AS groupCount
         `
-		result, err := transaction.Run(query, map[string]interface{}{"id": roleID})
+		result, err := transaction.Run(checkQuery, map[string]interface{}{"id": roleID})
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
-		summary, err := result.Consume()
-		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+		if !result.Next() {
+			return nil, echo_errors.ErrRoleNotFound
 		}
 
-		if summary.Counters().NodesDeleted() == 0 {
-			return nil, echo_errors.ErrRoleNotFound
+		record := result.Record()
+		userCount, _ = record.Values[1].(int64)
+		groupCount, _ = record.Values[2].(int64)
+		if (userCount > 0 || groupCount > 0) && !force {
+			logger.Warn("Role is still in use",
+				zap.String("roleID", roleID),
+				zap.Int64("userCount", userCount),
+				zap.Int64("groupCount", groupCount))
+			return nil, echo_errors.ErrRoleInUse
+		}
+
+		deleteQuery := `
+        MATCH (r:` + echo_neo4j.LabelRole + ` {id: $id})
+        DETACH DELETE r
+        RETURN count(*) AS deleted
+        `
+		if _, err := transaction.Run(deleteQuery, map[string]interface{}{"id": roleID}); err != nil {
+			return nil, classifyNeo4jError(err)
 		}
 
 		return nil, nil
@@ -353,7 +381,7 @@ func (dao *RoleDAO) DeleteRole(ctx context.Context, roleID string) error {
 			zap.Error(err),
 			zap.String("roleID", roleID),
 			zap.Duration("duration", duration))
-		return err
+		return classifyNeo4jError(err)
 	}
 
 	logger.Info("Role deleted successfully",
@@ -361,12 +389,18 @@ func (dao *RoleDAO) DeleteRole(ctx context.Context, roleID string) error {
 		zap.Duration("duration", duration))
 
 	// Audit trail
+	changeDetails := deletedDetails(helper_util.ActorFromContext(ctx), map[string]interface{}{
+		"force":          force,
+		"detachedUsers":  userCount,
+		"detachedGroups": groupCount,
+	})
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        helper_util.ActorFromContext(ctx),
 		Action:        "DELETE_ROLE",
 		ResourceID:    roleID,
 		AccessGranted: true,
+		ChangeDetails: changeDetails,
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
@@ -375,6 +409,11 @@ func (dao *RoleDAO) DeleteRole(ctx context.Context, roleID string) error {
 	return nil
 }
 
+// Exists reports whether a role with the given ID exists, without reading the whole node
+func (dao *RoleDAO) Exists(ctx context.Context, roleID string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelRole, roleID)
+}
+
 func (dao *RoleDAO) GetRole(ctx context.Context, roleID string) (*model.Role, error) {
 	start := time.Now()
 	logger.Info("Retrieving role", zap.String("roleID", roleID))
@@ -482,7 +521,220 @@ func (dao *RoleDAO) AssignPermissionToRole(ctx context.Context, roleID string, p
 		return result.Consume()
 	})
 
-	return err
+	return classifyNeo4jError(err)
+}
+
+// AssignRoleToUsers gives a role to every user of userIDs, which must be distinct, in one transaction.
+// Users who already hold the role keep their single assignment. It returns ErrRoleNotFound for an
+// unknown role and ErrUserNotFound, assigning nothing, when one of the users does not exist.
+func (dao *RoleDAO) AssignRoleToUsers(ctx context.Context, roleID string, userIDs []string) error {
+	start := time.Now()
+	logger.Info("Assigning role to users", zap.String("roleID", roleID), zap.Int("userCount", len(userIDs)))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		if err := roleExistsInTransaction(transaction, roleID); err != nil {
+			return nil, err
+		}
+
+		result, err := transaction.Run(`
+        MATCH (r:`+echo_neo4j.LabelRole+` {id: $roleID})
+        UNWIND $userIDs AS userID
+        MATCH (u:`+echo_neo4j.LabelUser+` {id: userID})
+        MERGE (u)-[:`+echo_neo4j.RelHasRole+`]->(r)
+        RETURN count(u) AS assigned
+        `, map[string]interface{}{"roleID": roleID, "userIDs": userIDs})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if !result.Next() {
+			return nil, echo_errors.ErrDatabaseOperation
+		}
+		if assigned, _ := result.Record().Values[0].(int64); assigned != int64(len(userIDs)) {
+			return nil, echo_errors.ErrUserNotFound
+		}
+		return nil, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to assign role to users",
+			zap.Error(err),
+			zap.String("roleID", roleID),
+			zap.Duration("duration", duration))
+		return classifyNeo4jError(err)
+	}
+
+	logger.Info("Role assigned to users successfully",
+		zap.String("roleID", roleID),
+		zap.Int("userCount", len(userIDs)),
+		zap.Duration("duration", duration))
+
+	dao.auditRoleChange(ctx, "ASSIGN_ROLE_TO_USERS", roleID, "userIDs", userIDs)
+	return nil
+}
+
+// UnassignRoleFromUsers takes a role away from every user of userIDs in one transaction. Users who do
+// not hold the role, or do not exist, are left as they are. It returns ErrRoleNotFound for an unknown
+// role.
+func (dao *RoleDAO) UnassignRoleFromUsers(ctx context.Context, roleID string, userIDs []string) error {
+	start := time.Now()
+	logger.Info("Unassigning role from users", zap.String("roleID", roleID), zap.Int("userCount", len(userIDs)))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	var removed int64
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		if err := roleExistsInTransaction(transaction, roleID); err != nil {
+			return nil, err
+		}
+
+		result, err := transaction.Run(`
+        MATCH (r:`+echo_neo4j.LabelRole+` {id: $roleID})
+        UNWIND $userIDs AS userID
+        MATCH (:`+echo_neo4j.LabelUser+` {id: userID})-[assignment:`+echo_neo4j.RelHasRole+`]->(r)
+        DELETE assignment
+        RETURN count(assignment) AS removed
+        `, map[string]interface{}{"roleID": roleID, "userIDs": userIDs})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if result.Next() {
+			removed, _ = result.Record().Values[0].(int64)
+		}
+		return nil, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to unassign role from users",
+			zap.Error(err),
+			zap.String("roleID", roleID),
+			zap.Duration("duration", duration))
+		return classifyNeo4jError(err)
+	}
+
+	logger.Info("Role unassigned from users successfully",
+		zap.String("roleID", roleID),
+		zap.Int64("removed", removed),
+		zap.Duration("duration", duration))
+
+	dao.auditRoleChange(ctx, "UNASSIGN_ROLE_FROM_USERS", roleID, "userIDs", userIDs)
+	return nil
+}
+
+// AssignPermissionsToRole grants a role every permission of permissionIDs, which must be distinct, in
+// one transaction. Permissions the role already holds are kept once. It returns ErrRoleNotFound for an
+// unknown role and ErrPermissionNotFound, granting nothing, when one of the permissions does not exist.
+func (dao *RoleDAO) AssignPermissionsToRole(ctx context.Context, roleID string, permissionIDs []string) error {
+	return dao.changeRolePermissions(ctx, "ASSIGN_PERMISSIONS_TO_ROLE", roleID, permissionIDs, `
+        MERGE (r)-[:`+echo_neo4j.RelHasPermission+`]->(p)
+        RETURN count(p) AS found
+        `)
+}
+
+// RemovePermissionsFromRole revokes every permission of permissionIDs, which must be distinct, from a
+// role in one transaction. Permissions the role does not hold are ignored. It returns ErrRoleNotFound
+// for an unknown role and ErrPermissionNotFound, revoking nothing, when one of the permissions does not
+// exist.
+func (dao *RoleDAO) RemovePermissionsFromRole(ctx context.Context, roleID string, permissionIDs []string) error {
+	return dao.changeRolePermissions(ctx, "REMOVE_PERMISSIONS_FROM_ROLE", roleID, permissionIDs, `
+        OPTIONAL MATCH (r)-[grant:`+echo_neo4j.RelHasPermission+`]->(p)
+        DELETE grant
+        RETURN count(p) AS found
+        `)
+}
+
+// changeRolePermissions runs change, a query continuing from the role r and each permission p of
+// permissionIDs and returning how many permissions were found, after checking that the role exists
+func (dao *RoleDAO) changeRolePermissions(ctx context.Context, action string, roleID string, permissionIDs []string, change string) error {
+	start := time.Now()
+	logger.Info("Changing role permissions",
+		zap.String("action", action),
+		zap.String("roleID", roleID),
+		zap.Int("permissionCount", len(permissionIDs)))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		if err := roleExistsInTransaction(transaction, roleID); err != nil {
+			return nil, err
+		}
+
+		result, err := transaction.Run(`
+        MATCH (r:`+echo_neo4j.LabelRole+` {id: $roleID})
+        UNWIND $permissionIDs AS permissionID
+        MATCH (p:`+echo_neo4j.LabelPermission+` {id: permissionID})
+        `+change, map[string]interface{}{"roleID": roleID, "permissionIDs": permissionIDs})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+		if !result.Next() {
+			return nil, echo_errors.ErrDatabaseOperation
+		}
+		if found, _ := result.Record().Values[0].(int64); found != int64(len(permissionIDs)) {
+			return nil, echo_errors.ErrPermissionNotFound
+		}
+		return nil, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to change role permissions",
+			zap.Error(err),
+			zap.String("action", action),
+			zap.String("roleID", roleID),
+			zap.Duration("duration", duration))
+		return classifyNeo4jError(err)
+	}
+
+	logger.Info("Role permissions changed successfully",
+		zap.String("action", action),
+		zap.String("roleID", roleID),
+		zap.Duration("duration", duration))
+
+	dao.auditRoleChange(ctx, action, roleID, "permissionIDs", permissionIDs)
+	return nil
+}
+
+// roleExistsInTransaction returns ErrRoleNotFound unless the role exists
+func roleExistsInTransaction(transaction neo4j.Transaction, roleID string) error {
+	result, err := transaction.Run(`
+    MATCH (r:`+echo_neo4j.LabelRole+` {id: $roleID})
+    RETURN r.id
+    `, map[string]interface{}{"roleID": roleID})
+	if err != nil {
+		return echo_errors.ErrDatabaseOperation
+	}
+	if !result.Next() {
+		return echo_errors.ErrRoleNotFound
+	}
+	return nil
+}
+
+// auditRoleChange records a bulk change of the users or permissions of a role, listing the IDs changed
+// under key
+func (dao *RoleDAO) auditRoleChange(ctx context.Context, action string, roleID string, key string, ids []string) {
+	requestingUserID := helper_util.ActorFromContext(ctx)
+	changeDetails, _ := json.Marshal(map[string]interface{}{
+		"action": action,
+		key:      ids,
+	})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        requestingUserID,
+		Action:        action,
+		ResourceID:    roleID,
+		AccessGranted: true,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
 }
 
 func (dao *RoleDAO) GetRolePermissions(ctx context.Context, roleID string) ([]string, error) {
@@ -506,6 +758,155 @@ func (dao *RoleDAO) GetRolePermissions(ctx context.Context, roleID string) ([]st
 	return permissions, nil
 }
 
+// GetUsersByRole returns the users directly assigned the role. Suspended users are left out unless includeSuspended is set
+func (dao *RoleDAO) GetUsersByRole(ctx context.Context, roleID string, includeSuspended bool) ([]*model.User, error) {
+	start := time.Now()
+	logger.Info("Retrieving users by role", zap.String("roleID", roleID), zap.Bool("includeSuspended", includeSuspended))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close()
+
+	query := `
+    MATCH (u:` + echo_neo4j.LabelUser + `)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + ` {id: $roleID})
+    WHERE $includeSuspended OR toLower(coalesce(u.status, '')) <> $suspended
+    RETURN u
+    ORDER BY u.createdAt DESC
+    `
+	result, err := session.Run(query, map[string]interface{}{
+		"roleID":           roleID,
+		"includeSuspended": includeSuspended,
+		"suspended":        model.UserStatusSuspended,
+	})
+	if err != nil {
+		logger.Error("Failed to execute get users by role query",
+			zap.Error(err),
+			zap.String("roleID", roleID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrDatabaseOperation
+	}
+
+	var users []*model.User
+	for result.Next() {
+		node := result.Record().Values[0].(neo4j.Node)
+		user, err := mapNodeToUser(node)
+		if err != nil {
+			logger.Error("Failed to map user node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		users = append(users, user)
+	}
+
+	logger.Info("Users by role retrieved successfully",
+		zap.String("roleID", roleID),
+		zap.Int("count", len(users)),
+		zap.Duration("duration", time.Since(start)))
+
+	return users, nil
+}
+
+// GetGroupsByRole returns the groups that hold the role directly
+func (dao *RoleDAO) GetGroupsByRole(ctx context.Context, roleID string) ([]*model.Group, error) {
+	start := time.Now()
+	logger.Info("Retrieving groups by role", zap.String("roleID", roleID))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close()
+
+	query := `
+    MATCH (g:` + echo_neo4j.LabelGroup + `)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + ` {id: $roleID})
+    RETURN g
+    ORDER BY g.name
+    `
+	result, err := session.Run(query, map[string]interface{}{"roleID": roleID})
+	if err != nil {
+		logger.Error("Failed to execute get groups by role query",
+			zap.Error(err),
+			zap.String("roleID", roleID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrDatabaseOperation
+	}
+
+	var groups []*model.Group
+	for result.Next() {
+		group, err := mapNodeToGroup(result.Record().Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map group node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		groups = append(groups, group)
+	}
+
+	logger.Info("Groups by role retrieved successfully",
+		zap.String("roleID", roleID),
+		zap.Int("count", len(groups)),
+		zap.Duration("duration", time.Since(start)))
+
+	return groups, nil
+}
+
+// AnalyzeRoleUsage counts the users, groups and permissions attached to a role
+func (dao *RoleDAO) AnalyzeRoleUsage(ctx context.Context, roleID string) (*model.RoleUsageAnalysis, error) {
+	start := time.Now()
+	logger.Info("Analyzing role usage", zap.String("roleID", roleID))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close()
+
+	query := `
+		MATCH (r:` + echo_neo4j.LabelRole + ` {id: $roleID})
+		OPTIONAL MATCH (u:` + echo_neo4j.LabelUser + `)-[:` + echo_neo4j.RelHasRole + `]->(r)
+		OPTIONAL MATCH (g:` + echo_neo4j.LabelGroup + `)-[:` + echo_neo4j.RelHasRole + `]->(r)
+		OPTIONAL MATCH (r)-[:` + echo_neo4j.RelHasPermission + `]->(p:` + echo_neo4j.LabelPermission + `)
+		RETURN
+			r.id AS roleID,
+			r.name AS roleName,
+			COUNT(DISTINCT u) AS userCount,
+			COUNT(DISTINCT g) AS groupCount,
+			COUNT(DISTINCT p) AS permissionCount,
+			r.createdAt AS createdAt,
+			r.updatedAt AS updatedAt
+    `
+
+	result, err := session.Run(query, map[string]interface{}{"roleID": roleID})
+	if err != nil {
+		logger.Error("Failed to execute analyze role usage query",
+			zap.Error(err),
+			zap.String("roleID", roleID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrDatabaseOperation
+	}
+
+	if result.Next() {
+		record := result.Record()
+		createdAt, _ := record.Values[5].(string)
+		updatedAt, _ := record.Values[6].(string)
+		analysis := &model.RoleUsageAnalysis{
+			RoleID:          record.Values[0].(string),
+			RoleName:        record.Values[1].(string),
+			UserCount:       int(record.Values[2].(int64)),
+			GroupCount:      int(record.Values[3].(int64)),
+			PermissionCount: int(record.Values[4].(int64)),
+			CreatedAt:       parseTime(createdAt),
+			LastUpdatedAt:   parseTime(updatedAt),
+		}
+
+		logger.Info("Role usage analyzed successfully",
+			zap.String("roleID", roleID),
+			zap.Duration("duration", time.Since(start)))
+
+		return analysis, nil
+	}
+
+	logger.Warn("Role not found for usage analysis",
+		zap.String("roleID", roleID),
+		zap.Duration("duration", time.Since(start)))
+	return nil, echo_errors.ErrRoleNotFound
+}
+
 // Helper function to map Neo4j Node to Role struct
 func mapNodeToRole(node neo4j.Node) (*model.Role, error) {
 	props := node.Props
@@ -527,6 +928,8 @@ func mapNodeToRole(node neo4j.Node) (*model.Role, error) {
 	}
 	role.CreatedAt, _ = helper_util.ParseTime(props["createdAt"].(string))
 	role.UpdatedAt, _ = helper_util.ParseTime(props["updatedAt"].(string))
+	role.CreatedBy, _ = props["createdBy"].(string)
+	role.UpdatedBy, _ = props["updatedBy"].(string)
 
 	return role, nil
 }
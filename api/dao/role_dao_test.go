@@ -0,0 +1,238 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestRoleUsage(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newDAO := func() (*dao.RoleDAO, *mock.MockTxSession, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		return &dao.RoleDAO{Driver: driver, AuditService: auditService}, session, tx, auditService
+	}
+
+	t.Run("AnalyzeRoleUsage_CountsReferences", func(t *testing.T) {
+		roleDAO, session, _, _ := newDAO()
+		session.On("Run", queryContaining("permissionCount"), map[string]interface{}{"roleID": "editor"}, testify_mock.Anything).
+			Return(resultWithRecord("editor", "Editor", int64(3), int64(2), int64(5), "2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z"), nil)
+
+		analysis, err := roleDAO.AnalyzeRoleUsage(ctx, "editor")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Editor", analysis.RoleName)
+		assert.Equal(t, 3, analysis.UserCount)
+		assert.Equal(t, 2, analysis.GroupCount)
+		assert.Equal(t, 5, analysis.PermissionCount)
+	})
+
+	t.Run("AnalyzeRoleUsage_NotFound", func(t *testing.T) {
+		roleDAO, session, _, _ := newDAO()
+		result := &mock.MockResult{}
+		result.On("Next").Return(false)
+		session.On("Run", queryContaining("permissionCount"), testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+
+		analysis, err := roleDAO.AnalyzeRoleUsage(ctx, "missing")
+
+		assert.Nil(t, analysis)
+		assert.Equal(t, echo_errors.ErrRoleNotFound, err)
+	})
+
+	t.Run("DeleteRole_InUse", func(t *testing.T) {
+		roleDAO, _, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("groupCount"), testify_mock.Anything).
+			Return(resultWithRecord("editor", int64(1), int64(0)), nil)
+
+		err := roleDAO.DeleteRole(ctx, "editor", false)
+
+		assert.Equal(t, echo_errors.ErrRoleInUse, err)
+		tx.AssertNotCalled(t, "Run", queryContaining("DETACH DELETE"), testify_mock.Anything)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("DeleteRole_Forced", func(t *testing.T) {
+		roleDAO, _, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("groupCount"), testify_mock.Anything).
+			Return(resultWithRecord("editor", int64(1), int64(2)), nil)
+		tx.On("Run", queryContaining("DETACH DELETE"), testify_mock.Anything).
+			Return(resultWithRecord(int64(1)), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "DELETE_ROLE" && log.ResourceID == "editor"
+		})).Return(nil)
+
+		err := roleDAO.DeleteRole(ctx, "editor", true)
+
+		assert.NoError(t, err)
+		tx.AssertCalled(t, "Run", queryContaining("DETACH DELETE"), testify_mock.Anything)
+		auditService.AssertExpectations(t)
+	})
+}
+
+func TestRoleAssignment(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newDAO := func(roleExists bool) (*dao.RoleDAO, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		roleResult := &mock.MockResult{}
+		if roleExists {
+			roleResult = resultWithRecord("editor")
+		} else {
+			roleResult.On("Next").Return(false)
+		}
+		tx.On("Run", queryContaining("RETURN r.id"), map[string]interface{}{"roleID": "editor"}).Return(roleResult, nil)
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		return &dao.RoleDAO{Driver: driver, AuditService: auditService}, tx, auditService
+	}
+
+	t.Run("AssignRoleToUsers_AlreadyAssignedUserKeepsOneAssignment", func(t *testing.T) {
+		roleDAO, tx, auditService := newDAO(true)
+		// u2 already holds the role; MERGE matches its assignment instead of adding a second one
+		userIDs := []string{"u1", "u2", "u3"}
+		tx.On("Run", queryContaining("UNWIND $userIDs"), map[string]interface{}{"roleID": "editor", "userIDs": userIDs}).
+			Return(resultWithRecord(int64(3)), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "ASSIGN_ROLE_TO_USERS" && log.ResourceID == "editor" && log.UserID == "admin"
+		})).Return(nil)
+
+		err := roleDAO.AssignRoleToUsers(ctx, "editor", userIDs)
+
+		assert.NoError(t, err)
+		tx.AssertCalled(t, "Run", queryContaining("MERGE (u)-[:HAS_ROLE]->(r)"), testify_mock.Anything)
+		tx.AssertNotCalled(t, "Run", queryContaining("CREATE"), testify_mock.Anything)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("AssignRoleToUsers_UnknownUser", func(t *testing.T) {
+		roleDAO, tx, auditService := newDAO(true)
+		tx.On("Run", queryContaining("UNWIND $userIDs"), testify_mock.Anything).Return(resultWithRecord(int64(1)), nil)
+
+		err := roleDAO.AssignRoleToUsers(ctx, "editor", []string{"u1", "missing"})
+
+		assert.Equal(t, echo_errors.ErrUserNotFound, err)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("AssignRoleToUsers_UnknownRole", func(t *testing.T) {
+		roleDAO, tx, _ := newDAO(false)
+
+		err := roleDAO.AssignRoleToUsers(ctx, "editor", []string{"u1"})
+
+		assert.Equal(t, echo_errors.ErrRoleNotFound, err)
+		tx.AssertNotCalled(t, "Run", queryContaining("UNWIND $userIDs"), testify_mock.Anything)
+	})
+
+	t.Run("UnassignRoleFromUsers", func(t *testing.T) {
+		roleDAO, tx, auditService := newDAO(true)
+		tx.On("Run", queryContaining("DELETE assignment"), map[string]interface{}{"roleID": "editor", "userIDs": []string{"u1", "u2"}}).
+			Return(resultWithRecord(int64(1)), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "UNASSIGN_ROLE_FROM_USERS" && log.ResourceID == "editor"
+		})).Return(nil)
+
+		err := roleDAO.UnassignRoleFromUsers(ctx, "editor", []string{"u1", "u2"})
+
+		assert.NoError(t, err)
+		auditService.AssertExpectations(t)
+	})
+}
+
+func TestRolePermissions(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newDAO := func() (*dao.RoleDAO, *mock.MockTransaction, *mock.MockAuditService) {
+		tx := &mock.MockTransaction{}
+		tx.On("Run", queryContaining("RETURN r.id"), map[string]interface{}{"roleID": "editor"}).Return(resultWithRecord("editor"), nil)
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		return &dao.RoleDAO{Driver: driver, AuditService: auditService}, tx, auditService
+	}
+
+	t.Run("AssignPermissionsToRole_AddsMultiple", func(t *testing.T) {
+		roleDAO, tx, auditService := newDAO()
+		permissionIDs := []string{"read", "write", "share"}
+		tx.On("Run", queryContaining("MERGE (r)-[:HAS_PERMISSION]->(p)"), map[string]interface{}{"roleID": "editor", "permissionIDs": permissionIDs}).
+			Return(resultWithRecord(int64(3)), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "ASSIGN_PERMISSIONS_TO_ROLE" && log.ResourceID == "editor"
+		})).Return(nil)
+
+		err := roleDAO.AssignPermissionsToRole(ctx, "editor", permissionIDs)
+
+		assert.NoError(t, err)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("RemovePermissionsFromRole_RemovesSubset", func(t *testing.T) {
+		roleDAO, tx, auditService := newDAO()
+		tx.On("Run", queryContaining("DELETE grant"), map[string]interface{}{"roleID": "editor", "permissionIDs": []string{"write"}}).
+			Return(resultWithRecord(int64(1)), nil)
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "REMOVE_PERMISSIONS_FROM_ROLE" && log.ResourceID == "editor"
+		})).Return(nil)
+
+		err := roleDAO.RemovePermissionsFromRole(ctx, "editor", []string{"write"})
+
+		assert.NoError(t, err)
+		tx.AssertNotCalled(t, "Run", queryContaining("MERGE"), testify_mock.Anything)
+		auditService.AssertExpectations(t)
+	})
+
+	t.Run("AssignPermissionsToRole_NonexistentPermission", func(t *testing.T) {
+		roleDAO, tx, auditService := newDAO()
+		// Only read exists, so the transaction is rolled back without granting it
+		tx.On("Run", queryContaining("MERGE (r)-[:HAS_PERMISSION]->(p)"), testify_mock.Anything).Return(resultWithRecord(int64(1)), nil)
+
+		err := roleDAO.AssignPermissionsToRole(ctx, "editor", []string{"read", "missing"})
+
+		assert.Equal(t, echo_errors.ErrPermissionNotFound, err)
+		auditService.AssertNotCalled(t, "LogAccess", testify_mock.Anything, testify_mock.Anything)
+	})
+}
+
+func TestGetUsersByRoleLeavesSuspendedUsersOutRegardlessOfCase(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	driver := mock.NewFakeDriver()
+	roleDAO := &dao.RoleDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	_, err := roleDAO.GetUsersByRole(context.Background(), "editor", false)
+
+	assert.NoError(t, err)
+	queries := driver.QueriesContaining("toLower(coalesce(u.status, '')) <> $suspended")
+	if assert.Len(t, queries, 1) {
+		assert.Equal(t, model.UserStatusSuspended, queries[0].Params["suspended"])
+	}
+}
@@ -0,0 +1,436 @@
+// api/dao/sod_rule_dao.go
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+type SoDRuleDAO struct {
+	Driver       Neo4jDriver
+	AuditService audit.Service
+}
+
+func NewSoDRuleDAO(driver Neo4jDriver, auditService audit.Service) *SoDRuleDAO {
+	dao := &SoDRuleDAO{Driver: driver, AuditService: auditService}
+	if err := dao.EnsureUniqueConstraint(context.Background()); err != nil {
+		logger.Fatal("Failed to ensure unique constraint for SoDRule", zap.Error(err))
+	}
+	return dao
+}
+
+func (dao *SoDRuleDAO) EnsureUniqueConstraint(ctx context.Context) error {
+	logger.Info("Ensuring unique constraint on SoDRule ID")
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		query := `
+        CREATE CONSTRAINT unique_sod_rule_id IF NOT EXISTS
+        FOR (s:` + echo_neo4j.LabelSoDRule + `) REQUIRE s.id IS UNIQUE
+        `
+		_, err := transaction.Run(query, nil)
+		return nil, err
+	})
+
+	if err != nil {
+		logger.Error("Failed to ensure unique constraint on SoDRule ID", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully ensured unique constraint on SoDRule ID")
+	return nil
+}
+
+func (dao *SoDRuleDAO) CreateSoDRule(ctx context.Context, rule model.SoDRule) (string, error) {
+	start := time.Now()
+	logger.Info("Creating new SoD rule", zap.String("roleA", rule.RoleA), zap.String("roleB", rule.RoleB))
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	rule.CreatedBy = changedBy(ctx, "")
+	rule.UpdatedBy = rule.CreatedBy
+
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		if err := checkSoDRuleRoles(transaction, rule); err != nil {
+			return nil, err
+		}
+
+		query := `
+        CREATE (s:` + echo_neo4j.LabelSoDRule + ` {id: $id})
+        SET s += $props
+        RETURN s.id AS id
+        `
+		now := time.Now().UTC().Format(time.RFC3339)
+		params := map[string]interface{}{
+			"id": rule.ID,
+			"props": map[string]interface{}{
+				"roleA":       rule.RoleA,
+				"roleB":       rule.RoleB,
+				"description": rule.Description,
+				"createdAt":   now,
+				"updatedAt":   now,
+				"createdBy":   rule.CreatedBy,
+				"updatedBy":   rule.UpdatedBy,
+			},
+		}
+
+		result, err := transaction.Run(query, params)
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+
+		if result.Next() {
+			return result.Record().Values[0], nil
+		}
+
+		return nil, echo_errors.ErrInternalServer
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to create SoD rule",
+			zap.Error(err),
+			zap.String("roleA", rule.RoleA),
+			zap.String("roleB", rule.RoleB),
+			zap.Duration("duration", duration))
+		return "", duplicateIDConflict(err, echo_errors.ErrSoDRuleConflict, rule.ID)
+	}
+
+	ruleID := fmt.Sprintf("%v", result)
+	logger.Info("SoD rule created successfully",
+		zap.String("ruleID", ruleID),
+		zap.Duration("duration", duration))
+
+	// Audit trail
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        rule.CreatedBy,
+		Action:        "CREATE_" + echo_neo4j.LabelSoDRule,
+		ResourceID:    ruleID,
+		AccessGranted: true,
+		ChangeDetails: createSoDRuleChangeDetails(nil, &rule),
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return ruleID, nil
+}
+
+func (dao *SoDRuleDAO) UpdateSoDRule(ctx context.Context, rule model.SoDRule) (*model.SoDRule, error) {
+	start := time.Now()
+	logger.Info("Updating SoD rule", zap.String("ruleID", rule.ID))
+
+	oldRule, err := dao.GetSoDRule(ctx, rule.ID)
+	if err != nil {
+		logger.Error("Failed to retrieve SoD rule for update", zap.Error(err))
+		return nil, err
+	}
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	rule.UpdatedBy = changedBy(ctx, "")
+	var updatedRule *model.SoDRule
+	_, err = session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		if err := checkSoDRuleRoles(transaction, rule); err != nil {
+			return nil, err
+		}
+
+		query := `
+        MATCH (s:` + echo_neo4j.LabelSoDRule + ` {id: $id})
+        SET s += $props
+        RETURN s
+        `
+		params := map[string]interface{}{
+			"id": rule.ID,
+			"props": map[string]interface{}{
+				"roleA":       rule.RoleA,
+				"roleB":       rule.RoleB,
+				"description": rule.Description,
+				"updatedAt":   time.Now().UTC().Format(time.RFC3339),
+				"updatedBy":   rule.UpdatedBy,
+			},
+		}
+
+		result, err := transaction.Run(query, params)
+		if err != nil {
+			logger.Error("Failed to execute update SoD rule query", zap.Error(err))
+			return nil, classifyNeo4jError(err)
+		}
+
+		if result.Next() {
+			updatedRule, err = mapNodeToSoDRule(result.Record().Values[0].(neo4j.Node))
+			if err != nil {
+				return nil, fmt.Errorf("failed to map SoD rule node to struct: %w", err)
+			}
+			return nil, nil
+		}
+
+		return nil, echo_errors.ErrSoDRuleNotFound
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to update SoD rule",
+			zap.Error(err),
+			zap.String("ruleID", rule.ID),
+			zap.Duration("duration", duration))
+		return nil, classifyNeo4jError(err)
+	}
+
+	logger.Info("SoD rule updated successfully",
+		zap.String("ruleID", rule.ID),
+		zap.Duration("duration", duration))
+
+	// Audit trail
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        rule.UpdatedBy,
+		Action:        "UPDATE_" + echo_neo4j.LabelSoDRule,
+		ResourceID:    rule.ID,
+		AccessGranted: true,
+		ChangeDetails: createSoDRuleChangeDetails(oldRule, updatedRule),
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return updatedRule, nil
+}
+
+func (dao *SoDRuleDAO) DeleteSoDRule(ctx context.Context, ruleID string) error {
+	start := time.Now()
+	logger.Info("Deleting SoD rule", zap.String("ruleID", ruleID))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		query := `
+        MATCH (s:` + echo_neo4j.LabelSoDRule + ` {id: $id})
+        DELETE s
+        `
+		result, err := transaction.Run(query, map[string]interface{}{"id": ruleID})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+
+		summary, err := result.Consume()
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+
+		if summary.Counters().NodesDeleted() == 0 {
+			return nil, echo_errors.ErrSoDRuleNotFound
+		}
+
+		return nil, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to delete SoD rule",
+			zap.Error(err),
+			zap.String("ruleID", ruleID),
+			zap.Duration("duration", duration))
+		return classifyNeo4jError(err)
+	}
+
+	logger.Info("SoD rule deleted successfully",
+		zap.String("ruleID", ruleID),
+		zap.Duration("duration", duration))
+
+	// Audit trail
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        helper_util.ActorFromContext(ctx),
+		Action:        "DELETE_" + echo_neo4j.LabelSoDRule,
+		ResourceID:    ruleID,
+		AccessGranted: true,
+		ChangeDetails: deletedDetails(helper_util.ActorFromContext(ctx), nil),
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return nil
+}
+
+func (dao *SoDRuleDAO) GetSoDRule(ctx context.Context, ruleID string) (*model.SoDRule, error) {
+	start := time.Now()
+	logger.Info("Retrieving SoD rule", zap.String("ruleID", ruleID))
+
+	query := `
+    MATCH (s:` + echo_neo4j.LabelSoDRule + ` {id: $id})
+    RETURN s
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"id": ruleID})
+	if err != nil {
+		logger.Error("Failed to execute get SoD rule query",
+			zap.Error(err),
+			zap.String("ruleID", ruleID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	if len(records) == 0 {
+		logger.Warn("SoD rule not found",
+			zap.String("ruleID", ruleID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrSoDRuleNotFound
+	}
+
+	rule, err := mapNodeToSoDRule(records[0].Values[0].(neo4j.Node))
+	if err != nil {
+		logger.Error("Failed to map SoD rule node to struct",
+			zap.Error(err),
+			zap.String("ruleID", ruleID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrInternalServer
+	}
+
+	logger.Info("SoD rule retrieved successfully",
+		zap.String("ruleID", ruleID),
+		zap.Duration("duration", time.Since(start)))
+	return rule, nil
+}
+
+// ListSoDRules returns every SoD rule, oldest first. Rules are few, so they are not paginated.
+func (dao *SoDRuleDAO) ListSoDRules(ctx context.Context) ([]*model.SoDRule, error) {
+	start := time.Now()
+	logger.Info("Listing SoD rules")
+
+	query := `
+    MATCH (s:` + echo_neo4j.LabelSoDRule + `)
+    RETURN s
+    ORDER BY s.createdAt, s.id
+    `
+	records, err := readRecords(ctx, dao.Driver, query, nil)
+	if err != nil {
+		logger.Error("Failed to execute list SoD rules query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	rules := make([]*model.SoDRule, 0, len(records))
+	for _, record := range records {
+		rule, err := mapNodeToSoDRule(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map SoD rule node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		rules = append(rules, rule)
+	}
+
+	logger.Info("SoD rules listed successfully",
+		zap.Int("count", len(rules)),
+		zap.Duration("duration", time.Since(start)))
+	return rules, nil
+}
+
+// checkSoDRuleRoles makes sure both roles of a rule exist and that no other rule covers the same pair,
+// in either order
+func checkSoDRuleRoles(transaction neo4j.Transaction, rule model.SoDRule) error {
+	query := `
+    OPTIONAL MATCH (a:` + echo_neo4j.LabelRole + ` {id: $roleA})
+    OPTIONAL MATCH (b:` + echo_neo4j.LabelRole + ` {id: $roleB})
+    OPTIONAL MATCH (existing:` + echo_neo4j.LabelSoDRule + `)
+    WHERE existing.id <> $id
+      AND ((existing.roleA = $roleA AND existing.roleB = $roleB) OR (existing.roleA = $roleB AND existing.roleB = $roleA))
+    RETURN a IS NOT NULL AS roleAExists, b IS NOT NULL AS roleBExists, count(existing) > 0 AS conflict
+    `
+	result, err := transaction.Run(query, map[string]interface{}{
+		"id":    rule.ID,
+		"roleA": rule.RoleA,
+		"roleB": rule.RoleB,
+	})
+	if err != nil {
+		return echo_errors.ErrDatabaseOperation
+	}
+	if !result.Next() {
+		return echo_errors.ErrInternalServer
+	}
+
+	values := result.Record().Values
+	if roleAExists, _ := values[0].(bool); !roleAExists {
+		return fmt.Errorf("%w: %s", echo_errors.ErrRoleNotFound, rule.RoleA)
+	}
+	if roleBExists, _ := values[1].(bool); !roleBExists {
+		return fmt.Errorf("%w: %s", echo_errors.ErrRoleNotFound, rule.RoleB)
+	}
+	if conflict, _ := values[2].(bool); conflict {
+		return echo_errors.ErrSoDRuleConflict
+	}
+	return nil
+}
+
+// Helper function to map Neo4j Node to SoDRule struct
+func mapNodeToSoDRule(node neo4j.Node) (*model.SoDRule, error) {
+	props := node.Props
+	rule := &model.SoDRule{}
+
+	var ok bool
+	if rule.ID, ok = props["id"].(string); !ok {
+		return nil, fmt.Errorf("invalid or missing 'id' property")
+	}
+	if rule.RoleA, ok = props["roleA"].(string); !ok {
+		return nil, fmt.Errorf("invalid or missing 'roleA' property")
+	}
+	if rule.RoleB, ok = props["roleB"].(string); !ok {
+		return nil, fmt.Errorf("invalid or missing 'roleB' property")
+	}
+	rule.Description, _ = props["description"].(string)
+	if createdAt, ok := props["createdAt"].(string); ok {
+		rule.CreatedAt = parseTime(createdAt)
+	}
+	if updatedAt, ok := props["updatedAt"].(string); ok {
+		rule.UpdatedAt = parseTime(updatedAt)
+	}
+	rule.CreatedBy, _ = props["createdBy"].(string)
+	rule.UpdatedBy, _ = props["updatedBy"].(string)
+
+	return rule, nil
+}
+
+// Helper function to create change details for audit log
+func createSoDRuleChangeDetails(oldRule, newRule *model.SoDRule) json.RawMessage {
+	changes := make(map[string]interface{})
+	if oldRule == nil {
+		changes["action"] = "created"
+		changes["roles"] = []string{newRule.RoleA, newRule.RoleB}
+	} else {
+		changes["action"] = "updated"
+		if oldRule.RoleA != newRule.RoleA || oldRule.RoleB != newRule.RoleB {
+			changes["roles"] = map[string][]string{
+				"old": {oldRule.RoleA, oldRule.RoleB},
+				"new": {newRule.RoleA, newRule.RoleB},
+			}
+		}
+		if oldRule.Description != newRule.Description {
+			changes["description"] = map[string]string{"old": oldRule.Description, "new": newRule.Description}
+		}
+	}
+	changeDetails, _ := json.Marshal(changes)
+	return changeDetails
+}
@@ -21,11 +21,11 @@ import (
 )
 
 type UserDAO struct {
-	Driver       neo4j.Driver
+	Driver       Neo4jDriver
 	AuditService audit.Service
 }
 
-func NewUserDAO(driver neo4j.Driver, auditService audit.Service) *UserDAO {
+func NewUserDAO(driver Neo4jDriver, auditService audit.Service) *UserDAO {
 	dao := &UserDAO{Driver: driver, AuditService: auditService}
 	// Ensure unique constraint on User ID
 	ctx := context.Background()
@@ -67,20 +67,22 @@ func (dao *UserDAO) CreateUser(ctx context.Context, user model.User) (string, er
 	if user.ID == "" {
 		user.ID = uuid.New().String()
 	}
+	user.CreatedBy = changedBy(ctx, "")
+	user.UpdatedBy = user.CreatedBy
 
-	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+	result, err := writeTransaction(ctx, session, func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
-            CREATE (u:USER {id: $id})
+            CREATE (u:` + echo_neo4j.LabelUser + ` {id: $id})
             SET u += $props
             WITH u
-            OPTIONAL MATCH (o:ORGANIZATION {id: $organizationID})
+            OPTIONAL MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $organizationID})
             FOREACH (_ IN CASE WHEN o IS NOT NULL THEN [1] ELSE [] END |
-                CREATE (u)-[:WORKS_FOR]->(o)
+                CREATE (u)-[:` + echo_neo4j.RelWorksFor + `]->(o)
             )
             WITH u
-            OPTIONAL MATCH (d:DEPARTMENT {id: $departmentID})
+            OPTIONAL MATCH (d:` + echo_neo4j.LabelDepartment + ` {id: $departmentID})
             FOREACH (_ IN CASE WHEN d IS NOT NULL THEN [1] ELSE [] END |
-                CREATE (u)-[:MEMBER_OF]->(d)
+                CREATE (u)-[:` + echo_neo4j.RelMemberOf + `]->(d)
             )
         `
 
@@ -89,9 +91,9 @@ func (dao *UserDAO) CreateUser(ctx context.Context, user model.User) (string, er
 			query += `
                 WITH u
                 UNWIND $roleIds AS roleId
-                OPTIONAL MATCH (r:ROLE {id: roleId})
+                OPTIONAL MATCH (r:` + echo_neo4j.LabelRole + ` {id: roleId})
                 FOREACH (_ IN CASE WHEN r IS NOT NULL THEN [1] ELSE [] END |
-                    CREATE (u)-[:HAS_ROLE]->(r)
+                    CREATE (u)-[:` + echo_neo4j.RelHasRole + `]->(r)
                 )
             `
 		}
@@ -101,9 +103,9 @@ func (dao *UserDAO) CreateUser(ctx context.Context, user model.User) (string, er
 			query += `
                 WITH u
                 UNWIND $groupIds AS groupId
-                OPTIONAL MATCH (g:GROUP {id: groupId})
+                OPTIONAL MATCH (g:` + echo_neo4j.LabelGroup + ` {id: groupId})
                 FOREACH (_ IN CASE WHEN g IS NOT NULL THEN [1] ELSE [] END |
-                    CREATE (u)-[:BELONGS_TO_GROUP]->(g)
+                    CREATE (u)-[:` + echo_neo4j.RelBelongsToGroup + `]->(g)
                 )
             `
 		}
@@ -121,16 +123,20 @@ func (dao *UserDAO) CreateUser(ctx context.Context, user model.User) (string, er
 		params := map[string]interface{}{
 			"id": user.ID,
 			"props": map[string]interface{}{
-				"name":           user.Name,
-				"username":       user.Username,
-				"email":          user.Email,
-				"userType":       user.UserType,
-				"organizationID": user.OrganizationID,
-				"departmentID":   user.DepartmentID,
-				"attributes":     string(attributesJSON),
-				"status":         user.Status,
-				"createdAt":      now,
-				"updatedAt":      now,
+				"name":             user.Name,
+				"username":         user.Username,
+				"email":            user.Email,
+				"userType":         user.UserType,
+				"organizationID":   user.OrganizationID,
+				"departmentID":     user.DepartmentID,
+				"attributes":       string(attributesJSON),
+				"attributeGroupID": user.AttributeGroupID,
+				"status":           user.Status,
+				"clearance":        user.Clearance,
+				"createdAt":        now,
+				"updatedAt":        now,
+				"createdBy":        user.CreatedBy,
+				"updatedBy":        user.UpdatedBy,
 			},
 			"organizationID": user.OrganizationID,
 			"departmentID":   user.DepartmentID,
@@ -172,10 +178,15 @@ func (dao *UserDAO) CreateUser(ctx context.Context, user model.User) (string, er
 			zap.Error(err),
 			zap.String("username", user.Username),
 			zap.Duration("duration", duration))
-		return "", err
+		return "", duplicateIDConflict(err, echo_errors.ErrUserConflict, user.ID)
 	}
 
 	userID := fmt.Sprintf("%v", result)
+	if helper_util.IsDryRun(ctx) {
+		logger.Info("Dry run: user creation rolled back", zap.String("userID", userID))
+		return userID, nil
+	}
+
 	logger.Info("User created successfully",
 		zap.String("userID", userID),
 		zap.Duration("duration", duration))
@@ -193,7 +204,7 @@ func (dao *UserDAO) CreateUser(ctx context.Context, user model.User) (string, er
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        user.CreatedBy,
 		Action:        "CREATE_USER",
 		ResourceID:    userID,
 		AccessGranted: true,
@@ -232,9 +243,9 @@ func (dao *UserDAO) verifyRelationships(ctx context.Context, userID, orgID, dept
 		returnedDeptID, _ := record.Get("deptId")
 
 		if orgID != "" && orgRel == nil {
-			logger.Error("BELONGS_TO relationship not created", zap.String("userID", userID), zap.String("orgID", orgID))
+			logger.Error("WORKS_FOR relationship not created", zap.String("userID", userID), zap.String("orgID", orgID))
 		} else if orgID != "" {
-			logger.Info("BELONGS_TO relationship verified", zap.String("userID", userID), zap.String("orgID", returnedOrgID.(string)))
+			logger.Info("WORKS_FOR relationship verified", zap.String("userID", userID), zap.String("orgID", returnedOrgID.(string)))
 		}
 
 		if deptID != "" && deptRel == nil {
@@ -256,13 +267,14 @@ func (dao *UserDAO) UpdateUser(ctx context.Context, user model.User) (*model.Use
 	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close()
 
+	user.UpdatedBy = changedBy(ctx, "")
 	var updatedUser *model.User
 	oldUser, err := dao.GetUser(ctx, user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	_, err = session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+	_, err = writeTransaction(ctx, session, func(transaction neo4j.Transaction) (interface{}, error) {
 		query := `
         MATCH (u:` + echo_neo4j.LabelUser + ` {id: $id})
         SET u.name = $name,
@@ -272,7 +284,10 @@ func (dao *UserDAO) UpdateUser(ctx context.Context, user model.User) (*model.Use
             u.organizationID = $organizationID,
             u.departmentID = $departmentID,
             u.attributes = $attributes,
-            u.updatedAt = $updatedAt
+            u.attributeGroupID = $attributeGroupID,
+            u.clearance = $clearance,
+            u.updatedAt = $updatedAt,
+            u.updatedBy = $updatedBy
         WITH u
         OPTIONAL MATCH (u)-[oldOrgRel:` + echo_neo4j.RelWorksFor + `]->(:` + echo_neo4j.LabelOrganization + `)
         DELETE oldOrgRel
@@ -289,55 +304,56 @@ func (dao *UserDAO) UpdateUser(ctx context.Context, user model.User) (*model.Use
         )
         `
 
-		// Conditionally update role relationships
-		if len(user.RoleIds) > 0 {
+		// Roles and groups live only in relationships. A nil list keeps them as they are; any other list,
+		// even an empty one, replaces them, so the last role can be removed.
+		if user.RoleIds != nil {
 			query += `
                 WITH u
                 OPTIONAL MATCH (u)-[oldRoleRel:` + echo_neo4j.RelHasRole + `]->(:` + echo_neo4j.LabelRole + `)
                 DELETE oldRoleRel
-                WITH u
-                UNWIND $roleIds AS roleId
-                MATCH (r:` + echo_neo4j.LabelRole + ` {id: roleId})
-                MERGE (u)-[:` + echo_neo4j.RelHasRole + `]->(r)
+                WITH DISTINCT u
+                OPTIONAL MATCH (r:` + echo_neo4j.LabelRole + `) WHERE r.id IN $roleIds
+                WITH u, collect(r) AS roles
+                FOREACH (r IN roles | MERGE (u)-[:` + echo_neo4j.RelHasRole + `]->(r))
             `
 		}
-
-		// Conditionally update group relationships
-		if len(user.GroupIds) > 0 {
+		if user.GroupIds != nil {
 			query += `
                 WITH u
                 OPTIONAL MATCH (u)-[oldGroupRel:` + echo_neo4j.RelBelongsToGroup + `]->(:` + echo_neo4j.LabelGroup + `)
                 DELETE oldGroupRel
-                WITH u
-                UNWIND $groupIds AS groupId
-                MATCH (g:` + echo_neo4j.LabelGroup + ` {id: groupId})
-                MERGE (u)-[:` + echo_neo4j.RelBelongsToGroup + `]->(g)
+                WITH DISTINCT u
+                OPTIONAL MATCH (g:` + echo_neo4j.LabelGroup + `) WHERE g.id IN $groupIds
+                WITH u, collect(g) AS groups
+                FOREACH (g IN groups | MERGE (u)-[:` + echo_neo4j.RelBelongsToGroup + `]->(g))
             `
 		}
 
 		query += `
-        RETURN u
+        RETURN u, [(u)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + `) | r.id] AS roleIds
         `
 
 		attributesJSON, _ := json.Marshal(user.Attributes)
 
 		params := map[string]interface{}{
-			"id":             user.ID,
-			"name":           user.Name,
-			"username":       user.Username,
-			"email":          user.Email,
-			"userType":       user.UserType,
-			"organizationID": user.OrganizationID,
-			"departmentID":   user.DepartmentID,
-			"attributes":     string(attributesJSON),
-			"updatedAt":      time.Now().Format(time.RFC3339),
+			"id":               user.ID,
+			"name":             user.Name,
+			"username":         user.Username,
+			"email":            user.Email,
+			"userType":         user.UserType,
+			"organizationID":   user.OrganizationID,
+			"departmentID":     user.DepartmentID,
+			"attributes":       string(attributesJSON),
+			"attributeGroupID": user.AttributeGroupID,
+			"clearance":        user.Clearance,
+			"updatedAt":        time.Now().Format(time.RFC3339),
+			"updatedBy":        user.UpdatedBy,
 		}
 
-		// Only include roleIds and groupIds in params if they are provided
-		if len(user.RoleIds) > 0 {
+		if user.RoleIds != nil {
 			params["roleIds"] = user.RoleIds
 		}
-		if len(user.GroupIds) > 0 {
+		if user.GroupIds != nil {
 			params["groupIds"] = user.GroupIds
 		}
 
@@ -347,7 +363,7 @@ func (dao *UserDAO) UpdateUser(ctx context.Context, user model.User) (*model.Use
 		result, err := transaction.Run(query, params)
 		if err != nil {
 			logger.Error("Failed to execute query", zap.Error(err), zap.Any("params", params))
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if result.Next() {
@@ -357,6 +373,11 @@ func (dao *UserDAO) UpdateUser(ctx context.Context, user model.User) (*model.Use
 			if err != nil {
 				return nil, fmt.Errorf("failed to map user node to struct: %w", err)
 			}
+			roleIds, _ := result.Record().Values[1].([]interface{})
+			updatedUser.RoleIds = make([]string, len(roleIds))
+			for i, roleID := range roleIds {
+				updatedUser.RoleIds[i] = roleID.(string)
+			}
 			return nil, nil
 		}
 
@@ -369,7 +390,12 @@ func (dao *UserDAO) UpdateUser(ctx context.Context, user model.User) (*model.Use
 			zap.Error(err),
 			zap.String("userID", user.ID),
 			zap.Duration("duration", duration))
-		return nil, err
+		return nil, classifyNeo4jError(err)
+	}
+
+	if helper_util.IsDryRun(ctx) {
+		logger.Info("Dry run: user update rolled back", zap.String("userID", user.ID))
+		return updatedUser, nil
 	}
 
 	logger.Info("User updated successfully",
@@ -379,7 +405,7 @@ func (dao *UserDAO) UpdateUser(ctx context.Context, user model.User) (*model.Use
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        user.UpdatedBy,
 		Action:        "UPDATE_USER",
 		ResourceID:    user.ID,
 		AccessGranted: true,
@@ -406,12 +432,12 @@ func (dao *UserDAO) DeleteUser(ctx context.Context, userID string) error {
         `
 		result, err := transaction.Run(query, map[string]interface{}{"id": userID})
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		summary, err := result.Consume()
 		if err != nil {
-			return nil, echo_errors.ErrDatabaseOperation
+			return nil, classifyNeo4jError(err)
 		}
 
 		if summary.Counters().NodesDeleted() == 0 {
@@ -427,7 +453,7 @@ func (dao *UserDAO) DeleteUser(ctx context.Context, userID string) error {
 			zap.Error(err),
 			zap.String("userID", userID),
 			zap.Duration("duration", duration))
-		return err
+		return classifyNeo4jError(err)
 	}
 
 	logger.Info("User deleted successfully",
@@ -437,10 +463,11 @@ func (dao *UserDAO) DeleteUser(ctx context.Context, userID string) error {
 	// Audit trail
 	auditLog := audit.AuditLog{
 		Timestamp:     time.Now(),
-		UserID:        ctx.Value("requestingUserID").(string),
+		UserID:        helper_util.ActorFromContext(ctx),
 		Action:        "DELETE_USER",
 		ResourceID:    userID,
 		AccessGranted: true,
+		ChangeDetails: deletedDetails(helper_util.ActorFromContext(ctx), nil),
 	}
 	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
 		logger.Error("Failed to create audit log", zap.Error(err))
@@ -449,6 +476,188 @@ func (dao *UserDAO) DeleteUser(ctx context.Context, userID string) error {
 	return nil
 }
 
+// SetUserStatus moves a user to the given lifecycle status and records the transition in the audit log
+func (dao *UserDAO) SetUserStatus(ctx context.Context, userID string, status string) (*model.User, error) {
+	start := time.Now()
+	logger.Info("Setting user status", zap.String("userID", userID), zap.String("status", status))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	actor := changedBy(ctx, "")
+	var oldStatus string
+	result, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		query := `
+        MATCH (u:` + echo_neo4j.LabelUser + ` {id: $id})
+        WITH u, u.status AS oldStatus
+        SET u.status = $status,
+            u.updatedAt = $updatedAt,
+            u.updatedBy = $updatedBy
+        RETURN u, oldStatus
+        `
+		result, err := transaction.Run(query, map[string]interface{}{
+			"id":        userID,
+			"status":    status,
+			"updatedAt": time.Now().Format(time.RFC3339),
+			"updatedBy": actor,
+		})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+
+		if !result.Next() {
+			return nil, echo_errors.ErrUserNotFound
+		}
+
+		record := result.Record()
+		if previous, ok := record.Values[1].(string); ok {
+			oldStatus = previous
+		}
+		return mapNodeToUser(record.Values[0].(neo4j.Node))
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to set user status",
+			zap.Error(err),
+			zap.String("userID", userID),
+			zap.String("status", status),
+			zap.Duration("duration", duration))
+		return nil, classifyNeo4jError(err)
+	}
+
+	logger.Info("User status updated successfully",
+		zap.String("userID", userID),
+		zap.String("oldStatus", oldStatus),
+		zap.String("newStatus", status),
+		zap.Duration("duration", duration))
+
+	// Audit trail
+	changeDetails, _ := json.Marshal(map[string]interface{}{
+		"action": "status_changed",
+		"status": map[string]string{"old": oldStatus, "new": status},
+	})
+	auditLog := audit.AuditLog{
+		Timestamp:     time.Now(),
+		UserID:        actor,
+		Action:        "SET_USER_STATUS_" + strings.ToUpper(status),
+		ResourceID:    userID,
+		AccessGranted: true,
+		ChangeDetails: changeDetails,
+	}
+	if err := dao.AuditService.LogAccess(ctx, auditLog); err != nil {
+		logger.Error("Failed to create audit log", zap.Error(err))
+	}
+
+	return result.(*model.User), nil
+}
+
+// RecordLogin stamps the user's lastLogin with the current time, unless the stored one is less than
+// interval old. It reports whether lastLogin was stamped; a zero interval stamps every login.
+func (dao *UserDAO) RecordLogin(ctx context.Context, userID string, interval time.Duration) (time.Time, bool, error) {
+	start := time.Now()
+	loginAt := start.UTC()
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	// Timestamps are stored as UTC RFC 3339 strings, which order like the times they hold
+	recorded, err := session.WriteTransaction(func(transaction neo4j.Transaction) (interface{}, error) {
+		query := `
+        MATCH (u:` + echo_neo4j.LabelUser + ` {id: $id})
+        WITH u, u.lastLogin IS NULL OR u.lastLogin <= $staleBefore AS stale
+        FOREACH (_ IN CASE WHEN stale THEN [1] ELSE [] END |
+            SET u.lastLogin = $lastLogin
+        )
+        RETURN stale
+        `
+		result, err := transaction.Run(query, map[string]interface{}{
+			"id":          userID,
+			"lastLogin":   loginAt.Format(time.RFC3339),
+			"staleBefore": loginAt.Add(-interval).Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, classifyNeo4jError(err)
+		}
+
+		if !result.Next() {
+			return nil, echo_errors.ErrUserNotFound
+		}
+		stale, _ := result.Record().Values[0].(bool)
+		return stale, nil
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Error("Failed to record user login",
+			zap.Error(err),
+			zap.String("userID", userID),
+			zap.Duration("duration", duration))
+		return time.Time{}, false, classifyNeo4jError(err)
+	}
+
+	if !recorded.(bool) {
+		return time.Time{}, false, nil
+	}
+	logger.Info("User login recorded",
+		zap.String("userID", userID),
+		zap.Duration("duration", duration))
+	return loginAt, true, nil
+}
+
+// ListInactiveUsers returns users whose last login is before the given time, including users who never logged in
+func (dao *UserDAO) ListInactiveUsers(ctx context.Context, since time.Time, limit int, offset int) ([]*model.User, error) {
+	start := time.Now()
+	logger.Info("Listing inactive users", zap.Time("since", since), zap.Int("limit", limit), zap.Int("offset", offset))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close()
+
+	query := `
+    MATCH (u:` + echo_neo4j.LabelUser + `)
+    WHERE u.lastLogin IS NULL OR u.lastLogin < $since
+    RETURN u
+    ORDER BY u.lastLogin ASC, u.createdAt ASC
+    SKIP $offset
+    LIMIT $limit
+    `
+	result, err := session.Run(query, map[string]interface{}{
+		"since":  since.UTC().Format(time.RFC3339),
+		"limit":  limit,
+		"offset": offset,
+	})
+	if err != nil {
+		logger.Error("Failed to execute list inactive users query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrDatabaseOperation
+	}
+
+	var users []*model.User
+	for result.Next() {
+		node := result.Record().Values[0].(neo4j.Node)
+		user, err := mapNodeToUser(node)
+		if err != nil {
+			logger.Error("Failed to map user node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		users = append(users, user)
+	}
+
+	logger.Info("Inactive users listed successfully",
+		zap.Int("count", len(users)),
+		zap.Duration("duration", time.Since(start)))
+
+	return users, nil
+}
+
+// Exists reports whether an user with the given ID exists, without reading the whole node
+func (dao *UserDAO) Exists(ctx context.Context, userID string) (bool, error) {
+	return nodeExists(ctx, dao.Driver, echo_neo4j.LabelUser, userID)
+}
+
 func (dao *UserDAO) GetUser(ctx context.Context, userID string) (*model.User, error) {
 	start := time.Now()
 	logger.Info("Retrieving user", zap.String("userID", userID))
@@ -501,13 +710,56 @@ func (dao *UserDAO) GetUser(ctx context.Context, userID string) (*model.User, er
 	return nil, echo_errors.ErrUserNotFound
 }
 
+// GetUsersByIDs retrieves the users among userIDs, with their roles, in one query. IDs with no user are
+// left out of the result, which follows no particular order.
+func (dao *UserDAO) GetUsersByIDs(ctx context.Context, userIDs []string) ([]*model.User, error) {
+	start := time.Now()
+	logger.Info("Retrieving users by ID", zap.Int("count", len(userIDs)))
+
+	query := `
+    MATCH (u:` + echo_neo4j.LabelUser + `)
+    WHERE u.id IN $ids
+    OPTIONAL MATCH (u)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + `)
+    WITH u, COLLECT(r.id) AS roleIds
+    RETURN u, roleIds
+    `
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{"ids": userIDs})
+	if err != nil {
+		logger.Error("Failed to execute get users by ID query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	users := make([]*model.User, 0, len(records))
+	for _, record := range records {
+		user, err := mapNodeToUser(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map user node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+
+		roleIds, _ := record.Values[1].([]interface{})
+		user.RoleIds = make([]string, len(roleIds))
+		for i, roleID := range roleIds {
+			user.RoleIds[i] = roleID.(string)
+		}
+		users = append(users, user)
+	}
+
+	logger.Info("Users retrieved by ID successfully",
+		zap.Int("requested", len(userIDs)),
+		zap.Int("found", len(users)),
+		zap.Duration("duration", time.Since(start)))
+	return users, nil
+}
+
 func (dao *UserDAO) ListUsers(ctx context.Context, limit int, offset int) ([]*model.User, error) {
 	start := time.Now()
 	logger.Info("Listing users", zap.Int("limit", limit), zap.Int("offset", offset))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	query := `
     MATCH (u:` + echo_neo4j.LabelUser + `)
     OPTIONAL MATCH (u)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + `)
@@ -518,7 +770,7 @@ func (dao *UserDAO) ListUsers(ctx context.Context, limit int, offset int) ([]*mo
     LIMIT $limit
     `
 
-	result, err := session.Run(query, map[string]interface{}{
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{
 		"limit":  limit,
 		"offset": offset,
 	})
@@ -526,12 +778,11 @@ func (dao *UserDAO) ListUsers(ctx context.Context, limit int, offset int) ([]*mo
 		logger.Error("Failed to execute list users query",
 			zap.Error(err),
 			zap.Duration("duration", time.Since(start)))
-		return nil, echo_errors.ErrDatabaseOperation
+		return nil, readFailure(ctx)
 	}
 
 	var users []*model.User
-	for result.Next() {
-		record := result.Record()
+	for _, record := range records {
 		node := record.Values[0].(neo4j.Node)
 		roleIds := record.Values[1].([]interface{})
 		user, err := mapNodeToUser(node)
@@ -556,6 +807,177 @@ func (dao *UserDAO) ListUsers(ctx context.Context, limit int, offset int) ([]*mo
 	return users, nil
 }
 
+// ListUsersInScope lists, like ListUsers, the users a scoped admin manages: those of the scope's
+// organization and, for a department scope, of the departments in its subtree
+func (dao *UserDAO) ListUsersInScope(ctx context.Context, scope model.ScopedAdmin, limit int, offset int) ([]*model.User, error) {
+	start := time.Now()
+	logger.Info("Listing users in admin scope",
+		zap.String("organizationID", scope.OrganizationID),
+		zap.String("departmentID", scope.DepartmentID),
+		zap.Int("limit", limit),
+		zap.Int("offset", offset))
+
+	query := `
+    MATCH (u:` + echo_neo4j.LabelUser + ` {organizationID: $organizationID})
+    WHERE $departmentIDs IS NULL OR u.departmentID IN $departmentIDs
+    OPTIONAL MATCH (u)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + `)
+    WITH u, COLLECT(r.id) AS roleIds
+    RETURN u, roleIds
+    ORDER BY u.createdAt DESC
+    SKIP $offset
+    LIMIT $limit
+    `
+
+	records, err := readRecords(ctx, dao.Driver, query, map[string]interface{}{
+		"organizationID": scope.OrganizationID,
+		"departmentIDs":  scopeDepartmentIDs(scope),
+		"limit":          limit,
+		"offset":         offset,
+	})
+	if err != nil {
+		logger.Error("Failed to execute list users in scope query",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, readFailure(ctx)
+	}
+
+	users := []*model.User{}
+	for _, record := range records {
+		user, err := mapNodeToUser(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map user node to struct",
+				zap.Error(err),
+				zap.Duration("duration", time.Since(start)))
+			return nil, echo_errors.ErrInternalServer
+		}
+		for _, roleID := range record.Values[1].([]interface{}) {
+			user.RoleIds = append(user.RoleIds, roleID.(string))
+		}
+		users = append(users, user)
+	}
+
+	logger.Info("Users in admin scope listed successfully",
+		zap.Int("count", len(users)),
+		zap.Duration("duration", time.Since(start)))
+
+	return users, nil
+}
+
+// GetUsersByOrganization returns a page of the users working for an organization, ordered by username,
+// and the organization's total user count
+func (dao *UserDAO) GetUsersByOrganization(ctx context.Context, orgID string, limit int, offset int) ([]*model.User, int64, error) {
+	start := time.Now()
+	logger.Info("Listing organization users", zap.String("orgID", orgID), zap.Int("limit", limit), zap.Int("offset", offset))
+
+	countQuery := `
+	OPTIONAL MATCH (o:` + echo_neo4j.LabelOrganization + ` {id: $orgID})
+	OPTIONAL MATCH (o)<-[:` + echo_neo4j.RelWorksFor + `]-(u:` + echo_neo4j.LabelUser + `)
+	RETURN o IS NOT NULL AS orgExists, count(u) AS total
+	`
+	records, err := readRecords(ctx, dao.Driver, countQuery, map[string]interface{}{"orgID": orgID})
+	if err != nil || len(records) == 0 {
+		logger.Error("Failed to count organization users",
+			zap.Error(err),
+			zap.String("orgID", orgID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, 0, readFailure(ctx)
+	}
+	if exists, _ := records[0].Values[0].(bool); !exists {
+		return nil, 0, echo_errors.ErrOrganizationNotFound
+	}
+	total, _ := records[0].Values[1].(int64)
+
+	paginationClause, params := helper_util.BuildPagination(limit, offset)
+	params["orgID"] = orgID
+	pageQuery := `
+	MATCH (:` + echo_neo4j.LabelOrganization + ` {id: $orgID})<-[:` + echo_neo4j.RelWorksFor + `]-(u:` + echo_neo4j.LabelUser + `)
+	RETURN u
+	ORDER BY u.username, u.id` + paginationClause
+
+	records, err = readRecords(ctx, dao.Driver, pageQuery, params)
+	if err != nil {
+		logger.Error("Failed to list organization users",
+			zap.Error(err),
+			zap.String("orgID", orgID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, 0, readFailure(ctx)
+	}
+
+	users := make([]*model.User, 0, len(records))
+	for _, record := range records {
+		user, err := mapNodeToUser(record.Values[0].(neo4j.Node))
+		if err != nil {
+			logger.Error("Failed to map user node to struct", zap.Error(err))
+			return nil, 0, echo_errors.ErrInternalServer
+		}
+		users = append(users, user)
+	}
+
+	logger.Info("Organization users listed successfully",
+		zap.String("orgID", orgID),
+		zap.Int("count", len(users)),
+		zap.Int64("total", total),
+		zap.Duration("duration", time.Since(start)))
+	return users, total, nil
+}
+
+// GetEffectivePermissions resolves the user's groups through SUBGROUP_OF nesting, the roles held directly or
+// through any of those groups, and the permissions those roles grant
+func (dao *UserDAO) GetEffectivePermissions(ctx context.Context, userID string) (*model.EffectivePermissions, error) {
+	start := time.Now()
+	logger.Info("Resolving effective permissions", zap.String("userID", userID))
+
+	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close()
+
+	query := `
+    MATCH (u:` + echo_neo4j.LabelUser + ` {id: $id})
+    OPTIONAL MATCH (u)-[:` + echo_neo4j.RelBelongsToGroup + `]->(:` + echo_neo4j.LabelGroup + `)-[:` + echo_neo4j.RelSubgroupOf + `*0..]->(g:` + echo_neo4j.LabelGroup + `)
+    WITH u, collect(DISTINCT g.id) AS groupIDs
+    OPTIONAL MATCH (u)-[:` + echo_neo4j.RelHasRole + `]->(direct:` + echo_neo4j.LabelRole + `)
+    WITH groupIDs, collect(DISTINCT direct.id) AS directRoleIDs
+    OPTIONAL MATCH (g:` + echo_neo4j.LabelGroup + `)-[:` + echo_neo4j.RelHasRole + `]->(inherited:` + echo_neo4j.LabelRole + `)
+    WHERE g.id IN groupIDs
+    WITH groupIDs, directRoleIDs, collect(DISTINCT inherited.id) AS inheritedRoleIDs
+    WITH groupIDs, directRoleIDs + [id IN inheritedRoleIDs WHERE NOT id IN directRoleIDs] AS roleIDs
+    OPTIONAL MATCH (r:` + echo_neo4j.LabelRole + `)-[:` + echo_neo4j.RelHasPermission + `]->(p:` + echo_neo4j.LabelPermission + `)
+    WHERE r.id IN roleIDs
+    RETURN groupIDs, roleIDs, collect(DISTINCT p.id) AS permissionIDs
+    `
+	result, err := session.Run(query, map[string]interface{}{"id": userID})
+	if err != nil {
+		logger.Error("Failed to execute effective permissions query",
+			zap.Error(err),
+			zap.String("userID", userID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrDatabaseOperation
+	}
+
+	if !result.Next() {
+		logger.Warn("User not found",
+			zap.String("userID", userID),
+			zap.Duration("duration", time.Since(start)))
+		return nil, echo_errors.ErrUserNotFound
+	}
+
+	record := result.Record()
+	effective := &model.EffectivePermissions{
+		UserID:        userID,
+		GroupIDs:      toStringSlice(record.Values[0]),
+		RoleIDs:       toStringSlice(record.Values[1]),
+		PermissionIDs: toStringSlice(record.Values[2]),
+	}
+
+	logger.Info("Effective permissions resolved successfully",
+		zap.String("userID", userID),
+		zap.Int("groupCount", len(effective.GroupIDs)),
+		zap.Int("roleCount", len(effective.RoleIDs)),
+		zap.Int("permissionCount", len(effective.PermissionIDs)),
+		zap.Duration("duration", time.Since(start)))
+
+	return effective, nil
+}
+
 // Helper function to map Neo4j Node to User struct
 func mapNodeToUser(node neo4j.Node) (*model.User, error) {
 	props := node.Props
@@ -573,35 +995,45 @@ func mapNodeToUser(node neo4j.Node) (*model.User, error) {
 	user.OrganizationID = props["organizationID"].(string)
 	user.DepartmentID = props["departmentID"].(string)
 
-	// Convert role IDs to string slice
-	roleIDs := []string{}
-	roleIDsInterface, ok := props["roleIds"].([]interface{})
-	if ok {
-		for _, id := range roleIDsInterface {
-			roleIDs = append(roleIDs, id.(string))
-		}
-	}
-
-	// Convert group IDs to string slice
-	groupIDs := []string{}
-	groupIDsInterface, ok := props["groupIds"].([]interface{})
-	if ok {
-		for _, id := range groupIDsInterface {
-			groupIDs = append(groupIDs, id.(string))
-		}
-	}
-
 	attributesJSON := props["attributes"].(string)
 	if err := json.Unmarshal([]byte(attributesJSON), &user.Attributes); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal user attributes: %w", err)
 	}
+	if attributeGroupID, ok := props["attributeGroupID"].(string); ok {
+		user.AttributeGroupID = attributeGroupID
+	}
+	if status, ok := props["status"].(string); ok {
+		user.Status = status
+	}
+	if clearance, ok := props["clearance"].(string); ok {
+		user.Clearance = clearance
+	}
+	if lastLogin, ok := props["lastLogin"].(string); ok {
+		if t, err := helper_util.ParseTime(lastLogin); err == nil {
+			user.LastLogin = &t
+		}
+	}
 
 	user.CreatedAt, _ = helper_util.ParseTime(props["createdAt"].(string))
 	user.UpdatedAt, _ = helper_util.ParseTime(props["updatedAt"].(string))
+	user.CreatedBy, _ = props["createdBy"].(string)
+	user.UpdatedBy, _ = props["updatedBy"].(string)
 
 	return user, nil
 }
 
+// Helper function to convert a Neo4j list of strings to a string slice
+func toStringSlice(value interface{}) []string {
+	items, _ := value.([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
 // Helper function to create change details for audit log
 func createUserChangeDetails(oldUser, newUser *model.User) json.RawMessage {
 	changes := make(map[string]interface{})
@@ -623,13 +1055,13 @@ func createUserChangeDetails(oldUser, newUser *model.User) json.RawMessage {
 	return changeDetails
 }
 
+// userSortFields lists the properties user searches may be ordered by
+var userSortFields = []string{"name", "username", "email", "userType", "status", "lastLogin", "createdAt", "updatedAt"}
+
 func (dao *UserDAO) SearchUsers(ctx context.Context, criteria model.UserSearchCriteria) ([]*model.User, error) {
 	start := time.Now()
 	logger.Info("Searching users", zap.Any("criteria", criteria))
 
-	session := dao.Driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close()
-
 	// Build the query dynamically based on the provided criteria
 	query := `MATCH (u:` + echo_neo4j.LabelUser + `)`
 	whereClauses := []string{}
@@ -657,26 +1089,27 @@ func (dao *UserDAO) SearchUsers(ctx context.Context, criteria model.UserSearchCr
 		params["userType"] = criteria.UserType
 	}
 	if criteria.Status != "" {
-		whereClauses = append(whereClauses, "u.status = $status")
-		params["status"] = criteria.Status
+		whereClauses = append(whereClauses, "toLower(coalesce(u.status, $activeStatus)) = $status")
+		params["status"] = model.NormalizeUserStatus(criteria.Status)
+		params["activeStatus"] = model.UserStatusActive
 	}
 	if criteria.OrganizationID != "" {
-		query += ` MATCH (u)-[:WORKS_FOR]->(o:ORGANIZATION)`
+		query += ` MATCH (u)-[:` + echo_neo4j.RelWorksFor + `]->(o:` + echo_neo4j.LabelOrganization + `)`
 		whereClauses = append(whereClauses, "o.id = $organizationId")
 		params["organizationId"] = criteria.OrganizationID
 	}
 	if criteria.DepartmentID != "" {
-		query += ` MATCH (u)-[:MEMBER_OF]->(d:DEPARTMENT)`
+		query += ` MATCH (u)-[:` + echo_neo4j.RelMemberOf + `]->(d:` + echo_neo4j.LabelDepartment + `)`
 		whereClauses = append(whereClauses, "d.id = $departmentId")
 		params["departmentId"] = criteria.DepartmentID
 	}
 	if criteria.RoleID != "" {
-		query += ` MATCH (u)-[:HAS_ROLE]->(r:ROLE)`
+		query += ` MATCH (u)-[:` + echo_neo4j.RelHasRole + `]->(r:` + echo_neo4j.LabelRole + `)`
 		whereClauses = append(whereClauses, "r.id = $roleId")
 		params["roleId"] = criteria.RoleID
 	}
 	if criteria.GroupID != "" {
-		query += ` MATCH (u)-[:BELONGS_TO_GROUP]->(g:GROUP)`
+		query += ` MATCH (u)-[:` + echo_neo4j.RelBelongsToGroup + `]->(g:` + echo_neo4j.LabelGroup + `)`
 		whereClauses = append(whereClauses, "g.id = $groupId")
 		params["groupId"] = criteria.GroupID
 	}
@@ -690,7 +1123,7 @@ func (dao *UserDAO) SearchUsers(ctx context.Context, criteria model.UserSearchCr
 	}
 	if criteria.LastLoginAfter != nil {
 		whereClauses = append(whereClauses, "u.lastLogin > $lastLoginAfter")
-		params["lastLoginAfter"] = criteria.LastLoginAfter.Format(time.RFC3339)
+		params["lastLoginAfter"] = criteria.LastLoginAfter.UTC().Format(time.RFC3339)
 	}
 
 	// Add WHERE clause if any conditions exist
@@ -702,30 +1135,29 @@ func (dao *UserDAO) SearchUsers(ctx context.Context, criteria model.UserSearchCr
 	query += " WITH u"
 
 	// Add ORDER BY clause
-	if criteria.SortBy != "" {
-		query += ` ORDER BY u.` + criteria.SortBy
-		if strings.ToLower(criteria.SortOrder) == "desc" {
-			query += " DESC"
-		} else {
-			query += " ASC"
-		}
-	} else {
-		query += " ORDER BY u.createdAt DESC"
+	sortClause, err := helper_util.SafeSortClause("u", criteria.SortBy, criteria.SortOrder, userSortFields)
+	if err != nil {
+		logger.Warn("Invalid sort field for user search", zap.String("sortBy", criteria.SortBy))
+		return nil, err
 	}
+	if sortClause == "" {
+		sortClause = " ORDER BY u.createdAt DESC"
+	}
+	query += sortClause
 
 	// Add SKIP and LIMIT clauses
-	query += ` SKIP $offset LIMIT $limit`
+	paginationClause, paginationParams := helper_util.BuildPagination(criteria.Limit, criteria.Offset)
+	query += paginationClause
+	helper_util.MergeParams(params, paginationParams)
 
 	// Add RETURN clause
 	query += " RETURN u"
-	params["offset"] = criteria.Offset
-	params["limit"] = criteria.Limit
 
 	// Log the query
 	logger.Debug("Search users query", zap.String("query", query), zap.Any("params", params))
 
 	// Execute the query
-	result, err := session.Run(query, params)
+	records, err := readRecords(ctx, dao.Driver, query, params)
 	if err != nil {
 		logger.Error("Failed to execute search users query",
 			zap.Error(err),
@@ -736,8 +1168,8 @@ func (dao *UserDAO) SearchUsers(ctx context.Context, criteria model.UserSearchCr
 	}
 
 	var users []*model.User
-	for result.Next() {
-		node := result.Record().Values[0].(neo4j.Node)
+	for _, record := range records {
+		node := record.Values[0].(neo4j.Node)
 		user, err := mapNodeToUser(node)
 		if err != nil {
 			logger.Error("Failed to map user node to struct",
@@ -0,0 +1,196 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestSearchUsersByLastLoginAfterRecordedLogin(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	tx := &mock.MockTransaction{}
+	session := &mock.MockTxSession{Tx: tx}
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	userDAO := &dao.UserDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	// The stored lastLogin is captured from the RecordLogin write and served back by the search
+	var storedLastLogin string
+	tx.On("Run", queryContaining("SET u.lastLogin = $lastLogin"), testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) {
+			storedLastLogin = args.Get(1).(map[string]interface{})["lastLogin"].(string)
+		}).
+		Return(resultWithRecord(true), nil)
+
+	before := time.Now().Add(-time.Hour)
+	loginAt, recorded, err := userDAO.RecordLogin(ctx, "u1", 0)
+	assert.NoError(t, err)
+	assert.True(t, recorded)
+	assert.Equal(t, loginAt.Format(time.RFC3339), storedLastLogin)
+
+	searchResult := &mock.MockResult{}
+	searchResult.On("Next").Return(true).Once()
+	searchResult.On("Next").Return(false)
+	searchResult.On("Record").Return(&neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+		"id":             "u1",
+		"name":           "Jane Doe",
+		"username":       "jdoe",
+		"email":          "jdoe@example.com",
+		"userType":       "DepartmentUser",
+		"organizationID": "org1",
+		"departmentID":   "dept1",
+		"attributes":     "{}",
+		"status":         model.UserStatusActive,
+		"lastLogin":      storedLastLogin,
+		"createdAt":      "2024-01-01T00:00:00Z",
+		"updatedAt":      "2024-01-01T00:00:00Z",
+	}}}})
+	session.On("Run", queryContaining("u.lastLogin > $lastLoginAfter"), testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+		lastLoginAfter, _ := params["lastLoginAfter"].(string)
+		return lastLoginAfter != "" && lastLoginAfter < storedLastLogin
+	}), testify_mock.Anything).Return(searchResult, nil)
+
+	users, err := userDAO.SearchUsers(ctx, model.UserSearchCriteria{LastLoginAfter: &before, Limit: 10})
+
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) && assert.NotNil(t, users[0].LastLogin) {
+		assert.True(t, users[0].LastLogin.Equal(loginAt.Truncate(time.Second)))
+	}
+}
+
+func TestRecordLoginSkipsRecentLogins(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	tx := &mock.MockTransaction{}
+	session := &mock.MockTxSession{Tx: tx}
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	userDAO := &dao.UserDAO{Driver: driver, AuditService: &mock.MockAuditService{}}
+
+	// The stored lastLogin is newer than staleBefore, so the query leaves it and answers false
+	var params map[string]interface{}
+	tx.On("Run", queryContaining("u.lastLogin <= $staleBefore"), testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) { params = args.Get(1).(map[string]interface{}) }).
+		Return(resultWithRecord(false), nil)
+
+	loginAt, recorded, err := userDAO.RecordLogin(context.Background(), "u1", 5*time.Minute)
+
+	assert.NoError(t, err)
+	assert.False(t, recorded)
+	assert.True(t, loginAt.IsZero())
+	lastLogin, err := time.Parse(time.RFC3339, params["lastLogin"].(string))
+	if assert.NoError(t, err) {
+		assert.Equal(t, lastLogin.Add(-5*time.Minute).Format(time.RFC3339), params["staleBefore"])
+	}
+}
+
+func TestUpdateUserReplacesRoleRelationships(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	userProps := map[string]any{
+		"id":             "u1",
+		"name":           "Jane Doe",
+		"username":       "jdoe",
+		"email":          "jdoe@example.com",
+		"userType":       "DepartmentUser",
+		"organizationID": "org1",
+		"departmentID":   "dept1",
+		"attributes":     "{}",
+		"createdAt":      "2024-01-01T00:00:00Z",
+		"updatedAt":      "2024-01-01T00:00:00Z",
+	}
+	user := model.User{
+		ID:             "u1",
+		Name:           "Jane Doe",
+		Username:       "jdoe",
+		Email:          "jdoe@example.com",
+		UserType:       "DepartmentUser",
+		OrganizationID: "org1",
+		DepartmentID:   "dept1",
+	}
+
+	newUserDAO := func(updated *mock.MockResult) (*dao.UserDAO, *mock.MockTransaction) {
+		tx := &mock.MockTransaction{}
+		session := &mock.MockTxSession{Tx: tx}
+		session.On("Close").Return(nil)
+		session.On("Run", queryContaining("COLLECT(r.id) AS roleIds"), testify_mock.Anything, testify_mock.Anything).
+			Return(resultWithRecord(neo4j.Node{Props: userProps}, []interface{}{"viewer"}), nil)
+		tx.On("Run", queryContaining("SET u.name = $name"), testify_mock.Anything).Return(updated, nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+		return &dao.UserDAO{Driver: driver, AuditService: auditService}, tx
+	}
+
+	t.Run("RemovesLastRole", func(t *testing.T) {
+		userDAO, tx := newUserDAO(resultWithRecord(neo4j.Node{Props: userProps}, []interface{}{}))
+		user := user
+		user.RoleIds = []string{}
+
+		updated, err := userDAO.UpdateUser(ctx, user)
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, updated) {
+			assert.Empty(t, updated.RoleIds)
+		}
+		query := tx.Calls[0].Arguments.String(0)
+		params := tx.Calls[0].Arguments.Get(1).(map[string]interface{})
+		assert.Contains(t, query, "DELETE oldRoleRel")
+		assert.Equal(t, []string{}, params["roleIds"])
+		assert.NotContains(t, query, "DELETE oldGroupRel")
+	})
+
+	t.Run("KeepsRolesWhenNotListed", func(t *testing.T) {
+		userDAO, tx := newUserDAO(resultWithRecord(neo4j.Node{Props: userProps}, []interface{}{"viewer"}))
+
+		updated, err := userDAO.UpdateUser(ctx, user)
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, updated) {
+			assert.Equal(t, []string{"viewer"}, updated.RoleIds)
+		}
+		query := tx.Calls[0].Arguments.String(0)
+		params := tx.Calls[0].Arguments.Get(1).(map[string]interface{})
+		assert.NotContains(t, query, "DELETE oldRoleRel")
+		assert.NotContains(t, params, "roleIds")
+	})
+
+	t.Run("ClearsGroupsAndKeepsRoles", func(t *testing.T) {
+		userDAO, tx := newUserDAO(resultWithRecord(neo4j.Node{Props: userProps}, []interface{}{"viewer"}))
+		user := user
+		user.GroupIds = []string{}
+
+		updated, err := userDAO.UpdateUser(ctx, user)
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, updated) {
+			assert.Equal(t, []string{"viewer"}, updated.RoleIds)
+		}
+		query := tx.Calls[0].Arguments.String(0)
+		params := tx.Calls[0].Arguments.Get(1).(map[string]interface{})
+		assert.Contains(t, query, "DELETE oldGroupRel")
+		assert.Equal(t, []string{}, params["groupIds"])
+		assert.NotContains(t, query, "DELETE oldRoleRel")
+		assert.NotContains(t, params, "roleIds")
+	})
+}
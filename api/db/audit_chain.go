@@ -0,0 +1,39 @@
+// api/db/audit_chain.go
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/dev-mohitbeniwal/echo/api/model"
+)
+
+// auditChainHeadKey holds the head of the audit hash chain. It never expires, as losing it would
+// restart the chain.
+const auditChainHeadKey = "audit:chain_head"
+
+// SetAuditChainHead records the latest audit log written to the hash chain
+func SetAuditChainHead(ctx context.Context, head model.AuditChainHead) error {
+	headJSON, err := json.Marshal(head)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit chain head: %w", err)
+	}
+	if err := RedisClient.Set(ctx, auditChainHeadKey, headJSON, 0).Err(); err != nil {
+		return fmt.Errorf("failed to set audit chain head: %w", err)
+	}
+	return nil
+}
+
+// GetAuditChainHead returns the latest audit log written to the hash chain, or nil before the first
+func GetAuditChainHead(ctx context.Context) (*model.AuditChainHead, error) {
+	headJSON, err := RedisClient.Get(ctx, auditChainHeadKey).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+	return decodeCachedJSON[model.AuditChainHead](headJSON)
+}
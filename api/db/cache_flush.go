@@ -0,0 +1,51 @@
+// api/db/cache_flush.go
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// FlushCached drops the entry cached under "<kind>:<id>", or every entry of kind when id is empty, and
+// returns how many keys were dropped. Flushing every decision drops their invalidation indexes too.
+// Keys are found and deleted a SCAN batch at a time, see ScanKeysFunc.
+func FlushCached(ctx context.Context, kind, id string) (int, error) {
+	if !IsCacheKind(kind) {
+		return 0, fmt.Errorf("unknown cache kind %q", kind)
+	}
+
+	if id != "" {
+		deleted, err := RedisClient.Del(ctx, fmt.Sprintf("%s:%s", kind, id)).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete %s entry from cache: %w", kind, err)
+		}
+		logger.Debug("Cache entry flushed", zap.String("kind", kind), zap.String("id", id))
+		return int(deleted), nil
+	}
+
+	patterns := []string{kind + ":*"}
+	if kind == CacheKindDecision {
+		patterns = append(patterns, decisionIndexKey("*"))
+	}
+	deleted := 0
+	for _, pattern := range patterns {
+		err := ScanKeysFunc(ctx, pattern, 0, func(keys []string) error {
+			count, err := RedisClient.Del(ctx, keys...).Result()
+			if err != nil {
+				return fmt.Errorf("failed to delete %s entries from cache: %w", kind, err)
+			}
+			deleted += int(count)
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	logger.Debug("Cache kind flushed", zap.String("kind", kind), zap.Int("count", deleted))
+	return deleted, nil
+}
@@ -0,0 +1,205 @@
+// api/db/context_driver.go
+package db
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// ContextDriver serves the neo4j.Driver API the DAOs are written against from a driver with context
+// support, as the driver's own legacy API does, and also opens sessions honoring a context. Heavy
+// reads run in those, so a request that is given up on stops its query rather than leaving it to
+// run until the server's timeout. Both kinds of session share one connection pool.
+type ContextDriver struct {
+	delegate neo4j.DriverWithContext
+}
+
+// NewContextDriver wraps delegate
+func NewContextDriver(delegate neo4j.DriverWithContext) *ContextDriver {
+	return &ContextDriver{delegate: delegate}
+}
+
+func (d *ContextDriver) Target() url.URL {
+	return d.delegate.Target()
+}
+
+func (d *ContextDriver) NewSession(config neo4j.SessionConfig) neo4j.Session {
+	return &legacySession{delegate: d.delegate.NewSession(context.Background(), config)}
+}
+
+// NewContextSession opens a session whose queries stop once the context passed to them is done
+func (d *ContextDriver) NewContextSession(ctx context.Context, config neo4j.SessionConfig) (neo4j.SessionWithContext, bool) {
+	return d.delegate.NewSession(ctx, config), true
+}
+
+func (d *ContextDriver) VerifyConnectivity() error {
+	return d.delegate.VerifyConnectivity(context.Background())
+}
+
+func (d *ContextDriver) Close() error {
+	return d.delegate.Close(context.Background())
+}
+
+func (d *ContextDriver) IsEncrypted() bool {
+	return d.delegate.IsEncrypted()
+}
+
+// contextSessions is implemented by the drivers opening sessions that honor a context
+type contextSessions interface {
+	NewContextSession(ctx context.Context, config neo4j.SessionConfig) (neo4j.SessionWithContext, bool)
+}
+
+// newContextSession opens a session honoring a context on driver, and reports false when driver
+// cannot open one
+func newContextSession(ctx context.Context, driver neo4j.Driver, config neo4j.SessionConfig) (neo4j.SessionWithContext, bool) {
+	if sessions, ok := driver.(contextSessions); ok {
+		return sessions.NewContextSession(ctx, config)
+	}
+	return nil, false
+}
+
+// legacySession runs a session with context support without one
+type legacySession struct {
+	delegate neo4j.SessionWithContext
+}
+
+func (s *legacySession) LastBookmarks() neo4j.Bookmarks {
+	return s.delegate.LastBookmarks()
+}
+
+func (s *legacySession) LastBookmark() string {
+	bookmarks := s.delegate.LastBookmarks()
+	if len(bookmarks) == 0 {
+		return ""
+	}
+	return bookmarks[len(bookmarks)-1]
+}
+
+func (s *legacySession) BeginTransaction(configurers ...func(*neo4j.TransactionConfig)) (neo4j.Transaction, error) {
+	transaction, err := s.delegate.BeginTransaction(context.Background(), configurers...)
+	if err != nil {
+		return nil, err
+	}
+	return &legacyTransaction{delegate: transaction}, nil
+}
+
+func (s *legacySession) ReadTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	return s.delegate.ExecuteRead(context.Background(), managedWork(work), configurers...)
+}
+
+func (s *legacySession) WriteTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	return s.delegate.ExecuteWrite(context.Background(), managedWork(work), configurers...)
+}
+
+func (s *legacySession) Run(cypher string, params map[string]any, configurers ...func(*neo4j.TransactionConfig)) (neo4j.Result, error) {
+	result, err := s.delegate.Run(context.Background(), cypher, params, configurers...)
+	if err != nil {
+		return nil, err
+	}
+	return &legacyResult{delegate: result}, nil
+}
+
+func (s *legacySession) Close() error {
+	return s.delegate.Close(context.Background())
+}
+
+// managedWork runs work, written for a legacy transaction, in a managed one
+func managedWork(work neo4j.TransactionWork) neo4j.ManagedTransactionWork {
+	return func(transaction neo4j.ManagedTransaction) (any, error) {
+		return work(&legacyManagedTransaction{delegate: transaction})
+	}
+}
+
+// legacyTransaction runs an explicit transaction without a context
+type legacyTransaction struct {
+	delegate neo4j.ExplicitTransaction
+}
+
+func (t *legacyTransaction) Run(cypher string, params map[string]any) (neo4j.Result, error) {
+	result, err := t.delegate.Run(context.Background(), cypher, params)
+	if err != nil {
+		return nil, err
+	}
+	return &legacyResult{delegate: result}, nil
+}
+
+func (t *legacyTransaction) Commit() error {
+	return t.delegate.Commit(context.Background())
+}
+
+func (t *legacyTransaction) Rollback() error {
+	return t.delegate.Rollback(context.Background())
+}
+
+func (t *legacyTransaction) Close() error {
+	return t.delegate.Close(context.Background())
+}
+
+// legacyManagedTransaction runs a managed transaction without a context. The session commits or rolls
+// it back once the work returns, so the work may not.
+type legacyManagedTransaction struct {
+	delegate neo4j.ManagedTransaction
+}
+
+func (t *legacyManagedTransaction) Run(cypher string, params map[string]any) (neo4j.Result, error) {
+	result, err := t.delegate.Run(context.Background(), cypher, params)
+	if err != nil {
+		return nil, err
+	}
+	return &legacyResult{delegate: result}, nil
+}
+
+func (t *legacyManagedTransaction) Commit() error {
+	return &neo4j.UsageError{Message: "Commit not allowed on retryable transaction"}
+}
+
+func (t *legacyManagedTransaction) Rollback() error {
+	return &neo4j.UsageError{Message: "Rollback not allowed on retryable transaction"}
+}
+
+func (t *legacyManagedTransaction) Close() error {
+	return &neo4j.UsageError{Message: "Close not allowed on retryable transaction"}
+}
+
+// legacyResult reads a result with context support without one
+type legacyResult struct {
+	delegate neo4j.ResultWithContext
+}
+
+func (r *legacyResult) Keys() ([]string, error) {
+	return r.delegate.Keys()
+}
+
+func (r *legacyResult) Next() bool {
+	return r.delegate.Next(context.Background())
+}
+
+func (r *legacyResult) NextRecord(record **neo4j.Record) bool {
+	return r.delegate.NextRecord(context.Background(), record)
+}
+
+func (r *legacyResult) PeekRecord(record **neo4j.Record) bool {
+	return r.delegate.PeekRecord(context.Background(), record)
+}
+
+func (r *legacyResult) Err() error {
+	return r.delegate.Err()
+}
+
+func (r *legacyResult) Record() *neo4j.Record {
+	return r.delegate.Record()
+}
+
+func (r *legacyResult) Collect() ([]*neo4j.Record, error) {
+	return r.delegate.Collect(context.Background())
+}
+
+func (r *legacyResult) Single() (*neo4j.Record, error) {
+	return r.delegate.Single(context.Background())
+}
+
+func (r *legacyResult) Consume() (neo4j.ResultSummary, error) {
+	return r.delegate.Consume(context.Background())
+}
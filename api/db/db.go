@@ -16,10 +16,14 @@ import (
 var Neo4jDriver neo4j.Driver
 
 func InitNeo4j() error {
-	var err error
 	uri := config.GetString("neo4j.uri")
-	logger.Info("Connecting to Neo4j at URI", zap.String("uri", uri))
-	Neo4jDriver, err = neo4j.NewDriver(
+	routing := config.GetBool("neo4j.routing.enabled")
+	if routing {
+		uri = RoutingURI(uri)
+	}
+	routers := config.GetStringSlice("neo4j.routing.addresses")
+	logger.Info("Connecting to Neo4j at URI", zap.String("uri", uri), zap.Bool("routing", routing), zap.Strings("routers", routers))
+	driver, err := neo4j.NewDriverWithContext(
 		uri,
 		neo4j.BasicAuth(
 			config.GetString("neo4j.username"),
@@ -27,15 +31,26 @@ func InitNeo4j() error {
 			"",
 		),
 		func(c *neo4j.Config) {
-			c.MaxConnectionLifetime = 30 * time.Minute
-			c.MaxConnectionPoolSize = 50
+			ApplyPoolConfig(c)
 			c.Log = neo4j.ConsoleLogger(neo4j.ERROR)
+			if routing && len(routers) > 0 {
+				// Seed the routing table discovery with the configured cluster members
+				c.AddressResolver = func(neo4j.ServerAddress) []neo4j.ServerAddress {
+					addresses := make([]neo4j.ServerAddress, 0, len(routers))
+					for _, router := range routers {
+						addresses = append(addresses, serverAddress(router))
+					}
+					return addresses
+				}
+			}
 		},
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create Neo4j driver: %w", err)
 	}
+	neo4jPool = NewPoolMonitor(NewContextDriver(driver), config.GetInt("neo4j.pool.max_size"), config.GetDuration("neo4j.pool.slow_acquisition_threshold"))
+	Neo4jDriver = NewRoutingDriver(neo4jPool, config.GetString("neo4j.routing.database"))
 
 	// Test the connection
 	_, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,90 @@
+// api/db/decision_cache.go
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+)
+
+// decisionIndexKey is the set of the decision keys cached under tag
+func decisionIndexKey(tag string) string {
+	return "decisions:" + tag
+}
+
+// CacheDecision caches an access decision under "decision:<key>" for ttl and indexes it under each of
+// tags, so InvalidateCachedDecisions can find it again. The indexes expire after indexTTL, which must be
+// at least the longest ttl any decision is cached for.
+func CacheDecision(ctx context.Context, key string, decision *model.AccessDecision, ttl, indexTTL time.Duration, tags []string) error {
+	decisionJSON, err := json.Marshal(decision)
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision: %w", err)
+	}
+
+	entryKey := fmt.Sprintf("%s:%s", CacheKindDecision, key)
+	pipe := RedisClient.Pipeline()
+	pipe.Set(ctx, entryKey, decisionJSON, ttl)
+	for _, tag := range tags {
+		pipe.SAdd(ctx, decisionIndexKey(tag), entryKey)
+		pipe.Expire(ctx, decisionIndexKey(tag), indexTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cache decision: %w", err)
+	}
+
+	logger.Debug("Decision cached successfully", zap.String("key", key), zap.Duration("ttl", ttl))
+	return nil
+}
+
+// GetCachedDecision returns the decision cached under key, or nil when there is none
+func GetCachedDecision(ctx context.Context, key string) (*model.AccessDecision, error) {
+	decisionJSON, err := RedisClient.Get(ctx, fmt.Sprintf("%s:%s", CacheKindDecision, key)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get decision from cache: %w", err)
+	}
+	return decodeCachedJSON[model.AccessDecision](decisionJSON)
+}
+
+// InvalidateCachedDecisions drops every decision indexed under any of tags, along with the indexes,
+// and returns how many decisions were dropped
+func InvalidateCachedDecisions(ctx context.Context, tags []string) (int, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	pipe := RedisClient.Pipeline()
+	members := make([]*redis.StringSliceCmd, len(tags))
+	for i, tag := range tags {
+		members[i] = pipe.SMembers(ctx, decisionIndexKey(tag))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to read decision indexes: %w", err)
+	}
+
+	var keys []string
+	decisions := map[string]bool{}
+	for i, tag := range tags {
+		for _, key := range members[i].Val() {
+			if !decisions[key] {
+				decisions[key] = true
+				keys = append(keys, key)
+			}
+		}
+		keys = append(keys, decisionIndexKey(tag))
+	}
+	if err := RedisClient.Del(ctx, keys...).Err(); err != nil {
+		return 0, fmt.Errorf("failed to delete decisions from cache: %w", err)
+	}
+
+	logger.Debug("Cached decisions invalidated", zap.Strings("tags", tags), zap.Int("count", len(decisions)))
+	return len(decisions), nil
+}
@@ -0,0 +1,44 @@
+// api/db/jobs.go
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/dev-mohitbeniwal/echo/api/model"
+)
+
+func jobKey(id string) string {
+	return "job:" + id
+}
+
+// SaveJob stores the state of a job under "job:<id>" for ttl, replacing the state stored before
+func SaveJob(ctx context.Context, job *model.Job, ttl time.Duration) error {
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	if err := RedisClient.Set(ctx, jobKey(job.ID), jobJSON, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+// GetJob returns the state of a job, or nil when there is none
+func GetJob(ctx context.Context, id string) (*model.Job, error) {
+	jobJSON, err := RedisClient.Get(ctx, jobKey(id)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	var job model.Job
+	if err := json.Unmarshal([]byte(jobJSON), &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+	}
+	return &job, nil
+}
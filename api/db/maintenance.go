@@ -0,0 +1,41 @@
+// api/db/maintenance.go
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// readOnlyKey is set while the API is read-only for maintenance. It never expires, so the mode holds
+// until it is turned off.
+const readOnlyKey = "maintenance:read_only"
+
+// SetReadOnly turns the read-only mode of every instance on or off
+func SetReadOnly(ctx context.Context, enabled bool) error {
+	var err error
+	if enabled {
+		err = RedisClient.Set(ctx, readOnlyKey, "1", 0).Err()
+	} else {
+		err = RedisClient.Del(ctx, readOnlyKey).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set read-only mode: %w", err)
+	}
+	return nil
+}
+
+// IsReadOnly reports whether the read-only mode is on. Without a Redis client it never is.
+func IsReadOnly(ctx context.Context) (bool, error) {
+	if RedisClient == nil {
+		return false, nil
+	}
+	err := RedisClient.Get(ctx, readOnlyKey).Err()
+	if err == redis.Nil {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get read-only mode: %w", err)
+	}
+	return true, nil
+}
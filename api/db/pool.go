@@ -0,0 +1,211 @@
+// api/db/pool.go
+package db
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/config"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// ApplyPoolConfig sizes the driver's connection pool from the neo4j.pool settings: how many
+// connections it may hold per server, how long a session waits for one and how long a connection
+// lives before it is replaced
+func ApplyPoolConfig(c *neo4j.Config) {
+	c.MaxConnectionPoolSize = config.GetInt("neo4j.pool.max_size")
+	c.ConnectionAcquisitionTimeout = config.GetDuration("neo4j.pool.acquisition_timeout")
+	c.MaxConnectionLifetime = config.GetDuration("neo4j.pool.max_lifetime")
+}
+
+// PoolStats is the connection pool usage as sessions see it. The driver keeps its pool to itself, so
+// a session counts as holding a connection from its first query until it is closed, and the wait for
+// a connection is measured up to the start of a transaction's work, or up to the server's first
+// response for queries run outside a transaction.
+type PoolStats struct {
+	MaxSize          int     `json:"max_size"`
+	InUse            int64   `json:"in_use"`
+	Idle             int64   `json:"idle"` // Connections of MaxSize no session holds
+	Acquisitions     int64   `json:"acquisitions"`
+	AverageWaitMs    float64 `json:"average_wait_ms"`
+	MaxWaitMs        float64 `json:"max_wait_ms"`
+	SlowAcquisitions int64   `json:"slow_acquisitions"` // Waits longer than the warning threshold
+}
+
+// PoolMonitor wraps a driver to measure how its sessions use the connection pool
+type PoolMonitor struct {
+	neo4j.Driver
+	maxSize       int
+	slowThreshold time.Duration
+
+	inUse        atomic.Int64
+	acquisitions atomic.Int64
+	totalWait    atomic.Int64
+	maxWait      atomic.Int64
+	slow         atomic.Int64
+}
+
+// NewPoolMonitor wraps driver, whose pool holds up to maxSize connections. Waits for a connection
+// longer than slowThreshold are logged; a zero threshold logs none.
+func NewPoolMonitor(driver neo4j.Driver, maxSize int, slowThreshold time.Duration) *PoolMonitor {
+	return &PoolMonitor{Driver: driver, maxSize: maxSize, slowThreshold: slowThreshold}
+}
+
+// NewSession opens a session on the wrapped driver whose queries are measured
+func (m *PoolMonitor) NewSession(config neo4j.SessionConfig) neo4j.Session {
+	return &monitoredSession{Session: m.Driver.NewSession(config), monitor: m}
+}
+
+// NewContextSession opens a session honoring a context on the wrapped driver, if it can, whose queries
+// run outside a transaction are measured
+func (m *PoolMonitor) NewContextSession(ctx context.Context, config neo4j.SessionConfig) (neo4j.SessionWithContext, bool) {
+	session, ok := newContextSession(ctx, m.Driver, config)
+	if !ok {
+		return nil, false
+	}
+	return &monitoredContextSession{SessionWithContext: session, monitor: m}, true
+}
+
+// Stats returns the pool usage measured so far
+func (m *PoolMonitor) Stats() PoolStats {
+	stats := PoolStats{
+		MaxSize:          m.maxSize,
+		InUse:            m.inUse.Load(),
+		Acquisitions:     m.acquisitions.Load(),
+		MaxWaitMs:        durationMs(time.Duration(m.maxWait.Load())),
+		SlowAcquisitions: m.slow.Load(),
+	}
+	if idle := int64(m.maxSize) - stats.InUse; idle > 0 {
+		stats.Idle = idle
+	}
+	if stats.Acquisitions > 0 {
+		stats.AverageWaitMs = durationMs(time.Duration(m.totalWait.Load() / stats.Acquisitions))
+	}
+	return stats
+}
+
+func (m *PoolMonitor) recordWait(wait time.Duration) {
+	m.acquisitions.Add(1)
+	m.totalWait.Add(int64(wait))
+	for {
+		longest := m.maxWait.Load()
+		if int64(wait) <= longest || m.maxWait.CompareAndSwap(longest, int64(wait)) {
+			break
+		}
+	}
+	if m.slowThreshold > 0 && wait > m.slowThreshold {
+		m.slow.Add(1)
+		logger.Warn("Slow Neo4j connection acquisition",
+			zap.Duration("wait", wait),
+			zap.Duration("threshold", m.slowThreshold),
+			zap.Int64("inUse", m.inUse.Load()),
+			zap.Int("maxSize", m.maxSize))
+	}
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// monitoredSession reports its wait for a connection, and holding one, to its monitor
+type monitoredSession struct {
+	neo4j.Session
+	monitor *PoolMonitor
+	active  bool
+}
+
+// acquire marks the session as holding a connection from its first query on
+func (s *monitoredSession) acquire() {
+	if !s.active {
+		s.active = true
+		s.monitor.inUse.Add(1)
+	}
+}
+
+func (s *monitoredSession) Run(cypher string, params map[string]any, configurers ...func(*neo4j.TransactionConfig)) (neo4j.Result, error) {
+	s.acquire()
+	start := time.Now()
+	result, err := s.Session.Run(cypher, params, configurers...)
+	s.monitor.recordWait(time.Since(start))
+	return result, err
+}
+
+func (s *monitoredSession) BeginTransaction(configurers ...func(*neo4j.TransactionConfig)) (neo4j.Transaction, error) {
+	s.acquire()
+	start := time.Now()
+	transaction, err := s.Session.BeginTransaction(configurers...)
+	s.monitor.recordWait(time.Since(start))
+	return transaction, err
+}
+
+func (s *monitoredSession) ReadTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	return s.Session.ReadTransaction(s.measured(work), configurers...)
+}
+
+func (s *monitoredSession) WriteTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	return s.Session.WriteTransaction(s.measured(work), configurers...)
+}
+
+// measured wraps a transaction's work to record the wait up to its first attempt. Retries of the work
+// wait for a connection again but are not counted.
+func (s *monitoredSession) measured(work neo4j.TransactionWork) neo4j.TransactionWork {
+	s.acquire()
+	start := time.Now()
+	attempted := false
+	return func(transaction neo4j.Transaction) (interface{}, error) {
+		if !attempted {
+			attempted = true
+			s.monitor.recordWait(time.Since(start))
+		}
+		return work(transaction)
+	}
+}
+
+func (s *monitoredSession) Close() error {
+	if s.active {
+		s.active = false
+		s.monitor.inUse.Add(-1)
+	}
+	return s.Session.Close()
+}
+
+// monitoredContextSession is a monitoredSession for a session honoring a context
+type monitoredContextSession struct {
+	neo4j.SessionWithContext
+	monitor *PoolMonitor
+	active  bool
+}
+
+func (s *monitoredContextSession) Run(ctx context.Context, cypher string, params map[string]any, configurers ...func(*neo4j.TransactionConfig)) (neo4j.ResultWithContext, error) {
+	if !s.active {
+		s.active = true
+		s.monitor.inUse.Add(1)
+	}
+	start := time.Now()
+	result, err := s.SessionWithContext.Run(ctx, cypher, params, configurers...)
+	s.monitor.recordWait(time.Since(start))
+	return result, err
+}
+
+func (s *monitoredContextSession) Close(ctx context.Context) error {
+	if s.active {
+		s.active = false
+		s.monitor.inUse.Add(-1)
+	}
+	return s.SessionWithContext.Close(ctx)
+}
+
+// neo4jPool monitors the pool of the driver InitNeo4j created
+var neo4jPool *PoolMonitor
+
+// Neo4jPoolStats returns the usage of the Neo4j connection pool, and false before InitNeo4j connected
+func Neo4jPoolStats() (PoolStats, bool) {
+	if neo4jPool == nil {
+		return PoolStats{}, false
+	}
+	return neo4jPool.Stats(), true
+}
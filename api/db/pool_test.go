@@ -0,0 +1,116 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestApplyPoolConfig(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("neo4j.pool.max_size", 120)
+	viper.Set("neo4j.pool.acquisition_timeout", "15s")
+	viper.Set("neo4j.pool.max_lifetime", "1h")
+
+	config := neo4j.Config{}
+	db.ApplyPoolConfig(&config)
+
+	assert.Equal(t, 120, config.MaxConnectionPoolSize)
+	assert.Equal(t, 15*time.Second, config.ConnectionAcquisitionTimeout)
+	assert.Equal(t, time.Hour, config.MaxConnectionLifetime)
+}
+
+func TestPoolMonitor(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	newMonitor := func(session neo4j.Session) *db.PoolMonitor {
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return db.NewPoolMonitor(driver, 2, 10*time.Millisecond)
+	}
+
+	t.Run("Sessions hold a connection until closed", func(t *testing.T) {
+		session := &mock.MockSession{}
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(&mock.MockResult{}, nil)
+		session.On("Close").Return(nil)
+		monitor := newMonitor(session)
+
+		first := monitor.NewSession(neo4j.SessionConfig{})
+		second := monitor.NewSession(neo4j.SessionConfig{})
+		assert.Equal(t, db.PoolStats{MaxSize: 2, Idle: 2}, monitor.Stats())
+
+		first.Run("RETURN 1", nil)
+		first.Run("RETURN 2", nil)
+		second.Run("RETURN 3", nil)
+		stats := monitor.Stats()
+		assert.Equal(t, int64(2), stats.InUse)
+		assert.Equal(t, int64(0), stats.Idle)
+		assert.Equal(t, int64(3), stats.Acquisitions)
+
+		assert.NoError(t, first.Close())
+		assert.NoError(t, second.Close())
+		assert.Equal(t, int64(0), monitor.Stats().InUse)
+		assert.Equal(t, int64(2), monitor.Stats().Idle)
+	})
+
+	t.Run("Slow acquisitions are counted", func(t *testing.T) {
+		session := &mock.MockSession{}
+		session.On("BeginTransaction", testify_mock.Anything).Run(func(testify_mock.Arguments) {
+			time.Sleep(20 * time.Millisecond)
+		}).Return(&mock.MockTransaction{}, nil)
+		monitor := newMonitor(session)
+
+		_, err := monitor.NewSession(neo4j.SessionConfig{}).BeginTransaction()
+
+		assert.NoError(t, err)
+		stats := monitor.Stats()
+		assert.Equal(t, int64(1), stats.SlowAcquisitions)
+		assert.GreaterOrEqual(t, stats.MaxWaitMs, 20.0)
+	})
+
+	t.Run("Transaction work is not waiting", func(t *testing.T) {
+		session := &mock.MockTxSession{}
+		monitor := newMonitor(session)
+
+		_, err := monitor.NewSession(neo4j.SessionConfig{}).WriteTransaction(func(neo4j.Transaction) (interface{}, error) {
+			time.Sleep(20 * time.Millisecond)
+			return nil, errors.New("work failed")
+		})
+
+		assert.Error(t, err)
+		stats := monitor.Stats()
+		assert.Equal(t, int64(1), stats.Acquisitions)
+		assert.Equal(t, int64(0), stats.SlowAcquisitions)
+	})
+
+	t.Run("Sessions honoring a context hold a connection until closed", func(t *testing.T) {
+		ctx := context.Background()
+		session := &mock.MockContextSession{}
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(&mock.MockContextResult{}, nil)
+		session.On("Close", testify_mock.Anything).Return(nil)
+		driver := &mock.MockContextDriver{}
+		driver.On("NewContextSession", testify_mock.Anything, testify_mock.Anything).Return(session, true)
+		monitor := db.NewPoolMonitor(driver, 2, 10*time.Millisecond)
+
+		monitored, ok := monitor.NewContextSession(ctx, neo4j.SessionConfig{})
+		assert.True(t, ok)
+		_, err := monitored.Run(ctx, "RETURN 1", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), monitor.Stats().InUse)
+		assert.Equal(t, int64(1), monitor.Stats().Acquisitions)
+
+		assert.NoError(t, monitored.Close(ctx))
+		assert.Equal(t, int64(0), monitor.Stats().InUse)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -23,8 +24,16 @@ import (
 var (
 	RedisClient   *redis.Client
 	encryptionKey []byte
+
+	defaultCacheTTL atomic.Int64 // time.Duration
 )
 
+// SetDefaultCacheTTL sets how long cached entities live. redis.defaultCacheTTL is hot-reloadable, so it
+// may change while the cache is written.
+func SetDefaultCacheTTL(ttl time.Duration) {
+	defaultCacheTTL.Store(int64(ttl))
+}
+
 func InitRedis() error {
 	RedisClient = redis.NewClient(&redis.Options{
 		Addr:         viper.GetString("redis.addr"),
@@ -45,6 +54,7 @@ func InitRedis() error {
 		return fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	SetDefaultCacheTTL(viper.GetDuration("redis.defaultCacheTTL"))
 	encryptionKey = []byte(viper.GetString("redis.encryptionKey"))
 	if len(encryptionKey) != 32 {
 		return fmt.Errorf("invalid encryption key length: must be 32 bytes")
@@ -96,19 +106,14 @@ func decrypt(ciphertext []byte) ([]byte, error) {
 }
 
 func CachePolicy(ctx context.Context, policy *model.Policy) error {
-	policyJSON, err := json.Marshal(policy)
+	encodedPolicy, err := encodePolicy(policy)
 	if err != nil {
-		return fmt.Errorf("failed to marshal policy: %w", err)
-	}
-
-	encryptedPolicy, err := encrypt(policyJSON)
-	if err != nil {
-		return fmt.Errorf("failed to encrypt policy: %w", err)
+		return err
 	}
 
 	key := fmt.Sprintf("policy:%s", policy.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
-	err = RedisClient.Set(ctx, key, base64.StdEncoding.EncodeToString(encryptedPolicy), defaultTTL).Err()
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
+	err = RedisClient.Set(ctx, key, encodedPolicy, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache policy: %w", err)
 	}
@@ -117,6 +122,20 @@ func CachePolicy(ctx context.Context, policy *model.Policy) error {
 	return nil
 }
 
+// encodePolicy encrypts a policy for the cache, which never holds policies in the clear
+func encodePolicy(policy *model.Policy) (string, error) {
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal policy: %w", err)
+	}
+
+	encryptedPolicy, err := encrypt(policyJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt policy: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(encryptedPolicy), nil
+}
+
 func GetCachedPolicy(ctx context.Context, policyID string) (*model.Policy, error) {
 	key := fmt.Sprintf("policy:%s", policyID)
 	encryptedPolicyStr, err := RedisClient.Get(ctx, key).Result()
@@ -127,6 +146,21 @@ func GetCachedPolicy(ctx context.Context, policyID string) (*model.Policy, error
 		return nil, fmt.Errorf("failed to get policy from cache: %w", err)
 	}
 
+	policy, err := decodeCachedPolicy(encryptedPolicyStr)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("Policy retrieved from cache", zap.String("policyID", policyID))
+	return policy, nil
+}
+
+// GetCachedPolicies fetches the cached policies among policyIDs in one round trip, keyed by ID
+func GetCachedPolicies(ctx context.Context, policyIDs []string) (map[string]*model.Policy, error) {
+	return getCachedMany(ctx, "policy", policyIDs, decodeCachedPolicy)
+}
+
+func decodeCachedPolicy(encryptedPolicyStr string) (*model.Policy, error) {
 	encryptedPolicy, err := base64.StdEncoding.DecodeString(encryptedPolicyStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode policy: %w", err)
@@ -142,11 +176,178 @@ func GetCachedPolicy(ctx context.Context, policyID string) (*model.Policy, error
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal policy: %w", err)
 	}
-
-	logger.Debug("Policy retrieved from cache", zap.String("policyID", policyID))
 	return &policy, nil
 }
 
+// getCachedMany fetches the cached entities of ids, stored under "<kind>:<id>", with a single MGET.
+// IDs not cached, or whose entry fails to decode, are left out so callers load them afresh.
+func getCachedMany[T any](ctx context.Context, kind string, ids []string, decode func(string) (*T, error)) (map[string]*T, error) {
+	found := make(map[string]*T, len(ids))
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf("%s:%s", kind, id)
+	}
+	values, err := RedisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s entries from cache: %w", kind, err)
+	}
+
+	for i, value := range values {
+		cached, ok := value.(string)
+		if !ok {
+			continue
+		}
+		entity, err := decode(cached)
+		if err != nil {
+			logger.Warn("Ignoring undecodable cache entry", zap.Error(err), zap.String("key", keys[i]))
+			continue
+		}
+		found[ids[i]] = entity
+	}
+
+	logger.Debug("Cache entries retrieved",
+		zap.String("kind", kind),
+		zap.Int("requested", len(ids)),
+		zap.Int("found", len(found)))
+	return found, nil
+}
+
+// Cache kinds, the prefixes of the keys entities are cached under
+const (
+	CacheKindPolicy         = "policy"
+	CacheKindOrganization   = "organization"
+	CacheKindDepartment     = "department"
+	CacheKindUser           = "user"
+	CacheKindRole           = "role"
+	CacheKindGroup          = "group"
+	CacheKindPermission     = "permission"
+	CacheKindResource       = "resource"
+	CacheKindResourceType   = "resourceType"
+	CacheKindAttributeGroup = "attributeGroup"
+	CacheKindDecision       = "decision"
+)
+
+// CacheKinds returns every cache kind
+func CacheKinds() []string {
+	return []string{
+		CacheKindPolicy, CacheKindOrganization, CacheKindDepartment, CacheKindUser, CacheKindRole, CacheKindGroup,
+		CacheKindPermission, CacheKindResource, CacheKindResourceType, CacheKindAttributeGroup, CacheKindDecision,
+	}
+}
+
+// IsCacheKind reports whether kind is one of the cache kinds
+func IsCacheKind(kind string) bool {
+	for _, known := range CacheKinds() {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheEntry returns the key an entity is cached under and its cached value, encoded as the entity's
+// own Cache function would: encrypted for policies and plain JSON for every other kind
+func cacheEntry(entity interface{}) (string, string, error) {
+	var kind, id string
+	switch e := entity.(type) {
+	case *model.Policy:
+		value, err := encodePolicy(e)
+		if err != nil {
+			return "", "", err
+		}
+		return fmt.Sprintf("%s:%s", CacheKindPolicy, e.ID), value, nil
+	case *model.Organization:
+		kind, id = CacheKindOrganization, e.ID
+	case *model.Department:
+		kind, id = CacheKindDepartment, e.ID
+	case *model.User:
+		kind, id = CacheKindUser, e.ID
+	case *model.Role:
+		kind, id = CacheKindRole, e.ID
+	case *model.Group:
+		kind, id = CacheKindGroup, e.ID
+	case *model.Permission:
+		kind, id = CacheKindPermission, e.ID
+	case *model.Resource:
+		kind, id = CacheKindResource, e.ID
+	case *model.ResourceType:
+		kind, id = CacheKindResourceType, e.ID
+	case *model.AttributeGroup:
+		kind, id = CacheKindAttributeGroup, e.ID
+	default:
+		return "", "", fmt.Errorf("cannot cache entities of type %T", entity)
+	}
+
+	entityJSON, err := json.Marshal(entity)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal %s: %w", kind, err)
+	}
+	return fmt.Sprintf("%s:%s", kind, id), string(entityJSON), nil
+}
+
+// CacheMany caches entities, which may be of different kinds, in one pipelined round trip. Entities
+// are pointers to the models the Cache functions take. Nothing is cached when one of them fails to
+// encode.
+func CacheMany(ctx context.Context, entities []interface{}) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(entities))
+	values := make([]string, len(entities))
+	for i, entity := range entities {
+		key, value, err := cacheEntry(entity)
+		if err != nil {
+			return err
+		}
+		keys[i], values[i] = key, value
+	}
+
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
+	pipe := RedisClient.Pipeline()
+	for i, key := range keys {
+		pipe.Set(ctx, key, values[i], defaultTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cache entries: %w", err)
+	}
+
+	logger.Debug("Cache entries stored", zap.Int("count", len(entities)))
+	return nil
+}
+
+// DeleteCachedMany drops the cached entities of ids, stored under "<kind>:<id>", in one pipelined
+// round trip
+func DeleteCachedMany(ctx context.Context, kind string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pipe := RedisClient.Pipeline()
+	for _, id := range ids {
+		pipe.Del(ctx, fmt.Sprintf("%s:%s", kind, id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete %s entries from cache: %w", kind, err)
+	}
+
+	logger.Debug("Cache entries deleted", zap.String("kind", kind), zap.Int("count", len(ids)))
+	return nil
+}
+
+// decodeCachedJSON decodes an entity cached as plain JSON
+func decodeCachedJSON[T any](cached string) (*T, error) {
+	var entity T
+	if err := json.Unmarshal([]byte(cached), &entity); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached entity: %w", err)
+	}
+	return &entity, nil
+}
+
 func DeleteCachedPolicy(ctx context.Context, policyID string) error {
 	key := fmt.Sprintf("policy:%s", policyID)
 	err := RedisClient.Del(ctx, key).Err()
@@ -164,7 +365,7 @@ func CacheOrganization(ctx context.Context, organization *model.Organization) er
 	}
 
 	key := fmt.Sprintf("organization:%s", organization.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
 	err = RedisClient.Set(ctx, key, organizationJSON, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache organization: %w", err)
@@ -211,7 +412,7 @@ func CacheDepartment(ctx context.Context, department *model.Department) error {
 	}
 
 	key := fmt.Sprintf("department:%s", department.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
 	err = RedisClient.Set(ctx, key, departmentJSON, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache department: %w", err)
@@ -258,7 +459,7 @@ func CacheUser(ctx context.Context, user *model.User) error {
 	}
 
 	key := fmt.Sprintf("user:%s", user.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
 	err = RedisClient.Set(ctx, key, userJSON, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache user: %w", err)
@@ -298,6 +499,11 @@ func GetCachedUser(ctx context.Context, userID string) (*model.User, error) {
 	return &user, nil
 }
 
+// GetCachedUsers fetches the cached users among userIDs in one round trip, keyed by ID
+func GetCachedUsers(ctx context.Context, userIDs []string) (map[string]*model.User, error) {
+	return getCachedMany(ctx, "user", userIDs, decodeCachedJSON[model.User])
+}
+
 func CacheRole(ctx context.Context, role *model.Role) error {
 	roleJSON, err := json.Marshal(role)
 	if err != nil {
@@ -305,7 +511,7 @@ func CacheRole(ctx context.Context, role *model.Role) error {
 	}
 
 	key := fmt.Sprintf("role:%s", role.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
 	err = RedisClient.Set(ctx, key, roleJSON, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache role: %w", err)
@@ -353,7 +559,7 @@ func CacheGroup(ctx context.Context, group *model.Group) error {
 	}
 
 	key := fmt.Sprintf("group:%s", group.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
 	err = RedisClient.Set(ctx, key, groupJSON, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache group: %w", err)
@@ -403,7 +609,7 @@ func CachePermission(ctx context.Context, permission *model.Permission) error {
 	}
 
 	key := fmt.Sprintf("permission:%s", permission.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
 	err = RedisClient.Set(ctx, key, permissionJSON, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache permission: %w", err)
@@ -453,7 +659,7 @@ func CacheResource(ctx context.Context, resource *model.Resource) error {
 	}
 
 	key := fmt.Sprintf("resource:%s", resource.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
 	err = RedisClient.Set(ctx, key, resourceJSON, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache resource: %w", err)
@@ -495,6 +701,11 @@ func GetCachedResource(ctx context.Context, resourceID string) (*model.Resource,
 	return &resource, nil
 }
 
+// GetCachedResources fetches the cached resources among resourceIDs in one round trip, keyed by ID
+func GetCachedResources(ctx context.Context, resourceIDs []string) (map[string]*model.Resource, error) {
+	return getCachedMany(ctx, "resource", resourceIDs, decodeCachedJSON[model.Resource])
+}
+
 // GetCachedResourceType
 func GetCachedResourceType(ctx context.Context, resourceTypeID string) (*model.ResourceType, error) {
 	key := fmt.Sprintf("resourceType:%s", resourceTypeID)
@@ -524,7 +735,7 @@ func CacheResourceType(ctx context.Context, resourceType *model.ResourceType) er
 	}
 
 	key := fmt.Sprintf("resourceType:%s", resourceType.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
 	err = RedisClient.Set(ctx, key, resourceTypeJSON, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache resourceType: %w", err)
@@ -553,7 +764,7 @@ func CacheAttributeGroup(ctx context.Context, attributeGroup *model.AttributeGro
 	}
 
 	key := fmt.Sprintf("attributeGroup:%s", attributeGroup.ID)
-	defaultTTL := viper.GetDuration("redis.defaultCacheTTL")
+	defaultTTL := time.Duration(defaultCacheTTL.Load())
 	err = RedisClient.Set(ctx, key, attributeGroupJSON, defaultTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache attributeGroup: %w", err)
@@ -0,0 +1,56 @@
+// api/db/redis_scan.go
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// defaultScanBatch is how many keys each SCAN call asks Redis to look at when callers do not say
+const defaultScanBatch = 500
+
+// ScanKeysFunc calls fn with each batch of the keys matching the glob pattern, as SCAN returns them, so
+// the keys are never all held in memory. Each SCAN call looks at about batch keys, or 500 when batch is
+// not positive; batches may be empty and, as SCAN promises no more, a key changed during the scan may
+// be passed twice. The scan stops at the first error fn returns. Keys are never enumerated with KEYS,
+// which blocks Redis while it walks the whole keyspace.
+func ScanKeysFunc(ctx context.Context, pattern string, batch int, fn func(keys []string) error) error {
+	if batch <= 0 {
+		batch = defaultScanBatch
+	}
+
+	var cursor uint64
+	for {
+		keys, next, err := RedisClient.Scan(ctx, cursor, pattern, int64(batch)).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan keys matching %q: %w", pattern, err)
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if cursor = next; cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// ScanKeys returns every key matching the glob pattern, each once, enumerated as ScanKeysFunc does
+func ScanKeys(ctx context.Context, pattern string, batch int) ([]string, error) {
+	var keys []string
+	seen := map[string]bool{}
+	err := ScanKeysFunc(ctx, pattern, batch, func(batch []string) error {
+		for _, key := range batch {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,90 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestScanKeys(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	// populate stores 10,000 users and a few roles
+	populate := func(t *testing.T) *mock.RedisServer {
+		server := mock.StartRedisServer(t)
+		pipe := db.RedisClient.Pipeline()
+		for i := 0; i < 10000; i++ {
+			pipe.Set(ctx, fmt.Sprintf("user:u%d", i), "cached", 0)
+		}
+		for i := 0; i < 3; i++ {
+			pipe.Set(ctx, fmt.Sprintf("role:r%d", i), "cached", 0)
+		}
+		_, err := pipe.Exec(ctx)
+		require.NoError(t, err)
+		return server
+	}
+
+	t.Run("Every matching key is returned once", func(t *testing.T) {
+		server := populate(t)
+
+		keys, err := db.ScanKeys(ctx, "user:*", 250)
+
+		require.NoError(t, err)
+		assert.Len(t, keys, 10000)
+		seen := map[string]bool{}
+		for _, key := range keys {
+			seen[key] = true
+		}
+		for i := 0; i < 10000; i++ {
+			require.True(t, seen[fmt.Sprintf("user:u%d", i)], "user:u%d missing", i)
+		}
+		assert.Greater(t, server.Calls("SCAN"), 1)
+		assert.Zero(t, server.Calls("KEYS"))
+	})
+
+	t.Run("Batches stream to the callback", func(t *testing.T) {
+		server := populate(t)
+
+		var batches, total int
+		err := db.ScanKeysFunc(ctx, "user:*", 1000, func(keys []string) error {
+			batches++
+			total += len(keys)
+			assert.LessOrEqual(t, len(keys), 1000)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 10000, total)
+		assert.GreaterOrEqual(t, batches, 10)
+		assert.Zero(t, server.Calls("KEYS"))
+	})
+
+	t.Run("A callback error stops the scan", func(t *testing.T) {
+		server := populate(t)
+		stop := errors.New("stop")
+
+		err := db.ScanKeysFunc(ctx, "user:*", 100, func(keys []string) error { return stop })
+
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, server.Calls("SCAN"))
+	})
+
+	t.Run("Keys of other patterns are left out", func(t *testing.T) {
+		populate(t)
+
+		keys, err := db.ScanKeys(ctx, "role:*", 0)
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"role:r0", "role:r1", "role:r2"}, keys)
+	})
+}
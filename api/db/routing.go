@@ -0,0 +1,120 @@
+// api/db/routing.go
+package db
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// RoutingDriver wraps a driver so that read sessions can be served by read replicas without losing
+// causal consistency. Every write session records its bookmarks when it is closed, and every read
+// session that does not bring its own bookmarks starts from the most recent of them, so a read
+// always observes the writes this process has already committed.
+type RoutingDriver struct {
+	neo4j.Driver
+	database string
+
+	mu        sync.RWMutex
+	bookmarks neo4j.Bookmarks
+}
+
+// NewRoutingDriver wraps driver. Sessions that do not name a database use database; an empty
+// database leaves the choice to the server.
+func NewRoutingDriver(driver neo4j.Driver, database string) *RoutingDriver {
+	return &RoutingDriver{Driver: driver, database: database}
+}
+
+// NewSession opens a session on the wrapped driver, threading the latest write bookmarks into read
+// sessions and capturing the bookmarks of write sessions
+func (d *RoutingDriver) NewSession(config neo4j.SessionConfig) neo4j.Session {
+	if config.DatabaseName == "" {
+		config.DatabaseName = d.database
+	}
+
+	if config.AccessMode == neo4j.AccessModeRead {
+		if len(config.Bookmarks) == 0 {
+			config.Bookmarks = d.LastBookmarks()
+		}
+		return d.Driver.NewSession(config)
+	}
+
+	return &bookmarkingSession{Session: d.Driver.NewSession(config), driver: d}
+}
+
+// NewContextSession opens a session honoring a context on the wrapped driver, if it can, threading and
+// capturing bookmarks as NewSession does
+func (d *RoutingDriver) NewContextSession(ctx context.Context, config neo4j.SessionConfig) (neo4j.SessionWithContext, bool) {
+	if config.DatabaseName == "" {
+		config.DatabaseName = d.database
+	}
+	if config.AccessMode == neo4j.AccessModeRead && len(config.Bookmarks) == 0 {
+		config.Bookmarks = d.LastBookmarks()
+	}
+
+	session, ok := newContextSession(ctx, d.Driver, config)
+	if !ok || config.AccessMode == neo4j.AccessModeRead {
+		return session, ok
+	}
+	return &bookmarkingContextSession{SessionWithContext: session, driver: d}, true
+}
+
+// LastBookmarks returns the bookmarks of the most recently closed write session
+func (d *RoutingDriver) LastBookmarks() neo4j.Bookmarks {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.bookmarks
+}
+
+func (d *RoutingDriver) recordBookmarks(bookmarks neo4j.Bookmarks) {
+	if len(bookmarks) == 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.bookmarks = bookmarks
+}
+
+// bookmarkingSession hands its bookmarks to the driver when it is closed
+type bookmarkingSession struct {
+	neo4j.Session
+	driver *RoutingDriver
+}
+
+func (s *bookmarkingSession) Close() error {
+	s.driver.recordBookmarks(s.Session.LastBookmarks())
+	return s.Session.Close()
+}
+
+// bookmarkingContextSession is a bookmarkingSession for a session honoring a context
+type bookmarkingContextSession struct {
+	neo4j.SessionWithContext
+	driver *RoutingDriver
+}
+
+func (s *bookmarkingContextSession) Close(ctx context.Context) error {
+	s.driver.recordBookmarks(s.SessionWithContext.LastBookmarks())
+	return s.SessionWithContext.Close(ctx)
+}
+
+// RoutingURI switches a direct bolt URI to the equivalent neo4j URI, which makes the driver discover
+// the cluster's routing table and send read sessions to followers and read replicas. Other URIs are
+// returned unchanged.
+func RoutingURI(uri string) string {
+	if strings.HasPrefix(uri, "bolt") {
+		return "neo4j" + strings.TrimPrefix(uri, "bolt")
+	}
+	return uri
+}
+
+// serverAddress parses a host:port cluster member, defaulting to the bolt port when none is given
+func serverAddress(address string) neo4j.ServerAddress {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = address, "7687"
+	}
+	return neo4j.NewServerAddress(host, port)
+}
@@ -0,0 +1,96 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestRoutingDriver(t *testing.T) {
+	t.Run("Read sessions start from the preceding write's bookmarks", func(t *testing.T) {
+		writeSession := &mock.MockSession{}
+		writeSession.On("LastBookmarks").Return([]string{"bookmark:42"})
+		writeSession.On("Close").Return(nil)
+		readSession := &mock.MockSession{}
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite, DatabaseName: "echo"}).Return(writeSession)
+		driver.On("NewSession", neo4j.SessionConfig{
+			AccessMode:   neo4j.AccessModeRead,
+			DatabaseName: "echo",
+			Bookmarks:    neo4j.Bookmarks{"bookmark:42"},
+		}).Return(readSession)
+		routingDriver := db.NewRoutingDriver(driver, "echo")
+
+		session := routingDriver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+		assert.NoError(t, session.Close())
+
+		assert.Same(t, readSession, routingDriver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead}))
+		driver.AssertExpectations(t)
+	})
+
+	t.Run("Read sessions before any write carry no bookmarks", func(t *testing.T) {
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(&mock.MockSession{})
+
+		db.NewRoutingDriver(driver, "").NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+
+		driver.AssertCalled(t, "NewSession", neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	})
+
+	t.Run("Explicit bookmarks are kept", func(t *testing.T) {
+		writeSession := &mock.MockSession{}
+		writeSession.On("LastBookmarks").Return([]string{"bookmark:42"})
+		writeSession.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(writeSession)
+		routingDriver := db.NewRoutingDriver(driver, "")
+		routingDriver.NewSession(neo4j.SessionConfig{}).Close()
+
+		routingDriver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead, Bookmarks: neo4j.Bookmarks{"bookmark:7"}})
+
+		driver.AssertCalled(t, "NewSession", neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead, Bookmarks: neo4j.Bookmarks{"bookmark:7"}})
+	})
+
+	t.Run("Read sessions honoring a context start from the preceding write's bookmarks", func(t *testing.T) {
+		writeSession := &mock.MockContextSession{}
+		writeSession.On("LastBookmarks").Return(neo4j.Bookmarks{"bookmark:42"})
+		writeSession.On("Close", testify_mock.Anything).Return(nil)
+		readSession := &mock.MockContextSession{}
+		ctx := context.Background()
+		driver := &mock.MockContextDriver{}
+		driver.On("NewContextSession", ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite, DatabaseName: "echo"}).Return(writeSession, true)
+		driver.On("NewContextSession", ctx, neo4j.SessionConfig{
+			AccessMode:   neo4j.AccessModeRead,
+			DatabaseName: "echo",
+			Bookmarks:    neo4j.Bookmarks{"bookmark:42"},
+		}).Return(readSession, true)
+		routingDriver := db.NewRoutingDriver(driver, "echo")
+
+		session, ok := routingDriver.NewContextSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+		assert.True(t, ok)
+		assert.NoError(t, session.Close(ctx))
+
+		session, ok = routingDriver.NewContextSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+		assert.True(t, ok)
+		assert.Same(t, readSession, session)
+		driver.AssertExpectations(t)
+	})
+
+	t.Run("Drivers without context support open no session honoring one", func(t *testing.T) {
+		_, ok := db.NewRoutingDriver(&mock.MockDriver{}, "").NewContextSession(context.Background(), neo4j.SessionConfig{})
+
+		assert.False(t, ok)
+	})
+}
+
+func TestRoutingURI(t *testing.T) {
+	assert.Equal(t, "neo4j://core1:7687", db.RoutingURI("bolt://core1:7687"))
+	assert.Equal(t, "neo4j+s://core1:7687", db.RoutingURI("bolt+s://core1:7687"))
+	assert.Equal(t, "neo4j://core1:7687", db.RoutingURI("neo4j://core1:7687"))
+}
@@ -6,12 +6,24 @@ var (
 	ErrRoleNotFound    = errors.New("role not found")
 	ErrRoleConflict    = errors.New("role conflict")
 	ErrInvalidRoleData = errors.New("invalid role data")
+	ErrRoleInUse       = errors.New("role is still assigned to users or groups")
 
 	ErrGroupNotFound    = errors.New("group not found")
 	ErrGroupConflict    = errors.New("group conflict")
 	ErrInvalidGroupData = errors.New("invalid group data")
+	ErrGroupCycle       = errors.New("group hierarchy would contain a cycle")
 
 	ErrPermissionNotFound    = errors.New("permission not found")
 	ErrPermissionConflict    = errors.New("permission conflict")
 	ErrInvalidPermissionData = errors.New("invalid permission data")
+
+	ErrInvalidAccessRequest = errors.New("invalid access request")
+
+	ErrPolicyBundleUnavailable = errors.New("policy bundles are unavailable without a signing key")
+	ErrInvalidPolicyBundle     = errors.New("invalid policy bundle")
+
+	ErrSoDRuleNotFound    = errors.New("separation of duties rule not found")
+	ErrSoDRuleConflict    = errors.New("separation of duties rule conflict")
+	ErrInvalidSoDRuleData = errors.New("invalid separation of duties rule data")
+	ErrSoDViolation       = errors.New("role assignment violates separation of duties")
 )
@@ -0,0 +1,9 @@
+// api/errors/job_errors.go
+package errors
+
+import "errors"
+
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrJobQueueFull = errors.New("too many jobs are queued")
+)
@@ -10,4 +10,9 @@ var (
 	ErrInvalidOrganizationData = errors.New("invalid organization data")
 	ErrDepartmentConflict      = errors.New("department conflict")
 	ErrInvalidDepartmentData   = errors.New("invalid department data")
+	ErrOrganizationInUse       = errors.New("organization still has dependent entities")
+	ErrInvalidOrgDeleteMode    = errors.New("invalid organization delete mode")
+	ErrOrganizationCycle       = errors.New("organization hierarchy would contain a cycle")
+	ErrDepartmentHasDependents = errors.New("department still has child departments or members")
+	ErrInvalidDeptDeleteMode   = errors.New("invalid department delete mode")
 )
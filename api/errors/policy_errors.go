@@ -6,10 +6,22 @@ import "errors"
 var (
 	ErrPolicyNotFound        = errors.New("policy not found")
 	ErrDatabaseOperation     = errors.New("database operation failed")
+	ErrConstraintViolation   = errors.New("database constraint violated")
+	ErrTransient             = errors.New("database temporarily unavailable")
+	ErrSyntax                = errors.New("invalid database query")
+	ErrCacheUnavailable      = errors.New("cache unavailable")
 	ErrInvalidPolicyData     = errors.New("invalid policy data")
 	ErrPolicyConflict        = errors.New("policy conflict")
+	ErrPriorityConflict      = errors.New("policy priority conflict")
 	ErrInternalServer        = errors.New("internal server error")
+	ErrServiceReadOnly       = errors.New("service is read-only for maintenance")
 	ErrUnauthorized          = errors.New("unauthorized")
+	ErrForbidden             = errors.New("forbidden")
 	ErrInvalidPagination     = errors.New("invalid pagination parameters")
 	ErrInvalidSearchCriteria = errors.New("invalid search criteria")
+
+	ErrPolicyTemplateNotFound   = errors.New("policy template not found")
+	ErrMissingTemplateVariables = errors.New("missing policy template variables")
+
+	ErrInvalidPolicyStatusTransition = errors.New("invalid policy status transition")
 )
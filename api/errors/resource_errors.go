@@ -6,12 +6,19 @@ import "errors"
 
 var (
 	ErrResourceNotFound          = errors.New("resource not found")
+	ErrResourceVersionNotFound   = errors.New("resource version not found")
 	ErrInvalidResourceData       = errors.New("invalid resource data")
 	ErrResourceConflict          = errors.New("resource conflict")
 	ErrResourceTypeNotFound      = errors.New("resource type not found")
+	ErrResourceTypeConflict      = errors.New("resource type conflict")
 	ErrAttributeGroupNotFound    = errors.New("attribute group not found")
 	ErrAttributeGroupConflict    = errors.New("attribute group conflict")
+	ErrAttributeGroupInUse       = errors.New("attribute group is still referenced by resources")
 	ErrInvalidAttributeGroupData = errors.New("invalid attribute group data")
 	ErrInvalidResourceType       = errors.New("invalid resource type")
 	ErrInvalidResourceTypeData   = errors.New("invalid resource type data")
+	ErrAttributeSchemaViolation  = errors.New("attributes violate attribute group schema")
+	ErrParentResourceNotFound    = errors.New("parent resource not found")
+	ErrRelatedResourceNotFound   = errors.New("related resource not found")
+	ErrBulkLimitExceeded         = errors.New("bulk operation matches more resources than allowed")
 )
@@ -7,4 +7,11 @@ var (
 	ErrUserNotFound    = errors.New("user not found")
 	ErrInvalidUserData = errors.New("invalid user data")
 	ErrUserConflict    = errors.New("user conflict")
+	ErrOwnerNotFound   = errors.New("owner not found")
+
+	ErrInvalidUserStatus           = errors.New("invalid user status")
+	ErrInvalidUserStatusTransition = errors.New("invalid user status transition")
+
+	ErrAdminScopeNotFound = errors.New("admin scope not found")
+	ErrInvalidAdminScope  = errors.New("invalid admin scope")
 )
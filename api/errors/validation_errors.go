@@ -0,0 +1,49 @@
+// api/errors/validation_errors.go
+package errors
+
+import "strings"
+
+// FieldError describes why one field of a request is invalid
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ErrValidation reports every field of an entity that failed validation. It wraps Kind, the sentinel
+// of the entity's invalid data such as ErrInvalidPolicyData, so errors.Is keeps matching that sentinel
+// while errors.As recovers the fields.
+type ErrValidation struct {
+	Kind   error
+	Fields []FieldError
+}
+
+// NewValidationError returns an empty validation error of the given kind; Add records the invalid
+// fields and Err returns it once any was recorded
+func NewValidationError(kind error) *ErrValidation {
+	return &ErrValidation{Kind: kind}
+}
+
+// Add records that field is invalid for reason
+func (e *ErrValidation) Add(field string, reason string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Reason: reason})
+}
+
+// Err returns e when a field was recorded and nil otherwise
+func (e *ErrValidation) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ErrValidation) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		reasons[i] = field.Field + " " + field.Reason
+	}
+	return e.Kind.Error() + ": " + strings.Join(reasons, "; ")
+}
+
+func (e *ErrValidation) Unwrap() error {
+	return e.Kind
+}
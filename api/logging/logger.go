@@ -12,8 +12,13 @@ import (
 
 var Log *zap.Logger
 
+// level is shared by every logger InitLogger builds, so SetLevel applies without rebuilding them
+var level = zap.NewAtomicLevel()
+
 func InitLogger(logDirPath string) {
 	config := zap.NewProductionConfig()
+	level.SetLevel(zapcore.InfoLevel)
+	config.Level = level
 
 	// Ensure log directory exists
 	err := os.MkdirAll(logDirPath, 0755)
@@ -24,9 +29,8 @@ func InitLogger(logDirPath string) {
 	// Customize log level based on environment
 	logLevel := os.Getenv("LOG_LEVEL")
 	if logLevel != "" {
-		level, err := zapcore.ParseLevel(logLevel)
-		if err == nil {
-			config.Level.SetLevel(level)
+		if parsed, err := zapcore.ParseLevel(logLevel); err == nil {
+			level.SetLevel(parsed)
 		}
 	}
 
@@ -53,6 +57,21 @@ func InitLogger(logDirPath string) {
 	zap.ReplaceGlobals(Log) // Replace global logger
 }
 
+// SetLevel changes the minimum level logged, such as "debug" or "warn", while the logger runs
+func SetLevel(name string) error {
+	parsed, err := zapcore.ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(parsed)
+	return nil
+}
+
+// GetLevel returns the minimum level logged, such as "info"
+func GetLevel() string {
+	return level.Level().String()
+}
+
 // Log methods for different levels
 func Info(msg string, fields ...zap.Field) {
 	Log.Info(msg, fields...)
@@ -70,6 +89,13 @@ func Warn(msg string, fields ...zap.Field) {
 	Log.Warn(msg, fields...)
 }
 
+// LogAt logs at the given level, for messages whose level is configured
+func LogAt(lvl zapcore.Level, msg string, fields ...zap.Field) {
+	if entry := Log.Check(lvl, msg); entry != nil {
+		entry.Write(fields...)
+	}
+}
+
 func Fatal(msg string, fields ...zap.Field) {
 	Log.Fatal(msg, fields...)
 }
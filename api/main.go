@@ -15,19 +15,107 @@ import (
 	"github.com/dev-mohitbeniwal/echo/api/audit"
 	"github.com/dev-mohitbeniwal/echo/api/config"
 	"github.com/dev-mohitbeniwal/echo/api/controller"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
 	"github.com/dev-mohitbeniwal/echo/api/db"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/middleware"
+	"github.com/dev-mohitbeniwal/echo/api/migrate"
 	router "github.com/dev-mohitbeniwal/echo/api/router"
 	"github.com/dev-mohitbeniwal/echo/api/service"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 func main() {
-	if err := run(); err != nil {
+	command := run
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "bootstrap":
+			command = bootstrap
+		case "migrate":
+			command = migrateSchema
+		}
+	}
+	if err := command(); err != nil {
 		log.Fatalf("Application error: %v", err)
 	}
 }
 
+// migrateSchema applies the pending graph migrations and exits
+func migrateSchema() error {
+	if err := config.InitConfig(); err != nil {
+		return fmt.Errorf("failed to initialize config: %w", err)
+	}
+
+	logger.InitLogger(config.GetString("log.file"))
+	defer logger.Sync()
+
+	if err := db.InitNeo4j(); err != nil {
+		return fmt.Errorf("failed to initialize Neo4j: %w", err)
+	}
+	defer db.CloseNeo4j()
+
+	applied, err := migrate.Run(context.Background(), db.Neo4jDriver)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Applied %d migrations: %v\n", len(applied), applied)
+	return nil
+}
+
+// bootstrap seeds a fresh deployment with the root organization, admin role and admin user the
+// bootstrap config describes. Running it again leaves existing entities alone.
+func bootstrap() error {
+	if err := config.InitConfig(); err != nil {
+		return fmt.Errorf("failed to initialize config: %w", err)
+	}
+
+	logger.InitLogger(config.GetString("log.file"))
+	defer logger.Sync()
+
+	if err := db.InitNeo4j(); err != nil {
+		return fmt.Errorf("failed to initialize Neo4j: %w", err)
+	}
+	defer db.CloseNeo4j()
+
+	// The audit chain head is kept in Redis
+	if err := db.InitRedis(); err != nil {
+		return fmt.Errorf("failed to initialize Redis: %w", err)
+	}
+	defer db.CloseRedis()
+
+	auditRepository, err := audit.NewElasticsearchRepository(config.GetString("elasticsearch.url"))
+	if err != nil {
+		return fmt.Errorf("failed to create audit repository: %w", err)
+	}
+	auditService := audit.NewService(auditRepository, audit.RedisChainHeadStore{})
+
+	bootstrapService := service.NewBootstrapService(
+		dao.NewOrganizationDAO(db.Neo4jDriver, auditService),
+		dao.NewPermissionDAO(db.Neo4jDriver, auditService),
+		dao.NewRoleDAO(db.Neo4jDriver, auditService),
+		dao.NewUserDAO(db.Neo4jDriver, auditService),
+	)
+	ctx := helper_util.WithActor(context.Background(), "bootstrap")
+	result, err := bootstrapService.Bootstrap(ctx, service.BootstrapConfig{
+		OrganizationID:   config.GetString("bootstrap.organization_id"),
+		OrganizationName: config.GetString("bootstrap.organization_name"),
+		AdminRoleID:      config.GetString("bootstrap.admin_role_id"),
+		AdminRoleName:    config.GetString("bootstrap.admin_role_name"),
+		AdminUserID:      config.GetString("bootstrap.admin_user_id"),
+		AdminUsername:    config.GetString("bootstrap.admin_username"),
+		AdminName:        config.GetString("bootstrap.admin_name"),
+		AdminEmail:       config.GetString("bootstrap.admin_email"),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Bootstrap created %d entities: %v\n", len(result.Created), result.Created)
+	return nil
+}
+
 func run() error {
 	// Initialize configuration
 	if err := config.InitConfig(); err != nil {
@@ -44,6 +132,12 @@ func run() error {
 	}
 	defer db.CloseNeo4j()
 
+	if config.GetBool("neo4j.migrate_on_startup") {
+		if _, err := migrate.Run(context.Background(), db.Neo4jDriver); err != nil {
+			return fmt.Errorf("failed to migrate the graph: %w", err)
+		}
+	}
+
 	// Initialize Redis
 	if err := db.InitRedis(); err != nil {
 		return fmt.Errorf("failed to initialize Redis: %w", err)
@@ -57,16 +151,108 @@ func run() error {
 	eventBus.Start(ctx)
 
 	// Initialize services and utilities
+	helper_util.SetMaxPageLimit(config.GetInt("pagination.max_limit"))
+	if err := helper_util.SetTrustedProxies(config.GetStringSlice("server.trusted_proxies")); err != nil {
+		return err
+	}
+	dao.SetQueryTimeout(config.GetDuration("neo4j.query_timeout"))
+	service.SetBulkTagLimit(config.GetInt("resources.bulk_tag_limit"))
+	service.SetMaxSearchAttributes(config.GetInt("resources.max_search_attributes"))
+	service.SetTenantIsolation(config.GetStringSlice("tenancy.isolated_entities"), config.GetString("tenancy.global_admin_role"))
+	service.SetScopedAdminRole(config.GetString("tenancy.scoped_admin_role"))
+	service.SetTenantSubOrganizations(config.GetBool("tenancy.include_sub_organizations"))
+	service.SetSoDEnforcement(config.GetString("sod.enforcement"))
+	service.SetLoginRecordInterval(config.GetDuration("auth.login_record_interval"))
+	if err := service.SetPolicyTimezone(config.GetString("policies.timezone")); err != nil {
+		return err
+	}
+	util.SetAllowedPolicyActions(config.GetStringSlice("policies.allowed_actions"))
+	util.SetClassificationLevels(config.GetStringSlice("resources.classification_levels"))
+	service.SetPolicyApprovalRequired(config.GetBool("policies.require_approval"))
+	service.SetClearanceEnforced(config.GetBool("policies.enforce_clearance"))
+	service.SetDecisionCacheTTL(config.GetDuration("policies.decision_cache_ttl"))
+	service.SetPolicyBundleSigningKey(config.GetString("policies.bundle_signing_key"))
+	service.SetPriorityMode(config.GetString("policies.priority_mode"))
+	service.SetDefaultEffect(config.GetString("pdp.defaultEffect"))
+	service.SetInheritanceMaxDepth(config.GetInt("pdp.inheritanceMaxDepth"))
+	middleware.SetAccessLog(middleware.AccessLogConfig{
+		Level:     config.GetString("access_log.level"),
+		SkipPaths: config.GetStringSlice("access_log.skip_paths"),
+		Sampling:  config.GetIntMap("access_log.sampling"),
+	})
+	middleware.SetCompression(middleware.CompressionConfig{
+		MinSize:      config.GetInt("compression.min_size"),
+		ContentTypes: config.GetStringSlice("compression.content_types"),
+	})
+	middleware.SetCORS(middleware.CORSConfig{
+		AllowedOrigins:   config.GetStringSlice("cors.allowed_origins"),
+		AllowedMethods:   config.GetStringSlice("cors.allowed_methods"),
+		AllowedHeaders:   config.GetStringSlice("cors.allowed_headers"),
+		AllowCredentials: config.GetBool("cors.allow_credentials"),
+		MaxAge:           config.GetDuration("cors.max_age"),
+	})
+	routeMaxBodySizes := map[string]int64{}
+	for route, size := range config.GetIntMap("requests.route_max_body_sizes") {
+		routeMaxBodySizes[route] = int64(size)
+	}
+	middleware.SetRequestLimits(middleware.RequestLimitsConfig{
+		MaxBodySize:       int64(config.GetInt("requests.max_body_size")),
+		RouteMaxBodySizes: routeMaxBodySizes,
+		Timeout:           config.GetDuration("requests.timeout"),
+		TimeoutExempt:     config.GetStringSlice("requests.timeout_exempt"),
+	})
 	validationUtil := util.NewValidationUtil()
-	cacheService := util.NewCacheService()
-	notificationService := util.NewNotificationService()
+	cacheService := util.NewCacheService(util.NewCircuitBreaker("redis", util.CircuitBreakerConfig{
+		FailureThreshold: config.GetInt("redis.circuit_breaker.failure_threshold"),
+		Cooldown:         config.GetDuration("redis.circuit_breaker.cooldown"),
+	}))
+	notificationChannels := map[string]util.NotificationSender{util.NotificationChannelLog: util.LogNotification}
+	if url := config.GetString("notifications.webhook.url"); url != "" {
+		notificationChannels[util.NotificationChannelWebhook] = util.WebhookSender(url)
+	}
+	if url := config.GetString("notifications.chat.webhook_url"); url != "" {
+		notificationChannels[util.NotificationChannelChat] = util.ChatSender(url)
+	}
+	var emailNotifier *util.EmailNotifier
+	if host := config.GetString("notifications.email.host"); host != "" {
+		var templates map[string]util.EmailTemplate
+		if err := config.UnmarshalKey("notifications.email.templates", &templates); err != nil {
+			return fmt.Errorf("invalid email templates: %w", err)
+		}
+		notifier, err := util.NewEmailNotifier(util.SMTPConfig{
+			Host:         host,
+			Port:         config.GetInt("notifications.email.port"),
+			Username:     config.GetString("notifications.email.username"),
+			Password:     config.GetString("notifications.email.password"),
+			TLS:          config.GetString("notifications.email.tls"),
+			From:         config.GetString("notifications.email.from"),
+			To:           config.GetStringSlice("notifications.email.to"),
+			Timeout:      config.GetDuration("notifications.email.timeout"),
+			MaxRetries:   config.GetInt("notifications.email.max_retries"),
+			RetryBackoff: config.GetDuration("notifications.email.retry_backoff"),
+			QueueSize:    config.GetInt("notifications.email.queue_size"),
+			Templates:    templates,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create email notifier: %w", err)
+		}
+		emailNotifier = notifier
+		notificationChannels[util.NotificationChannelEmail] = emailNotifier.Send
+	}
+	notificationRouter := util.NewNotificationRouter(notificationChannels, config.GetStringSliceMap("notifications.routes"))
+	notificationService := util.NewNotificationService(config.GetDuration("notifications.digest_window"), notificationRouter.Send)
 	auditRepository, err := audit.NewElasticsearchRepository(config.GetString("elasticsearch.url"))
 	if err != nil {
 		return fmt.Errorf("failed to create audit repository: %w", err)
 	}
-	auditService := audit.NewService(auditRepository)
+	auditService := audit.NewService(auditRepository, audit.RedisChainHeadStore{})
+	audit.StartRetention(ctx, auditRepository, audit.RetentionPolicy{
+		Days:   config.GetInt("audit.retention.days"),
+		DryRun: config.GetBool("audit.retention.dry_run"),
+	}, config.GetDuration("audit.retention.interval"))
+	jobRunner := util.NewJobRunner(util.RedisJobStore{TTL: config.GetDuration("jobs.ttl")}, config.GetInt("jobs.workers"), config.GetInt("jobs.queue_size"))
 
-	services, err := service.InitializeServices(db.Neo4jDriver, auditService, validationUtil, cacheService, notificationService, eventBus)
+	services, err := service.InitializeServices(db.Neo4jDriver, auditService, validationUtil, cacheService, notificationService, eventBus, jobRunner)
 	if err != nil {
 		return fmt.Errorf("failed to initialize services: %w", err)
 	}
@@ -75,7 +261,10 @@ func run() error {
 
 	rateLimitRequests := config.GetInt("rate_limit.requests")
 	rateLimitDuration := config.GetDuration("rate_limit.duration")
-	router := router.SetupRouter(controllers, rateLimitRequests, rateLimitDuration)
+	router := router.SetupRouter(controllers, services.User, rateLimitRequests, rateLimitDuration, config.GetString("tenancy.global_admin_role"))
+
+	applyHotReloadableSettings()
+	config.WatchConfig(applyHotReloadableSettings)
 
 	// Set up the server
 	server := &http.Server{
@@ -101,10 +290,44 @@ func run() error {
 	// the request it is currently handling
 	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
-		return fmt.Errorf("server forced to shutdown: %w", err)
+	shutdownErr := server.Shutdown(ctx)
+
+	// Let the handlers of events already published finish before the deferred calls close Neo4j
+	// and Redis
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), config.GetDuration("server.drain_timeout"))
+	defer drainCancel()
+	if err := jobRunner.Shutdown(drainCtx); err != nil {
+		logger.Warn("Jobs still running at shutdown were abandoned", zap.Error(err))
+	}
+	if err := eventBus.Shutdown(drainCtx); err != nil {
+		logger.Warn("Event handlers still running at shutdown were abandoned", zap.Error(err))
+	}
+	if err := notificationService.Shutdown(drainCtx); err != nil {
+		logger.Warn("Notification digests still sending at shutdown were abandoned", zap.Error(err))
+	}
+	if emailNotifier != nil {
+		if err := emailNotifier.Shutdown(drainCtx); err != nil {
+			logger.Warn("Notification emails still queued at shutdown were abandoned", zap.Error(err))
+		}
+	}
+
+	if shutdownErr != nil {
+		return fmt.Errorf("server forced to shutdown: %w", shutdownErr)
 	}
 
 	logger.Info("Server exiting")
 	return nil
 }
+
+// applyHotReloadableSettings puts the settings config.HotReloadableKeys lists into effect. Each is
+// handed to the package using it, which keeps it where requests may read it while it changes.
+func applyHotReloadableSettings() {
+	if level := config.GetString("log.level"); level != "" {
+		if err := logger.SetLevel(level); err != nil {
+			logger.Warn("Invalid log level ignored", zap.String("level", level), zap.Error(err))
+		}
+	}
+	middleware.SetRateLimit(config.GetInt("rate_limit.requests"), config.GetDuration("rate_limit.duration"))
+	db.SetDefaultCacheTTL(config.GetDuration("redis.defaultCacheTTL"))
+	audit.SetRetentionInterval(config.GetDuration("audit.retention.interval"))
+}
@@ -0,0 +1,171 @@
+// api/middleware/compress.go
+
+package middleware
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompressionConfig controls which responses Compress gzips
+type CompressionConfig struct {
+	MinSize      int      // Responses smaller than this many bytes are sent as they are
+	ContentTypes []string // Media types compressed, such as "application/json"
+}
+
+var compression atomic.Pointer[CompressionConfig]
+
+func init() {
+	SetCompression(CompressionConfig{MinSize: 1024, ContentTypes: []string{"application/json", "text/csv"}})
+}
+
+// SetCompression changes which responses Compress gzips
+func SetCompression(cfg CompressionConfig) {
+	compression.Store(&cfg)
+}
+
+// Compress is a middleware that gzips responses for clients accepting it. A response is compressed
+// when its content type is allowed, it carries no Content-Encoding already, and it reaches the minimum
+// size or is flushed before the handler is done, as streamed exports are.
+func Compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &gzipWriter{ResponseWriter: original, settings: compression.Load()}
+		c.Writer = writer
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		defer func() {
+			writer.finish()
+			c.Writer = original
+		}()
+
+		c.Next()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip, e.g. "gzip, deflate" but not
+// "gzip;q=0"
+func acceptsGzip(header string) bool {
+	for _, entry := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter holds back the start of a response until it knows whether to compress it
+type gzipWriter struct {
+	gin.ResponseWriter
+	settings *CompressionConfig
+	buffer   []byte
+	decided  bool
+	gz       *gzip.Writer // Nil while undecided and when the response is sent as it is
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if !w.compressible() {
+			if err := w.decide(false); err != nil {
+				return 0, err
+			}
+		} else {
+			w.buffer = append(w.buffer, data...)
+			if len(w.buffer) < w.settings.MinSize {
+				return len(data), nil
+			}
+			if err := w.decide(true); err != nil {
+				return 0, err
+			}
+			return len(data), nil
+		}
+	}
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush compresses a response still undecided, since a handler flushing is streaming it
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		w.decide(w.compressible())
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// compressible reports whether the response may be compressed, judging by its status and headers
+func (w *gzipWriter) compressible() bool {
+	status := w.Status()
+	if status == http.StatusNoContent || status == http.StatusNotModified || w.Header().Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, allowed := range w.settings.ContentTypes {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// decide settles whether the response is compressed and writes out what was held back
+func (w *gzipWriter) decide(compress bool) error {
+	w.decided = true
+	if compress {
+		w.Header().Del("Content-Length")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+
+	buffered := w.buffer
+	w.buffer = nil
+	if len(buffered) == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(buffered)
+	} else {
+		_, err = w.ResponseWriter.Write(buffered)
+	}
+	return err
+}
+
+// finish sends a response too small to compress as it is, or ends the compressed stream
+func (w *gzipWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
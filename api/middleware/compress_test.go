@@ -0,0 +1,103 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dev-mohitbeniwal/echo/api/middleware"
+)
+
+func TestCompress(t *testing.T) {
+	middleware.SetCompression(middleware.CompressionConfig{MinSize: 1024, ContentTypes: []string{"application/json", "text/csv"}})
+
+	large := strings.Repeat("resource ", 500)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Compress())
+	router.GET("/large", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"items": large}) })
+	router.GET("/tiny", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"id": "r1"}) })
+	router.GET("/text", func(c *gin.Context) { c.String(http.StatusOK, large) })
+	router.GET("/encoded", func(c *gin.Context) {
+		c.Header("Content-Encoding", "br")
+		c.Data(http.StatusOK, "application/json", []byte(large))
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		c.Writer.WriteString("id,name\n")
+		c.Writer.Flush()
+		c.Writer.WriteString("r1,Report\n")
+	})
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			request.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	gunzip := func(t *testing.T, body io.Reader) string {
+		reader, err := gzip.NewReader(body)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("LargeJSONIsCompressed", func(t *testing.T) {
+		recorder := get("/large", "gzip, deflate")
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+		assert.Less(t, recorder.Body.Len(), len(large))
+		assert.JSONEq(t, `{"items":"`+large+`"}`, gunzip(t, recorder.Body))
+	})
+
+	t.Run("TinyJSONIsNot", func(t *testing.T) {
+		recorder := get("/tiny", "gzip")
+
+		assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+		assert.JSONEq(t, `{"id":"r1"}`, recorder.Body.String())
+	})
+
+	t.Run("ClientNotAcceptingGzip", func(t *testing.T) {
+		for _, acceptEncoding := range []string{"", "deflate", "gzip;q=0"} {
+			recorder := get("/large", acceptEncoding)
+
+			assert.Empty(t, recorder.Header().Get("Content-Encoding"), acceptEncoding)
+		}
+	})
+
+	t.Run("OtherContentType", func(t *testing.T) {
+		recorder := get("/text", "gzip")
+
+		assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, recorder.Body.String())
+	})
+
+	t.Run("AlreadyEncoded", func(t *testing.T) {
+		recorder := get("/encoded", "gzip")
+
+		assert.Equal(t, "br", recorder.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, recorder.Body.String())
+	})
+
+	t.Run("FlushedStreamIsCompressed", func(t *testing.T) {
+		recorder := get("/stream", "gzip")
+
+		assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+		assert.Equal(t, "id,name\nr1,Report\n", gunzip(t, recorder.Body))
+	})
+}
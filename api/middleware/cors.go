@@ -0,0 +1,108 @@
+// api/middleware/cors.go
+
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig controls which browser origins may call the API cross-origin. No origin is allowed
+// unless listed; "*" allows every origin.
+type CORSConfig struct {
+	AllowedOrigins   []string      // Origins such as "https://admin.example.com"
+	AllowedMethods   []string      // Methods preflight requests may ask for
+	AllowedHeaders   []string      // Request headers preflight requests may ask for
+	AllowCredentials bool          // Whether browsers may send cookies and authorization headers
+	MaxAge           time.Duration // How long browsers may cache a preflight response; zero leaves it to them
+}
+
+var cors atomic.Pointer[CORSConfig]
+
+func init() {
+	SetCORS(CORSConfig{
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
+		AllowedHeaders: []string{"Authorization", "Content-Type", RequestIDHeader},
+	})
+}
+
+// SetCORS changes which origins CORS allows
+func SetCORS(cfg CORSConfig) {
+	cors.Store(&cfg)
+}
+
+// CORS is a middleware answering cross-origin requests from allowed origins. The request origin is
+// echoed back, never "*", so credentials keep working. Preflight requests are answered here, before
+// authentication, with 204 when allowed and 403 otherwise; other requests from origins not allowed
+// are served without CORS headers, which leaves the browser to block them.
+func CORS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		settings := cors.Load()
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		allowed := containsFold(settings.AllowedOrigins, origin) || containsFold(settings.AllowedOrigins, "*")
+
+		if !preflight {
+			if allowed {
+				header.Set("Access-Control-Allow-Origin", origin)
+				if settings.AllowCredentials {
+					header.Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+			c.Next()
+			return
+		}
+
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		if !allowed || !containsFold(settings.AllowedMethods, c.GetHeader("Access-Control-Request-Method")) ||
+			!headersAllowed(settings.AllowedHeaders, c.GetHeader("Access-Control-Request-Headers")) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Methods", strings.Join(settings.AllowedMethods, ", "))
+		if len(settings.AllowedHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(settings.AllowedHeaders, ", "))
+		}
+		if settings.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if settings.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(settings.MaxAge.Seconds())))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// headersAllowed reports whether every header of an Access-Control-Request-Headers list is allowed
+func headersAllowed(allowed []string, requested string) bool {
+	for _, name := range strings.Split(requested, ",") {
+		if name = strings.TrimSpace(name); name != "" && !containsFold(allowed, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dev-mohitbeniwal/echo/api/middleware"
+)
+
+func TestCORS(t *testing.T) {
+	middleware.SetCORS(middleware.CORSConfig{
+		AllowedOrigins:   []string{"https://admin.example.com"},
+		AllowedMethods:   []string{"GET", "POST", "DELETE"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	defer middleware.SetCORS(middleware.CORSConfig{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.CORS())
+	// Stands in for authentication, which preflight requests must never reach
+	router.Use(func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	})
+	router.GET("/policies", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"items": []string{}}) })
+
+	send := func(method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/policies", nil)
+		request.Header.Set("Origin", origin)
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("AllowedOrigin", func(t *testing.T) {
+		recorder := send(http.MethodGet, "https://admin.example.com", map[string]string{"Authorization": "Bearer token"})
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "https://admin.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Contains(t, recorder.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("DisallowedOrigin", func(t *testing.T) {
+		recorder := send(http.MethodGet, "https://evil.example.com", map[string]string{"Authorization": "Bearer token"})
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("Preflight", func(t *testing.T) {
+		recorder := send(http.MethodOptions, "https://admin.example.com", map[string]string{
+			"Access-Control-Request-Method":  "DELETE",
+			"Access-Control-Request-Headers": "authorization, content-type",
+		})
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Equal(t, "https://admin.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, POST, DELETE", recorder.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Authorization, Content-Type", recorder.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", recorder.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("PreflightFromDisallowedOrigin", func(t *testing.T) {
+		recorder := send(http.MethodOptions, "https://evil.example.com", map[string]string{
+			"Access-Control-Request-Method": "GET",
+		})
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("PreflightForMethodNotAllowed", func(t *testing.T) {
+		recorder := send(http.MethodOptions, "https://admin.example.com", map[string]string{
+			"Access-Control-Request-Method": "PUT",
+		})
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/dev-mohitbeniwal/echo/api/config"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/util"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -32,6 +34,12 @@ type CognitoClaims struct {
 	CognitoUsername string   `json:"cognito:username"`
 	EmailVerified   bool     `json:"email_verified"`
 	Email           string   `json:"email"`
+	OrganizationID  string   `json:"custom:organization_id"`
+}
+
+// LoginRecorder is notified whenever a request is successfully authenticated
+type LoginRecorder interface {
+	RecordLogin(ctx context.Context, userID string) error
 }
 
 type Jwks struct {
@@ -96,13 +104,13 @@ func GetCognitoPublicKey(region, userPoolID string) (*rsa.PublicKey, error) {
 	return publicKey, nil
 }
 
-func GroupAuthMiddleware(requiredGroups []string) gin.HandlerFunc {
+func GroupAuthMiddleware(requiredGroups []string, loginRecorder LoginRecorder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		logger.Info("Received token: %s", zap.String("token", tokenString))
 		if tokenString == "" {
 			logger.Warn("No Authorization token provided")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.JSON(http.StatusUnauthorized, util.NewErrorResponse("UNAUTHORIZED", "Unauthorized"))
 			c.Abort()
 			return
 		}
@@ -110,7 +118,7 @@ func GroupAuthMiddleware(requiredGroups []string) gin.HandlerFunc {
 		claims, err := parseTokenUnverified(tokenString)
 		if err != nil {
 			logger.Error("Error parsing token: %v", zap.Error(err))
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.JSON(http.StatusUnauthorized, util.NewErrorResponse("UNAUTHORIZED", "Unauthorized"))
 			c.Abort()
 			return
 		}
@@ -118,7 +126,7 @@ func GroupAuthMiddleware(requiredGroups []string) gin.HandlerFunc {
 		logger.Info("Parsed claims: %+v", zap.Any("claims", claims))
 		if !isUserInGroups(claims, requiredGroups) {
 			logger.Warn("User does not have the required groups")
-			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			c.JSON(http.StatusForbidden, util.NewErrorResponse("FORBIDDEN", "Forbidden"))
 			c.Abort()
 			return
 		}
@@ -126,8 +134,31 @@ func GroupAuthMiddleware(requiredGroups []string) gin.HandlerFunc {
 		// Add the user's sub to the context
 		c.Set("requestingUserID", claims.Subject)
 		c.Set("requestingUser", claims.CognitoUsername)
+		c.Set("requestingOrganizationID", claims.OrganizationID)
+		c.Set("requestingRoles", claims.CognitoGroups)
 		logger.Info("Added user sub to context: %s", zap.Any("sub", claims.Subject))
 
+		if loginRecorder != nil {
+			if err := loginRecorder.RecordLogin(c, claims.Subject); err != nil {
+				logger.Warn("Failed to record login", zap.Error(err), zap.String("sub", claims.Subject))
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireGroups only lets through users belonging to one of groups. It reads the groups
+// GroupAuthMiddleware stored, so it must run after it.
+func RequireGroups(groups ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userGroups, _ := c.Value("requestingRoles").([]string)
+		if !isUserInGroups(&CognitoClaims{CognitoGroups: userGroups}, groups) {
+			logger.Warn("User does not have the required groups", zap.Strings("required", groups), zap.String("path", c.FullPath()))
+			c.JSON(http.StatusForbidden, util.NewErrorResponse("FORBIDDEN", "Forbidden"))
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,253 @@
+// api/middleware/limits.go
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// RequestLimitsConfig bounds how large a request body may be and how long its handler may take
+type RequestLimitsConfig struct {
+	MaxBodySize       int64            // Bytes; zero allows any size
+	RouteMaxBodySizes map[string]int64 // Route, such as "/api/v1/resources/bulk-tag", to a limit replacing MaxBodySize
+	Timeout           time.Duration    // Zero lets handlers take as long as they need
+	TimeoutExempt     []string         // Routes with no timeout, such as streamed exports
+}
+
+var requestLimits atomic.Pointer[RequestLimitsConfig]
+
+func init() {
+	SetRequestLimits(RequestLimitsConfig{MaxBodySize: 1 << 20, Timeout: 30 * time.Second})
+}
+
+// SetRequestLimits changes the limits BodyLimit and Timeout enforce
+func SetRequestLimits(cfg RequestLimitsConfig) {
+	requestLimits.Store(&cfg)
+}
+
+// BodyLimit is a middleware rejecting request bodies over the size limit of their route with 413. The
+// body is read through http.MaxBytesReader before the handler runs, so no handler sees a truncated
+// body and the memory a request takes stays within the limit.
+func BodyLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings := requestLimits.Load()
+		limit := settings.MaxBodySize
+		if routeLimit, ok := settings.RouteMaxBodySizes[c.FullPath()]; ok {
+			limit = routeLimit
+		}
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		tooLarge := func() {
+			util.RespondWithError(c, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("Request body exceeds %d bytes", limit), nil)
+			c.Abort()
+		}
+		if c.Request.ContentLength > limit {
+			tooLarge()
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				tooLarge()
+				return
+			}
+			util.RespondWithError(c, http.StatusBadRequest, "Failed to read request body", err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// Timeout is a middleware giving each request a deadline. The rest of the chain sees it on the request
+// context. A handler still running when it passes is answered for with 503 unless it has started its
+// response, and whatever it writes afterwards is dropped. The context is only cancelled once the
+// writer has been marked timed out, so a handler woken by it can no longer slip its response in first.
+func Timeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings := requestLimits.Load()
+		if settings.Timeout <= 0 || containsFold(settings.TimeoutExempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithCancelCause(c.Request.Context())
+		defer cancel(nil)
+		c.Request = c.Request.WithContext(&timeoutContext{Context: ctx, deadline: time.Now().Add(settings.Timeout)})
+
+		original := c.Writer
+		writer := &timeoutWriter{ResponseWriter: original, header: http.Header{}}
+		c.Writer = writer
+		defer func() { c.Writer = original }()
+
+		fired := make(chan struct{})
+		timer := time.AfterFunc(settings.Timeout, func() {
+			defer close(fired)
+			if writer.timeOut() {
+				logger.Warn("Request timed out",
+					zap.String("path", c.Request.URL.Path),
+					zap.String("method", c.Request.Method),
+					zap.Duration("timeout", settings.Timeout))
+			}
+			cancel(context.DeadlineExceeded)
+		})
+
+		var panicked interface{}
+		func() {
+			defer func() { panicked = recover() }()
+			c.Next()
+		}()
+		// A timer already fired may still be writing the 503; it must finish before gin reuses the writer
+		if !timer.Stop() {
+			<-fired
+		}
+		if panicked != nil {
+			panic(panicked)
+		}
+	}
+}
+
+// timeoutContext is the request context under Timeout. It reports the request's deadline, and once
+// Timeout cancels it on that deadline, context.DeadlineExceeded as its error.
+type timeoutContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	if deadline, ok := c.Context.Deadline(); ok && deadline.Before(c.deadline) {
+		return deadline, true
+	}
+	return c.deadline, true
+}
+
+func (c *timeoutContext) Err() error {
+	if c.Context.Err() == nil {
+		return nil
+	}
+	return context.Cause(c.Context)
+}
+
+// timeoutWriter passes a handler's response through until the request times out. The status and
+// headers are held back until the first write, since a timeout may still replace them.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	status   int
+	wrote    bool
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.wrote && !w.timedOut {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.commit() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.commit() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.commit() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.wrote && w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.wrote
+}
+
+// commit sends the held back status and headers on the handler's first write. It reports whether the
+// handler may still write, which it may not once the request timed out.
+func (w *timeoutWriter) commit() bool {
+	if w.timedOut {
+		return false
+	}
+	if !w.wrote {
+		w.wrote = true
+		for name, values := range w.header {
+			w.ResponseWriter.Header()[name] = values
+		}
+		if w.status != 0 {
+			w.ResponseWriter.WriteHeader(w.status)
+		}
+	}
+	return true
+}
+
+// timeOut answers with 503 unless the handler has started its response, and drops the handler's
+// writes from then on. It reports whether it answered.
+func (w *timeoutWriter) timeOut() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+	if w.wrote {
+		return false
+	}
+	w.wrote = true
+	body, _ := json.Marshal(util.NewErrorResponse("REQUEST_TIMEOUT", "Request timed out"))
+	w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(http.StatusServiceUnavailable)
+	w.ResponseWriter.Write(body)
+	w.ResponseWriter.Flush()
+	return true
+}
@@ -0,0 +1,149 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/middleware"
+)
+
+func TestBodyLimit(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer middleware.SetRequestLimits(middleware.RequestLimitsConfig{MaxBodySize: 1 << 20, Timeout: 30 * time.Second})
+
+	middleware.SetRequestLimits(middleware.RequestLimitsConfig{
+		MaxBodySize:       16,
+		RouteMaxBodySizes: map[string]int64{"/bulk": 64},
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.BodyLimit())
+	echoBody := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		assert.NoError(t, err)
+		c.String(http.StatusOK, string(body))
+	}
+	router.POST("/policies", echoBody)
+	router.POST("/bulk", echoBody)
+
+	post := func(path, body string, chunked bool) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if chunked {
+			// Without a Content-Length the limit is only found while reading
+			request.ContentLength = -1
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		recorder := post("/policies", `{"name":"Docs"}`, false)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `{"name":"Docs"}`, recorder.Body.String())
+	})
+
+	t.Run("OverLimit", func(t *testing.T) {
+		recorder := post("/policies", `{"name":"Quarterly reports"}`, false)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "REQUEST_ENTITY_TOO_LARGE")
+	})
+
+	t.Run("OverLimitWithoutContentLength", func(t *testing.T) {
+		recorder := post("/policies", `{"name":"Quarterly reports"}`, true)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	})
+
+	t.Run("RouteWithHigherLimit", func(t *testing.T) {
+		recorder := post("/bulk", `{"name":"Quarterly reports"}`, false)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer middleware.SetRequestLimits(middleware.RequestLimitsConfig{MaxBodySize: 1 << 20, Timeout: 30 * time.Second})
+
+	middleware.SetRequestLimits(middleware.RequestLimitsConfig{
+		Timeout:       50 * time.Millisecond,
+		TimeoutExempt: []string{"/export"},
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Timeout())
+	router.GET("/fast", func(c *gin.Context) {
+		c.Header("X-Handler", "fast")
+		c.JSON(http.StatusCreated, gin.H{"id": "p1"})
+	})
+	slow := func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+		c.JSON(http.StatusOK, gin.H{"id": "p1"})
+	}
+	router.GET("/slow", slow)
+	router.GET("/export", slow)
+	var deadlineSeen bool
+	var ctxErr error
+	router.GET("/deadline", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		_, deadlineSeen = ctx.Deadline()
+		<-ctx.Done()
+		ctxErr = ctx.Err()
+		c.JSON(http.StatusOK, gin.H{"id": "p1"})
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	t.Run("HandlerWithinTimeout", func(t *testing.T) {
+		recorder := get("/fast")
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "fast", recorder.Header().Get("X-Handler"))
+		assert.JSONEq(t, `{"id":"p1"}`, recorder.Body.String())
+	})
+
+	t.Run("HandlerExceedingTimeout", func(t *testing.T) {
+		recorder := get("/slow")
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "REQUEST_TIMEOUT")
+		assert.NotContains(t, recorder.Body.String(), "p1")
+	})
+
+	t.Run("HandlerSeesDeadline", func(t *testing.T) {
+		recorder := get("/deadline")
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.True(t, deadlineSeen)
+		assert.ErrorIs(t, ctxErr, context.DeadlineExceeded)
+	})
+
+	t.Run("ExemptRoute", func(t *testing.T) {
+		recorder := get("/export")
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
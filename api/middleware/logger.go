@@ -1,50 +1,124 @@
 package middleware
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 )
 
-// Logger is a middleware that logs incoming HTTP requests
+// RequestIDHeader carries the ID correlating a request with its access log line. A client may send
+// one; otherwise Logger generates it. Either way it is echoed in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// AccessLogConfig controls the access log Logger writes
+type AccessLogConfig struct {
+	Level     string         // Level access lines are logged at, such as "info" or "debug"
+	SkipPaths []string       // Paths never logged, such as health checks
+	Sampling  map[string]int // Path to N, logging one in every N successful requests to it
+}
+
+type accessLogSettings struct {
+	level    zapcore.Level
+	skip     map[string]bool
+	sampling map[string]int
+	counters map[string]*atomic.Uint64
+}
+
+var accessLog atomic.Pointer[accessLogSettings]
+
+func init() {
+	SetAccessLog(AccessLogConfig{Level: "info"})
+}
+
+// SetAccessLog changes what Logger logs. An unknown level falls back to info.
+func SetAccessLog(cfg AccessLogConfig) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		level = zapcore.InfoLevel
+	}
+	settings := &accessLogSettings{
+		level:    level,
+		skip:     make(map[string]bool),
+		sampling: make(map[string]int),
+		counters: make(map[string]*atomic.Uint64),
+	}
+	for _, path := range cfg.SkipPaths {
+		settings.skip[path] = true
+	}
+	for path, every := range cfg.Sampling {
+		if every > 1 {
+			settings.sampling[path] = every
+			settings.counters[path] = &atomic.Uint64{}
+		}
+	}
+	accessLog.Store(settings)
+}
+
+// Logger is a middleware that writes one access log line per HTTP request, with its method, path,
+// status, latency, response size, user and request ID. Requests to sampled paths are logged one in
+// every N unless they fail.
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 		query := c.Request.URL.RawQuery
 
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+
 		// Process request
 		c.Next()
 
-		// Log request details
-		end := time.Now()
-		latency := end.Sub(start)
+		settings := accessLog.Load()
+		route := c.FullPath()
+		if route == "" {
+			route = path
+		}
+		if settings.skip[path] || settings.skip[route] {
+			return
+		}
+		status := c.Writer.Status()
+		if every, ok := settings.sampling[route]; ok && status < 500 && len(c.Errors) == 0 {
+			if settings.counters[route].Add(1)%uint64(every) != 1 {
+				return
+			}
+		}
 
+		level := settings.level
+		if status >= 500 || len(c.Errors) > 0 {
+			level = zapcore.ErrorLevel
+		}
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		userID, _ := c.Get("requestingUserID")
+		fields := []zap.Field{
+			zap.String("requestID", requestID),
+			zap.String("method", c.Request.Method),
+			zap.String("path", path),
+			zap.String("route", route),
+			zap.String("query", query),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("size", size),
+			zap.Any("userID", userID),
+			zap.String("ip", c.ClientIP()),
+			zap.String("user-agent", c.Request.UserAgent()),
+		}
 		if len(c.Errors) > 0 {
-			// Log errors if any
-			for _, e := range c.Errors.Errors() {
-				logger.Error("Request error",
-					zap.String("path", path),
-					zap.String("query", query),
-					zap.String("ip", c.ClientIP()),
-					zap.String("user-agent", c.Request.UserAgent()),
-					zap.String("error", e),
-				)
-			}
-		} else {
-			// Log request details
-			logger.Info("Request processed",
-				zap.String("method", c.Request.Method),
-				zap.String("path", path),
-				zap.String("query", query),
-				zap.Int("status", c.Writer.Status()),
-				zap.Duration("latency", latency),
-				zap.String("ip", c.ClientIP()),
-				zap.String("user-agent", c.Request.UserAgent()),
-			)
+			fields = append(fields, zap.Strings("errors", c.Errors.Errors()))
 		}
+		logger.LogAt(level, "Request processed", fields...)
 	}
 }
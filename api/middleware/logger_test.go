@@ -0,0 +1,86 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/middleware"
+)
+
+func TestLoggerWritesAccessLog(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	previous := logger.Log
+	logger.Log = zap.New(core)
+	defer func() { logger.Log = previous }()
+	defer middleware.SetAccessLog(middleware.AccessLogConfig{Level: "info"})
+
+	middleware.SetAccessLog(middleware.AccessLogConfig{
+		Level:     "debug",
+		SkipPaths: []string{"/health"},
+		Sampling:  map[string]int{"/busy": 3},
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Logger())
+	router.GET("/resources/:id", func(c *gin.Context) {
+		c.Set("requestingUserID", "u1")
+		c.String(http.StatusOK, "hello")
+	})
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/busy", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := httptest.NewRequest(http.MethodGet, "/resources/r1?verbose=true", nil)
+	request.Header.Set(middleware.RequestIDHeader, "req-1")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, "req-1", recorder.Header().Get(middleware.RequestIDHeader))
+	entries := logs.FilterMessage("Request processed").AllUntimed()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, zapcore.DebugLevel, entries[0].Level)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "req-1", fields["requestID"])
+		assert.Equal(t, "GET", fields["method"])
+		assert.Equal(t, "/resources/r1", fields["path"])
+		assert.Equal(t, "/resources/:id", fields["route"])
+		assert.Equal(t, int64(http.StatusOK), fields["status"])
+		assert.Equal(t, int64(len("hello")), fields["size"])
+		assert.Equal(t, "u1", fields["userID"])
+		assert.Contains(t, fields, "latency")
+	}
+
+	t.Run("GeneratesRequestID", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/resources/r2", nil))
+
+		assert.NotEmpty(t, recorder.Header().Get(middleware.RequestIDHeader))
+	})
+
+	t.Run("SkipsHealthChecks", func(t *testing.T) {
+		logs.TakeAll()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Zero(t, logs.Len())
+	})
+
+	t.Run("SamplesBusyRoutes", func(t *testing.T) {
+		logs.TakeAll()
+		for i := 0; i < 6; i++ {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/busy", nil))
+		}
+
+		assert.Equal(t, 2, logs.FilterMessage("Request processed").Len())
+	})
+}
@@ -5,6 +5,7 @@ package middleware
 import (
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,15 +13,32 @@ import (
 
 	"github.com/dev-mohitbeniwal/echo/api/db"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/util"
 )
 
+type rateLimitSettings struct {
+	limit int
+	per   time.Duration
+}
+
+var rateLimit atomic.Pointer[rateLimitSettings]
+
+// SetRateLimit changes the number of requests a client may make per period. Running RateLimiter
+// middleware applies it from the next request on.
+func SetRateLimit(limit int, per time.Duration) {
+	rateLimit.Store(&rateLimitSettings{limit: limit, per: per})
+}
+
 func RateLimiter(limit int, per time.Duration) gin.HandlerFunc {
+	SetRateLimit(limit, per)
 	return func(c *gin.Context) {
+		settings := rateLimit.Load()
+		limit, per := settings.limit, settings.per
 		key := c.ClientIP() // Or use a user identifier
 		allowed, err := db.RateLimit(c, key, limit, per)
 		if err != nil {
 			logger.Error("Rate limiting failed", zap.Error(err), zap.String("ip", key))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Rate limiting failed"})
+			c.JSON(http.StatusInternalServerError, util.NewErrorResponse(util.CodeInternalError, "Rate limiting failed"))
 			c.Abort()
 			return
 		}
@@ -34,7 +52,7 @@ func RateLimiter(limit int, per time.Duration) gin.HandlerFunc {
 				zap.String("ip", key),
 				zap.Int("limit", limit),
 				zap.Duration("per", per))
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			c.JSON(http.StatusTooManyRequests, util.NewErrorResponse("RATE_LIMIT_EXCEEDED", "Rate limit exceeded"))
 			c.Abort()
 			return
 		}
@@ -0,0 +1,42 @@
+// api/middleware/read_only.go
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// ReadOnly is a middleware rejecting POST, PUT, PATCH and DELETE requests with 503 while the API is
+// read-only for maintenance. The admin routes stay open so the mode can be turned off, and so do the
+// POST routes that only read: access evaluations, access review jobs, searches and batch gets.
+func ReadOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if readOnlyExempt(c.FullPath()) || !util.IsReadOnly(c) {
+			c.Next()
+			return
+		}
+
+		util.RespondWithError(c, http.StatusServiceUnavailable, "The service is read-only for maintenance; retry later", echo_errors.ErrServiceReadOnly)
+		c.Abort()
+	}
+}
+
+// readOnlyExempt reports whether a route keeps serving writes while read-only
+func readOnlyExempt(route string) bool {
+	return strings.HasPrefix(route, "/api/v1/admin/") ||
+		strings.HasSuffix(route, "/access/evaluate") ||
+		strings.HasSuffix(route, "/access-review/jobs") ||
+		strings.HasSuffix(route, "/search") ||
+		strings.HasSuffix(route, "/batch-get")
+}
@@ -0,0 +1,71 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/middleware"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestReadOnly(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	server := mock.StartRedisServer(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ReadOnly())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/policies/:id", ok)
+	router.POST("/api/v1/policies", ok)
+	router.DELETE("/api/v1/policies/:id", ok)
+	router.POST("/api/v1/policies/search", ok)
+	router.POST("/api/v1/access/evaluate", ok)
+	router.PUT("/api/v1/admin/read-only", ok)
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, "/api/v1/policies").Code)
+
+	require.NoError(t, util.SetReadOnly(context.Background(), true))
+	defer util.SetReadOnly(context.Background(), false)
+	assert.True(t, server.Has("maintenance:read_only"))
+
+	t.Run("Writes are rejected", func(t *testing.T) {
+		for _, method := range []string{http.MethodPost, http.MethodDelete} {
+			path := "/api/v1/policies"
+			if method == http.MethodDelete {
+				path += "/p1"
+			}
+			w := send(method, path)
+
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.Contains(t, w.Body.String(), `"code":"SERVICE_READ_ONLY"`)
+		}
+	})
+
+	t.Run("Reads are served", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/v1/policies/p1").Code)
+		assert.Equal(t, http.StatusOK, send(http.MethodPost, "/api/v1/policies/search").Code)
+		assert.Equal(t, http.StatusOK, send(http.MethodPost, "/api/v1/access/evaluate").Code)
+	})
+
+	t.Run("The mode can be turned off", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(http.MethodPut, "/api/v1/admin/read-only").Code)
+	})
+}
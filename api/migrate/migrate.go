@@ -0,0 +1,204 @@
+// api/migrate/migrate.go
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+)
+
+// Migration is one versioned change to the graph, such as a new constraint, a relationship rename or
+// a backfill. Its Cypher statements, or its Up function when it has none, run in one write
+// transaction. The version is recorded in a separate transaction, since Neo4j does not allow data
+// writes after a schema change in the same one, so a migration must be safe to run again should
+// recording it fail.
+type Migration struct {
+	Version     int
+	Description string
+	Cypher      []string                         // Statements run in order
+	Up          func(tx neo4j.Transaction) error // Used when Cypher is empty
+}
+
+// Migrations are the graph migrations in the order they apply. Add new ones at the end with the next
+// version; never change or remove one that has been released.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Rename department BELONGS_TO relationships to PART_OF and CHILD_OF",
+		Cypher: []string{
+			`MATCH (d:` + echo_neo4j.LabelDepartment + `)-[old:` + echo_neo4j.RelBelongsTo + `]->(o:` + echo_neo4j.LabelOrganization + `)
+            MERGE (d)-[:` + echo_neo4j.RelPartOf + `]->(o)
+            DELETE old`,
+			`MATCH (d:` + echo_neo4j.LabelDepartment + `)-[old:` + echo_neo4j.RelBelongsTo + `]->(parent:` + echo_neo4j.LabelDepartment + `)
+            MERGE (d)-[:` + echo_neo4j.RelChildOf + `]->(parent)
+            DELETE old`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "Link policies to their resource types, attribute groups, subject and condition nodes",
+		Up:          dao.RelinkPolicies,
+	},
+	{
+		Version:     3,
+		Description: "Drop the roleIds and groupIds user properties in favour of HAS_ROLE and BELONGS_TO_GROUP",
+		Cypher: []string{
+			`MATCH (u:` + echo_neo4j.LabelUser + `)
+            WHERE u.roleIds IS NOT NULL OR u.groupIds IS NOT NULL
+            REMOVE u.roleIds, u.groupIds`,
+		},
+	},
+	{
+		Version:     4,
+		Description: "Normalize user statuses to lower case and map the legacy inactive status to disabled",
+		Cypher: []string{
+			`MATCH (u:` + echo_neo4j.LabelUser + `)
+            WHERE u.status IS NOT NULL AND u.status <> toLower(trim(u.status))
+            SET u.status = toLower(trim(u.status))`,
+			`MATCH (u:` + echo_neo4j.LabelUser + ` {status: 'inactive'})
+            SET u.status = 'disabled'`,
+		},
+	},
+	{
+		Version:     5,
+		Description: "Snapshot the current version of resources written before resource versions were kept",
+		Cypher: []string{
+			`MATCH (r:` + echo_neo4j.LabelResource + `)
+            WHERE NOT (r)-[:` + echo_neo4j.RelHasVersion + `]->(:` + echo_neo4j.LabelResourceVersion + `)
+            SET r.version = coalesce(r.version, 1)
+            WITH r
+            CREATE (r)-[:` + echo_neo4j.RelHasVersion + `]->(v:` + echo_neo4j.LabelResourceVersion + `)
+            SET v = properties(r), v.resourceID = r.id`,
+		},
+	},
+}
+
+// Run applies the Migrations not applied yet
+func Run(ctx context.Context, driver neo4j.Driver) ([]int, error) {
+	return Apply(ctx, driver, Migrations)
+}
+
+// Apply applies, by ascending version, the migrations no SchemaMigration node records yet and returns
+// the versions it applied. It stops at the first failing migration.
+func Apply(ctx context.Context, driver neo4j.Driver, migrations []Migration) ([]int, error) {
+	start := time.Now()
+	ordered := append([]Migration(nil), migrations...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Version < ordered[j].Version
+	})
+	for i, migration := range ordered {
+		if migration.Version <= 0 {
+			return nil, fmt.Errorf("migration %q has invalid version %d", migration.Description, migration.Version)
+		}
+		if i > 0 && ordered[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("migration version %d is used twice", migration.Version)
+		}
+	}
+
+	session := driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	if err := ensureVersionConstraint(session); err != nil {
+		return nil, err
+	}
+	done, err := appliedVersions(session)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []int{}
+	for _, migration := range ordered {
+		if done[migration.Version] {
+			continue
+		}
+
+		logger.Info("Applying migration", zap.Int("version", migration.Version), zap.String("description", migration.Description))
+		if _, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+			return nil, runMigration(tx, migration)
+		}); err != nil {
+			logger.Error("Migration failed", zap.Error(err), zap.Int("version", migration.Version))
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+
+		if _, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+			query := `
+            MERGE (m:` + echo_neo4j.LabelSchemaMigration + ` {version: $version})
+            ON CREATE SET m.description = $description, m.appliedAt = $appliedAt
+            `
+			_, err := tx.Run(query, map[string]interface{}{
+				"version":     migration.Version,
+				"description": migration.Description,
+				"appliedAt":   time.Now().UTC().Format(time.RFC3339),
+			})
+			return nil, err
+		}); err != nil {
+			logger.Error("Failed to record migration", zap.Error(err), zap.Int("version", migration.Version))
+			return applied, fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+		}
+		applied = append(applied, migration.Version)
+	}
+
+	logger.Info("Migrations applied",
+		zap.Ints("versions", applied),
+		zap.Duration("duration", time.Since(start)))
+	return applied, nil
+}
+
+func runMigration(tx neo4j.Transaction, migration Migration) error {
+	if len(migration.Cypher) == 0 {
+		if migration.Up == nil {
+			return fmt.Errorf("migration has neither Cypher nor Up")
+		}
+		return migration.Up(tx)
+	}
+	for _, statement := range migration.Cypher {
+		if _, err := tx.Run(statement, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureVersionConstraint(session neo4j.Session) error {
+	_, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		query := `
+        CREATE CONSTRAINT unique_schema_migration_version IF NOT EXISTS
+        FOR (m:` + echo_neo4j.LabelSchemaMigration + `) REQUIRE m.version IS UNIQUE
+        `
+		_, err := tx.Run(query, nil)
+		return nil, err
+	})
+	if err != nil {
+		logger.Error("Failed to ensure unique constraint on SchemaMigration version", zap.Error(err))
+		return fmt.Errorf("failed to ensure migration version constraint: %w", err)
+	}
+	return nil
+}
+
+func appliedVersions(session neo4j.Session) (map[int]bool, error) {
+	query := `
+    MATCH (m:` + echo_neo4j.LabelSchemaMigration + `)
+    RETURN m.version AS version
+    `
+	result, err := session.Run(query, nil)
+	if err != nil {
+		logger.Error("Failed to read applied migrations", zap.Error(err))
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	done := make(map[int]bool)
+	for result.Next() {
+		if version, ok := result.Record().Values[0].(int64); ok {
+			done[int(version)] = true
+		}
+	}
+	return done, nil
+}
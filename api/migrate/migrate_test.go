@@ -0,0 +1,89 @@
+package migrate_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/migrate"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestApplyOnlyRunsPendingMigrations(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	// The graph keeps the versions recorded so far and the statements migrations ran
+	var recorded []int64
+	var statements []string
+	tx := &mock.MockTransaction{}
+	tx.On("Run", testify_mock.MatchedBy(func(query string) bool { return strings.Contains(query, "MERGE (m:SchemaMigration") }), testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) {
+			recorded = append(recorded, int64(args.Get(1).(map[string]any)["version"].(int)))
+		}).Return(nil, nil)
+	tx.On("Run", testify_mock.MatchedBy(func(query string) bool { return strings.Contains(query, "CREATE CONSTRAINT") }), testify_mock.Anything).Return(nil, nil)
+	tx.On("Run", testify_mock.Anything, testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) {
+			statements = append(statements, args.String(0))
+		}).Return(nil, nil)
+	session := &mock.MockTxSession{Tx: tx}
+	session.On("Close").Return(nil)
+	versions := &mock.MockResult{}
+	session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).
+		Run(func(testify_mock.Arguments) {
+			versions.ExpectedCalls = nil
+			for _, version := range recorded {
+				versions.On("Next").Return(true).Once()
+				versions.On("Record").Return(&neo4j.Record{Values: []any{version}}).Once()
+			}
+			versions.On("Next").Return(false)
+		}).Return(versions, nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+
+	runs := map[int]int{}
+	up := func(version int) func(neo4j.Transaction) error {
+		return func(neo4j.Transaction) error {
+			runs[version]++
+			return nil
+		}
+	}
+	migrations := []migrate.Migration{
+		{Version: 2, Description: "Backfill status", Up: up(2)},
+		{Version: 1, Description: "Rename label", Cypher: []string{"MATCH (n:OLD) SET n:NEW REMOVE n:OLD"}},
+	}
+
+	applied, err := migrate.Apply(ctx, driver, migrations)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, applied)
+
+	applied, err = migrate.Apply(ctx, driver, migrations)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	migrations = append(migrations, migrate.Migration{Version: 3, Description: "Add index", Up: up(3)})
+	applied, err = migrate.Apply(ctx, driver, migrations)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, applied)
+
+	assert.Equal(t, map[int]int{2: 1, 3: 1}, runs)
+	assert.Equal(t, []string{"MATCH (n:OLD) SET n:NEW REMOVE n:OLD"}, statements)
+	assert.Equal(t, []int64{1, 2, 3}, recorded)
+
+	t.Run("DuplicateVersion", func(t *testing.T) {
+		_, err := migrate.Apply(ctx, driver, []migrate.Migration{
+			{Version: 4, Description: "One", Up: up(4)},
+			{Version: 4, Description: "Other", Up: up(4)},
+		})
+
+		assert.EqualError(t, err, "migration version 4 is used twice")
+		assert.Zero(t, runs[4])
+	})
+}
@@ -1,7 +1,10 @@
 // api/model/access.go
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Role struct {
 	ID             string            `json:"id"`
@@ -13,6 +16,36 @@ type Role struct {
 	Attributes     map[string]string `json:"attributes,omitempty"`    // For ABAC-specific attributes
 	CreatedAt      time.Time         `json:"created_at"`
 	UpdatedAt      time.Time         `json:"updated_at"`
+	CreatedBy      string            `json:"created_by,omitempty"`
+	UpdatedBy      string            `json:"updated_by,omitempty"`
+}
+
+// RoleAssignment is a bulk change of the users holding a role
+type RoleAssignment struct {
+	RoleID  string   `json:"role_id"`
+	UserIDs []string `json:"user_ids" binding:"required"`
+}
+
+// RolePermissionsChange lists the permissions to grant a role and to revoke from it
+type RolePermissionsChange struct {
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+// RolePermissions lists the permissions a role holds
+type RolePermissions struct {
+	RoleID        string   `json:"role_id"`
+	PermissionIDs []string `json:"permission_ids"`
+}
+
+type RoleUsageAnalysis struct {
+	RoleID          string
+	RoleName        string
+	UserCount       int
+	GroupCount      int
+	PermissionCount int
+	CreatedAt       time.Time
+	LastUpdatedAt   time.Time
 }
 
 type Group struct {
@@ -20,11 +53,14 @@ type Group struct {
 	Name           string            `json:"name"`
 	Description    string            `json:"description"`
 	OrganizationID string            `json:"organization_id"`
-	DepartmentID   string            `json:"department_id,omitempty"` // Optional, for department-specific groups
-	Roles          []string          `json:"roles,omitempty"`         // IDs of associated roles
-	Attributes     map[string]string `json:"attributes,omitempty"`    // For ABAC-specific attributes
+	DepartmentID   string            `json:"department_id,omitempty"`   // Optional, for department-specific groups
+	ParentGroupID  string            `json:"parent_group_id,omitempty"` // Optional, for groups nested in another group
+	Roles          []string          `json:"roles,omitempty"`           // IDs of associated roles
+	Attributes     map[string]string `json:"attributes,omitempty"`      // For ABAC-specific attributes
 	CreatedAt      time.Time         `json:"created_at"`
 	UpdatedAt      time.Time         `json:"updated_at"`
+	CreatedBy      string            `json:"created_by,omitempty"`
+	UpdatedBy      string            `json:"updated_by,omitempty"`
 }
 
 type Permission struct {
@@ -32,6 +68,15 @@ type Permission struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Action      string `json:"action"` // e.g., "read", "write", "delete"
+	CreatedBy   string `json:"created_by,omitempty"`
+	UpdatedBy   string `json:"updated_by,omitempty"`
+}
+
+type PermissionSearchCriteria struct {
+	Name   string `json:"name,omitempty"`   // Case-insensitive substring match
+	Action string `json:"action,omitempty"` // Case-insensitive substring match
+	Limit  int    `json:"limit,omitempty"`
+	Offset int    `json:"offset,omitempty"`
 }
 
 type DynamicAttribute struct {
@@ -41,3 +86,136 @@ type DynamicAttribute struct {
 	Value     interface{} `json:"value"`
 	UpdatedAt time.Time   `json:"updated_at"`
 }
+
+// AccessRequest asks whether a subject may perform an action on a resource
+type AccessRequest struct {
+	SubjectID  string                 `json:"subject_id"`
+	ResourceID string                 `json:"resource_id"`
+	Action     string                 `json:"action"`
+	Context    map[string]interface{} `json:"context,omitempty"` // Environment attributes such as time or client IP
+	// Explain asks for the evaluation trace in the decision. It is off by default as tracing costs time.
+	Explain bool `json:"-"`
+}
+
+// AccessDecision is the outcome of evaluating an AccessRequest against the active policies
+type AccessDecision struct {
+	Allowed   bool      `json:"allowed"`
+	Effect    string    `json:"effect"`              // "ALLOW" or "DENY"
+	PolicyID  string    `json:"policy_id,omitempty"` // Policy that determined the effect, if any
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+	// Ancestor resource the deciding policy matched, when the resource inherited its access
+	InheritedFrom string `json:"inherited_from,omitempty"`
+	// Obligations of every matched policy, highest priority first
+	Obligations []Obligation `json:"obligations,omitempty"`
+	// Trace of the evaluation, only set when the request asked for an explanation
+	Trace *DecisionTrace `json:"trace,omitempty"`
+}
+
+// DecisionTrace explains an AccessDecision: every candidate policy considered, in evaluation order,
+// and whether it matched the request
+type DecisionTrace struct {
+	Policies []PolicyTrace `json:"policies"`
+}
+
+// PolicyTrace records how one candidate policy fared against the request. A policy that did not match
+// carries the reason, along with the failing condition when its conditions ruled it out.
+type PolicyTrace struct {
+	PolicyID        string     `json:"policy_id"`
+	PolicyName      string     `json:"policy_name"`
+	Effect          string     `json:"effect"`
+	Priority        int        `json:"priority"`
+	Matched         bool       `json:"matched"`
+	Deciding        bool       `json:"deciding"`                 // The policy determined the final effect
+	InheritedFrom   string     `json:"inherited_from,omitempty"` // Ancestor resource the policy was evaluated against
+	Reason          string     `json:"reason,omitempty"`
+	FailedCondition *Condition `json:"failed_condition,omitempty"`
+}
+
+// AccessReviewReport lists, for periodic access certification, what each user of an organization
+// holds and which of its resources they can act on. Users holds one page of the organization's
+// Total users, starting at Offset.
+type AccessReviewReport struct {
+	OrganizationID string              `json:"organization_id"`
+	GeneratedAt    time.Time           `json:"generated_at"`
+	Total          int64               `json:"total"`
+	Offset         int                 `json:"offset"`
+	Users          []AccessReviewEntry `json:"users"`
+}
+
+// AccessReviewEntry is one user's part of an access review
+type AccessReviewEntry struct {
+	UserID        string           `json:"user_id"`
+	Username      string           `json:"username"`
+	Name          string           `json:"name"`
+	Status        string           `json:"status"`
+	RoleIDs       []string         `json:"role_ids"`       // Direct and inherited roles
+	GroupIDs      []string         `json:"group_ids"`      // Direct and enclosing groups
+	PermissionIDs []string         `json:"permission_ids"` // Permissions granted by RoleIDs
+	Resources     []ResourceAccess `json:"resources"`      // Resources the policy decision point allows any action on
+}
+
+// ResourceAccess lists the actions a user is allowed on a resource
+type ResourceAccess struct {
+	ResourceID   string   `json:"resource_id"`
+	ResourceName string   `json:"resource_name"`
+	Actions      []string `json:"actions"`
+}
+
+// SoDRule is a separation of duties rule: no user may hold RoleA and RoleB at the same time, whether
+// directly or through groups
+type SoDRule struct {
+	ID          string    `json:"id"`
+	RoleA       string    `json:"role_a"`
+	RoleB       string    `json:"role_b"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	UpdatedBy   string    `json:"updated_by,omitempty"`
+}
+
+// SoDViolation reports a user holding both roles of a separation of duties rule
+type SoDViolation struct {
+	UserID      string `json:"user_id"`
+	RuleID      string `json:"rule_id"`
+	RoleA       string `json:"role_a"`
+	RoleB       string `json:"role_b"`
+	Description string `json:"description,omitempty"`
+}
+
+// PolicyBundleFormat is the version of the PolicyBundle layout, raised whenever it changes
+const PolicyBundleFormat = 1
+
+// PolicyBundle holds everything the access evaluation of an organization needs, so it can run without
+// the database: the active policies along with the organization's users and resources
+type PolicyBundle struct {
+	Format         int              `json:"format"` // PolicyBundleFormat the bundle was written in
+	OrganizationID string           `json:"organization_id"`
+	GeneratedAt    time.Time        `json:"generated_at"`
+	Policies       []*Policy        `json:"policies"`
+	Subjects       []BundledSubject `json:"subjects"`
+	Resources      []*Resource      `json:"resources"`
+}
+
+// BundledSubject is the part of a user the access evaluation reads, with the roles and groups the user
+// holds directly or through nested groups. Contact details are left out of bundles.
+type BundledSubject struct {
+	ID             string            `json:"id"`
+	UserType       string            `json:"user_type"`
+	OrganizationID string            `json:"organization_id,omitempty"`
+	DepartmentID   string            `json:"department_id,omitempty"`
+	RoleIDs        []string          `json:"role_ids"`
+	GroupIDs       []string          `json:"group_ids"`
+	Attributes     map[string]string `json:"attributes"`
+	Status         string            `json:"status"`
+	Clearance      string            `json:"clearance,omitempty"`
+}
+
+// SignedPolicyBundle is a PolicyBundle as exported: the bundle's JSON along with its HMAC-SHA256
+// signature, hex encoded
+type SignedPolicyBundle struct {
+	Bundle    json.RawMessage `json:"bundle"`
+	Algorithm string          `json:"algorithm"` // Always "HS256"
+	Signature string          `json:"signature"`
+}
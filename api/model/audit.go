@@ -0,0 +1,11 @@
+// api/model/audit.go
+package model
+
+import "time"
+
+// AuditChainHead is the latest audit log written to the hash chain, which the next one links to
+type AuditChainHead struct {
+	Sequence  int64     `json:"sequence"`
+	Hash      string    `json:"hash"`
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,20 @@
+// api/model/change.go
+package model
+
+import "time"
+
+// ChangeEvent is one entry of the change feed: an entity that was created, updated, deleted or moved
+type ChangeEvent struct {
+	Sequence   int64     `json:"sequence"`    // Position in the feed; later changes have higher ones
+	EntityType string    `json:"entity_type"` // Such as "policy" or "resource"
+	EntityID   string    `json:"entity_id"`
+	Action     string    `json:"action"` // "created", "updated", "deleted" or "moved"
+	Timestamp  time.Time `json:"timestamp"`
+	Actor      string    `json:"actor,omitempty"` // User whose request made the change
+}
+
+// ChangeFeedPage is the changes following a change feed cursor, oldest first
+type ChangeFeedPage struct {
+	Items      []*ChangeEvent `json:"items"`
+	NextCursor string         `json:"next_cursor"` // Pass as since to read the changes after these
+}
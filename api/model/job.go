@@ -0,0 +1,39 @@
+// api/model/job.go
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Statuses of a background job. A job is queued until a worker picks it up and running until it ends
+// in one of the terminal statuses.
+const (
+	JobStatusQueued  = "queued"
+	JobStatusRunning = "running"
+	JobStatusSuccess = "success" // Every item succeeded
+	JobStatusPartial = "partial" // The job completed, but some of its items failed
+	JobStatusFailure = "failure" // The job itself failed
+)
+
+// Job is a long-running operation run in the background, which clients poll for its progress and,
+// once it has ended, its result
+type Job struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"` // Such as "policy_import" or "access_review"
+	Status     string          `json:"status"`
+	Total      int             `json:"total"`     // Items the job works through; 0 until known
+	Processed  int             `json:"processed"` // Items done so far, failed ones included
+	Failed     int             `json:"failed"`
+	Errors     []string        `json:"errors,omitempty"` // Why items failed, or why the job did
+	Result     json.RawMessage `json:"result,omitempty"`
+	CreatedBy  string          `json:"created_by,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job has ended
+func (j *Job) Finished() bool {
+	return j.Status == JobStatusSuccess || j.Status == JobStatusPartial || j.Status == JobStatusFailure
+}
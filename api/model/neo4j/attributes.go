@@ -33,6 +33,12 @@ const (
 	// AttrUpdatedAt represents the last update timestamp of a node
 	AttrUpdatedAt = "updatedAt"
 
+	// AttrCreatedBy represents the ID of the user who created a node
+	AttrCreatedBy = "createdBy"
+
+	// AttrUpdatedBy represents the ID of the user who last updated a node
+	AttrUpdatedBy = "updatedBy"
+
 	// AttrActive represents whether a node is active
 	AttrActive = "active"
 
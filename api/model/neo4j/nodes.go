@@ -21,9 +21,21 @@ const (
 	// LabelResource represents a resource that can be accessed in the system
 	LabelResource = "RESOURCE"
 
+	// LabelResourceVersion represents a snapshot of a resource's properties as they were at one version
+	LabelResourceVersion = "RESOURCE_VERSION"
+
 	// LabelPolicy represents an access control policy
 	LabelPolicy = "POLICY"
 
+	// LabelPolicySubject represents one subject of a policy, such as a role, owned by that policy
+	LabelPolicySubject = "POLICY_SUBJECT"
+
+	// LabelCondition represents one top-level condition of a policy, owned by that policy
+	LabelCondition = "CONDITION"
+
+	// LabelPolicyTemplate represents a policy with placeholder variables that concrete policies are instantiated from
+	LabelPolicyTemplate = "POLICY_TEMPLATE"
+
 	// LabelResourceType represents a type of resource
 	LabelResourceType = "RESOURCE_TYPE"
 
@@ -41,4 +53,16 @@ const (
 
 	// LabelAuditLog represents an audit log entry
 	LabelAuditLog = "AUDIT_LOG"
+
+	// LabelSoDRule represents a separation of duties rule forbidding one user two conflicting roles
+	LabelSoDRule = "SoDRule"
+
+	// LabelChangeEvent represents one create, update or delete of an entity recorded in the change feed
+	LabelChangeEvent = "ChangeEvent"
+
+	// LabelChangeFeed holds the last sequence number handed to a ChangeEvent
+	LabelChangeFeed = "ChangeFeed"
+
+	// LabelSchemaMigration records a graph migration that has been applied
+	LabelSchemaMigration = "SchemaMigration"
 )
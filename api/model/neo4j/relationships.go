@@ -2,19 +2,62 @@
 package echo_neo4j
 
 // Relationship Types
+//
+// The tenancy graph is linked as follows, and queries must name these relationships through the
+// constants rather than spelling them out:
+//
+//	(:ORGANIZATION)-[:PARENT_OF]->(:ORGANIZATION)
+//	(:DEPARTMENT)-[:PART_OF]->(:ORGANIZATION)
+//	(:DEPARTMENT)-[:CHILD_OF]->(:DEPARTMENT)
+//	(:USER)-[:WORKS_FOR]->(:ORGANIZATION)
+//	(:USER)-[:MEMBER_OF]->(:DEPARTMENT)
+//	(:USER)-[:HAS_ROLE]->(:ROLE)
+//	(:USER)-[:BELONGS_TO_GROUP]->(:GROUP)
+//	(:GROUP)-[:SUBGROUP_OF]->(:GROUP)
+//	(:ROLE|GROUP)-[:PART_OF]->(:ORGANIZATION|DEPARTMENT)
+//	(:RESOURCE)-[:BELONGS_TO]->(:ORGANIZATION)
+//	(:RESOURCE)-[:ASSIGNED_TO]->(:DEPARTMENT)
+//	(:RESOURCE)-[:OWNED_BY]->(:USER)
+//	(:RESOURCE)-[:CHILD_OF]->(:RESOURCE)
 const (
-	// RelPartOf represents the relationship between a department and its organization
+	// RelPartOf represents the relationship between a department, role or group and its organization,
+	// and between a role or group and its department
 	RelPartOf = "PART_OF"
 
-	// RelChildOf represents the relationship between a department and its parent department
+	// RelChildOf represents the relationship between a department or resource and its parent
 	RelChildOf = "CHILD_OF"
 
+	// RelBelongsTo represents the relationship between a resource and its organization
+	RelBelongsTo = "BELONGS_TO"
+
+	// RelParentOf represents the relationship between an organization and its sub-organizations
+	RelParentOf = "PARENT_OF"
+
+	// RelAssignedTo represents the relationship between a resource and its department
+	RelAssignedTo = "ASSIGNED_TO"
+
+	// RelOwnedBy represents the relationship between a resource and the user owning it
+	RelOwnedBy = "OWNED_BY"
+
+	// RelHasType represents the relationship between a resource and its resource type
+	RelHasType = "HAS_TYPE"
+
+	// RelHasVersion represents the relationship between a resource and its version snapshots
+	RelHasVersion = "HAS_VERSION"
+
+	// RelRelatedTo represents the relationship between a resource and the resources related to it
+	RelRelatedTo = "RELATED_TO"
+
 	// RelWorksFor represents the relationship between a user and their organization
 	RelWorksFor = "WORKS_FOR"
 
 	// RelMemberOf represents the relationship between a user and their department
 	RelMemberOf = "MEMBER_OF"
 
+	// RelAdministers represents the relationship between a scoped administrator and the organization or
+	// department they manage
+	RelAdministers = "ADMINISTERS"
+
 	// RelHasRole represents the relationship between a user and their assigned roles
 	RelHasRole = "HAS_ROLE"
 
@@ -27,17 +70,28 @@ const (
 	// RelCanAccess represents the relationship between a role and the resources it can access
 	RelCanAccess = "CAN_ACCESS"
 
-	// RelAppliesTo represents the relationship between a policy and the resources it applies to
+	// RelAppliesTo represents the relationship between a policy and the resource types, attribute groups
+	// and subjects it applies to
 	RelAppliesTo = "APPLIES_TO"
 
+	// RelHasCondition represents the relationship between a policy and its conditions
 	RelHasCondition = "HAS_CONDITION"
 
+	// RelInstantiatedFrom represents the relationship between a policy and the template it was instantiated from
+	RelInstantiatedFrom = "INSTANTIATED_FROM"
+
 	// RelHasAttribute represents the relationship between a node and its attributes
 	RelHasAttribute = "HAS_ATTRIBUTE"
 
 	// RelBelongsToGroup represents the relationship between a user and their groups
 	RelBelongsToGroup = "BELONGS_TO_GROUP"
 
+	// RelSubgroupOf represents the relationship between a group and its parent group
+	RelSubgroupOf = "SUBGROUP_OF"
+
+	// RelInGroup represents the relationship between a resource and its attribute group
+	RelInGroup = "IN_GROUP"
+
 	// RelCreatedBy represents the relationship between a node and its creator
 	RelCreatedBy = "CREATED_BY"
 
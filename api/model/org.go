@@ -3,16 +3,33 @@ package model
 
 import "time"
 
+// Organization statuses
+const (
+	OrgStatusActive    = "active"
+	OrgStatusSuspended = "suspended"
+	OrgStatusArchived  = "archived"
+)
+
 type Organization struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description,omitempty"`
+	Status       string            `json:"status"` // One of the OrgStatus values; active when left empty
+	ContactEmail string            `json:"contact_email,omitempty"`
+	ContactPhone string            `json:"contact_phone,omitempty"`
+	ExternalIDs  map[string]string `json:"external_ids,omitempty"` // The organization's identifiers in other systems, keyed by system
+	ParentID     string            `json:"parent_id,omitempty"`
+	ChildIDs     []string          `json:"child_ids,omitempty"` // Direct sub-organizations; read-only
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	CreatedBy    string            `json:"created_by,omitempty"`
+	UpdatedBy    string            `json:"updated_by,omitempty"`
 }
 
 type OrganizationSearchCriteria struct {
 	Name      string     `json:"name,omitempty"`
 	ID        string     `json:"id,omitempty"`
+	Status    string     `json:"status,omitempty"`
 	FromDate  *time.Time `json:"from_date,omitempty"`
 	ToDate    *time.Time `json:"to_date,omitempty"`
 	Limit     int        `json:"limit,omitempty"`
@@ -21,6 +38,32 @@ type OrganizationSearchCriteria struct {
 	SortOrder string     `json:"sort_order,omitempty"`
 }
 
+// Modes for deleting an organization that still has dependents
+const (
+	OrgDeleteModeRestrict = "restrict" // Refuse deletion while dependents exist
+	OrgDeleteModeCascade  = "cascade"  // Delete all dependents along with the organization
+	OrgDeleteModeReassign = "reassign" // Move all dependents to another organization
+)
+
+// OrganizationDeletionSummary reports the entities affected by an organization deletion
+type OrganizationDeletionSummary struct {
+	OrganizationID       string `json:"organization_id"`
+	Mode                 string `json:"mode"`
+	TargetOrganizationID string `json:"target_organization_id,omitempty"` // Set when mode is reassign
+	Departments          int64  `json:"departments"`
+	Users                int64  `json:"users"`
+	Roles                int64  `json:"roles"`
+	Groups               int64  `json:"groups"`
+	Resources            int64  `json:"resources"`
+	SubOrganizations     int64  `json:"sub_organizations"` // Moved under the deleted organization's parent unless restricted
+	DeletedBy            string `json:"deleted_by"`
+}
+
+// Total returns the number of dependents affected by the deletion
+func (s OrganizationDeletionSummary) Total() int64 {
+	return s.Departments + s.Users + s.Roles + s.Groups + s.Resources + s.SubOrganizations
+}
+
 type Department struct {
 	ID             string    `json:"id"`
 	Name           string    `json:"name"`
@@ -28,6 +71,26 @@ type Department struct {
 	ParentID       string    `json:"parent_id,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	CreatedBy      string    `json:"created_by,omitempty"`
+	UpdatedBy      string    `json:"updated_by,omitempty"`
+}
+
+// Modes for deleting a department that still has child departments or members
+const (
+	DeptDeleteModeRestrict = "restrict" // Refuse deletion while child departments or members exist
+	DeptDeleteModeCascade  = "cascade"  // Move child departments up a level and leave members without a department
+	DeptDeleteModeReassign = "reassign" // Move child departments up a level and members to another department
+)
+
+// DepartmentDeletionSummary reports the entities affected by a department deletion
+type DepartmentDeletionSummary struct {
+	DepartmentID       string   `json:"department_id"`
+	Mode               string   `json:"mode"`
+	TargetDepartmentID string   `json:"target_department_id,omitempty"` // Set when mode is reassign
+	NewParentID        string   `json:"new_parent_id,omitempty"`        // Where child departments moved; empty when they became top-level
+	ChildDepartmentIDs []string `json:"child_department_ids"`
+	Users              int64    `json:"users"`
+	DeletedBy          string   `json:"deleted_by"`
 }
 
 type DepartmentSearchCriteria struct {
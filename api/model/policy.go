@@ -5,25 +5,59 @@ import (
 	"time"
 )
 
+// Policy status values. A policy moves from draft to pending approval when submitted, and from there
+// to active or rejected when reviewed; a rejected policy may be submitted again. Only active policies
+// are evaluated.
+const (
+	PolicyStatusDraft           = "draft"
+	PolicyStatusPendingApproval = "pending_approval"
+	PolicyStatusActive          = "active"
+	PolicyStatusRejected        = "rejected"
+)
+
 type Policy struct {
-	ID                string      `json:"id"`
-	Name              string      `json:"name"`
-	Description       string      `json:"description"`
-	Effect            string      `json:"effect"` // "allow" or "deny"
-	Subjects          []Subject   `json:"subjects"`
-	ResourceTypes     []string    `json:"resource_types"`
-	AttributeGroups   []string    `json:"attribute_groups"`
-	Actions           []string    `json:"actions"`
-	Conditions        []Condition `json:"conditions"`
-	DynamicAttributes []string    `json:"dynamic_attributes,omitempty"`
-	Priority          int         `json:"priority"`
-	Version           int         `json:"version"`
-	ParentPolicyID    string      `json:"parent_policy_id,omitempty"`
-	CreatedAt         time.Time   `json:"created_at"`
-	UpdatedAt         time.Time   `json:"updated_at"`
-	Active            bool        `json:"active"`
-	ActivationDate    *time.Time  `json:"activation_date,omitempty"`
-	DeactivationDate  *time.Time  `json:"deactivation_date,omitempty"`
+	ID                string       `json:"id"`
+	Name              string       `json:"name"`
+	Description       string       `json:"description"`
+	Effect            string       `json:"effect"` // "allow" or "deny"
+	Subjects          []Subject    `json:"subjects"`
+	ResourceTypes     []string     `json:"resource_types"`
+	AttributeGroups   []string     `json:"attribute_groups"`
+	Actions           []string     `json:"actions"`
+	Conditions        []Condition  `json:"conditions"`
+	DynamicAttributes []string     `json:"dynamic_attributes,omitempty"`
+	Obligations       []Obligation `json:"obligations,omitempty"` // Requirements passed on to the caller whenever the policy matches
+	Priority          int          `json:"priority"`
+	Version           int          `json:"version"`
+	ParentPolicyID    string       `json:"parent_policy_id,omitempty"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+	CreatedBy         string       `json:"created_by,omitempty"`
+	UpdatedBy         string       `json:"updated_by,omitempty"`
+	Active            bool         `json:"active"`
+	ActivationDate    *time.Time   `json:"activation_date,omitempty"`
+	DeactivationDate  *time.Time   `json:"deactivation_date,omitempty"`
+	TemplateID        string       `json:"template_id,omitempty"`     // Set when instantiated from a PolicyTemplate
+	OrganizationID    string       `json:"organization_id,omitempty"` // Owning organization; empty for policies shared by all organizations
+	Status            string       `json:"status"`                    // Approval status; policies stored before approvals existed are active
+	SubmittedBy       string       `json:"submitted_by,omitempty"`    // Who last submitted the policy for approval
+	SubmittedAt       *time.Time   `json:"submitted_at,omitempty"`
+	ReviewedBy        string       `json:"reviewed_by,omitempty"` // Who last approved or rejected the policy
+	ReviewedAt        *time.Time   `json:"reviewed_at,omitempty"`
+	ReviewComment     string       `json:"review_comment,omitempty"`
+}
+
+// PolicyReviewRequest is the optional body of a policy submission, approval or rejection
+type PolicyReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// Obligation is a requirement a matched policy places on whoever enforces the access decision, such
+// as "require_mfa" or "mask_field" with {"field": "ssn"}. The decision point only reports obligations;
+// carrying them out is up to the caller.
+type Obligation struct {
+	ID     string                 `json:"id"`
+	Params map[string]interface{} `json:"params,omitempty"`
 }
 
 type Subject struct {
@@ -72,6 +106,50 @@ type PartOf struct {
 	OrganizationID string `json:"organization_id"`
 }
 
+// PolicyTemplate is a policy whose strings may contain ${name} placeholders, such as a subject
+// attribute of "${department}" or a condition value of "${resourceId}". Concrete policies are
+// instantiated from it by supplying a value for every placeholder.
+type PolicyTemplate struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Policy      Policy    `json:"policy"`
+	Variables   []string  `json:"variables"` // Placeholder names found in Policy, derived on create
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// InstantiatePolicyRequest carries the values substituted for a template's placeholders
+type InstantiatePolicyRequest struct {
+	Variables map[string]string `json:"variables"`
+}
+
+// PolicyPage is one page of a cursor-paginated policy listing
+type PolicyPage struct {
+	Items      []*Policy `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// BatchGetRequest names the entities a batch get fetches
+type BatchGetRequest struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+// PolicyBatch is the result of a batch get of policies
+type PolicyBatch struct {
+	Items   []*Policy `json:"items"`   // In the order they were asked for
+	Missing []string  `json:"missing"` // IDs with no policy the caller may see
+}
+
+// PolicyChange is a policy that was created, updated or deleted, as pushed to policy stream clients
+type PolicyChange struct {
+	PolicyID  string    `json:"policy_id"`
+	Action    string    `json:"action"` // "created", "updated" or "deleted"
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor,omitempty"` // User whose request made the change
+}
+
 type PolicySearchCriteria struct {
 	Name        string
 	Effect      string
@@ -81,6 +159,25 @@ type PolicySearchCriteria struct {
 	FromDate    time.Time
 	ToDate      time.Time
 	Limit       int
+	Offset      int
+}
+
+// ResolvedSubject is a user a policy names as a subject, with the organization, department, roles and
+// groups the graph links them to
+type ResolvedSubject struct {
+	Subject      Subject       `json:"subject"`
+	User         *User         `json:"user"`
+	Organization *Organization `json:"organization,omitempty"`
+	Department   *Department   `json:"department,omitempty"`
+	Roles        []*Role       `json:"roles"`
+	Groups       []*Group      `json:"groups"`
+}
+
+// PolicyWithSubjects is a policy along with its user subjects resolved through the graph. Subjects
+// naming users that no longer exist are left out of ResolvedSubjects.
+type PolicyWithSubjects struct {
+	*Policy
+	ResolvedSubjects []ResolvedSubject `json:"resolved_subjects"`
 }
 
 type PolicyUsageAnalysis struct {
@@ -92,3 +189,53 @@ type PolicyUsageAnalysis struct {
 	CreatedAt      time.Time
 	LastUpdatedAt  time.Time
 }
+
+// Kinds of problems the policy set analysis reports
+const (
+	PolicyFindingUnreachable = "unreachable"            // Another policy always takes precedence
+	PolicyFindingDuplicate   = "duplicate"              // Policies that differ only in ID and name
+	PolicyFindingAlwaysTrue  = "always_true_condition"  // A condition that never filters anything
+	PolicyFindingAlwaysFalse = "always_false_condition" // A condition that keeps the policy from ever applying
+)
+
+// PolicyFinding is one problem found in the active policy set, naming every policy involved
+type PolicyFinding struct {
+	Type      string   `json:"type"`
+	PolicyIDs []string `json:"policy_ids"`
+	Message   string   `json:"message"`
+}
+
+// PolicySetAnalysis lists the problems found in the active policy set
+type PolicySetAnalysis struct {
+	AnalyzedAt  time.Time       `json:"analyzed_at"`
+	PolicyCount int             `json:"policy_count"`
+	Findings    []PolicyFinding `json:"findings"`
+}
+
+// PolicyPriorityCount is how many policies share a priority
+type PolicyPriorityCount struct {
+	Priority int `json:"priority"`
+	Count    int `json:"count"`
+}
+
+// PolicyImportResult is the result of a policy import job
+type PolicyImportResult struct {
+	PolicyIDs []string `json:"policy_ids"` // The policies created, in the order they were imported
+}
+
+// CoverageReport describes the policy set governing an organization, its own policies and those shared
+// by all organizations: how many allow and deny, how many are not evaluated, how priorities spread,
+// and the organization's resources and users no evaluated policy applies to
+type CoverageReport struct {
+	OrganizationID       string                `json:"organization_id"`
+	GeneratedAt          time.Time             `json:"generated_at"`
+	PolicyCount          int                   `json:"policy_count"`
+	AllowCount           int                   `json:"allow_count"`
+	DenyCount            int                   `json:"deny_count"`
+	InactiveCount        int                   `json:"inactive_count"`        // Inactive, unapproved or outside their activation window
+	PriorityDistribution []PolicyPriorityCount `json:"priority_distribution"` // Highest priority first
+	ResourceCount        int                   `json:"resource_count"`
+	SubjectCount         int                   `json:"subject_count"`
+	UncoveredResourceIDs []string              `json:"uncovered_resource_ids"`
+	UncoveredSubjectIDs  []string              `json:"uncovered_subject_ids"`
+}
@@ -69,13 +69,119 @@ type ResourceType struct {
 }
 
 type AttributeGroup struct {
-	ID         string            `json:"id"`
-	Name       string            `json:"name"`
-	Attributes map[string]string `json:"attributes"`
-	CreatedBy  string            `json:"created_by,omitempty"`
-	UpdatedBy  string            `json:"updated_by,omitempty"`
-	CreatedAt  time.Time         `json:"created_at,omitempty"`
-	UpdatedAt  time.Time         `json:"updated_at,omitempty"`
+	ID         string                         `json:"id"`
+	Name       string                         `json:"name"`
+	Attributes map[string]string              `json:"attributes"`
+	Schema     map[string]AttributeDefinition `json:"schema,omitempty"` // Attribute key -> definition enforced on members
+	CreatedBy  string                         `json:"created_by,omitempty"`
+	UpdatedBy  string                         `json:"updated_by,omitempty"`
+	CreatedAt  time.Time                      `json:"created_at,omitempty"`
+	UpdatedAt  time.Time                      `json:"updated_at,omitempty"`
+}
+
+// AttributeDefinition describes a single attribute allowed by an AttributeGroup schema
+type AttributeDefinition struct {
+	Type          string        `json:"type"`                     // "string", "number", "boolean", "array" or "object"
+	Required      bool          `json:"required,omitempty"`       // Whether the attribute must be present
+	AllowedValues []interface{} `json:"allowed_values,omitempty"` // Optional enumeration of permitted values
+}
+
+// Supported attribute definition types
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeArray   = "array"
+	AttributeTypeObject  = "object"
+)
+
+// ResourcePage is one page of a cursor-paginated resource listing
+type ResourcePage struct {
+	Items      []*Resource `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// ResourceBatch is the result of a batch get of resources
+type ResourceBatch struct {
+	Items   []*Resource `json:"items"`   // In the order they were asked for
+	Missing []string    `json:"missing"` // IDs with no resource the caller may see
+}
+
+// OwnershipTransferRequest names the user a resource is handed over to
+type OwnershipTransferRequest struct {
+	NewOwnerID string `json:"new_owner_id" binding:"required"`
+}
+
+// ResourceTags carries the tags added to or removed from a resource, and its tags afterwards
+type ResourceTags struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+// BulkTagRequest asks for tags to be added to every resource matching Criteria
+type BulkTagRequest struct {
+	Criteria ResourceSearchCriteria `json:"criteria"`
+	Tags     []string               `json:"tags" binding:"required"`
+}
+
+// BulkTagResult reports how many resources gained at least one tag
+type BulkTagResult struct {
+	Affected int `json:"affected"`
+}
+
+// TagCount is a tag in use on resources along with the number of resources carrying it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// Kinds of IntegrityIssue
+const (
+	IntegrityMissingRelationship = "missing_relationship" // The property names a node the resource has no relationship to
+	IntegrityMismatch            = "mismatch"             // The relationships point elsewhere than the property
+)
+
+// IntegrityIssue is a reference a resource stores both as a property and as a relationship where the
+// two disagree
+type IntegrityIssue struct {
+	ResourceID   string   `json:"resource_id"`
+	Property     string   `json:"property"`     // e.g. organizationID
+	Relationship string   `json:"relationship"` // e.g. BELONGS_TO
+	Kind         string   `json:"kind"`
+	Stored       string   `json:"stored"` // The property value
+	Linked       []string `json:"linked"` // IDs of the nodes the relationships point at
+	Repaired     bool     `json:"repaired"`
+}
+
+// IntegrityReport lists the resources whose reference properties disagree with their relationships
+type IntegrityReport struct {
+	CheckedAt        time.Time        `json:"checked_at"`
+	ResourcesChecked int              `json:"resources_checked"`
+	Issues           []IntegrityIssue `json:"issues"`
+	Repaired         int              `json:"repaired"`
+}
+
+// FieldChange is one field that differs between two states of an entity, named by its JSON name
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ResourceDiff lists the fields that changed from one version of a resource to another
+type ResourceDiff struct {
+	ResourceID  string        `json:"resource_id"`
+	FromVersion int           `json:"from_version"`
+	ToVersion   int           `json:"to_version"`
+	Changes     []FieldChange `json:"changes"`
+}
+
+type AttributeGroupSearchCriteria struct {
+	Name      string `json:"name,omitempty"`       // Case-insensitive substring match
+	CreatedBy string `json:"created_by,omitempty"` // Exact match on creator ID
+	Limit     int    `json:"limit,omitempty"`
+	Offset    int    `json:"offset,omitempty"`
+	SortBy    string `json:"sort_by,omitempty"` // "name", "createdAt", "updatedAt" or "createdBy"
+	SortOrder string `json:"sort_order,omitempty"`
 }
 
 type ResourceSearchCriteria struct {
@@ -84,6 +190,7 @@ type ResourceSearchCriteria struct {
 	Type           string                 `json:"type,omitempty"`
 	OrganizationID string                 `json:"organization_id,omitempty"`
 	DepartmentID   string                 `json:"department_id,omitempty"`
+	DepartmentIDs  []string               `json:"-"` // Set by the service to hold a scoped admin to their departments
 	OwnerID        string                 `json:"owner_id,omitempty"`
 	Status         string                 `json:"status,omitempty"`
 	Sensitivity    string                 `json:"sensitivity,omitempty"`
@@ -0,0 +1,18 @@
+// api/model/search.go
+package model
+
+// SearchHit is one entity a global search found
+type SearchHit struct {
+	Type   string      `json:"type"` // "user", "resource" or "policy"
+	ID     string      `json:"id"`
+	Name   string      `json:"name"`
+	Score  int         `json:"score"`  // Higher for closer matches of the name
+	Entity interface{} `json:"entity"` // The User, Resource or Policy itself
+}
+
+// SearchResults is what a global search found across entity types, best matches first. Types whose
+// search failed are named in Warnings, and the results hold what the other types found.
+type SearchResults struct {
+	Items    []SearchHit `json:"items"`
+	Warnings []string    `json:"warnings,omitempty"`
+}
@@ -1,29 +1,63 @@
 // api/model/user.go
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// User status values
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusDisabled  = "disabled"
+
+	// userStatusInactive is the status older releases gave deactivated users
+	userStatusInactive = "inactive"
+)
+
+// NormalizeUserStatus returns the canonical form of a user status: statuses compare without regard to
+// case, an empty status is active and the legacy "inactive" is disabled
+func NormalizeUserStatus(status string) string {
+	status = strings.ToLower(strings.TrimSpace(status))
+	switch status {
+	case "":
+		return UserStatusActive
+	case userStatusInactive:
+		return UserStatusDisabled
+	}
+	return status
+}
 
 type User struct {
-	Identity       string            `json:"identity,omitempty"` // Unique identifier for the user
-	ID             string            `json:"id"`
-	Name           string            `json:"name"`
-	Username       string            `json:"username"`
-	Email          string            `json:"email"`
-	Password       string            `json:"-"`         // Hashed password, not returned in JSON
-	UserType       string            `json:"user_type"` // "AliveLife", "CorporateAdmin", "DepartmentUser"
-	OrganizationID string            `json:"organization_id,omitempty"`
-	DepartmentID   string            `json:"department_id,omitempty"`
-	RoleIds        []string          `json:"role_ids,omitempty"`    // List of role IDs
-	GroupIds       []string          `json:"group_ids,omitempty"`   // List of group IDs
-	Permissions    []string          `json:"permissions,omitempty"` // List of permission IDs (Relationship to resources)
-	Attributes     map[string]string `json:"attributes"`
-	Status         string            `json:"status"` // "Active", "Inactive", "Suspended", etc.
-	LastLogin      *time.Time        `json:"last_login,omitempty"`
-	CreatedAt      time.Time         `json:"created_at"`
-	UpdatedAt      time.Time         `json:"updated_at"`
-	CreatedBy      string            `json:"created_by,omitempty"` // ID of the user who created this user
-	UpdatedBy      string            `json:"updated_by,omitempty"` // ID of the user who last updated this user
-	DeletedAt      *time.Time        `json:"deleted_at,omitempty"` // For soft delete
+	Identity         string            `json:"identity,omitempty"` // Unique identifier for the user
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	Username         string            `json:"username"`
+	Email            string            `json:"email"`
+	Password         string            `json:"-"`         // Hashed password, not returned in JSON
+	UserType         string            `json:"user_type"` // "AliveLife", "CorporateAdmin", "DepartmentUser"
+	OrganizationID   string            `json:"organization_id,omitempty"`
+	DepartmentID     string            `json:"department_id,omitempty"`
+	RoleIds          []string          `json:"role_ids,omitempty"`    // List of role IDs; on update nil keeps the roles and empty clears them
+	GroupIds         []string          `json:"group_ids,omitempty"`   // List of group IDs; on update nil keeps the groups and empty clears them
+	Permissions      []string          `json:"permissions,omitempty"` // List of permission IDs (Relationship to resources)
+	Attributes       map[string]string `json:"attributes"`
+	AttributeGroupID string            `json:"attribute_group_id,omitempty"` // ID of the AttributeGroup whose schema governs Attributes
+	Status           string            `json:"status"`                       // "active", "suspended" or "disabled"
+	Clearance        string            `json:"clearance,omitempty"`          // Most sensitive classification level the user is cleared for
+	LastLogin        *time.Time        `json:"last_login,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	CreatedBy        string            `json:"created_by,omitempty"` // ID of the user who created this user
+	UpdatedBy        string            `json:"updated_by,omitempty"` // ID of the user who last updated this user
+	DeletedAt        *time.Time        `json:"deleted_at,omitempty"` // For soft delete
+}
+
+// UserBatch is the result of a batch get of users
+type UserBatch struct {
+	Items   []*User  `json:"items"`   // In the order they were asked for
+	Missing []string `json:"missing"` // IDs with no user
 }
 
 // UserRelationships represents the relationships a user has in the graph database
@@ -42,6 +76,23 @@ type FullUser struct {
 	Relationships UserRelationships `json:"relationships,omitempty"`
 }
 
+// EffectivePermissions is what a user holds once nested group membership is resolved
+type EffectivePermissions struct {
+	UserID        string   `json:"user_id"`
+	GroupIDs      []string `json:"group_ids"`      // Direct groups and every group they are nested in
+	RoleIDs       []string `json:"role_ids"`       // Direct roles and roles inherited through GroupIDs
+	PermissionIDs []string `json:"permission_ids"` // Permissions granted by RoleIDs
+}
+
+// ScopedAdmin ties an administrator to the part of an organization they manage: a department and
+// every department below it, or the whole organization when DepartmentID is empty
+type ScopedAdmin struct {
+	UserID         string   `json:"user_id"`
+	OrganizationID string   `json:"organization_id"`
+	DepartmentID   string   `json:"department_id,omitempty"`
+	DepartmentIDs  []string `json:"department_ids,omitempty"` // DepartmentID and the departments below it, resolved when read
+}
+
 // UserSearchCriteria defines the possible search parameters for users
 type UserSearchCriteria struct {
 	ID             string            `json:"id,omitempty"`
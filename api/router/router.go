@@ -12,14 +12,21 @@ import (
 
 func SetupRouter(
 	controllers *controller.Controllers,
+	loginRecorder middleware.LoginRecorder,
 	rateLimitRequests int,
 	rateLimitDuration time.Duration,
+	adminGroup string,
 ) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Recovery())
 	router.Use(middleware.Logger())
+	router.Use(middleware.CORS())
+	router.Use(middleware.Compress())
+	router.Use(middleware.BodyLimit())
+	router.Use(middleware.Timeout())
 	router.Use(middleware.RateLimiter(rateLimitRequests, rateLimitDuration))
-	router.Use(middleware.GroupAuthMiddleware([]string{"alive-admin"}))
+	router.Use(middleware.GroupAuthMiddleware([]string{"alive-admin"}, loginRecorder))
+	router.Use(middleware.ReadOnly())
 
 	api := router.Group("/api/v1")
 
@@ -33,6 +40,13 @@ func SetupRouter(
 	controllers.Resource.RegisterRoutes(api)
 	controllers.ResourceType.RegisterRoutes(api)
 	controllers.AttributeGroup.RegisterRoutes(api)
+	controllers.Access.RegisterRoutes(api)
+	controllers.Audit.RegisterRoutes(api)
+	controllers.SoD.RegisterRoutes(api)
+	controllers.Change.RegisterRoutes(api)
+	controllers.Search.RegisterRoutes(api)
+	controllers.Job.RegisterRoutes(api)
+	controllers.Admin.RegisterRoutes(api.Group("", middleware.RequireGroups(adminGroup)))
 
 	return router
 }
@@ -0,0 +1,770 @@
+// api/service/access_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+// IAccessService defines the interface of the policy decision point
+type IAccessService interface {
+	EvaluateAccess(ctx context.Context, request model.AccessRequest) (*model.AccessDecision, error)
+	GenerateAccessReview(ctx context.Context, orgID string) (*model.AccessReviewReport, error)
+	GenerateAccessReviewPage(ctx context.Context, orgID string, limit int, offset int) (*model.AccessReviewReport, error)
+	ExportPolicyBundle(ctx context.Context, orgID string) ([]byte, error)
+	SubmitAccessReview(ctx context.Context, orgID string) (*model.Job, error)
+}
+
+// AccessService evaluates access requests against the active policies
+type AccessService struct {
+	userDAO      *dao.UserDAO
+	resourceDAO  *dao.ResourceDAO
+	policyDAO    *dao.PolicyDAO
+	cacheService *util.CacheService
+	jobRunner    *util.JobRunner
+}
+
+var _ IAccessService = &AccessService{}
+
+// defaultEffect decides requests no policy matches
+var defaultEffect = echo_neo4j.PolicyEffectDeny
+
+// SetDefaultEffect sets the effect of requests no policy matches, "deny" or "allow". Unknown effects
+// keep the current one, and allowing by default is warned about, as every request then goes through
+// unless a policy denies it.
+func SetDefaultEffect(effect string) {
+	switch effect = strings.ToUpper(effect); effect {
+	case echo_neo4j.PolicyEffectDeny:
+		defaultEffect = effect
+	case echo_neo4j.PolicyEffectAllow:
+		defaultEffect = effect
+		logger.Warn("Requests no policy matches are allowed by default; set pdp.defaultEffect to deny unless every resource is public")
+	default:
+		logger.Warn("Unknown default effect ignored", zap.String("effect", effect), zap.String("current", defaultEffect))
+	}
+}
+
+// NewAccessService creates a new instance of AccessService. Decisions are cached in cacheService, when
+// the decision cache is on, and invalidated on the changes eventBus publishes. Access reviews submitted
+// as jobs run on jobRunner.
+func NewAccessService(userDAO *dao.UserDAO, resourceDAO *dao.ResourceDAO, policyDAO *dao.PolicyDAO, cacheService *util.CacheService, eventBus *util.EventBus, jobRunner *util.JobRunner) *AccessService {
+	service := &AccessService{
+		userDAO:      userDAO,
+		resourceDAO:  resourceDAO,
+		policyDAO:    policyDAO,
+		cacheService: cacheService,
+		jobRunner:    jobRunner,
+	}
+	service.subscribeDecisionInvalidation(eventBus)
+	return service
+}
+
+// EvaluateAccess decides whether the subject may perform the action on the resource.
+// Subjects that are not active are always denied, as are subjects whose clearance is below the
+// resource's classification while clearance is enforced. Otherwise the highest priority matching
+// policies decide: those listing the action, then the resource type, exactly come before those matching
+// them by a pattern, which come before "*", and deny overrides allow among equally close matches. No
+// match means the default effect. A resource inheriting its ACL that no policy matches is decided by
+// the policies of its nearest ancestor some policy matches, up to the inheritance depth.
+// While the decision cache is on, repeated requests are answered from it; requests asking for an
+// explanation are always evaluated.
+func (s *AccessService) EvaluateAccess(ctx context.Context, request model.AccessRequest) (*model.AccessDecision, error) {
+	start := time.Now()
+	if request.SubjectID == "" || request.ResourceID == "" || request.Action == "" {
+		return nil, fmt.Errorf("%w: subject, resource and action are required", echo_errors.ErrInvalidAccessRequest)
+	}
+
+	// Subject status is read from the database rather than either cache, ahead of the cached decisions,
+	// so suspensions take effect immediately
+	subject, err := s.userDAO.GetUser(ctx, request.SubjectID)
+	if err != nil {
+		logger.Error("Error retrieving access subject", zap.Error(err), zap.String("subjectID", request.SubjectID))
+		return nil, err
+	}
+
+	if decision := inactiveSubjectDecision(subject, request); decision != nil {
+		logDecision(request, decision, start)
+		return decision, nil
+	}
+
+	cacheKey := ""
+	if s.decisionsCached() && !request.Explain {
+		cacheKey = decisionCacheKey(request)
+		if decision := s.cachedDecision(ctx, cacheKey); decision != nil {
+			logDecision(request, decision, start)
+			return decision, nil
+		}
+	}
+
+	// Role and group subjects match on everything the user holds through nested groups
+	effective, err := s.userDAO.GetEffectivePermissions(ctx, request.SubjectID)
+	if err != nil {
+		logger.Error("Error resolving subject memberships", zap.Error(err), zap.String("subjectID", request.SubjectID))
+		return nil, err
+	}
+	subject.RoleIds = effective.RoleIDs
+	subject.GroupIds = effective.GroupIDs
+
+	resource, err := s.resourceDAO.GetResource(ctx, request.ResourceID)
+	if err != nil {
+		logger.Error("Error retrieving access resource", zap.Error(err), zap.String("resourceID", request.ResourceID))
+		return nil, err
+	}
+
+	var ancestors []*model.Resource
+	if resource.InheritedACL && inheritanceMaxDepth > 0 {
+		if ancestors, err = s.resourceDAO.GetInheritingAncestors(ctx, request.ResourceID, inheritanceMaxDepth); err != nil {
+			logger.Error("Error retrieving inherited resources", zap.Error(err), zap.String("resourceID", request.ResourceID))
+			return nil, err
+		}
+	}
+
+	policies, err := s.policyDAO.GetActivePolicies(ctx)
+	if err != nil {
+		logger.Error("Error retrieving active policies", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve active policies: %w", err)
+	}
+
+	now := time.Now()
+	decision := evaluateInheritedPolicies(policies, subject, resource, ancestors, request, now)
+	if cacheKey != "" {
+		s.cacheDecision(ctx, cacheKey, decision, policies, subject, resource, ancestors, request, now)
+	}
+	logDecision(request, decision, start)
+	return decision, nil
+}
+
+// GenerateAccessReview reports the roles, groups, permissions and accessible resources of every user
+// of the organization. Large organizations are better reviewed page by page with GenerateAccessReviewPage,
+// or in the background with SubmitAccessReview.
+func (s *AccessService) GenerateAccessReview(ctx context.Context, orgID string) (*model.AccessReviewReport, error) {
+	if err := checkTenantAccess(ctx, TenantEntityResource, orgID); err != nil {
+		return nil, err
+	}
+	return s.generateAccessReview(ctx, orgID, nil)
+}
+
+// SubmitAccessReview generates the access review of the organization in a background job, which
+// reports each user as it is reviewed and ends with the report GenerateAccessReview returns
+func (s *AccessService) SubmitAccessReview(ctx context.Context, orgID string) (*model.Job, error) {
+	if err := checkTenantAccess(ctx, TenantEntityResource, orgID); err != nil {
+		return nil, err
+	}
+
+	return s.jobRunner.Submit(ctx, JobTypeAccessReview, func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+		return s.generateAccessReview(ctx, orgID, progress)
+	})
+}
+
+// generateAccessReview reviews every user of the organization, reporting each page of them to progress
+func (s *AccessService) generateAccessReview(ctx context.Context, orgID string, progress *util.JobProgress) (*model.AccessReviewReport, error) {
+	review, err := s.newAccessReview(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.AccessReviewReport{OrganizationID: orgID, GeneratedAt: review.now, Users: []model.AccessReviewEntry{}}
+	limit := helper_util.MaxPageLimit()
+	for offset := 0; ; offset += limit {
+		users, total, err := s.userDAO.GetUsersByOrganization(ctx, orgID, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization users: %w", err)
+		}
+		if offset == 0 {
+			progress.SetTotal(int(total))
+		}
+		report.Total = total
+		if err := review.addEntries(ctx, report, users); err != nil {
+			return nil, err
+		}
+		progress.Succeeded(len(users))
+		if len(users) < limit {
+			return report, nil
+		}
+	}
+}
+
+// GenerateAccessReviewPage reports on one page of the organization's users, like GenerateAccessReview
+func (s *AccessService) GenerateAccessReviewPage(ctx context.Context, orgID string, limit int, offset int) (*model.AccessReviewReport, error) {
+	if err := checkTenantAccess(ctx, TenantEntityResource, orgID); err != nil {
+		return nil, err
+	}
+
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.userDAO.GetUsersByOrganization(ctx, orgID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization users: %w", err)
+	}
+
+	review, err := s.newAccessReview(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.AccessReviewReport{OrganizationID: orgID, GeneratedAt: review.now, Total: total, Offset: offset, Users: []model.AccessReviewEntry{}}
+	if err := review.addEntries(ctx, report, users); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// accessReview holds what every entry of an access review is evaluated against. Resources inherit
+// access only from ancestors within the organization.
+type accessReview struct {
+	s         *AccessService
+	resources []*model.Resource
+	ancestors map[string][]*model.Resource
+	policies  []*model.Policy
+	actions   []string
+	now       time.Time
+}
+
+// newAccessReview loads the organization's resources and the active policies. The actions reviewed are
+// those the policies name; a wildcard action grants nothing beyond them, as there is no list of all actions.
+func (s *AccessService) newAccessReview(ctx context.Context, orgID string) (*accessReview, error) {
+	review := &accessReview{s: s, now: time.Now()}
+
+	limit := helper_util.MaxPageLimit()
+	for offset := 0; ; offset += limit {
+		resources, _, err := s.resourceDAO.GetResourcesByOrganization(ctx, orgID, false, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization resources: %w", err)
+		}
+		review.resources = append(review.resources, resources...)
+		if len(resources) < limit {
+			break
+		}
+	}
+
+	byID := make(map[string]*model.Resource, len(review.resources))
+	for _, resource := range review.resources {
+		byID[resource.ID] = resource
+	}
+	review.ancestors = make(map[string][]*model.Resource, len(review.resources))
+	for _, resource := range review.resources {
+		review.ancestors[resource.ID] = inheritingAncestors(resource, byID)
+	}
+
+	policies, err := s.policyDAO.GetActivePolicies(ctx)
+	if err != nil {
+		logger.Error("Error retrieving active policies", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve active policies: %w", err)
+	}
+	review.policies = policies
+
+	seen := map[string]bool{}
+	for _, policy := range policies {
+		for _, action := range policy.Actions {
+			if action != "*" && !seen[action] {
+				seen[action] = true
+				review.actions = append(review.actions, action)
+			}
+		}
+	}
+	sort.Strings(review.actions)
+	return review, nil
+}
+
+func (r *accessReview) addEntries(ctx context.Context, report *model.AccessReviewReport, users []*model.User) error {
+	for _, user := range users {
+		entry, err := r.entry(ctx, user)
+		if err != nil {
+			return err
+		}
+		report.Users = append(report.Users, *entry)
+	}
+	return nil
+}
+
+// entry evaluates every reviewed action on every resource for the user, exactly as EvaluateAccess would
+func (r *accessReview) entry(ctx context.Context, user *model.User) (*model.AccessReviewEntry, error) {
+	effective, err := r.s.userDAO.GetEffectivePermissions(ctx, user.ID)
+	if err != nil {
+		logger.Error("Error resolving reviewed user memberships", zap.Error(err), zap.String("userID", user.ID))
+		return nil, fmt.Errorf("failed to resolve effective permissions of user %s: %w", user.ID, err)
+	}
+	user.RoleIds = effective.RoleIDs
+	user.GroupIds = effective.GroupIDs
+
+	entry := &model.AccessReviewEntry{
+		UserID:        user.ID,
+		Username:      user.Username,
+		Name:          user.Name,
+		Status:        user.Status,
+		RoleIDs:       effective.RoleIDs,
+		GroupIDs:      effective.GroupIDs,
+		PermissionIDs: effective.PermissionIDs,
+		Resources:     []model.ResourceAccess{},
+	}
+	// Subjects that are not active are denied everything
+	if !isActiveSubject(user) {
+		return entry, nil
+	}
+
+	for _, resource := range r.resources {
+		var allowed []string
+		for _, action := range r.actions {
+			request := model.AccessRequest{SubjectID: user.ID, ResourceID: resource.ID, Action: action}
+			if evaluateInheritedPolicies(r.policies, user, resource, r.ancestors[resource.ID], request, r.now).Allowed {
+				allowed = append(allowed, action)
+			}
+		}
+		if len(allowed) > 0 {
+			entry.Resources = append(entry.Resources, model.ResourceAccess{ResourceID: resource.ID, ResourceName: resource.Name, Actions: allowed})
+		}
+	}
+	return entry, nil
+}
+
+// Helper methods
+
+// isActiveSubject reports whether the subject may be granted anything. Users created before statuses
+// were enforced may have none and count as active, as they do when their status is changed.
+func isActiveSubject(subject *model.User) bool {
+	return model.NormalizeUserStatus(subject.Status) == model.UserStatusActive
+}
+
+// inactiveSubjectDecision denies every request of a subject that is not active, and returns nil for
+// active subjects
+func inactiveSubjectDecision(subject *model.User, request model.AccessRequest) *model.AccessDecision {
+	if isActiveSubject(subject) {
+		return nil
+	}
+	return explained(denyDecision("", fmt.Sprintf("subject status is %q", subject.Status)), request, nil)
+}
+
+func evaluatePolicies(policies []*model.Policy, subject *model.User, resource *model.Resource, request model.AccessRequest, now time.Time) *model.AccessDecision {
+	return evaluateInheritedPolicies(policies, subject, resource, nil, request, now)
+}
+
+// evaluateInheritedPolicies decides the request like evaluatePolicies, falling back on the ancestors the
+// resource inherits access from, nearest first, when no policy matches the resource itself. The first
+// resource some policy matches decides, so policies matching a child override those of its parents.
+func evaluateInheritedPolicies(policies []*model.Policy, subject *model.User, resource *model.Resource, ancestors []*model.Resource, request model.AccessRequest, now time.Time) *model.AccessDecision {
+	// Clearance is a hard floor no policy can lift
+	if reason := clearanceDenial(subject, resource); reason != "" {
+		return explained(denyDecision("", reason), request, nil)
+	}
+
+	var matched []*model.Policy
+	var trace []model.PolicyTrace
+	inheritedFrom := ""
+	for _, evaluated := range append([]*model.Resource{resource}, ancestors...) {
+		if evaluated != resource {
+			inheritedFrom = evaluated.ID
+		}
+		var levelTrace []model.PolicyTrace
+		matched, levelTrace = matchPolicies(policies, subject, evaluated, request, now)
+		for i := range levelTrace {
+			levelTrace[i].InheritedFrom = inheritedFrom
+		}
+		trace = append(trace, levelTrace...)
+		if len(matched) > 0 {
+			resource = evaluated
+			break
+		}
+	}
+
+	if len(matched) == 0 {
+		return explained(defaultDecision(), request, trace)
+	}
+
+	// At a priority, policies matching the action, then the resource type, more closely come first
+	specificity := make(map[*model.Policy][2]int, len(matched))
+	for _, policy := range matched {
+		specificity[policy] = policySpecificity(policy, resource, request.Action)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Priority == matched[j].Priority && specificity[matched[i]] != specificity[matched[j]] {
+			return moreSpecific(specificity[matched[i]], specificity[matched[j]])
+		}
+		return policyPrecedes(matched[i], matched[j])
+	})
+
+	// Obligations are only reported; enforcing them is the caller's job
+	var obligations []model.Obligation
+	for _, policy := range matched {
+		obligations = append(obligations, policy.Obligations...)
+	}
+
+	inherited := ""
+	if inheritedFrom != "" {
+		inherited = fmt.Sprintf(" inherited from resource %s", inheritedFrom)
+	}
+
+	// Among the closest matches sharing the highest priority a deny wins, so the first one decides
+	deciding := matched[0]
+	if strings.EqualFold(deciding.Effect, echo_neo4j.PolicyEffectDeny) {
+		decision := denyDecision(deciding.ID, fmt.Sprintf("denied by policy %q%s", deciding.Name, inherited))
+		decision.InheritedFrom = inheritedFrom
+		decision.Obligations = obligations
+		return explained(decision, request, trace)
+	}
+
+	return explained(&model.AccessDecision{
+		Allowed:       true,
+		Effect:        echo_neo4j.PolicyEffectAllow,
+		PolicyID:      deciding.ID,
+		InheritedFrom: inheritedFrom,
+		Reason:        fmt.Sprintf("allowed by policy %q%s", deciding.Name, inherited),
+		Timestamp:     time.Now(),
+		Obligations:   obligations,
+	}, request, trace)
+}
+
+// matchPolicies returns the policies covering the request on the resource, along with the trace of every
+// policy when the request asks for an explanation
+func matchPolicies(policies []*model.Policy, subject *model.User, resource *model.Resource, request model.AccessRequest, now time.Time) ([]*model.Policy, []model.PolicyTrace) {
+	attributes := buildEvaluationAttributes(subject, resource, request, now)
+
+	var matched []*model.Policy
+	var trace []model.PolicyTrace
+	for _, policy := range policies {
+		if !request.Explain {
+			if policyMismatch(policy, subject, resource, request.Action, now) == "" && conditionsMatch(policy.Conditions, attributes) {
+				matched = append(matched, policy)
+			}
+			continue
+		}
+
+		entry := tracePolicy(policy, subject, resource, request.Action, attributes, now)
+		if entry.Matched {
+			matched = append(matched, policy)
+		}
+		trace = append(trace, entry)
+	}
+	return matched, trace
+}
+
+// tracePolicy evaluates one candidate policy the way evaluatePolicies does, recording why it did not
+// match
+func tracePolicy(policy *model.Policy, subject *model.User, resource *model.Resource, action string, attributes map[string]interface{}, now time.Time) model.PolicyTrace {
+	entry := model.PolicyTrace{
+		PolicyID:   policy.ID,
+		PolicyName: policy.Name,
+		Effect:     policy.Effect,
+		Priority:   policy.Priority,
+	}
+	if entry.Reason = policyMismatch(policy, subject, resource, action, now); entry.Reason != "" {
+		return entry
+	}
+	for i, condition := range policy.Conditions {
+		if !conditionMatches(condition, attributes) {
+			entry.FailedCondition = &policy.Conditions[i]
+			entry.Reason = fmt.Sprintf("condition on %q is not met", condition.Attribute)
+			if condition.Attribute == "" {
+				entry.Reason = "condition set is not met"
+			}
+			return entry
+		}
+	}
+	entry.Matched = true
+	return entry
+}
+
+// explained attaches the trace to the decision when the request asked for it, flagging the policy
+// that determined the effect
+func explained(decision *model.AccessDecision, request model.AccessRequest, trace []model.PolicyTrace) *model.AccessDecision {
+	if !request.Explain {
+		return decision
+	}
+	for i := range trace {
+		trace[i].Deciding = decision.PolicyID != "" && trace[i].PolicyID == decision.PolicyID && trace[i].InheritedFrom == decision.InheritedFrom
+	}
+	if trace == nil {
+		trace = []model.PolicyTrace{}
+	}
+	decision.Trace = &model.DecisionTrace{Policies: trace}
+	return decision
+}
+
+// policyMismatch returns why the policy does not cover the request, before its conditions are
+// considered, or an empty reason when it does
+func policyMismatch(policy *model.Policy, subject *model.User, resource *model.Resource, action string, now time.Time) string {
+	if reason := policyInactivity(policy, now); reason != "" {
+		return reason
+	}
+	if !containsOrWildcard(policy.Actions, action) {
+		return "action is not covered"
+	}
+	if reason := resourceMismatch(policy, resource); reason != "" {
+		return reason
+	}
+	for _, policySubject := range policy.Subjects {
+		if subjectMatches(policySubject, subject) {
+			return ""
+		}
+	}
+	return "no subject matches"
+}
+
+// policyInactivity returns why a policy is not evaluated at all at now, or "" when it is
+func policyInactivity(policy *model.Policy, now time.Time) string {
+	if !policy.Active {
+		return "policy is inactive"
+	}
+	// Drafts and policies awaiting or refused approval are never evaluated
+	if policy.Status != "" && policy.Status != model.PolicyStatusActive {
+		return "policy is not approved"
+	}
+	if policy.ActivationDate != nil && now.Before(*policy.ActivationDate) {
+		return "policy is not yet activated"
+	}
+	if policy.DeactivationDate != nil && !now.Before(*policy.DeactivationDate) {
+		return "policy is deactivated"
+	}
+	return ""
+}
+
+// resourceMismatch returns why a policy does not apply to a resource, or "" when its resource types
+// and attribute groups cover it
+func resourceMismatch(policy *model.Policy, resource *model.Resource) string {
+	if len(policy.ResourceTypes) > 0 && !containsOrWildcard(policy.ResourceTypes, resource.Type) && !containsOrWildcard(policy.ResourceTypes, resource.TypeID) {
+		return "resource type is not covered"
+	}
+	if len(policy.AttributeGroups) > 0 && !containsOrWildcard(policy.AttributeGroups, resource.AttributeGroupID) {
+		return "attribute group is not covered"
+	}
+	return ""
+}
+
+// subjectMatches checks a policy subject against the requesting user. Role, group, department and
+// organization subjects carry the referenced ID in the "id" attribute; any other attribute must
+// equal the user's attribute of the same name.
+func subjectMatches(policySubject model.Subject, subject *model.User) bool {
+	switch strings.ToLower(policySubject.Type) {
+	case "user":
+		if policySubject.UserID != "" && policySubject.UserID != subject.ID {
+			return false
+		}
+	case "role":
+		if id := policySubject.Attributes["id"]; id != "*" && !containsOrWildcard(subject.RoleIds, id) {
+			return false
+		}
+	case "group":
+		if id := policySubject.Attributes["id"]; id != "*" && !containsOrWildcard(subject.GroupIds, id) {
+			return false
+		}
+	case "department":
+		if policySubject.Attributes["id"] != subject.DepartmentID {
+			return false
+		}
+	case "organization":
+		if policySubject.Attributes["id"] != subject.OrganizationID {
+			return false
+		}
+	default:
+		return false
+	}
+
+	for key, value := range policySubject.Attributes {
+		if key == "id" {
+			continue
+		}
+		if subject.Attributes[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// buildEvaluationAttributes flattens the subject, resource and request context into the
+// "subject.*", "resource.*" and "context.*" names that policy conditions refer to, along with the
+// temporal attributes of now
+func buildEvaluationAttributes(subject *model.User, resource *model.Resource, request model.AccessRequest, now time.Time) map[string]interface{} {
+	attributes := map[string]interface{}{
+		"action":                   request.Action,
+		"subject.id":               subject.ID,
+		"subject.user_type":        subject.UserType,
+		"subject.organization_id":  subject.OrganizationID,
+		"subject.department_id":    subject.DepartmentID,
+		"subject.status":           subject.Status,
+		"subject.clearance":        subject.Clearance,
+		"resource.id":              resource.ID,
+		"resource.type":            resource.Type,
+		"resource.type_id":         resource.TypeID,
+		"resource.organization_id": resource.OrganizationID,
+		"resource.department_id":   resource.DepartmentID,
+		"resource.owner_id":        resource.OwnerID,
+		"resource.status":          resource.Status,
+		"resource.sensitivity":     resource.Sensitivity,
+		"resource.classification":  resource.Classification,
+	}
+	for key, value := range subject.Attributes {
+		attributes["subject."+key] = value
+	}
+	for key, value := range resource.Attributes {
+		attributes["resource."+key] = value
+	}
+	for key, value := range request.Context {
+		attributes["context."+key] = value
+	}
+	addTemporalAttributes(attributes, now)
+	return attributes
+}
+
+func conditionsMatch(conditions []model.Condition, attributes map[string]interface{}) bool {
+	for _, condition := range conditions {
+		if !conditionMatches(condition, attributes) {
+			return false
+		}
+	}
+	return true
+}
+
+func conditionMatches(condition model.Condition, attributes map[string]interface{}) bool {
+	if condition.Attribute != "" && !attributeMatches(condition, attributes[condition.Attribute]) {
+		return false
+	}
+	if condition.SubConditions == nil {
+		return true
+	}
+
+	if strings.EqualFold(condition.SubConditions.Operator, "OR") {
+		for _, sub := range condition.SubConditions.Conditions {
+			if conditionMatches(sub, attributes) {
+				return true
+			}
+		}
+		return len(condition.SubConditions.Conditions) == 0
+	}
+	return conditionsMatch(condition.SubConditions.Conditions, attributes)
+}
+
+func compareAttribute(operator string, actual, expected interface{}) bool {
+	switch strings.ToLower(operator) {
+	case "exists":
+		return actual != nil
+	case "equals", "eq", "==":
+		return actual != nil && valuesEqual(actual, expected)
+	case "not_equals", "ne", "!=":
+		return !valuesEqual(actual, expected)
+	case "in":
+		return actual != nil && listContains(expected, actual)
+	case "not_in":
+		return !listContains(expected, actual)
+	case "contains":
+		if s, ok := actual.(string); ok {
+			e, ok := expected.(string)
+			return ok && strings.Contains(s, e)
+		}
+		return listContains(actual, expected)
+	case "greater_than", "gt", ">":
+		a, aok := toNumber(actual)
+		e, eok := toNumber(expected)
+		return aok && eok && a > e
+	case "less_than", "lt", "<":
+		a, aok := toNumber(actual)
+		e, eok := toNumber(expected)
+		return aok && eok && a < e
+	case "between":
+		return timeOfDayBetween(actual, expected)
+	case "ipincidr":
+		return ipInCIDR(actual, expected)
+	case "matches":
+		a, aok := actual.(string)
+		e, eok := expected.(string)
+		return aok && eok && util.GlobMatch(e, a)
+	default:
+		logger.Warn("Unsupported condition operator", zap.String("operator", operator))
+		return false
+	}
+}
+
+func valuesEqual(a, b interface{}) bool {
+	if an, ok := toNumber(a); ok {
+		if bn, ok := toNumber(b); ok {
+			return an == bn
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func listContains(list, value interface{}) bool {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false
+	}
+	for i := 0; i < v.Len(); i++ {
+		if valuesEqual(v.Index(i).Interface(), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// containsOrWildcard reports whether one of the values, read as glob patterns, matches value
+func containsOrWildcard(values []string, value string) bool {
+	return patternMatch(values, value) != matchNone
+}
+
+func denyDecision(policyID, reason string) *model.AccessDecision {
+	return &model.AccessDecision{
+		Allowed:   false,
+		Effect:    echo_neo4j.PolicyEffectDeny,
+		PolicyID:  policyID,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+}
+
+// defaultDecision decides a request no policy matches with the default effect
+func defaultDecision() *model.AccessDecision {
+	reason := fmt.Sprintf("no matching policy, default effect is %s", strings.ToLower(defaultEffect))
+	if defaultEffect != echo_neo4j.PolicyEffectAllow {
+		return denyDecision("", reason)
+	}
+	return &model.AccessDecision{
+		Allowed:   true,
+		Effect:    echo_neo4j.PolicyEffectAllow,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+}
+
+func logDecision(request model.AccessRequest, decision *model.AccessDecision, start time.Time) {
+	logger.Info("Access evaluated",
+		zap.String("subjectID", request.SubjectID),
+		zap.String("resourceID", request.ResourceID),
+		zap.String("action", request.Action),
+		zap.Bool("allowed", decision.Allowed),
+		zap.String("policyID", decision.PolicyID),
+		zap.String("reason", decision.Reason),
+		zap.Int("obligations", len(decision.Obligations)),
+		zap.Duration("duration", time.Since(start)))
+}
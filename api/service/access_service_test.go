@@ -0,0 +1,416 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func userRecord(id, status string) *neo4j.Record {
+	node := neo4j.Node{Props: map[string]any{
+		"id":             id,
+		"name":           "Jane Doe",
+		"username":       "jdoe",
+		"email":          "jdoe@example.com",
+		"userType":       "DepartmentUser",
+		"organizationID": "org1",
+		"departmentID":   "dept1",
+		"attributes":     "{}",
+		"status":         status,
+		"createdAt":      "2024-01-01T00:00:00Z",
+		"updatedAt":      "2024-01-01T00:00:00Z",
+	}}
+	return &neo4j.Record{Keys: []string{"u", "roleIds"}, Values: []any{node, []interface{}{"r1"}}}
+}
+
+func TestAccessServiceDeniesInactiveSubjects(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	request := model.AccessRequest{SubjectID: "u1", ResourceID: "res1", Action: "read"}
+
+	for _, status := range []string{model.UserStatusSuspended, model.UserStatusDisabled, "Suspended", "inactive"} {
+		t.Run(status, func(t *testing.T) {
+			userResult := &mock.MockResult{}
+			userResult.On("Next").Return(true).Once()
+			userResult.On("Record").Return(userRecord("u1", status))
+
+			session := &mock.MockSession{}
+			session.On("Close").Return(nil)
+			session.On("Run", testify_mock.MatchedBy(func(query string) bool {
+				return strings.Contains(query, "MATCH (u:"+echo_neo4j.LabelUser+" {id: $id})")
+			}), map[string]interface{}{"id": "u1"}, testify_mock.Anything).Return(userResult, nil)
+			driver := &mock.MockDriver{}
+			driver.On("NewSession", testify_mock.Anything).Return(session)
+			auditService := &mock.MockAuditService{}
+
+			accessService := service.NewAccessService(
+				&dao.UserDAO{Driver: driver, AuditService: auditService},
+				&dao.ResourceDAO{Driver: driver, AuditService: auditService},
+				&dao.PolicyDAO{Driver: driver, AuditService: auditService},
+				nil,
+				util.NewEventBus(),
+				nil,
+			)
+
+			decision, err := accessService.EvaluateAccess(ctx, request)
+
+			assert.NoError(t, err)
+			assert.False(t, decision.Allowed)
+			assert.Equal(t, echo_neo4j.PolicyEffectDeny, decision.Effect)
+			assert.Contains(t, decision.Reason, status)
+			// Neither the resource nor any policy is consulted once the subject is inactive
+			session.AssertNumberOfCalls(t, "Run", 1)
+		})
+	}
+}
+
+func TestAccessServiceTreatsSubjectsWithoutStatusAsActive(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	// The subject was created before statuses were enforced and has none
+	resource := mock.ResourceNode("res1", nil)
+	policy := neo4j.Node{Props: map[string]any{
+		"id":                "p-read",
+		"name":              "p-read",
+		"description":       "",
+		"effect":            echo_neo4j.PolicyEffectAllow,
+		"priority":          int64(1),
+		"version":           int64(1),
+		"createdAt":         "2024-01-01T00:00:00Z",
+		"updatedAt":         "2024-01-01T00:00:00Z",
+		"active":            true,
+		"subjects":          `[{"type":"role","attributes":{"id":"r1"}}]`,
+		"resourceTypes":     "[]",
+		"attributeGroups":   "[]",
+		"actions":           `["read"]`,
+		"conditions":        "[]",
+		"dynamicAttributes": "[]",
+	}}
+	driver := mock.NewFakeDriver().
+		Returns("AS permissionIDs", &neo4j.Record{Values: []any{[]interface{}{}, []interface{}{"r1"}, []interface{}{}}}).
+		Returns("AS parentID", &neo4j.Record{Keys: []string{"r", "parentID", "relatedIDs"}, Values: []any{resource, nil, []any{}}}).
+		Returns("WHERE p.active = true", &neo4j.Record{Values: []any{policy}}).
+		Returns("RETURN u, roleIds", userRecord("u1", ""))
+	auditService := &mock.MockAuditService{}
+	accessService := service.NewAccessService(
+		&dao.UserDAO{Driver: driver, AuditService: auditService},
+		&dao.ResourceDAO{Driver: driver, AuditService: auditService},
+		&dao.PolicyDAO{Driver: driver, AuditService: auditService},
+		nil,
+		util.NewEventBus(),
+		nil,
+	)
+
+	decision, err := accessService.EvaluateAccess(context.Background(), model.AccessRequest{SubjectID: "u1", ResourceID: "res1", Action: "read"})
+
+	assert.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "p-read", decision.PolicyID)
+}
+
+func TestAccessServiceGenerateAccessReview(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	queryContaining := func(fragment string) interface{} {
+		return testify_mock.MatchedBy(func(query string) bool { return strings.Contains(query, fragment) })
+	}
+	resultOf := func(records ...*neo4j.Record) *mock.MockResult {
+		result := &mock.MockResult{}
+		for _, record := range records {
+			result.On("Next").Return(true).Once()
+			result.On("Record").Return(record).Once()
+		}
+		result.On("Next").Return(false)
+		return result
+	}
+	policyRecord := func(id, roleID string, actions string) *neo4j.Record {
+		return &neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+			"id":                id,
+			"name":              id,
+			"description":       "",
+			"effect":            echo_neo4j.PolicyEffectAllow,
+			"priority":          int64(1),
+			"version":           int64(1),
+			"createdAt":         "2024-01-01T00:00:00Z",
+			"updatedAt":         "2024-01-01T00:00:00Z",
+			"active":            true,
+			"subjects":          `[{"type":"role","attributes":{"id":"` + roleID + `"}}]`,
+			"resourceTypes":     "[]",
+			"attributeGroups":   "[]",
+			"actions":           actions,
+			"conditions":        "[]",
+			"dynamicAttributes": "[]",
+		}}}}
+	}
+	resource := mock.ResourceNode("res1", nil)
+
+	// The editor role may read and write, the viewer role may only read. The viewer predates user
+	// statuses and has none.
+	session := &mock.MockSession{}
+	session.On("Close").Return(nil)
+	session.On("Run", queryContaining("count(u) AS total"), testify_mock.Anything, testify_mock.Anything).
+		Return(resultOf(&neo4j.Record{Values: []any{true, int64(2)}}), nil)
+	session.On("Run", queryContaining("{id: $orgID})<-[:"+echo_neo4j.RelWorksFor+"]-(u:"), testify_mock.Anything, testify_mock.Anything).
+		Return(resultOf(userRecord("u1", model.UserStatusActive), userRecord("u2", "")), nil)
+	session.On("Run", queryContaining("count(r) AS total"), testify_mock.Anything, testify_mock.Anything).
+		Return(resultOf(&neo4j.Record{Values: []any{true, int64(1)}}), nil)
+	session.On("Run", queryContaining("{id: $orgID})<-[:BELONGS_TO]-(r:"), testify_mock.Anything, testify_mock.Anything).
+		Return(resultOf(&neo4j.Record{Values: []any{resource}}), nil)
+	session.On("Run", queryContaining("WHERE p.active = true"), testify_mock.Anything, testify_mock.Anything).
+		Return(resultOf(policyRecord("p-edit", "editor", `["read","write"]`), policyRecord("p-view", "viewer", `["read"]`)), nil)
+	session.On("Run", queryContaining("AS permissionIDs"), map[string]interface{}{"id": "u1"}, testify_mock.Anything).
+		Return(resultOf(&neo4j.Record{Values: []any{[]interface{}{"g1"}, []interface{}{"editor"}, []interface{}{"perm-write"}}}), nil)
+	session.On("Run", queryContaining("AS permissionIDs"), map[string]interface{}{"id": "u2"}, testify_mock.Anything).
+		Return(resultOf(&neo4j.Record{Values: []any{[]interface{}{}, []interface{}{"viewer"}, []interface{}{"perm-read"}}}), nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	auditService := &mock.MockAuditService{}
+
+	accessService := service.NewAccessService(
+		&dao.UserDAO{Driver: driver, AuditService: auditService},
+		&dao.ResourceDAO{Driver: driver, AuditService: auditService},
+		&dao.PolicyDAO{Driver: driver, AuditService: auditService},
+		nil,
+		util.NewEventBus(),
+		nil,
+	)
+
+	report, err := accessService.GenerateAccessReview(ctx, "org1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "org1", report.OrganizationID)
+	assert.Equal(t, int64(2), report.Total)
+	if assert.Len(t, report.Users, 2) {
+		editor, viewer := report.Users[0], report.Users[1]
+
+		assert.Equal(t, "u1", editor.UserID)
+		assert.Equal(t, []string{"editor"}, editor.RoleIDs)
+		assert.Equal(t, []string{"g1"}, editor.GroupIDs)
+		assert.Equal(t, []string{"perm-write"}, editor.PermissionIDs)
+		assert.Equal(t, []model.ResourceAccess{{ResourceID: "res1", ResourceName: "Quarterly Report", Actions: []string{"read", "write"}}}, editor.Resources)
+
+		assert.Equal(t, "u2", viewer.UserID)
+		assert.Equal(t, []string{"viewer"}, viewer.RoleIDs)
+		assert.Empty(t, viewer.GroupIDs)
+		assert.Equal(t, []model.ResourceAccess{{ResourceID: "res1", ResourceName: "Quarterly Report", Actions: []string{"read"}}}, viewer.Resources)
+	}
+}
+
+func TestAccessDecisionObligations(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	allowWithMFA := &model.Policy{
+		ID:          "p1",
+		Name:        "Finance reads",
+		Effect:      echo_neo4j.PolicyEffectAllow,
+		Active:      true,
+		Priority:    10,
+		Subjects:    []model.Subject{{Type: "user", UserID: "u1"}},
+		Actions:     []string{"read"},
+		Obligations: []model.Obligation{{ID: "require_mfa"}, {ID: "mask_field", Params: map[string]interface{}{"field": "ssn"}}},
+	}
+	auditReads := &model.Policy{
+		ID:          "p2",
+		Name:        "Audit reads",
+		Effect:      echo_neo4j.PolicyEffectAllow,
+		Active:      true,
+		Priority:    1,
+		Subjects:    []model.Subject{{Type: "user", UserID: "u1"}},
+		Actions:     []string{"read"},
+		Obligations: []model.Obligation{{ID: "log", Params: map[string]interface{}{"verbosity": "high"}}},
+	}
+	writesOnly := &model.Policy{
+		ID:          "p3",
+		Name:        "Finance writes",
+		Effect:      echo_neo4j.PolicyEffectAllow,
+		Active:      true,
+		Subjects:    []model.Subject{{Type: "user", UserID: "u1"}},
+		Actions:     []string{"write"},
+		Obligations: []model.Obligation{{ID: "require_approval"}},
+	}
+	subject := &model.User{ID: "u1", Status: model.UserStatusActive}
+	resource := &model.Resource{ID: "res1", Type: "DOCUMENT"}
+	request := model.AccessRequest{SubjectID: "u1", ResourceID: "res1", Action: "read"}
+
+	decision := service.EvaluatePolicies([]*model.Policy{writesOnly, auditReads, allowWithMFA}, subject, resource, request, time.Now())
+
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "p1", decision.PolicyID)
+	// Both matched policies contribute, highest priority first; the unmatched one does not
+	assert.Equal(t, []model.Obligation{
+		{ID: "require_mfa"},
+		{ID: "mask_field", Params: map[string]interface{}{"field": "ssn"}},
+		{ID: "log", Params: map[string]interface{}{"verbosity": "high"}},
+	}, decision.Obligations)
+}
+
+func TestAccessDecisionTrace(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	subjects := []model.Subject{{Type: "user", UserID: "u1"}}
+	allowFromOffice := &model.Policy{
+		ID:         "p1",
+		Name:       "Office reads",
+		Effect:     echo_neo4j.PolicyEffectAllow,
+		Active:     true,
+		Priority:   20,
+		Subjects:   subjects,
+		Actions:    []string{"read"},
+		Conditions: []model.Condition{{Attribute: "context.network", Operator: "equals", Value: "office"}},
+	}
+	denyContractors := &model.Policy{
+		ID:         "p2",
+		Name:       "No contractors",
+		Effect:     echo_neo4j.PolicyEffectDeny,
+		Active:     true,
+		Priority:   10,
+		Subjects:   subjects,
+		Actions:    []string{"read"},
+		Conditions: []model.Condition{{Attribute: "subject.user_type", Operator: "equals", Value: "contractor"}},
+	}
+	allowReads := &model.Policy{
+		ID:       "p3",
+		Name:     "Reads",
+		Effect:   echo_neo4j.PolicyEffectAllow,
+		Active:   true,
+		Priority: 10,
+		Subjects: subjects,
+		Actions:  []string{"read"},
+	}
+	writesOnly := &model.Policy{
+		ID:       "p4",
+		Name:     "Writes",
+		Effect:   echo_neo4j.PolicyEffectAllow,
+		Active:   true,
+		Subjects: subjects,
+		Actions:  []string{"write"},
+	}
+	policies := []*model.Policy{allowFromOffice, denyContractors, allowReads, writesOnly}
+	subject := &model.User{ID: "u1", UserType: "contractor", Status: model.UserStatusActive}
+	resource := &model.Resource{ID: "res1", Type: "DOCUMENT"}
+	request := model.AccessRequest{
+		SubjectID:  "u1",
+		ResourceID: "res1",
+		Action:     "read",
+		Context:    map[string]interface{}{"network": "home"},
+	}
+
+	t.Run("Off by default", func(t *testing.T) {
+		decision := service.EvaluatePolicies(policies, subject, resource, request, time.Now())
+
+		assert.False(t, decision.Allowed)
+		assert.Nil(t, decision.Trace)
+	})
+
+	t.Run("Explains the decision", func(t *testing.T) {
+		request := request
+		request.Explain = true
+
+		decision := service.EvaluatePolicies(policies, subject, resource, request, time.Now())
+
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, "p2", decision.PolicyID)
+		if assert.NotNil(t, decision.Trace) && assert.Len(t, decision.Trace.Policies, 4) {
+			office, contractors, reads, writes := decision.Trace.Policies[0], decision.Trace.Policies[1], decision.Trace.Policies[2], decision.Trace.Policies[3]
+
+			// The higher priority allow was skipped on its network condition
+			assert.Equal(t, "p1", office.PolicyID)
+			assert.False(t, office.Matched)
+			assert.False(t, office.Deciding)
+			assert.Equal(t, &allowFromOffice.Conditions[0], office.FailedCondition)
+			assert.Contains(t, office.Reason, "context.network")
+
+			// The deny overrode the allow at its priority
+			assert.Equal(t, "p2", contractors.PolicyID)
+			assert.True(t, contractors.Matched)
+			assert.True(t, contractors.Deciding)
+			assert.Equal(t, echo_neo4j.PolicyEffectDeny, contractors.Effect)
+			assert.True(t, reads.Matched)
+			assert.False(t, reads.Deciding)
+
+			assert.False(t, writes.Matched)
+			assert.Equal(t, "action is not covered", writes.Reason)
+			assert.Nil(t, writes.FailedCondition)
+		}
+	})
+}
+
+func TestDefaultEffect(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer service.SetDefaultEffect(echo_neo4j.PolicyEffectDeny)
+
+	// The only policy covers writes, so a read is matched by none
+	policies := []*model.Policy{{
+		ID:       "p1",
+		Name:     "Writes",
+		Effect:   echo_neo4j.PolicyEffectDeny,
+		Active:   true,
+		Subjects: []model.Subject{{Type: "user", UserID: "u1"}},
+		Actions:  []string{"write"},
+	}}
+	subject := &model.User{ID: "u1", Status: model.UserStatusActive}
+	resource := &model.Resource{ID: "res1", Type: "DOCUMENT"}
+	request := model.AccessRequest{SubjectID: "u1", ResourceID: "res1", Action: "read", Explain: true}
+
+	t.Run("Deny", func(t *testing.T) {
+		service.SetDefaultEffect("deny")
+
+		decision := service.EvaluatePolicies(policies, subject, resource, request, time.Now())
+
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, echo_neo4j.PolicyEffectDeny, decision.Effect)
+		assert.Empty(t, decision.PolicyID)
+		assert.Equal(t, "no matching policy, default effect is deny", decision.Reason)
+		if assert.NotNil(t, decision.Trace) && assert.Len(t, decision.Trace.Policies, 1) {
+			assert.False(t, decision.Trace.Policies[0].Matched)
+		}
+	})
+
+	t.Run("Allow", func(t *testing.T) {
+		service.SetDefaultEffect("allow")
+
+		decision := service.EvaluatePolicies(policies, subject, resource, request, time.Now())
+
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, echo_neo4j.PolicyEffectAllow, decision.Effect)
+		assert.Empty(t, decision.PolicyID)
+		assert.Equal(t, "no matching policy, default effect is allow", decision.Reason)
+		if assert.NotNil(t, decision.Trace) && assert.Len(t, decision.Trace.Policies, 1) {
+			assert.False(t, decision.Trace.Policies[0].Deciding)
+		}
+
+		// A matching deny still decides
+		write := request
+		write.Action = "write"
+		assert.False(t, service.EvaluatePolicies(policies, subject, resource, write, time.Now()).Allowed)
+	})
+
+	t.Run("Unknown effects keep the current one", func(t *testing.T) {
+		service.SetDefaultEffect("deny")
+		service.SetDefaultEffect("permit")
+
+		assert.False(t, service.EvaluatePolicies(policies, subject, resource, request, time.Now()).Allowed)
+	})
+}
@@ -0,0 +1,167 @@
+// api/service/admin_scope.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+// DefaultScopedAdminRole is the role whose holders only administer their scope unless configured otherwise
+const DefaultScopedAdminRole = "department-admin"
+
+var scopedAdminRole = DefaultScopedAdminRole
+
+// SetScopedAdminRole sets the role whose holders may only create, update, delete and list the users and
+// resources within the organization or department subtree they are scoped to. An empty role keeps the
+// current one.
+func SetScopedAdminRole(role string) {
+	if role != "" {
+		scopedAdminRole = role
+	}
+}
+
+// requestingAdminScope returns the scope of the requesting user when they are a scoped admin, and nil
+// when their reach is not limited by a scope: for global admins, users without the scoped admin role
+// and calls made outside a request. A scoped admin without a scope administers nothing, so gets
+// ErrForbidden.
+func requestingAdminScope(ctx context.Context, userDAO *dao.UserDAO) (*model.ScopedAdmin, error) {
+	requestingUserID := helper_util.ActorFromContext(ctx)
+	if requestingUserID == "" {
+		return nil, nil
+	}
+
+	scoped := false
+	roles, _ := ctx.Value("requestingRoles").([]string)
+	for _, role := range roles {
+		if role == globalAdminRole {
+			return nil, nil
+		}
+		if role == scopedAdminRole {
+			scoped = true
+		}
+	}
+	if !scoped {
+		return nil, nil
+	}
+
+	scope, err := userDAO.GetAdminScope(ctx, requestingUserID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrAdminScopeNotFound) {
+			logger.Warn("Scoped admin without a scope denied", zap.String("requestingUserID", requestingUserID))
+			return nil, echo_errors.ErrForbidden
+		}
+		logger.Error("Error retrieving admin scope", zap.Error(err), zap.String("requestingUserID", requestingUserID))
+		return nil, fmt.Errorf("failed to get admin scope: %w", err)
+	}
+	return scope, nil
+}
+
+// scopeCovers reports whether an entity of orgID, assigned to departmentID, lies within scope. A
+// department scope does not cover the entities assigned to no department.
+func scopeCovers(scope *model.ScopedAdmin, orgID string, departmentID string) bool {
+	if scope.OrganizationID != orgID {
+		return false
+	}
+	if scope.DepartmentID == "" {
+		return true
+	}
+	for _, id := range scope.DepartmentIDs {
+		if id == departmentID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAdminScope returns ErrForbidden when the requesting user is a scoped admin and the entity of
+// orgID, assigned to departmentID, lies outside their scope
+func checkAdminScope(ctx context.Context, userDAO *dao.UserDAO, orgID string, departmentID string) error {
+	scope, err := requestingAdminScope(ctx, userDAO)
+	if err != nil || scope == nil {
+		return err
+	}
+
+	if !scopeCovers(scope, orgID, departmentID) {
+		logger.Warn("Out of scope administration denied",
+			zap.String("organizationID", orgID),
+			zap.String("departmentID", departmentID),
+			zap.String("requestingUserID", scope.UserID),
+			zap.String("scopeOrganizationID", scope.OrganizationID),
+			zap.String("scopeDepartmentID", scope.DepartmentID))
+		return echo_errors.ErrForbidden
+	}
+	return nil
+}
+
+// SetAdminScope ties a user to the organization or department subtree they administer. Scoped admins
+// may not hand out scopes.
+func (s *UserService) SetAdminScope(ctx context.Context, scope model.ScopedAdmin, actorID string) (*model.ScopedAdmin, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if scope.OrganizationID == "" {
+		return nil, fmt.Errorf("%w: organization_id is required", echo_errors.ErrInvalidAdminScope)
+	}
+	if err := s.checkUnscopedAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.userDAO.SetAdminScope(ctx, scope, actorID); err != nil {
+		logger.Error("Error setting admin scope", zap.Error(err), zap.String("userID", scope.UserID), zap.String("actorID", actorID))
+		return nil, err
+	}
+
+	logger.Info("Admin scope set successfully", zap.String("userID", scope.UserID), zap.String("actorID", actorID))
+	return s.userDAO.GetAdminScope(ctx, scope.UserID)
+}
+
+// GetAdminScope returns the scope a user administers
+func (s *UserService) GetAdminScope(ctx context.Context, userID string) (*model.ScopedAdmin, error) {
+	return s.userDAO.GetAdminScope(ctx, userID)
+}
+
+// RemoveAdminScope unties a user from the scope they administer. Scoped admins may not take scopes away.
+func (s *UserService) RemoveAdminScope(ctx context.Context, userID string, actorID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
+	if err := s.checkUnscopedAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := s.userDAO.RemoveAdminScope(ctx, userID, actorID); err != nil {
+		logger.Error("Error removing admin scope", zap.Error(err), zap.String("userID", userID), zap.String("actorID", actorID))
+		return err
+	}
+
+	logger.Info("Admin scope removed successfully", zap.String("userID", userID), zap.String("actorID", actorID))
+	return nil
+}
+
+// checkUnscopedAdmin returns ErrForbidden for scoped admins, who would otherwise widen their own reach
+func (s *UserService) checkUnscopedAdmin(ctx context.Context) error {
+	roles, _ := ctx.Value("requestingRoles").([]string)
+	for _, role := range roles {
+		if role == globalAdminRole {
+			return nil
+		}
+	}
+	for _, role := range roles {
+		if role == scopedAdminRole {
+			return echo_errors.ErrForbidden
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// scopedSession answers the admin scope query with scope, or no scope when nil, and any other read with
+// record. Writes fail, so an operation either stops with ErrForbidden or reaches the database write.
+func scopedSession(scope []any, record []any) *mock.MockSession {
+	scopeResult := &mock.MockResult{}
+	if scope != nil {
+		scopeResult.On("Next").Return(true).Once()
+		scopeResult.On("Record").Return(&neo4j.Record{Keys: []string{"organizationID", "departmentID", "departmentIDs"}, Values: scope})
+	}
+	scopeResult.On("Next").Return(false)
+
+	result := &mock.MockResult{}
+	result.On("Next").Return(true).Once()
+	result.On("Next").Return(false)
+	result.On("Record").Return(&neo4j.Record{Values: record})
+
+	session := &mock.MockSession{}
+	session.On("Run", testify_mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, echo_neo4j.RelAdministers)
+	}), testify_mock.Anything, testify_mock.Anything).Return(scopeResult, nil)
+	session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+	session.On("WriteTransaction", testify_mock.Anything, testify_mock.Anything).Return(nil, errors.New("write failed"))
+	session.On("Close").Return(nil)
+	return session
+}
+
+func TestAdminScope(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	// u1 administers the sales department, which has emea below it
+	salesScope := []any{"org1", "sales", []any{"sales", "emea"}}
+
+	newResourceService := func(scope []any, departmentID string) (*service.ResourceService, *mock.MockSession) {
+		session := scopedSession(scope, []any{mock.ResourceNode("r1", map[string]any{
+			"departmentID": departmentID,
+			"ownerID":      "u2",
+			"createdBy":    "u2",
+			"updatedBy":    "u2",
+		}), nil, []any{}})
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return mock.NewResourceService(driver, nil), session
+	}
+
+	t.Run("DeleteWithinSubtreeIsAllowed", func(t *testing.T) {
+		resourceService, session := newResourceService(salesScope, "emea")
+
+		err := resourceService.DeleteResource(requestContext("org1", service.DefaultScopedAdminRole), "r1", "u1")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("DeleteOutsideSubtreeIsForbidden", func(t *testing.T) {
+		resourceService, session := newResourceService(salesScope, "engineering")
+
+		err := resourceService.DeleteResource(requestContext("org1", service.DefaultScopedAdminRole), "r1", "u1")
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("AdminWithoutScopeIsForbidden", func(t *testing.T) {
+		resourceService, session := newResourceService(nil, "sales")
+
+		err := resourceService.DeleteResource(requestContext("org1", service.DefaultScopedAdminRole), "r1", "u1")
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("GlobalAdminIsNotScoped", func(t *testing.T) {
+		resourceService, session := newResourceService(salesScope, "engineering")
+
+		err := resourceService.DeleteResource(requestContext("org1", service.DefaultScopedAdminRole, service.DefaultGlobalAdminRole), "r1", "u1")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("ExportByAdminWithoutScopeIsForbidden", func(t *testing.T) {
+		resourceService, _ := newResourceService(nil, "sales")
+
+		err := resourceService.ExportResources(requestContext("org1", service.DefaultScopedAdminRole), model.ResourceSearchCriteria{}, func(*model.Resource) error {
+			return nil
+		})
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+	})
+
+	// newScopedSearch serves the admin scope of salesScope and finds no resources, so the criteria the
+	// resource queries run with can be inspected
+	newScopedSearch := func() (*service.ResourceService, *mock.FakeDriver) {
+		driver := mock.NewFakeDriver().Returns(echo_neo4j.RelAdministers, &neo4j.Record{
+			Keys:   []string{"organizationID", "departmentID", "departmentIDs"},
+			Values: salesScope,
+		})
+		return mock.NewResourceService(driver, nil), driver
+	}
+
+	t.Run("SearchIsNarrowedToSubtree", func(t *testing.T) {
+		resourceService, driver := newScopedSearch()
+
+		_, err := resourceService.SearchResources(requestContext("org1", service.DefaultScopedAdminRole), model.ResourceSearchCriteria{Name: "report", Limit: 10})
+
+		assert.NoError(t, err)
+		queries := driver.QueriesContaining("sd.id IN $departmentIds")
+		if assert.Len(t, queries, 1) {
+			assert.Equal(t, "org1", queries[0].Params["organizationId"])
+			assert.Equal(t, []string{"sales", "emea"}, queries[0].Params["departmentIds"])
+		}
+	})
+
+	t.Run("SearchOutsideSubtreeIsForbidden", func(t *testing.T) {
+		resourceService, driver := newScopedSearch()
+
+		_, err := resourceService.SearchResources(requestContext("org1", service.DefaultScopedAdminRole), model.ResourceSearchCriteria{DepartmentID: "engineering", Limit: 10})
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		assert.Empty(t, driver.QueriesContaining("MATCH (r:"+echo_neo4j.LabelResource+")"))
+	})
+
+	t.Run("ExportIsNarrowedToSubtree", func(t *testing.T) {
+		resourceService, driver := newScopedSearch()
+
+		err := resourceService.ExportResources(requestContext("org1", service.DefaultScopedAdminRole), model.ResourceSearchCriteria{Name: "report"}, func(*model.Resource) error {
+			return nil
+		})
+
+		assert.NoError(t, err)
+		queries := driver.QueriesContaining("sd.id IN $departmentIds")
+		if assert.Len(t, queries, 1) {
+			assert.Contains(t, queries[0].Cypher, "r.createdAt < $afterCreatedAt")
+			assert.Equal(t, "report", queries[0].Params["name"])
+			assert.Equal(t, []string{"sales", "emea"}, queries[0].Params["departmentIds"])
+		}
+	})
+
+	t.Run("ExportOutsideSubtreeIsForbidden", func(t *testing.T) {
+		resourceService, driver := newScopedSearch()
+
+		err := resourceService.ExportResources(requestContext("org1", service.DefaultScopedAdminRole), model.ResourceSearchCriteria{DepartmentID: "engineering"}, func(*model.Resource) error {
+			return nil
+		})
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		assert.Empty(t, driver.QueriesContaining("MATCH (r:"+echo_neo4j.LabelResource+")"))
+	})
+
+	t.Run("BulkTaggingIsNarrowedToSubtree", func(t *testing.T) {
+		resourceService, driver := newScopedSearch()
+
+		_, err := resourceService.BulkTagResources(requestContext("org1", service.DefaultScopedAdminRole), model.ResourceSearchCriteria{Name: "report"}, []string{"archived"}, "u1")
+
+		assert.NoError(t, err)
+		queries := driver.QueriesContaining("SET r.tags")
+		if assert.Len(t, queries, 1) {
+			assert.Contains(t, queries[0].Cypher, "sd.id IN $departmentIds")
+			assert.Equal(t, []string{"sales", "emea"}, queries[0].Params["departmentIds"])
+		}
+	})
+
+	t.Run("BulkTaggingInAnotherOrgIsForbidden", func(t *testing.T) {
+		resourceService, driver := newScopedSearch()
+
+		_, err := resourceService.BulkTagResources(requestContext("org1", service.DefaultScopedAdminRole), model.ResourceSearchCriteria{OrganizationID: "org2"}, []string{"archived"}, "u1")
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		assert.Empty(t, driver.QueriesContaining("SET r.tags"))
+	})
+
+	t.Run("UserOutsideSubtreeIsForbidden", func(t *testing.T) {
+		session := scopedSession(salesScope, []any{neo4j.Node{Props: map[string]any{
+			"id":             "u2",
+			"name":           "Jane",
+			"username":       "jane",
+			"email":          "jane@example.com",
+			"userType":       "employee",
+			"organizationID": "org1",
+			"departmentID":   "engineering",
+			"attributes":     "{}",
+			"createdAt":      "2024-01-01T00:00:00Z",
+			"updatedAt":      "2024-01-01T00:00:00Z",
+		}}, []any{}})
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		userService := service.NewUserService(
+			&dao.UserDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+			nil,
+			nil,
+			util.NewValidationUtil(),
+			nil,
+			nil,
+			util.NewEventBus(),
+		)
+
+		err := userService.DeleteUser(requestContext("org1", service.DefaultScopedAdminRole), "u2", "u1")
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("BulkRoleAssignmentOutsideSubtreeIsForbidden", func(t *testing.T) {
+		session := scopedSession(salesScope, []any{neo4j.Node{Props: map[string]any{
+			"id":             "u2",
+			"name":           "Jane",
+			"username":       "jane",
+			"email":          "jane@example.com",
+			"userType":       "employee",
+			"organizationID": "org1",
+			"departmentID":   "engineering",
+			"attributes":     "{}",
+			"createdAt":      "2024-01-01T00:00:00Z",
+			"updatedAt":      "2024-01-01T00:00:00Z",
+		}}, []any{}})
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		roleService := service.NewRoleService(
+			&dao.RoleDAO{Driver: driver, AuditService: auditService},
+			&dao.UserDAO{Driver: driver, AuditService: auditService},
+			&dao.SoDRuleDAO{Driver: driver, AuditService: auditService},
+			util.NewValidationUtil(),
+			nil,
+			nil,
+			util.NewEventBus(),
+		)
+
+		_, err := roleService.AssignRoleToUsers(requestContext("org1", service.DefaultScopedAdminRole), "editor", []string{"u2"}, "u1")
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+}
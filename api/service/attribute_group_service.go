@@ -10,19 +10,22 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IAttributeGroupService defines the interface for attribute group operations
 type IAttributeGroupService interface {
 	CreateAttributeGroup(ctx context.Context, attributeGroup model.AttributeGroup, creatorID string) (*model.AttributeGroup, error)
 	UpdateAttributeGroup(ctx context.Context, attributeGroup model.AttributeGroup, updaterID string) (*model.AttributeGroup, error)
-	DeleteAttributeGroup(ctx context.Context, attributeGroupID string, deleterID string) error
+	DeleteAttributeGroup(ctx context.Context, attributeGroupID string, deleterID string, force bool) error
 	GetAttributeGroup(ctx context.Context, attributeGroupID string) (*model.AttributeGroup, error)
 	ListAttributeGroups(ctx context.Context, limit int, offset int) ([]*model.AttributeGroup, error)
+	SearchAttributeGroups(ctx context.Context, criteria model.AttributeGroupSearchCriteria) ([]*model.AttributeGroup, int64, error)
 }
 
 // AttributeGroupService handles business logic for attribute group operations
@@ -107,6 +110,10 @@ func (s *AttributeGroupService) handleAttributeGroupDeleted(ctx context.Context,
 
 // CreateAttributeGroup handles the creation of a new attribute group
 func (s *AttributeGroupService) CreateAttributeGroup(ctx context.Context, attributeGroup model.AttributeGroup, creatorID string) (*model.AttributeGroup, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateAttributeGroup(attributeGroup); err != nil {
 		logger.Error("Validation for attribute group data failed", zap.Error(err))
 		return nil, fmt.Errorf("invalid attribute group: %w", err)
@@ -139,6 +146,10 @@ func (s *AttributeGroupService) CreateAttributeGroup(ctx context.Context, attrib
 
 // UpdateAttributeGroup handles updates to an existing attribute group
 func (s *AttributeGroupService) UpdateAttributeGroup(ctx context.Context, attributeGroup model.AttributeGroup, updaterID string) (*model.AttributeGroup, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateAttributeGroup(attributeGroup); err != nil {
 		logger.Error("Validation for attribute group data failed", zap.Error(err))
 		return nil, fmt.Errorf("invalid attribute group: %w", err)
@@ -174,11 +185,19 @@ func (s *AttributeGroupService) UpdateAttributeGroup(ctx context.Context, attrib
 	return updatedAttributeGroup, nil
 }
 
-// DeleteAttributeGroup handles the deletion of an attribute group
-func (s *AttributeGroupService) DeleteAttributeGroup(ctx context.Context, attributeGroupID string, deleterID string) error {
-	err := s.attributeGroupDAO.DeleteAttributeGroup(ctx, attributeGroupID)
+// DeleteAttributeGroup handles the deletion of an attribute group.
+// Groups still referenced by resources are only deleted when force is set.
+func (s *AttributeGroupService) DeleteAttributeGroup(ctx context.Context, attributeGroupID string, deleterID string, force bool) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
+	err := s.attributeGroupDAO.DeleteAttributeGroup(ctx, attributeGroupID, force)
 	if err != nil {
 		logger.Error("Error deleting attribute group", zap.Error(err), zap.String("attributeGroupID", attributeGroupID), zap.String("deleterID", deleterID))
+		if errors.Is(err, echo_errors.ErrAttributeGroupNotFound) || errors.Is(err, echo_errors.ErrAttributeGroupInUse) {
+			return err
+		}
 		return fmt.Errorf("failed to delete attribute group: %w", err)
 	}
 
@@ -198,7 +217,9 @@ func (s *AttributeGroupService) DeleteAttributeGroup(ctx context.Context, attrib
 func (s *AttributeGroupService) GetAttributeGroup(ctx context.Context, attributeGroupID string) (*model.AttributeGroup, error) {
 	// Try to get from cache first
 	cachedAttributeGroup, err := s.cacheService.GetAttributeGroup(ctx, attributeGroupID)
-	if err == nil && cachedAttributeGroup != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindAttributeGroup, attributeGroupID, err)
+	} else if cachedAttributeGroup != nil {
 		return cachedAttributeGroup, nil
 	}
 
@@ -221,6 +242,11 @@ func (s *AttributeGroupService) GetAttributeGroup(ctx context.Context, attribute
 
 // ListAttributeGroups retrieves all attribute groups, possibly with pagination
 func (s *AttributeGroupService) ListAttributeGroups(ctx context.Context, limit int, offset int) ([]*model.AttributeGroup, error) {
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	attributeGroups, err := s.attributeGroupDAO.ListAttributeGroups(ctx, limit, offset)
 	if err != nil {
 		logger.Error("Error listing attribute groups", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
@@ -230,6 +256,50 @@ func (s *AttributeGroupService) ListAttributeGroups(ctx context.Context, limit i
 	return attributeGroups, nil
 }
 
+// SearchAttributeGroups searches for attribute groups based on criteria and reports the total match count
+func (s *AttributeGroupService) SearchAttributeGroups(ctx context.Context, criteria model.AttributeGroupSearchCriteria) ([]*model.AttributeGroup, int64, error) {
+	logger.Info("Searching attribute groups", zap.Any("criteria", criteria))
+
+	limit, offset, err := helper_util.ClampPagination(criteria.Limit, criteria.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	criteria.Limit, criteria.Offset = limit, offset
+
+	attributeGroups, total, err := s.attributeGroupDAO.SearchAttributeGroups(ctx, criteria)
+	if err != nil {
+		logger.Error("Error searching attribute groups",
+			zap.Error(err),
+			zap.Any("criteria", criteria))
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) {
+			return nil, 0, err
+		}
+		return nil, 0, fmt.Errorf("failed to search attribute groups: %w", err)
+	}
+
+	logger.Info("Attribute groups search completed", zap.Int("attributeGroupCount", len(attributeGroups)), zap.Int64("total", total))
+	return attributeGroups, total, nil
+}
+
+// validateAttributesForGroup loads the referenced attribute group and checks attrs against its schema.
+// An empty attributeGroupID means the entity is not governed by a schema.
+func validateAttributesForGroup(ctx context.Context, attributeGroupDAO *dao.AttributeGroupDAO, validationUtil *util.ValidationUtil, attributeGroupID string, attrs map[string]interface{}) error {
+	if attributeGroupID == "" {
+		return nil
+	}
+
+	attributeGroup, err := attributeGroupDAO.GetAttributeGroup(ctx, attributeGroupID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrAttributeGroupNotFound) {
+			return echo_errors.ErrAttributeGroupNotFound
+		}
+		logger.Error("Error retrieving attribute group for validation", zap.Error(err), zap.String("attributeGroupID", attributeGroupID))
+		return echo_errors.ErrDatabaseOperation
+	}
+
+	return validationUtil.ValidateAttributesAgainstGroup(attrs, *attributeGroup)
+}
+
 // Helper methods
 
 func (s *AttributeGroupService) invalidateRelatedCaches(ctx context.Context, attributeGroupID string) error {
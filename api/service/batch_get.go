@@ -0,0 +1,98 @@
+// api/service/batch_get.go
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// MaxBatchGetIDs is the most IDs a single batch get may ask for
+const MaxBatchGetIDs = 100
+
+// batchSource tells batchGet where the entities of one kind are cached and stored
+type batchSource[T any] struct {
+	kind    string // Such as "policy", for logging
+	idOf    func(*T) string
+	cached  func(context.Context, []string) (map[string]*T, error)
+	load    func(context.Context, []string) ([]*T, error)
+	store   func(context.Context, ...interface{}) error // Caches the loaded entities in one round trip
+	visible func(*T) bool                               // Nil when callers may see every entity
+}
+
+// batchGet fetches the entities of ids, taking what it can from the cache and loading only the misses
+// from the database in one query, then caching them. Items keep the order of ids, with repeats and empty
+// IDs dropped; IDs with no entity, or with one visible rejects, are returned as missing, so a batch get
+// reveals no more than fetching the entities one at a time would.
+func batchGet[T any](ctx context.Context, source batchSource[T], ids []string) ([]*T, []string, error) {
+	ids = distinctIDs(ids)
+	if len(ids) > MaxBatchGetIDs {
+		return nil, nil, fmt.Errorf("%w: %d IDs requested, at most %d allowed", echo_errors.ErrInvalidSearchCriteria, len(ids), MaxBatchGetIDs)
+	}
+	if len(ids) == 0 {
+		return []*T{}, []string{}, nil
+	}
+
+	found, err := source.cached(ctx, ids)
+	if err != nil {
+		logger.Warn("Batch cache lookup failed, loading every ID", zap.Error(err), zap.String("kind", source.kind))
+		found = map[string]*T{}
+	}
+
+	var misses []string
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			misses = append(misses, id)
+		}
+	}
+	if len(misses) > 0 {
+		loaded, err := source.load(ctx, misses)
+		if err != nil {
+			logger.Error("Error loading batch", zap.Error(err), zap.String("kind", source.kind), zap.Int("count", len(misses)))
+			return nil, nil, err
+		}
+		entities := make([]interface{}, len(loaded))
+		for i, entity := range loaded {
+			found[source.idOf(entity)] = entity
+			entities[i] = entity
+		}
+		if err := source.store(ctx, entities...); err != nil {
+			logger.Warn("Failed to cache batch", zap.Error(err), zap.String("kind", source.kind), zap.Int("count", len(loaded)))
+		}
+	}
+
+	items := make([]*T, 0, len(ids))
+	missing := []string{}
+	for _, id := range ids {
+		entity, ok := found[id]
+		if !ok || (source.visible != nil && !source.visible(entity)) {
+			missing = append(missing, id)
+			continue
+		}
+		items = append(items, entity)
+	}
+
+	logger.Info("Batch retrieved",
+		zap.String("kind", source.kind),
+		zap.Int("requested", len(ids)),
+		zap.Int("cacheHits", len(ids)-len(misses)),
+		zap.Int("missing", len(missing)))
+	return items, missing, nil
+}
+
+// distinctIDs drops empty and repeated IDs, keeping the first occurrence of the others in order
+func distinctIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	distinct := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	return distinct
+}
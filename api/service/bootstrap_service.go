@@ -0,0 +1,134 @@
+// api/service/bootstrap_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+)
+
+// BootstrapConfig describes the root organization, admin role and admin user a fresh deployment
+// starts with. AdminUserID must be the admin's identity provider subject so their token maps to the
+// created user.
+type BootstrapConfig struct {
+	OrganizationID   string
+	OrganizationName string
+	AdminRoleID      string
+	AdminRoleName    string
+	AdminUserID      string
+	AdminUsername    string
+	AdminName        string
+	AdminEmail       string
+}
+
+// BootstrapResult lists the entities a bootstrap run created, e.g. "organization root". It is empty
+// when everything already existed.
+type BootstrapResult struct {
+	Created []string `json:"created"`
+}
+
+// BootstrapService seeds an empty graph with what is needed to start administering it
+type BootstrapService struct {
+	orgDAO        *dao.OrganizationDAO
+	permissionDAO *dao.PermissionDAO
+	roleDAO       *dao.RoleDAO
+	userDAO       *dao.UserDAO
+}
+
+// NewBootstrapService creates a new instance of BootstrapService
+func NewBootstrapService(orgDAO *dao.OrganizationDAO, permissionDAO *dao.PermissionDAO, roleDAO *dao.RoleDAO, userDAO *dao.UserDAO) *BootstrapService {
+	return &BootstrapService{
+		orgDAO:        orgDAO,
+		permissionDAO: permissionDAO,
+		roleDAO:       roleDAO,
+		userDAO:       userDAO,
+	}
+}
+
+// Bootstrap creates the root organization, a permission allowing every action, an admin role holding
+// it and the admin user. Entities are looked up by their configured IDs and only created when
+// missing, so running it again changes nothing.
+func (s *BootstrapService) Bootstrap(ctx context.Context, cfg BootstrapConfig) (*BootstrapResult, error) {
+	var missing []string
+	for _, setting := range []struct{ key, value string }{
+		{"organization_id", cfg.OrganizationID},
+		{"admin_role_id", cfg.AdminRoleID},
+		{"admin_user_id", cfg.AdminUserID},
+		{"admin_username", cfg.AdminUsername},
+		{"admin_email", cfg.AdminEmail},
+	} {
+		if setting.value == "" {
+			missing = append(missing, "bootstrap."+setting.key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("bootstrap configuration incomplete, set %s", strings.Join(missing, ", "))
+	}
+
+	result := &BootstrapResult{Created: []string{}}
+	permissionID := cfg.AdminRoleID + "-all"
+
+	steps := []struct {
+		entity string
+		id     string
+		exists func(context.Context, string) (bool, error)
+		create func() (string, error)
+	}{
+		{"organization", cfg.OrganizationID, s.orgDAO.Exists, func() (string, error) {
+			return s.orgDAO.CreateOrganization(ctx, model.Organization{ID: cfg.OrganizationID, Name: cfg.OrganizationName})
+		}},
+		{"permission", permissionID, s.permissionDAO.Exists, func() (string, error) {
+			return s.permissionDAO.CreatePermission(ctx, model.Permission{
+				ID:          permissionID,
+				Name:        "All actions",
+				Description: "Allows every action; held by the bootstrap admin role",
+				Action:      "*",
+			})
+		}},
+		{"role", cfg.AdminRoleID, s.roleDAO.Exists, func() (string, error) {
+			return s.roleDAO.CreateRole(ctx, model.Role{
+				ID:             cfg.AdminRoleID,
+				Name:           cfg.AdminRoleName,
+				Description:    "Administers the deployment",
+				OrganizationID: cfg.OrganizationID,
+				Permissions:    []string{permissionID},
+			})
+		}},
+		{"user", cfg.AdminUserID, s.userDAO.Exists, func() (string, error) {
+			return s.userDAO.CreateUser(ctx, model.User{
+				ID:             cfg.AdminUserID,
+				Name:           cfg.AdminName,
+				Username:       cfg.AdminUsername,
+				Email:          cfg.AdminEmail,
+				UserType:       "CorporateAdmin",
+				OrganizationID: cfg.OrganizationID,
+				RoleIds:        []string{cfg.AdminRoleID},
+				Status:         model.UserStatusActive,
+			})
+		}},
+	}
+
+	for _, step := range steps {
+		exists, err := step.exists(ctx, step.id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up bootstrap %s: %w", step.entity, err)
+		}
+		if exists {
+			logger.Info("Bootstrap entity already exists", zap.String("entity", step.entity), zap.String("id", step.id))
+			continue
+		}
+		if _, err := step.create(); err != nil {
+			return nil, fmt.Errorf("failed to create bootstrap %s: %w", step.entity, err)
+		}
+		result.Created = append(result.Created, step.entity+" "+step.id)
+	}
+
+	logger.Info("Bootstrap completed", zap.Strings("created", result.Created))
+	return result, nil
+}
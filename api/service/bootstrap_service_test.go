@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestBootstrapIsIdempotent(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "bootstrap")
+
+	// The graph remembers the IDs written, so existence checks see what earlier writes created
+	created := map[string]int{}
+	written := &mock.MockResult{}
+	tx := &mock.MockTransaction{}
+	tx.On("Run", testify_mock.Anything, testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) {
+			id := args.Get(1).(map[string]any)["id"].(string)
+			created[id]++
+			written.ExpectedCalls = nil
+			written.On("Next").Return(true).Once()
+			written.On("Record").Return(&neo4j.Record{Keys: []string{"id"}, Values: []any{id}})
+		}).Return(written, nil)
+	session := &mock.MockTxSession{Tx: tx}
+	session.On("Close").Return(nil)
+	lookedUp := &mock.MockResult{}
+	session.On("Run", testify_mock.MatchedBy(func(query string) bool { return strings.Contains(query, "AS found") }), testify_mock.Anything, testify_mock.Anything).
+		Run(func(args testify_mock.Arguments) {
+			found := created[args.Get(1).(map[string]any)["id"].(string)] > 0
+			lookedUp.ExpectedCalls = nil
+			lookedUp.On("Next").Return(true).Once()
+			lookedUp.On("Record").Return(&neo4j.Record{Values: []any{found}}).Once()
+			lookedUp.On("Next").Return(false)
+		}).Return(lookedUp, nil)
+	empty := &mock.MockResult{}
+	empty.On("Next").Return(false)
+	session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(empty, nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	auditService := &mock.MockAuditService{}
+	auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+
+	bootstrapService := service.NewBootstrapService(
+		&dao.OrganizationDAO{Driver: driver, AuditService: auditService},
+		&dao.PermissionDAO{Driver: driver, AuditService: auditService},
+		&dao.RoleDAO{Driver: driver, AuditService: auditService},
+		&dao.UserDAO{Driver: driver, AuditService: auditService},
+	)
+	cfg := service.BootstrapConfig{
+		OrganizationID:   "root",
+		OrganizationName: "Root Organization",
+		AdminRoleID:      "admin",
+		AdminRoleName:    "Administrator",
+		AdminUserID:      "sub-123",
+		AdminUsername:    "admin",
+		AdminName:        "Administrator",
+		AdminEmail:       "admin@example.com",
+	}
+
+	first, err := bootstrapService.Bootstrap(ctx, cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"organization root", "permission admin-all", "role admin", "user sub-123"}, first.Created)
+
+	second, err := bootstrapService.Bootstrap(ctx, cfg)
+	assert.NoError(t, err)
+	assert.Empty(t, second.Created)
+	assert.Equal(t, map[string]int{"root": 1, "admin-all": 1, "admin": 1, "sub-123": 1}, created)
+
+	t.Run("IncompleteConfig", func(t *testing.T) {
+		cfg := cfg
+		cfg.AdminUserID, cfg.AdminEmail = "", ""
+
+		_, err := bootstrapService.Bootstrap(ctx, cfg)
+
+		assert.EqualError(t, err, "bootstrap configuration incomplete, set bootstrap.admin_user_id, bootstrap.admin_email")
+	})
+}
@@ -0,0 +1,23 @@
+// api/service/cache_read.go
+package service
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// logCacheReadError records a cache read that failed, whether Redis was unreachable or the entry did not
+// decode. Read-through callers then load the entity from the database, so a cache error never fails a
+// request the database can serve. Reads skipped by the open circuit breaker are expected and only
+// logged at debug level.
+func logCacheReadError(kind string, id string, err error) {
+	if errors.Is(err, echo_errors.ErrCacheUnavailable) {
+		logger.Debug("Cache skipped, reading from the database", zap.String("kind", kind), zap.String("id", id))
+		return
+	}
+	logger.Warn("Cache read failed, reading from the database", zap.Error(err), zap.String("kind", kind), zap.String("id", id))
+}
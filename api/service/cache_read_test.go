@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// recordDriver answers every read with record
+func recordDriver(record *neo4j.Record) *mock.MockDriver {
+	result := &mock.MockResult{}
+	result.On("Next").Return(true).Once()
+	result.On("Next").Return(false)
+	result.On("Record").Return(record)
+	session := &mock.MockSession{}
+	session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	return driver
+}
+
+func TestReadThroughCacheErrors(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	// Nothing listens on port 1, so every cache read fails with a connection error
+	previousClient := db.RedisClient
+	db.RedisClient = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer func() { db.RedisClient = previousClient }()
+
+	caches := map[string]func(t *testing.T) *util.CacheService{
+		"UnreachableRedis": func(t *testing.T) *util.CacheService {
+			return util.NewCacheService(nil)
+		},
+		"OpenCircuitBreaker": func(t *testing.T) *util.CacheService {
+			cacheService := util.NewCacheService(util.NewCircuitBreaker("redis", util.CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Hour}))
+			_, err := cacheService.GetPolicy(context.Background(), "trip")
+			require.Error(t, err)
+			_, err = cacheService.GetPolicy(context.Background(), "trip")
+			require.ErrorIs(t, err, echo_errors.ErrCacheUnavailable)
+			return cacheService
+		},
+	}
+
+	for name, newCache := range caches {
+		t.Run(name, func(t *testing.T) {
+			t.Run("Policy", func(t *testing.T) {
+				driver := recordDriver(&neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+					"id":                "p1",
+					"name":              "Docs",
+					"description":       "Docs policy",
+					"effect":            echo_neo4j.PolicyEffectAllow,
+					"priority":          int64(1),
+					"version":           int64(1),
+					"createdAt":         "2024-01-01T00:00:00Z",
+					"updatedAt":         "2024-01-01T00:00:00Z",
+					"active":            true,
+					"subjects":          "[]",
+					"resourceTypes":     "[]",
+					"attributeGroups":   "[]",
+					"actions":           "[]",
+					"conditions":        "[]",
+					"dynamicAttributes": "[]",
+				}}}})
+				policyService := service.NewPolicyService(
+					&dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+					nil,
+					nil,
+					util.NewValidationUtil(),
+					newCache(t),
+					nil,
+					util.NewEventBus(),
+					nil,
+				)
+
+				policy, err := policyService.GetPolicy(context.Background(), "p1")
+
+				require.NoError(t, err)
+				assert.Equal(t, "Docs", policy.Name)
+			})
+
+			t.Run("Resource", func(t *testing.T) {
+				driver := recordDriver(&neo4j.Record{
+					Keys: []string{"r", "parentID", "relatedIDs"},
+					Values: []any{mock.ResourceNode("r1", map[string]any{
+						"ownerID":   "u2",
+						"createdBy": "u2",
+						"updatedBy": "u2",
+					}), nil, []any{}},
+				})
+				resourceService := mock.NewResourceService(driver, newCache(t))
+
+				resource, err := resourceService.GetResource(context.Background(), "r1")
+
+				require.NoError(t, err)
+				assert.Equal(t, "Quarterly Report", resource.Name)
+			})
+
+			t.Run("Department", func(t *testing.T) {
+				driver := recordDriver(&neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+					"id":             "d1",
+					"name":           "Sales",
+					"organizationID": "org1",
+					"createdAt":      "2024-01-01T00:00:00Z",
+					"updatedAt":      "2024-01-01T00:00:00Z",
+				}}}})
+				departmentService := service.NewDepartmentService(
+					&dao.DepartmentDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+					util.NewValidationUtil(),
+					newCache(t),
+					nil,
+					util.NewEventBus(),
+				)
+
+				department, err := departmentService.GetDepartment(context.Background(), "d1")
+
+				require.NoError(t, err)
+				assert.Equal(t, "Sales", department.Name)
+			})
+
+			t.Run("User", func(t *testing.T) {
+				driver := recordDriver(&neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+					"id":             "u1",
+					"name":           "Jane",
+					"username":       "jane",
+					"email":          "jane@example.com",
+					"userType":       "employee",
+					"organizationID": "org1",
+					"departmentID":   "d1",
+					"attributes":     "{}",
+					"createdAt":      "2024-01-01T00:00:00Z",
+					"updatedAt":      "2024-01-01T00:00:00Z",
+				}}, []any{"r1"}}})
+				userService := service.NewUserService(
+					&dao.UserDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+					nil,
+					nil,
+					util.NewValidationUtil(),
+					newCache(t),
+					nil,
+					util.NewEventBus(),
+				)
+
+				user, err := userService.GetUser(context.Background(), "u1")
+
+				require.NoError(t, err)
+				assert.Equal(t, "Jane", user.Name)
+				assert.Equal(t, []string{"r1"}, user.RoleIds)
+			})
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// api/service/change_feed_service.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// MaxChangeFeedWait is the longest a change feed read may wait for a change to arrive
+const MaxChangeFeedWait = 30 * time.Second
+
+// changeFeedPollInterval is how often a waiting read checks for changes recorded by other instances
+const changeFeedPollInterval = time.Second
+
+// changeFeedEntities are the entities whose created, updated and deleted events are recorded in the
+// change feed
+var changeFeedEntities = []string{
+	"policy", "resource", "resourceType", "attributeGroup", "organization",
+	"department", "user", "role", "group", "permission",
+}
+
+type IChangeFeedService interface {
+	GetChanges(ctx context.Context, since string, limit int, wait time.Duration) (*model.ChangeFeedPage, error)
+}
+
+// ChangeFeedService records every entity change published on the event bus in an ordered feed that
+// clients tail with a cursor instead of polling each entity
+type ChangeFeedService struct {
+	changeEventDAO *dao.ChangeEventDAO
+
+	mu       sync.Mutex
+	recorded chan struct{} // Closed, and replaced, whenever a change is recorded
+}
+
+var _ IChangeFeedService = &ChangeFeedService{}
+
+// NewChangeFeedService creates a new instance of ChangeFeedService
+func NewChangeFeedService(changeEventDAO *dao.ChangeEventDAO, eventBus *util.EventBus) *ChangeFeedService {
+	service := &ChangeFeedService{
+		changeEventDAO: changeEventDAO,
+		recorded:       make(chan struct{}),
+	}
+
+	for _, entity := range changeFeedEntities {
+		for _, action := range []string{"created", "updated", "deleted"} {
+			eventBus.Subscribe(entity+"."+action, service.recordChange)
+		}
+	}
+	eventBus.Subscribe("department.moved", service.recordChange)
+
+	return service
+}
+
+// recordChange appends the change an event announces to the feed. Handlers run after the request that
+// published the event, whose context may be gone, so the change is recorded without it.
+func (s *ChangeFeedService) recordChange(ctx context.Context, event util.Event) error {
+	entityType, action, _ := strings.Cut(event.Type, ".")
+	change := model.ChangeEvent{
+		EntityType: entityType,
+		EntityID:   changedEntityID(event.Payload),
+		Action:     action,
+		Timestamp:  event.OccurredAt,
+		Actor:      event.Actor,
+	}
+	if _, err := s.changeEventDAO.RecordChange(context.Background(), change); err != nil {
+		return fmt.Errorf("failed to record %s in the change feed: %w", event.Type, err)
+	}
+
+	s.mu.Lock()
+	close(s.recorded)
+	s.recorded = make(chan struct{})
+	s.mu.Unlock()
+	return nil
+}
+
+// GetChanges returns up to limit changes following the since cursor, or from the start of the feed
+// when since is empty. With no such change yet it waits up to wait, capped at MaxChangeFeedWait, for
+// one to be recorded. The returned NextCursor is since itself when nothing changed.
+func (s *ChangeFeedService) GetChanges(ctx context.Context, since string, limit int, wait time.Duration) (*model.ChangeFeedPage, error) {
+	var after int64
+	if since != "" {
+		parsed, err := strconv.ParseInt(since, 10, 64)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("%w: malformed cursor", echo_errors.ErrInvalidSearchCriteria)
+		}
+		after = parsed
+	}
+	if wait > MaxChangeFeedWait {
+		wait = MaxChangeFeedWait
+	}
+	deadline := time.Now().Add(wait)
+
+	for {
+		// Taken before reading so a change recorded meanwhile still ends the wait
+		s.mu.Lock()
+		recorded := s.recorded
+		s.mu.Unlock()
+
+		changes, err := s.changeEventDAO.ListChangesSince(ctx, after, limit)
+		if err != nil {
+			logger.Error("Error listing changes", zap.Error(err), zap.Int64("after", after))
+			return nil, err
+		}
+		remaining := time.Until(deadline)
+		if len(changes) > 0 || remaining <= 0 {
+			page := &model.ChangeFeedPage{Items: changes, NextCursor: strconv.FormatInt(after, 10)}
+			if len(changes) > 0 {
+				page.NextCursor = strconv.FormatInt(changes[len(changes)-1].Sequence, 10)
+			}
+			return page, nil
+		}
+
+		timer := time.NewTimer(min(remaining, changeFeedPollInterval))
+		select {
+		case <-recorded:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		timer.Stop()
+	}
+}
+
+// changedEntityID finds the ID of the entity an event is about. Deletes carry the ID itself, creates
+// the entity, updates the entity before and after as old and new, and moves the department as deptID.
+func changedEntityID(payload interface{}) string {
+	if id, ok := payload.(string); ok {
+		return id
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	var fields struct {
+		ID     string `json:"id"`
+		DeptID string `json:"deptID"`
+		New    struct {
+			ID string `json:"id"`
+		} `json:"new"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	switch {
+	case fields.ID != "":
+		return fields.ID
+	case fields.New.ID != "":
+		return fields.New.ID
+	default:
+		return fields.DeptID
+	}
+}
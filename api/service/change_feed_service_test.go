@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// changeFeedResult returns a result holding one record per value, each in its own column 0
+func changeFeedResult(values ...interface{}) *mock.MockResult {
+	result := &mock.MockResult{}
+	for _, value := range values {
+		result.On("Next").Return(true).Once()
+		result.On("Record").Return(&neo4j.Record{Values: []interface{}{value}}).Once()
+	}
+	result.On("Next").Return(false)
+	return result
+}
+
+func TestChangeFeedService(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	tx := &mock.MockTransaction{}
+	session := &mock.MockTxSession{Tx: tx}
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+
+	// Stands in for the ChangeEvent nodes, numbered as the ChangeFeed node would
+	var mu sync.Mutex
+	var stored []neo4j.Node
+	recordResult := &mock.MockResult{}
+	recordResult.On("Next").Return(true)
+	recordResult.On("Record").Return(&neo4j.Record{Values: []interface{}{int64(0)}})
+	tx.On("Run", testify_mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "ChangeFeed")
+	}), testify_mock.Anything).Return(recordResult, nil).Run(func(args testify_mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		props := map[string]interface{}{"sequence": int64(len(stored) + 1)}
+		for key, value := range args.Get(1).(map[string]interface{})["props"].(map[string]interface{}) {
+			props[key] = value
+		}
+		stored = append(stored, neo4j.Node{Props: props})
+	})
+	storedCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(stored)
+	}
+
+	eventBus := util.NewEventBus()
+	changeFeedService := service.NewChangeFeedService(&dao.ChangeEventDAO{Driver: driver}, eventBus)
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	t.Run("RecordsCreatesInOrder", func(t *testing.T) {
+		eventBus.Publish(ctx, "policy.created", model.Policy{ID: "p1", Name: "Docs readers"})
+		assert.Eventually(t, func() bool { return storedCount() == 1 }, time.Second, 5*time.Millisecond)
+		eventBus.Publish(ctx, "resource.created", model.Resource{ID: "r1", Name: "Quarterly Report"})
+		assert.Eventually(t, func() bool { return storedCount() == 2 }, time.Second, 5*time.Millisecond)
+
+		mu.Lock()
+		session.On("Run", testify_mock.Anything, map[string]interface{}{"after": int64(0), "limit": 20}, testify_mock.Anything).
+			Return(changeFeedResult(stored[0], stored[1]), nil).Once()
+		mu.Unlock()
+
+		page, err := changeFeedService.GetChanges(ctx, "", 20, 0)
+
+		assert.NoError(t, err)
+		if assert.Len(t, page.Items, 2) {
+			assert.Equal(t, int64(1), page.Items[0].Sequence)
+			assert.Equal(t, "policy", page.Items[0].EntityType)
+			assert.Equal(t, "p1", page.Items[0].EntityID)
+			assert.Equal(t, "created", page.Items[0].Action)
+			assert.Equal(t, "admin", page.Items[0].Actor)
+			assert.False(t, page.Items[0].Timestamp.IsZero())
+			assert.Equal(t, int64(2), page.Items[1].Sequence)
+			assert.Equal(t, "resource", page.Items[1].EntityType)
+			assert.Equal(t, "r1", page.Items[1].EntityID)
+		}
+		assert.Equal(t, "2", page.NextCursor)
+	})
+
+	t.Run("LongPollWaitsForChange", func(t *testing.T) {
+		afterSecond := map[string]interface{}{"after": int64(2), "limit": 20}
+		session.On("Run", testify_mock.Anything, afterSecond, testify_mock.Anything).Return(changeFeedResult(), nil).Once()
+		session.On("Run", testify_mock.Anything, afterSecond, testify_mock.Anything).Return(changeFeedResult(neo4j.Node{Props: map[string]interface{}{
+			"sequence": int64(3), "entityType": "policy", "entityID": "p1", "action": "updated",
+		}}), nil).Once()
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			eventBus.Publish(ctx, "policy.updated", map[string]interface{}{
+				"old": model.Policy{ID: "p1", Name: "Docs readers"},
+				"new": model.Policy{ID: "p1", Name: "Document readers"},
+			})
+		}()
+		start := time.Now()
+
+		page, err := changeFeedService.GetChanges(ctx, "2", 20, 5*time.Second)
+
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+		if assert.Len(t, page.Items, 1) {
+			assert.Equal(t, "updated", page.Items[0].Action)
+		}
+		assert.Equal(t, "3", page.NextCursor)
+		assert.Equal(t, 3, storedCount())
+		mu.Lock()
+		assert.Equal(t, "p1", stored[2].Props["entityID"])
+		mu.Unlock()
+	})
+
+	t.Run("NothingNewWithoutWait", func(t *testing.T) {
+		session.On("Run", testify_mock.Anything, map[string]interface{}{"after": int64(3), "limit": 20}, testify_mock.Anything).
+			Return(changeFeedResult(), nil).Once()
+
+		page, err := changeFeedService.GetChanges(ctx, "3", 20, 0)
+
+		assert.NoError(t, err)
+		assert.Empty(t, page.Items)
+		assert.Equal(t, "3", page.NextCursor)
+	})
+
+	t.Run("MalformedCursor", func(t *testing.T) {
+		_, err := changeFeedService.GetChanges(ctx, "not-a-cursor", 20, 0)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,45 @@
+// api/service/clearance.go
+package service
+
+import (
+	"fmt"
+
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// clearanceEnforced makes the PDP deny access to resources classified above the subject's clearance,
+// whatever the policies allow
+var clearanceEnforced bool
+
+// SetClearanceEnforced sets whether subjects are denied resources classified above their clearance,
+// regardless of the policies allowing them
+func SetClearanceEnforced(enforced bool) {
+	clearanceEnforced = enforced
+}
+
+// clearanceDenial returns why the subject's clearance falls short of the resource's classification, or
+// an empty reason when it does not. Subjects without a clearance, or with one outside the taxonomy, are
+// cleared for its lowest level only; resources classified outside it are treated as the most
+// sensitive. Unclassified resources are open to every clearance, and without a taxonomy there are no
+// levels to compare.
+func clearanceDenial(subject *model.User, resource *model.Resource) string {
+	levels := util.ClassificationLevels()
+	if !clearanceEnforced || len(levels) == 0 || resource.Classification == "" {
+		return ""
+	}
+
+	classification, ok := util.ClassificationRank(resource.Classification)
+	if !ok {
+		classification = len(levels)
+	}
+	clearance, ok := util.ClassificationRank(subject.Clearance)
+	if !ok {
+		clearance = 0
+	}
+
+	if classification <= clearance {
+		return ""
+	}
+	return fmt.Sprintf("resource classification %q exceeds subject clearance %q", resource.Classification, levels[clearance])
+}
@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestClearance(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	util.SetClassificationLevels([]string{"public", "internal", "confidential", "restricted"})
+	defer util.SetClassificationLevels(nil)
+	defer service.SetClearanceEnforced(false)
+
+	allowReads := &model.Policy{
+		ID:            "p1",
+		Name:          "Everyone reads documents",
+		Effect:        echo_neo4j.PolicyEffectAllow,
+		Active:        true,
+		Subjects:      []model.Subject{{Type: "user", UserID: "u1"}},
+		ResourceTypes: []string{"DOCUMENT"},
+		Actions:       []string{"read"},
+	}
+	request := model.AccessRequest{SubjectID: "u1", ResourceID: "res1", Action: "read"}
+
+	tests := []struct {
+		name           string
+		enforced       bool
+		clearance      string
+		classification string
+		allowed        bool
+	}{
+		{"Low clearance is denied despite allow", true, "internal", "restricted", false},
+		{"Clearance at the classification level", true, "confidential", "confidential", true},
+		{"Clearance above the classification level", true, "restricted", "internal", true},
+		{"Missing clearance is the lowest level", true, "", "public", true},
+		{"Missing clearance is denied above the lowest level", true, "", "internal", false},
+		{"Unclassified resources are open", true, "", "", true},
+		{"Unknown classification is the most sensitive", true, "restricted", "top-secret", false},
+		{"Rule off", false, "internal", "restricted", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service.SetClearanceEnforced(tt.enforced)
+			subject := &model.User{ID: "u1", Status: model.UserStatusActive, Clearance: tt.clearance}
+			resource := &model.Resource{ID: "res1", Type: "DOCUMENT", Classification: tt.classification}
+
+			decision := service.EvaluatePolicies([]*model.Policy{allowReads}, subject, resource, request, time.Now())
+
+			assert.Equal(t, tt.allowed, decision.Allowed)
+			if !tt.allowed {
+				assert.Empty(t, decision.PolicyID)
+				assert.Contains(t, decision.Reason, "exceeds subject clearance")
+			}
+		})
+	}
+}
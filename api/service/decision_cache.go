@@ -0,0 +1,263 @@
+// api/service/decision_cache.go
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// decisionCacheTTL is how long access decisions are cached. Zero turns the decision cache off.
+var decisionCacheTTL time.Duration
+
+// SetDecisionCacheTTL sets how long access decisions are cached; zero turns the cache off
+func SetDecisionCacheTTL(ttl time.Duration) {
+	decisionCacheTTL = ttl
+}
+
+// Tags cached decisions are indexed under, so a change drops exactly the decisions it may alter
+const allDecisionsTag = "all"
+
+func subjectDecisionTag(userID string) string      { return "subject:" + userID }
+func resourceDecisionTag(resourceID string) string { return "resource:" + resourceID }
+func policyDecisionTag(policyID string) string     { return "policy:" + policyID }
+
+// decisionsCached reports whether access decisions go through the cache
+func (s *AccessService) decisionsCached() bool {
+	return decisionCacheTTL > 0 && s.cacheService != nil
+}
+
+// subscribeDecisionInvalidation drops the cached decisions a change may alter: those of the subject or
+// resource changed, and those a changed policy took part in. A policy created, or one whose scope
+// changed, may alter any decision, and so may a changed group or role, so every decision is dropped
+// then.
+func (s *AccessService) subscribeDecisionInvalidation(eventBus *util.EventBus) {
+	eventBus.Subscribe("policy.created", func(ctx context.Context, event util.Event) error {
+		if policy, ok := event.Payload.(model.Policy); ok && !policyEvaluated(policy) {
+			return nil
+		}
+		return s.invalidateDecisions(ctx, allDecisionsTag)
+	})
+	eventBus.Subscribe("policy.updated", func(ctx context.Context, event util.Event) error {
+		payload, _ := event.Payload.(map[string]interface{})
+		oldPolicy, oldOK := payload["old"].(model.Policy)
+		newPolicy, newOK := payload["new"].(model.Policy)
+		if !oldOK || !newOK || policyScopeChanged(oldPolicy, newPolicy) {
+			return s.invalidateDecisions(ctx, allDecisionsTag)
+		}
+		return s.invalidateDecisions(ctx, policyDecisionTag(newPolicy.ID))
+	})
+	eventBus.Subscribe("policy.deleted", func(ctx context.Context, event util.Event) error {
+		policyID, _ := event.Payload.(string)
+		return s.invalidateDecisions(ctx, policyDecisionTag(policyID))
+	})
+	eventBus.Subscribe("user.updated", func(ctx context.Context, event util.Event) error {
+		users, _ := event.Payload.(map[string]model.User)
+		return s.invalidateDecisions(ctx, subjectDecisionTag(users["new"].ID))
+	})
+	eventBus.Subscribe("user.deleted", func(ctx context.Context, event util.Event) error {
+		userID, _ := event.Payload.(string)
+		return s.invalidateDecisions(ctx, subjectDecisionTag(userID))
+	})
+	for _, eventType := range []string{"role.assigned", "role.unassigned"} {
+		eventBus.Subscribe(eventType, func(ctx context.Context, event util.Event) error {
+			assignment, _ := event.Payload.(model.RoleAssignment)
+			tags := make([]string, len(assignment.UserIDs))
+			for i, userID := range assignment.UserIDs {
+				tags[i] = subjectDecisionTag(userID)
+			}
+			return s.invalidateDecisions(ctx, tags...)
+		})
+	}
+	// A group or role reaches every user holding it, directly or through nested groups, and decisions
+	// are not indexed by the groups and roles they relied on, so changing one drops every decision
+	for _, eventType := range []string{"group.updated", "group.deleted", "group.moved", "role.updated", "role.deleted", "role.permissions_changed"} {
+		eventBus.Subscribe(eventType, func(ctx context.Context, event util.Event) error {
+			return s.invalidateDecisions(ctx, allDecisionsTag)
+		})
+	}
+	eventBus.Subscribe("resource.updated", func(ctx context.Context, event util.Event) error {
+		resources, _ := event.Payload.(map[string]model.Resource)
+		return s.invalidateDecisions(ctx, resourceDecisionTag(resources["new"].ID))
+	})
+	eventBus.Subscribe("resource.deleted", func(ctx context.Context, event util.Event) error {
+		resourceID, _ := event.Payload.(string)
+		return s.invalidateDecisions(ctx, resourceDecisionTag(resourceID))
+	})
+}
+
+func (s *AccessService) invalidateDecisions(ctx context.Context, tags ...string) error {
+	if !s.decisionsCached() || len(tags) == 0 {
+		return nil
+	}
+	dropped, err := s.cacheService.InvalidateDecisions(ctx, tags...)
+	if err != nil {
+		logger.Warn("Failed to invalidate cached decisions", zap.Error(err), zap.Strings("tags", tags))
+		return err
+	}
+	logger.Debug("Cached decisions invalidated", zap.Strings("tags", tags), zap.Int("count", dropped))
+	return nil
+}
+
+// cachedDecision returns the decision cached for the request, or nil on a miss
+func (s *AccessService) cachedDecision(ctx context.Context, key string) *model.AccessDecision {
+	decision, err := s.cacheService.GetDecision(ctx, key)
+	if err != nil {
+		logCacheReadError(db.CacheKindDecision, key, err)
+		return nil
+	}
+	return decision
+}
+
+// cacheDecision caches the decision, indexed under its subject, its resource and the ancestors the
+// resource inherits from, and under every policy that may take part in it: the policies the graph links
+// to the subject or the resource, and those the evaluation found covering the request on the resource
+// or its ancestors. Decisions whose policies cannot be listed are not cached, as nothing would then
+// invalidate them.
+func (s *AccessService) cacheDecision(ctx context.Context, key string, decision *model.AccessDecision, policies []*model.Policy, subject *model.User, resource *model.Resource, ancestors []*model.Resource, request model.AccessRequest, now time.Time) {
+	ttl := decisionTTL(policies, subject, resource, request.Action, now, decisionCacheTTL)
+	if ttl <= 0 {
+		return
+	}
+
+	subjectPolicies, err := s.policyDAO.GetPoliciesForSubject(ctx, request.SubjectID)
+	if err != nil {
+		logger.Warn("Decision not cached, failed to list the subject's policies", zap.Error(err), zap.String("subjectID", request.SubjectID))
+		return
+	}
+	resourcePolicies, err := s.policyDAO.GetPoliciesForResource(ctx, request.ResourceID)
+	if err != nil {
+		logger.Warn("Decision not cached, failed to list the resource's policies", zap.Error(err), zap.String("resourceID", request.ResourceID))
+		return
+	}
+
+	tags := []string{allDecisionsTag, subjectDecisionTag(request.SubjectID), resourceDecisionTag(request.ResourceID)}
+	for _, ancestor := range ancestors {
+		tags = append(tags, resourceDecisionTag(ancestor.ID))
+	}
+	tagged := map[string]bool{}
+	tagPolicy := func(policy *model.Policy) {
+		if !tagged[policy.ID] {
+			tagged[policy.ID] = true
+			tags = append(tags, policyDecisionTag(policy.ID))
+		}
+	}
+	for _, policy := range subjectPolicies {
+		tagPolicy(policy)
+	}
+	for _, policy := range resourcePolicies {
+		tagPolicy(policy)
+	}
+	for _, policy := range policies {
+		for _, evaluated := range append([]*model.Resource{resource}, ancestors...) {
+			if policyMismatch(policy, subject, evaluated, request.Action, now) == "" {
+				tagPolicy(policy)
+				break
+			}
+		}
+	}
+
+	if err := s.cacheService.SetDecision(ctx, key, *decision, ttl, decisionCacheTTL, tags); err != nil {
+		logger.Warn("Failed to cache decision", zap.Error(err), zap.String("subjectID", request.SubjectID), zap.String("resourceID", request.ResourceID))
+	}
+}
+
+// decisionCacheKey identifies a request by a hash of its subject, resource, action and context
+// attributes
+func decisionCacheKey(request model.AccessRequest) string {
+	// Maps marshal with sorted keys, so equal contexts hash alike
+	requestJSON, _ := json.Marshal([]interface{}{request.SubjectID, request.ResourceID, request.Action, request.Context})
+	hash := sha256.Sum256(requestJSON)
+	return hex.EncodeToString(hash[:])
+}
+
+// decisionTTL caps ttl so a cached decision never outlives a point in time where the policies could
+// decide otherwise: a policy's activation or deactivation, or, for the policies covering the request,
+// the edge of a timeOfDay window or the midnight a dayOfWeek condition turns on. Other timeOfDay
+// conditions change with every minute.
+func decisionTTL(policies []*model.Policy, subject *model.User, resource *model.Resource, action string, now time.Time, ttl time.Duration) time.Duration {
+	expiry := now.Add(ttl)
+	capAt := func(edge time.Time) {
+		if edge.After(now) && edge.Before(expiry) {
+			expiry = edge
+		}
+	}
+
+	for _, policy := range policies {
+		if policy.ActivationDate != nil {
+			capAt(*policy.ActivationDate)
+		}
+		if policy.DeactivationDate != nil {
+			capAt(*policy.DeactivationDate)
+		}
+		if policyMismatch(policy, subject, resource, action, now) != "" {
+			continue
+		}
+		forEachCondition(policy.Conditions, func(condition model.Condition) {
+			switch condition.Attribute {
+			case AttrTimeOfDay:
+				if !strings.EqualFold(condition.Operator, "between") {
+					capAt(now.Truncate(time.Minute).Add(time.Minute))
+					return
+				}
+				for _, bound := range timeWindowBounds(condition.Value) {
+					if minute, err := minuteOfDay(bound); err == nil {
+						capAt(nextWallClock(now, minute))
+					}
+				}
+			case AttrDayOfWeek:
+				capAt(nextWallClock(now, 0))
+			}
+		})
+	}
+	return expiry.Sub(now)
+}
+
+// nextWallClock returns the first time after now the wall clock of the policy timezone reads minute,
+// counted from midnight
+func nextWallClock(now time.Time, minute int) time.Time {
+	local := now.In(policyLocation)
+	next := time.Date(local.Year(), local.Month(), local.Day(), minute/60, minute%60, 0, 0, policyLocation)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, minute/60, minute%60, 0, 0, policyLocation)
+	}
+	return next
+}
+
+// forEachCondition calls visit on every condition, sub-conditions included
+func forEachCondition(conditions []model.Condition, visit func(model.Condition)) {
+	for _, condition := range conditions {
+		visit(condition)
+		if condition.SubConditions != nil {
+			forEachCondition(condition.SubConditions.Conditions, visit)
+		}
+	}
+}
+
+// policyEvaluated reports whether the access evaluation may consider the policy at all
+func policyEvaluated(policy model.Policy) bool {
+	return policy.Active && (policy.Status == "" || policy.Status == model.PolicyStatusActive)
+}
+
+// policyScopeChanged reports whether an update may have changed which requests the policy covers, and
+// so which cached decisions it takes part in
+func policyScopeChanged(oldPolicy, newPolicy model.Policy) bool {
+	return policyEvaluated(oldPolicy) != policyEvaluated(newPolicy) ||
+		!reflect.DeepEqual(oldPolicy.ActivationDate, newPolicy.ActivationDate) ||
+		!reflect.DeepEqual(oldPolicy.DeactivationDate, newPolicy.DeactivationDate) ||
+		!reflect.DeepEqual(oldPolicy.Subjects, newPolicy.Subjects) ||
+		!reflect.DeepEqual(oldPolicy.Actions, newPolicy.Actions) ||
+		!reflect.DeepEqual(oldPolicy.ResourceTypes, newPolicy.ResourceTypes) ||
+		!reflect.DeepEqual(oldPolicy.AttributeGroups, newPolicy.AttributeGroups)
+}
@@ -0,0 +1,197 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestDecisionCache(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	server := mock.StartRedisServer(t)
+	service.SetDecisionCacheTTL(time.Minute)
+	defer service.SetDecisionCacheTTL(0)
+
+	ctx := context.Background()
+	policyNode := func(effect string) neo4j.Node {
+		return neo4j.Node{Props: map[string]any{
+			"id":                "p1",
+			"name":              "Report reads",
+			"description":       "",
+			"effect":            effect,
+			"priority":          int64(1),
+			"version":           int64(1),
+			"createdAt":         "2024-01-01T00:00:00Z",
+			"updatedAt":         "2024-01-01T00:00:00Z",
+			"active":            true,
+			"subjects":          `[{"type":"user","user_id":"u1"}]`,
+			"resourceTypes":     "[]",
+			"attributeGroups":   "[]",
+			"actions":           `["read"]`,
+			"conditions":        "[]",
+			"dynamicAttributes": "[]",
+		}}
+	}
+	resourceRecord := &neo4j.Record{
+		Keys: []string{"r", "parentID", "relatedIDs"},
+		Values: []any{mock.ResourceNode("res1", map[string]any{
+			"ownerID":   "u2",
+			"createdBy": "u2",
+			"updatedBy": "u2",
+		}), nil, []any{}},
+	}
+
+	// Every query answers once per evaluation reaching the database, so a third one fails the test. The
+	// subject is read for every request, those answered from the cache included.
+	session := &mock.MockSession{}
+	session.On("Close").Return(nil)
+	expectRun := func(fragment string, records ...*neo4j.Record) {
+		result := &mock.MockResult{}
+		for _, record := range records {
+			result.On("Next").Return(true).Once()
+			result.On("Record").Return(record).Once()
+		}
+		result.On("Next").Return(false)
+		session.On("Run", testify_mock.MatchedBy(func(query string) bool { return strings.Contains(query, fragment) }), testify_mock.Anything, testify_mock.Anything).
+			Return(result, nil).Once()
+	}
+	for _, effect := range []string{echo_neo4j.PolicyEffectAllow, echo_neo4j.PolicyEffectDeny} {
+		expectRun("AS permissionIDs", &neo4j.Record{Values: []any{[]interface{}{}, []interface{}{}, []interface{}{}}})
+		expectRun("AS parentID", resourceRecord)
+		expectRun("$userID", &neo4j.Record{Values: []any{policyNode(effect)}})
+		expectRun("$resourceID")
+		expectRun("(p:"+echo_neo4j.LabelPolicy+")", &neo4j.Record{Values: []any{policyNode(effect)}})
+	}
+	for _, status := range []string{model.UserStatusActive, model.UserStatusActive, model.UserStatusSuspended, model.UserStatusActive} {
+		expectRun("RETURN u, roleIds", userRecord("u1", status))
+	}
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	auditService := &mock.MockAuditService{}
+	eventBus := util.NewEventBus()
+
+	accessService := service.NewAccessService(
+		&dao.UserDAO{Driver: driver, AuditService: auditService},
+		&dao.ResourceDAO{Driver: driver, AuditService: auditService},
+		&dao.PolicyDAO{Driver: driver, AuditService: auditService},
+		util.NewCacheService(nil),
+		eventBus,
+		nil,
+	)
+	request := model.AccessRequest{SubjectID: "u1", ResourceID: "res1", Action: "read", Context: map[string]interface{}{"client_ip": "10.0.0.1"}}
+
+	t.Run("Repeated requests are answered from the cache", func(t *testing.T) {
+		first, err := accessService.EvaluateAccess(ctx, request)
+		require.NoError(t, err)
+		require.True(t, first.Allowed)
+		session.AssertNumberOfCalls(t, "Run", 6)
+
+		second, err := accessService.EvaluateAccess(ctx, request)
+
+		require.NoError(t, err)
+		assert.True(t, second.Allowed)
+		assert.Equal(t, "p1", second.PolicyID)
+		session.AssertNumberOfCalls(t, "Run", 7)
+		assert.True(t, server.Has("decisions:policy:p1"))
+	})
+
+	t.Run("A suspended subject is denied despite a cached allow", func(t *testing.T) {
+		decision, err := accessService.EvaluateAccess(ctx, request)
+
+		require.NoError(t, err)
+		assert.False(t, decision.Allowed)
+		session.AssertNumberOfCalls(t, "Run", 8)
+	})
+
+	t.Run("A policy change invalidates the decisions it took part in", func(t *testing.T) {
+		allow := model.Policy{ID: "p1", Effect: echo_neo4j.PolicyEffectAllow, Active: true, Actions: []string{"read"}}
+		deny := allow
+		deny.Effect = echo_neo4j.PolicyEffectDeny
+
+		eventBus.Publish(ctx, "policy.updated", map[string]interface{}{"old": allow, "new": deny})
+		assert.Eventually(t, func() bool { return !server.Has("decisions:policy:p1") }, time.Second, 5*time.Millisecond)
+
+		decision, err := accessService.EvaluateAccess(ctx, request)
+
+		require.NoError(t, err)
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, "p1", decision.PolicyID)
+		session.AssertNumberOfCalls(t, "Run", 14)
+	})
+
+	for _, eventType := range []string{"group.updated", "group.deleted", "group.moved", "role.updated", "role.deleted", "role.permissions_changed"} {
+		t.Run("A "+eventType+" event invalidates every decision", func(t *testing.T) {
+			require.NoError(t, util.NewCacheService(nil).SetDecision(ctx, "k1", model.AccessDecision{Allowed: true}, time.Minute, time.Minute, []string{"all", "subject:u1"}))
+			require.True(t, server.Has("decisions:all"))
+
+			eventBus.Publish(ctx, eventType, "g1")
+
+			assert.Eventually(t, func() bool { return !server.Has("decisions:all") }, time.Second, 5*time.Millisecond)
+		})
+	}
+}
+
+func TestDecisionTTL(t *testing.T) {
+	subject := &model.User{ID: "u1", Status: model.UserStatusActive}
+	resource := &model.Resource{ID: "res1", Type: "DOCUMENT"}
+	policy := func(action string, conditions ...model.Condition) *model.Policy {
+		return &model.Policy{
+			ID:         "p1",
+			Effect:     echo_neo4j.PolicyEffectAllow,
+			Active:     true,
+			Subjects:   []model.Subject{{Type: "user", UserID: "u1"}},
+			Actions:    []string{action},
+			Conditions: conditions,
+		}
+	}
+	officeHours := model.Condition{Attribute: service.AttrTimeOfDay, Operator: "between", Value: "09:00-17:00"}
+	weekdays := model.Condition{Attribute: service.AttrDayOfWeek, Operator: "in", Value: []interface{}{"Mon..Fri"}}
+	// A Monday, half a minute before office hours
+	now := time.Date(2024, 1, 1, 8, 59, 30, 0, time.UTC)
+
+	t.Run("Capped at the edge of a time window", func(t *testing.T) {
+		ttl := service.DecisionTTL([]*model.Policy{policy("read", officeHours)}, subject, resource, "read", now, 5*time.Minute)
+
+		assert.Equal(t, 30*time.Second, ttl)
+	})
+
+	t.Run("Capped at midnight for days of the week", func(t *testing.T) {
+		beforeMidnight := time.Date(2024, 1, 1, 23, 58, 0, 0, time.UTC)
+
+		ttl := service.DecisionTTL([]*model.Policy{policy("read", weekdays)}, subject, resource, "read", beforeMidnight, 5*time.Minute)
+
+		assert.Equal(t, 2*time.Minute, ttl)
+	})
+
+	t.Run("Capped at a policy's activation", func(t *testing.T) {
+		upcoming := policy("read")
+		activation := now.Add(10 * time.Second)
+		upcoming.ActivationDate = &activation
+
+		ttl := service.DecisionTTL([]*model.Policy{upcoming}, subject, resource, "read", now, 5*time.Minute)
+
+		assert.Equal(t, 10*time.Second, ttl)
+	})
+
+	t.Run("Policies not covering the request leave it uncapped", func(t *testing.T) {
+		ttl := service.DecisionTTL([]*model.Policy{policy("write", officeHours)}, subject, resource, "read", now, 5*time.Minute)
+
+		assert.Equal(t, 5*time.Minute, ttl)
+	})
+}
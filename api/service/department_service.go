@@ -10,17 +10,19 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IDepartmentService defines the interface for department operations
 type IDepartmentService interface {
 	CreateDepartment(ctx context.Context, dept model.Department, userID string) (*model.Department, error)
 	UpdateDepartment(ctx context.Context, dept model.Department, userID string) (*model.Department, error)
-	DeleteDepartment(ctx context.Context, deptID string, userID string) error
+	DeleteDepartment(ctx context.Context, deptID string, userID string, mode string, targetDeptID string) (*model.DepartmentDeletionSummary, error)
 	GetDepartment(ctx context.Context, deptID string) (*model.Department, error)
 	ListDepartments(ctx context.Context, limit int, offset int) ([]*model.Department, error)
 	GetDepartmentsByOrganization(ctx context.Context, orgID string) ([]*model.Department, error)
@@ -134,21 +136,25 @@ func (s *DepartmentService) handleDepartmentDeleted(ctx context.Context, event u
 
 // CreateDepartment handles the creation of a new department
 func (s *DepartmentService) CreateDepartment(ctx context.Context, dept model.Department, userID string) (*model.Department, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateDepartment(dept); err != nil {
 		return nil, fmt.Errorf("invalid department: %w", err)
 	}
 
 	// Check if department with the same ID already exists
 	if dept.ID != "" {
-		_, err := s.deptDAO.GetDepartment(ctx, dept.ID)
-		if err == nil {
-			// Department with this ID already exists
-			return nil, echo_errors.ErrDepartmentConflict
-		}
-		if err != echo_errors.ErrDepartmentNotFound {
+		exists, err := s.deptDAO.Exists(ctx, dept.ID)
+		if err != nil {
 			// An error occurred while checking for existing department
 			return nil, echo_errors.ErrDatabaseOperation
 		}
+		if exists {
+			// Department with this ID already exists
+			return nil, echo_errors.ErrDepartmentConflict
+		}
 	}
 
 	dept.CreatedAt = time.Now()
@@ -176,6 +182,10 @@ func (s *DepartmentService) CreateDepartment(ctx context.Context, dept model.Dep
 
 // UpdateDepartment handles updates to an existing department
 func (s *DepartmentService) UpdateDepartment(ctx context.Context, dept model.Department, userID string) (*model.Department, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateDepartment(dept); err != nil {
 		return nil, fmt.Errorf("invalid department: %w", err)
 	}
@@ -209,31 +219,43 @@ func (s *DepartmentService) UpdateDepartment(ctx context.Context, dept model.Dep
 	return updatedDept, nil
 }
 
-// DeleteDepartment handles the deletion of a department
-func (s *DepartmentService) DeleteDepartment(ctx context.Context, deptID string, userID string) error {
-	err := s.deptDAO.DeleteDepartment(ctx, deptID)
+// DeleteDepartment handles the deletion of a department and its child departments and members
+// according to mode
+func (s *DepartmentService) DeleteDepartment(ctx context.Context, deptID string, userID string, mode string, targetDeptID string) (*model.DepartmentDeletionSummary, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if mode == "" {
+		mode = model.DeptDeleteModeRestrict
+	}
+
+	summary, err := s.deptDAO.DeleteDepartment(ctx, deptID, mode, targetDeptID)
 	if err != nil {
-		logger.Error("Error deleting department", zap.Error(err), zap.String("deptID", deptID), zap.String("userID", userID))
-		return fmt.Errorf("failed to delete department: %w", err)
+		logger.Error("Error deleting department", zap.Error(err), zap.String("deptID", deptID), zap.String("mode", mode), zap.String("userID", userID))
+		return nil, fmt.Errorf("failed to delete department: %w", err)
 	}
 
-	// Remove from cache
-	if err := s.cacheService.DeleteDepartment(ctx, deptID); err != nil {
-		logger.Warn("Failed to delete department from cache", zap.Error(err), zap.String("deptID", deptID))
+	// Remove from cache, along with the child departments whose parent changed
+	staleDeptIDs := append([]string{deptID}, summary.ChildDepartmentIDs...)
+	if err := s.cacheService.DeleteMany(ctx, db.CacheKindDepartment, staleDeptIDs); err != nil {
+		logger.Warn("Failed to delete departments from cache", zap.Error(err), zap.Strings("deptIDs", staleDeptIDs))
 	}
 
 	// Publish event for asynchronous processing
 	s.eventBus.Publish(ctx, "department.deleted", deptID)
 
-	logger.Info("Department deleted successfully", zap.String("deptID", deptID), zap.String("userID", userID))
-	return nil
+	logger.Info("Department deleted successfully", zap.String("deptID", deptID), zap.Any("summary", summary), zap.String("userID", userID))
+	return summary, nil
 }
 
 // GetDepartment retrieves a department by its ID
 func (s *DepartmentService) GetDepartment(ctx context.Context, deptID string) (*model.Department, error) {
 	// Try to get from cache first
 	cachedDept, err := s.cacheService.GetDepartment(ctx, deptID)
-	if err == nil && cachedDept != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindDepartment, deptID, err)
+	} else if cachedDept != nil {
 		return cachedDept, nil
 	}
 
@@ -256,6 +278,11 @@ func (s *DepartmentService) GetDepartment(ctx context.Context, deptID string) (*
 
 // ListDepartments retrieves all departments, possibly with pagination
 func (s *DepartmentService) ListDepartments(ctx context.Context, limit int, offset int) ([]*model.Department, error) {
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	depts, err := s.deptDAO.ListDepartments(ctx, limit, offset)
 	if err != nil {
 		logger.Error("Error listing departments", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
@@ -300,6 +327,10 @@ func (s *DepartmentService) GetChildDepartments(ctx context.Context, parentDeptI
 
 // MoveDepartment moves a department to a new parent department
 func (s *DepartmentService) MoveDepartment(ctx context.Context, deptID string, newParentID string, userID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
 	err := s.deptDAO.MoveDepartment(ctx, deptID, newParentID)
 	if err != nil {
 		logger.Error("Error moving department", zap.Error(err), zap.String("deptID", deptID), zap.String("newParentID", newParentID), zap.String("userID", userID))
@@ -320,6 +351,12 @@ func (s *DepartmentService) MoveDepartment(ctx context.Context, deptID string, n
 
 // SearchDepartments searches for departments based on a name pattern
 func (s *DepartmentService) SearchDepartments(ctx context.Context, criteria model.DepartmentSearchCriteria) ([]*model.Department, error) {
+	limit, offset, err := helper_util.ClampPagination(criteria.Limit, criteria.Offset)
+	if err != nil {
+		return nil, err
+	}
+	criteria.Limit, criteria.Offset = limit, offset
+
 	depts, err := s.deptDAO.SearchDepartments(ctx, criteria)
 	if err != nil {
 		logger.Error("Error searching departments", zap.Error(err), zap.Any("criteria", criteria))
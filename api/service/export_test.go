@@ -0,0 +1,28 @@
+package service
+
+import "github.com/dev-mohitbeniwal/echo/api/dao"
+
+// EvaluatePolicies exposes the policy evaluation to the external tests, which need to choose the
+// evaluation time
+var EvaluatePolicies = evaluatePolicies
+
+// DecisionTTL exposes how long a decision may be cached to the external tests, which need to choose
+// the evaluation time
+var DecisionTTL = decisionTTL
+
+// SetTenantOrganizationDAO sets the DAO the tenant guard reads organization ancestry from, which
+// InitializeServices otherwise wires
+func SetTenantOrganizationDAO(orgDAO *dao.OrganizationDAO) {
+	tenantOrgDAO = orgDAO
+}
+
+// EvaluateInheritedPolicies exposes the evaluation falling back on inherited ancestors to the external
+// tests
+var EvaluateInheritedPolicies = evaluateInheritedPolicies
+
+// InheritingAncestors exposes the in-memory walk of inherited ancestors to the external tests
+var InheritingAncestors = inheritingAncestors
+
+// PolicyCoverage exposes the coverage computation to the external tests, which need to choose the
+// report time
+var PolicyCoverage = policyCoverage
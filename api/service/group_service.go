@@ -10,10 +10,12 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IGroupService defines the interface for group operations
@@ -24,6 +26,9 @@ type IGroupService interface {
 	GetGroup(ctx context.Context, groupID string) (*model.Group, error)
 	ListGroups(ctx context.Context, limit int, offset int) ([]*model.Group, error)
 	SearchGroups(ctx context.Context, query string, limit, offset int) ([]*model.Group, error)
+	SetParentGroup(ctx context.Context, groupID string, parentGroupID string, updaterID string) error
+	GetGroupHierarchy(ctx context.Context, groupID string) ([]*model.Group, error)
+	GetEffectiveGroupMembers(ctx context.Context, groupID string) ([]*model.User, error)
 }
 
 // GroupService handles business logic for group operations
@@ -130,6 +135,10 @@ func (s *GroupService) handleGroupDeleted(ctx context.Context, event util.Event)
 
 // CreateGroup handles the creation of a new group
 func (s *GroupService) CreateGroup(ctx context.Context, group model.Group, creatorID string) (*model.Group, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateGroup(group); err != nil {
 		return nil, fmt.Errorf("invalid group: %w", err)
 	}
@@ -159,6 +168,10 @@ func (s *GroupService) CreateGroup(ctx context.Context, group model.Group, creat
 
 // UpdateGroup handles updates to an existing group
 func (s *GroupService) UpdateGroup(ctx context.Context, group model.Group, updaterID string) (*model.Group, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateGroup(group); err != nil {
 		return nil, fmt.Errorf("invalid group: %w", err)
 	}
@@ -194,6 +207,10 @@ func (s *GroupService) UpdateGroup(ctx context.Context, group model.Group, updat
 
 // DeleteGroup handles the deletion of a group
 func (s *GroupService) DeleteGroup(ctx context.Context, groupID string, deleterID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
 	err := s.groupDAO.DeleteGroup(ctx, groupID)
 	if err != nil {
 		logger.Error("Error deleting group", zap.Error(err), zap.String("groupID", groupID), zap.String("deleterID", deleterID))
@@ -216,7 +233,9 @@ func (s *GroupService) DeleteGroup(ctx context.Context, groupID string, deleterI
 func (s *GroupService) GetGroup(ctx context.Context, groupID string) (*model.Group, error) {
 	// Try to get from cache first
 	cachedGroup, err := s.cacheService.GetGroup(ctx, groupID)
-	if err == nil && cachedGroup != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindGroup, groupID, err)
+	} else if cachedGroup != nil {
 		return cachedGroup, nil
 	}
 
@@ -239,6 +258,11 @@ func (s *GroupService) GetGroup(ctx context.Context, groupID string) (*model.Gro
 
 // ListGroups retrieves all groups, possibly with pagination
 func (s *GroupService) ListGroups(ctx context.Context, limit int, offset int) ([]*model.Group, error) {
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	groups, err := s.groupDAO.ListGroups(ctx, limit, offset)
 	if err != nil {
 		logger.Error("Error listing groups", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
@@ -256,6 +280,55 @@ func (s *GroupService) SearchGroups(ctx context.Context, query string, limit, of
 	return nil, fmt.Errorf("group search not implemented")
 }
 
+// SetParentGroup nests a group inside another group, or detaches it when parentGroupID is empty
+func (s *GroupService) SetParentGroup(ctx context.Context, groupID string, parentGroupID string, updaterID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
+	if groupID == parentGroupID {
+		return echo_errors.ErrGroupCycle
+	}
+
+	if err := s.groupDAO.SetParentGroup(ctx, groupID, parentGroupID); err != nil {
+		logger.Error("Error setting parent group", zap.Error(err), zap.String("groupID", groupID), zap.String("parentGroupID", parentGroupID), zap.String("updaterID", updaterID))
+		return err
+	}
+
+	// The cached group carries the old parent
+	if err := s.cacheService.DeleteGroup(ctx, groupID); err != nil {
+		logger.Warn("Failed to delete group from cache", zap.Error(err), zap.String("groupID", groupID))
+	}
+
+	// Publish event for asynchronous processing
+	s.eventBus.Publish(ctx, "group.moved", map[string]string{"groupID": groupID, "parentGroupID": parentGroupID})
+
+	logger.Info("Parent group set successfully", zap.String("groupID", groupID), zap.String("parentGroupID", parentGroupID), zap.String("updaterID", updaterID))
+	return nil
+}
+
+// GetGroupHierarchy retrieves the chain of parent groups for a given group
+func (s *GroupService) GetGroupHierarchy(ctx context.Context, groupID string) ([]*model.Group, error) {
+	hierarchy, err := s.groupDAO.GetGroupHierarchy(ctx, groupID)
+	if err != nil {
+		logger.Error("Error retrieving group hierarchy", zap.Error(err), zap.String("groupID", groupID))
+		return nil, err
+	}
+
+	return hierarchy, nil
+}
+
+// GetEffectiveGroupMembers retrieves the members of a group including members of its nested subgroups
+func (s *GroupService) GetEffectiveGroupMembers(ctx context.Context, groupID string) ([]*model.User, error) {
+	members, err := s.groupDAO.GetEffectiveGroupMembers(ctx, groupID)
+	if err != nil {
+		logger.Error("Error retrieving effective group members", zap.Error(err), zap.String("groupID", groupID))
+		return nil, fmt.Errorf("failed to get effective group members: %w", err)
+	}
+
+	return members, nil
+}
+
 // Helper methods
 
 func (s *GroupService) updateGroupIndexes(ctx context.Context, group model.Group) error {
@@ -0,0 +1,54 @@
+// api/service/ip_conditions.go
+package service
+
+import (
+	"net/netip"
+	"strings"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// AttrClientIP is the attribute holding the address the access request came from, which ipInCidr
+// conditions check against a list of CIDR ranges
+const AttrClientIP = "context.client_ip"
+
+// ipInCIDR reports whether actual, an IPv4 or IPv6 address, lies in any of the CIDR ranges expected
+// lists. IPv4 addresses written as IPv6 match IPv4 ranges. Malformed ranges match nothing.
+func ipInCIDR(actual, expected interface{}) bool {
+	value, ok := actual.(string)
+	if !ok {
+		return false
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	var ranges []string
+	switch cidrs := expected.(type) {
+	case string:
+		ranges = []string{cidrs}
+	case []interface{}:
+		for _, cidr := range cidrs {
+			s, _ := cidr.(string)
+			ranges = append(ranges, s)
+		}
+	case []string:
+		ranges = cidrs
+	}
+
+	for _, cidr := range ranges {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			logger.Warn("Malformed CIDR in policy condition", zap.String("value", cidr), zap.Error(err))
+			continue
+		}
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
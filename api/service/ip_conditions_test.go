@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+)
+
+func TestIPInCIDRConditions(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	corporateNetwork := &model.Policy{
+		ID:       "p1",
+		Name:     "Corporate network",
+		Effect:   echo_neo4j.PolicyEffectAllow,
+		Active:   true,
+		Subjects: []model.Subject{{Type: "user", UserID: "u1"}},
+		Actions:  []string{"read"},
+		Conditions: []model.Condition{
+			{Attribute: service.AttrClientIP, Operator: "ipInCidr", Value: []interface{}{"not-a-cidr", "10.0.0.0/8", "2001:db8::/32"}},
+		},
+	}
+	subject := &model.User{ID: "u1", Status: model.UserStatusActive}
+	resource := &model.Resource{ID: "res1", Type: "DOCUMENT"}
+
+	tests := []struct {
+		clientIP string
+		allowed  bool
+	}{
+		{"10.1.2.3", true},
+		{"::ffff:10.1.2.3", true},
+		{"2001:db8:1::7", true},
+		{"192.168.1.20", false},
+		{"2001:db9::7", false},
+		{"garbage", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.clientIP, func(t *testing.T) {
+			request := model.AccessRequest{SubjectID: "u1", ResourceID: "res1", Action: "read", Context: map[string]interface{}{"client_ip": tt.clientIP}}
+
+			decision := service.EvaluatePolicies([]*model.Policy{corporateNetwork}, subject, resource, request, time.Now())
+
+			assert.Equal(t, tt.allowed, decision.Allowed)
+		})
+	}
+}
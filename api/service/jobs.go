@@ -0,0 +1,8 @@
+// api/service/jobs.go
+package service
+
+// Types of the background jobs the services submit
+const (
+	JobTypePolicyImport = "policy_import"
+	JobTypeAccessReview = "access_review"
+)
@@ -10,20 +10,25 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IOrganizationService defines the interface for organization operations
 type IOrganizationService interface {
 	CreateOrganization(ctx context.Context, org model.Organization, userID string) (*model.Organization, error)
 	UpdateOrganization(ctx context.Context, org model.Organization, userID string) (*model.Organization, error)
-	DeleteOrganization(ctx context.Context, orgID string, userID string) error
+	DeleteOrganization(ctx context.Context, orgID string, userID string, mode string, targetOrgID string) (*model.OrganizationDeletionSummary, error)
 	GetOrganization(ctx context.Context, orgID string) (*model.Organization, error)
 	ListOrganizations(ctx context.Context, limit int, offset int) ([]*model.Organization, error)
 	SearchOrganizations(ctx context.Context, criteria model.OrganizationSearchCriteria) ([]*model.Organization, error)
+	SetParentOrganization(ctx context.Context, orgID string, parentID string, userID string) (*model.Organization, error)
+	GetOrganizationHierarchy(ctx context.Context, orgID string) ([]*model.Organization, error)
+	GetSubOrganizations(ctx context.Context, orgID string) ([]*model.Organization, error)
 }
 
 // OrganizationService handles business logic for organization operations
@@ -130,21 +135,32 @@ func (s *OrganizationService) handleOrganizationDeleted(ctx context.Context, eve
 
 // CreateOrganization handles the creation of a new organization
 func (s *OrganizationService) CreateOrganization(ctx context.Context, org model.Organization, userID string) (*model.Organization, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if org.Status == "" {
+		org.Status = model.OrgStatusActive
+	}
 	if err := s.validationUtil.ValidateOrganization(org); err != nil {
 		return nil, fmt.Errorf("invalid organization: %w", err)
 	}
 
+	if org.ID != "" && org.ID == org.ParentID {
+		return nil, echo_errors.ErrOrganizationCycle
+	}
+
 	// Check if organization with the same ID already exists
 	if org.ID != "" {
-		_, err := s.orgDAO.GetOrganization(ctx, org.ID)
-		if err == nil {
-			// Organization with this ID already exists
-			return nil, echo_errors.ErrOrganizationConflict
-		}
-		if err != echo_errors.ErrOrganizationNotFound {
+		exists, err := s.orgDAO.Exists(ctx, org.ID)
+		if err != nil {
 			// An error occurred while checking for existing organization
 			return nil, echo_errors.ErrDatabaseOperation
 		}
+		if exists {
+			// Organization with this ID already exists
+			return nil, echo_errors.ErrOrganizationConflict
+		}
 	}
 
 	org.CreatedAt = time.Now()
@@ -162,6 +178,12 @@ func (s *OrganizationService) CreateOrganization(ctx context.Context, org model.
 	if err := s.cacheService.SetOrganization(ctx, org); err != nil {
 		logger.Warn("Failed to cache organization", zap.Error(err), zap.String("orgID", orgID))
 	}
+	// The cached parent lacks its new child
+	if org.ParentID != "" {
+		if err := s.cacheService.DeleteOrganization(ctx, org.ParentID); err != nil {
+			logger.Warn("Failed to delete organization from cache", zap.Error(err), zap.String("orgID", org.ParentID))
+		}
+	}
 
 	// Publish event for asynchronous processing
 	s.eventBus.Publish(ctx, "organization.created", org)
@@ -172,6 +194,10 @@ func (s *OrganizationService) CreateOrganization(ctx context.Context, org model.
 
 // UpdateOrganization handles updates to an existing organization
 func (s *OrganizationService) UpdateOrganization(ctx context.Context, org model.Organization, userID string) (*model.Organization, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateOrganization(org); err != nil {
 		return nil, fmt.Errorf("invalid organization: %w", err)
 	}
@@ -205,31 +231,54 @@ func (s *OrganizationService) UpdateOrganization(ctx context.Context, org model.
 	return updatedOrg, nil
 }
 
-// DeleteOrganization handles the deletion of an organization
-func (s *OrganizationService) DeleteOrganization(ctx context.Context, orgID string, userID string) error {
-	err := s.orgDAO.DeleteOrganization(ctx, orgID)
+// DeleteOrganization handles the deletion of an organization and its dependents according to mode
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, orgID string, userID string, mode string, targetOrgID string) (*model.OrganizationDeletionSummary, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if mode == "" {
+		mode = model.OrgDeleteModeRestrict
+	}
+
+	// Sub-organizations move up a level, so the cached entries of the parent and children go stale too
+	staleOrgIDs := []string{orgID}
+	if org, err := s.orgDAO.GetOrganization(ctx, orgID); err == nil {
+		staleOrgIDs = append(staleOrgIDs, org.ChildIDs...)
+		if org.ParentID != "" {
+			staleOrgIDs = append(staleOrgIDs, org.ParentID)
+		}
+	}
+
+	summary, err := s.orgDAO.DeleteOrganization(ctx, orgID, mode, targetOrgID)
 	if err != nil {
-		logger.Error("Error deleting organization", zap.Error(err), zap.String("orgID", orgID), zap.String("userID", userID))
-		return fmt.Errorf("failed to delete organization: %w", err)
+		logger.Error("Error deleting organization", zap.Error(err), zap.String("orgID", orgID), zap.String("mode", mode), zap.String("userID", userID))
+		switch err {
+		case echo_errors.ErrOrganizationNotFound, echo_errors.ErrOrganizationInUse, echo_errors.ErrInvalidOrgDeleteMode:
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to delete organization: %w", err)
 	}
 
 	// Remove from cache
-	if err := s.cacheService.DeleteOrganization(ctx, orgID); err != nil {
-		logger.Warn("Failed to delete organization from cache", zap.Error(err), zap.String("orgID", orgID))
+	if err := s.cacheService.DeleteMany(ctx, db.CacheKindOrganization, staleOrgIDs); err != nil {
+		logger.Warn("Failed to delete organizations from cache", zap.Error(err), zap.Strings("orgIDs", staleOrgIDs))
 	}
 
 	// Publish event for asynchronous processing
 	s.eventBus.Publish(ctx, "organization.deleted", orgID)
 
-	logger.Info("Organization deleted successfully", zap.String("orgID", orgID), zap.String("userID", userID))
-	return nil
+	logger.Info("Organization deleted successfully", zap.String("orgID", orgID), zap.Any("summary", summary), zap.String("userID", userID))
+	return summary, nil
 }
 
 // GetOrganization retrieves an organization by its ID
 func (s *OrganizationService) GetOrganization(ctx context.Context, orgID string) (*model.Organization, error) {
 	// Try to get from cache first
 	cachedOrg, err := s.cacheService.GetOrganization(ctx, orgID)
-	if err == nil && cachedOrg != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindOrganization, orgID, err)
+	} else if cachedOrg != nil {
 		return cachedOrg, nil
 	}
 
@@ -252,6 +301,11 @@ func (s *OrganizationService) GetOrganization(ctx context.Context, orgID string)
 
 // ListOrganizations retrieves all organizations, possibly with pagination
 func (s *OrganizationService) ListOrganizations(ctx context.Context, limit int, offset int) ([]*model.Organization, error) {
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	orgs, err := s.orgDAO.ListOrganizations(ctx, limit, offset)
 	if err != nil {
 		logger.Error("Error listing organizations", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
@@ -263,6 +317,12 @@ func (s *OrganizationService) ListOrganizations(ctx context.Context, limit int,
 
 // SearchOrganizations searches for organizations based on a name pattern
 func (s *OrganizationService) SearchOrganizations(ctx context.Context, criteria model.OrganizationSearchCriteria) ([]*model.Organization, error) {
+	limit, offset, err := helper_util.ClampPagination(criteria.Limit, criteria.Offset)
+	if err != nil {
+		return nil, err
+	}
+	criteria.Limit, criteria.Offset = limit, offset
+
 	orgs, err := s.orgDAO.SearchOrganizations(ctx, criteria)
 	if err != nil {
 		logger.Error("Error searching organizations", zap.Error(err), zap.Any("criteria", criteria))
@@ -272,6 +332,72 @@ func (s *OrganizationService) SearchOrganizations(ctx context.Context, criteria
 	return orgs, nil
 }
 
+// SetParentOrganization makes an organization a sub-organization of parentID, or a top-level
+// organization when parentID is empty
+func (s *OrganizationService) SetParentOrganization(ctx context.Context, orgID string, parentID string, userID string) (*model.Organization, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if orgID == parentID {
+		return nil, echo_errors.ErrOrganizationCycle
+	}
+
+	oldOrg, err := s.orgDAO.GetOrganization(ctx, orgID)
+	if err != nil {
+		logger.Error("Error retrieving existing organization", zap.Error(err), zap.String("orgID", orgID))
+		return nil, err
+	}
+
+	updatedOrg, err := s.orgDAO.SetParentOrganization(ctx, orgID, parentID)
+	if err != nil {
+		logger.Error("Error setting parent organization", zap.Error(err), zap.String("orgID", orgID), zap.String("parentID", parentID), zap.String("userID", userID))
+		return nil, err
+	}
+
+	// The cached organization carries the old parent, and both parents the old children
+	staleOrgIDs := []string{orgID}
+	for _, id := range []string{oldOrg.ParentID, parentID} {
+		if id != "" {
+			staleOrgIDs = append(staleOrgIDs, id)
+		}
+	}
+	if err := s.cacheService.DeleteMany(ctx, db.CacheKindOrganization, staleOrgIDs); err != nil {
+		logger.Warn("Failed to delete organizations from cache", zap.Error(err), zap.Strings("orgIDs", staleOrgIDs))
+	}
+
+	s.eventBus.Publish(ctx, "organization.updated", map[string]model.Organization{
+		"old": *oldOrg,
+		"new": *updatedOrg,
+	})
+
+	logger.Info("Parent organization set successfully", zap.String("orgID", orgID), zap.String("parentID", parentID), zap.String("userID", userID))
+	return updatedOrg, nil
+}
+
+// GetOrganizationHierarchy retrieves the chain of organizations from the top-level ancestor down to the
+// given organization
+func (s *OrganizationService) GetOrganizationHierarchy(ctx context.Context, orgID string) ([]*model.Organization, error) {
+	hierarchy, err := s.orgDAO.GetOrganizationHierarchy(ctx, orgID)
+	if err != nil {
+		logger.Error("Error retrieving organization hierarchy", zap.Error(err), zap.String("orgID", orgID))
+		return nil, err
+	}
+
+	return hierarchy, nil
+}
+
+// GetSubOrganizations retrieves every organization below the given one
+func (s *OrganizationService) GetSubOrganizations(ctx context.Context, orgID string) ([]*model.Organization, error) {
+	subOrgs, err := s.orgDAO.GetSubOrganizations(ctx, orgID)
+	if err != nil {
+		logger.Error("Error retrieving sub-organizations", zap.Error(err), zap.String("orgID", orgID))
+		return nil, err
+	}
+
+	return subOrgs, nil
+}
+
 // Helper methods
 func (s *OrganizationService) updateOrganizationIndexes(ctx context.Context, org model.Organization) error {
 	// Implementation for updating indexes
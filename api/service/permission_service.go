@@ -5,14 +5,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IPermissionService defines the interface for permission operations
@@ -22,9 +25,15 @@ type IPermissionService interface {
 	DeletePermission(ctx context.Context, permissionID string, deleterID string) error
 	GetPermission(ctx context.Context, permissionID string) (*model.Permission, error)
 	ListPermissions(ctx context.Context, limit int, offset int) ([]*model.Permission, error)
-	SearchPermissions(ctx context.Context, query string, limit, offset int) ([]*model.Permission, error)
+	SearchPermissions(ctx context.Context, criteria model.PermissionSearchCriteria) ([]*model.Permission, error)
+	GetPermissionsByAction(ctx context.Context, action string) ([]*model.Permission, error)
+	ListOrphanedPermissions(ctx context.Context) ([]*model.Permission, error)
+	CleanupOrphanedPermissions(ctx context.Context, maxAge time.Duration, deleterID string) ([]string, error)
 }
 
+// DefaultOrphanedPermissionMaxAge is how old an orphaned permission must be before cleanup removes it
+const DefaultOrphanedPermissionMaxAge = 30 * 24 * time.Hour
+
 // PermissionService handles business logic for permission operations
 type PermissionService struct {
 	permissionDAO   *dao.PermissionDAO
@@ -127,6 +136,10 @@ func (s *PermissionService) handlePermissionDeleted(ctx context.Context, event u
 
 // CreatePermission handles the creation of a new permission
 func (s *PermissionService) CreatePermission(ctx context.Context, permission model.Permission, creatorID string) (*model.Permission, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidatePermission(permission); err != nil {
 		return nil, fmt.Errorf("invalid permission: %w", err)
 	}
@@ -153,6 +166,10 @@ func (s *PermissionService) CreatePermission(ctx context.Context, permission mod
 
 // UpdatePermission handles updates to an existing permission
 func (s *PermissionService) UpdatePermission(ctx context.Context, permission model.Permission, updaterID string) (*model.Permission, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidatePermission(permission); err != nil {
 		return nil, fmt.Errorf("invalid permission: %w", err)
 	}
@@ -186,6 +203,10 @@ func (s *PermissionService) UpdatePermission(ctx context.Context, permission mod
 
 // DeletePermission handles the deletion of a permission
 func (s *PermissionService) DeletePermission(ctx context.Context, permissionID string, deleterID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
 	err := s.permissionDAO.DeletePermission(ctx, permissionID)
 	if err != nil {
 		logger.Error("Error deleting permission", zap.Error(err), zap.String("permissionID", permissionID), zap.String("deleterID", deleterID))
@@ -208,7 +229,9 @@ func (s *PermissionService) DeletePermission(ctx context.Context, permissionID s
 func (s *PermissionService) GetPermission(ctx context.Context, permissionID string) (*model.Permission, error) {
 	// Try to get from cache first
 	cachedPermission, err := s.cacheService.GetPermission(ctx, permissionID)
-	if err == nil && cachedPermission != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindPermission, permissionID, err)
+	} else if cachedPermission != nil {
 		return cachedPermission, nil
 	}
 
@@ -231,6 +254,11 @@ func (s *PermissionService) GetPermission(ctx context.Context, permissionID stri
 
 // ListPermissions retrieves all permissions, possibly with pagination
 func (s *PermissionService) ListPermissions(ctx context.Context, limit int, offset int) ([]*model.Permission, error) {
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	permissions, err := s.permissionDAO.ListPermissions(ctx, limit, offset)
 	if err != nil {
 		logger.Error("Error listing permissions", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
@@ -240,12 +268,80 @@ func (s *PermissionService) ListPermissions(ctx context.Context, limit int, offs
 	return permissions, nil
 }
 
-// SearchPermissions searches for permissions based on a query string
-func (s *PermissionService) SearchPermissions(ctx context.Context, query string, limit, offset int) ([]*model.Permission, error) {
-	// Implement permission search logic here
-	// This might involve searching by permission name, description, or action
-	// You may need to add a corresponding method in the PermissionDAO
-	return nil, fmt.Errorf("permission search not implemented")
+// SearchPermissions searches for permissions by name and action
+func (s *PermissionService) SearchPermissions(ctx context.Context, criteria model.PermissionSearchCriteria) ([]*model.Permission, error) {
+	logger.Info("Searching permissions", zap.Any("criteria", criteria))
+
+	limit, offset, err := helper_util.ClampPagination(criteria.Limit, criteria.Offset)
+	if err != nil {
+		return nil, err
+	}
+	criteria.Limit, criteria.Offset = limit, offset
+
+	permissions, err := s.permissionDAO.SearchPermissions(ctx, criteria)
+	if err != nil {
+		logger.Error("Error searching permissions", zap.Error(err), zap.Any("criteria", criteria))
+		return nil, fmt.Errorf("failed to search permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// GetPermissionsByAction returns all permissions that grant the given action
+func (s *PermissionService) GetPermissionsByAction(ctx context.Context, action string) ([]*model.Permission, error) {
+	if action == "" {
+		return nil, echo_errors.ErrInvalidSearchCriteria
+	}
+
+	permissions, err := s.permissionDAO.GetPermissionsByAction(ctx, action)
+	if err != nil {
+		logger.Error("Error retrieving permissions by action", zap.Error(err), zap.String("action", action))
+		return nil, fmt.Errorf("failed to retrieve permissions by action: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// ListOrphanedPermissions returns permissions that are not attached to any role
+func (s *PermissionService) ListOrphanedPermissions(ctx context.Context) ([]*model.Permission, error) {
+	permissions, err := s.permissionDAO.ListOrphanedPermissions(ctx)
+	if err != nil {
+		logger.Error("Error listing orphaned permissions", zap.Error(err))
+		return nil, fmt.Errorf("failed to list orphaned permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// CleanupOrphanedPermissions deletes orphaned permissions older than maxAge and returns their IDs.
+// A maxAge of zero uses DefaultOrphanedPermissionMaxAge.
+func (s *PermissionService) CleanupOrphanedPermissions(ctx context.Context, maxAge time.Duration, deleterID string) ([]string, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if maxAge < 0 {
+		return nil, echo_errors.ErrInvalidSearchCriteria
+	}
+	if maxAge == 0 {
+		maxAge = DefaultOrphanedPermissionMaxAge
+	}
+
+	deletedIDs, err := s.permissionDAO.DeleteOrphanedPermissions(ctx, time.Now().Add(-maxAge))
+	if err != nil {
+		logger.Error("Error cleaning up orphaned permissions", zap.Error(err), zap.Duration("maxAge", maxAge), zap.String("deleterID", deleterID))
+		return nil, fmt.Errorf("failed to clean up orphaned permissions: %w", err)
+	}
+
+	for _, permissionID := range deletedIDs {
+		if err := s.cacheService.DeletePermission(ctx, permissionID); err != nil {
+			logger.Warn("Failed to delete permission from cache", zap.Error(err), zap.String("permissionID", permissionID))
+		}
+		s.eventBus.Publish(ctx, "permission.deleted", permissionID)
+	}
+
+	logger.Info("Orphaned permissions cleaned up", zap.Int("count", len(deletedIDs)), zap.String("deleterID", deleterID))
+	return deletedIDs, nil
 }
 
 // Helper methods
@@ -0,0 +1,407 @@
+// api/service/policy_analysis.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+)
+
+// The operators compareAttribute understands; conditions using any other never match
+var conditionOperators = map[string]bool{
+	"exists": true, "equals": true, "eq": true, "==": true, "not_equals": true, "ne": true, "!=": true,
+	"in": true, "not_in": true, "contains": true, "greater_than": true, "gt": true, ">": true,
+	"less_than": true, "lt": true, "<": true, "between": true, "ipincidr": true, "matches": true,
+}
+
+// The outcome of a condition whatever the request
+type conditionVerdict int
+
+const (
+	verdictVaries conditionVerdict = iota
+	verdictAlwaysTrue
+	verdictAlwaysFalse
+)
+
+// AnalyzePolicySet lints the active policies, reporting policies that can never decide a request
+// because another always takes precedence, policies duplicating each other, and conditions whose
+// outcome does not depend on the request
+func (s *PolicyService) AnalyzePolicySet(ctx context.Context) (*model.PolicySetAnalysis, error) {
+	policies, err := s.policyDAO.GetActivePolicies(ctx)
+	if err != nil {
+		logger.Error("Error retrieving active policies for analysis", zap.Error(err))
+		return nil, fmt.Errorf("failed to get active policies: %w", err)
+	}
+
+	analysis := analyzePolicies(policies)
+	logger.Info("Policy set analyzed",
+		zap.Int("policies", analysis.PolicyCount),
+		zap.Int("findings", len(analysis.Findings)))
+	return analysis, nil
+}
+
+func analyzePolicies(policies []*model.Policy) *model.PolicySetAnalysis {
+	analysis := &model.PolicySetAnalysis{
+		AnalyzedAt:  time.Now(),
+		PolicyCount: len(policies),
+		Findings:    []model.PolicyFinding{},
+	}
+
+	// Highest priority first, the order evaluation settles on a decision in
+	sorted := append([]*model.Policy(nil), policies...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+
+	// Policies that never apply are reported for their condition rather than as unreachable
+	neverApplies := make(map[string]bool)
+	for _, policy := range sorted {
+		for i, condition := range policy.Conditions {
+			switch analyzeCondition(condition) {
+			case verdictAlwaysTrue:
+				analysis.Findings = append(analysis.Findings, model.PolicyFinding{
+					Type:      model.PolicyFindingAlwaysTrue,
+					PolicyIDs: []string{policy.ID},
+					Message: fmt.Sprintf("condition %d of policy %q (%s) holds for every request; remove it",
+						i, policy.Name, describeCondition(condition)),
+				})
+			case verdictAlwaysFalse:
+				neverApplies[policy.ID] = true
+				analysis.Findings = append(analysis.Findings, model.PolicyFinding{
+					Type:      model.PolicyFindingAlwaysFalse,
+					PolicyIDs: []string{policy.ID},
+					Message: fmt.Sprintf("condition %d of policy %q (%s) never holds, so the policy never applies; fix the condition or deactivate the policy",
+						i, policy.Name, describeCondition(condition)),
+				})
+			}
+		}
+	}
+
+	duplicates := make(map[string]bool)
+	groups := make(map[string][]*model.Policy)
+	var keys []string
+	for _, policy := range sorted {
+		key := policySignature(policy)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], policy)
+	}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		ids := make([]string, len(group))
+		for i, policy := range group {
+			ids[i] = policy.ID
+			duplicates[policy.ID] = true
+		}
+		analysis.Findings = append(analysis.Findings, model.PolicyFinding{
+			Type:      model.PolicyFindingDuplicate,
+			PolicyIDs: ids,
+			Message:   fmt.Sprintf("policies %s are identical apart from their name; keep one and delete the rest", strings.Join(ids, ", ")),
+		})
+	}
+
+	for _, policy := range sorted {
+		if duplicates[policy.ID] || neverApplies[policy.ID] {
+			continue
+		}
+		if shadow := shadowingPolicy(policy, sorted); shadow != nil {
+			analysis.Findings = append(analysis.Findings, model.PolicyFinding{
+				Type:      model.PolicyFindingUnreachable,
+				PolicyIDs: []string{policy.ID, shadow.ID},
+				Message: fmt.Sprintf("policy %q never decides a request: policy %q (%s, priority %d) matches every request it does and takes precedence; raise its priority or narrow %q",
+					policy.Name, shadow.Name, strings.ToLower(shadow.Effect), shadow.Priority, shadow.Name),
+			})
+		}
+	}
+
+	return analysis
+}
+
+// shadowingPolicy returns the first policy that matches every request policy does and wins over it,
+// either by a higher priority or by denying at the same priority while listing its actions and resource
+// types as is. Only unconditional policies without an activation window are considered, as those are
+// the ones certain to match.
+func shadowingPolicy(policy *model.Policy, sorted []*model.Policy) *model.Policy {
+	for _, other := range sorted {
+		if other == policy || other.Priority < policy.Priority {
+			continue
+		}
+		if other.Priority == policy.Priority &&
+			(!strings.EqualFold(other.Effect, echo_neo4j.PolicyEffectDeny) || strings.EqualFold(policy.Effect, echo_neo4j.PolicyEffectDeny)) {
+			continue
+		}
+		if other.ActivationDate != nil || other.DeactivationDate != nil || !alwaysHolds(other.Conditions) {
+			continue
+		}
+		// A deny matching by a pattern loses to a closer allow at its priority
+		if other.Priority == policy.Priority && !matchesAsClosely(other, policy) {
+			continue
+		}
+		if policyCovers(other, policy) {
+			return other
+		}
+	}
+	return nil
+}
+
+// policyCovers reports whether outer applies to every subject, resource and action inner does
+func policyCovers(outer, inner *model.Policy) bool {
+	if !valuesCover(outer.Actions, inner.Actions) {
+		return false
+	}
+	if len(outer.ResourceTypes) > 0 && (len(inner.ResourceTypes) == 0 || !valuesCover(outer.ResourceTypes, inner.ResourceTypes)) {
+		return false
+	}
+	if len(outer.AttributeGroups) > 0 && (len(inner.AttributeGroups) == 0 || !valuesCover(outer.AttributeGroups, inner.AttributeGroups)) {
+		return false
+	}
+	for _, innerSubject := range inner.Subjects {
+		covered := false
+		for _, outerSubject := range outer.Subjects {
+			if subjectCovers(outerSubject, innerSubject) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAsClosely reports whether outer matches every request inner does at least as closely, listing
+// each of its actions and resource types as is
+func matchesAsClosely(outer, inner *model.Policy) bool {
+	for _, action := range inner.Actions {
+		if !listed(outer.Actions, action) {
+			return false
+		}
+	}
+	if len(inner.ResourceTypes) == 0 {
+		return true
+	}
+	for _, resourceType := range inner.ResourceTypes {
+		if !listed(outer.ResourceTypes, resourceType) {
+			return false
+		}
+	}
+	return true
+}
+
+func listed(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesCover reports whether outer lists "*" or every one of inner
+func valuesCover(outer, inner []string) bool {
+	for _, value := range outer {
+		if value == "*" {
+			return true
+		}
+	}
+	for _, value := range inner {
+		if value == "*" || !containsOrWildcard(outer, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// subjectCovers reports whether every user subjectMatches with inner also matches outer
+func subjectCovers(outer, inner model.Subject) bool {
+	if !strings.EqualFold(outer.Type, inner.Type) {
+		return false
+	}
+	switch strings.ToLower(outer.Type) {
+	case "user":
+		if outer.UserID != "" && outer.UserID != inner.UserID {
+			return false
+		}
+	case "role", "group":
+		if id := outer.Attributes["id"]; id != "*" && id != inner.Attributes["id"] {
+			return false
+		}
+	case "department", "organization":
+		if outer.Attributes["id"] != inner.Attributes["id"] {
+			return false
+		}
+	default:
+		return false
+	}
+
+	for key, value := range outer.Attributes {
+		if key == "id" {
+			continue
+		}
+		if innerValue, ok := inner.Attributes[key]; !ok || innerValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// policySignature identifies a policy by everything evaluation looks at, so policies sharing one
+// are duplicates. Lists whose order does not matter are sorted first.
+func policySignature(policy *model.Policy) string {
+	sortedCopy := func(values []string) []string {
+		sorted := append([]string{}, values...)
+		sort.Strings(sorted)
+		return sorted
+	}
+	subjects := make([]string, len(policy.Subjects))
+	for i, subject := range policy.Subjects {
+		encoded, _ := json.Marshal(model.Subject{
+			Type:       strings.ToLower(subject.Type),
+			UserID:     subject.UserID,
+			Attributes: subject.Attributes,
+		})
+		subjects[i] = string(encoded)
+	}
+	sort.Strings(subjects)
+
+	signature, _ := json.Marshal(struct {
+		Effect           string
+		Priority         int
+		Subjects         []string
+		ResourceTypes    []string
+		AttributeGroups  []string
+		Actions          []string
+		Conditions       []model.Condition
+		ActivationDate   *time.Time
+		DeactivationDate *time.Time
+	}{
+		Effect:           strings.ToLower(policy.Effect),
+		Priority:         policy.Priority,
+		Subjects:         subjects,
+		ResourceTypes:    sortedCopy(policy.ResourceTypes),
+		AttributeGroups:  sortedCopy(policy.AttributeGroups),
+		Actions:          sortedCopy(policy.Actions),
+		Conditions:       policy.Conditions,
+		ActivationDate:   policy.ActivationDate,
+		DeactivationDate: policy.DeactivationDate,
+	})
+	return string(signature)
+}
+
+func alwaysHolds(conditions []model.Condition) bool {
+	for _, condition := range conditions {
+		if analyzeCondition(condition) != verdictAlwaysTrue {
+			return false
+		}
+	}
+	return true
+}
+
+// analyzeCondition works out whether a condition holds whatever the request, mirroring
+// conditionMatches
+func analyzeCondition(condition model.Condition) conditionVerdict {
+	verdict := verdictAlwaysTrue
+	if condition.Attribute != "" {
+		verdict = analyzeAttributeCheck(condition)
+		if verdict == verdictAlwaysFalse {
+			return verdictAlwaysFalse
+		}
+	}
+	if condition.SubConditions == nil {
+		return verdict
+	}
+
+	// An OR holds if any branch does and an AND unless one fails; both hold when empty
+	or := strings.EqualFold(condition.SubConditions.Operator, "OR")
+	settled, unsettled := verdictAlwaysFalse, verdictAlwaysTrue
+	if or {
+		settled, unsettled = verdictAlwaysTrue, verdictAlwaysFalse
+	}
+	subVerdict := unsettled
+	if len(condition.SubConditions.Conditions) == 0 {
+		subVerdict = verdictAlwaysTrue
+	}
+	for _, sub := range condition.SubConditions.Conditions {
+		switch analyzeCondition(sub) {
+		case settled:
+			subVerdict = settled
+		case verdictVaries:
+			if subVerdict == unsettled {
+				subVerdict = verdictVaries
+			}
+		}
+		if subVerdict == settled {
+			break
+		}
+	}
+
+	if subVerdict == verdictAlwaysFalse {
+		return verdictAlwaysFalse
+	}
+	if verdict == verdictAlwaysTrue && subVerdict == verdictAlwaysTrue {
+		return verdictAlwaysTrue
+	}
+	return verdictVaries
+}
+
+// analyzeAttributeCheck works out whether the attribute comparison of a condition holds whatever
+// the request
+func analyzeAttributeCheck(condition model.Condition) conditionVerdict {
+	operator := strings.ToLower(condition.Operator)
+	if !conditionOperators[operator] {
+		return verdictAlwaysFalse
+	}
+
+	// Membership in an empty list is decided before the attribute is even read
+	if operator == "in" || operator == "not_in" {
+		list := reflect.ValueOf(condition.Value)
+		if (list.Kind() != reflect.Slice && list.Kind() != reflect.Array) || list.Len() == 0 {
+			if operator == "in" {
+				return verdictAlwaysFalse
+			}
+			return verdictAlwaysTrue
+		}
+	}
+
+	// The attributes every evaluation sets, whatever the subject, resource and request
+	present := buildEvaluationAttributes(&model.User{}, &model.Resource{}, model.AccessRequest{}, time.Now())
+	if _, ok := present[condition.Attribute]; ok {
+		if operator == "exists" {
+			return verdictAlwaysTrue
+		}
+		return verdictVaries
+	}
+	for _, prefix := range []string{"subject.", "resource.", "context."} {
+		if strings.HasPrefix(condition.Attribute, prefix) {
+			return verdictVaries
+		}
+	}
+
+	// Nothing ever sets the attribute, so the comparison always sees nil
+	if compareAttribute(condition.Operator, nil, condition.Value) {
+		return verdictAlwaysTrue
+	}
+	return verdictAlwaysFalse
+}
+
+func describeCondition(condition model.Condition) string {
+	if condition.Attribute == "" {
+		return "no attribute"
+	}
+	return fmt.Sprintf("%s %s %v", condition.Attribute, condition.Operator, condition.Value)
+}
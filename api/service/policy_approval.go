@@ -0,0 +1,153 @@
+// api/service/policy_approval.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// policyStatusTransitions lists the statuses a policy may move to from each status. Editing a policy
+// leaves its status as it is, unless editedPolicyStatus sends it back to draft.
+var policyStatusTransitions = map[string][]string{
+	model.PolicyStatusDraft:           {model.PolicyStatusPendingApproval},
+	model.PolicyStatusPendingApproval: {model.PolicyStatusActive, model.PolicyStatusRejected},
+	model.PolicyStatusRejected:        {model.PolicyStatusPendingApproval},
+}
+
+// policyApprovalRequired makes every new policy start as a draft, so none takes effect before it is
+// approved
+var policyApprovalRequired bool
+
+// SetPolicyApprovalRequired sets whether new policies must be approved before they are evaluated. When
+// not required, a policy only starts as a draft when created with that status.
+func SetPolicyApprovalRequired(required bool) {
+	policyApprovalRequired = required
+}
+
+// initialPolicyStatus is the status a new policy is created with
+func initialPolicyStatus(requested string) string {
+	if policyApprovalRequired || requested == model.PolicyStatusDraft {
+		return model.PolicyStatusDraft
+	}
+	return model.PolicyStatusActive
+}
+
+// editedPolicyStatus is the status a policy with status current has once edited. With approval
+// required, an edited active policy goes back to draft, so its changes are only evaluated once
+// approved again; any other policy keeps its status.
+func editedPolicyStatus(current string) string {
+	if current == "" {
+		current = model.PolicyStatusActive
+	}
+	if policyApprovalRequired && current == model.PolicyStatusActive {
+		return model.PolicyStatusDraft
+	}
+	return current
+}
+
+// SubmitForApproval moves a draft or rejected policy to pending approval
+func (s *PolicyService) SubmitForApproval(ctx context.Context, policyID string, actorID string, comment string) (*model.Policy, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.changePolicyStatus(ctx, policyID, model.PolicyStatusPendingApproval, "submitted", actorID, comment)
+}
+
+// ApprovePolicy makes a policy pending approval active, so access evaluation starts considering it
+func (s *PolicyService) ApprovePolicy(ctx context.Context, policyID string, actorID string, comment string) (*model.Policy, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.changePolicyStatus(ctx, policyID, model.PolicyStatusActive, "approved", actorID, comment)
+}
+
+// RejectPolicy sends a policy pending approval back to its author, who may change and resubmit it
+func (s *PolicyService) RejectPolicy(ctx context.Context, policyID string, actorID string, comment string) (*model.Policy, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.changePolicyStatus(ctx, policyID, model.PolicyStatusRejected, "rejected", actorID, comment)
+}
+
+func (s *PolicyService) changePolicyStatus(ctx context.Context, policyID string, status string, action string, actorID string, comment string) (*model.Policy, error) {
+	oldPolicy, err := s.policyDAO.GetPolicy(ctx, policyID)
+	if err != nil {
+		logger.Error("Error retrieving existing policy", zap.Error(err), zap.String("policyID", policyID))
+		return nil, err
+	}
+
+	if err := checkTenantAccess(ctx, TenantEntityPolicy, oldPolicy.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, next := range policyStatusTransitions[oldPolicy.Status] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: %s to %s", echo_errors.ErrInvalidPolicyStatusTransition, oldPolicy.Status, status)
+	}
+
+	// An approved policy starts being evaluated, so it must not tie with the active ones
+	if status == model.PolicyStatusActive {
+		approved := *oldPolicy
+		approved.Status = status
+		if err := s.checkPolicyConflicts(ctx, approved); err != nil {
+			return nil, fmt.Errorf("policy conflict: %w", err)
+		}
+	}
+
+	updatedPolicy, err := s.policyDAO.SetPolicyStatus(ctx, policyID, oldPolicy.Status, status, actorID, comment)
+	if err != nil {
+		logger.Error("Error changing policy status", zap.Error(err), zap.String("policyID", policyID), zap.String("status", status), zap.String("actorID", actorID))
+		return nil, err
+	}
+
+	// Update cache
+	if err := s.cacheService.SetPolicy(ctx, *updatedPolicy); err != nil {
+		logger.Warn("Failed to update policy in cache", zap.Error(err), zap.String("policyID", policyID))
+	}
+
+	// The status decides whether the policy is evaluated, so subscribers treat the change as an update
+	s.eventBus.Publish(ctx, "policy.updated", map[string]interface{}{
+		"old": *oldPolicy,
+		"new": *updatedPolicy,
+	})
+	s.eventBus.Publish(ctx, "policy."+action, *updatedPolicy)
+
+	logger.Info("Policy status changed successfully",
+		zap.String("policyID", policyID),
+		zap.String("oldStatus", oldPolicy.Status),
+		zap.String("newStatus", status),
+		zap.String("actorID", actorID))
+	return updatedPolicy, nil
+}
+
+// handlePolicyReviewed tells people a policy was submitted, approved or rejected
+func (s *PolicyService) handlePolicyReviewed(ctx context.Context, event util.Event) error {
+	policy, ok := event.Payload.(model.Policy)
+	if !ok {
+		logger.Error("Invalid event payload type", zap.Any("payload", event.Payload))
+		return fmt.Errorf("invalid event payload type: %T", event.Payload)
+	}
+
+	action := strings.TrimPrefix(event.Type, "policy.")
+	if err := s.notificationSvc.NotifyPolicyChange(ctx, action, policy); err != nil {
+		logger.Warn("Failed to send policy review notification", zap.Error(err), zap.String("policyID", policy.ID), zap.String("action", action))
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/audit"
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestPolicyApprovalWorkflow(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	// An unreachable cache, as the approval only writes to it
+	previousClient := db.RedisClient
+	db.RedisClient = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer func() { db.RedisClient = previousClient }()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "reviewer")
+	subject := &model.User{ID: "u1", Status: model.UserStatusActive}
+	resource := &model.Resource{ID: "res1", Type: "DOCUMENT"}
+	request := model.AccessRequest{SubjectID: "u1", ResourceID: "res1", Action: "read"}
+
+	policyNode := func(status string, extra map[string]any) neo4j.Node {
+		props := map[string]any{
+			"id":                "p1",
+			"name":              "Docs",
+			"description":       "",
+			"effect":            echo_neo4j.PolicyEffectAllow,
+			"priority":          int64(1),
+			"version":           int64(1),
+			"createdAt":         "2024-01-01T00:00:00Z",
+			"updatedAt":         "2024-01-01T00:00:00Z",
+			"active":            true,
+			"subjects":          `[{"type":"user","user_id":"u1"}]`,
+			"resourceTypes":     `["DOCUMENT"]`,
+			"attributeGroups":   "[]",
+			"actions":           `["read"]`,
+			"conditions":        "[]",
+			"dynamicAttributes": "[]",
+			"status":            status,
+		}
+		for key, value := range extra {
+			props[key] = value
+		}
+		return neo4j.Node{Props: props}
+	}
+	resultOf := func(node neo4j.Node) *mock.MockResult {
+		result := &mock.MockResult{}
+		result.On("Next").Return(true).Once()
+		result.On("Next").Return(false)
+		result.On("Record").Return(&neo4j.Record{Values: []any{node}})
+		return result
+	}
+	// newService reads stored from the database and writes written as the outcome of a status change
+	newService := func(stored neo4j.Node, written *neo4j.Node) (*service.PolicyService, *mock.MockTransaction, *mock.MockAuditService) {
+		transaction := &mock.MockTransaction{}
+		if written != nil {
+			transaction.On("Run", testify_mock.Anything, testify_mock.Anything).Return(resultOf(*written), nil)
+		}
+		session := &mock.MockTxSession{Tx: transaction}
+		session.On("Run", testify_mock.Anything, map[string]interface{}{"id": "p1"}, testify_mock.Anything).Return(resultOf(stored), nil)
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		auditService := &mock.MockAuditService{}
+		auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+		policyService := service.NewPolicyService(
+			&dao.PolicyDAO{Driver: driver, AuditService: auditService},
+			nil,
+			nil,
+			util.NewValidationUtil(),
+			util.NewCacheService(nil),
+			util.NewNotificationService(0, nil),
+			util.NewEventBus(),
+			nil,
+		)
+		return policyService, transaction, auditService
+	}
+
+	t.Run("DraftIsEvaluatedOnlyOnceApproved", func(t *testing.T) {
+		draft := &model.Policy{
+			ID:            "p1",
+			Name:          "Docs",
+			Effect:        echo_neo4j.PolicyEffectAllow,
+			Active:        true,
+			Status:        model.PolicyStatusDraft,
+			Subjects:      []model.Subject{{Type: "user", UserID: "u1"}},
+			ResourceTypes: []string{"DOCUMENT"},
+			Actions:       []string{"read"},
+		}
+		assert.False(t, service.EvaluatePolicies([]*model.Policy{draft}, subject, resource, request, draft.CreatedAt).Allowed)
+
+		approvedNode := policyNode(model.PolicyStatusActive, map[string]any{
+			"reviewedBy":    "reviewer",
+			"reviewedAt":    "2024-01-02T00:00:00Z",
+			"reviewComment": "Looks good",
+		})
+		policyService, transaction, auditService := newService(policyNode(model.PolicyStatusPendingApproval, nil), &approvedNode)
+
+		approved, err := policyService.ApprovePolicy(ctx, "p1", "reviewer", "Looks good")
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PolicyStatusActive, approved.Status)
+		assert.Equal(t, "reviewer", approved.ReviewedBy)
+		assert.Equal(t, "Looks good", approved.ReviewComment)
+		assert.True(t, service.EvaluatePolicies([]*model.Policy{approved}, subject, resource, request, approved.CreatedAt).Allowed)
+
+		transaction.AssertCalled(t, "Run", testify_mock.Anything, testify_mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["from"] == model.PolicyStatusPendingApproval && params["status"] == model.PolicyStatusActive && params["actorID"] == "reviewer"
+		}))
+		auditService.AssertCalled(t, "LogAccess", testify_mock.Anything, testify_mock.MatchedBy(func(log audit.AuditLog) bool {
+			return log.Action == "APPROVE_POLICY" && log.UserID == "reviewer"
+		}))
+	})
+
+	t.Run("DraftCannotBeApproved", func(t *testing.T) {
+		policyService, transaction, _ := newService(policyNode(model.PolicyStatusDraft, nil), nil)
+
+		policy, err := policyService.ApprovePolicy(ctx, "p1", "reviewer", "")
+
+		assert.Nil(t, policy)
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidPolicyStatusTransition))
+		status, code := util.MapError(err)
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, "INVALID_POLICY_STATUS_TRANSITION", code)
+		transaction.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("PoliciesStoredBeforeApprovalsAreActive", func(t *testing.T) {
+		legacy := policyNode("", nil)
+		delete(legacy.Props, "status")
+		policyService, _, _ := newService(legacy, nil)
+
+		_, err := policyService.SubmitForApproval(ctx, "p1", "author", "")
+
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidPolicyStatusTransition))
+		assert.Contains(t, err.Error(), "active to pending_approval")
+	})
+}
+
+func TestEditingActivePolicyRequiresApprovalAgain(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer service.SetPolicyApprovalRequired(false)
+
+	writeReached := errors.New("write reached")
+	stored := &neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+		"id":                "p1",
+		"name":              "Docs",
+		"description":       "",
+		"effect":            "allow",
+		"priority":          int64(1),
+		"version":           int64(1),
+		"createdAt":         "2024-01-01T00:00:00Z",
+		"updatedAt":         "2024-01-01T00:00:00Z",
+		"active":            true,
+		"subjects":          `[{"type":"user","user_id":"u1"}]`,
+		"resourceTypes":     `["DOCUMENT"]`,
+		"attributeGroups":   "[]",
+		"actions":           `["read"]`,
+		"conditions":        "[]",
+		"dynamicAttributes": "[]",
+		"status":            model.PolicyStatusActive,
+	}}}}
+	edited := model.Policy{
+		ID:            "p1",
+		Name:          "Docs",
+		Effect:        "allow",
+		Priority:      1,
+		Active:        true,
+		Subjects:      []model.Subject{{Type: "user", UserID: "u1"}},
+		ResourceTypes: []string{"DOCUMENT"},
+		Actions:       []string{"read", "write"},
+	}
+	// update runs the edit and returns the status it was written with
+	update := func() interface{} {
+		driver := mock.NewFakeDriver().
+			Fails("p.status = coalesce($status, p.status)", writeReached).
+			Returns("MATCH (p:"+echo_neo4j.LabelPolicy+" {id: $id})", stored)
+		auditService := &mock.MockAuditService{}
+		policyService := service.NewPolicyService(
+			&dao.PolicyDAO{Driver: driver, AuditService: auditService},
+			nil,
+			nil,
+			util.NewValidationUtil(),
+			nil,
+			nil,
+			util.NewEventBus(),
+			nil,
+		)
+
+		_, err := policyService.UpdatePolicy(context.Background(), edited, "u1")
+
+		assert.ErrorIs(t, err, writeReached)
+		writes := driver.QueriesContaining("p.status = coalesce($status, p.status)")
+		if !assert.Len(t, writes, 1) {
+			return nil
+		}
+		return writes[0].Params["status"]
+	}
+
+	t.Run("ApprovalRequired", func(t *testing.T) {
+		service.SetPolicyApprovalRequired(true)
+
+		assert.Equal(t, model.PolicyStatusDraft, update())
+	})
+
+	t.Run("ApprovalNotRequired", func(t *testing.T) {
+		service.SetPolicyApprovalRequired(false)
+
+		assert.Equal(t, model.PolicyStatusActive, update())
+	})
+}
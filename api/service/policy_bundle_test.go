@@ -0,0 +1,226 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestPolicyBundle(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	signingKey := "bundle-signing-key"
+	service.SetPolicyBundleSigningKey(signingKey)
+	defer service.SetPolicyBundleSigningKey("")
+
+	resultOf := func(records ...*neo4j.Record) *mock.MockResult {
+		result := &mock.MockResult{}
+		for _, record := range records {
+			result.On("Next").Return(true).Once()
+			result.On("Record").Return(record).Once()
+		}
+		result.On("Next").Return(false)
+		return result
+	}
+	policyRecord := func(id, effect string, priority int64, subjects, resourceTypes, actions, conditions string) *neo4j.Record {
+		return &neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+			"id":                id,
+			"name":              id,
+			"description":       "",
+			"effect":            effect,
+			"priority":          priority,
+			"version":           int64(1),
+			"createdAt":         "2024-01-01T00:00:00Z",
+			"updatedAt":         "2024-01-01T00:00:00Z",
+			"active":            true,
+			"subjects":          subjects,
+			"resourceTypes":     resourceTypes,
+			"attributeGroups":   "[]",
+			"actions":           actions,
+			"conditions":        conditions,
+			"dynamicAttributes": "[]",
+		}}}}
+	}
+	resourceNode := func(id, resourceType, ownerID string) neo4j.Node {
+		return mock.ResourceNode(id, map[string]any{
+			"name":      id,
+			"type":      resourceType,
+			"typeID":    "",
+			"ownerID":   ownerID,
+			"createdBy": ownerID,
+			"updatedBy": ownerID,
+		})
+	}
+
+	// Editors may read and write, viewers may read, nobody writes spreadsheets and anyone in the
+	// organization may delete what u2 owns
+	users := map[string]*neo4j.Record{
+		"u1": userRecord("u1", model.UserStatusActive),
+		"u2": userRecord("u2", model.UserStatusActive),
+		"u3": userRecord("u3", model.UserStatusSuspended),
+	}
+	memberships := map[string]*neo4j.Record{
+		"u1": {Values: []any{[]interface{}{"g1"}, []interface{}{"editor"}, []interface{}{}}},
+		"u2": {Values: []any{[]interface{}{}, []interface{}{"viewer"}, []interface{}{}}},
+		"u3": {Values: []any{[]interface{}{}, []interface{}{"editor"}, []interface{}{}}},
+	}
+	resources := map[string]neo4j.Node{
+		"doc":   resourceNode("doc", "DOCUMENT", "u2"),
+		"sheet": resourceNode("sheet", "SPREADSHEET", "u1"),
+	}
+	policies := []*neo4j.Record{
+		policyRecord("p-edit", echo_neo4j.PolicyEffectAllow, 1, `[{"type":"role","attributes":{"id":"editor"}}]`, "[]", `["read","write"]`, "[]"),
+		policyRecord("p-view", echo_neo4j.PolicyEffectAllow, 1, `[{"type":"role","attributes":{"id":"viewer"}}]`, "[]", `["read"]`, "[]"),
+		policyRecord("p-sheets", echo_neo4j.PolicyEffectDeny, 5, `[{"type":"role","attributes":{"id":"*"}}]`, `["SPREADSHEET"]`, `["write"]`, "[]"),
+		policyRecord("p-owner", echo_neo4j.PolicyEffectAllow, 1, `[{"type":"organization","attributes":{"id":"org1"}}]`, "[]", `["delete"]`,
+			`[{"attribute":"resource.owner_id","operator":"equals","value":"u2"}]`),
+		policyRecord("p-org1", echo_neo4j.PolicyEffectAllow, 1, `[{"type":"role","attributes":{"id":"auditor"}}]`, "[]", `["read"]`, "[]"),
+		policyRecord("p-org2", echo_neo4j.PolicyEffectAllow, 1, `[{"type":"role","attributes":{"id":"auditor"}}]`, "[]", `["read"]`, "[]"),
+	}
+	policies[4].Values[0].(neo4j.Node).Props["organizationID"] = "org1"
+	policies[5].Values[0].(neo4j.Node).Props["organizationID"] = "org2"
+
+	session := &mock.MockSession{}
+	session.On("Close").Return(nil)
+	respond := func(fragment string, records func(params map[string]any) []*neo4j.Record) {
+		call := session.On("Run", testify_mock.MatchedBy(func(query string) bool { return strings.Contains(query, fragment) }), testify_mock.Anything, testify_mock.Anything)
+		call.Run(func(args testify_mock.Arguments) {
+			call.ReturnArguments = testify_mock.Arguments{resultOf(records(args.Get(1).(map[string]any))...), nil}
+		})
+	}
+	respond("count(u) AS total", func(map[string]any) []*neo4j.Record {
+		return []*neo4j.Record{{Values: []any{true, int64(len(users))}}}
+	})
+	respond("{id: $orgID})<-[:"+echo_neo4j.RelWorksFor+"]-(u:", func(map[string]any) []*neo4j.Record {
+		return []*neo4j.Record{users["u1"], users["u2"], users["u3"]}
+	})
+	respond("count(r) AS total", func(map[string]any) []*neo4j.Record {
+		return []*neo4j.Record{{Values: []any{true, int64(len(resources))}}}
+	})
+	respond("{id: $orgID})<-[:BELONGS_TO]-(r:", func(map[string]any) []*neo4j.Record {
+		return []*neo4j.Record{{Values: []any{resources["doc"]}}, {Values: []any{resources["sheet"]}}}
+	})
+	respond("AS permissionIDs", func(params map[string]any) []*neo4j.Record {
+		return []*neo4j.Record{memberships[params["id"].(string)]}
+	})
+	respond("AS parentID", func(params map[string]any) []*neo4j.Record {
+		return []*neo4j.Record{{Keys: []string{"r", "parentID", "relatedIDs"}, Values: []any{resources[params["id"].(string)], nil, []any{}}}}
+	})
+	respond("RETURN u, roleIds", func(params map[string]any) []*neo4j.Record {
+		return []*neo4j.Record{users[params["id"].(string)]}
+	})
+	respond("WHERE p.active = true", func(map[string]any) []*neo4j.Record { return policies })
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	auditService := &mock.MockAuditService{}
+
+	accessService := service.NewAccessService(
+		&dao.UserDAO{Driver: driver, AuditService: auditService},
+		&dao.ResourceDAO{Driver: driver, AuditService: auditService},
+		&dao.PolicyDAO{Driver: driver, AuditService: auditService},
+		nil,
+		util.NewEventBus(),
+		nil,
+	)
+
+	bundle, err := accessService.ExportPolicyBundle(ctx, "org1")
+	require.NoError(t, err)
+
+	t.Run("The bundle evaluator agrees with the live evaluation", func(t *testing.T) {
+		evaluator, err := service.LoadPolicyBundle(bundle, []byte(signingKey))
+		require.NoError(t, err)
+
+		allowed := 0
+		for _, subjectID := range []string{"u1", "u2", "u3"} {
+			for _, resourceID := range []string{"doc", "sheet"} {
+				for _, action := range []string{"read", "write", "delete"} {
+					request := model.AccessRequest{SubjectID: subjectID, ResourceID: resourceID, Action: action}
+					t.Run(fmt.Sprintf("%s %s %s", subjectID, action, resourceID), func(t *testing.T) {
+						live, err := accessService.EvaluateAccess(ctx, request)
+						require.NoError(t, err)
+
+						offline, err := evaluator.Evaluate(request)
+
+						require.NoError(t, err)
+						assert.Equal(t, live.Allowed, offline.Allowed)
+						assert.Equal(t, live.Effect, offline.Effect)
+						assert.Equal(t, live.PolicyID, offline.PolicyID)
+						assert.Equal(t, live.Reason, offline.Reason)
+						if live.Allowed {
+							allowed++
+						}
+					})
+				}
+			}
+		}
+		// The sample covers both outcomes: u1 reads, writes and deletes the document and reads the sheet,
+		// u2 reads both and deletes the document, and u3 is suspended
+		assert.Equal(t, 7, allowed)
+	})
+
+	t.Run("Only the organization's and shared policies are bundled", func(t *testing.T) {
+		var signed model.SignedPolicyBundle
+		require.NoError(t, json.Unmarshal(bundle, &signed))
+		var contents model.PolicyBundle
+		require.NoError(t, json.Unmarshal(signed.Bundle, &contents))
+
+		var policyIDs []string
+		for _, policy := range contents.Policies {
+			policyIDs = append(policyIDs, policy.ID)
+		}
+		assert.ElementsMatch(t, []string{"p-edit", "p-view", "p-sheets", "p-owner", "p-org1"}, policyIDs)
+	})
+
+	t.Run("Subjects and resources outside the bundle are not found", func(t *testing.T) {
+		evaluator, err := service.LoadPolicyBundle(bundle, []byte(signingKey))
+		require.NoError(t, err)
+
+		_, err = evaluator.Evaluate(model.AccessRequest{SubjectID: "u9", ResourceID: "doc", Action: "read"})
+		assert.ErrorIs(t, err, echo_errors.ErrUserNotFound)
+		_, err = evaluator.Evaluate(model.AccessRequest{SubjectID: "u1", ResourceID: "r9", Action: "read"})
+		assert.ErrorIs(t, err, echo_errors.ErrResourceNotFound)
+	})
+
+	t.Run("Altered bundles are rejected", func(t *testing.T) {
+		tampered := bytes.Replace(bundle, []byte(`"role_ids":["viewer"]`), []byte(`"role_ids":["editor"]`), 1)
+		require.False(t, bytes.Equal(bundle, tampered))
+
+		_, err := service.LoadPolicyBundle(tampered, []byte(signingKey))
+
+		assert.ErrorIs(t, err, echo_errors.ErrInvalidPolicyBundle)
+	})
+
+	t.Run("Bundles signed with another key are rejected", func(t *testing.T) {
+		_, err := service.LoadPolicyBundle(bundle, []byte("another-key"))
+
+		assert.ErrorIs(t, err, echo_errors.ErrInvalidPolicyBundle)
+	})
+
+	t.Run("Exports need a signing key", func(t *testing.T) {
+		service.SetPolicyBundleSigningKey("")
+		defer service.SetPolicyBundleSigningKey(signingKey)
+
+		_, err := accessService.ExportPolicyBundle(ctx, "org1")
+
+		assert.ErrorIs(t, err, echo_errors.ErrPolicyBundleUnavailable)
+	})
+}
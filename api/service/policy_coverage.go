@@ -0,0 +1,165 @@
+// api/service/policy_coverage.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+// CoverageReport reports the shape of the policy set governing an organization and its coverage gaps:
+// the organization's resources and users that no evaluated policy applies to, whatever the action.
+// A policy covers a resource its resource types and attribute groups match, directly or through an
+// ancestor the resource inherits from, and a user one of its subjects matches through the user's
+// direct and inherited roles and groups; conditions are not considered, so a covered resource or user
+// may still be left to the default effect.
+func (s *PolicyService) CoverageReport(ctx context.Context, orgID string) (*model.CoverageReport, error) {
+	if err := checkTenantAccess(ctx, TenantEntityResource, orgID); err != nil {
+		return nil, err
+	}
+
+	var policies []*model.Policy
+	limit := helper_util.MaxPageLimit()
+	for offset := 0; ; offset += limit {
+		page, err := s.policyDAO.ListPolicies(ctx, limit, offset)
+		if err != nil {
+			logger.Error("Error listing policies for coverage report", zap.Error(err))
+			return nil, fmt.Errorf("failed to list policies: %w", err)
+		}
+		for _, policy := range page {
+			if policy.OrganizationID == "" || policy.OrganizationID == orgID {
+				policies = append(policies, policy)
+			}
+		}
+		if len(page) < limit {
+			break
+		}
+	}
+
+	var subjects []*model.User
+	for offset := 0; ; offset += limit {
+		users, _, err := s.userDAO.GetUsersByOrganization(ctx, orgID, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization users: %w", err)
+		}
+		for _, user := range users {
+			effective, err := s.userDAO.GetEffectivePermissions(ctx, user.ID)
+			if err != nil {
+				logger.Error("Error resolving user memberships for coverage report", zap.Error(err), zap.String("userID", user.ID))
+				return nil, fmt.Errorf("failed to resolve effective permissions of user %s: %w", user.ID, err)
+			}
+			subject := *user
+			subject.RoleIds = effective.RoleIDs
+			subject.GroupIds = effective.GroupIDs
+			subjects = append(subjects, &subject)
+		}
+		if len(users) < limit {
+			break
+		}
+	}
+
+	var resources []*model.Resource
+	for offset := 0; ; offset += limit {
+		page, _, err := s.resourceDAO.GetResourcesByOrganization(ctx, orgID, false, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization resources: %w", err)
+		}
+		resources = append(resources, page...)
+		if len(page) < limit {
+			break
+		}
+	}
+
+	report := policyCoverage(orgID, policies, subjects, resources, time.Now())
+	logger.Info("Policy coverage report generated",
+		zap.String("organizationID", orgID),
+		zap.Int("policies", report.PolicyCount),
+		zap.Int("uncoveredResources", len(report.UncoveredResourceIDs)),
+		zap.Int("uncoveredSubjects", len(report.UncoveredSubjectIDs)))
+	return report, nil
+}
+
+func policyCoverage(orgID string, policies []*model.Policy, subjects []*model.User, resources []*model.Resource, now time.Time) *model.CoverageReport {
+	report := &model.CoverageReport{
+		OrganizationID:       orgID,
+		GeneratedAt:          now,
+		PolicyCount:          len(policies),
+		PriorityDistribution: []model.PolicyPriorityCount{},
+		ResourceCount:        len(resources),
+		SubjectCount:         len(subjects),
+		UncoveredResourceIDs: []string{},
+		UncoveredSubjectIDs:  []string{},
+	}
+
+	var evaluated []*model.Policy
+	priorities := make(map[int]int)
+	for _, policy := range policies {
+		if strings.EqualFold(policy.Effect, echo_neo4j.PolicyEffectDeny) {
+			report.DenyCount++
+		} else {
+			report.AllowCount++
+		}
+		priorities[policy.Priority]++
+		if policyInactivity(policy, now) != "" {
+			report.InactiveCount++
+			continue
+		}
+		evaluated = append(evaluated, policy)
+	}
+	for priority, count := range priorities {
+		report.PriorityDistribution = append(report.PriorityDistribution, model.PolicyPriorityCount{Priority: priority, Count: count})
+	}
+	sort.Slice(report.PriorityDistribution, func(i, j int) bool {
+		return report.PriorityDistribution[i].Priority > report.PriorityDistribution[j].Priority
+	})
+
+	byID := make(map[string]*model.Resource, len(resources))
+	for _, resource := range resources {
+		byID[resource.ID] = resource
+	}
+	for _, resource := range resources {
+		if !resourceCovered(evaluated, append([]*model.Resource{resource}, inheritingAncestors(resource, byID)...)) {
+			report.UncoveredResourceIDs = append(report.UncoveredResourceIDs, resource.ID)
+		}
+	}
+	for _, subject := range subjects {
+		if !subjectCovered(evaluated, subject) {
+			report.UncoveredSubjectIDs = append(report.UncoveredSubjectIDs, subject.ID)
+		}
+	}
+	sort.Strings(report.UncoveredResourceIDs)
+	sort.Strings(report.UncoveredSubjectIDs)
+	return report
+}
+
+// resourceCovered reports whether a policy applies to a resource or one of the ancestors it inherits from
+func resourceCovered(policies []*model.Policy, lineage []*model.Resource) bool {
+	for _, policy := range policies {
+		for _, resource := range lineage {
+			if resourceMismatch(policy, resource) == "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func subjectCovered(policies []*model.Policy, subject *model.User) bool {
+	for _, policy := range policies {
+		for _, policySubject := range policy.Subjects {
+			if subjectMatches(policySubject, subject) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+)
+
+func TestPolicyCoverage(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(24 * time.Hour)
+	policies := []*model.Policy{
+		{
+			ID: "editors", Effect: echo_neo4j.PolicyEffectAllow, Priority: 10, Active: true,
+			Actions: []string{"read"}, ResourceTypes: []string{"document"},
+			Subjects: []model.Subject{{Type: "role", Attributes: map[string]string{"id": "editor"}}},
+			// Conditions do not matter to coverage
+			Conditions: []model.Condition{{Attribute: "context.mfa", Operator: "equals", Value: true}},
+		},
+		{
+			ID: "secrets", Effect: echo_neo4j.PolicyEffectDeny, Priority: 10, Active: true,
+			Actions: []string{"*"}, AttributeGroups: []string{"secret"},
+			Subjects: []model.Subject{{Type: "user", UserID: "auditor"}},
+		},
+		{
+			ID: "everyone", Effect: echo_neo4j.PolicyEffectAllow, Priority: 5, Active: false,
+			Actions: []string{"*"}, ResourceTypes: []string{"*"},
+			Subjects: []model.Subject{{Type: "role", Attributes: map[string]string{"id": "*"}}},
+		},
+		{
+			ID: "pending", Effect: echo_neo4j.PolicyEffectAllow, Priority: 1, Active: true, Status: model.PolicyStatusPendingApproval,
+			Actions: []string{"*"}, ResourceTypes: []string{"image"},
+			Subjects: []model.Subject{{Type: "organization", Attributes: map[string]string{"id": "org1"}}},
+		},
+		{
+			ID: "scheduled", Effect: echo_neo4j.PolicyEffectAllow, Priority: 1, Active: true, ActivationDate: &later,
+			Actions: []string{"*"}, ResourceTypes: []string{"image"},
+			Subjects: []model.Subject{{Type: "organization", Attributes: map[string]string{"id": "org1"}}},
+		},
+	}
+	resources := []*model.Resource{
+		{ID: "report", Type: "document"},
+		{ID: "payroll", Type: "spreadsheet", AttributeGroupID: "secret"},
+		{ID: "logo", Type: "image"},
+		{ID: "attachment", Type: "image", ParentID: "report", InheritedACL: true},
+		{ID: "thumbnail", Type: "image", ParentID: "report"},
+	}
+	subjects := []*model.User{
+		{ID: "alice", OrganizationID: "org1", RoleIds: []string{"editor"}},
+		{ID: "auditor", OrganizationID: "org1"},
+		{ID: "bob", OrganizationID: "org1", RoleIds: []string{"viewer"}},
+	}
+
+	report := service.PolicyCoverage("org1", policies, subjects, resources, now)
+
+	assert.Equal(t, "org1", report.OrganizationID)
+	assert.Equal(t, 5, report.PolicyCount)
+	assert.Equal(t, 4, report.AllowCount)
+	assert.Equal(t, 1, report.DenyCount)
+	assert.Equal(t, 3, report.InactiveCount)
+	assert.Equal(t, []model.PolicyPriorityCount{{Priority: 10, Count: 2}, {Priority: 5, Count: 1}, {Priority: 1, Count: 2}}, report.PriorityDistribution)
+	assert.Equal(t, 5, report.ResourceCount)
+	assert.Equal(t, 3, report.SubjectCount)
+	// The logo and the thumbnail, which does not inherit from its covered parent, are left to the default
+	assert.Equal(t, []string{"logo", "thumbnail"}, report.UncoveredResourceIDs)
+	assert.Equal(t, []string{"bob"}, report.UncoveredSubjectIDs)
+
+	t.Run("An empty policy set covers nothing", func(t *testing.T) {
+		report := service.PolicyCoverage("org1", nil, subjects, resources, now)
+
+		assert.Zero(t, report.PolicyCount)
+		assert.Empty(t, report.PriorityDistribution)
+		assert.Len(t, report.UncoveredResourceIDs, 5)
+		assert.Equal(t, []string{"alice", "auditor", "bob"}, report.UncoveredSubjectIDs)
+	})
+}
@@ -0,0 +1,76 @@
+// api/service/policy_patterns.go
+package service
+
+import (
+	"strings"
+
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// How closely a policy's actions or resource types match a request, from the loosest to the closest.
+// Among matching policies sharing a priority, the closest match decides.
+const (
+	matchNone     = iota
+	matchWildcard // Matched by "*" alone
+	matchPattern  // Matched by a pattern such as "project/*" or "read?"
+	matchExact    // Listed as is
+)
+
+// patternMatch returns how closely the closest of patterns matches value. Patterns are globs, see
+// util.GlobMatch, and only "*" matches an empty value.
+func patternMatch(patterns []string, value string) int {
+	best := matchNone
+	for _, pattern := range patterns {
+		level := matchNone
+		if pattern == "*" {
+			level = matchWildcard
+		} else if literal, ok := util.GlobLiteral(pattern); ok {
+			if value != "" && literal == value {
+				level = matchExact
+			}
+		} else if value != "" && util.GlobMatch(pattern, value) {
+			level = matchPattern
+		}
+		if level > best {
+			best = level
+		}
+	}
+	return best
+}
+
+// policySpecificity returns how closely the policy matches the action, then the resource type, of a
+// request it covers. Policies listing no resource types cover every type, as "*" does.
+func policySpecificity(policy *model.Policy, resource *model.Resource, action string) [2]int {
+	resourceMatch := matchWildcard
+	if len(policy.ResourceTypes) > 0 {
+		resourceMatch = max(patternMatch(policy.ResourceTypes, resource.Type), patternMatch(policy.ResourceTypes, resource.TypeID))
+	}
+	return [2]int{patternMatch(policy.Actions, action), resourceMatch}
+}
+
+// moreSpecific reports whether specificity a outranks b, comparing actions first
+func moreSpecific(a, b [2]int) bool {
+	if a[0] != b[0] {
+		return a[0] > b[0]
+	}
+	return a[1] > b[1]
+}
+
+// patternsIntersect reports whether some value may match both patterns. Patterns holding wildcards are
+// only told apart by their literal starts, so "read:*" and "write:*" are, while "*:docs" and "*:sheets"
+// are assumed to intersect.
+func patternsIntersect(a, b string) bool {
+	aLiteral, aIsLiteral := util.GlobLiteral(a)
+	bLiteral, bIsLiteral := util.GlobLiteral(b)
+	switch {
+	case aIsLiteral && bIsLiteral:
+		return aLiteral == bLiteral
+	case aIsLiteral:
+		return util.GlobMatch(b, aLiteral)
+	case bIsLiteral:
+		return util.GlobMatch(a, bLiteral)
+	}
+	aPrefix, bPrefix := util.GlobPrefix(a), util.GlobPrefix(b)
+	return strings.HasPrefix(aPrefix, bPrefix) || strings.HasPrefix(bPrefix, aPrefix)
+}
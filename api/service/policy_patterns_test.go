@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+)
+
+func TestPolicyPatterns(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	subject := &model.User{ID: "u1", Status: model.UserStatusActive}
+	resource := &model.Resource{ID: "project/alpha/plan", Type: "project/alpha"}
+	read := model.AccessRequest{SubjectID: "u1", ResourceID: resource.ID, Action: "read"}
+	now := time.Now()
+
+	policy := func(id, effect string, resourceTypes []string, actions ...string) *model.Policy {
+		return &model.Policy{
+			ID:            id,
+			Name:          id,
+			Effect:        effect,
+			Priority:      10,
+			Active:        true,
+			Subjects:      []model.Subject{{Type: "user", UserID: "u1"}},
+			ResourceTypes: resourceTypes,
+			Actions:       actions,
+		}
+	}
+	decide := func(request model.AccessRequest, policies ...*model.Policy) *model.AccessDecision {
+		return service.EvaluatePolicies(policies, subject, resource, request, now)
+	}
+
+	t.Run("Matches", func(t *testing.T) {
+		matches := map[string]*model.Policy{
+			"Exact":           policy("exact", echo_neo4j.PolicyEffectAllow, []string{"project/alpha"}, "read"),
+			"Prefix wildcard": policy("prefix", echo_neo4j.PolicyEffectAllow, []string{"project/*"}, "re*"),
+			"Full wildcard":   policy("wildcard", echo_neo4j.PolicyEffectAllow, []string{"*"}, "*"),
+			"Single letter":   policy("single", echo_neo4j.PolicyEffectAllow, []string{"project/alph?"}, "rea?"),
+		}
+		for name, matching := range matches {
+			t.Run(name, func(t *testing.T) {
+				assert.True(t, decide(read, matching).Allowed)
+			})
+		}
+
+		misses := map[string]*model.Policy{
+			"Another prefix":  policy("other-prefix", echo_neo4j.PolicyEffectAllow, []string{"team/*"}, "read"),
+			"Too short":       policy("too-short", echo_neo4j.PolicyEffectAllow, []string{"project/alpha"}, "rea"),
+			"Escaped star":    policy("escaped", echo_neo4j.PolicyEffectAllow, []string{`project/\*`}, "read"),
+			"Escaped letter":  policy("escaped-letter", echo_neo4j.PolicyEffectAllow, []string{"project/alpha"}, `re\?d`),
+			"Two letters off": policy("two-off", echo_neo4j.PolicyEffectAllow, []string{"project/alpha"}, "r?d"),
+		}
+		for name, missing := range misses {
+			t.Run(name+" does not match", func(t *testing.T) {
+				assert.False(t, decide(read, missing).Allowed)
+			})
+		}
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		exactAllow := policy("exact-allow", echo_neo4j.PolicyEffectAllow, []string{"project/alpha"}, "read")
+		prefixDeny := policy("prefix-deny", echo_neo4j.PolicyEffectDeny, []string{"project/alpha"}, "re*")
+		wildcardAllow := policy("wildcard-allow", echo_neo4j.PolicyEffectAllow, []string{"project/alpha"}, "*")
+
+		// An exact action match beats a prefix, whatever the effects
+		decision := decide(read, wildcardAllow, prefixDeny, exactAllow)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, "exact-allow", decision.PolicyID)
+
+		// A prefix beats the full wildcard
+		decision = decide(read, wildcardAllow, prefixDeny)
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, "prefix-deny", decision.PolicyID)
+
+		// Resource types are compared once the actions match as closely
+		typeDeny := policy("type-deny", echo_neo4j.PolicyEffectDeny, []string{"project/*"}, "read")
+		decision = decide(read, typeDeny, exactAllow)
+		assert.Equal(t, "exact-allow", decision.PolicyID)
+
+		// Among equally close matches a deny still wins
+		exactDeny := policy("exact-deny", echo_neo4j.PolicyEffectDeny, []string{"project/alpha"}, "read")
+		assert.Equal(t, "exact-deny", decide(read, exactAllow, exactDeny).PolicyID)
+
+		// And a higher priority wins over any closer match
+		wildcardAllow.Priority = 20
+		assert.Equal(t, "wildcard-allow", decide(read, exactDeny, wildcardAllow).PolicyID)
+	})
+
+	t.Run("Conditions match patterns", func(t *testing.T) {
+		conditioned := policy("conditioned", echo_neo4j.PolicyEffectAllow, []string{"*"}, "read")
+		conditioned.Conditions = []model.Condition{{Attribute: "resource.id", Operator: "matches", Value: "project/*/plan"}}
+
+		assert.True(t, decide(read, conditioned).Allowed)
+
+		conditioned.Conditions[0].Value = "project/*/budget"
+		assert.False(t, decide(read, conditioned).Allowed)
+	})
+}
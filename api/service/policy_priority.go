@@ -0,0 +1,140 @@
+// api/service/policy_priority.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+)
+
+// How policies sharing a priority are handled
+const (
+	PriorityModeTiebreak = "tiebreak" // Ties are allowed and settled by the evaluation order
+	PriorityModeUnique   = "unique"   // Policies covering some of the same requests may not share a priority
+)
+
+var priorityMode = PriorityModeTiebreak
+
+// SetPriorityMode sets how policies sharing a priority are handled. Unknown modes keep the current
+// one.
+func SetPriorityMode(mode string) {
+	switch mode = strings.ToLower(mode); mode {
+	case PriorityModeTiebreak, PriorityModeUnique:
+		priorityMode = mode
+	default:
+		logger.Warn("Unknown policy priority mode ignored", zap.String("mode", mode), zap.String("current", priorityMode))
+	}
+}
+
+// policyPrecedes reports whether policy a is considered before b when both match a request: the higher
+// priority first, then deny before allow, then the policy created first, then the lower ID. The order
+// is total, so the deciding policy never depends on the order the policies were listed in.
+func policyPrecedes(a, b *model.Policy) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	aDenies := strings.EqualFold(a.Effect, echo_neo4j.PolicyEffectDeny)
+	if bDenies := strings.EqualFold(b.Effect, echo_neo4j.PolicyEffectDeny); aDenies != bDenies {
+		return aDenies
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// checkPriorityConflict returns ErrPriorityConflict when priorities must be unique and an active policy
+// covering some of the requests policy does shares its priority. Policies that are not evaluated yet
+// are checked once they are approved.
+func (s *PolicyService) checkPriorityConflict(ctx context.Context, policy model.Policy) error {
+	if priorityMode != PriorityModeUnique || !policyEvaluated(policy) {
+		return nil
+	}
+
+	policies, err := s.policyDAO.GetActivePolicies(ctx)
+	if err != nil {
+		logger.Error("Error retrieving active policies for the priority check", zap.Error(err))
+		return fmt.Errorf("failed to get active policies: %w", err)
+	}
+	for _, other := range policies {
+		if other.ID != policy.ID && other.Priority == policy.Priority && policiesOverlap(&policy, other) {
+			return fmt.Errorf("%w: policy %q covers some of the same requests at priority %d",
+				echo_errors.ErrPriorityConflict, other.Name, other.Priority)
+		}
+	}
+	return nil
+}
+
+// policiesOverlap reports whether a request may be covered by both policies. Conditions are not
+// compared, as whether two of them can hold at once cannot be told in general, so policies told
+// apart only by their conditions overlap.
+func policiesOverlap(a, b *model.Policy) bool {
+	if (a.DeactivationDate != nil && b.ActivationDate != nil && !a.DeactivationDate.After(*b.ActivationDate)) ||
+		(b.DeactivationDate != nil && a.ActivationDate != nil && !b.DeactivationDate.After(*a.ActivationDate)) {
+		return false
+	}
+	if !valuesIntersect(a.Actions, b.Actions) {
+		return false
+	}
+	if len(a.ResourceTypes) > 0 && len(b.ResourceTypes) > 0 && !valuesIntersect(a.ResourceTypes, b.ResourceTypes) {
+		return false
+	}
+	if len(a.AttributeGroups) > 0 && len(b.AttributeGroups) > 0 && !valuesIntersect(a.AttributeGroups, b.AttributeGroups) {
+		return false
+	}
+	for _, aSubject := range a.Subjects {
+		for _, bSubject := range b.Subjects {
+			if subjectsOverlap(aSubject, bSubject) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// valuesIntersect reports whether some value may match patterns of both
+func valuesIntersect(a, b []string) bool {
+	for _, aPattern := range a {
+		for _, bPattern := range b {
+			if patternsIntersect(aPattern, bPattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subjectsOverlap reports whether one user may match both policy subjects. Only subjects naming
+// different users, departments or organizations, or requiring different values of an attribute, are
+// told apart: a user may hold any number of roles and groups.
+func subjectsOverlap(a, b model.Subject) bool {
+	if strings.EqualFold(a.Type, b.Type) {
+		switch strings.ToLower(a.Type) {
+		case "user":
+			if a.UserID != "" && b.UserID != "" && a.UserID != b.UserID {
+				return false
+			}
+		case "department", "organization":
+			if a.Attributes["id"] != b.Attributes["id"] {
+				return false
+			}
+		}
+	}
+
+	for key, value := range a.Attributes {
+		if key == "id" {
+			continue
+		}
+		if other, ok := b.Attributes[key]; ok && other != value {
+			return false
+		}
+	}
+	return true
+}
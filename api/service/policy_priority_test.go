@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestPriorityTiebreak(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	subject := &model.User{ID: "u1", Status: model.UserStatusActive, RoleIds: []string{"editor"}}
+	resource := &model.Resource{ID: "doc", Type: "DOCUMENT"}
+	request := model.AccessRequest{SubjectID: "u1", ResourceID: "doc", Action: "read"}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	policy := func(id, effect string, createdAt time.Time) *model.Policy {
+		return &model.Policy{
+			ID:        id,
+			Name:      id,
+			Effect:    effect,
+			Priority:  10,
+			Active:    true,
+			CreatedAt: createdAt,
+			Subjects:  []model.Subject{{Type: "role", Attributes: map[string]string{"id": "editor"}}},
+			Actions:   []string{"read"},
+		}
+	}
+	// decidingPolicies evaluates every ordering of policies, returning the deciding policy of each
+	decidingPolicies := func(policies []*model.Policy) map[string]bool {
+		deciding := map[string]bool{}
+		var permute func(prefix, rest []*model.Policy)
+		permute = func(prefix, rest []*model.Policy) {
+			if len(rest) == 0 {
+				deciding[service.EvaluatePolicies(prefix, subject, resource, request, now).PolicyID] = true
+				return
+			}
+			for i := range rest {
+				remaining := append(append([]*model.Policy{}, rest[:i]...), rest[i+1:]...)
+				permute(append(append([]*model.Policy{}, prefix...), rest[i]), remaining)
+			}
+		}
+		permute(nil, policies)
+		return deciding
+	}
+
+	t.Run("The oldest policy decides among allows", func(t *testing.T) {
+		policies := []*model.Policy{
+			policy("newer", echo_neo4j.PolicyEffectAllow, now.Add(-time.Hour)),
+			policy("older", echo_neo4j.PolicyEffectAllow, now.Add(-48*time.Hour)),
+			policy("b-same-age", echo_neo4j.PolicyEffectAllow, now.Add(-24*time.Hour)),
+			policy("a-same-age", echo_neo4j.PolicyEffectAllow, now.Add(-24*time.Hour)),
+		}
+
+		assert.Equal(t, map[string]bool{"older": true}, decidingPolicies(policies))
+	})
+
+	t.Run("The lowest ID decides among policies created together", func(t *testing.T) {
+		policies := []*model.Policy{
+			policy("b", echo_neo4j.PolicyEffectAllow, now),
+			policy("c", echo_neo4j.PolicyEffectAllow, now),
+			policy("a", echo_neo4j.PolicyEffectAllow, now),
+		}
+
+		assert.Equal(t, map[string]bool{"a": true}, decidingPolicies(policies))
+	})
+
+	t.Run("A deny wins whatever its age", func(t *testing.T) {
+		policies := []*model.Policy{
+			policy("old-allow", echo_neo4j.PolicyEffectAllow, now.Add(-48*time.Hour)),
+			policy("new-deny", echo_neo4j.PolicyEffectDeny, now.Add(-time.Hour)),
+			policy("newest-deny", echo_neo4j.PolicyEffectDeny, now),
+		}
+
+		assert.Equal(t, map[string]bool{"new-deny": true}, decidingPolicies(policies))
+		assert.False(t, service.EvaluatePolicies(policies, subject, resource, request, now).Allowed)
+	})
+}
+
+func TestPriorityUniqueness(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+	service.SetPriorityMode(service.PriorityModeUnique)
+	defer service.SetPriorityMode(service.PriorityModeTiebreak)
+
+	// u1 may read and write documents at priority 10
+	activeNode := neo4j.Node{Props: map[string]any{
+		"id":                "p-editors",
+		"name":              "u1 edits documents",
+		"description":       "",
+		"effect":            echo_neo4j.PolicyEffectAllow,
+		"priority":          int64(10),
+		"version":           int64(1),
+		"createdAt":         "2024-01-01T00:00:00Z",
+		"updatedAt":         "2024-01-01T00:00:00Z",
+		"active":            true,
+		"subjects":          `[{"type":"user","user_id":"u1"}]`,
+		"resourceTypes":     `["DOCUMENT"]`,
+		"attributeGroups":   "[]",
+		"actions":           `["read","write"]`,
+		"conditions":        "[]",
+		"dynamicAttributes": "[]",
+	}}
+	newService := func() (*service.PolicyService, *mock.MockSession) {
+		result := &mock.MockResult{}
+		result.On("Next").Return(true).Once()
+		result.On("Next").Return(false)
+		result.On("Record").Return(&neo4j.Record{Values: []any{activeNode}})
+		session := &mock.MockSession{}
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+		session.On("WriteTransaction", testify_mock.Anything, testify_mock.Anything).Return(nil, echo_errors.ErrDatabaseOperation)
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		policyService := service.NewPolicyService(
+			&dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+			nil,
+			nil,
+			util.NewValidationUtil(),
+			nil,
+			nil,
+			util.NewEventBus(),
+			nil,
+		)
+		return policyService, session
+	}
+	newPolicy := func(priority int, subject model.Subject, actions ...string) model.Policy {
+		return model.Policy{
+			Name:          "Deny document access",
+			Effect:        "deny",
+			Priority:      priority,
+			Active:        true,
+			Subjects:      []model.Subject{subject},
+			ResourceTypes: []string{"DOCUMENT"},
+			Actions:       actions,
+		}
+	}
+
+	t.Run("An overlapping policy sharing the priority is rejected", func(t *testing.T) {
+		policyService, session := newService()
+
+		_, err := policyService.CreatePolicy(ctx, newPolicy(10, model.Subject{Type: "user", UserID: "u1"}, "write"), "admin")
+
+		require.True(t, errors.Is(err, echo_errors.ErrPriorityConflict))
+		assert.Contains(t, err.Error(), "u1 edits documents")
+		status, code := util.MapError(err)
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, "PRIORITY_CONFLICT", code)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	// Drafts are not evaluated, so they are only checked once approved
+	draft := newPolicy(10, model.Subject{Type: "user", UserID: "u1"}, "write")
+	draft.Status = model.PolicyStatusDraft
+	allowed := map[string]model.Policy{
+		"Another priority": newPolicy(11, model.Subject{Type: "user", UserID: "u1"}, "write"),
+		"Other actions":    newPolicy(10, model.Subject{Type: "user", UserID: "u1"}, "delete"),
+		"Another user":     newPolicy(10, model.Subject{Type: "user", UserID: "u2"}, "write"),
+		"A draft":          draft,
+	}
+	for name, policy := range allowed {
+		t.Run(name+" goes through", func(t *testing.T) {
+			policyService, session := newService()
+
+			_, err := policyService.CreatePolicy(ctx, policy, "admin")
+
+			assert.False(t, errors.Is(err, echo_errors.ErrPriorityConflict))
+			session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+		})
+	}
+
+	t.Run("Ties go through in tiebreak mode", func(t *testing.T) {
+		service.SetPriorityMode(service.PriorityModeTiebreak)
+		defer service.SetPriorityMode(service.PriorityModeUnique)
+		policyService, session := newService()
+
+		_, err := policyService.CreatePolicy(ctx, newPolicy(10, model.Subject{Type: "user", UserID: "u1"}, "write"), "admin")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrPriorityConflict))
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+}
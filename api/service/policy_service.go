@@ -11,48 +11,78 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IPolicyService defines the interface for policy operations
 type IPolicyService interface {
 	CreatePolicy(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error)
+	CreatePolicyAndFetch(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error)
 	UpdatePolicy(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error)
 	DeletePolicy(ctx context.Context, policyID string, userID string) error
 	GetPolicy(ctx context.Context, policyID string) (*model.Policy, error)
+	GetPoliciesByIDs(ctx context.Context, policyIDs []string) (*model.PolicyBatch, error)
+	GetPolicyWithSubjects(ctx context.Context, policyID string) (*model.PolicyWithSubjects, error)
+	PolicyExists(ctx context.Context, policyID string) (bool, error)
 	ListPolicies(ctx context.Context, limit int, offset int) ([]*model.Policy, error)
+	ListPoliciesByCursor(ctx context.Context, cursor string, limit int) (*model.PolicyPage, error)
 	SearchPolicies(ctx context.Context, criteria model.PolicySearchCriteria) ([]*model.Policy, error)
 	AnalyzePolicyUsage(ctx context.Context, policyID string) (*model.PolicyUsageAnalysis, error)
+	AnalyzePolicySet(ctx context.Context) (*model.PolicySetAnalysis, error)
+	CoverageReport(ctx context.Context, orgID string) (*model.CoverageReport, error)
+	CreatePolicyTemplate(ctx context.Context, template model.PolicyTemplate, creatorID string) (*model.PolicyTemplate, error)
+	GetPolicyTemplate(ctx context.Context, templateID string) (*model.PolicyTemplate, error)
+	InstantiatePolicy(ctx context.Context, templateID string, vars map[string]string, userID string) (*model.Policy, error)
+	SubmitForApproval(ctx context.Context, policyID string, actorID string, comment string) (*model.Policy, error)
+	ApprovePolicy(ctx context.Context, policyID string, actorID string, comment string) (*model.Policy, error)
+	RejectPolicy(ctx context.Context, policyID string, actorID string, comment string) (*model.Policy, error)
+	WatchPolicyChanges(ctx context.Context) <-chan model.PolicyChange
+	ImportPolicies(ctx context.Context, policies []model.Policy, userID string) (*model.Job, error)
 }
 
 // PolicyService handles business logic for policy operations
 type PolicyService struct {
 	policyDAO       *dao.PolicyDAO
+	userDAO         *dao.UserDAO
+	resourceDAO     *dao.ResourceDAO
 	validationUtil  *util.ValidationUtil
 	cacheService    *util.CacheService
 	notificationSvc *util.NotificationService
 	eventBus        *util.EventBus
+	jobRunner       *util.JobRunner
+	watchers        policyWatchers
 }
 
 var _ IPolicyService = &PolicyService{}
 
 // NewPolicyService creates a new instance of PolicyService
-func NewPolicyService(policyDAO *dao.PolicyDAO, validationUtil *util.ValidationUtil, cacheService *util.CacheService, notificationSvc *util.NotificationService, eventBus *util.EventBus) *PolicyService {
+func NewPolicyService(policyDAO *dao.PolicyDAO, userDAO *dao.UserDAO, resourceDAO *dao.ResourceDAO, validationUtil *util.ValidationUtil, cacheService *util.CacheService, notificationSvc *util.NotificationService, eventBus *util.EventBus, jobRunner *util.JobRunner) *PolicyService {
 	service := &PolicyService{
 		policyDAO:       policyDAO,
+		userDAO:         userDAO,
+		resourceDAO:     resourceDAO,
 		validationUtil:  validationUtil,
 		cacheService:    cacheService,
 		notificationSvc: notificationSvc,
 		eventBus:        eventBus,
+		jobRunner:       jobRunner,
 	}
 
 	// Set up event subscriptions
 	eventBus.Subscribe("policy.created", service.handlePolicyCreated)
 	eventBus.Subscribe("policy.updated", service.handlePolicyUpdated)
 	eventBus.Subscribe("policy.deleted", service.handlePolicyDeleted)
+	for _, eventType := range []string{"policy.submitted", "policy.approved", "policy.rejected"} {
+		eventBus.Subscribe(eventType, service.handlePolicyReviewed)
+	}
+	for _, eventType := range []string{"policy.created", "policy.updated", "policy.deleted"} {
+		eventBus.Subscribe(eventType, service.watchers.broadcast)
+	}
 
 	return service
 }
@@ -181,6 +211,35 @@ func (s *PolicyService) handlePolicyDeleted(ctx context.Context, event util.Even
 
 // CreatePolicy handles the creation of a new policy
 func (s *PolicyService) CreatePolicy(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	created, err := s.storePolicy(ctx, policy, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A dry run was rolled back, so neither the cache nor subscribers may hear of it
+	if helper_util.IsDryRun(ctx) {
+		return created, nil
+	}
+
+	// Update cache
+	if err := s.cacheService.SetPolicy(ctx, *created); err != nil {
+		logger.Warn("Failed to cache policy", zap.Error(err), zap.String("policyID", created.ID))
+	}
+
+	// Publish event for asynchronous processing
+	s.eventBus.Publish(ctx, "policy.created", *created)
+
+	logger.Info("Policy created successfully", zap.String("policyID", created.ID), zap.String("userID", userID))
+	return created, nil
+}
+
+// storePolicy validates a new policy and writes it to the database, leaving the cache and subscribers
+// to its caller
+func (s *PolicyService) storePolicy(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error) {
 	if err := s.validationUtil.ValidatePolicy(policy); err != nil {
 		return nil, fmt.Errorf("invalid policy: %w", err)
 	}
@@ -189,9 +248,16 @@ func (s *PolicyService) CreatePolicy(ctx context.Context, policy model.Policy, u
 		return nil, fmt.Errorf("policy conflict: %w", err)
 	}
 
+	if err := checkTenantOwnership(ctx, TenantEntityPolicy, policy.OrganizationID); err != nil {
+		return nil, err
+	}
+
 	policy.CreatedAt = time.Now()
 	policy.UpdatedAt = time.Now()
 	policy.Version = 1
+	policy.Status = initialPolicyStatus(policy.Status)
+	policy.SubmittedBy, policy.SubmittedAt = "", nil
+	policy.ReviewedBy, policy.ReviewedAt, policy.ReviewComment = "", nil, ""
 
 	policyID, err := s.policyDAO.CreatePolicy(ctx, policy, userID)
 	if err != nil {
@@ -200,21 +266,35 @@ func (s *PolicyService) CreatePolicy(ctx context.Context, policy model.Policy, u
 	}
 
 	policy.ID = policyID
+	return &policy, nil
+}
 
-	// Update cache
-	if err := s.cacheService.SetPolicy(ctx, policy); err != nil {
-		logger.Warn("Failed to cache policy", zap.Error(err), zap.String("policyID", policyID))
-	}
+// CreatePolicyAndFetch creates a policy and reads it back from the database. The read is made with the
+// create's bookmarks, so on a cluster it observes the new policy even when served by a replica that
+// has not caught up yet. A dry run returns the policy it would create, as nothing is stored to read.
+func (s *PolicyService) CreatePolicyAndFetch(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error) {
+	ctx = dao.WithReadYourWrites(ctx)
 
-	// Publish event for asynchronous processing
-	s.eventBus.Publish(ctx, "policy.created", policy)
+	created, err := s.CreatePolicy(ctx, policy, userID)
+	if err != nil || helper_util.IsDryRun(ctx) {
+		return created, err
+	}
 
-	logger.Info("Policy created successfully", zap.String("policyID", policyID), zap.String("userID", userID))
-	return &policy, nil
+	// Bypass the cache, which CreatePolicy has just filled from the request rather than the database
+	fetched, err := s.policyDAO.GetPolicy(ctx, created.ID)
+	if err != nil {
+		logger.Error("Error fetching created policy", zap.Error(err), zap.String("policyID", created.ID))
+		return nil, fmt.Errorf("failed to fetch created policy: %w", err)
+	}
+	return fetched, nil
 }
 
 // UpdatePolicy handles updates to an existing policy
 func (s *PolicyService) UpdatePolicy(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidatePolicy(policy); err != nil {
 		return nil, fmt.Errorf("invalid policy: %w", err)
 	}
@@ -229,6 +309,18 @@ func (s *PolicyService) UpdatePolicy(ctx context.Context, policy model.Policy, u
 		return nil, err
 	}
 
+	// The policy may neither be changed by nor moved into another organization, nor shared by all of
+	// them; a policy sent without one stays in its own
+	if err := checkTenantAccess(ctx, TenantEntityPolicy, oldPolicy.OrganizationID); err != nil {
+		return nil, err
+	}
+	if policy.OrganizationID == "" {
+		policy.OrganizationID = oldPolicy.OrganizationID
+	}
+	if err := checkTenantOwnership(ctx, TenantEntityPolicy, policy.OrganizationID); err != nil {
+		return nil, err
+	}
+
 	// Check if there are any differences between the old and new policies
 	if !s.hasPolicyChanged(oldPolicy, &policy) {
 		logger.Info("No changes detected in the policy, skipping update", zap.String("policyID", policy.ID))
@@ -237,6 +329,10 @@ func (s *PolicyService) UpdatePolicy(ctx context.Context, policy model.Policy, u
 
 	policy.UpdatedAt = time.Now()
 	policy.Version = oldPolicy.Version + 1
+	policy.Status = editedPolicyStatus(oldPolicy.Status)
+	if policy.Status == model.PolicyStatusDraft && oldPolicy.Status != model.PolicyStatusDraft {
+		logger.Info("Edited policy goes back to draft until approved again", zap.String("policyID", policy.ID))
+	}
 
 	updatedPolicy, err := s.policyDAO.UpdatePolicy(ctx, policy, userID)
 	if err != nil {
@@ -244,6 +340,11 @@ func (s *PolicyService) UpdatePolicy(ctx context.Context, policy model.Policy, u
 		return nil, fmt.Errorf("failed to update policy: %w", err)
 	}
 
+	// A dry run was rolled back, so neither the cache nor subscribers may hear of it
+	if helper_util.IsDryRun(ctx) {
+		return updatedPolicy, nil
+	}
+
 	// Update cache
 	if err := s.cacheService.SetPolicy(ctx, *updatedPolicy); err != nil {
 		logger.Warn("Failed to update policy in cache", zap.Error(err), zap.String("policyID", policy.ID))
@@ -261,6 +362,24 @@ func (s *PolicyService) UpdatePolicy(ctx context.Context, policy model.Policy, u
 
 // DeletePolicy handles the deletion of a policy
 func (s *PolicyService) DeletePolicy(ctx context.Context, policyID string, userID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
+	if tenantIsolationEnabled(TenantEntityPolicy) {
+		policy, err := s.policyDAO.GetPolicy(ctx, policyID)
+		if err != nil {
+			if errors.Is(err, echo_errors.ErrPolicyNotFound) {
+				return echo_errors.ErrPolicyNotFound
+			}
+			logger.Error("Error retrieving policy", zap.Error(err), zap.String("policyID", policyID))
+			return fmt.Errorf("failed to get policy: %w", err)
+		}
+		if err := checkTenantAccess(ctx, TenantEntityPolicy, policy.OrganizationID); err != nil {
+			return err
+		}
+	}
+
 	err := s.policyDAO.DeletePolicy(ctx, policyID, userID)
 	if err != nil {
 		logger.Error("Error deleting policy", zap.Error(err), zap.String("policyID", policyID), zap.String("userID", userID))
@@ -279,11 +398,34 @@ func (s *PolicyService) DeletePolicy(ctx context.Context, policyID string, userI
 	return nil
 }
 
+// GetPolicyWithSubjects retrieves a policy along with the users it names as subjects, resolved to
+// their organization, department, roles and groups. GetPolicy stays the cheaper read when those are
+// not needed.
+func (s *PolicyService) GetPolicyWithSubjects(ctx context.Context, policyID string) (*model.PolicyWithSubjects, error) {
+	policy, err := s.GetPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.policyDAO.ResolveUserSubjects(ctx, policy.Subjects)
+	if err != nil {
+		logger.Error("Error resolving policy subjects", zap.Error(err), zap.String("policyID", policyID))
+		return nil, echo_errors.ErrInternalServer
+	}
+
+	return &model.PolicyWithSubjects{Policy: policy, ResolvedSubjects: subjects}, nil
+}
+
 // GetPolicy retrieves a policy by its ID
 func (s *PolicyService) GetPolicy(ctx context.Context, policyID string) (*model.Policy, error) {
 	// Try to get from cache first
 	cachedPolicy, err := s.cacheService.GetPolicy(ctx, policyID)
-	if err == nil && cachedPolicy != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindPolicy, policyID, err)
+	} else if cachedPolicy != nil {
+		if err := checkTenantAccess(ctx, TenantEntityPolicy, cachedPolicy.OrganizationID); err != nil {
+			return nil, err
+		}
 		return cachedPolicy, nil
 	}
 
@@ -296,6 +438,10 @@ func (s *PolicyService) GetPolicy(ctx context.Context, policyID string) (*model.
 		return nil, echo_errors.ErrInternalServer
 	}
 
+	if err := checkTenantAccess(ctx, TenantEntityPolicy, policy.OrganizationID); err != nil {
+		return nil, err
+	}
+
 	// Update cache
 	if err := s.cacheService.SetPolicy(ctx, *policy); err != nil {
 		logger.Warn("Failed to cache policy", zap.Error(err), zap.String("policyID", policyID))
@@ -304,8 +450,43 @@ func (s *PolicyService) GetPolicy(ctx context.Context, policyID string) (*model.
 	return policy, nil
 }
 
+// GetPoliciesByIDs retrieves up to MaxBatchGetIDs policies at once, from the cache where possible,
+// listing the IDs with no policy the caller may see as missing
+func (s *PolicyService) GetPoliciesByIDs(ctx context.Context, policyIDs []string) (*model.PolicyBatch, error) {
+	policies, missing, err := batchGet(ctx, batchSource[model.Policy]{
+		kind:   "policy",
+		idOf:   func(policy *model.Policy) string { return policy.ID },
+		cached: s.cacheService.GetPolicies,
+		load:   s.policyDAO.GetPoliciesByIDs,
+		store:  s.cacheService.SetMany,
+		visible: func(policy *model.Policy) bool {
+			return checkTenantAccess(ctx, TenantEntityPolicy, policy.OrganizationID) == nil
+		},
+	}, policyIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PolicyBatch{Items: policies, Missing: missing}, nil
+}
+
+// PolicyExists reports whether a policy exists without fetching it
+func (s *PolicyService) PolicyExists(ctx context.Context, policyID string) (bool, error) {
+	exists, err := s.policyDAO.Exists(ctx, policyID)
+	if err != nil {
+		logger.Error("Error checking policy existence", zap.Error(err), zap.String("policyID", policyID))
+		return false, fmt.Errorf("failed to check policy existence: %w", err)
+	}
+
+	return exists, nil
+}
+
 // ListPolicies retrieves all policies, possibly with pagination
 func (s *PolicyService) ListPolicies(ctx context.Context, limit int, offset int) ([]*model.Policy, error) {
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	policies, err := s.policyDAO.ListPolicies(ctx, limit, offset)
 	if err != nil {
 		logger.Error("Error listing policies", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
@@ -315,10 +496,16 @@ func (s *PolicyService) ListPolicies(ctx context.Context, limit int, offset int)
 	return policies, nil
 }
 
-// BulkCreatePolicies creates multiple policies in parallel
+// BulkCreatePolicies creates multiple policies in parallel, then caches the created ones in one round
+// trip
 func (s *PolicyService) BulkCreatePolicies(ctx context.Context, policies []model.Policy, userID string) ([]string, error) {
-	g, ctx := errgroup.WithContext(ctx)
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	g, groupCtx := errgroup.WithContext(ctx)
 	policyIDs := make([]string, len(policies))
+	created := make([]*model.Policy, len(policies))
 
 	// Limit concurrency to avoid overwhelming the system
 	semaphore := make(chan struct{}, 10) // Adjust this number based on your system's capacity
@@ -329,16 +516,22 @@ func (s *PolicyService) BulkCreatePolicies(ctx context.Context, policies []model
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			createdPolicy, err := s.CreatePolicy(ctx, policy, userID)
+			createdPolicy, err := s.storePolicy(groupCtx, policy, userID)
 			if err != nil {
 				return err
 			}
 			policyIDs[i] = createdPolicy.ID
+			created[i] = createdPolicy
 			return nil
 		})
 	}
 
-	if err := g.Wait(); err != nil {
+	err := g.Wait()
+	// Policies created before a failure stay created, so they are cached and announced all the same
+	if !helper_util.IsDryRun(ctx) {
+		s.announceCreatedPolicies(ctx, created)
+	}
+	if err != nil {
 		logger.Error("Error in bulk create policies", zap.Error(err), zap.String("userID", userID))
 		return nil, fmt.Errorf("failed to bulk create policies: %w", err)
 	}
@@ -347,8 +540,91 @@ func (s *PolicyService) BulkCreatePolicies(ctx context.Context, policies []model
 	return policyIDs, nil
 }
 
+// ImportPolicies creates policies one after the other in a background job and returns the job, which
+// reports each policy as it is created. A policy that cannot be created does not stop the import, which
+// then ends partial, listing why each failed.
+func (s *PolicyService) ImportPolicies(ctx context.Context, policies []model.Policy, userID string) (*model.Job, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.jobRunner.Submit(ctx, JobTypePolicyImport, func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+		progress.SetTotal(len(policies))
+		result := model.PolicyImportResult{PolicyIDs: []string{}}
+		created := make([]*model.Policy, 0, len(policies))
+		for i, policy := range policies {
+			createdPolicy, err := s.storePolicy(ctx, policy, userID)
+			if err != nil {
+				progress.Failed(fmt.Errorf("policy %d (%s): %w", i, policy.Name, err))
+				continue
+			}
+			created = append(created, createdPolicy)
+			result.PolicyIDs = append(result.PolicyIDs, createdPolicy.ID)
+			progress.Succeeded(1)
+		}
+
+		if !helper_util.IsDryRun(ctx) {
+			s.announceCreatedPolicies(ctx, created)
+		}
+		logger.Info("Policy import completed", zap.Int("created", len(created)), zap.Int("total", len(policies)), zap.String("userID", userID))
+		return result, nil
+	})
+}
+
+// announceCreatedPolicies caches the policies a bulk create stored and publishes their creation.
+// Entries of policies that were not created are nil.
+func (s *PolicyService) announceCreatedPolicies(ctx context.Context, created []*model.Policy) {
+	policies := make([]interface{}, 0, len(created))
+	for _, policy := range created {
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+
+	if err := s.cacheService.SetMany(ctx, policies...); err != nil {
+		logger.Warn("Failed to cache bulk created policies", zap.Error(err), zap.Int("count", len(policies)))
+	}
+	for _, policy := range created {
+		if policy != nil {
+			s.eventBus.Publish(ctx, "policy.created", *policy)
+		}
+	}
+}
+
+// ListPoliciesByCursor retrieves the page of policies following cursor, or the first page when
+// cursor is empty. Prefer it over offset pagination for large listings.
+func (s *PolicyService) ListPoliciesByCursor(ctx context.Context, cursor string, limit int) (*model.PolicyPage, error) {
+	limit, _, err := helper_util.ClampPagination(limit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := helper_util.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, next, err := s.policyDAO.ListPoliciesAfter(ctx, after, limit)
+	if err != nil {
+		logger.Error("Error listing policies by cursor", zap.Error(err), zap.Int("limit", limit))
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	page := &model.PolicyPage{Items: policies}
+	if next != nil {
+		page.NextCursor = helper_util.EncodeCursor(*next)
+	}
+	return page, nil
+}
+
 // SearchPolicies searches for policies based on given criteria
 func (s *PolicyService) SearchPolicies(ctx context.Context, criteria model.PolicySearchCriteria) ([]*model.Policy, error) {
+	limit, offset, err := helper_util.ClampPagination(criteria.Limit, criteria.Offset)
+	if err != nil {
+		return nil, err
+	}
+	criteria.Limit, criteria.Offset = limit, offset
+
 	policies, err := s.policyDAO.SearchPolicies(ctx, criteria)
 	if err != nil {
 		logger.Error("Error searching policies", zap.Error(err), zap.Any("criteria", criteria))
@@ -369,12 +645,85 @@ func (s *PolicyService) AnalyzePolicyUsage(ctx context.Context, policyID string)
 	return analysis, nil
 }
 
+// CreatePolicyTemplate stores a policy template, recording the placeholder variables its policy uses
+func (s *PolicyService) CreatePolicyTemplate(ctx context.Context, template model.PolicyTemplate, creatorID string) (*model.PolicyTemplate, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if template.Name == "" {
+		return nil, fmt.Errorf("%w: template name cannot be empty", echo_errors.ErrInvalidPolicyData)
+	}
+
+	variables, err := util.TemplateVariables(template.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", echo_errors.ErrInvalidPolicyData, err)
+	}
+
+	template.Variables = variables
+	template.CreatedBy = creatorID
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = time.Now()
+
+	templateID, err := s.policyDAO.CreatePolicyTemplate(ctx, template)
+	if err != nil {
+		logger.Error("Error creating policy template", zap.Error(err), zap.String("creatorID", creatorID))
+		return nil, err
+	}
+	template.ID = templateID
+
+	logger.Info("Policy template created successfully", zap.String("templateID", templateID), zap.Strings("variables", variables))
+	return &template, nil
+}
+
+// GetPolicyTemplate retrieves a policy template by its ID
+func (s *PolicyService) GetPolicyTemplate(ctx context.Context, templateID string) (*model.PolicyTemplate, error) {
+	template, err := s.policyDAO.GetPolicyTemplate(ctx, templateID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrPolicyTemplateNotFound) {
+			return nil, echo_errors.ErrPolicyTemplateNotFound
+		}
+		logger.Error("Error retrieving policy template", zap.Error(err), zap.String("templateID", templateID))
+		return nil, fmt.Errorf("failed to get policy template: %w", err)
+	}
+
+	return template, nil
+}
+
+// InstantiatePolicy creates a concrete policy from a template by substituting vars for its placeholders.
+// Every placeholder must be supplied, and the resulting policy is validated like any other and linked
+// back to its template.
+func (s *PolicyService) InstantiatePolicy(ctx context.Context, templateID string, vars map[string]string, userID string) (*model.Policy, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	template, err := s.GetPolicyTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := util.InstantiateTemplate(*template, vars)
+	if err != nil {
+		logger.Warn("Failed to instantiate policy template", zap.Error(err), zap.String("templateID", templateID))
+		return nil, err
+	}
+
+	createdPolicy, err := s.CreatePolicy(ctx, policy, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Policy instantiated from template",
+		zap.String("policyID", createdPolicy.ID),
+		zap.String("templateID", templateID),
+		zap.String("userID", userID))
+	return createdPolicy, nil
+}
+
 // checkPolicyConflicts checks if the given policy conflicts with existing policies
 func (s *PolicyService) checkPolicyConflicts(ctx context.Context, policy model.Policy) error {
-	// Implement logic to check for conflicts with existing policies
-	// This could involve checking for overlapping subjects, resources, or actions
-	// Return an error if a conflict is found
-	return nil
+	return s.checkPriorityConflict(ctx, policy)
 }
 
 // hasPolicyChanged checks if there are any differences between the old and new policies
@@ -387,7 +736,8 @@ func (s *PolicyService) hasPolicyChanged(oldPolicy, newPolicy *model.Policy) boo
 		!reflect.DeepEqual(oldPolicy.Subjects, newPolicy.Subjects) ||
 		!reflect.DeepEqual(oldPolicy.ResourceTypes, newPolicy.ResourceTypes) ||
 		!reflect.DeepEqual(oldPolicy.Actions, newPolicy.Actions) ||
-		!reflect.DeepEqual(oldPolicy.Conditions, newPolicy.Conditions) {
+		!reflect.DeepEqual(oldPolicy.Conditions, newPolicy.Conditions) ||
+		!reflect.DeepEqual(oldPolicy.Obligations, newPolicy.Obligations) {
 		return true
 	}
 	return false
@@ -0,0 +1,376 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+func TestPolicyServiceInstantiatePolicy(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newService := func() (*service.PolicyService, *mock.MockSession) {
+		session := &mock.MockSession{}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		policyService := service.NewPolicyService(
+			&dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+			nil,
+			nil,
+			util.NewValidationUtil(),
+			nil,
+			nil,
+			util.NewEventBus(),
+			nil,
+		)
+		return policyService, session
+	}
+
+	templateNode := neo4j.Node{Props: map[string]any{
+		"id":          "tpl1",
+		"name":        "Resource readers",
+		"description": "Grants read on one resource",
+		"policy":      `{"name":"Read ${resourceId}","effect":"allow","subjects":[{"type":"role","attributes":{"role":"${role}"}}],"resource_types":["DOCUMENT"],"actions":["read"]}`,
+		"variables":   []any{"resourceId", "role"},
+		"createdBy":   "admin",
+		"createdAt":   "2024-01-01T00:00:00Z",
+		"updatedAt":   "2024-01-01T00:00:00Z",
+	}}
+
+	t.Run("MissingVariableIsRejectedBeforeCreating", func(t *testing.T) {
+		policyService, session := newService()
+		result := &mock.MockResult{}
+		result.On("Next").Return(true).Once()
+		result.On("Record").Return(&neo4j.Record{Values: []any{templateNode}})
+		session.On("Run", testify_mock.Anything, map[string]interface{}{"id": "tpl1"}, testify_mock.Anything).Return(result, nil)
+
+		policy, err := policyService.InstantiatePolicy(ctx, "tpl1", map[string]string{"resourceId": "r1"}, "admin")
+
+		assert.Nil(t, policy)
+		assert.True(t, errors.Is(err, echo_errors.ErrMissingTemplateVariables))
+		assert.Contains(t, err.Error(), "role")
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("UnknownTemplate", func(t *testing.T) {
+		policyService, session := newService()
+		result := &mock.MockResult{}
+		result.On("Next").Return(false)
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+
+		policy, err := policyService.InstantiatePolicy(ctx, "missing", map[string]string{}, "admin")
+
+		assert.Nil(t, policy)
+		assert.Equal(t, echo_errors.ErrPolicyTemplateNotFound, err)
+	})
+}
+
+func TestPolicyServiceErrorMapping(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	newService := func() (*service.PolicyService, *mock.MockSession) {
+		session := &mock.MockSession{}
+		session.On("WriteTransaction", testify_mock.Anything, testify_mock.Anything).Return(nil, echo_errors.ErrDatabaseOperation)
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		policyService := service.NewPolicyService(
+			&dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+			nil,
+			nil,
+			util.NewValidationUtil(),
+			nil,
+			nil,
+			util.NewEventBus(),
+			nil,
+		)
+		return policyService, session
+	}
+
+	t.Run("ValidationFailureIsABadRequest", func(t *testing.T) {
+		policyService, session := newService()
+
+		_, err := policyService.CreatePolicy(ctx, model.Policy{Effect: "permit", ResourceTypes: []string{"DOCUMENT"}, Actions: []string{"read"}}, "admin")
+
+		var validationErr *echo_errors.ErrValidation
+		if assert.True(t, errors.As(err, &validationErr)) {
+			assert.Equal(t, []echo_errors.FieldError{
+				{Field: "name", Reason: "cannot be empty"},
+				{Field: "effect", Reason: `"permit" must be one of allow, deny`},
+				{Field: "subjects", Reason: "must have at least one subject"},
+			}, validationErr.Fields)
+		}
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidPolicyData))
+		status, code := util.MapError(err)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, util.CodeValidationFailed, code)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("DatabaseFailureIsAServerError", func(t *testing.T) {
+		policyService, _ := newService()
+		policy := model.Policy{
+			Name:          "Docs",
+			Effect:        "allow",
+			Subjects:      []model.Subject{{Type: "role"}},
+			ResourceTypes: []string{"DOCUMENT"},
+			Actions:       []string{"read"},
+		}
+
+		_, err := policyService.CreatePolicy(ctx, policy, "admin")
+
+		var validationErr *echo_errors.ErrValidation
+		assert.False(t, errors.As(err, &validationErr))
+		status, code := util.MapError(err)
+		assert.Equal(t, http.StatusInternalServerError, status)
+		assert.Equal(t, "DATABASE_ERROR", code)
+	})
+}
+
+func TestPolicyServiceAnalyzePolicySet(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	policyNode := func(id, effect string, priority int64, roleID, actions, conditions string) any {
+		return neo4j.Node{Props: map[string]any{
+			"id":                id,
+			"name":              id,
+			"description":       "",
+			"effect":            effect,
+			"priority":          priority,
+			"version":           int64(1),
+			"createdAt":         "2024-01-01T00:00:00Z",
+			"updatedAt":         "2024-01-01T00:00:00Z",
+			"active":            true,
+			"subjects":          `[{"type":"role","attributes":{"id":"` + roleID + `"}}]`,
+			"resourceTypes":     `["DOCUMENT"]`,
+			"attributeGroups":   "[]",
+			"actions":           actions,
+			"conditions":        conditions,
+			"dynamicAttributes": "[]",
+		}}
+	}
+
+	// Editors may do anything to documents ahead of their deny, viewers are granted
+	// reading twice, and the auditors' policy checks an attribute nothing sets
+	result := &mock.MockResult{}
+	for _, node := range []any{
+		policyNode("allow-editors", echo_neo4j.PolicyEffectAllow, 20, "editor", `["*"]`, "[]"),
+		policyNode("deny-editors", echo_neo4j.PolicyEffectDeny, 10, "editor", `["delete"]`, "[]"),
+		policyNode("viewers", echo_neo4j.PolicyEffectAllow, 5, "viewer", `["read","list"]`, "[]"),
+		policyNode("viewers-copy", echo_neo4j.PolicyEffectAllow, 5, "viewer", `["list","read"]`, "[]"),
+		policyNode("auditors", echo_neo4j.PolicyEffectAllow, 5, "auditor", `["read"]`, `[{"attribute":"clearance","operator":"equals","value":"high"}]`),
+	} {
+		result.On("Next").Return(true).Once()
+		result.On("Record").Return(&neo4j.Record{Values: []any{node}}).Once()
+	}
+	result.On("Next").Return(false)
+	session := &mock.MockSession{}
+	session.On("Close").Return(nil)
+	session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	policyService := service.NewPolicyService(
+		&dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+		nil,
+		nil,
+		util.NewValidationUtil(),
+		nil,
+		nil,
+		util.NewEventBus(),
+		nil,
+	)
+
+	analysis, err := policyService.AnalyzePolicySet(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, analysis.PolicyCount)
+	findings := make(map[string][]string)
+	for _, finding := range analysis.Findings {
+		assert.NotEmpty(t, finding.Message)
+		findings[finding.Type] = finding.PolicyIDs
+	}
+	assert.Equal(t, map[string][]string{
+		model.PolicyFindingUnreachable: {"deny-editors", "allow-editors"},
+		model.PolicyFindingDuplicate:   {"viewers", "viewers-copy"},
+		model.PolicyFindingAlwaysFalse: {"auditors"},
+	}, findings)
+	assert.Len(t, analysis.Findings, 3)
+}
+
+func TestPolicyServiceGetPoliciesByIDs(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer service.SetTenantIsolation(nil, service.DefaultGlobalAdminRole)
+
+	// An unreachable cache makes every ID a miss, which is how a cold cache behaves
+	previousClient := db.RedisClient
+	db.RedisClient = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer func() { db.RedisClient = previousClient }()
+
+	policy := func(id, orgID string) neo4j.Node {
+		return neo4j.Node{Props: map[string]any{
+			"id":                id,
+			"name":              "Docs",
+			"description":       "Docs policy",
+			"effect":            echo_neo4j.PolicyEffectAllow,
+			"priority":          int64(1),
+			"version":           int64(1),
+			"createdAt":         "2024-01-01T00:00:00Z",
+			"updatedAt":         "2024-01-01T00:00:00Z",
+			"active":            true,
+			"subjects":          "[]",
+			"resourceTypes":     "[]",
+			"attributeGroups":   "[]",
+			"actions":           "[]",
+			"conditions":        "[]",
+			"dynamicAttributes": "[]",
+			"organizationID":    orgID,
+		}}
+	}
+	newService := func(nodes ...neo4j.Node) (*service.PolicyService, *mock.MockSession) {
+		result := &mock.MockResult{}
+		for _, node := range nodes {
+			result.On("Next").Return(true).Once()
+			result.On("Record").Return(&neo4j.Record{Values: []any{node}}).Once()
+		}
+		result.On("Next").Return(false)
+		session := &mock.MockSession{}
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		policyService := service.NewPolicyService(
+			&dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+			nil,
+			nil,
+			util.NewValidationUtil(),
+			util.NewCacheService(nil),
+			nil,
+			util.NewEventBus(),
+			nil,
+		)
+		return policyService, session
+	}
+
+	t.Run("ExistingAndMissingIDs", func(t *testing.T) {
+		policyService, session := newService(policy("p3", "org1"), policy("p1", "org1"))
+
+		batch, err := policyService.GetPoliciesByIDs(context.Background(), []string{"p1", "p2", "p3", "p1", ""})
+
+		assert.NoError(t, err)
+		if assert.Len(t, batch.Items, 2) {
+			assert.Equal(t, "p1", batch.Items[0].ID)
+			assert.Equal(t, "p3", batch.Items[1].ID)
+		}
+		assert.Equal(t, []string{"p2"}, batch.Missing)
+		session.AssertCalled(t, "Run", testify_mock.Anything, map[string]interface{}{"ids": []string{"p1", "p2", "p3"}}, testify_mock.Anything)
+	})
+
+	t.Run("OtherOrganizationsPoliciesAreMissing", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, _ := newService(policy("p1", "org1"), policy("p2", "org2"))
+
+		batch, err := policyService.GetPoliciesByIDs(requestContext("org1"), []string{"p1", "p2"})
+
+		assert.NoError(t, err)
+		if assert.Len(t, batch.Items, 1) {
+			assert.Equal(t, "p1", batch.Items[0].ID)
+		}
+		assert.Equal(t, []string{"p2"}, batch.Missing)
+	})
+
+	t.Run("TooManyIDs", func(t *testing.T) {
+		policyService, session := newService()
+		ids := make([]string, service.MaxBatchGetIDs+1)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("p%d", i)
+		}
+
+		batch, err := policyService.GetPoliciesByIDs(context.Background(), ids)
+
+		assert.Nil(t, batch)
+		assert.ErrorIs(t, err, echo_errors.ErrInvalidSearchCriteria)
+		session.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+	})
+}
+
+func TestPolicyServiceImportPolicies(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	mock.StartRedisServer(t)
+	ctx := helper_util.WithActor(context.Background(), "admin")
+	driver := mock.NewFakeDriver().Returns("RETURN p.id as id", &neo4j.Record{Keys: []string{"id"}, Values: []any{"p-imported"}})
+	auditService := &mock.MockAuditService{}
+	auditService.On("LogAccess", testify_mock.Anything, testify_mock.Anything).Return(nil)
+	jobRunner := util.NewJobRunner(util.RedisJobStore{TTL: time.Hour}, 1, 10)
+	defer jobRunner.Shutdown(context.Background())
+	policyService := service.NewPolicyService(
+		&dao.PolicyDAO{Driver: driver, AuditService: auditService},
+		nil,
+		nil,
+		util.NewValidationUtil(),
+		util.NewCacheService(nil),
+		util.NewNotificationService(0, nil),
+		util.NewEventBus(),
+		jobRunner,
+	)
+	valid := model.Policy{
+		Name:          "Docs readers",
+		Effect:        "allow",
+		Subjects:      []model.Subject{{Type: "role", Attributes: map[string]string{"id": "reader"}}},
+		ResourceTypes: []string{"DOCUMENT"},
+		Actions:       []string{"read"},
+	}
+	invalid := valid
+	invalid.Name, invalid.Effect = "Docs permitters", "permit"
+
+	job, err := policyService.ImportPolicies(ctx, []model.Policy{valid, invalid}, "admin")
+
+	require.NoError(t, err)
+	assert.Equal(t, service.JobTypePolicyImport, job.Type)
+	assert.Equal(t, model.JobStatusQueued, job.Status)
+	require.Eventually(t, func() bool {
+		job, err = jobRunner.Get(ctx, job.ID)
+		return err == nil && job.Finished()
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, model.JobStatusPartial, job.Status)
+	assert.Equal(t, 2, job.Total)
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, 1, job.Failed)
+	if assert.Len(t, job.Errors, 1) {
+		assert.Contains(t, job.Errors[0], "policy 1 (Docs permitters)")
+	}
+	var result model.PolicyImportResult
+	require.NoError(t, json.Unmarshal(job.Result, &result))
+	assert.Equal(t, []string{"p-imported"}, result.PolicyIDs)
+}
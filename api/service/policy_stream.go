@@ -0,0 +1,73 @@
+// api/service/policy_stream.go
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// policyWatchBuffer is how many changes a watcher may fall behind by before further ones are dropped
+const policyWatchBuffer = 32
+
+// policyWatchers fans the policy events of the event bus out to the clients watching policies. A single
+// subscription serves every watcher, since the event bus cannot unsubscribe one closure among several.
+type policyWatchers struct {
+	mu       sync.Mutex
+	channels map[chan model.PolicyChange]struct{}
+}
+
+// WatchPolicyChanges returns a channel receiving every policy created, updated or deleted from now on.
+// The channel is closed once ctx is done. A watcher too slow to keep up misses changes rather than
+// holding up the others.
+func (s *PolicyService) WatchPolicyChanges(ctx context.Context) <-chan model.PolicyChange {
+	return s.watchers.watch(ctx)
+}
+
+func (w *policyWatchers) watch(ctx context.Context) <-chan model.PolicyChange {
+	changes := make(chan model.PolicyChange, policyWatchBuffer)
+	w.mu.Lock()
+	if w.channels == nil {
+		w.channels = make(map[chan model.PolicyChange]struct{})
+	}
+	w.channels[changes] = struct{}{}
+	w.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		w.mu.Lock()
+		delete(w.channels, changes)
+		close(changes)
+		w.mu.Unlock()
+	}()
+	return changes
+}
+
+func (w *policyWatchers) broadcast(ctx context.Context, event util.Event) error {
+	_, action, _ := strings.Cut(event.Type, ".")
+	change := model.PolicyChange{
+		PolicyID:  changedEntityID(event.Payload),
+		Action:    action,
+		Timestamp: event.OccurredAt,
+		Actor:     event.Actor,
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for changes := range w.channels {
+		select {
+		case changes <- change:
+		default:
+			logger.Warn("Policy watcher falling behind, change dropped",
+				zap.String("policyID", change.PolicyID),
+				zap.String("action", change.Action))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestReadOnlyMode(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+	mock.StartRedisServer(t)
+	require.NoError(t, util.SetReadOnly(ctx, true))
+	defer util.SetReadOnly(ctx, false)
+
+	driver := recordDriver(&neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+		"id":                "p1",
+		"name":              "Docs",
+		"description":       "Docs policy",
+		"effect":            echo_neo4j.PolicyEffectAllow,
+		"priority":          int64(1),
+		"version":           int64(1),
+		"createdAt":         "2024-01-01T00:00:00Z",
+		"updatedAt":         "2024-01-01T00:00:00Z",
+		"active":            true,
+		"subjects":          "[]",
+		"resourceTypes":     "[]",
+		"attributeGroups":   "[]",
+		"actions":           "[]",
+		"conditions":        "[]",
+		"dynamicAttributes": "[]",
+	}}}})
+	policyService := service.NewPolicyService(
+		&dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+		nil,
+		nil,
+		util.NewValidationUtil(),
+		util.NewCacheService(nil),
+		nil,
+		util.NewEventBus(),
+		nil,
+	)
+
+	t.Run("Writes are rejected", func(t *testing.T) {
+		policy := model.Policy{
+			Name:          "Docs",
+			Effect:        "allow",
+			Subjects:      []model.Subject{{Type: "role"}},
+			ResourceTypes: []string{"DOCUMENT"},
+			Actions:       []string{"read"},
+		}
+
+		_, err := policyService.CreatePolicy(ctx, policy, "admin")
+		assert.True(t, errors.Is(err, echo_errors.ErrServiceReadOnly))
+
+		err = policyService.DeletePolicy(ctx, "p1", "admin")
+		assert.True(t, errors.Is(err, echo_errors.ErrServiceReadOnly))
+
+		status, code := util.MapError(err)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, "SERVICE_READ_ONLY", code)
+	})
+
+	t.Run("Reads are served", func(t *testing.T) {
+		policy, err := policyService.GetPolicy(ctx, "p1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Docs", policy.Name)
+	})
+}
@@ -0,0 +1,34 @@
+// api/service/resource_inheritance.go
+package service
+
+import (
+	"github.com/dev-mohitbeniwal/echo/api/model"
+)
+
+// inheritanceMaxDepth caps how many levels of ancestors a resource may inherit access from. Zero turns
+// inheritance off.
+var inheritanceMaxDepth = 5
+
+// SetInheritanceMaxDepth sets how many levels of ancestors a resource inheriting its ACL may inherit
+// access from; zero turns inheritance off and negative depths are read as zero
+func SetInheritanceMaxDepth(depth int) {
+	inheritanceMaxDepth = max(depth, 0)
+}
+
+// inheritingAncestors walks the ancestors the resource inherits access from among resources, nearest
+// first, the way ResourceDAO.GetInheritingAncestors does in the database. The walk stops at the first
+// resource not inheriting its ACL, at an ancestor missing from resources, and at the depth cap.
+func inheritingAncestors(resource *model.Resource, resources map[string]*model.Resource) []*model.Resource {
+	var ancestors []*model.Resource
+	visited := map[string]bool{resource.ID: true}
+	for child := resource; len(ancestors) < inheritanceMaxDepth && child.InheritedACL && child.ParentID != ""; {
+		parent, ok := resources[child.ParentID]
+		if !ok || visited[parent.ID] {
+			break
+		}
+		visited[parent.ID] = true
+		ancestors = append(ancestors, parent)
+		child = parent
+	}
+	return ancestors
+}
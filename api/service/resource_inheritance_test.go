@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+)
+
+func TestResourceInheritance(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	// The plan sits in the alpha project folder, itself in the projects folder
+	projects := &model.Resource{ID: "projects", Type: "FOLDER"}
+	alpha := &model.Resource{ID: "alpha", Type: "FOLDER", ParentID: "projects", InheritedACL: true}
+	plan := &model.Resource{ID: "plan", Type: "DOCUMENT", ParentID: "alpha", InheritedACL: true}
+	resources := map[string]*model.Resource{"projects": projects, "alpha": alpha, "plan": plan}
+
+	subject := &model.User{ID: "u1", Status: model.UserStatusActive}
+	request := model.AccessRequest{SubjectID: "u1", ResourceID: "plan", Action: "read"}
+	policy := func(id, effect string, priority int, resourceType string) *model.Policy {
+		return &model.Policy{
+			ID:            id,
+			Name:          id,
+			Effect:        effect,
+			Priority:      priority,
+			Active:        true,
+			Subjects:      []model.Subject{{Type: "user", UserID: "u1"}},
+			ResourceTypes: []string{resourceType},
+			Actions:       []string{"read"},
+		}
+	}
+	folderReads := policy("folder-reads", echo_neo4j.PolicyEffectAllow, 10, "FOLDER")
+
+	t.Run("A child inherits its parent's allow", func(t *testing.T) {
+		request := request
+		request.Explain = true
+
+		decision := service.EvaluateInheritedPolicies([]*model.Policy{folderReads}, subject, plan, service.InheritingAncestors(plan, resources), request, time.Now())
+
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, "folder-reads", decision.PolicyID)
+		assert.Equal(t, "alpha", decision.InheritedFrom)
+		assert.Contains(t, decision.Reason, "inherited from resource alpha")
+		if assert.NotNil(t, decision.Trace) && assert.Len(t, decision.Trace.Policies, 2) {
+			assert.False(t, decision.Trace.Policies[0].Matched)
+			assert.Empty(t, decision.Trace.Policies[0].InheritedFrom)
+			assert.True(t, decision.Trace.Policies[1].Deciding)
+			assert.Equal(t, "alpha", decision.Trace.Policies[1].InheritedFrom)
+		}
+	})
+
+	t.Run("A child overrides its parent with a deny", func(t *testing.T) {
+		planDeny := policy("document-deny", echo_neo4j.PolicyEffectDeny, 1, "DOCUMENT")
+
+		decision := service.EvaluateInheritedPolicies([]*model.Policy{folderReads, planDeny}, subject, plan, service.InheritingAncestors(plan, resources), request, time.Now())
+
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, "document-deny", decision.PolicyID)
+		assert.Empty(t, decision.InheritedFrom)
+	})
+
+	t.Run("Nothing is inherited without the flag", func(t *testing.T) {
+		standalone := *plan
+		standalone.InheritedACL = false
+
+		ancestors := service.InheritingAncestors(&standalone, resources)
+		decision := service.EvaluateInheritedPolicies([]*model.Policy{folderReads}, subject, &standalone, ancestors, request, time.Now())
+
+		assert.Empty(t, ancestors)
+		assert.False(t, decision.Allowed)
+	})
+
+	t.Run("Ancestors stop at a parent not inheriting and at the depth cap", func(t *testing.T) {
+		ids := func(ancestors []*model.Resource) []string {
+			var ids []string
+			for _, ancestor := range ancestors {
+				ids = append(ids, ancestor.ID)
+			}
+			return ids
+		}
+		assert.Equal(t, []string{"alpha", "projects"}, ids(service.InheritingAncestors(plan, resources)))
+
+		service.SetInheritanceMaxDepth(1)
+		defer service.SetInheritanceMaxDepth(5)
+		assert.Equal(t, []string{"alpha"}, ids(service.InheritingAncestors(plan, resources)))
+
+		service.SetInheritanceMaxDepth(5)
+		alphaAlone := *alpha
+		alphaAlone.InheritedACL = false
+		assert.Equal(t, []string{"alpha"}, ids(service.InheritingAncestors(plan, map[string]*model.Resource{"alpha": &alphaAlone, "projects": projects})))
+	})
+}
@@ -5,15 +5,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IResourceService defines the interface for resource operations
@@ -22,29 +27,75 @@ type IResourceService interface {
 	UpdateResource(ctx context.Context, resource model.Resource, updaterID string) (*model.Resource, error)
 	DeleteResource(ctx context.Context, resourceID string, deleterID string) error
 	GetResource(ctx context.Context, resourceID string) (*model.Resource, error)
+	GetResourcesByIDs(ctx context.Context, resourceIDs []string) (*model.ResourceBatch, error)
+	ResourceExists(ctx context.Context, resourceID string) (bool, error)
 	ListResources(ctx context.Context, limit int, offset int) ([]*model.Resource, error)
+	ListResourcesByCursor(ctx context.Context, cursor string, limit int) (*model.ResourcePage, error)
+	ExportResources(ctx context.Context, criteria model.ResourceSearchCriteria, visit func(*model.Resource) error) error
 	SearchResources(ctx context.Context, criteria model.ResourceSearchCriteria) ([]*model.Resource, error)
+	GetResourcesByOrganization(ctx context.Context, orgID string, includeSubOrgs bool, limit int, offset int) ([]*model.Resource, int64, error)
+	GetResourcesByMinClassification(ctx context.Context, level string) ([]*model.Resource, error)
+	AddResourceTags(ctx context.Context, resourceID string, tags []string, updaterID string) ([]string, error)
+	RemoveResourceTags(ctx context.Context, resourceID string, tags []string, updaterID string) ([]string, error)
+	ListAllTags(ctx context.Context) ([]model.TagCount, error)
+	BulkTagResources(ctx context.Context, criteria model.ResourceSearchCriteria, tags []string, userID string) (int, error)
+	TransferResourceOwnership(ctx context.Context, resourceID string, newOwnerID string, userID string) error
+	CheckIntegrity(ctx context.Context, repair bool) (*model.IntegrityReport, error)
+	GetResourceDiff(ctx context.Context, resourceID string, fromVersion, toVersion int) (*model.ResourceDiff, error)
+}
+
+// DefaultBulkTagLimit is the largest number of resources a bulk tagging may match unless configured otherwise
+const DefaultBulkTagLimit = 1000
+
+var bulkTagLimit = DefaultBulkTagLimit
+
+// SetBulkTagLimit sets the largest number of resources a bulk tagging may match. Non-positive values
+// keep the current limit.
+func SetBulkTagLimit(limit int) {
+	if limit > 0 {
+		bulkTagLimit = limit
+	}
+}
+
+// DefaultMaxSearchAttributes is the largest number of attribute filters a resource search may carry
+// unless configured otherwise
+const DefaultMaxSearchAttributes = 10
+
+var maxSearchAttributes = DefaultMaxSearchAttributes
+
+// SetMaxSearchAttributes sets the largest number of attribute filters a resource search may carry.
+// Non-positive values keep the current maximum.
+func SetMaxSearchAttributes(limit int) {
+	if limit > 0 {
+		maxSearchAttributes = limit
+	}
 }
 
 // ResourceService handles business logic for resource operations
 type ResourceService struct {
-	resourceDAO     *dao.ResourceDAO
-	validationUtil  *util.ValidationUtil
-	cacheService    *util.CacheService
-	notificationSvc *util.NotificationService
-	eventBus        *util.EventBus
+	resourceDAO       *dao.ResourceDAO
+	resourceTypeDAO   *dao.ResourceTypeDAO
+	attributeGroupDAO *dao.AttributeGroupDAO
+	userDAO           *dao.UserDAO
+	validationUtil    *util.ValidationUtil
+	cacheService      *util.CacheService
+	notificationSvc   *util.NotificationService
+	eventBus          *util.EventBus
 }
 
 var _ IResourceService = &ResourceService{}
 
 // NewResourceService creates a new instance of ResourceService
-func NewResourceService(resourceDAO *dao.ResourceDAO, validationUtil *util.ValidationUtil, cacheService *util.CacheService, notificationSvc *util.NotificationService, eventBus *util.EventBus) *ResourceService {
+func NewResourceService(resourceDAO *dao.ResourceDAO, resourceTypeDAO *dao.ResourceTypeDAO, attributeGroupDAO *dao.AttributeGroupDAO, userDAO *dao.UserDAO, validationUtil *util.ValidationUtil, cacheService *util.CacheService, notificationSvc *util.NotificationService, eventBus *util.EventBus) *ResourceService {
 	service := &ResourceService{
-		resourceDAO:     resourceDAO,
-		validationUtil:  validationUtil,
-		cacheService:    cacheService,
-		notificationSvc: notificationSvc,
-		eventBus:        eventBus,
+		resourceDAO:       resourceDAO,
+		resourceTypeDAO:   resourceTypeDAO,
+		attributeGroupDAO: attributeGroupDAO,
+		userDAO:           userDAO,
+		validationUtil:    validationUtil,
+		cacheService:      cacheService,
+		notificationSvc:   notificationSvc,
+		eventBus:          eventBus,
 	}
 
 	// Set up event subscriptions
@@ -130,28 +181,50 @@ func (s *ResourceService) handleResourceDeleted(ctx context.Context, event util.
 
 // CreateResource handles the creation of a new resource
 func (s *ResourceService) CreateResource(ctx context.Context, resource model.Resource, creatorID string) (*model.Resource, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateResource(resource); err != nil {
 		logger.Error("Validation for resource data failed", zap.Error(err))
 		return nil, fmt.Errorf("invalid resource: %w", err)
 	}
 
+	if err := s.validateResourceType(ctx, resource.TypeID); err != nil {
+		logger.Error("Resource references an invalid resource type", zap.Error(err), zap.String("typeID", resource.TypeID))
+		return nil, err
+	}
+
+	if err := validateAttributesForGroup(ctx, s.attributeGroupDAO, s.validationUtil, resource.AttributeGroupID, resource.Attributes); err != nil {
+		logger.Error("Resource attributes failed attribute group validation", zap.Error(err), zap.String("attributeGroupID", resource.AttributeGroupID))
+		return nil, err
+	}
+
+	if err := checkTenantOwnership(ctx, TenantEntityResource, resource.OrganizationID); err != nil {
+		return nil, err
+	}
+	if err := checkAdminScope(ctx, s.userDAO, resource.OrganizationID, resource.DepartmentID); err != nil {
+		return nil, err
+	}
+
 	// Check if resource with the same ID already exists
 	if resource.ID != "" {
-		_, err := s.resourceDAO.GetResource(ctx, resource.ID)
-		if err == nil {
-			// Resource with this ID already exists
-			return nil, echo_errors.ErrResourceConflict
-		}
-		if err != echo_errors.ErrResourceNotFound {
+		exists, err := s.resourceDAO.Exists(ctx, resource.ID)
+		if err != nil {
 			// An error occurred while checking for existing resource
 			return nil, echo_errors.ErrDatabaseOperation
 		}
+		if exists {
+			// Resource with this ID already exists
+			return nil, echo_errors.ErrResourceConflict
+		}
 	}
 
 	resource.CreatedAt = time.Now()
 	resource.UpdatedAt = time.Now()
 	resource.CreatedBy = creatorID
 	resource.UpdatedBy = creatorID
+	resource.Version = 1 // The DAO stores every new resource as version 1
 
 	resourceID, err := s.resourceDAO.CreateResource(ctx, resource)
 	if err != nil {
@@ -161,6 +234,11 @@ func (s *ResourceService) CreateResource(ctx context.Context, resource model.Res
 
 	resource.ID = resourceID
 
+	// A dry run was rolled back, so neither the cache nor subscribers may hear of it
+	if helper_util.IsDryRun(ctx) {
+		return &resource, nil
+	}
+
 	// Update cache
 	if err := s.cacheService.SetResource(ctx, resource); err != nil {
 		logger.Warn("Failed to cache resource", zap.Error(err), zap.String("resourceID", resourceID))
@@ -175,17 +253,46 @@ func (s *ResourceService) CreateResource(ctx context.Context, resource model.Res
 
 // UpdateResource handles updates to an existing resource
 func (s *ResourceService) UpdateResource(ctx context.Context, resource model.Resource, updaterID string) (*model.Resource, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateResource(resource); err != nil {
 		logger.Error("Validation for resource data failed", zap.Error(err))
 		return nil, fmt.Errorf("invalid resource: %w", err)
 	}
 
+	if err := s.validateResourceType(ctx, resource.TypeID); err != nil {
+		logger.Error("Resource references an invalid resource type", zap.Error(err), zap.String("typeID", resource.TypeID))
+		return nil, err
+	}
+
+	if err := validateAttributesForGroup(ctx, s.attributeGroupDAO, s.validationUtil, resource.AttributeGroupID, resource.Attributes); err != nil {
+		logger.Error("Resource attributes failed attribute group validation", zap.Error(err), zap.String("attributeGroupID", resource.AttributeGroupID))
+		return nil, err
+	}
+
 	oldResource, err := s.resourceDAO.GetResource(ctx, resource.ID)
 	if err != nil {
 		logger.Error("Error retrieving existing resource", zap.Error(err), zap.String("resourceID", resource.ID))
 		return nil, err
 	}
 
+	// The resource may neither be changed by nor moved into another organization, nor shared by all of
+	// them
+	if err := checkTenantAccess(ctx, TenantEntityResource, oldResource.OrganizationID); err != nil {
+		return nil, err
+	}
+	if err := checkTenantOwnership(ctx, TenantEntityResource, resource.OrganizationID); err != nil {
+		return nil, err
+	}
+	if err := checkAdminScope(ctx, s.userDAO, oldResource.OrganizationID, oldResource.DepartmentID); err != nil {
+		return nil, err
+	}
+	if err := checkAdminScope(ctx, s.userDAO, resource.OrganizationID, resource.DepartmentID); err != nil {
+		return nil, err
+	}
+
 	resource.UpdatedAt = time.Now()
 	resource.UpdatedBy = updaterID
 
@@ -195,6 +302,11 @@ func (s *ResourceService) UpdateResource(ctx context.Context, resource model.Res
 		return nil, fmt.Errorf("failed to update resource: %w", err)
 	}
 
+	// A dry run was rolled back, so neither the cache nor subscribers may hear of it
+	if helper_util.IsDryRun(ctx) {
+		return updatedResource, nil
+	}
+
 	// Update cache
 	if err := s.cacheService.SetResource(ctx, *updatedResource); err != nil {
 		logger.Warn("Failed to update resource in cache", zap.Error(err), zap.String("resourceID", resource.ID))
@@ -212,6 +324,14 @@ func (s *ResourceService) UpdateResource(ctx context.Context, resource model.Res
 
 // DeleteResource handles the deletion of a resource
 func (s *ResourceService) DeleteResource(ctx context.Context, resourceID string, deleterID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
+	if err := s.authorizeResource(ctx, resourceID); err != nil {
+		return err
+	}
+
 	err := s.resourceDAO.DeleteResource(ctx, resourceID)
 	if err != nil {
 		logger.Error("Error deleting resource", zap.Error(err), zap.String("resourceID", resourceID), zap.String("deleterID", deleterID))
@@ -234,7 +354,12 @@ func (s *ResourceService) DeleteResource(ctx context.Context, resourceID string,
 func (s *ResourceService) GetResource(ctx context.Context, resourceID string) (*model.Resource, error) {
 	// Try to get from cache first
 	cachedResource, err := s.cacheService.GetResource(ctx, resourceID)
-	if err == nil && cachedResource != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindResource, resourceID, err)
+	} else if cachedResource != nil {
+		if err := checkTenantAccess(ctx, TenantEntityResource, cachedResource.OrganizationID); err != nil {
+			return nil, err
+		}
 		return cachedResource, nil
 	}
 
@@ -247,6 +372,10 @@ func (s *ResourceService) GetResource(ctx context.Context, resourceID string) (*
 		return nil, echo_errors.ErrInternalServer
 	}
 
+	if err := checkTenantAccess(ctx, TenantEntityResource, resource.OrganizationID); err != nil {
+		return nil, err
+	}
+
 	// Update cache
 	if err := s.cacheService.SetResource(ctx, *resource); err != nil {
 		logger.Warn("Failed to cache resource", zap.Error(err), zap.String("resourceID", resourceID))
@@ -255,9 +384,55 @@ func (s *ResourceService) GetResource(ctx context.Context, resourceID string) (*
 	return resource, nil
 }
 
-// ListResources retrieves all resources, possibly with pagination
+// GetResourcesByIDs retrieves up to MaxBatchGetIDs resources at once, from the cache where possible,
+// listing the IDs with no resource the caller may see as missing
+func (s *ResourceService) GetResourcesByIDs(ctx context.Context, resourceIDs []string) (*model.ResourceBatch, error) {
+	resources, missing, err := batchGet(ctx, batchSource[model.Resource]{
+		kind:   "resource",
+		idOf:   func(resource *model.Resource) string { return resource.ID },
+		cached: s.cacheService.GetResources,
+		load:   s.resourceDAO.GetResourcesByIDs,
+		store:  s.cacheService.SetMany,
+		visible: func(resource *model.Resource) bool {
+			return checkTenantAccess(ctx, TenantEntityResource, resource.OrganizationID) == nil
+		},
+	}, resourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ResourceBatch{Items: resources, Missing: missing}, nil
+}
+
+// ResourceExists reports whether a resource exists without fetching it
+func (s *ResourceService) ResourceExists(ctx context.Context, resourceID string) (bool, error) {
+	exists, err := s.resourceDAO.Exists(ctx, resourceID)
+	if err != nil {
+		logger.Error("Error checking resource existence", zap.Error(err), zap.String("resourceID", resourceID))
+		return false, fmt.Errorf("failed to check resource existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ListResources retrieves all resources, possibly with pagination. Scoped admins only see the resources
+// within their scope.
 func (s *ResourceService) ListResources(ctx context.Context, limit int, offset int) ([]*model.Resource, error) {
-	resources, err := s.resourceDAO.ListResources(ctx, limit, offset)
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := requestingAdminScope(ctx, s.userDAO)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []*model.Resource
+	if scope != nil {
+		resources, err = s.resourceDAO.ListResourcesInScope(ctx, *scope, limit, offset)
+	} else {
+		resources, err = s.resourceDAO.ListResources(ctx, limit, offset)
+	}
 	if err != nil {
 		logger.Error("Error listing resources", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
 		return nil, fmt.Errorf("failed to list resources: %w", err)
@@ -266,16 +441,100 @@ func (s *ResourceService) ListResources(ctx context.Context, limit int, offset i
 	return resources, nil
 }
 
-// SearchResources searches for resources based on criteria
+// ListResourcesByCursor retrieves the page of resources following cursor, or the first page when
+// cursor is empty. Prefer it over offset pagination for large listings.
+func (s *ResourceService) ListResourcesByCursor(ctx context.Context, cursor string, limit int) (*model.ResourcePage, error) {
+	limit, _, err := helper_util.ClampPagination(limit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := helper_util.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := requestingAdminScope(ctx, s.userDAO)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, next, err := s.resourceDAO.ListResourcesAfter(ctx, after, limit)
+	if err != nil {
+		logger.Error("Error listing resources by cursor", zap.Error(err), zap.Int("limit", limit))
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	// Scoped admins page through every resource, seeing only those within their scope, so a page may hold
+	// fewer than limit resources
+	if scope != nil {
+		inScope := make([]*model.Resource, 0, len(resources))
+		for _, resource := range resources {
+			if scopeCovers(scope, resource.OrganizationID, resource.DepartmentID) {
+				inScope = append(inScope, resource)
+			}
+		}
+		resources = inScope
+	}
+
+	page := &model.ResourcePage{Items: resources}
+	if next != nil {
+		page.NextCursor = helper_util.EncodeCursor(*next)
+	}
+	return page, nil
+}
+
+// ExportResources visits every resource matching criteria, newest first. It reads one page of the largest
+// allowed size at a time, so exports of large inventories never hold more than a page in memory, and
+// stops at the first error visit returns. The criteria are narrowed as SearchResources narrows them,
+// and their limit, offset and sort are not used.
+func (s *ResourceService) ExportResources(ctx context.Context, criteria model.ResourceSearchCriteria, visit func(*model.Resource) error) error {
+	var err error
+	if criteria.Attributes, err = normalizeAttributeFilters(criteria.Attributes); err != nil {
+		return err
+	}
+	if criteria, err = s.scopeResourceCriteria(ctx, criteria); err != nil {
+		return err
+	}
+
+	var after *helper_util.Cursor
+	for {
+		resources, next, err := s.resourceDAO.SearchResourcesAfter(ctx, criteria, after, helper_util.MaxPageLimit())
+		if err != nil {
+			logger.Error("Error exporting resources", zap.Error(err), zap.Any("criteria", criteria))
+			return fmt.Errorf("failed to export resources: %w", err)
+		}
+		for _, resource := range resources {
+			if checkTenantAccess(ctx, TenantEntityResource, resource.OrganizationID) != nil {
+				continue
+			}
+			if err := visit(resource); err != nil {
+				return err
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		after = next
+	}
+}
+
+// SearchResources searches for resources based on criteria, narrowed to the requesting user's
+// organization while resources are isolated per tenant and to their scope for a scoped admin. Resources
+// of other organizations are left out of the results too, so a page may hold fewer than limit resources.
 func (s *ResourceService) SearchResources(ctx context.Context, criteria model.ResourceSearchCriteria) ([]*model.Resource, error) {
 	logger.Info("Searching resources", zap.Any("criteria", criteria))
 
-	if criteria.Limit < 1 {
-		criteria.Limit = 10 // or any other default value
+	limit, offset, err := helper_util.ClampPagination(criteria.Limit, criteria.Offset)
+	if err != nil {
+		return nil, err
 	}
-
-	if criteria.Offset < 0 {
-		criteria.Offset = 0
+	criteria.Limit, criteria.Offset = limit, offset
+	if criteria.Attributes, err = normalizeAttributeFilters(criteria.Attributes); err != nil {
+		return nil, err
+	}
+	if criteria, err = s.scopeResourceCriteria(ctx, criteria); err != nil {
+		return nil, err
 	}
 
 	resources, err := s.resourceDAO.SearchResources(ctx, criteria)
@@ -286,12 +545,377 @@ func (s *ResourceService) SearchResources(ctx context.Context, criteria model.Re
 		return nil, fmt.Errorf("failed to search resources: %w", err)
 	}
 
-	logger.Info("Resources search completed", zap.Int("resourceCount", len(resources)))
-	return resources, nil
+	visible := make([]*model.Resource, 0, len(resources))
+	for _, resource := range resources {
+		if checkTenantAccess(ctx, TenantEntityResource, resource.OrganizationID) == nil {
+			visible = append(visible, resource)
+		}
+	}
+
+	logger.Info("Resources search completed", zap.Int("resourceCount", len(visible)))
+	return visible, nil
+}
+
+// GetResourcesByOrganization returns a page of an organization's resources, and of its sub-organizations'
+// when includeSubOrgs is set, along with their total count
+func (s *ResourceService) GetResourcesByOrganization(ctx context.Context, orgID string, includeSubOrgs bool, limit int, offset int) ([]*model.Resource, int64, error) {
+	if err := checkTenantAccess(ctx, TenantEntityResource, orgID); err != nil {
+		return nil, 0, err
+	}
+	if includeSubOrgs {
+		if err := checkSubOrganizationAccess(ctx, TenantEntityResource); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resources, total, err := s.resourceDAO.GetResourcesByOrganization(ctx, orgID, includeSubOrgs, limit, offset)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrOrganizationNotFound) {
+			return nil, 0, echo_errors.ErrOrganizationNotFound
+		}
+		logger.Error("Error listing organization resources", zap.Error(err), zap.String("orgID", orgID))
+		return nil, 0, fmt.Errorf("failed to list organization resources: %w", err)
+	}
+
+	return resources, total, nil
+}
+
+// GetResourcesByMinClassification returns, for audits, every resource classified at level or a more
+// sensitive level of the taxonomy, most sensitive first. Resources of other organizations are left out
+// while resources are isolated per tenant.
+func (s *ResourceService) GetResourcesByMinClassification(ctx context.Context, level string) ([]*model.Resource, error) {
+	classifications, err := util.ClassificationsAtOrAbove(level)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := s.resourceDAO.GetResourcesByClassifications(ctx, classifications)
+	if err != nil {
+		logger.Error("Error listing resources by classification", zap.Error(err), zap.String("level", level))
+		return nil, fmt.Errorf("failed to list resources by classification: %w", err)
+	}
+
+	visible := make([]*model.Resource, 0, len(resources))
+	for _, resource := range resources {
+		if checkTenantAccess(ctx, TenantEntityResource, resource.OrganizationID) == nil {
+			visible = append(visible, resource)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		rankI, _ := util.ClassificationRank(visible[i].Classification)
+		rankJ, _ := util.ClassificationRank(visible[j].Classification)
+		return rankI > rankJ
+	})
+
+	return visible, nil
+}
+
+// TransferResourceOwnership hands a resource over to newOwnerID, who must be an existing user
+func (s *ResourceService) TransferResourceOwnership(ctx context.Context, resourceID string, newOwnerID string, userID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
+	if newOwnerID == "" {
+		return fmt.Errorf("%w: new owner ID cannot be empty", echo_errors.ErrInvalidResourceData)
+	}
+
+	oldResource, err := s.resourceDAO.GetResource(ctx, resourceID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrResourceNotFound) {
+			return echo_errors.ErrResourceNotFound
+		}
+		logger.Error("Error retrieving existing resource", zap.Error(err), zap.String("resourceID", resourceID))
+		return fmt.Errorf("failed to get resource: %w", err)
+	}
+
+	if err := checkTenantAccess(ctx, TenantEntityResource, oldResource.OrganizationID); err != nil {
+		return err
+	}
+	if err := checkAdminScope(ctx, s.userDAO, oldResource.OrganizationID, oldResource.DepartmentID); err != nil {
+		return err
+	}
+
+	updatedResource, err := s.resourceDAO.TransferResourceOwnership(ctx, resourceID, newOwnerID, userID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrResourceNotFound) || errors.Is(err, echo_errors.ErrUserNotFound) {
+			return err
+		}
+		logger.Error("Error transferring resource ownership", zap.Error(err), zap.String("resourceID", resourceID), zap.String("userID", userID))
+		return fmt.Errorf("failed to transfer resource ownership: %w", err)
+	}
+
+	// Update cache
+	if err := s.cacheService.SetResource(ctx, *updatedResource); err != nil {
+		logger.Warn("Failed to update resource in cache", zap.Error(err), zap.String("resourceID", resourceID))
+	}
+
+	// Publish event for asynchronous processing
+	s.eventBus.Publish(ctx, "resource.updated", map[string]model.Resource{
+		"old": *oldResource,
+		"new": *updatedResource,
+	})
+
+	logger.Info("Resource ownership transferred",
+		zap.String("resourceID", resourceID),
+		zap.String("oldOwnerID", oldResource.OwnerID),
+		zap.String("newOwnerID", newOwnerID),
+		zap.String("userID", userID))
+	return nil
+}
+
+// AddResourceTags adds tags to a resource and returns its tags. Adding a tag the resource already
+// carries is a no-op.
+func (s *ResourceService) AddResourceTags(ctx context.Context, resourceID string, tags []string, updaterID string) ([]string, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeResource(ctx, resourceID); err != nil {
+		return nil, err
+	}
+
+	updatedTags, err := s.resourceDAO.AddResourceTags(ctx, resourceID, tags, updaterID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrResourceNotFound) {
+			return nil, echo_errors.ErrResourceNotFound
+		}
+		logger.Error("Error adding resource tags", zap.Error(err), zap.String("resourceID", resourceID), zap.String("updaterID", updaterID))
+		return nil, fmt.Errorf("failed to add resource tags: %w", err)
+	}
+
+	s.evictResource(ctx, resourceID)
+	logger.Info("Resource tags added", zap.String("resourceID", resourceID), zap.Strings("tags", tags), zap.String("updaterID", updaterID))
+	return updatedTags, nil
+}
+
+// RemoveResourceTags removes tags from a resource and returns its tags. Removing a tag the resource
+// does not carry is a no-op.
+func (s *ResourceService) RemoveResourceTags(ctx context.Context, resourceID string, tags []string, updaterID string) ([]string, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeResource(ctx, resourceID); err != nil {
+		return nil, err
+	}
+
+	updatedTags, err := s.resourceDAO.RemoveResourceTags(ctx, resourceID, tags, updaterID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrResourceNotFound) {
+			return nil, echo_errors.ErrResourceNotFound
+		}
+		logger.Error("Error removing resource tags", zap.Error(err), zap.String("resourceID", resourceID), zap.String("updaterID", updaterID))
+		return nil, fmt.Errorf("failed to remove resource tags: %w", err)
+	}
+
+	s.evictResource(ctx, resourceID)
+	logger.Info("Resource tags removed", zap.String("resourceID", resourceID), zap.Strings("tags", tags), zap.String("updaterID", updaterID))
+	return updatedTags, nil
+}
+
+// ListAllTags returns every tag in use on resources with its usage count
+func (s *ResourceService) ListAllTags(ctx context.Context) ([]model.TagCount, error) {
+	tagCounts, err := s.resourceDAO.ListAllTags(ctx)
+	if err != nil {
+		logger.Error("Error listing resource tags", zap.Error(err))
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return tagCounts, nil
+}
+
+// CheckIntegrity reports the resources whose reference properties disagree with their relationships.
+// With repair, relationships missing for a stored property are recreated.
+func (s *ResourceService) CheckIntegrity(ctx context.Context, repair bool) (*model.IntegrityReport, error) {
+	if repair {
+		if err := util.CheckWritable(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	report, err := s.resourceDAO.CheckIntegrity(ctx)
+	if err != nil {
+		logger.Error("Error checking resource integrity", zap.Error(err))
+		return nil, fmt.Errorf("failed to check resource integrity: %w", err)
+	}
+
+	if repair && len(report.Issues) > 0 {
+		if _, err := s.resourceDAO.RepairMissingRelationships(ctx, report); err != nil {
+			logger.Error("Error repairing resource relationships", zap.Error(err))
+			return nil, fmt.Errorf("failed to repair resource relationships: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+// BulkTagResources adds tags to every resource matching criteria and returns how many resources gained
+// a tag. Criteria must filter on something, and matching more resources than the configured limit
+// tags none of them. Criteria are narrowed like those of SearchResources.
+func (s *ResourceService) BulkTagResources(ctx context.Context, criteria model.ResourceSearchCriteria, tags []string, userID string) (int, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return 0, err
+	}
+	if criteria.Attributes, err = normalizeAttributeFilters(criteria.Attributes); err != nil {
+		return 0, err
+	}
+	// Narrowing to the requesting user's reach is no filter of their own
+	if !dao.HasResourceFilter(criteria) {
+		logger.Warn("Refusing to bulk tag resources without any filter", zap.String("userID", userID))
+		return 0, fmt.Errorf("%w: bulk tagging requires at least one filter", echo_errors.ErrInvalidSearchCriteria)
+	}
+	if criteria, err = s.scopeResourceCriteria(ctx, criteria); err != nil {
+		return 0, err
+	}
+
+	resourceIDs, err := s.resourceDAO.BulkTagResources(ctx, criteria, tags, bulkTagLimit)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrInvalidSearchCriteria) || errors.Is(err, echo_errors.ErrBulkLimitExceeded) {
+			return 0, err
+		}
+		logger.Error("Error bulk tagging resources", zap.Error(err), zap.String("userID", userID))
+		return 0, fmt.Errorf("failed to bulk tag resources: %w", err)
+	}
+
+	if err := s.cacheService.DeleteMany(ctx, db.CacheKindResource, resourceIDs); err != nil {
+		logger.Warn("Failed to delete bulk tagged resources from cache", zap.Error(err), zap.Int("count", len(resourceIDs)))
+	}
+
+	logger.Info("Resources bulk tagged", zap.Int("count", len(resourceIDs)), zap.Strings("tags", tags), zap.String("userID", userID))
+	return len(resourceIDs), nil
+}
+
+// scopeResourceCriteria narrows criteria to the resources the requesting user may reach: those of their
+// organization while resources are isolated per tenant, and those within their scope for a scoped
+// admin. Criteria naming an organization or department beyond that reach are refused with ErrForbidden.
+func (s *ResourceService) scopeResourceCriteria(ctx context.Context, criteria model.ResourceSearchCriteria) (model.ResourceSearchCriteria, error) {
+	if tenantGuarded(ctx, TenantEntityResource) {
+		if criteria.OrganizationID == "" {
+			criteria.OrganizationID, _ = ctx.Value("requestingOrganizationID").(string)
+			if criteria.OrganizationID == "" {
+				logger.Warn("Resource search without an organization denied", zap.String("requestingUserID", helper_util.ActorFromContext(ctx)))
+				return criteria, echo_errors.ErrForbidden
+			}
+		} else if err := checkTenantAccess(ctx, TenantEntityResource, criteria.OrganizationID); err != nil {
+			return criteria, err
+		}
+	}
+
+	scope, err := requestingAdminScope(ctx, s.userDAO)
+	if err != nil || scope == nil {
+		return criteria, err
+	}
+	if criteria.OrganizationID != "" && criteria.OrganizationID != scope.OrganizationID ||
+		criteria.DepartmentID != "" && !scopeCovers(scope, scope.OrganizationID, criteria.DepartmentID) {
+		logger.Warn("Out of scope resource search denied", zap.String("requestingUserID", scope.UserID))
+		return criteria, echo_errors.ErrForbidden
+	}
+	criteria.OrganizationID = scope.OrganizationID
+	if scope.DepartmentID != "" {
+		criteria.DepartmentIDs = append([]string{}, scope.DepartmentIDs...)
+		if len(criteria.DepartmentIDs) == 0 {
+			criteria.DepartmentIDs = []string{scope.DepartmentID}
+		}
+	}
+	return criteria, nil
+}
+
+// authorizeResource applies the tenant guard and the admin scope to a resource the operation would not
+// read otherwise. It only reads the resource while isolation is enforced for resources or the requesting
+// user is a scoped admin.
+func (s *ResourceService) authorizeResource(ctx context.Context, resourceID string) error {
+	scope, err := requestingAdminScope(ctx, s.userDAO)
+	if err != nil {
+		return err
+	}
+	if scope == nil && !tenantIsolationEnabled(TenantEntityResource) {
+		return nil
+	}
+
+	resource, err := s.resourceDAO.GetResource(ctx, resourceID)
+	if err != nil {
+		if errors.Is(err, echo_errors.ErrResourceNotFound) {
+			return echo_errors.ErrResourceNotFound
+		}
+		logger.Error("Error retrieving resource", zap.Error(err), zap.String("resourceID", resourceID))
+		return fmt.Errorf("failed to get resource: %w", err)
+	}
+	if err := checkTenantAccess(ctx, TenantEntityResource, resource.OrganizationID); err != nil {
+		return err
+	}
+	if scope != nil && !scopeCovers(scope, resource.OrganizationID, resource.DepartmentID) {
+		logger.Warn("Out of scope resource administration denied", zap.String("resourceID", resourceID), zap.String("requestingUserID", scope.UserID))
+		return echo_errors.ErrForbidden
+	}
+	return nil
+}
+
+// evictResource drops a resource from the cache after a change made without reading it back
+func (s *ResourceService) evictResource(ctx context.Context, resourceID string) {
+	if err := s.cacheService.DeleteResource(ctx, resourceID); err != nil {
+		logger.Warn("Failed to delete resource from cache", zap.Error(err), zap.String("resourceID", resourceID))
+	}
+}
+
+// normalizeTags trims tags and drops blank and repeated ones. At least one tag must remain.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one tag is required", echo_errors.ErrInvalidResourceData)
+	}
+	return normalized, nil
 }
 
 // Helper methods
 
+// validateResourceType ensures the resource type a resource points at exists, since the HAS_TYPE
+// relationship is only created when the type node can be matched
+func (s *ResourceService) validateResourceType(ctx context.Context, typeID string) error {
+	if typeID == "" {
+		return fmt.Errorf("%w: resource type ID cannot be empty", echo_errors.ErrInvalidResourceType)
+	}
+
+	exists, err := s.resourceTypeDAO.Exists(ctx, typeID)
+	if err != nil {
+		return echo_errors.ErrDatabaseOperation
+	}
+	if !exists {
+		return echo_errors.ErrResourceTypeNotFound
+	}
+
+	return nil
+}
+
 func (s *ResourceService) updateResourceIndexes(ctx context.Context, resource model.Resource) error {
 	// Implementation for updating indexes
 	return nil
@@ -311,3 +935,47 @@ func (s *ResourceService) cleanupResourceRelatedData(ctx context.Context, resour
 	// Implementation for cleaning up related data
 	return nil
 }
+
+// GetResourceDiff returns the fields that changed from one version of a resource to another
+func (s *ResourceService) GetResourceDiff(ctx context.Context, resourceID string, fromVersion, toVersion int) (*model.ResourceDiff, error) {
+	if fromVersion < 1 || toVersion < 1 {
+		return nil, fmt.Errorf("%w: versions start at 1", echo_errors.ErrInvalidResourceData)
+	}
+	// Reading the resource checks it exists and may be seen from the requesting organization
+	if _, err := s.GetResource(ctx, resourceID); err != nil {
+		return nil, err
+	}
+
+	diff, err := s.resourceDAO.GetResourceDiff(ctx, resourceID, fromVersion, toVersion)
+	if err != nil {
+		logger.Error("Error comparing resource versions", zap.Error(err), zap.String("resourceID", resourceID))
+		return nil, err
+	}
+	return diff, nil
+}
+
+// normalizeAttributeFilters trims the keys of a search's attribute filters and folds the filters
+// naming the same key into one. Each filter left costs the search a scan of the resources' attributes,
+// so an empty key, a key asked for two different values, or more keys than the configured maximum are
+// rejected with ErrInvalidSearchCriteria.
+func normalizeAttributeFilters(attributes map[string]interface{}) (map[string]interface{}, error) {
+	if len(attributes) == 0 {
+		return attributes, nil
+	}
+	normalized := make(map[string]interface{}, len(attributes))
+	for key, value := range attributes {
+		trimmed := strings.TrimSpace(key)
+		if trimmed == "" {
+			return nil, fmt.Errorf("%w: attribute filters need a key", echo_errors.ErrInvalidSearchCriteria)
+		}
+		if other, ok := normalized[trimmed]; ok && !reflect.DeepEqual(other, value) {
+			return nil, fmt.Errorf("%w: attribute %q is filtered on with different values", echo_errors.ErrInvalidSearchCriteria, trimmed)
+		}
+		normalized[trimmed] = value
+	}
+	if len(normalized) > maxSearchAttributes {
+		return nil, fmt.Errorf("%w: %d attribute filters exceed the maximum of %d",
+			echo_errors.ErrInvalidSearchCriteria, len(normalized), maxSearchAttributes)
+	}
+	return normalized, nil
+}
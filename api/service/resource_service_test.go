@@ -0,0 +1,254 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+func TestResourceServiceResourceTypeValidation(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.WithValue(context.Background(), "requestingUserID", "admin")
+
+	tx := &mock.MockTransaction{}
+	session := &mock.MockTxSession{Tx: tx}
+	session.On("Close").Return(nil)
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	resourceService := mock.NewResourceService(driver, nil)
+
+	resource := model.Resource{
+		ID:             "r1",
+		Name:           "Quarterly Report",
+		Type:           "DOCUMENT",
+		OrganizationID: "org1",
+		OwnerID:        "u1",
+		Status:         "active",
+	}
+
+	t.Run("CreateResource_UnknownType", func(t *testing.T) {
+		notFoundResult := &mock.MockResult{}
+		notFoundResult.On("Next").Return(true).Once()
+		notFoundResult.On("Next").Return(false)
+		notFoundResult.On("Record").Return(&neo4j.Record{Keys: []string{"found"}, Values: []any{false}})
+		session.On("Run", testify_mock.Anything, map[string]interface{}{"id": "missing-type"}, testify_mock.Anything).Return(notFoundResult, nil)
+
+		unknownType := resource
+		unknownType.TypeID = "missing-type"
+
+		createdResource, err := resourceService.CreateResource(ctx, unknownType, "admin")
+
+		assert.Nil(t, createdResource)
+		assert.Equal(t, echo_errors.ErrResourceTypeNotFound, err)
+	})
+
+	t.Run("CreateResource_MissingTypeID", func(t *testing.T) {
+		createdResource, err := resourceService.CreateResource(ctx, resource, "admin")
+
+		assert.Nil(t, createdResource)
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidResourceType))
+	})
+}
+
+func TestResourceServiceListResourcesLimits(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	newService := func() (*service.ResourceService, *mock.MockSession) {
+		session := &mock.MockSession{}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return mock.NewResourceService(driver, nil), session
+	}
+
+	emptyResult := func() *mock.MockResult {
+		result := &mock.MockResult{}
+		result.On("Next").Return(false)
+		return result
+	}
+
+	t.Run("ZeroLimitUsesDefault", func(t *testing.T) {
+		resourceService, session := newService()
+		session.On("Run", testify_mock.Anything, map[string]interface{}{"limit": helper_util.DefaultPageLimit, "offset": 0}, testify_mock.Anything).
+			Return(emptyResult(), nil)
+
+		_, err := resourceService.ListResources(ctx, 0, 0)
+
+		assert.NoError(t, err)
+		session.AssertNumberOfCalls(t, "Run", 1)
+	})
+
+	t.Run("NegativeOffsetIsFloored", func(t *testing.T) {
+		resourceService, session := newService()
+		session.On("Run", testify_mock.Anything, map[string]interface{}{"limit": 5, "offset": 0}, testify_mock.Anything).
+			Return(emptyResult(), nil)
+
+		_, err := resourceService.ListResources(ctx, 5, -10)
+
+		assert.NoError(t, err)
+		session.AssertNumberOfCalls(t, "Run", 1)
+	})
+
+	t.Run("OverMaxLimitIsRejected", func(t *testing.T) {
+		resourceService, session := newService()
+
+		resources, err := resourceService.ListResources(ctx, helper_util.MaxPageLimit()+1, 0)
+
+		assert.Nil(t, resources)
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidSearchCriteria))
+		session.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("SearchOverMaxLimitIsRejected", func(t *testing.T) {
+		resourceService, session := newService()
+
+		resources, err := resourceService.SearchResources(ctx, model.ResourceSearchCriteria{Limit: helper_util.MaxPageLimit() + 1})
+
+		assert.Nil(t, resources)
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidSearchCriteria))
+		session.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+	})
+}
+
+func TestResourceServiceMinClassification(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	util.SetClassificationLevels([]string{"public", "internal", "confidential", "restricted"})
+	defer util.SetClassificationLevels(nil)
+
+	ctx := context.Background()
+
+	resourceNode := func(id string, classification string) neo4j.Node {
+		return mock.ResourceNode(id, map[string]any{
+			"name":           id,
+			"classification": classification,
+		})
+	}
+
+	newService := func() (*service.ResourceService, *mock.MockSession) {
+		session := &mock.MockSession{}
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return mock.NewResourceService(driver, nil), session
+	}
+
+	t.Run("LevelAndAbove", func(t *testing.T) {
+		resourceService, session := newService()
+		result := &mock.MockResult{}
+		result.On("Next").Return(true).Twice()
+		result.On("Next").Return(false)
+		result.On("Record").Return(&neo4j.Record{Values: []any{resourceNode("r1", "confidential")}}).Once()
+		result.On("Record").Return(&neo4j.Record{Values: []any{resourceNode("r2", "restricted")}}).Once()
+		session.On("Run", testify_mock.Anything, map[string]interface{}{"classifications": []string{"confidential", "restricted"}}, testify_mock.Anything).
+			Return(result, nil)
+
+		resources, err := resourceService.GetResourcesByMinClassification(ctx, "confidential")
+
+		assert.NoError(t, err)
+		if assert.Len(t, resources, 2) {
+			assert.Equal(t, "r2", resources[0].ID)
+			assert.Equal(t, "r1", resources[1].ID)
+		}
+	})
+
+	t.Run("UnknownLevelIsRejected", func(t *testing.T) {
+		resourceService, session := newService()
+
+		resources, err := resourceService.GetResourcesByMinClassification(ctx, "top-secret")
+
+		assert.Nil(t, resources)
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidSearchCriteria))
+		session.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+	})
+}
+
+func TestResourceServiceSearchAttributeFilters(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	service.SetMaxSearchAttributes(3)
+	defer service.SetMaxSearchAttributes(service.DefaultMaxSearchAttributes)
+
+	ctx := context.Background()
+	newService := func() (*service.ResourceService, *mock.FakeDriver) {
+		driver := mock.NewFakeDriver()
+		return mock.NewResourceService(driver, nil), driver
+	}
+
+	t.Run("Each distinct attribute is matched once", func(t *testing.T) {
+		resourceService, driver := newService()
+
+		_, err := resourceService.SearchResources(ctx, model.ResourceSearchCriteria{Attributes: map[string]interface{}{
+			"project":   "apollo",
+			" project ": "apollo",
+			"level":     float64(3),
+			"region":    "eu",
+		}})
+
+		assert.NoError(t, err)
+		queries := driver.QueriesContaining("r.attributes CONTAINS")
+		if assert.Len(t, queries, 1) {
+			assert.Equal(t, 3, strings.Count(queries[0].Cypher, "r.attributes CONTAINS"))
+			assert.Equal(t, `"level":3`, queries[0].Params["attr0"])
+			assert.Equal(t, `"project":"apollo"`, queries[0].Params["attr1"])
+			assert.Equal(t, `"region":"eu"`, queries[0].Params["attr2"])
+		}
+	})
+
+	t.Run("More attributes than the maximum are rejected", func(t *testing.T) {
+		resourceService, driver := newService()
+
+		resources, err := resourceService.SearchResources(ctx, model.ResourceSearchCriteria{Attributes: map[string]interface{}{
+			"a": "1", "b": "2", "c": "3", "d": "4",
+		}})
+
+		assert.Nil(t, resources)
+		assert.ErrorIs(t, err, echo_errors.ErrInvalidSearchCriteria)
+		assert.Contains(t, err.Error(), "4 attribute filters exceed the maximum of 3")
+		status, _ := util.MapError(err)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Empty(t, driver.Queries())
+	})
+
+	t.Run("An attribute asked for two values is rejected", func(t *testing.T) {
+		resourceService, driver := newService()
+
+		_, err := resourceService.SearchResources(ctx, model.ResourceSearchCriteria{Attributes: map[string]interface{}{
+			"project": "apollo", "project ": "gemini",
+		}})
+
+		assert.ErrorIs(t, err, echo_errors.ErrInvalidSearchCriteria)
+		assert.Empty(t, driver.Queries())
+	})
+
+	t.Run("Bulk tagging is held to the same maximum", func(t *testing.T) {
+		resourceService, driver := newService()
+		criteria := model.ResourceSearchCriteria{Attributes: map[string]interface{}{
+			"a": "1", "b": "2", "c": "3", "d": "4",
+		}}
+
+		_, err := resourceService.BulkTagResources(ctx, criteria, []string{"archived"}, "admin")
+
+		assert.ErrorIs(t, err, echo_errors.ErrInvalidSearchCriteria)
+		assert.Empty(t, driver.Queries())
+	})
+}
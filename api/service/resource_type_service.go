@@ -10,10 +10,12 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IResourceTypeService defines the interface for resource type operations
@@ -105,6 +107,10 @@ func (s *ResourceTypeService) handleResourceTypeDeleted(ctx context.Context, eve
 
 // CreateResourceType handles the creation of a new resource type
 func (s *ResourceTypeService) CreateResourceType(ctx context.Context, resourceType model.ResourceType, creatorID string) (*model.ResourceType, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateResourceType(resourceType); err != nil {
 		logger.Error("Validation for resource type data failed", zap.Error(err))
 		return nil, fmt.Errorf("invalid resource type: %w", err)
@@ -137,6 +143,10 @@ func (s *ResourceTypeService) CreateResourceType(ctx context.Context, resourceTy
 
 // UpdateResourceType handles updates to an existing resource type
 func (s *ResourceTypeService) UpdateResourceType(ctx context.Context, resourceType model.ResourceType, updaterID string) (*model.ResourceType, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateResourceType(resourceType); err != nil {
 		logger.Error("Validation for resource type data failed", zap.Error(err))
 		return nil, fmt.Errorf("invalid resource type: %w", err)
@@ -174,6 +184,10 @@ func (s *ResourceTypeService) UpdateResourceType(ctx context.Context, resourceTy
 
 // DeleteResourceType handles the deletion of a resource type
 func (s *ResourceTypeService) DeleteResourceType(ctx context.Context, resourceTypeID string, deleterID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
 	err := s.resourceTypeDAO.DeleteResourceType(ctx, resourceTypeID)
 	if err != nil {
 		logger.Error("Error deleting resource type", zap.Error(err), zap.String("resourceTypeID", resourceTypeID), zap.String("deleterID", deleterID))
@@ -196,7 +210,9 @@ func (s *ResourceTypeService) DeleteResourceType(ctx context.Context, resourceTy
 func (s *ResourceTypeService) GetResourceType(ctx context.Context, resourceTypeID string) (*model.ResourceType, error) {
 	// Try to get from cache first
 	cachedResourceType, err := s.cacheService.GetResourceType(ctx, resourceTypeID)
-	if err == nil && cachedResourceType != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindResourceType, resourceTypeID, err)
+	} else if cachedResourceType != nil {
 		return cachedResourceType, nil
 	}
 
@@ -219,6 +235,11 @@ func (s *ResourceTypeService) GetResourceType(ctx context.Context, resourceTypeI
 
 // ListResourceTypes retrieves all resource types, possibly with pagination
 func (s *ResourceTypeService) ListResourceTypes(ctx context.Context, limit int, offset int) ([]*model.ResourceType, error) {
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	resourceTypes, err := s.resourceTypeDAO.ListResourceTypes(ctx, limit, offset)
 	if err != nil {
 		logger.Error("Error listing resource types", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
@@ -10,25 +10,38 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IRoleService defines the interface for role operations
 type IRoleService interface {
 	CreateRole(ctx context.Context, role model.Role, creatorID string) (*model.Role, error)
 	UpdateRole(ctx context.Context, role model.Role, updaterID string) (*model.Role, error)
-	DeleteRole(ctx context.Context, roleID string, deleterID string) error
+	DeleteRole(ctx context.Context, roleID string, deleterID string, force bool) error
 	GetRole(ctx context.Context, roleID string) (*model.Role, error)
 	ListRoles(ctx context.Context, limit int, offset int) ([]*model.Role, error)
 	SearchRoles(ctx context.Context, query string, limit, offset int) ([]*model.Role, error)
+	GetUsersByRole(ctx context.Context, roleID string, includeSuspended bool) ([]*model.User, error)
+	GetGroupsByRole(ctx context.Context, roleID string) ([]*model.Group, error)
+	AnalyzeRoleUsage(ctx context.Context, roleID string) (*model.RoleUsageAnalysis, error)
+	AssignRoleToUsers(ctx context.Context, roleID string, userIDs []string, actorID string) (*model.RoleAssignment, error)
+	UnassignRoleFromUsers(ctx context.Context, roleID string, userIDs []string, actorID string) (*model.RoleAssignment, error)
+	UpdateRolePermissions(ctx context.Context, roleID string, change model.RolePermissionsChange, actorID string) (*model.RolePermissions, error)
 }
 
+// MaxRoleAssignmentUsers is the most users a single bulk role assignment may name
+const MaxRoleAssignmentUsers = 1000
+
 // RoleService handles business logic for role operations
 type RoleService struct {
 	roleDAO         *dao.RoleDAO
+	userDAO         *dao.UserDAO
+	sodRuleDAO      *dao.SoDRuleDAO
 	validationUtil  *util.ValidationUtil
 	cacheService    *util.CacheService
 	notificationSvc *util.NotificationService
@@ -38,9 +51,11 @@ type RoleService struct {
 var _ IRoleService = &RoleService{}
 
 // NewRoleService creates a new instance of RoleService
-func NewRoleService(roleDAO *dao.RoleDAO, validationUtil *util.ValidationUtil, cacheService *util.CacheService, notificationSvc *util.NotificationService, eventBus *util.EventBus) *RoleService {
+func NewRoleService(roleDAO *dao.RoleDAO, userDAO *dao.UserDAO, sodRuleDAO *dao.SoDRuleDAO, validationUtil *util.ValidationUtil, cacheService *util.CacheService, notificationSvc *util.NotificationService, eventBus *util.EventBus) *RoleService {
 	service := &RoleService{
 		roleDAO:         roleDAO,
+		userDAO:         userDAO,
+		sodRuleDAO:      sodRuleDAO,
 		validationUtil:  validationUtil,
 		cacheService:    cacheService,
 		notificationSvc: notificationSvc,
@@ -130,6 +145,10 @@ func (s *RoleService) handleRoleDeleted(ctx context.Context, event util.Event) e
 
 // CreateRole handles the creation of a new role
 func (s *RoleService) CreateRole(ctx context.Context, role model.Role, creatorID string) (*model.Role, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateRole(role); err != nil {
 		return nil, fmt.Errorf("invalid role: %w", err)
 	}
@@ -159,6 +178,10 @@ func (s *RoleService) CreateRole(ctx context.Context, role model.Role, creatorID
 
 // UpdateRole handles updates to an existing role
 func (s *RoleService) UpdateRole(ctx context.Context, role model.Role, updaterID string) (*model.Role, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateRole(role); err != nil {
 		return nil, fmt.Errorf("invalid role: %w", err)
 	}
@@ -192,11 +215,18 @@ func (s *RoleService) UpdateRole(ctx context.Context, role model.Role, updaterID
 	return updatedRole, nil
 }
 
-// DeleteRole handles the deletion of a role
-func (s *RoleService) DeleteRole(ctx context.Context, roleID string, deleterID string) error {
-	err := s.roleDAO.DeleteRole(ctx, roleID)
+// DeleteRole handles the deletion of a role, refusing roles still in use unless force is set
+func (s *RoleService) DeleteRole(ctx context.Context, roleID string, deleterID string, force bool) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
+	err := s.roleDAO.DeleteRole(ctx, roleID, force)
 	if err != nil {
 		logger.Error("Error deleting role", zap.Error(err), zap.String("roleID", roleID), zap.String("deleterID", deleterID))
+		if err == echo_errors.ErrRoleNotFound || err == echo_errors.ErrRoleInUse {
+			return err
+		}
 		return fmt.Errorf("failed to delete role: %w", err)
 	}
 
@@ -216,7 +246,9 @@ func (s *RoleService) DeleteRole(ctx context.Context, roleID string, deleterID s
 func (s *RoleService) GetRole(ctx context.Context, roleID string) (*model.Role, error) {
 	// Try to get from cache first
 	cachedRole, err := s.cacheService.GetRole(ctx, roleID)
-	if err == nil && cachedRole != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindRole, roleID, err)
+	} else if cachedRole != nil {
 		return cachedRole, nil
 	}
 
@@ -239,6 +271,11 @@ func (s *RoleService) GetRole(ctx context.Context, roleID string) (*model.Role,
 
 // ListRoles retrieves all roles, possibly with pagination
 func (s *RoleService) ListRoles(ctx context.Context, limit int, offset int) ([]*model.Role, error) {
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	roles, err := s.roleDAO.ListRoles(ctx, limit, offset)
 	if err != nil {
 		logger.Error("Error listing roles", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
@@ -256,6 +293,222 @@ func (s *RoleService) SearchRoles(ctx context.Context, query string, limit, offs
 	return nil, fmt.Errorf("role search not implemented")
 }
 
+// GetUsersByRole retrieves the users directly assigned a role, leaving out suspended users unless requested
+func (s *RoleService) GetUsersByRole(ctx context.Context, roleID string, includeSuspended bool) ([]*model.User, error) {
+	users, err := s.roleDAO.GetUsersByRole(ctx, roleID, includeSuspended)
+	if err != nil {
+		logger.Error("Error retrieving users by role", zap.Error(err), zap.String("roleID", roleID))
+		return nil, fmt.Errorf("failed to get users by role: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetGroupsByRole retrieves the groups that hold a role directly
+func (s *RoleService) GetGroupsByRole(ctx context.Context, roleID string) ([]*model.Group, error) {
+	groups, err := s.roleDAO.GetGroupsByRole(ctx, roleID)
+	if err != nil {
+		logger.Error("Error retrieving groups by role", zap.Error(err), zap.String("roleID", roleID))
+		return nil, fmt.Errorf("failed to get groups by role: %w", err)
+	}
+
+	return groups, nil
+}
+
+// AnalyzeRoleUsage analyzes the usage of a role
+func (s *RoleService) AnalyzeRoleUsage(ctx context.Context, roleID string) (*model.RoleUsageAnalysis, error) {
+	analysis, err := s.roleDAO.AnalyzeRoleUsage(ctx, roleID)
+	if err != nil {
+		logger.Error("Error analyzing role usage", zap.Error(err), zap.String("roleID", roleID))
+		if err == echo_errors.ErrRoleNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to analyze role usage: %w", err)
+	}
+
+	return analysis, nil
+}
+
+// AssignRoleToUsers gives a role to many users at once, such as a team being onboarded. Users who
+// already hold the role are left as they are; an unknown user fails the whole assignment, and so does a
+// user outside a scoped admin's scope or whose roles would then break separation of duties.
+func (s *RoleService) AssignRoleToUsers(ctx context.Context, roleID string, userIDs []string, actorID string) (*model.RoleAssignment, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	assignment, err := newRoleAssignment(roleID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRoleAssignment(ctx, roleID, assignment.UserIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.roleDAO.AssignRoleToUsers(ctx, roleID, assignment.UserIDs); err != nil {
+		if errors.Is(err, echo_errors.ErrRoleNotFound) || errors.Is(err, echo_errors.ErrUserNotFound) {
+			return nil, err
+		}
+		logger.Error("Error assigning role to users", zap.Error(err), zap.String("roleID", roleID), zap.String("actorID", actorID))
+		return nil, fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	s.roleAssignmentChanged(ctx, "role.assigned", *assignment)
+	logger.Info("Role assigned to users successfully", zap.String("roleID", roleID), zap.Int("userCount", len(assignment.UserIDs)), zap.String("actorID", actorID))
+	return assignment, nil
+}
+
+// UnassignRoleFromUsers takes a role away from many users at once. Users who do not hold the role are
+// left as they are.
+func (s *RoleService) UnassignRoleFromUsers(ctx context.Context, roleID string, userIDs []string, actorID string) (*model.RoleAssignment, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	assignment, err := newRoleAssignment(roleID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.roleDAO.UnassignRoleFromUsers(ctx, roleID, assignment.UserIDs); err != nil {
+		if errors.Is(err, echo_errors.ErrRoleNotFound) {
+			return nil, err
+		}
+		logger.Error("Error unassigning role from users", zap.Error(err), zap.String("roleID", roleID), zap.String("actorID", actorID))
+		return nil, fmt.Errorf("failed to unassign role: %w", err)
+	}
+
+	s.roleAssignmentChanged(ctx, "role.unassigned", *assignment)
+	logger.Info("Role unassigned from users successfully", zap.String("roleID", roleID), zap.Int("userCount", len(assignment.UserIDs)), zap.String("actorID", actorID))
+	return assignment, nil
+}
+
+// UpdateRolePermissions grants a role the permissions of change.Add and revokes those of change.Remove,
+// returning the permissions the role holds afterwards. Each list is applied in one transaction, the
+// grants first; an unknown permission fails its list without changing anything.
+func (s *RoleService) UpdateRolePermissions(ctx context.Context, roleID string, change model.RolePermissionsChange, actorID string) (*model.RolePermissions, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	add, remove := distinctIDs(change.Add), distinctIDs(change.Remove)
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, fmt.Errorf("%w: add or remove must name at least one permission", echo_errors.ErrInvalidRoleData)
+	}
+	adding := make(map[string]bool, len(add))
+	for _, permissionID := range add {
+		adding[permissionID] = true
+	}
+	for _, permissionID := range remove {
+		if adding[permissionID] {
+			return nil, fmt.Errorf("%w: permission %s is both added and removed", echo_errors.ErrInvalidRoleData, permissionID)
+		}
+	}
+
+	if len(add) > 0 {
+		if err := s.roleDAO.AssignPermissionsToRole(ctx, roleID, add); err != nil {
+			return nil, s.rolePermissionsError(err, roleID, actorID)
+		}
+	}
+	if len(remove) > 0 {
+		if err := s.roleDAO.RemovePermissionsFromRole(ctx, roleID, remove); err != nil {
+			s.rolePermissionsChanged(ctx, roleID)
+			return nil, s.rolePermissionsError(err, roleID, actorID)
+		}
+	}
+	s.rolePermissionsChanged(ctx, roleID)
+
+	permissionIDs, err := s.roleDAO.GetRolePermissions(ctx, roleID)
+	if err != nil {
+		logger.Error("Error retrieving role permissions", zap.Error(err), zap.String("roleID", roleID))
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	if permissionIDs == nil {
+		permissionIDs = []string{}
+	}
+	permissions := &model.RolePermissions{RoleID: roleID, PermissionIDs: permissionIDs}
+	s.eventBus.Publish(ctx, "role.permissions_changed", *permissions)
+
+	logger.Info("Role permissions updated successfully",
+		zap.String("roleID", roleID),
+		zap.Int("added", len(add)),
+		zap.Int("removed", len(remove)),
+		zap.String("actorID", actorID))
+	return permissions, nil
+}
+
+// rolePermissionsError passes on the errors callers can act upon and wraps the rest
+func (s *RoleService) rolePermissionsError(err error, roleID string, actorID string) error {
+	if errors.Is(err, echo_errors.ErrRoleNotFound) || errors.Is(err, echo_errors.ErrPermissionNotFound) {
+		return err
+	}
+	logger.Error("Error updating role permissions", zap.Error(err), zap.String("roleID", roleID), zap.String("actorID", actorID))
+	return fmt.Errorf("failed to update role permissions: %w", err)
+}
+
+// rolePermissionsChanged drops the cached role, whose permissions are out of date
+func (s *RoleService) rolePermissionsChanged(ctx context.Context, roleID string) {
+	if err := s.cacheService.DeleteRole(ctx, roleID); err != nil {
+		logger.Warn("Failed to delete role from cache", zap.Error(err), zap.String("roleID", roleID))
+	}
+}
+
+// newRoleAssignment drops empty and repeated user IDs, rejecting an assignment naming no user or more
+// than MaxRoleAssignmentUsers
+func newRoleAssignment(roleID string, userIDs []string) (*model.RoleAssignment, error) {
+	userIDs = distinctIDs(userIDs)
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("%w: user_ids must name at least one user", echo_errors.ErrInvalidRoleData)
+	}
+	if len(userIDs) > MaxRoleAssignmentUsers {
+		return nil, fmt.Errorf("%w: %d users named, at most %d allowed", echo_errors.ErrInvalidRoleData, len(userIDs), MaxRoleAssignmentUsers)
+	}
+	return &model.RoleAssignment{RoleID: roleID, UserIDs: userIDs}, nil
+}
+
+// checkRoleAssignment holds every user given roleID to the rules a single user's role change is held
+// to: a scoped admin may only assign roles within their scope, and the roles the user would hold are
+// checked against the SoD rules
+func (s *RoleService) checkRoleAssignment(ctx context.Context, roleID string, userIDs []string) error {
+	scope, err := requestingAdminScope(ctx, s.userDAO)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		user, err := s.userDAO.GetUser(ctx, userID)
+		if err != nil {
+			logger.Error("Error retrieving user to assign a role to", zap.Error(err), zap.String("userID", userID))
+			return err
+		}
+		if scope != nil && !scopeCovers(scope, user.OrganizationID, user.DepartmentID) {
+			logger.Warn("Out of scope role assignment denied", zap.String("userID", userID), zap.String("requestingUserID", scope.UserID))
+			return echo_errors.ErrForbidden
+		}
+		held := false
+		for _, id := range user.RoleIds {
+			held = held || id == roleID
+		}
+		if !held {
+			user.RoleIds = append(user.RoleIds, roleID)
+		}
+		if err := enforceSoD(ctx, s.sodRuleDAO, *user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roleAssignmentChanged drops the cached users whose roles changed, so their next read reflects the
+// new permissions, and publishes the change for the other caches and subscribers
+func (s *RoleService) roleAssignmentChanged(ctx context.Context, eventType string, assignment model.RoleAssignment) {
+	if err := s.cacheService.DeleteMany(ctx, db.CacheKindUser, assignment.UserIDs); err != nil {
+		logger.Warn("Failed to delete reassigned users from cache", zap.Error(err), zap.String("roleID", assignment.RoleID))
+	}
+	s.eventBus.Publish(ctx, eventType, assignment)
+}
+
 // Helper methods
 
 func (s *RoleService) updateRoleIndexes(ctx context.Context, role model.Role) error {
@@ -0,0 +1,192 @@
+// api/service/search_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+const (
+	// DefaultSearchTypeLimit is how many hits a global search takes from each type unless asked otherwise
+	DefaultSearchTypeLimit = 10
+	// MaxSearchResults caps the hits a global search returns across all types
+	MaxSearchResults = 50
+)
+
+// SearchTypes are the entity types a global search covers, in the order their hits rank on equal scores
+var SearchTypes = []string{"user", "resource", "policy"}
+
+type ISearchService interface {
+	Search(ctx context.Context, term string, types []string, limit int) (*model.SearchResults, error)
+}
+
+// SearchService searches users, resources and policies by name at once, for a single search box
+type SearchService struct {
+	userDAO     *dao.UserDAO
+	resourceDAO *dao.ResourceDAO
+	policyDAO   *dao.PolicyDAO
+}
+
+var _ ISearchService = &SearchService{}
+
+// NewSearchService creates a new instance of SearchService
+func NewSearchService(userDAO *dao.UserDAO, resourceDAO *dao.ResourceDAO, policyDAO *dao.PolicyDAO) *SearchService {
+	return &SearchService{
+		userDAO:     userDAO,
+		resourceDAO: resourceDAO,
+		policyDAO:   policyDAO,
+	}
+}
+
+// Search looks for term in the names of the given types, or of all SearchTypes when none are given,
+// taking up to limit hits from each. The types are searched concurrently; one whose search fails is
+// reported in Warnings rather than failing the whole search, unless every one of them fails.
+func (s *SearchService) Search(ctx context.Context, term string, types []string, limit int) (*model.SearchResults, error) {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return nil, fmt.Errorf("%w: a search term is required", echo_errors.ErrInvalidSearchCriteria)
+	}
+	if limit < 1 {
+		limit = DefaultSearchTypeLimit
+	}
+	limit, _, err := helper_util.ClampPagination(limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	types, err = searchTypes(types)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([][]model.SearchHit, len(types))
+	var mu sync.Mutex
+	var warnings []string
+	var g errgroup.Group
+	for i, entityType := range types {
+		i, entityType := i, entityType
+		g.Go(func() error {
+			found, err := s.searchType(ctx, entityType, term, limit)
+			if err != nil {
+				logger.Warn("Global search failed for a type", zap.Error(err), zap.String("type", entityType), zap.String("term", term))
+				mu.Lock()
+				warnings = append(warnings, fmt.Sprintf("%s search failed", entityType))
+				mu.Unlock()
+				return nil
+			}
+			hits[i] = found
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if len(warnings) == len(types) {
+		return nil, fmt.Errorf("failed to search %s", strings.Join(types, ", "))
+	}
+	sort.Strings(warnings)
+
+	results := &model.SearchResults{Items: []model.SearchHit{}, Warnings: warnings}
+	for _, found := range hits {
+		results.Items = append(results.Items, found...)
+	}
+	sort.SliceStable(results.Items, func(i, j int) bool {
+		return results.Items[i].Score > results.Items[j].Score
+	})
+	if len(results.Items) > MaxSearchResults {
+		results.Items = results.Items[:MaxSearchResults]
+	}
+
+	logger.Info("Global search completed",
+		zap.String("term", term),
+		zap.Strings("types", types),
+		zap.Int("hits", len(results.Items)),
+		zap.Int("failedTypes", len(warnings)))
+	return results, nil
+}
+
+// searchType searches one entity type by name. Resources and policies of other organizations are left
+// out, as fetching them one at a time would be refused.
+func (s *SearchService) searchType(ctx context.Context, entityType, term string, limit int) ([]model.SearchHit, error) {
+	var hits []model.SearchHit
+	switch entityType {
+	case "user":
+		users, err := s.userDAO.SearchUsers(ctx, model.UserSearchCriteria{Name: term, Limit: limit})
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			hits = append(hits, searchHit(entityType, user.ID, user.Name, term, user))
+		}
+	case "resource":
+		resources, err := s.resourceDAO.SearchResources(ctx, model.ResourceSearchCriteria{Name: term, Limit: limit})
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources {
+			if checkTenantAccess(ctx, TenantEntityResource, resource.OrganizationID) == nil {
+				hits = append(hits, searchHit(entityType, resource.ID, resource.Name, term, resource))
+			}
+		}
+	case "policy":
+		policies, err := s.policyDAO.SearchPolicies(ctx, model.PolicySearchCriteria{Name: term, Limit: limit})
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			if checkTenantAccess(ctx, TenantEntityPolicy, policy.OrganizationID) == nil {
+				hits = append(hits, searchHit(entityType, policy.ID, policy.Name, term, policy))
+			}
+		}
+	}
+	return hits, nil
+}
+
+// searchTypes checks the requested types against SearchTypes, dropping repeats, and defaults to all of them
+func searchTypes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return SearchTypes, nil
+	}
+	var types []string
+	for _, entityType := range distinctIDs(requested) {
+		known := false
+		for _, searchType := range SearchTypes {
+			known = known || searchType == entityType
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown search type %q", echo_errors.ErrInvalidSearchCriteria, entityType)
+		}
+		types = append(types, entityType)
+	}
+	return types, nil
+}
+
+func searchHit(entityType, id, name, term string, entity interface{}) model.SearchHit {
+	return model.SearchHit{Type: entityType, ID: id, Name: name, Score: searchScore(name, term), Entity: entity}
+}
+
+// searchScore rates how closely name matches term, ignoring case: an exact match scores highest, then
+// a name starting with term, then one containing it elsewhere
+func searchScore(name, term string) int {
+	name, term = strings.ToLower(name), strings.ToLower(term)
+	switch {
+	case name == term:
+		return 3
+	case strings.HasPrefix(name, term):
+		return 2
+	case strings.Contains(name, term):
+		return 1
+	default:
+		return 0
+	}
+}
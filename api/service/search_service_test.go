@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+)
+
+func TestSearchService(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	userNode := neo4j.Node{Props: map[string]any{
+		"id":             "u2",
+		"name":           "Report Bot",
+		"username":       "reportbot",
+		"email":          "reportbot@example.com",
+		"userType":       "DepartmentUser",
+		"organizationID": "org1",
+		"departmentID":   "dept1",
+		"attributes":     "{}",
+		"createdAt":      "2024-01-01T00:00:00Z",
+		"updatedAt":      "2024-01-01T00:00:00Z",
+	}}
+	resourceNode := mock.ResourceNode("r1", nil)
+
+	// Each DAO gets its own driver: users and resources find one node each, and policy searches fail
+	driverFinding := func(node neo4j.Node) *mock.MockDriver {
+		result := &mock.MockResult{}
+		result.On("Next").Return(true).Once()
+		result.On("Next").Return(false)
+		result.On("Record").Return(&neo4j.Record{Values: []any{node}})
+		session := &mock.MockSession{}
+		session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+		session.On("Close").Return(nil)
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return driver
+	}
+	newService := func() *service.SearchService {
+		policySession := &mock.MockSession{}
+		policySession.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(&mock.MockResult{}, errors.New("policy index unavailable"))
+		policySession.On("Close").Return(nil)
+		policyDriver := &mock.MockDriver{}
+		policyDriver.On("NewSession", testify_mock.Anything).Return(policySession)
+		return service.NewSearchService(&dao.UserDAO{Driver: driverFinding(userNode)}, &dao.ResourceDAO{Driver: driverFinding(resourceNode)}, &dao.PolicyDAO{Driver: policyDriver})
+	}
+
+	t.Run("FindsUserAndResource", func(t *testing.T) {
+		searchService := newService()
+
+		results, err := searchService.Search(requestContext("org1"), "Report", []string{"user", "resource"}, 0)
+
+		assert.NoError(t, err)
+		assert.Empty(t, results.Warnings)
+		if assert.Len(t, results.Items, 2) {
+			// "Report Bot" starts with the term, so it ranks above "Quarterly Report"
+			assert.Equal(t, "user", results.Items[0].Type)
+			assert.Equal(t, "u2", results.Items[0].ID)
+			assert.Equal(t, "resource", results.Items[1].Type)
+			assert.Equal(t, "r1", results.Items[1].ID)
+			assert.Greater(t, results.Items[0].Score, results.Items[1].Score)
+		}
+	})
+
+	t.Run("FailedTypeBecomesWarning", func(t *testing.T) {
+		searchService := newService()
+
+		results, err := searchService.Search(requestContext("org1"), "Report", nil, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"policy search failed"}, results.Warnings)
+		assert.Len(t, results.Items, 2)
+	})
+
+	t.Run("RejectsUnknownType", func(t *testing.T) {
+		_, err := newService().Search(requestContext("org1"), "Report", []string{"planet"}, 0)
+
+		assert.ErrorIs(t, err, echo_errors.ErrInvalidSearchCriteria)
+	})
+
+	t.Run("RequiresTerm", func(t *testing.T) {
+		_, err := newService().Search(requestContext("org1"), "  ", nil, 0)
+
+		assert.ErrorIs(t, err, echo_errors.ErrInvalidSearchCriteria)
+	})
+}
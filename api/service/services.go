@@ -19,6 +19,13 @@ type Services struct {
 	Resource              IResourceService
 	ResourceTypeService   IResourceTypeService
 	AttributeGroupService IAttributeGroupService
+	Access                IAccessService
+	SoD                   ISoDService
+	ChangeFeed            IChangeFeedService
+	Search                ISearchService
+	Audit                 audit.Service
+	Cache                 *util.CacheService
+	Jobs                  *util.JobRunner
 }
 
 func InitializeServices(
@@ -28,6 +35,7 @@ func InitializeServices(
 	cacheService *util.CacheService,
 	notificationSvc *util.NotificationService,
 	eventBus *util.EventBus,
+	jobRunner *util.JobRunner,
 ) (*Services, error) {
 	policyDAO := dao.NewPolicyDAO(driver, auditService)
 	userDAO := dao.NewUserDAO(driver, auditService)
@@ -39,18 +47,28 @@ func InitializeServices(
 	resourceDAO := dao.NewResourceDAO(driver, auditService)
 	resourceTypeDAO := dao.NewResourceTypeDAO(driver, auditService)
 	attributeGroupDAO := dao.NewAttributeGroupDAO(driver, auditService)
+	sodRuleDAO := dao.NewSoDRuleDAO(driver, auditService)
+	changeEventDAO := dao.NewChangeEventDAO(driver)
+	tenantOrgDAO = organizationDAO
 
 	services := &Services{
-		Policy:                NewPolicyService(policyDAO, validationUtil, cacheService, notificationSvc, eventBus),
-		User:                  NewUserService(userDAO, validationUtil, cacheService, notificationSvc, eventBus),
+		Policy:                NewPolicyService(policyDAO, userDAO, resourceDAO, validationUtil, cacheService, notificationSvc, eventBus, jobRunner),
+		User:                  NewUserService(userDAO, attributeGroupDAO, sodRuleDAO, validationUtil, cacheService, notificationSvc, eventBus),
 		Org:                   NewOrganizationService(organizationDAO, validationUtil, cacheService, notificationSvc, eventBus),
 		Dept:                  NewDepartmentService(departmentDAO, validationUtil, cacheService, notificationSvc, eventBus),
-		Role:                  NewRoleService(roleDAO, validationUtil, cacheService, notificationSvc, eventBus),
+		Role:                  NewRoleService(roleDAO, userDAO, sodRuleDAO, validationUtil, cacheService, notificationSvc, eventBus),
 		Group:                 NewGroupService(groupDAO, validationUtil, cacheService, notificationSvc, eventBus),
 		Permission:            NewPermissionService(permissionDAO, validationUtil, cacheService, notificationSvc, eventBus),
-		Resource:              NewResourceService(resourceDAO, validationUtil, cacheService, notificationSvc, eventBus),
+		Resource:              NewResourceService(resourceDAO, resourceTypeDAO, attributeGroupDAO, userDAO, validationUtil, cacheService, notificationSvc, eventBus),
 		ResourceTypeService:   NewResourceTypeService(resourceTypeDAO, validationUtil, cacheService, notificationSvc, eventBus),
 		AttributeGroupService: NewAttributeGroupService(attributeGroupDAO, validationUtil, cacheService, notificationSvc, eventBus),
+		Access:                NewAccessService(userDAO, resourceDAO, policyDAO, cacheService, eventBus, jobRunner),
+		SoD:                   NewSoDService(sodRuleDAO, userDAO, validationUtil),
+		ChangeFeed:            NewChangeFeedService(changeEventDAO, eventBus),
+		Search:                NewSearchService(userDAO, resourceDAO, policyDAO),
+		Audit:                 auditService,
+		Cache:                 cacheService,
+		Jobs:                  jobRunner,
 	}
 
 	return services, nil
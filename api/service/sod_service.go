@@ -0,0 +1,240 @@
+// api/service/sod_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+// How role assignments that break a separation of duties rule are handled
+const (
+	SoDEnforcementOff    = "off"    // Assignments are not checked; violations only show up in reports
+	SoDEnforcementWarn   = "warn"   // Assignments go through and the violation is logged
+	SoDEnforcementReject = "reject" // Assignments fail with ErrSoDViolation
+)
+
+var sodEnforcement = SoDEnforcementOff
+
+// SetSoDEnforcement sets how role assignments that break a separation of duties rule are handled.
+// Unknown modes keep the current one.
+func SetSoDEnforcement(mode string) {
+	switch mode = strings.ToLower(mode); mode {
+	case SoDEnforcementOff, SoDEnforcementWarn, SoDEnforcementReject:
+		sodEnforcement = mode
+	default:
+		logger.Warn("Unknown SoD enforcement mode ignored", zap.String("mode", mode), zap.String("current", sodEnforcement))
+	}
+}
+
+// ISoDService defines the interface for separation of duties rules and their violations
+type ISoDService interface {
+	CreateSoDRule(ctx context.Context, rule model.SoDRule, creatorID string) (*model.SoDRule, error)
+	UpdateSoDRule(ctx context.Context, rule model.SoDRule, updaterID string) (*model.SoDRule, error)
+	DeleteSoDRule(ctx context.Context, ruleID string, deleterID string) error
+	GetSoDRule(ctx context.Context, ruleID string) (*model.SoDRule, error)
+	ListSoDRules(ctx context.Context) ([]*model.SoDRule, error)
+	CheckSoDViolations(ctx context.Context, userID string) ([]model.SoDViolation, error)
+	ScanSoDViolations(ctx context.Context, orgID string) ([]model.SoDViolation, error)
+}
+
+// SoDService manages separation of duties rules and checks users against them
+type SoDService struct {
+	sodRuleDAO     *dao.SoDRuleDAO
+	userDAO        *dao.UserDAO
+	validationUtil *util.ValidationUtil
+}
+
+var _ ISoDService = &SoDService{}
+
+// NewSoDService creates a new instance of SoDService
+func NewSoDService(sodRuleDAO *dao.SoDRuleDAO, userDAO *dao.UserDAO, validationUtil *util.ValidationUtil) *SoDService {
+	return &SoDService{
+		sodRuleDAO:     sodRuleDAO,
+		userDAO:        userDAO,
+		validationUtil: validationUtil,
+	}
+}
+
+// CreateSoDRule handles the creation of a new SoD rule
+func (s *SoDService) CreateSoDRule(ctx context.Context, rule model.SoDRule, creatorID string) (*model.SoDRule, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.validationUtil.ValidateSoDRule(rule); err != nil {
+		return nil, fmt.Errorf("invalid SoD rule: %w", err)
+	}
+
+	ruleID, err := s.sodRuleDAO.CreateSoDRule(ctx, rule)
+	if err != nil {
+		logger.Error("Error creating SoD rule", zap.Error(err), zap.String("creatorID", creatorID))
+		return nil, err
+	}
+
+	logger.Info("SoD rule created successfully", zap.String("ruleID", ruleID), zap.String("creatorID", creatorID))
+	return s.sodRuleDAO.GetSoDRule(ctx, ruleID)
+}
+
+// UpdateSoDRule handles updates to an existing SoD rule
+func (s *SoDService) UpdateSoDRule(ctx context.Context, rule model.SoDRule, updaterID string) (*model.SoDRule, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.validationUtil.ValidateSoDRule(rule); err != nil {
+		return nil, fmt.Errorf("invalid SoD rule: %w", err)
+	}
+
+	updatedRule, err := s.sodRuleDAO.UpdateSoDRule(ctx, rule)
+	if err != nil {
+		logger.Error("Error updating SoD rule", zap.Error(err), zap.String("ruleID", rule.ID), zap.String("updaterID", updaterID))
+		return nil, fmt.Errorf("failed to update SoD rule: %w", err)
+	}
+
+	logger.Info("SoD rule updated successfully", zap.String("ruleID", rule.ID), zap.String("updaterID", updaterID))
+	return updatedRule, nil
+}
+
+// DeleteSoDRule handles the deletion of a SoD rule
+func (s *SoDService) DeleteSoDRule(ctx context.Context, ruleID string, deleterID string) error {
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
+	if err := s.sodRuleDAO.DeleteSoDRule(ctx, ruleID); err != nil {
+		logger.Error("Error deleting SoD rule", zap.Error(err), zap.String("ruleID", ruleID), zap.String("deleterID", deleterID))
+		return fmt.Errorf("failed to delete SoD rule: %w", err)
+	}
+
+	logger.Info("SoD rule deleted successfully", zap.String("ruleID", ruleID), zap.String("deleterID", deleterID))
+	return nil
+}
+
+// GetSoDRule retrieves a SoD rule by its ID
+func (s *SoDService) GetSoDRule(ctx context.Context, ruleID string) (*model.SoDRule, error) {
+	return s.sodRuleDAO.GetSoDRule(ctx, ruleID)
+}
+
+// ListSoDRules retrieves every SoD rule
+func (s *SoDService) ListSoDRules(ctx context.Context) ([]*model.SoDRule, error) {
+	rules, err := s.sodRuleDAO.ListSoDRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SoD rules: %w", err)
+	}
+	return rules, nil
+}
+
+// CheckSoDViolations reports every rule the user breaks with the roles they hold, directly or through
+// nested groups
+func (s *SoDService) CheckSoDViolations(ctx context.Context, userID string) ([]model.SoDViolation, error) {
+	rules, err := s.ListSoDRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	effective, err := s.userDAO.GetEffectivePermissions(ctx, userID)
+	if err != nil {
+		logger.Error("Error resolving user roles for SoD check", zap.Error(err), zap.String("userID", userID))
+		return nil, err
+	}
+
+	return findSoDViolations(userID, effective.RoleIDs, rules), nil
+}
+
+// ScanSoDViolations checks every user of the organization, reporting all violations found
+func (s *SoDService) ScanSoDViolations(ctx context.Context, orgID string) ([]model.SoDViolation, error) {
+	rules, err := s.ListSoDRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []model.SoDViolation{}
+	if len(rules) == 0 {
+		return violations, nil
+	}
+
+	limit := helper_util.MaxPageLimit()
+	for offset := 0; ; offset += limit {
+		users, _, err := s.userDAO.GetUsersByOrganization(ctx, orgID, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization users: %w", err)
+		}
+		for _, user := range users {
+			effective, err := s.userDAO.GetEffectivePermissions(ctx, user.ID)
+			if err != nil {
+				logger.Error("Error resolving user roles for SoD scan", zap.Error(err), zap.String("userID", user.ID))
+				return nil, err
+			}
+			violations = append(violations, findSoDViolations(user.ID, effective.RoleIDs, rules)...)
+		}
+		if len(users) < limit {
+			break
+		}
+	}
+
+	logger.Info("SoD scan completed", zap.String("orgID", orgID), zap.Int("violations", len(violations)))
+	return violations, nil
+}
+
+// enforceSoD checks the roles assigned to a user against the SoD rules as the enforcement mode
+// demands. Only the assigned roles are checked; roles the user inherits through groups show up in
+// CheckSoDViolations.
+func enforceSoD(ctx context.Context, sodRuleDAO *dao.SoDRuleDAO, user model.User) error {
+	if sodEnforcement == SoDEnforcementOff || len(user.RoleIds) < 2 {
+		return nil
+	}
+
+	rules, err := sodRuleDAO.ListSoDRules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list SoD rules: %w", err)
+	}
+
+	violations := findSoDViolations(user.ID, user.RoleIds, rules)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	pairs := make([]string, len(violations))
+	for i, violation := range violations {
+		pairs[i] = violation.RoleA + " and " + violation.RoleB
+	}
+	if sodEnforcement == SoDEnforcementWarn {
+		logger.Warn("Role assignment violates separation of duties",
+			zap.String("userID", user.ID),
+			zap.Strings("conflicts", pairs))
+		return nil
+	}
+	return fmt.Errorf("%w: %s", echo_errors.ErrSoDViolation, strings.Join(pairs, ", "))
+}
+
+// findSoDViolations returns the rules broken by holding all of roleIDs
+func findSoDViolations(userID string, roleIDs []string, rules []*model.SoDRule) []model.SoDViolation {
+	held := make(map[string]bool, len(roleIDs))
+	for _, roleID := range roleIDs {
+		held[roleID] = true
+	}
+
+	violations := []model.SoDViolation{}
+	for _, rule := range rules {
+		if held[rule.RoleA] && held[rule.RoleB] {
+			violations = append(violations, model.SoDViolation{
+				UserID:      userID,
+				RuleID:      rule.ID,
+				RoleA:       rule.RoleA,
+				RoleB:       rule.RoleB,
+				Description: rule.Description,
+			})
+		}
+	}
+	return violations
+}
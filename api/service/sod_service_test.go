@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// sodSession serves one rule forbidding "requester" together with "approver", and the roles of u1,
+// who holds both, and u2, who only requests. Any other read finds nothing.
+func sodSession() *mock.MockSession {
+	session := &mock.MockSession{}
+	session.On("Close").Return(nil)
+
+	// Each run gets the records afresh, so every check sees the same data
+	serve := func(query interface{}, params interface{}, records ...*neo4j.Record) {
+		result := &mock.MockResult{}
+		session.On("Run", query, params, testify_mock.Anything).
+			Run(func(testify_mock.Arguments) {
+				result.ExpectedCalls = nil
+				for _, record := range records {
+					result.On("Next").Return(true).Once()
+					result.On("Record").Return(record).Once()
+				}
+				result.On("Next").Return(false)
+			}).Return(result, nil)
+	}
+	queryContaining := func(fragment string) interface{} {
+		return testify_mock.MatchedBy(func(query string) bool { return strings.Contains(query, fragment) })
+	}
+	roles := func(roleIDs ...interface{}) *neo4j.Record {
+		return &neo4j.Record{Values: []any{[]interface{}{}, roleIDs, []interface{}{}}}
+	}
+
+	serve(queryContaining("MATCH (s:"+echo_neo4j.LabelSoDRule+")"), testify_mock.Anything,
+		&neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+			"id":          "sod1",
+			"roleA":       "requester",
+			"roleB":       "approver",
+			"description": "Nobody approves their own requests",
+			"createdAt":   "2024-01-01T00:00:00Z",
+			"updatedAt":   "2024-01-01T00:00:00Z",
+		}}}})
+	serve(queryContaining("AS permissionIDs"), map[string]interface{}{"id": "u1"}, roles("requester", "auditor", "approver"))
+	serve(queryContaining("AS permissionIDs"), map[string]interface{}{"id": "u2"}, roles("requester"))
+	serve(testify_mock.Anything, testify_mock.Anything)
+	return session
+}
+
+func TestSoDServiceCheckSoDViolations(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(sodSession())
+	auditService := &mock.MockAuditService{}
+	sodService := service.NewSoDService(
+		&dao.SoDRuleDAO{Driver: driver, AuditService: auditService},
+		&dao.UserDAO{Driver: driver, AuditService: auditService},
+		util.NewValidationUtil(),
+	)
+
+	t.Run("Conflicting roles", func(t *testing.T) {
+		violations, err := sodService.CheckSoDViolations(ctx, "u1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.SoDViolation{{
+			UserID:      "u1",
+			RuleID:      "sod1",
+			RoleA:       "requester",
+			RoleB:       "approver",
+			Description: "Nobody approves their own requests",
+		}}, violations)
+	})
+
+	t.Run("Clean user", func(t *testing.T) {
+		violations, err := sodService.CheckSoDViolations(ctx, "u2")
+
+		assert.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+}
+
+func TestSoDEnforcementOnRoleAssignment(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer service.SetSoDEnforcement(service.SoDEnforcementOff)
+
+	ctx := context.Background()
+	session := sodSession()
+	session.On("WriteTransaction", testify_mock.Anything, testify_mock.Anything).Return(nil, errors.New("write reached"))
+	driver := &mock.MockDriver{}
+	driver.On("NewSession", testify_mock.Anything).Return(session)
+	auditService := &mock.MockAuditService{}
+	userService := service.NewUserService(
+		&dao.UserDAO{Driver: driver, AuditService: auditService},
+		&dao.AttributeGroupDAO{Driver: driver, AuditService: auditService},
+		&dao.SoDRuleDAO{Driver: driver, AuditService: auditService},
+		util.NewValidationUtil(),
+		nil,
+		nil,
+		util.NewEventBus(),
+	)
+	user := model.User{
+		ID:       "u3",
+		Name:     "Jane Doe",
+		Username: "jdoe",
+		Email:    "jdoe@example.com",
+		UserType: "DepartmentUser",
+		RoleIds:  []string{"approver", "requester"},
+	}
+
+	service.SetSoDEnforcement(service.SoDEnforcementReject)
+	_, err := userService.CreateUser(ctx, user, "admin")
+	assert.ErrorIs(t, err, echo_errors.ErrSoDViolation)
+	session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+
+	service.SetSoDEnforcement(service.SoDEnforcementWarn)
+	_, err = userService.CreateUser(ctx, user, "admin")
+	assert.NotErrorIs(t, err, echo_errors.ErrSoDViolation)
+	session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+}
+
+func TestSoDEnforcementOnBulkRoleAssignment(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer service.SetSoDEnforcement(service.SoDEnforcementOff)
+
+	// u2 only requests, and is about to be made an approver
+	requester := userRecord("u2", model.UserStatusActive)
+	requester.Values[1] = []interface{}{"requester"}
+	driver := mock.NewFakeDriver().
+		Returns("MATCH (s:"+echo_neo4j.LabelSoDRule+")", &neo4j.Record{Values: []any{neo4j.Node{Props: map[string]any{
+			"id":          "sod1",
+			"roleA":       "requester",
+			"roleB":       "approver",
+			"description": "Nobody approves their own requests",
+			"createdAt":   "2024-01-01T00:00:00Z",
+			"updatedAt":   "2024-01-01T00:00:00Z",
+		}}}}).
+		Returns("RETURN u, roleIds", requester)
+	auditService := &mock.MockAuditService{}
+	roleService := service.NewRoleService(
+		&dao.RoleDAO{Driver: driver, AuditService: auditService},
+		&dao.UserDAO{Driver: driver, AuditService: auditService},
+		&dao.SoDRuleDAO{Driver: driver, AuditService: auditService},
+		util.NewValidationUtil(),
+		nil,
+		nil,
+		util.NewEventBus(),
+	)
+
+	service.SetSoDEnforcement(service.SoDEnforcementReject)
+	_, err := roleService.AssignRoleToUsers(context.Background(), "approver", []string{"u2"}, "admin")
+
+	assert.ErrorIs(t, err, echo_errors.ErrSoDViolation)
+	assert.Empty(t, driver.QueriesContaining("MERGE (u)-[:"+echo_neo4j.RelHasRole+"]->(r)"))
+}
@@ -0,0 +1,175 @@
+// api/service/temporal_conditions.go
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	// Embedded so policy timezones load on hosts without a zoneinfo database
+	_ "time/tzdata"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+)
+
+// Attributes policy conditions use to limit access to certain hours and days. They are read from the
+// server clock in the policy timezone, e.g. timeOfDay between "09:00-17:00" and dayOfWeek in ["Mon..Fri"].
+const (
+	AttrTimeOfDay = "timeOfDay" // Wall-clock time as "15:04"
+	AttrDayOfWeek = "dayOfWeek" // "Mon" through "Sun"
+)
+
+var policyLocation = time.UTC
+
+// SetPolicyTimezone sets the IANA timezone, such as "Europe/Berlin", whose wall clock temporal
+// conditions are evaluated against. An empty name keeps the current timezone.
+func SetPolicyTimezone(name string) error {
+	if name == "" {
+		return nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid policy timezone %q: %w", name, err)
+	}
+	policyLocation = location
+	return nil
+}
+
+// addTemporalAttributes adds the time of day and day of week of now. Converting to the policy
+// timezone applies its daylight saving rules, so a window keeps following the local wall clock.
+func addTemporalAttributes(attributes map[string]interface{}, now time.Time) {
+	local := now.In(policyLocation)
+	attributes[AttrTimeOfDay] = local.Format("15:04")
+	attributes[AttrDayOfWeek] = local.Format("Mon")
+}
+
+// attributeMatches compares an attribute against a condition. Day of week conditions go through
+// dayOfWeekIn so their lists may hold ranges; everything else through compareAttribute.
+func attributeMatches(condition model.Condition, actual interface{}) bool {
+	if condition.Attribute == AttrDayOfWeek {
+		switch strings.ToLower(condition.Operator) {
+		case "in", "equals", "eq", "==":
+			return dayOfWeekIn(actual, condition.Value)
+		case "not_in", "not_equals", "ne", "!=":
+			return !dayOfWeekIn(actual, condition.Value)
+		}
+	}
+	return compareAttribute(condition.Operator, actual, condition.Value)
+}
+
+// timeOfDayBetween reports whether actual, a "15:04" time, lies in the window expected describes,
+// either "09:00-17:00" or ["09:00", "17:00"]. The start is inclusive and the end exclusive; a window
+// whose end is before its start spans midnight.
+func timeOfDayBetween(actual, expected interface{}) bool {
+	value, ok := actual.(string)
+	if !ok {
+		return false
+	}
+
+	bounds := timeWindowBounds(expected)
+	if len(bounds) != 2 {
+		logger.Warn("Malformed time window in policy condition", zap.Any("value", expected))
+		return false
+	}
+
+	at, err := minuteOfDay(value)
+	if err != nil {
+		return false
+	}
+	from, fromErr := minuteOfDay(bounds[0])
+	to, toErr := minuteOfDay(bounds[1])
+	if fromErr != nil || toErr != nil {
+		logger.Warn("Malformed time window in policy condition", zap.Any("value", expected))
+		return false
+	}
+
+	if from <= to {
+		return at >= from && at < to
+	}
+	return at >= from || at < to
+}
+
+// timeWindowBounds splits a time window, "09:00-17:00" or ["09:00", "17:00"], into its bounds
+func timeWindowBounds(window interface{}) []string {
+	var bounds []string
+	switch w := window.(type) {
+	case string:
+		bounds = strings.Split(w, "-")
+	case []interface{}:
+		for _, bound := range w {
+			s, _ := bound.(string)
+			bounds = append(bounds, s)
+		}
+	case []string:
+		bounds = w
+	}
+	return bounds
+}
+
+func minuteOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// dayOfWeekIn reports whether actual, a day such as "Mon", is among the days expected lists. Days
+// are matched case-insensitively by their first three letters, and "Mon..Fri" stands for every day
+// from Monday to Friday; a range ending before it starts wraps around the weekend.
+func dayOfWeekIn(actual, expected interface{}) bool {
+	value, ok := actual.(string)
+	if !ok {
+		return false
+	}
+	day := weekdayIndex(value)
+	if day < 0 {
+		return false
+	}
+
+	var entries []string
+	switch days := expected.(type) {
+	case string:
+		entries = []string{days}
+	case []interface{}:
+		for _, entry := range days {
+			s, _ := entry.(string)
+			entries = append(entries, s)
+		}
+	case []string:
+		entries = days
+	}
+
+	for _, entry := range entries {
+		from, to := entry, entry
+		if bounds := strings.SplitN(entry, "..", 2); len(bounds) == 2 {
+			from, to = bounds[0], bounds[1]
+		}
+		start, end := weekdayIndex(from), weekdayIndex(to)
+		if start < 0 || end < 0 {
+			logger.Warn("Malformed day of week in policy condition", zap.String("value", entry))
+			continue
+		}
+		if (start <= end && day >= start && day <= end) || (start > end && (day >= start || day <= end)) {
+			return true
+		}
+	}
+	return false
+}
+
+func weekdayIndex(day string) int {
+	day = strings.ToLower(strings.TrimSpace(day))
+	if len(day) < 3 {
+		return -1
+	}
+	for i, weekday := range weekdays {
+		if day[:3] == weekday {
+			return i
+		}
+	}
+	return -1
+}
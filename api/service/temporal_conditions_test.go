@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+)
+
+func TestTemporalConditions(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer service.SetPolicyTimezone("UTC")
+
+	businessHours := &model.Policy{
+		ID:       "p1",
+		Name:     "Business hours",
+		Effect:   echo_neo4j.PolicyEffectAllow,
+		Active:   true,
+		Subjects: []model.Subject{{Type: "user", UserID: "u1"}},
+		Actions:  []string{"read"},
+		Conditions: []model.Condition{
+			{Attribute: service.AttrTimeOfDay, Operator: "between", Value: "09:00-17:00"},
+			{Attribute: service.AttrDayOfWeek, Operator: "in", Value: []interface{}{"Mon..Fri"}},
+		},
+	}
+	subject := &model.User{ID: "u1", Status: model.UserStatusActive}
+	resource := &model.Resource{ID: "res1", Type: "DOCUMENT"}
+	request := model.AccessRequest{SubjectID: "u1", ResourceID: "res1", Action: "read"}
+
+	tests := []struct {
+		name     string
+		timezone string
+		now      time.Time
+		allowed  bool
+	}{
+		// 00:30 UTC is outside the window, but it is already 09:30 on Monday in Tokyo
+		{"In window ahead of UTC", "Asia/Tokyo", time.Date(2024, 7, 1, 0, 30, 0, 0, time.UTC), true},
+		// 10:00 UTC is inside the window, but it is 19:00 in Tokyo
+		{"Out of window ahead of UTC", "Asia/Tokyo", time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC), false},
+		// Friday 23:30 UTC is Saturday 08:30 in Tokyo, outside both hours and days
+		{"Weekend across the date line", "Asia/Tokyo", time.Date(2024, 7, 5, 23, 30, 0, 0, time.UTC), false},
+		// New York moves to daylight saving time on 10 March 2024, so 13:30 UTC is 08:30 before and 09:30 after
+		{"Before daylight saving time", "America/New_York", time.Date(2024, 3, 8, 13, 30, 0, 0, time.UTC), false},
+		{"After daylight saving time", "America/New_York", time.Date(2024, 3, 11, 13, 30, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, service.SetPolicyTimezone(tt.timezone))
+
+			decision := service.EvaluatePolicies([]*model.Policy{businessHours}, subject, resource, request, tt.now)
+
+			assert.Equal(t, tt.allowed, decision.Allowed)
+		})
+	}
+
+	t.Run("Unknown timezone", func(t *testing.T) {
+		assert.Error(t, service.SetPolicyTimezone("Mars/Olympus_Mons"))
+	})
+}
@@ -0,0 +1,351 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	testify_mock "github.com/stretchr/testify/mock"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func requestContext(orgID string, roles ...string) context.Context {
+	ctx := context.WithValue(context.Background(), "requestingUserID", "u1")
+	ctx = context.WithValue(ctx, "requestingOrganizationID", orgID)
+	return context.WithValue(ctx, "requestingRoles", roles)
+}
+
+// tenantSession serves node to reads and fails every write, so a guarded operation either stops with
+// ErrForbidden or reaches the database write
+func tenantSession(node neo4j.Node) *mock.MockSession {
+	result := &mock.MockResult{}
+	result.On("Next").Return(true).Once()
+	result.On("Next").Return(false)
+	result.On("Record").Return(&neo4j.Record{Keys: []string{"r", "parentID", "relatedIDs"}, Values: []any{node, nil, []any{}}})
+
+	session := &mock.MockSession{}
+	session.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(result, nil)
+	session.On("WriteTransaction", testify_mock.Anything, testify_mock.Anything).Return(nil, errors.New("write failed"))
+	session.On("Close").Return(nil)
+	return session
+}
+
+func TestTenantIsolationResources(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer service.SetTenantIsolation(nil, service.DefaultGlobalAdminRole)
+
+	newService := func() (*service.ResourceService, *mock.MockSession) {
+		session := tenantSession(mock.ResourceNode("r1", nil))
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return mock.NewResourceService(driver, nil), session
+	}
+
+	t.Run("CrossOrgDeleteIsForbidden", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityResource}, "")
+		resourceService, session := newService()
+
+		err := resourceService.DeleteResource(requestContext("org2"), "r1", "u1")
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("CrossOrgTransferIsForbidden", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityResource}, "")
+		resourceService, session := newService()
+
+		err := resourceService.TransferResourceOwnership(requestContext("org2"), "r1", "u2", "u1")
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("CrossOrgListingIsForbidden", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityResource}, "")
+		resourceService, session := newService()
+
+		resources, _, err := resourceService.GetResourcesByOrganization(requestContext("org2"), "org1", false, 10, 0)
+
+		assert.Nil(t, resources)
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("SameOrgDeleteIsAllowed", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityResource}, "")
+		resourceService, session := newService()
+
+		err := resourceService.DeleteResource(requestContext("org1"), "r1", "u1")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("GlobalAdminCrossesOrgs", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityResource}, "platform-admin")
+		resourceService, session := newService()
+
+		err := resourceService.DeleteResource(requestContext("org2", "platform-admin"), "r1", "u1")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("SearchLeavesOtherOrgsOut", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityResource}, "")
+		resourceService, _ := newService()
+
+		resources, err := resourceService.SearchResources(requestContext("org2"), model.ResourceSearchCriteria{Limit: 10})
+
+		assert.NoError(t, err)
+		assert.Empty(t, resources)
+	})
+
+	t.Run("SearchKeepsOwnOrg", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityResource}, "")
+		resourceService, _ := newService()
+
+		resources, err := resourceService.SearchResources(requestContext("org1"), model.ResourceSearchCriteria{Limit: 10})
+
+		assert.NoError(t, err)
+		if assert.Len(t, resources, 1) {
+			assert.Equal(t, "r1", resources[0].ID)
+		}
+	})
+
+	t.Run("GuardIsOptIn", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		resourceService, session := newService()
+
+		err := resourceService.DeleteResource(requestContext("org2"), "r1", "u1")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+}
+
+func TestTenantIsolationPolicies(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer service.SetTenantIsolation(nil, service.DefaultGlobalAdminRole)
+
+	newService := func(orgID string) (*service.PolicyService, *mock.MockSession) {
+		session := tenantSession(neo4j.Node{Props: map[string]any{
+			"id":                "p1",
+			"name":              "Docs",
+			"description":       "Docs policy",
+			"effect":            echo_neo4j.PolicyEffectAllow,
+			"priority":          int64(1),
+			"version":           int64(1),
+			"createdAt":         "2024-01-01T00:00:00Z",
+			"updatedAt":         "2024-01-01T00:00:00Z",
+			"active":            true,
+			"subjects":          "[]",
+			"resourceTypes":     "[]",
+			"attributeGroups":   "[]",
+			"actions":           "[]",
+			"conditions":        "[]",
+			"dynamicAttributes": "[]",
+			"organizationID":    orgID,
+		}})
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		policyService := service.NewPolicyService(
+			&dao.PolicyDAO{Driver: driver, AuditService: &mock.MockAuditService{}},
+			nil,
+			nil,
+			util.NewValidationUtil(),
+			nil,
+			nil,
+			util.NewEventBus(),
+			nil,
+		)
+		return policyService, session
+	}
+
+	t.Run("CrossOrgDeleteIsForbidden", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, session := newService("org1")
+
+		err := policyService.DeletePolicy(requestContext("org2"), "p1", "u1")
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("SameOrgDeleteIsAllowed", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, session := newService("org1")
+
+		err := policyService.DeletePolicy(requestContext("org1"), "p1", "u1")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("SharedPolicyIsOpenToEveryOrg", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, session := newService("")
+
+		err := policyService.DeletePolicy(requestContext("org2"), "p1", "u1")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	policy := func(orgID string) model.Policy {
+		return model.Policy{
+			ID:             "p1",
+			Name:           "Docs",
+			Effect:         "allow",
+			Priority:       1,
+			Subjects:       []model.Subject{{Type: "role", Attributes: map[string]string{"id": "editor"}}},
+			ResourceTypes:  []string{"DOCUMENT"},
+			Actions:        []string{"read"},
+			OrganizationID: orgID,
+		}
+	}
+
+	t.Run("OrgLessCreateIsForbidden", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, session := newService("org1")
+
+		_, err := policyService.CreatePolicy(requestContext("org1"), policy(""), "u1")
+
+		assert.ErrorIs(t, err, echo_errors.ErrForbidden)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("CrossOrgCreateIsForbidden", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, session := newService("org1")
+
+		_, err := policyService.CreatePolicy(requestContext("org1"), policy("org2"), "u1")
+
+		assert.ErrorIs(t, err, echo_errors.ErrForbidden)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("MoveIntoAnotherOrgIsForbidden", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, session := newService("org1")
+
+		_, err := policyService.UpdatePolicy(requestContext("org1"), policy("org2"), "u1")
+
+		assert.ErrorIs(t, err, echo_errors.ErrForbidden)
+		session.AssertNotCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("UpdateWithoutOrgStaysInOwnOrg", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, session := newService("org1")
+
+		_, err := policyService.UpdatePolicy(requestContext("org1"), policy(""), "u1")
+
+		// The policy is read a second time by the update itself, past the tenant guard
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertNumberOfCalls(t, "Run", 2)
+	})
+
+	t.Run("GlobalAdminCreatesSharedPolicy", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, session := newService("org1")
+
+		_, err := policyService.CreatePolicy(requestContext("org1", service.DefaultGlobalAdminRole), policy(""), "u1")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("GlobalAdminCrossesOrgs", func(t *testing.T) {
+		service.SetTenantIsolation([]string{service.TenantEntityPolicy}, "")
+		policyService, session := newService("org1")
+
+		err := policyService.DeletePolicy(requestContext("org2", service.DefaultGlobalAdminRole), "p1", "u1")
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertCalled(t, "WriteTransaction", testify_mock.Anything, testify_mock.Anything)
+	})
+}
+
+func TestTenantSubOrganizations(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	defer service.SetTenantIsolation(nil, service.DefaultGlobalAdminRole)
+	defer service.SetTenantSubOrganizations(false)
+	defer service.SetTenantOrganizationDAO(nil)
+
+	// org1 is a sub-organization of parent
+	orgNode := func(id string) neo4j.Node {
+		return neo4j.Node{Props: map[string]any{"id": id, "name": id, "createdAt": "2024-01-01T00:00:00Z", "updatedAt": "2024-01-01T00:00:00Z"}}
+	}
+	hierarchy := &mock.MockResult{}
+	hierarchy.On("Next").Return(true).Twice()
+	hierarchy.On("Next").Return(false)
+	hierarchy.On("Record").Return(&neo4j.Record{Values: []any{orgNode("parent"), []any{"org1"}}}).Once()
+	hierarchy.On("Record").Return(&neo4j.Record{Values: []any{orgNode("org1"), []any{}}}).Once()
+	orgSession := &mock.MockSession{}
+	orgSession.On("Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything).Return(hierarchy, nil)
+	orgSession.On("Close").Return(nil)
+	orgDriver := &mock.MockDriver{}
+	orgDriver.On("NewSession", testify_mock.Anything).Return(orgSession)
+	service.SetTenantOrganizationDAO(&dao.OrganizationDAO{Driver: orgDriver, AuditService: &mock.MockAuditService{}})
+	service.SetTenantIsolation([]string{service.TenantEntityResource}, "")
+
+	newService := func() (*service.ResourceService, *mock.MockSession) {
+		session := tenantSession(neo4j.Node{Props: map[string]any{}})
+		driver := &mock.MockDriver{}
+		driver.On("NewSession", testify_mock.Anything).Return(session)
+		return mock.NewResourceService(driver, nil), session
+	}
+
+	t.Run("ParentOrgIsForbiddenUnlessShared", func(t *testing.T) {
+		resourceService, session := newService()
+
+		_, _, err := resourceService.GetResourcesByOrganization(requestContext("parent"), "org1", false, 10, 0)
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("SubOrgListingIsForbiddenUnlessShared", func(t *testing.T) {
+		resourceService, session := newService()
+
+		_, _, err := resourceService.GetResourcesByOrganization(requestContext("org1"), "org1", true, 10, 0)
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+		session.AssertNotCalled(t, "Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("ParentOrgReachesSharedSubOrg", func(t *testing.T) {
+		service.SetTenantSubOrganizations(true)
+		resourceService, session := newService()
+
+		_, _, err := resourceService.GetResourcesByOrganization(requestContext("parent"), "org1", true, 10, 0)
+
+		assert.False(t, errors.Is(err, echo_errors.ErrForbidden))
+		session.AssertCalled(t, "Run", testify_mock.Anything, testify_mock.Anything, testify_mock.Anything)
+	})
+
+	t.Run("SubOrgDoesNotReachParent", func(t *testing.T) {
+		service.SetTenantSubOrganizations(true)
+		resourceService, _ := newService()
+
+		_, _, err := resourceService.GetResourcesByOrganization(requestContext("org1"), "parent", false, 10, 0)
+
+		assert.Equal(t, echo_errors.ErrForbidden, err)
+	})
+}
@@ -10,10 +10,12 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/db"
 	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // IUserService defines the interface for user operations
@@ -22,29 +24,53 @@ type IUserService interface {
 	UpdateUser(ctx context.Context, user model.User, updaterID string) (*model.User, error)
 	DeleteUser(ctx context.Context, userID string, deleterID string) error
 	GetUser(ctx context.Context, userID string) (*model.User, error)
+	GetUsersByIDs(ctx context.Context, userIDs []string) (*model.UserBatch, error)
+	UserExists(ctx context.Context, userID string) (bool, error)
 	ListUsers(ctx context.Context, limit int, offset int) ([]*model.User, error)
 	SearchUsers(ctx context.Context, criteria model.UserSearchCriteria) ([]*model.User, error)
+	ActivateUser(ctx context.Context, userID string, actorID string) (*model.User, error)
+	SuspendUser(ctx context.Context, userID string, actorID string) (*model.User, error)
+	DisableUser(ctx context.Context, userID string, actorID string) (*model.User, error)
+	RecordLogin(ctx context.Context, userID string) error
+	GetInactiveUsers(ctx context.Context, days int, limit int, offset int) ([]*model.User, error)
+	GetEffectivePermissions(ctx context.Context, userID string) (*model.EffectivePermissions, error)
+	SetAdminScope(ctx context.Context, scope model.ScopedAdmin, actorID string) (*model.ScopedAdmin, error)
+	GetAdminScope(ctx context.Context, userID string) (*model.ScopedAdmin, error)
+	RemoveAdminScope(ctx context.Context, userID string, actorID string) error
 }
 
 // UserService handles business logic for user operations
 type UserService struct {
-	userDAO         *dao.UserDAO
-	validationUtil  *util.ValidationUtil
-	cacheService    *util.CacheService
-	notificationSvc *util.NotificationService
-	eventBus        *util.EventBus
+	userDAO           *dao.UserDAO
+	attributeGroupDAO *dao.AttributeGroupDAO
+	sodRuleDAO        *dao.SoDRuleDAO
+	validationUtil    *util.ValidationUtil
+	cacheService      *util.CacheService
+	notificationSvc   *util.NotificationService
+	eventBus          *util.EventBus
 }
 
 var _ IUserService = &UserService{}
 
+// loginRecordInterval is how old a user's recorded last login must be before another is recorded
+var loginRecordInterval time.Duration
+
+// SetLoginRecordInterval sets how old a user's recorded last login must be before another is
+// recorded; zero records every authenticated request
+func SetLoginRecordInterval(interval time.Duration) {
+	loginRecordInterval = interval
+}
+
 // NewUserService creates a new instance of UserService
-func NewUserService(userDAO *dao.UserDAO, validationUtil *util.ValidationUtil, cacheService *util.CacheService, notificationSvc *util.NotificationService, eventBus *util.EventBus) *UserService {
+func NewUserService(userDAO *dao.UserDAO, attributeGroupDAO *dao.AttributeGroupDAO, sodRuleDAO *dao.SoDRuleDAO, validationUtil *util.ValidationUtil, cacheService *util.CacheService, notificationSvc *util.NotificationService, eventBus *util.EventBus) *UserService {
 	service := &UserService{
-		userDAO:         userDAO,
-		validationUtil:  validationUtil,
-		cacheService:    cacheService,
-		notificationSvc: notificationSvc,
-		eventBus:        eventBus,
+		userDAO:           userDAO,
+		attributeGroupDAO: attributeGroupDAO,
+		sodRuleDAO:        sodRuleDAO,
+		validationUtil:    validationUtil,
+		cacheService:      cacheService,
+		notificationSvc:   notificationSvc,
+		eventBus:          eventBus,
 	}
 
 	// Set up event subscriptions
@@ -130,25 +156,49 @@ func (s *UserService) handleUserDeleted(ctx context.Context, event util.Event) e
 
 // CreateUser handles the creation of a new user
 func (s *UserService) CreateUser(ctx context.Context, user model.User, creatorID string) (*model.User, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateUser(user); err != nil {
 		return nil, fmt.Errorf("invalid user: %w", err)
 	}
 
+	if err := validateAttributesForGroup(ctx, s.attributeGroupDAO, s.validationUtil, user.AttributeGroupID, userAttributesToMap(user.Attributes)); err != nil {
+		logger.Error("User attributes failed attribute group validation", zap.Error(err), zap.String("attributeGroupID", user.AttributeGroupID))
+		return nil, err
+	}
+
+	if err := enforceSoD(ctx, s.sodRuleDAO, user); err != nil {
+		return nil, err
+	}
+
+	if err := checkAdminScope(ctx, s.userDAO, user.OrganizationID, user.DepartmentID); err != nil {
+		return nil, err
+	}
+
 	// Check if user with the same ID already exists
 	if user.ID != "" {
-		_, err := s.userDAO.GetUser(ctx, user.ID)
-		if err == nil {
-			// Department with this ID already exists
-			return nil, echo_errors.ErrUserConflict
-		}
-		if err != echo_errors.ErrUserNotFound {
-			// An error occurred while checking for existing department
+		exists, err := s.userDAO.Exists(ctx, user.ID)
+		if err != nil {
+			// An error occurred while checking for existing user
 			return nil, echo_errors.ErrDatabaseOperation
 		}
+		if exists {
+			// User with this ID already exists
+			return nil, echo_errors.ErrUserConflict
+		}
+	}
+
+	user.Status = model.NormalizeUserStatus(user.Status)
+	if !isValidUserStatus(user.Status) {
+		return nil, fmt.Errorf("%w: %s", echo_errors.ErrInvalidUserStatus, user.Status)
 	}
 
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
+	user.CreatedBy = creatorID
+	user.UpdatedBy = creatorID
 
 	userID, err := s.userDAO.CreateUser(ctx, user)
 	if err != nil {
@@ -158,6 +208,11 @@ func (s *UserService) CreateUser(ctx context.Context, user model.User, creatorID
 
 	user.ID = userID
 
+	// A dry run was rolled back, so neither the cache nor subscribers may hear of it
+	if helper_util.IsDryRun(ctx) {
+		return &user, nil
+	}
+
 	// Update cache
 	if err := s.cacheService.SetUser(ctx, user); err != nil {
 		logger.Warn("Failed to cache user", zap.Error(err), zap.String("userID", userID))
@@ -172,17 +227,39 @@ func (s *UserService) CreateUser(ctx context.Context, user model.User, creatorID
 
 // UpdateUser handles updates to an existing user
 func (s *UserService) UpdateUser(ctx context.Context, user model.User, updaterID string) (*model.User, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := s.validationUtil.ValidateUser(user); err != nil {
 		return nil, fmt.Errorf("invalid user: %w", err)
 	}
 
+	if err := validateAttributesForGroup(ctx, s.attributeGroupDAO, s.validationUtil, user.AttributeGroupID, userAttributesToMap(user.Attributes)); err != nil {
+		logger.Error("User attributes failed attribute group validation", zap.Error(err), zap.String("attributeGroupID", user.AttributeGroupID))
+		return nil, err
+	}
+
+	if err := enforceSoD(ctx, s.sodRuleDAO, user); err != nil {
+		return nil, err
+	}
+
 	oldUser, err := s.userDAO.GetUser(ctx, user.ID)
 	if err != nil {
 		logger.Error("Error retrieving existing user", zap.Error(err), zap.String("userID", user.ID))
 		return nil, err
 	}
 
+	// A scoped admin may neither edit a user outside their scope nor move one out of it
+	if err := checkAdminScope(ctx, s.userDAO, oldUser.OrganizationID, oldUser.DepartmentID); err != nil {
+		return nil, err
+	}
+	if err := checkAdminScope(ctx, s.userDAO, user.OrganizationID, user.DepartmentID); err != nil {
+		return nil, err
+	}
+
 	user.UpdatedAt = time.Now()
+	user.UpdatedBy = updaterID
 
 	updatedUser, err := s.userDAO.UpdateUser(ctx, user)
 	if err != nil {
@@ -190,6 +267,11 @@ func (s *UserService) UpdateUser(ctx context.Context, user model.User, updaterID
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	// A dry run was rolled back, so neither the cache nor subscribers may hear of it
+	if helper_util.IsDryRun(ctx) {
+		return updatedUser, nil
+	}
+
 	// Update cache
 	if err := s.cacheService.SetUser(ctx, *updatedUser); err != nil {
 		logger.Warn("Failed to update user in cache", zap.Error(err), zap.String("userID", user.ID))
@@ -207,7 +289,27 @@ func (s *UserService) UpdateUser(ctx context.Context, user model.User, updaterID
 
 // DeleteUser handles the deletion of a user
 func (s *UserService) DeleteUser(ctx context.Context, userID string, deleterID string) error {
-	err := s.userDAO.DeleteUser(ctx, userID)
+	if err := util.CheckWritable(ctx); err != nil {
+		return err
+	}
+
+	scope, err := requestingAdminScope(ctx, s.userDAO)
+	if err != nil {
+		return err
+	}
+	if scope != nil {
+		user, err := s.userDAO.GetUser(ctx, userID)
+		if err != nil {
+			logger.Error("Error retrieving user", zap.Error(err), zap.String("userID", userID))
+			return err
+		}
+		if !scopeCovers(scope, user.OrganizationID, user.DepartmentID) {
+			logger.Warn("Out of scope user deletion denied", zap.String("userID", userID), zap.String("deleterID", deleterID))
+			return echo_errors.ErrForbidden
+		}
+	}
+
+	err = s.userDAO.DeleteUser(ctx, userID)
 	if err != nil {
 		logger.Error("Error deleting user", zap.Error(err), zap.String("userID", userID), zap.String("deleterID", deleterID))
 		return fmt.Errorf("failed to delete user: %w", err)
@@ -229,7 +331,9 @@ func (s *UserService) DeleteUser(ctx context.Context, userID string, deleterID s
 func (s *UserService) GetUser(ctx context.Context, userID string) (*model.User, error) {
 	// Try to get from cache first
 	cachedUser, err := s.cacheService.GetUser(ctx, userID)
-	if err == nil && cachedUser != nil {
+	if err != nil {
+		logCacheReadError(db.CacheKindUser, userID, err)
+	} else if cachedUser != nil {
 		return cachedUser, nil
 	}
 
@@ -250,9 +354,52 @@ func (s *UserService) GetUser(ctx context.Context, userID string) (*model.User,
 	return user, nil
 }
 
-// ListUsers retrieves all users, possibly with pagination
+// GetUsersByIDs retrieves up to MaxBatchGetIDs users at once, from the cache where possible, listing
+// the IDs with no user as missing
+func (s *UserService) GetUsersByIDs(ctx context.Context, userIDs []string) (*model.UserBatch, error) {
+	users, missing, err := batchGet(ctx, batchSource[model.User]{
+		kind:   "user",
+		idOf:   func(user *model.User) string { return user.ID },
+		cached: s.cacheService.GetUsers,
+		load:   s.userDAO.GetUsersByIDs,
+		store:  s.cacheService.SetMany,
+	}, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &model.UserBatch{Items: users, Missing: missing}, nil
+}
+
+// UserExists reports whether a user exists without fetching it
+func (s *UserService) UserExists(ctx context.Context, userID string) (bool, error) {
+	exists, err := s.userDAO.Exists(ctx, userID)
+	if err != nil {
+		logger.Error("Error checking user existence", zap.Error(err), zap.String("userID", userID))
+		return false, fmt.Errorf("failed to check user existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ListUsers retrieves all users, possibly with pagination. Scoped admins only see the users within
+// their scope.
 func (s *UserService) ListUsers(ctx context.Context, limit int, offset int) ([]*model.User, error) {
-	users, err := s.userDAO.ListUsers(ctx, limit, offset)
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := requestingAdminScope(ctx, s.userDAO)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*model.User
+	if scope != nil {
+		users, err = s.userDAO.ListUsersInScope(ctx, *scope, limit, offset)
+	} else {
+		users, err = s.userDAO.ListUsers(ctx, limit, offset)
+	}
 	if err != nil {
 		logger.Error("Error listing users", zap.Error(err), zap.Int("limit", limit), zap.Int("offset", offset))
 		return nil, fmt.Errorf("failed to list users: %w", err)
@@ -265,13 +412,11 @@ func (s *UserService) ListUsers(ctx context.Context, limit int, offset int) ([]*
 func (s *UserService) SearchUsers(ctx context.Context, criteria model.UserSearchCriteria) ([]*model.User, error) {
 	logger.Info("Searching users", zap.Any("criteria", criteria))
 
-	if criteria.Limit < 1 {
-		criteria.Limit = 10 // or any other default value
-	}
-
-	if criteria.Offset < 0 {
-		criteria.Offset = 0
+	limit, offset, err := helper_util.ClampPagination(criteria.Limit, criteria.Offset)
+	if err != nil {
+		return nil, err
 	}
+	criteria.Limit, criteria.Offset = limit, offset
 
 	users, err := s.userDAO.SearchUsers(ctx, criteria)
 	if err != nil {
@@ -285,8 +430,159 @@ func (s *UserService) SearchUsers(ctx context.Context, criteria model.UserSearch
 	return users, nil
 }
 
+// ActivateUser moves a suspended or disabled user back to active
+func (s *UserService) ActivateUser(ctx context.Context, userID string, actorID string) (*model.User, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.changeUserStatus(ctx, userID, model.UserStatusActive, actorID)
+}
+
+// SuspendUser temporarily blocks an active user from being granted access
+func (s *UserService) SuspendUser(ctx context.Context, userID string, actorID string) (*model.User, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.changeUserStatus(ctx, userID, model.UserStatusSuspended, actorID)
+}
+
+// DisableUser permanently blocks a user from being granted access until reactivated
+func (s *UserService) DisableUser(ctx context.Context, userID string, actorID string) (*model.User, error) {
+	if err := util.CheckWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.changeUserStatus(ctx, userID, model.UserStatusDisabled, actorID)
+}
+
+// RecordLogin records a successful authentication for the user. Every authenticated request counts
+// as one, so a login is only recorded once the last one recorded is loginRecordInterval old.
+func (s *UserService) RecordLogin(ctx context.Context, userID string) error {
+	// Logins go unrecorded while read-only, so authenticated reads keep serving
+	if util.IsReadOnly(ctx) {
+		return nil
+	}
+
+	_, recorded, err := s.userDAO.RecordLogin(ctx, userID, loginRecordInterval)
+	if err != nil || !recorded {
+		return err
+	}
+
+	// Drop the cached copy so the next read returns the new last login
+	if err := s.cacheService.DeleteUser(ctx, userID); err != nil {
+		logger.Warn("Failed to delete user from cache", zap.Error(err), zap.String("userID", userID))
+	}
+
+	return nil
+}
+
+// GetInactiveUsers retrieves users who have not logged in within the given number of days
+func (s *UserService) GetInactiveUsers(ctx context.Context, days int, limit int, offset int) ([]*model.User, error) {
+	if days < 1 {
+		return nil, fmt.Errorf("%w: days must be at least 1", echo_errors.ErrInvalidSearchCriteria)
+	}
+
+	limit, offset, err := helper_util.ClampPagination(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	users, err := s.userDAO.ListInactiveUsers(ctx, since, limit, offset)
+	if err != nil {
+		logger.Error("Error listing inactive users", zap.Error(err), zap.Int("days", days))
+		return nil, fmt.Errorf("failed to list inactive users: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetEffectivePermissions resolves the groups, roles and permissions a user holds through nested groups
+func (s *UserService) GetEffectivePermissions(ctx context.Context, userID string) (*model.EffectivePermissions, error) {
+	effective, err := s.userDAO.GetEffectivePermissions(ctx, userID)
+	if err != nil {
+		logger.Error("Error resolving effective permissions", zap.Error(err), zap.String("userID", userID))
+		return nil, err
+	}
+
+	return effective, nil
+}
+
 // Helper methods
 
+// userStatusTransitions lists the statuses each status may move to
+var userStatusTransitions = map[string][]string{
+	model.UserStatusActive:    {model.UserStatusSuspended, model.UserStatusDisabled},
+	model.UserStatusSuspended: {model.UserStatusActive, model.UserStatusDisabled},
+	model.UserStatusDisabled:  {model.UserStatusActive},
+}
+
+func isValidUserStatus(status string) bool {
+	_, ok := userStatusTransitions[status]
+	return ok
+}
+
+func (s *UserService) changeUserStatus(ctx context.Context, userID string, status string, actorID string) (*model.User, error) {
+	oldUser, err := s.userDAO.GetUser(ctx, userID)
+	if err != nil {
+		logger.Error("Error retrieving existing user", zap.Error(err), zap.String("userID", userID))
+		return nil, err
+	}
+
+	if err := checkAdminScope(ctx, s.userDAO, oldUser.OrganizationID, oldUser.DepartmentID); err != nil {
+		return nil, err
+	}
+
+	// Users created before statuses were enforced may have none, or a legacy or differently cased one
+	current := model.NormalizeUserStatus(oldUser.Status)
+
+	allowed := false
+	for _, next := range userStatusTransitions[current] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: %s to %s", echo_errors.ErrInvalidUserStatusTransition, current, status)
+	}
+
+	updatedUser, err := s.userDAO.SetUserStatus(ctx, userID, status)
+	if err != nil {
+		logger.Error("Error changing user status", zap.Error(err), zap.String("userID", userID), zap.String("status", status), zap.String("actorID", actorID))
+		return nil, err
+	}
+
+	// Update cache
+	if err := s.cacheService.SetUser(ctx, *updatedUser); err != nil {
+		logger.Warn("Failed to update user in cache", zap.Error(err), zap.String("userID", userID))
+	}
+
+	// Publish event for asynchronous processing
+	s.eventBus.Publish(ctx, "user.updated", map[string]model.User{
+		"old": *oldUser,
+		"new": *updatedUser,
+	})
+
+	logger.Info("User status changed successfully",
+		zap.String("userID", userID),
+		zap.String("oldStatus", current),
+		zap.String("newStatus", status),
+		zap.String("actorID", actorID))
+	return updatedUser, nil
+}
+
+// userAttributesToMap widens user attributes so they can be checked against an attribute group schema
+func userAttributesToMap(attrs map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(attrs))
+	for key, value := range attrs {
+		result[key] = value
+	}
+	return result
+}
+
 func (s *UserService) updateUserIndexes(ctx context.Context, user model.User) error {
 	// Implementation for updating indexes
 	return nil
@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	echo_neo4j "github.com/dev-mohitbeniwal/echo/api/model/neo4j"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestUserStatusNormalization(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	writeReached := errors.New("write reached")
+
+	newService := func(driver *mock.FakeDriver) *service.UserService {
+		auditService := &mock.MockAuditService{}
+		return service.NewUserService(
+			&dao.UserDAO{Driver: driver, AuditService: auditService},
+			&dao.AttributeGroupDAO{Driver: driver, AuditService: auditService},
+			&dao.SoDRuleDAO{Driver: driver, AuditService: auditService},
+			util.NewValidationUtil(),
+			nil,
+			nil,
+			util.NewEventBus(),
+		)
+	}
+
+	t.Run("CreateAcceptsCapitalizedStatus", func(t *testing.T) {
+		driver := mock.NewFakeDriver().Fails("RETURN u.id as id", writeReached)
+		user := model.User{
+			ID:       "u3",
+			Name:     "Jane Doe",
+			Username: "jdoe",
+			Email:    "jdoe@example.com",
+			UserType: "DepartmentUser",
+			Status:   "Active",
+		}
+
+		_, err := newService(driver).CreateUser(ctx, user, "admin")
+
+		assert.NotErrorIs(t, err, echo_errors.ErrInvalidUserStatus)
+		writes := driver.QueriesContaining("RETURN u.id as id")
+		if assert.Len(t, writes, 1) {
+			props := writes[0].Params["props"].(map[string]interface{})
+			assert.Equal(t, model.UserStatusActive, props["status"])
+		}
+	})
+
+	t.Run("LegacyInactiveUserIsDisabled", func(t *testing.T) {
+		driver := mock.NewFakeDriver().
+			Fails("SET u.status = $status", writeReached).
+			Returns("MATCH (u:"+echo_neo4j.LabelUser, userRecord("u1", "inactive"))
+		userService := newService(driver)
+
+		_, err := userService.SuspendUser(ctx, "u1", "admin")
+		assert.ErrorIs(t, err, echo_errors.ErrInvalidUserStatusTransition)
+		assert.Empty(t, driver.QueriesContaining("SET u.status = $status"))
+
+		_, err = userService.ActivateUser(ctx, "u1", "admin")
+		assert.ErrorIs(t, err, writeReached)
+	})
+
+	t.Run("CapitalizedSuspendedUserMayBeReactivated", func(t *testing.T) {
+		driver := mock.NewFakeDriver().
+			Fails("SET u.status = $status", writeReached).
+			Returns("MATCH (u:"+echo_neo4j.LabelUser, userRecord("u1", "Suspended"))
+
+		_, err := newService(driver).ActivateUser(ctx, "u1", "admin")
+
+		assert.ErrorIs(t, err, writeReached)
+	})
+}
@@ -23,3 +23,55 @@ func (m *MockAuditService) QueryLogs(ctx context.Context, from, to time.Time, us
 	args := m.Called(ctx, from, to, userID, resourceID)
 	return args.Get(0).([]audit.AuditLog), args.Error(1)
 }
+
+// StreamLogs visits the logs configured for the call in order
+func (m *MockAuditService) StreamLogs(ctx context.Context, from, to time.Time, userID, resourceID string, visit func(audit.AuditLog) error) error {
+	args := m.Called(ctx, from, to, userID, resourceID)
+	for _, log := range args.Get(0).([]audit.AuditLog) {
+		if err := visit(log); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockAuditService) VerifyChain(ctx context.Context, from, to time.Time) (*audit.ChainVerification, error) {
+	args := m.Called(ctx, from, to)
+	verification, _ := args.Get(0).(*audit.ChainVerification)
+	return verification, args.Error(1)
+}
+
+// MockAuditRepository is a mock implementation of audit.Repository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) LogAccess(ctx context.Context, log audit.AuditLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) QueryLogs(ctx context.Context, from, to time.Time, userID, resourceID string) ([]audit.AuditLog, error) {
+	args := m.Called(ctx, from, to, userID, resourceID)
+	return args.Get(0).([]audit.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditRepository) StreamLogs(ctx context.Context, from, to time.Time, userID, resourceID string, visit func(audit.AuditLog) error) error {
+	args := m.Called(ctx, from, to, userID, resourceID)
+	for _, log := range args.Get(0).([]audit.AuditLog) {
+		if err := visit(log); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockAuditRepository) ListIndices(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAuditRepository) DeleteIndex(ctx context.Context, index string) error {
+	args := m.Called(ctx, index)
+	return args.Error(0)
+}
@@ -0,0 +1,284 @@
+// test/mock/fake_neo4j.go
+package mock
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// FakeDriver is an in-memory stand-in for a Neo4j driver that DAOs can be run against without a
+// database. It records every query run through its sessions, in a transaction or not, and answers each
+// with the response of the first registered fragment the query contains, or with no records.
+type FakeDriver struct {
+	mu        sync.Mutex
+	responses []fakeResponse
+	queries   []FakeQuery
+	commits   int
+	rollbacks int
+}
+
+// FakeQuery is a query run through a FakeDriver
+type FakeQuery struct {
+	Cypher string
+	Params map[string]any
+	Write  bool // Whether it ran in a write transaction or on a write session
+}
+
+// FakeResponse is what a FakeDriver answers the queries containing a fragment with
+type FakeResponse struct {
+	Records      []*neo4j.Record
+	NodesDeleted int   // Reported by the summary of the result
+	Err          error // Returned by Run in place of a result
+	StreamErr    error // Reported by the result once its records are read, as when the stream is cut off
+}
+
+type fakeResponse struct {
+	fragment string
+	FakeResponse
+}
+
+// NewFakeDriver returns a FakeDriver answering every query with no records
+func NewFakeDriver() *FakeDriver {
+	return &FakeDriver{}
+}
+
+// On answers the queries containing fragment with response, unless a fragment registered earlier
+// matches them too
+func (d *FakeDriver) On(fragment string, response FakeResponse) *FakeDriver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.responses = append(d.responses, fakeResponse{fragment: fragment, FakeResponse: response})
+	return d
+}
+
+// Returns answers the queries containing fragment with records
+func (d *FakeDriver) Returns(fragment string, records ...*neo4j.Record) *FakeDriver {
+	return d.On(fragment, FakeResponse{Records: records})
+}
+
+// Fails makes the queries containing fragment fail with err
+func (d *FakeDriver) Fails(fragment string, err error) *FakeDriver {
+	return d.On(fragment, FakeResponse{Err: err})
+}
+
+// Queries returns the queries run so far, in order
+func (d *FakeDriver) Queries() []FakeQuery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]FakeQuery(nil), d.queries...)
+}
+
+// QueriesContaining returns the queries run so far that contain fragment, in order
+func (d *FakeDriver) QueriesContaining(fragment string) []FakeQuery {
+	var matching []FakeQuery
+	for _, query := range d.Queries() {
+		if strings.Contains(query.Cypher, fragment) {
+			matching = append(matching, query)
+		}
+	}
+	return matching
+}
+
+// Commits returns how many transactions committed, their work having succeeded
+func (d *FakeDriver) Commits() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.commits
+}
+
+// Rollbacks returns how many transactions rolled back, their work having failed
+func (d *FakeDriver) Rollbacks() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rollbacks
+}
+
+func (d *FakeDriver) NewSession(config neo4j.SessionConfig) neo4j.Session {
+	return &fakeSession{driver: d, write: config.AccessMode == neo4j.AccessModeWrite}
+}
+
+func (d *FakeDriver) run(cypher string, params map[string]any, write bool) (neo4j.Result, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, FakeQuery{Cypher: cypher, Params: params, Write: write})
+	for _, response := range d.responses {
+		if strings.Contains(cypher, response.fragment) {
+			if response.Err != nil {
+				return nil, response.Err
+			}
+			return &fakeResult{records: response.Records, nodesDeleted: response.NodesDeleted, streamErr: response.StreamErr}, nil
+		}
+	}
+	return &fakeResult{}, nil
+}
+
+func (d *FakeDriver) settle(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		d.rollbacks++
+	} else {
+		d.commits++
+	}
+}
+
+type fakeSession struct {
+	driver *FakeDriver
+	write  bool
+}
+
+func (s *fakeSession) Run(cypher string, params map[string]any, configurers ...func(*neo4j.TransactionConfig)) (neo4j.Result, error) {
+	return s.driver.run(cypher, params, s.write)
+}
+
+func (s *fakeSession) ReadTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	return s.transact(work, false)
+}
+
+func (s *fakeSession) WriteTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	return s.transact(work, true)
+}
+
+func (s *fakeSession) transact(work neo4j.TransactionWork, write bool) (any, error) {
+	result, err := work(&fakeTransaction{driver: s.driver, write: write})
+	s.driver.settle(err)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *fakeSession) BeginTransaction(configurers ...func(*neo4j.TransactionConfig)) (neo4j.Transaction, error) {
+	return &fakeTransaction{driver: s.driver, write: s.write}, nil
+}
+
+func (s *fakeSession) LastBookmarks() neo4j.Bookmarks {
+	return nil
+}
+
+func (s *fakeSession) LastBookmark() string {
+	return ""
+}
+
+func (s *fakeSession) Close() error {
+	return nil
+}
+
+type fakeTransaction struct {
+	driver *FakeDriver
+	write  bool
+}
+
+func (t *fakeTransaction) Run(cypher string, params map[string]any) (neo4j.Result, error) {
+	return t.driver.run(cypher, params, t.write)
+}
+
+func (t *fakeTransaction) Commit() error {
+	t.driver.settle(nil)
+	return nil
+}
+
+func (t *fakeTransaction) Rollback() error {
+	t.driver.settle(errors.New("rolled back"))
+	return nil
+}
+
+func (t *fakeTransaction) Close() error {
+	return nil
+}
+
+type fakeResult struct {
+	records      []*neo4j.Record
+	next         int
+	current      *neo4j.Record
+	nodesDeleted int
+	streamErr    error
+}
+
+func (r *fakeResult) Keys() ([]string, error) {
+	if len(r.records) == 0 {
+		return nil, nil
+	}
+	return r.records[0].Keys, nil
+}
+
+func (r *fakeResult) Next() bool {
+	if r.next >= len(r.records) {
+		r.current = nil
+		return false
+	}
+	r.current = r.records[r.next]
+	r.next++
+	return true
+}
+
+func (r *fakeResult) NextRecord(record **neo4j.Record) bool {
+	ok := r.Next()
+	if record != nil {
+		*record = r.current
+	}
+	return ok
+}
+
+func (r *fakeResult) PeekRecord(record **neo4j.Record) bool {
+	if r.next >= len(r.records) {
+		return false
+	}
+	if record != nil {
+		*record = r.records[r.next]
+	}
+	return true
+}
+
+func (r *fakeResult) Err() error {
+	if r.next < len(r.records) {
+		return nil
+	}
+	return r.streamErr
+}
+
+func (r *fakeResult) Record() *neo4j.Record {
+	return r.current
+}
+
+func (r *fakeResult) Collect() ([]*neo4j.Record, error) {
+	records := r.records[r.next:]
+	r.next = len(r.records)
+	return records, r.streamErr
+}
+
+func (r *fakeResult) Single() (*neo4j.Record, error) {
+	if len(r.records)-r.next != 1 {
+		return nil, errors.New("result does not hold exactly one record")
+	}
+	r.Next()
+	return r.current, nil
+}
+
+func (r *fakeResult) Consume() (neo4j.ResultSummary, error) {
+	r.next = len(r.records)
+	return fakeSummary{counters: fakeCounters{nodesDeleted: r.nodesDeleted}}, nil
+}
+
+// fakeSummary and fakeCounters only implement what the DAOs read; the rest of their methods are left
+// to the nil interfaces they embed
+type fakeSummary struct {
+	neo4j.ResultSummary
+	counters fakeCounters
+}
+
+func (s fakeSummary) Counters() neo4j.Counters {
+	return s.counters
+}
+
+type fakeCounters struct {
+	neo4j.Counters
+	nodesDeleted int
+}
+
+func (c fakeCounters) NodesDeleted() int {
+	return c.nodesDeleted
+}
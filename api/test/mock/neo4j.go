@@ -2,7 +2,9 @@
 package mock
 
 import (
+	"context"
 	"net/url"
+	"sync"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 	"github.com/stretchr/testify/mock"
@@ -78,6 +80,48 @@ func (m *MockSession) Close() error {
 	return args.Error(0)
 }
 
+// MockTxSession is a MockSession whose transaction functions execute the supplied work against Tx.
+// Like the driver, it commits a write transaction whose work succeeds and rolls back one whose work
+// fails, counting both outcomes. Transactions may run concurrently, as they do when event subscribers
+// write.
+type MockTxSession struct {
+	MockSession
+	Tx        neo4j.Transaction
+	mu        sync.Mutex
+	commits   int
+	rollbacks int
+}
+
+func (m *MockTxSession) ReadTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	return work(m.Tx)
+}
+
+func (m *MockTxSession) WriteTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	result, err := work(m.Tx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.rollbacks++
+		return nil, err
+	}
+	m.commits++
+	return result, nil
+}
+
+// Commits returns how many write transactions committed, their work having succeeded
+func (m *MockTxSession) Commits() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commits
+}
+
+// Rollbacks returns how many write transactions rolled back, their work having failed
+func (m *MockTxSession) Rollbacks() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rollbacks
+}
+
 // MockTransaction is a mock implementation of neo4j.Transaction
 type MockTransaction struct {
 	mock.Mock
@@ -114,6 +158,21 @@ func (m *MockResult) Next() bool {
 	return args.Bool(0)
 }
 
+func (m *MockResult) NextRecord(record **neo4j.Record) bool {
+	args := m.Called(record)
+	return args.Bool(0)
+}
+
+func (m *MockResult) PeekRecord(record **neo4j.Record) bool {
+	args := m.Called(record)
+	return args.Bool(0)
+}
+
+func (m *MockResult) Single() (*neo4j.Record, error) {
+	args := m.Called()
+	return args.Get(0).(*neo4j.Record), args.Error(1)
+}
+
 func (m *MockResult) Record() *neo4j.Record {
 	args := m.Called()
 	return args.Get(0).(*neo4j.Record)
@@ -144,19 +203,14 @@ func (m *MockResult) Peek() (*neo4j.Record, error) {
 	return args.Get(0).(*neo4j.Record), args.Error(1)
 }
 
+// Err returns the error set up with On("Err"), and nil for results that set none up, as most end cleanly
 func (m *MockResult) Err() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-func (m *MockResult) NextRecord() (**neo4j.Record, error) {
-	args := m.Called()
-	return args.Get(0).(**neo4j.Record), args.Error(1)
-}
-
-func (m *MockResult) Single() (interface{}, error) {
-	args := m.Called()
-	return args.Get(0), args.Error(1)
+	for _, call := range m.ExpectedCalls {
+		if call.Method == "Err" {
+			return m.Called().Error(0)
+		}
+	}
+	return nil
 }
 
 func (m *MockResult) KeysSummary() ([]string, error) {
@@ -228,3 +282,59 @@ func (m *ResultSummary) ResultConsumedAfter() neo4j.Duration {
 	args := m.Called()
 	return args.Get(0).(neo4j.Duration)
 }
+
+// MockContextDriver is a MockDriver that also opens sessions honoring a context
+type MockContextDriver struct {
+	MockDriver
+}
+
+func (m *MockContextDriver) NewContextSession(ctx context.Context, config neo4j.SessionConfig) (neo4j.SessionWithContext, bool) {
+	args := m.Called(ctx, config)
+	session, _ := args.Get(0).(neo4j.SessionWithContext)
+	return session, args.Bool(1)
+}
+
+// MockContextSession is a mock implementation of the neo4j.SessionWithContext methods the DAOs use;
+// the others panic
+type MockContextSession struct {
+	neo4j.SessionWithContext
+	mock.Mock
+}
+
+func (m *MockContextSession) Run(ctx context.Context, cypher string, params map[string]any, configurers ...func(*neo4j.TransactionConfig)) (neo4j.ResultWithContext, error) {
+	args := m.Called(ctx, cypher, params, configurers)
+	result, _ := args.Get(0).(neo4j.ResultWithContext)
+	return result, args.Error(1)
+}
+
+func (m *MockContextSession) LastBookmarks() neo4j.Bookmarks {
+	args := m.Called()
+	return args.Get(0).(neo4j.Bookmarks)
+}
+
+func (m *MockContextSession) Close(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// MockContextResult is a mock implementation of the neo4j.ResultWithContext methods the DAOs use; the
+// others panic
+type MockContextResult struct {
+	neo4j.ResultWithContext
+	mock.Mock
+}
+
+func (m *MockContextResult) Next(ctx context.Context) bool {
+	args := m.Called(ctx)
+	return args.Bool(0)
+}
+
+func (m *MockContextResult) Record() *neo4j.Record {
+	args := m.Called()
+	return args.Get(0).(*neo4j.Record)
+}
+
+func (m *MockContextResult) Err() error {
+	args := m.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,254 @@
+// test/mock/redis.go
+package mock
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// RedisServer is a minimal Redis server keeping its keys in memory. It knows just enough commands for
+// the cache: SET, GET, MGET, DEL, SADD, SMEMBERS, EXPIRE, SCAN and PING. Expirations are accepted
+// but never applied.
+type RedisServer struct {
+	listener net.Listener
+
+	mu   sync.Mutex
+	keys map[string]string
+	sets map[string]map[string]bool
+	// cursors holds the key each SCAN cursor resumes from. Cursor 0 starts from the first key.
+	cursors []string
+	// calls counts the commands received, by name
+	calls map[string]int
+}
+
+// StartRedisServer starts a RedisServer and points the cache at it until the test ends
+func StartRedisServer(tb testing.TB) *RedisServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+	server := &RedisServer{listener: listener, keys: map[string]string{}, sets: map[string]map[string]bool{}, cursors: []string{""}, calls: map[string]int{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	previousClient := db.RedisClient
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), Protocol: 2, DisableIndentity: true})
+	db.RedisClient = client
+	tb.Cleanup(func() {
+		client.Close()
+		db.RedisClient = previousClient
+		listener.Close()
+	})
+	return server
+}
+
+// Has reports whether key holds a value or a set
+func (s *RedisServer) Has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, isValue := s.keys[key]
+	_, isSet := s.sets[key]
+	return isValue || isSet
+}
+
+// Calls returns how many times the command was received, including pipelined ones
+func (s *RedisServer) Calls(command string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[strings.ToUpper(command)]
+}
+
+func (s *RedisServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.reply(writer, args)
+		// Replies to pipelined commands go out together, once the client has no more to send
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:length])
+	}
+	return args, nil
+}
+
+func (s *RedisServer) reply(writer *bufio.Writer, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[strings.ToUpper(args[0])]++
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		writer.WriteString("+PONG\r\n")
+	case "SET":
+		s.keys[args[1]] = args[2]
+		writer.WriteString("+OK\r\n")
+	case "GET":
+		writeBulk(writer, s.keys, args[1])
+	case "MGET":
+		fmt.Fprintf(writer, "*%d\r\n", len(args)-1)
+		for _, key := range args[1:] {
+			writeBulk(writer, s.keys, key)
+		}
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			_, isValue := s.keys[key]
+			_, isSet := s.sets[key]
+			if isValue || isSet {
+				delete(s.keys, key)
+				delete(s.sets, key)
+				deleted++
+			}
+		}
+		fmt.Fprintf(writer, ":%d\r\n", deleted)
+	case "SADD":
+		members := s.sets[args[1]]
+		if members == nil {
+			members = map[string]bool{}
+			s.sets[args[1]] = members
+		}
+		added := 0
+		for _, member := range args[2:] {
+			if !members[member] {
+				members[member] = true
+				added++
+			}
+		}
+		fmt.Fprintf(writer, ":%d\r\n", added)
+	case "SMEMBERS":
+		var members []string
+		for member := range s.sets[args[1]] {
+			members = append(members, member)
+		}
+		sort.Strings(members)
+		fmt.Fprintf(writer, "*%d\r\n", len(members))
+		for _, member := range members {
+			fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(member), member)
+		}
+	case "EXPIRE":
+		_, isValue := s.keys[args[1]]
+		_, isSet := s.sets[args[1]]
+		if isValue || isSet {
+			writer.WriteString(":1\r\n")
+		} else {
+			writer.WriteString(":0\r\n")
+		}
+	case "SCAN":
+		s.scan(writer, args[1:])
+	default:
+		fmt.Fprintf(writer, "-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// scan replies to SCAN <cursor> [MATCH <pattern>] [COUNT <count>]. The cursor is the position, among
+// the sorted keys, the next call resumes from, so keys deleted between calls do not make it skip others.
+func (s *RedisServer) scan(writer *bufio.Writer, args []string) {
+	cursor, err := strconv.Atoi(args[0])
+	if err != nil {
+		writer.WriteString("-ERR invalid cursor\r\n")
+		return
+	}
+	pattern, count := "*", 10
+	for i := 1; i+1 < len(args); i += 2 {
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			pattern = args[i+1]
+		case "COUNT":
+			if count, err = strconv.Atoi(args[i+1]); err != nil || count < 1 {
+				writer.WriteString("-ERR value is not an integer or out of range\r\n")
+				return
+			}
+		}
+	}
+
+	var keys []string
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	for key := range s.sets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Resume after the keys already returned, which sort before the cursor's key
+	start := sort.SearchStrings(keys, s.cursors[cursor])
+	end := min(start+count, len(keys))
+	var matched []string
+	for _, key := range keys[start:end] {
+		if util.GlobMatch(pattern, key) {
+			matched = append(matched, key)
+		}
+	}
+	next := 0
+	if end < len(keys) {
+		s.cursors = append(s.cursors, keys[end])
+		next = len(s.cursors) - 1
+	}
+
+	nextCursor := strconv.Itoa(next)
+	fmt.Fprintf(writer, "*2\r\n$%d\r\n%s\r\n*%d\r\n", len(nextCursor), nextCursor, len(matched))
+	for _, key := range matched {
+		fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(key), key)
+	}
+}
+
+func writeBulk(writer *bufio.Writer, keys map[string]string, key string) {
+	value, ok := keys[key]
+	if !ok {
+		writer.WriteString("$-1\r\n")
+		return
+	}
+	fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(value), value)
+}
@@ -0,0 +1,59 @@
+// test/mock/resource.go
+package mock
+
+import (
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+
+	"github.com/dev-mohitbeniwal/echo/api/dao"
+	"github.com/dev-mohitbeniwal/echo/api/service"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// ResourceNode returns a stored resource node with every property the resource DAO reads set: a
+// DOCUMENT of org1 owned and created by u1 at version 1. props replaces individual properties.
+func ResourceNode(id string, props map[string]any) neo4j.Node {
+	node := neo4j.Node{Props: map[string]any{
+		"id":               id,
+		"name":             "Quarterly Report",
+		"description":      "",
+		"type":             "DOCUMENT",
+		"typeID":           "rt1",
+		"uri":              "",
+		"organizationID":   "org1",
+		"departmentID":     "",
+		"ownerID":          "u1",
+		"status":           "active",
+		"version":          int64(1),
+		"attributeGroupID": "",
+		"sensitivity":      "",
+		"classification":   "",
+		"location":         "",
+		"format":           "",
+		"size":             int64(0),
+		"createdBy":        "u1",
+		"updatedBy":        "u1",
+		"inheritedACL":     false,
+		"createdAt":        "2024-01-01T00:00:00Z",
+		"updatedAt":        "2024-01-01T00:00:00Z",
+	}}
+	for key, value := range props {
+		node.Props[key] = value
+	}
+	return node
+}
+
+// NewResourceService returns a resource service whose DAOs all run against driver and audit to a
+// MockAuditService. cacheService may be nil.
+func NewResourceService(driver dao.Neo4jDriver, cacheService *util.CacheService) *service.ResourceService {
+	auditService := &MockAuditService{}
+	return service.NewResourceService(
+		&dao.ResourceDAO{Driver: driver, AuditService: auditService},
+		&dao.ResourceTypeDAO{Driver: driver, AuditService: auditService},
+		&dao.AttributeGroupDAO{Driver: driver, AuditService: auditService},
+		&dao.UserDAO{Driver: driver, AuditService: auditService},
+		util.NewValidationUtil(),
+		cacheService,
+		nil,
+		util.NewEventBus(),
+	)
+}
@@ -40,6 +40,21 @@ func (m *MockIPolicyService) EXPECT() *MockIPolicyServiceMockRecorder {
 	return m.recorder
 }
 
+// AnalyzePolicySet mocks base method.
+func (m *MockIPolicyService) AnalyzePolicySet(ctx context.Context) (*model.PolicySetAnalysis, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnalyzePolicySet", ctx)
+	ret0, _ := ret[0].(*model.PolicySetAnalysis)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnalyzePolicySet indicates an expected call of AnalyzePolicySet.
+func (mr *MockIPolicyServiceMockRecorder) AnalyzePolicySet(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnalyzePolicySet", reflect.TypeOf((*MockIPolicyService)(nil).AnalyzePolicySet), ctx)
+}
+
 // AnalyzePolicyUsage mocks base method.
 func (m *MockIPolicyService) AnalyzePolicyUsage(ctx context.Context, policyID string) (*model.PolicyUsageAnalysis, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +70,36 @@ func (mr *MockIPolicyServiceMockRecorder) AnalyzePolicyUsage(ctx, policyID any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnalyzePolicyUsage", reflect.TypeOf((*MockIPolicyService)(nil).AnalyzePolicyUsage), ctx, policyID)
 }
 
+// ApprovePolicy mocks base method.
+func (m *MockIPolicyService) ApprovePolicy(ctx context.Context, policyID, actorID, comment string) (*model.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApprovePolicy", ctx, policyID, actorID, comment)
+	ret0, _ := ret[0].(*model.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApprovePolicy indicates an expected call of ApprovePolicy.
+func (mr *MockIPolicyServiceMockRecorder) ApprovePolicy(ctx, policyID, actorID, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApprovePolicy", reflect.TypeOf((*MockIPolicyService)(nil).ApprovePolicy), ctx, policyID, actorID, comment)
+}
+
+// CoverageReport mocks base method.
+func (m *MockIPolicyService) CoverageReport(ctx context.Context, orgID string) (*model.CoverageReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CoverageReport", ctx, orgID)
+	ret0, _ := ret[0].(*model.CoverageReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CoverageReport indicates an expected call of CoverageReport.
+func (mr *MockIPolicyServiceMockRecorder) CoverageReport(ctx, orgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CoverageReport", reflect.TypeOf((*MockIPolicyService)(nil).CoverageReport), ctx, orgID)
+}
+
 // CreatePolicy mocks base method.
 func (m *MockIPolicyService) CreatePolicy(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +115,36 @@ func (mr *MockIPolicyServiceMockRecorder) CreatePolicy(ctx, policy, userID any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePolicy", reflect.TypeOf((*MockIPolicyService)(nil).CreatePolicy), ctx, policy, userID)
 }
 
+// CreatePolicyAndFetch mocks base method.
+func (m *MockIPolicyService) CreatePolicyAndFetch(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePolicyAndFetch", ctx, policy, userID)
+	ret0, _ := ret[0].(*model.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePolicyAndFetch indicates an expected call of CreatePolicyAndFetch.
+func (mr *MockIPolicyServiceMockRecorder) CreatePolicyAndFetch(ctx, policy, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePolicyAndFetch", reflect.TypeOf((*MockIPolicyService)(nil).CreatePolicyAndFetch), ctx, policy, userID)
+}
+
+// CreatePolicyTemplate mocks base method.
+func (m *MockIPolicyService) CreatePolicyTemplate(ctx context.Context, template model.PolicyTemplate, creatorID string) (*model.PolicyTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePolicyTemplate", ctx, template, creatorID)
+	ret0, _ := ret[0].(*model.PolicyTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePolicyTemplate indicates an expected call of CreatePolicyTemplate.
+func (mr *MockIPolicyServiceMockRecorder) CreatePolicyTemplate(ctx, template, creatorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePolicyTemplate", reflect.TypeOf((*MockIPolicyService)(nil).CreatePolicyTemplate), ctx, template, creatorID)
+}
+
 // DeletePolicy mocks base method.
 func (m *MockIPolicyService) DeletePolicy(ctx context.Context, policyID, userID string) error {
 	m.ctrl.T.Helper()
@@ -84,6 +159,21 @@ func (mr *MockIPolicyServiceMockRecorder) DeletePolicy(ctx, policyID, userID any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePolicy", reflect.TypeOf((*MockIPolicyService)(nil).DeletePolicy), ctx, policyID, userID)
 }
 
+// GetPoliciesByIDs mocks base method.
+func (m *MockIPolicyService) GetPoliciesByIDs(ctx context.Context, policyIDs []string) (*model.PolicyBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPoliciesByIDs", ctx, policyIDs)
+	ret0, _ := ret[0].(*model.PolicyBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPoliciesByIDs indicates an expected call of GetPoliciesByIDs.
+func (mr *MockIPolicyServiceMockRecorder) GetPoliciesByIDs(ctx, policyIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPoliciesByIDs", reflect.TypeOf((*MockIPolicyService)(nil).GetPoliciesByIDs), ctx, policyIDs)
+}
+
 // GetPolicy mocks base method.
 func (m *MockIPolicyService) GetPolicy(ctx context.Context, policyID string) (*model.Policy, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +189,66 @@ func (mr *MockIPolicyServiceMockRecorder) GetPolicy(ctx, policyID any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicy", reflect.TypeOf((*MockIPolicyService)(nil).GetPolicy), ctx, policyID)
 }
 
+// GetPolicyTemplate mocks base method.
+func (m *MockIPolicyService) GetPolicyTemplate(ctx context.Context, templateID string) (*model.PolicyTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicyTemplate", ctx, templateID)
+	ret0, _ := ret[0].(*model.PolicyTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolicyTemplate indicates an expected call of GetPolicyTemplate.
+func (mr *MockIPolicyServiceMockRecorder) GetPolicyTemplate(ctx, templateID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicyTemplate", reflect.TypeOf((*MockIPolicyService)(nil).GetPolicyTemplate), ctx, templateID)
+}
+
+// GetPolicyWithSubjects mocks base method.
+func (m *MockIPolicyService) GetPolicyWithSubjects(ctx context.Context, policyID string) (*model.PolicyWithSubjects, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicyWithSubjects", ctx, policyID)
+	ret0, _ := ret[0].(*model.PolicyWithSubjects)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolicyWithSubjects indicates an expected call of GetPolicyWithSubjects.
+func (mr *MockIPolicyServiceMockRecorder) GetPolicyWithSubjects(ctx, policyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicyWithSubjects", reflect.TypeOf((*MockIPolicyService)(nil).GetPolicyWithSubjects), ctx, policyID)
+}
+
+// ImportPolicies mocks base method.
+func (m *MockIPolicyService) ImportPolicies(ctx context.Context, policies []model.Policy, userID string) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportPolicies", ctx, policies, userID)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportPolicies indicates an expected call of ImportPolicies.
+func (mr *MockIPolicyServiceMockRecorder) ImportPolicies(ctx, policies, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportPolicies", reflect.TypeOf((*MockIPolicyService)(nil).ImportPolicies), ctx, policies, userID)
+}
+
+// InstantiatePolicy mocks base method.
+func (m *MockIPolicyService) InstantiatePolicy(ctx context.Context, templateID string, vars map[string]string, userID string) (*model.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstantiatePolicy", ctx, templateID, vars, userID)
+	ret0, _ := ret[0].(*model.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InstantiatePolicy indicates an expected call of InstantiatePolicy.
+func (mr *MockIPolicyServiceMockRecorder) InstantiatePolicy(ctx, templateID, vars, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstantiatePolicy", reflect.TypeOf((*MockIPolicyService)(nil).InstantiatePolicy), ctx, templateID, vars, userID)
+}
+
 // ListPolicies mocks base method.
 func (m *MockIPolicyService) ListPolicies(ctx context.Context, limit, offset int) ([]*model.Policy, error) {
 	m.ctrl.T.Helper()
@@ -114,6 +264,51 @@ func (mr *MockIPolicyServiceMockRecorder) ListPolicies(ctx, limit, offset any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPolicies", reflect.TypeOf((*MockIPolicyService)(nil).ListPolicies), ctx, limit, offset)
 }
 
+// ListPoliciesByCursor mocks base method.
+func (m *MockIPolicyService) ListPoliciesByCursor(ctx context.Context, cursor string, limit int) (*model.PolicyPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPoliciesByCursor", ctx, cursor, limit)
+	ret0, _ := ret[0].(*model.PolicyPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPoliciesByCursor indicates an expected call of ListPoliciesByCursor.
+func (mr *MockIPolicyServiceMockRecorder) ListPoliciesByCursor(ctx, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPoliciesByCursor", reflect.TypeOf((*MockIPolicyService)(nil).ListPoliciesByCursor), ctx, cursor, limit)
+}
+
+// PolicyExists mocks base method.
+func (m *MockIPolicyService) PolicyExists(ctx context.Context, policyID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PolicyExists", ctx, policyID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PolicyExists indicates an expected call of PolicyExists.
+func (mr *MockIPolicyServiceMockRecorder) PolicyExists(ctx, policyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PolicyExists", reflect.TypeOf((*MockIPolicyService)(nil).PolicyExists), ctx, policyID)
+}
+
+// RejectPolicy mocks base method.
+func (m *MockIPolicyService) RejectPolicy(ctx context.Context, policyID, actorID, comment string) (*model.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectPolicy", ctx, policyID, actorID, comment)
+	ret0, _ := ret[0].(*model.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectPolicy indicates an expected call of RejectPolicy.
+func (mr *MockIPolicyServiceMockRecorder) RejectPolicy(ctx, policyID, actorID, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectPolicy", reflect.TypeOf((*MockIPolicyService)(nil).RejectPolicy), ctx, policyID, actorID, comment)
+}
+
 // SearchPolicies mocks base method.
 func (m *MockIPolicyService) SearchPolicies(ctx context.Context, criteria model.PolicySearchCriteria) ([]*model.Policy, error) {
 	m.ctrl.T.Helper()
@@ -129,6 +324,21 @@ func (mr *MockIPolicyServiceMockRecorder) SearchPolicies(ctx, criteria any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPolicies", reflect.TypeOf((*MockIPolicyService)(nil).SearchPolicies), ctx, criteria)
 }
 
+// SubmitForApproval mocks base method.
+func (m *MockIPolicyService) SubmitForApproval(ctx context.Context, policyID, actorID, comment string) (*model.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitForApproval", ctx, policyID, actorID, comment)
+	ret0, _ := ret[0].(*model.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitForApproval indicates an expected call of SubmitForApproval.
+func (mr *MockIPolicyServiceMockRecorder) SubmitForApproval(ctx, policyID, actorID, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitForApproval", reflect.TypeOf((*MockIPolicyService)(nil).SubmitForApproval), ctx, policyID, actorID, comment)
+}
+
 // UpdatePolicy mocks base method.
 func (m *MockIPolicyService) UpdatePolicy(ctx context.Context, policy model.Policy, userID string) (*model.Policy, error) {
 	m.ctrl.T.Helper()
@@ -143,3 +353,17 @@ func (mr *MockIPolicyServiceMockRecorder) UpdatePolicy(ctx, policy, userID any)
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePolicy", reflect.TypeOf((*MockIPolicyService)(nil).UpdatePolicy), ctx, policy, userID)
 }
+
+// WatchPolicyChanges mocks base method.
+func (m *MockIPolicyService) WatchPolicyChanges(ctx context.Context) <-chan model.PolicyChange {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchPolicyChanges", ctx)
+	ret0, _ := ret[0].(<-chan model.PolicyChange)
+	return ret0
+}
+
+// WatchPolicyChanges indicates an expected call of WatchPolicyChanges.
+func (mr *MockIPolicyServiceMockRecorder) WatchPolicyChanges(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchPolicyChanges", reflect.TypeOf((*MockIPolicyService)(nil).WatchPolicyChanges), ctx)
+}
@@ -4,148 +4,259 @@ package util
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
 
 	"github.com/dev-mohitbeniwal/echo/api/db"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 )
 
-type CacheService struct{}
+// CacheService caches entities in Redis. While its circuit breaker is open, calls fail at once with
+// ErrCacheUnavailable rather than waiting on an unreachable Redis, and callers fall back to the database.
+type CacheService struct {
+	breaker *CircuitBreaker // Nil when Redis is always called
+}
+
+// NewCacheService creates a cache guarded by breaker, which may be nil
+func NewCacheService(breaker *CircuitBreaker) *CacheService {
+	return &CacheService{breaker: breaker}
+}
+
+// BreakerStats returns the state of the circuit breaker guarding Redis, and false without one
+func (c *CacheService) BreakerStats() (CircuitBreakerStats, bool) {
+	if c.breaker == nil {
+		return CircuitBreakerStats{}, false
+	}
+	return c.breaker.Stats(), true
+}
+
+// guard makes a Redis call through the circuit breaker
+func (c *CacheService) guard(call func() error) error {
+	_, err := guarded(c, func() (struct{}, error) { return struct{}{}, call() })
+	return err
+}
+
+// guarded makes a Redis call returning a value through the circuit breaker of c. Only failures to reach
+// Redis count against it; cache misses and entries failing to decode do not.
+func guarded[T any](c *CacheService, call func() (T, error)) (T, error) {
+	if c == nil || c.breaker == nil {
+		return call()
+	}
+	if !c.breaker.Allow() {
+		var zero T
+		return zero, echo_errors.ErrCacheUnavailable
+	}
+
+	value, err := call()
+	if redisUnavailable(err) {
+		c.breaker.Failure(err)
+	} else {
+		c.breaker.Success()
+	}
+	return value, err
+}
+
+// redisUnavailable reports whether err means Redis could not be reached or did not answer in time
+func redisUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, redis.ErrClosed) ||
+		strings.Contains(err.Error(), "connection pool timeout")
+}
+
+// SetMany caches entities, which may be of different kinds, in one round trip to Redis. Entities are
+// pointers to models, each cached as its own Set method would cache it.
+func (c *CacheService) SetMany(ctx context.Context, entities ...interface{}) error {
+	return c.guard(func() error { return db.CacheMany(ctx, entities) })
+}
 
-func NewCacheService() *CacheService {
-	return &CacheService{}
+// DeleteMany drops the cached entities of ids, all of one kind such as db.CacheKindResource, in one
+// round trip to Redis
+func (c *CacheService) DeleteMany(ctx context.Context, kind string, ids []string) error {
+	return c.guard(func() error { return db.DeleteCachedMany(ctx, kind, ids) })
 }
 
 func (c *CacheService) GetPolicy(ctx context.Context, policyID string) (*model.Policy, error) {
-	return db.GetCachedPolicy(ctx, policyID)
+	return guarded(c, func() (*model.Policy, error) { return db.GetCachedPolicy(ctx, policyID) })
+}
+
+// GetPolicies returns the cached policies among policyIDs, keyed by ID
+func (c *CacheService) GetPolicies(ctx context.Context, policyIDs []string) (map[string]*model.Policy, error) {
+	return guarded(c, func() (map[string]*model.Policy, error) { return db.GetCachedPolicies(ctx, policyIDs) })
 }
 
 func (c *CacheService) SetPolicy(ctx context.Context, policy model.Policy) error {
-	return db.CachePolicy(ctx, &policy)
+	return c.guard(func() error { return db.CachePolicy(ctx, &policy) })
 }
 
 func (c *CacheService) DeletePolicy(ctx context.Context, policyID string) error {
-	return db.DeleteCachedPolicy(ctx, policyID)
+	return c.guard(func() error { return db.DeleteCachedPolicy(ctx, policyID) })
 }
 
 func (c *CacheService) SetOrganization(ctx context.Context, organization model.Organization) error {
-	return db.CacheOrganization(ctx, &organization)
+	return c.guard(func() error { return db.CacheOrganization(ctx, &organization) })
 }
 
 func (c *CacheService) DeleteOrganization(ctx context.Context, organizationID string) error {
-	return db.DeleteCachedOrganization(ctx, organizationID)
+	return c.guard(func() error { return db.DeleteCachedOrganization(ctx, organizationID) })
 }
 
 func (c *CacheService) GetOrganization(ctx context.Context, organizationID string) (*model.Organization, error) {
-	return db.GetCachedOrganization(ctx, organizationID)
+	return guarded(c, func() (*model.Organization, error) { return db.GetCachedOrganization(ctx, organizationID) })
 }
 
 func (c *CacheService) SetDepartment(ctx context.Context, department model.Department) error {
-	return db.CacheDepartment(ctx, &department)
+	return c.guard(func() error { return db.CacheDepartment(ctx, &department) })
 }
 
 func (c *CacheService) DeleteDepartment(ctx context.Context, departmentID string) error {
-	return db.DeleteCachedDepartment(ctx, departmentID)
+	return c.guard(func() error { return db.DeleteCachedDepartment(ctx, departmentID) })
 }
 
 func (c *CacheService) GetDepartment(ctx context.Context, departmentID string) (*model.Department, error) {
-	return db.GetCachedDepartment(ctx, departmentID)
+	return guarded(c, func() (*model.Department, error) { return db.GetCachedDepartment(ctx, departmentID) })
 }
 
 func (c *CacheService) SetUser(ctx context.Context, user model.User) error {
-	return db.CacheUser(ctx, &user)
+	return c.guard(func() error { return db.CacheUser(ctx, &user) })
 }
 
 func (c *CacheService) DeleteUser(ctx context.Context, userID string) error {
-	return db.DeleteCachedUser(ctx, userID)
+	return c.guard(func() error { return db.DeleteCachedUser(ctx, userID) })
 }
 
 func (c *CacheService) GetUser(ctx context.Context, userID string) (*model.User, error) {
-	return db.GetCachedUser(ctx, userID)
+	return guarded(c, func() (*model.User, error) { return db.GetCachedUser(ctx, userID) })
+}
+
+// GetUsers returns the cached users among userIDs, keyed by ID
+func (c *CacheService) GetUsers(ctx context.Context, userIDs []string) (map[string]*model.User, error) {
+	return guarded(c, func() (map[string]*model.User, error) { return db.GetCachedUsers(ctx, userIDs) })
 }
 
 func (c *CacheService) SetRole(ctx context.Context, role model.Role) error {
-	return db.CacheRole(ctx, &role)
+	return c.guard(func() error { return db.CacheRole(ctx, &role) })
 }
 
 func (c *CacheService) DeleteRole(ctx context.Context, roleID string) error {
-	return db.DeleteCachedRole(ctx, roleID)
+	return c.guard(func() error { return db.DeleteCachedRole(ctx, roleID) })
 }
 
 func (c *CacheService) GetRole(ctx context.Context, roleID string) (*model.Role, error) {
-	return db.GetCachedRole(ctx, roleID)
+	return guarded(c, func() (*model.Role, error) { return db.GetCachedRole(ctx, roleID) })
 }
 
 // SetGroup
 func (c *CacheService) SetGroup(ctx context.Context, group model.Group) error {
-	return db.CacheGroup(ctx, &group)
+	return c.guard(func() error { return db.CacheGroup(ctx, &group) })
 }
 
 // DeleteGroup
 func (c *CacheService) DeleteGroup(ctx context.Context, groupID string) error {
-	return db.DeleteCachedGroup(ctx, groupID)
+	return c.guard(func() error { return db.DeleteCachedGroup(ctx, groupID) })
 }
 
 // GetGroup
 func (c *CacheService) GetGroup(ctx context.Context, groupID string) (*model.Group, error) {
-	return db.GetCachedGroup(ctx, groupID)
+	return guarded(c, func() (*model.Group, error) { return db.GetCachedGroup(ctx, groupID) })
 }
 
 // SetPermission
 func (c *CacheService) SetPermission(ctx context.Context, permission model.Permission) error {
-	return db.CachePermission(ctx, &permission)
+	return c.guard(func() error { return db.CachePermission(ctx, &permission) })
 }
 
 // DeletePermission
 func (c *CacheService) DeletePermission(ctx context.Context, permissionID string) error {
-	return db.DeleteCachedPermission(ctx, permissionID)
+	return c.guard(func() error { return db.DeleteCachedPermission(ctx, permissionID) })
 }
 
 // GetPermission
 func (c *CacheService) GetPermission(ctx context.Context, permissionID string) (*model.Permission, error) {
-	return db.GetCachedPermission(ctx, permissionID)
+	return guarded(c, func() (*model.Permission, error) { return db.GetCachedPermission(ctx, permissionID) })
 }
 
 // SetResource
 func (c *CacheService) SetResource(ctx context.Context, resource model.Resource) error {
-	return db.CacheResource(ctx, &resource)
+	return c.guard(func() error { return db.CacheResource(ctx, &resource) })
 }
 
 // DeleteResource
 func (c *CacheService) DeleteResource(ctx context.Context, resourceID string) error {
-	return db.DeleteCachedResource(ctx, resourceID)
+	return c.guard(func() error { return db.DeleteCachedResource(ctx, resourceID) })
 }
 
 // GetResource
 func (c *CacheService) GetResource(ctx context.Context, resourceID string) (*model.Resource, error) {
-	return db.GetCachedResource(ctx, resourceID)
+	return guarded(c, func() (*model.Resource, error) { return db.GetCachedResource(ctx, resourceID) })
+}
+
+// GetResources returns the cached resources among resourceIDs, keyed by ID
+func (c *CacheService) GetResources(ctx context.Context, resourceIDs []string) (map[string]*model.Resource, error) {
+	return guarded(c, func() (map[string]*model.Resource, error) { return db.GetCachedResources(ctx, resourceIDs) })
 }
 
 // GetResourceType
 func (c *CacheService) GetResourceType(ctx context.Context, resourceTypeID string) (*model.ResourceType, error) {
-	return db.GetCachedResourceType(ctx, resourceTypeID)
+	return guarded(c, func() (*model.ResourceType, error) { return db.GetCachedResourceType(ctx, resourceTypeID) })
 }
 
 // SetResourceType
 func (c *CacheService) SetResourceType(ctx context.Context, resourceType model.ResourceType) error {
-	return db.CacheResourceType(ctx, &resourceType)
+	return c.guard(func() error { return db.CacheResourceType(ctx, &resourceType) })
 }
 
 // DeleteResourceType
 func (c *CacheService) DeleteResourceType(ctx context.Context, resourceTypeID string) error {
-	return db.DeleteCachedResourceType(ctx, resourceTypeID)
+	return c.guard(func() error { return db.DeleteCachedResourceType(ctx, resourceTypeID) })
 }
 
 // SetAttributeGroup
 func (c *CacheService) SetAttributeGroup(ctx context.Context, attributeGroup model.AttributeGroup) error {
-	return db.CacheAttributeGroup(ctx, &attributeGroup)
+	return c.guard(func() error { return db.CacheAttributeGroup(ctx, &attributeGroup) })
 }
 
 // DeleteAttributeGroup
 func (c *CacheService) DeleteAttributeGroup(ctx context.Context, attributeGroupID string) error {
-	return db.DeleteCachedAttributeGroup(ctx, attributeGroupID)
+	return c.guard(func() error { return db.DeleteCachedAttributeGroup(ctx, attributeGroupID) })
 }
 
 // GetAttributeGroup
 func (c *CacheService) GetAttributeGroup(ctx context.Context, attributeGroupID string) (*model.AttributeGroup, error) {
-	return db.GetCachedAttributeGroup(ctx, attributeGroupID)
+	return guarded(c, func() (*model.AttributeGroup, error) { return db.GetCachedAttributeGroup(ctx, attributeGroupID) })
+}
+
+// GetDecision returns the access decision cached under key, or nil when there is none
+func (c *CacheService) GetDecision(ctx context.Context, key string) (*model.AccessDecision, error) {
+	return guarded(c, func() (*model.AccessDecision, error) { return db.GetCachedDecision(ctx, key) })
+}
+
+// SetDecision caches an access decision under key for ttl, indexed under tags for InvalidateDecisions.
+// The indexes live for indexTTL, the longest a decision is ever cached.
+func (c *CacheService) SetDecision(ctx context.Context, key string, decision model.AccessDecision, ttl, indexTTL time.Duration, tags []string) error {
+	return c.guard(func() error { return db.CacheDecision(ctx, key, &decision, ttl, indexTTL, tags) })
+}
+
+// InvalidateDecisions drops every cached access decision indexed under any of tags and returns how many
+// were dropped
+func (c *CacheService) InvalidateDecisions(ctx context.Context, tags ...string) (int, error) {
+	return guarded(c, func() (int, error) { return db.InvalidateCachedDecisions(ctx, tags) })
+}
+
+// Flush drops the entry of kind cached under id, or every entry of kind when id is empty, and returns
+// how many keys were dropped
+func (c *CacheService) Flush(ctx context.Context, kind, id string) (int, error) {
+	return guarded(c, func() (int, error) { return db.FlushCached(ctx, kind, id) })
 }
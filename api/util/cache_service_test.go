@@ -0,0 +1,170 @@
+// api/util/cache_service_test.go
+package util_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestCacheServiceSetMany(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	cacheService := util.NewCacheService(nil)
+
+	t.Run("Every entry is cached", func(t *testing.T) {
+		mock.StartRedisServer(t)
+
+		var entities []interface{}
+		var userIDs, resourceIDs []string
+		for i := 0; i < 20; i++ {
+			user := &model.User{ID: fmt.Sprintf("u%d", i), Name: fmt.Sprintf("User %d", i)}
+			resource := &model.Resource{ID: fmt.Sprintf("r%d", i), Name: fmt.Sprintf("Resource %d", i)}
+			entities = append(entities, user, resource)
+			userIDs = append(userIDs, user.ID)
+			resourceIDs = append(resourceIDs, resource.ID)
+		}
+		entities = append(entities, &model.Role{ID: "role1", Name: "Auditor"})
+
+		require.NoError(t, cacheService.SetMany(ctx, entities...))
+
+		users, err := cacheService.GetUsers(ctx, userIDs)
+		require.NoError(t, err)
+		assert.Len(t, users, 20)
+		assert.Equal(t, "User 7", users["u7"].Name)
+
+		resources, err := cacheService.GetResources(ctx, resourceIDs)
+		require.NoError(t, err)
+		assert.Len(t, resources, 20)
+		assert.Equal(t, "Resource 13", resources["r13"].Name)
+
+		role, err := cacheService.GetRole(ctx, "role1")
+		require.NoError(t, err)
+		assert.Equal(t, "Auditor", role.Name)
+	})
+
+	t.Run("Unsupported entities cache nothing", func(t *testing.T) {
+		server := mock.StartRedisServer(t)
+
+		err := cacheService.SetMany(ctx, &model.User{ID: "u1"}, model.User{ID: "u2"})
+
+		assert.Error(t, err)
+		assert.False(t, server.Has("user:u1"))
+	})
+
+	t.Run("DeleteMany drops every entry", func(t *testing.T) {
+		server := mock.StartRedisServer(t)
+		require.NoError(t, cacheService.SetMany(ctx, &model.Resource{ID: "r1"}, &model.Resource{ID: "r2"}, &model.Resource{ID: "r3"}))
+
+		require.NoError(t, cacheService.DeleteMany(ctx, db.CacheKindResource, []string{"r1", "r3"}))
+
+		assert.False(t, server.Has("resource:r1"))
+		assert.True(t, server.Has("resource:r2"))
+		assert.False(t, server.Has("resource:r3"))
+	})
+}
+
+func TestCacheServiceCircuitBreaker(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	cacheService := util.NewCacheService(util.NewCircuitBreaker("redis", util.CircuitBreakerConfig{
+		FailureThreshold: 3,
+		Cooldown:         50 * time.Millisecond,
+	}))
+
+	// Nothing listens on port 1, so every call fails to reach Redis
+	previousClient := db.RedisClient
+	db.RedisClient = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer func() { db.RedisClient = previousClient }()
+
+	for i := 0; i < 3; i++ {
+		_, err := cacheService.GetPolicy(ctx, "p1")
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, echo_errors.ErrCacheUnavailable))
+	}
+	stats, ok := cacheService.BreakerStats()
+	require.True(t, ok)
+	assert.Equal(t, util.CircuitOpen, stats.State)
+	assert.Equal(t, int64(1), stats.Trips)
+
+	// While open, calls fail at once without reaching Redis
+	_, err := cacheService.GetUser(ctx, "u1")
+	assert.ErrorIs(t, err, echo_errors.ErrCacheUnavailable)
+	assert.ErrorIs(t, cacheService.SetUser(ctx, model.User{ID: "u1"}), echo_errors.ErrCacheUnavailable)
+	stats, _ = cacheService.BreakerStats()
+	assert.Equal(t, int64(2), stats.ShortCircuited)
+
+	// A probe failing after the cooldown opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	_, err = cacheService.GetUser(ctx, "u1")
+	assert.False(t, errors.Is(err, echo_errors.ErrCacheUnavailable))
+	stats, _ = cacheService.BreakerStats()
+	assert.Equal(t, util.CircuitOpen, stats.State)
+	assert.Equal(t, int64(2), stats.Trips)
+
+	// Once Redis is back, the next probe closes the breaker
+	mock.StartRedisServer(t)
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, cacheService.SetUser(ctx, model.User{ID: "u1", Name: "Jane"}))
+	stats, _ = cacheService.BreakerStats()
+	assert.Equal(t, util.CircuitClosed, stats.State)
+	assert.Zero(t, stats.ConsecutiveFailures)
+	assert.Nil(t, stats.OpenedAt)
+
+	user, err := cacheService.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", user.Name)
+}
+
+// BenchmarkCacheServiceSetMany compares caching users one round trip at a time with caching them in one
+// pipelined batch
+func BenchmarkCacheServiceSetMany(b *testing.B) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	cacheService := util.NewCacheService(nil)
+	mock.StartRedisServer(b)
+
+	for _, count := range []int{10, 100} {
+		users := make([]interface{}, count)
+		for i := range users {
+			users[i] = &model.User{ID: fmt.Sprintf("u%d", i), Name: fmt.Sprintf("User %d", i)}
+		}
+
+		b.Run(fmt.Sprintf("Individual/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, user := range users {
+					if err := cacheService.SetUser(ctx, *user.(*model.User)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Pipelined/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				if err := cacheService.SetMany(ctx, users...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
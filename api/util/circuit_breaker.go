@@ -0,0 +1,137 @@
+// api/util/circuit_breaker.go
+
+package util
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"    // Calls go through
+	CircuitOpen     = "open"      // Calls are short-circuited until the cooldown ends
+	CircuitHalfOpen = "half_open" // One probe call goes through to test recovery
+)
+
+// CircuitBreakerConfig tells a circuit breaker when to open and for how long
+type CircuitBreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open the breaker; 0 never opens it
+	Cooldown         time.Duration // How long the breaker stays open before probing
+}
+
+// CircuitBreakerStats is the state of a circuit breaker as reported on the metrics endpoint
+type CircuitBreakerStats struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Trips               int64      `json:"trips"`           // Times the breaker opened
+	ShortCircuited      int64      `json:"short_circuited"` // Calls refused while open
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// CircuitBreaker stops calling a failing dependency once it failed FailureThreshold times in a row.
+// After the cooldown, a single probe call is let through: its success closes the breaker and its
+// failure opens it for another cooldown.
+type CircuitBreaker struct {
+	name   string
+	config CircuitBreakerConfig
+
+	mu             sync.Mutex
+	state          string
+	failures       int
+	openedAt       time.Time
+	probing        bool // A half-open probe is in flight
+	trips          int64
+	shortCircuited int64
+}
+
+// NewCircuitBreaker creates a closed circuit breaker, named after the dependency it guards for logging
+func NewCircuitBreaker(name string, config CircuitBreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{name: name, config: config, state: CircuitClosed}
+}
+
+// Allow reports whether a call may go through. Every allowed call must be followed by Success or
+// Failure.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.config.Cooldown {
+			b.shortCircuited++
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		logger.Info("Circuit breaker probing", zap.String("breaker", b.name))
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			b.shortCircuited++
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success records a call that reached the dependency, closing the breaker
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != CircuitClosed {
+		logger.Info("Circuit breaker closed", zap.String("breaker", b.name))
+	}
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records a call that failed to reach the dependency, opening the breaker once failures reach
+// the threshold or when the half-open probe failed
+func (b *CircuitBreaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.config.FailureThreshold <= 0 {
+		return
+	}
+	if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.failures >= b.config.FailureThreshold) {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+		b.trips++
+		logger.Warn("Circuit breaker opened",
+			zap.String("breaker", b.name),
+			zap.Error(err),
+			zap.Int("consecutiveFailures", b.failures),
+			zap.Duration("cooldown", b.config.Cooldown))
+	}
+}
+
+// Stats returns the current state of the breaker
+func (b *CircuitBreaker) Stats() CircuitBreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := CircuitBreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+		ShortCircuited:      b.shortCircuited,
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
@@ -0,0 +1,62 @@
+// api/util/classification.go
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+)
+
+// classificationLevels is the classification taxonomy, ordered from the least to the most sensitive
+// level. While empty, classifications are free-form.
+var classificationLevels []string
+
+// SetClassificationLevels sets the levels a resource may be classified at, ordered from the least to
+// the most sensitive. An empty taxonomy lifts the restriction.
+func SetClassificationLevels(levels []string) {
+	if len(levels) == 0 {
+		classificationLevels = nil
+		return
+	}
+	classificationLevels = append([]string{}, levels...)
+}
+
+// ClassificationLevels returns the classification taxonomy, ordered from the least to the most
+// sensitive level
+func ClassificationLevels() []string {
+	return append([]string{}, classificationLevels...)
+}
+
+// ClassificationRank returns the position of level in the taxonomy, 0 being the least sensitive, and
+// whether level is part of it
+func ClassificationRank(level string) (int, bool) {
+	for i, known := range classificationLevels {
+		if known == level {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// ClassificationsAtOrAbove returns the levels of the taxonomy as sensitive as level or more. It returns
+// ErrInvalidSearchCriteria for a level outside the taxonomy.
+func ClassificationsAtOrAbove(level string) ([]string, error) {
+	rank, ok := ClassificationRank(level)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown classification level %q", echo_errors.ErrInvalidSearchCriteria, level)
+	}
+	return append([]string{}, classificationLevels[rank:]...), nil
+}
+
+// validateClassification rejects a resource classification or user clearance outside the taxonomy.
+// Unclassified resources and users without a clearance are always valid.
+func validateClassification(invalid *echo_errors.ErrValidation, field string, classification string) {
+	if classification == "" || classificationLevels == nil {
+		return
+	}
+	if _, ok := ClassificationRank(classification); !ok {
+		invalid.Add(field, fmt.Sprintf("%q must be one of %s", classification, strings.Join(classificationLevels, ", ")))
+	}
+}
@@ -0,0 +1,280 @@
+// api/util/email_notifier.go
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// How an EmailNotifier secures its connection to the SMTP server
+const (
+	SMTPTLSNone     = "none"     // Plain text, for local relays only
+	SMTPTLSStartTLS = "starttls" // Upgraded with STARTTLS, usually on port 587
+	SMTPTLSImplicit = "tls"      // TLS from the start, usually on port 465
+)
+
+// EmailTemplate renders the subject and body of a notification email. Both are text/template
+// templates over the Notification, with a join function for lists, such as
+// {{join .EntityIDs ", "}}.
+type EmailTemplate struct {
+	Subject string
+	Body    string
+}
+
+// DefaultEmailTemplate is used for the notification types without a template of their own
+var DefaultEmailTemplate = EmailTemplate{
+	Subject: "[Echo] {{.Message}}",
+	Body:    "{{.Message}}\n\nType: {{.Type}}\nEntities: {{join .EntityIDs \", \"}}\n",
+}
+
+// SMTPConfig is how an EmailNotifier reaches its SMTP server and whom it writes to
+type SMTPConfig struct {
+	Host         string
+	Port         int
+	Username     string // Empty skips authentication
+	Password     string
+	TLS          string // SMTPTLSNone, SMTPTLSStartTLS or SMTPTLSImplicit
+	From         string
+	To           []string
+	Timeout      time.Duration            // For each delivery attempt
+	MaxRetries   int                      // Attempts after the first before an email is given up
+	RetryBackoff time.Duration            // Before the first retry, doubling for each one after
+	QueueSize    int                      // Emails waiting to be sent before new ones are dropped
+	Templates    map[string]EmailTemplate // By notification type, ignoring case
+}
+
+type renderedEmail struct {
+	notificationType string
+	subject          string
+	body             string
+}
+
+// EmailNotifier emails notifications through an SMTP server. Its Send only renders and queues the email,
+// so a slow or unreachable server never holds up the changes being notified about; a background worker
+// delivers the queue, retrying each failed email a bounded number of times.
+type EmailNotifier struct {
+	config    SMTPConfig
+	templates map[string]*emailTemplate // By lowercased notification type
+	fallback  *emailTemplate
+
+	mu     sync.Mutex
+	queue  chan renderedEmail
+	closed bool          // Set by Shutdown, which closes queue
+	done   chan struct{} // Closed once the worker has delivered the queue
+}
+
+type emailTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// NewEmailNotifier creates an EmailNotifier for config and starts its delivery worker. It fails when a
+// template does not parse.
+func NewEmailNotifier(config SMTPConfig) (*EmailNotifier, error) {
+	if config.TLS == "" {
+		config.TLS = SMTPTLSStartTLS
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.QueueSize < 1 {
+		config.QueueSize = 100
+	}
+
+	fallback, err := parseEmailTemplate("default", DefaultEmailTemplate)
+	if err != nil {
+		return nil, err
+	}
+	notifier := &EmailNotifier{
+		config:    config,
+		templates: make(map[string]*emailTemplate, len(config.Templates)),
+		fallback:  fallback,
+		queue:     make(chan renderedEmail, config.QueueSize),
+		done:      make(chan struct{}),
+	}
+	for notificationType, source := range config.Templates {
+		parsed, err := parseEmailTemplate(notificationType, source)
+		if err != nil {
+			return nil, err
+		}
+		notifier.templates[strings.ToLower(notificationType)] = parsed
+	}
+
+	go notifier.deliverQueue()
+	return notifier, nil
+}
+
+func parseEmailTemplate(name string, source EmailTemplate) (*emailTemplate, error) {
+	funcs := template.FuncMap{"join": strings.Join}
+	subject, err := template.New(name + " subject").Funcs(funcs).Parse(source.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email subject template for %s: %w", name, err)
+	}
+	body, err := template.New(name + " body").Funcs(funcs).Parse(source.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email body template for %s: %w", name, err)
+	}
+	return &emailTemplate{subject: subject, body: body}, nil
+}
+
+// Send renders the email for a notification and queues it for delivery. It fails only when the email
+// does not render, the queue is full or the notifier is shut down.
+func (e *EmailNotifier) Send(ctx context.Context, notification Notification) error {
+	tmpl, ok := e.templates[strings.ToLower(notification.Type)]
+	if !ok {
+		tmpl = e.fallback
+	}
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, notification); err != nil {
+		return fmt.Errorf("failed to render email subject: %w", err)
+	}
+	if err := tmpl.body.Execute(&body, notification); err != nil {
+		return fmt.Errorf("failed to render email body: %w", err)
+	}
+
+	email := renderedEmail{
+		notificationType: notification.Type,
+		// A subject is a single header line
+		subject: strings.Join(strings.Fields(subject.String()), " "),
+		body:    body.String(),
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return fmt.Errorf("email notifier is shut down, %s notification dropped", notification.Type)
+	}
+	select {
+	case e.queue <- email:
+		return nil
+	default:
+		return fmt.Errorf("email queue is full, %s notification dropped", notification.Type)
+	}
+}
+
+// Shutdown stops accepting emails and waits for those queued to be delivered or given up. It gives up
+// waiting when ctx is done, returning its error.
+func (e *EmailNotifier) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *EmailNotifier) deliverQueue() {
+	defer close(e.done)
+	for email := range e.queue {
+		backoff := e.config.RetryBackoff
+		for attempt := 0; ; attempt++ {
+			err := e.deliver(email)
+			if err == nil {
+				logger.Debug("Notification email sent", zap.String("type", email.notificationType), zap.Strings("to", e.config.To))
+				break
+			}
+			if attempt >= e.config.MaxRetries {
+				logger.Error("Giving up on notification email", zap.Error(err), zap.String("type", email.notificationType), zap.Int("attempts", attempt+1))
+				break
+			}
+			logger.Warn("Failed to send notification email, retrying", zap.Error(err), zap.String("type", email.notificationType), zap.Duration("backoff", backoff))
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+}
+
+// deliver sends one email in its own SMTP session
+func (e *EmailNotifier) deliver(email renderedEmail) error {
+	if len(e.config.To) == 0 {
+		return errors.New("no email recipients configured")
+	}
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(e.config.Port))
+	tlsConfig := &tls.Config{ServerName: e.config.Host}
+	dialer := &net.Dialer{Timeout: e.config.Timeout}
+
+	var conn net.Conn
+	var err error
+	if e.config.TLS == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(e.config.Timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if e.config.TLS == SMTPTLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if e.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(e.config.From); err != nil {
+		return err
+	}
+	for _, recipient := range e.config.To {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(e.message(email)); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message formats an email as a plain text RFC 5322 message
+func (e *EmailNotifier) message(email renderedEmail) []byte {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(e.config.To, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", email.subject)
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(strings.ReplaceAll(email.body, "\r\n", "\n"), "\n", "\r\n"))
+	return message.Bytes()
+}
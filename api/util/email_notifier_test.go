@@ -0,0 +1,202 @@
+// api/util/email_notifier_test.go
+package util_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// smtpServer is a minimal SMTP server keeping the messages it receives. It turns the first failFirst
+// sessions away, as a busy server would.
+type smtpServer struct {
+	listener  net.Listener
+	failFirst int
+
+	mu       sync.Mutex
+	sessions int
+	messages []string
+}
+
+func startSMTPServer(t *testing.T, failFirst int) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &smtpServer{listener: listener, failFirst: failFirst}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+
+	s.mu.Lock()
+	s.sessions++
+	busy := s.sessions <= s.failFirst
+	s.mu.Unlock()
+	if busy {
+		text.PrintfLine("421 Service not available")
+		return
+	}
+
+	text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		switch command := strings.ToUpper(strings.Fields(line + " ")[0]); command {
+		case "EHLO", "HELO":
+			text.PrintfLine("250 localhost")
+		case "MAIL", "RCPT":
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, string(data))
+			s.mu.Unlock()
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func (s *smtpServer) config() util.SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNumber, _ := net.LookupPort("tcp", port)
+	return util.SMTPConfig{
+		Host:         host,
+		Port:         portNumber,
+		TLS:          util.SMTPTLSNone,
+		From:         "echo@example.com",
+		To:           []string{"security@example.com"},
+		Timeout:      time.Second,
+		MaxRetries:   2,
+		RetryBackoff: 10 * time.Millisecond,
+	}
+}
+
+// header reads the value of a header of a received message
+func header(t *testing.T, message, name string) string {
+	headers, err := textproto.NewReader(bufio.NewReader(strings.NewReader(message))).ReadMIMEHeader()
+	require.NoError(t, err)
+	return headers.Get(name)
+}
+
+func TestEmailNotifier(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	t.Run("RendersTemplatePerType", func(t *testing.T) {
+		server := startSMTPServer(t, 0)
+		config := server.config()
+		config.Templates = map[string]util.EmailTemplate{
+			"resource.deleted": {
+				Subject: "Deleted: {{.Message}}",
+				Body:    "{{.Count}} resource(s) deleted: {{join .EntityIDs \", \"}}\nPlease review.",
+			},
+		}
+		notifier, err := util.NewEmailNotifier(config)
+		require.NoError(t, err)
+
+		assert.NoError(t, notifier.Send(ctx, util.Notification{Type: "resource.deleted", Message: "Resource r1 deleted", EntityIDs: []string{"r1"}, Count: 1}))
+		assert.NoError(t, notifier.Shutdown(ctx))
+
+		messages := server.received()
+		if assert.Len(t, messages, 1) {
+			assert.Equal(t, "Deleted: Resource r1 deleted", header(t, messages[0], "Subject"))
+			assert.Equal(t, "security@example.com", header(t, messages[0], "To"))
+			assert.Contains(t, messages[0], "\n\n1 resource(s) deleted: r1\nPlease review.")
+		}
+	})
+
+	t.Run("DefaultTemplate", func(t *testing.T) {
+		server := startSMTPServer(t, 0)
+		notifier, err := util.NewEmailNotifier(server.config())
+		require.NoError(t, err)
+
+		assert.NoError(t, notifier.Send(ctx, util.Notification{Type: "policy.updated", Message: "12 policies updated", EntityIDs: []string{"p1", "p2"}, Count: 12}))
+		assert.NoError(t, notifier.Shutdown(ctx))
+
+		messages := server.received()
+		if assert.Len(t, messages, 1) {
+			assert.Equal(t, "[Echo] 12 policies updated", header(t, messages[0], "Subject"))
+			assert.Contains(t, messages[0], "Entities: p1, p2")
+		}
+	})
+
+	t.Run("RetriesFailedSend", func(t *testing.T) {
+		server := startSMTPServer(t, 2)
+		notifier, err := util.NewEmailNotifier(server.config())
+		require.NoError(t, err)
+
+		assert.NoError(t, notifier.Send(ctx, util.Notification{Type: "user.created", Message: `User "Jane" created`}))
+		assert.NoError(t, notifier.Shutdown(ctx))
+
+		assert.Len(t, server.received(), 1)
+	})
+
+	t.Run("SendDoesNotWaitForServer", func(t *testing.T) {
+		// Accepts connections but never greets, like a server too slow to answer
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		config := (&smtpServer{listener: listener}).config()
+		config.Timeout = 200 * time.Millisecond
+		notifier, err := util.NewEmailNotifier(config)
+		require.NoError(t, err)
+
+		start := time.Now()
+		assert.NoError(t, notifier.Send(ctx, util.Notification{Type: "role.created", Message: `Role "Auditor" created`}))
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, notifier.Shutdown(shutdownCtx), context.DeadlineExceeded)
+
+		// With the server gone, the retries fail fast and the email is given up
+		listener.Close()
+		assert.NoError(t, notifier.Shutdown(ctx))
+	})
+
+	t.Run("InvalidTemplate", func(t *testing.T) {
+		_, err := util.NewEmailNotifier(util.SMTPConfig{Templates: map[string]util.EmailTemplate{"user.created": {Subject: "{{.Message"}}})
+
+		assert.Error(t, err)
+	})
+}
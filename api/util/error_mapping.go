@@ -0,0 +1,190 @@
+// api/util/error_mapping.go
+package util
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// Codes of failures no sentinel error describes
+const (
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInternalError    = "INTERNAL_ERROR"
+)
+
+// ErrorBody is what every failed request answers with under "error": a stable code clients can switch
+// on and a human-readable message, plus the offending fields when validation failed
+type ErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// ErrorResponse is the envelope of every failed request
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// NewErrorResponse returns the envelope of a failed request
+func NewErrorResponse(code string, message string) ErrorResponse {
+	return ErrorResponse{Error: ErrorBody{Code: code, Message: message}}
+}
+
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings ties each sentinel error to the status and code it is reported with. An error whose
+// chain holds several sentinels is reported as the first of them in this order.
+var errorMappings = []errorMapping{
+	{echo_errors.ErrPolicyNotFound, http.StatusNotFound, "POLICY_NOT_FOUND"},
+	{echo_errors.ErrPolicyTemplateNotFound, http.StatusNotFound, "POLICY_TEMPLATE_NOT_FOUND"},
+	{echo_errors.ErrResourceNotFound, http.StatusNotFound, "RESOURCE_NOT_FOUND"},
+	{echo_errors.ErrResourceVersionNotFound, http.StatusNotFound, "RESOURCE_VERSION_NOT_FOUND"},
+	{echo_errors.ErrResourceTypeNotFound, http.StatusNotFound, "RESOURCE_TYPE_NOT_FOUND"},
+	{echo_errors.ErrAttributeGroupNotFound, http.StatusNotFound, "ATTRIBUTE_GROUP_NOT_FOUND"},
+	{echo_errors.ErrParentResourceNotFound, http.StatusNotFound, "PARENT_RESOURCE_NOT_FOUND"},
+	{echo_errors.ErrRelatedResourceNotFound, http.StatusNotFound, "RELATED_RESOURCE_NOT_FOUND"},
+	{echo_errors.ErrUserNotFound, http.StatusNotFound, "USER_NOT_FOUND"},
+	{echo_errors.ErrAdminScopeNotFound, http.StatusNotFound, "ADMIN_SCOPE_NOT_FOUND"},
+	{echo_errors.ErrOwnerNotFound, http.StatusNotFound, "OWNER_NOT_FOUND"},
+	{echo_errors.ErrOrganizationNotFound, http.StatusNotFound, "ORGANIZATION_NOT_FOUND"},
+	{echo_errors.ErrDepartmentNotFound, http.StatusNotFound, "DEPARTMENT_NOT_FOUND"},
+	{echo_errors.ErrRoleNotFound, http.StatusNotFound, "ROLE_NOT_FOUND"},
+	{echo_errors.ErrGroupNotFound, http.StatusNotFound, "GROUP_NOT_FOUND"},
+	{echo_errors.ErrPermissionNotFound, http.StatusNotFound, "PERMISSION_NOT_FOUND"},
+	{echo_errors.ErrSoDRuleNotFound, http.StatusNotFound, "SOD_RULE_NOT_FOUND"},
+	{echo_errors.ErrJobNotFound, http.StatusNotFound, "JOB_NOT_FOUND"},
+
+	{echo_errors.ErrPriorityConflict, http.StatusConflict, "PRIORITY_CONFLICT"},
+	{echo_errors.ErrPolicyConflict, http.StatusConflict, "POLICY_CONFLICT"},
+	{echo_errors.ErrResourceConflict, http.StatusConflict, "RESOURCE_CONFLICT"},
+	{echo_errors.ErrResourceTypeConflict, http.StatusConflict, "RESOURCE_TYPE_CONFLICT"},
+	{echo_errors.ErrAttributeGroupConflict, http.StatusConflict, "ATTRIBUTE_GROUP_CONFLICT"},
+	{echo_errors.ErrAttributeGroupInUse, http.StatusConflict, "ATTRIBUTE_GROUP_IN_USE"},
+	{echo_errors.ErrUserConflict, http.StatusConflict, "USER_CONFLICT"},
+	{echo_errors.ErrOrganizationConflict, http.StatusConflict, "ORGANIZATION_CONFLICT"},
+	{echo_errors.ErrOrganizationInUse, http.StatusConflict, "ORGANIZATION_IN_USE"},
+	{echo_errors.ErrOrganizationCycle, http.StatusConflict, "ORGANIZATION_CYCLE"},
+	{echo_errors.ErrDepartmentConflict, http.StatusConflict, "DEPARTMENT_CONFLICT"},
+	{echo_errors.ErrDepartmentHasDependents, http.StatusConflict, "DEPARTMENT_HAS_DEPENDENTS"},
+	{echo_errors.ErrRoleConflict, http.StatusConflict, "ROLE_CONFLICT"},
+	{echo_errors.ErrRoleInUse, http.StatusConflict, "ROLE_IN_USE"},
+	{echo_errors.ErrGroupConflict, http.StatusConflict, "GROUP_CONFLICT"},
+	{echo_errors.ErrGroupCycle, http.StatusConflict, "GROUP_CYCLE"},
+	{echo_errors.ErrPermissionConflict, http.StatusConflict, "PERMISSION_CONFLICT"},
+	{echo_errors.ErrInvalidUserStatusTransition, http.StatusConflict, "INVALID_USER_STATUS_TRANSITION"},
+	{echo_errors.ErrInvalidPolicyStatusTransition, http.StatusConflict, "INVALID_POLICY_STATUS_TRANSITION"},
+	{echo_errors.ErrSoDRuleConflict, http.StatusConflict, "SOD_RULE_CONFLICT"},
+	{echo_errors.ErrSoDViolation, http.StatusConflict, "SOD_VIOLATION"},
+	{echo_errors.ErrConstraintViolation, http.StatusConflict, "CONSTRAINT_VIOLATION"},
+
+	{echo_errors.ErrInvalidPolicyData, http.StatusBadRequest, "INVALID_POLICY_DATA"},
+	{echo_errors.ErrMissingTemplateVariables, http.StatusBadRequest, "MISSING_TEMPLATE_VARIABLES"},
+	{echo_errors.ErrInvalidResourceData, http.StatusBadRequest, "INVALID_RESOURCE_DATA"},
+	{echo_errors.ErrInvalidResourceType, http.StatusBadRequest, "INVALID_RESOURCE_TYPE"},
+	{echo_errors.ErrInvalidResourceTypeData, http.StatusBadRequest, "INVALID_RESOURCE_TYPE_DATA"},
+	{echo_errors.ErrInvalidAttributeGroupData, http.StatusBadRequest, "INVALID_ATTRIBUTE_GROUP_DATA"},
+	{echo_errors.ErrAttributeSchemaViolation, http.StatusBadRequest, "ATTRIBUTE_SCHEMA_VIOLATION"},
+	{echo_errors.ErrBulkLimitExceeded, http.StatusBadRequest, "BULK_LIMIT_EXCEEDED"},
+	{echo_errors.ErrInvalidUserData, http.StatusBadRequest, "INVALID_USER_DATA"},
+	{echo_errors.ErrInvalidUserStatus, http.StatusBadRequest, "INVALID_USER_STATUS"},
+	{echo_errors.ErrInvalidAdminScope, http.StatusBadRequest, "INVALID_ADMIN_SCOPE"},
+	{echo_errors.ErrInvalidOrganizationData, http.StatusBadRequest, "INVALID_ORGANIZATION_DATA"},
+	{echo_errors.ErrInvalidOrgDeleteMode, http.StatusBadRequest, "INVALID_ORGANIZATION_DELETE_MODE"},
+	{echo_errors.ErrInvalidDeptDeleteMode, http.StatusBadRequest, "INVALID_DEPARTMENT_DELETE_MODE"},
+	{echo_errors.ErrInvalidDepartmentData, http.StatusBadRequest, "INVALID_DEPARTMENT_DATA"},
+	{echo_errors.ErrInvalidRoleData, http.StatusBadRequest, "INVALID_ROLE_DATA"},
+	{echo_errors.ErrInvalidGroupData, http.StatusBadRequest, "INVALID_GROUP_DATA"},
+	{echo_errors.ErrInvalidPermissionData, http.StatusBadRequest, "INVALID_PERMISSION_DATA"},
+	{echo_errors.ErrInvalidAccessRequest, http.StatusBadRequest, "INVALID_ACCESS_REQUEST"},
+	{echo_errors.ErrInvalidSoDRuleData, http.StatusBadRequest, "INVALID_SOD_RULE_DATA"},
+	{echo_errors.ErrInvalidPagination, http.StatusBadRequest, "INVALID_PAGINATION"},
+	{echo_errors.ErrInvalidSearchCriteria, http.StatusBadRequest, "INVALID_SEARCH_CRITERIA"},
+
+	{echo_errors.ErrUnauthorized, http.StatusUnauthorized, "UNAUTHORIZED"},
+	{echo_errors.ErrForbidden, http.StatusForbidden, "FORBIDDEN"},
+
+	{echo_errors.ErrServiceReadOnly, http.StatusServiceUnavailable, "SERVICE_READ_ONLY"},
+	{echo_errors.ErrPolicyBundleUnavailable, http.StatusServiceUnavailable, "POLICY_BUNDLE_UNAVAILABLE"},
+	{echo_errors.ErrJobQueueFull, http.StatusServiceUnavailable, "JOB_QUEUE_FULL"},
+	{echo_errors.ErrTransient, http.StatusServiceUnavailable, "DATABASE_UNAVAILABLE"},
+
+	{echo_errors.ErrSyntax, http.StatusInternalServerError, "DATABASE_QUERY_ERROR"},
+	{echo_errors.ErrDatabaseOperation, http.StatusInternalServerError, "DATABASE_ERROR"},
+	{echo_errors.ErrInternalServer, http.StatusInternalServerError, CodeInternalError},
+}
+
+// MapError returns the status and code an error is reported with. Validation errors carrying field
+// details are reported as VALIDATION_FAILED; other sentinels are matched with errors.Is, so they are
+// recognized through any wrapping. Errors that carry none of them are internal errors.
+func MapError(err error) (status int, code string) {
+	if _, ok := validationDetails(err); ok {
+		return http.StatusBadRequest, CodeValidationFailed
+	}
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.err) {
+			return mapping.status, mapping.code
+		}
+	}
+	return http.StatusInternalServerError, CodeInternalError
+}
+
+// RespondWithMappedError answers a failed request with the status and code MapError picks for err.
+// Client errors carry err's message; server errors carry a generic one so internals do not leak.
+func RespondWithMappedError(c *gin.Context, err error) {
+	status, code := MapError(err)
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		message = "Internal server error"
+	}
+	details, _ := validationDetails(err)
+	respond(c, status, ErrorBody{Code: code, Message: message, Details: details}, err)
+}
+
+// validationDetails recovers the invalid fields of a validation error from anywhere in err's chain
+func validationDetails(err error) ([]FieldError, bool) {
+	var validationErr *echo_errors.ErrValidation
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields, true
+	}
+	var schemaErr *SchemaValidationError
+	if errors.As(err, &schemaErr) {
+		return schemaErr.Fields, true
+	}
+	return nil, false
+}
+
+// errorCode returns the code of a response the caller chose the status of: err's own code when it maps
+// to that status, otherwise one named after the status
+func errorCode(status int, err error) string {
+	if err != nil {
+		if mappedStatus, code := MapError(err); mappedStatus == status {
+			return code
+		}
+	}
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+// respond logs a failed request, client errors as warnings, and writes its envelope
+func respond(c *gin.Context, status int, body ErrorBody, err error) {
+	log := logger.Error
+	if status < http.StatusInternalServerError {
+		log = logger.Warn
+	}
+	log(body.Message,
+		zap.Error(err),
+		zap.String("code", body.Code),
+		zap.String("path", c.Request.URL.Path),
+		zap.String("method", c.Request.Method))
+	c.JSON(status, ErrorResponse{Error: body})
+}
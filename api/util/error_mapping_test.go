@@ -0,0 +1,130 @@
+package util_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{echo_errors.ErrPolicyNotFound, http.StatusNotFound, "POLICY_NOT_FOUND"},
+		{echo_errors.ErrPolicyTemplateNotFound, http.StatusNotFound, "POLICY_TEMPLATE_NOT_FOUND"},
+		{echo_errors.ErrResourceNotFound, http.StatusNotFound, "RESOURCE_NOT_FOUND"},
+		{echo_errors.ErrResourceTypeNotFound, http.StatusNotFound, "RESOURCE_TYPE_NOT_FOUND"},
+		{echo_errors.ErrAttributeGroupNotFound, http.StatusNotFound, "ATTRIBUTE_GROUP_NOT_FOUND"},
+		{echo_errors.ErrParentResourceNotFound, http.StatusNotFound, "PARENT_RESOURCE_NOT_FOUND"},
+		{echo_errors.ErrRelatedResourceNotFound, http.StatusNotFound, "RELATED_RESOURCE_NOT_FOUND"},
+		{echo_errors.ErrUserNotFound, http.StatusNotFound, "USER_NOT_FOUND"},
+		{echo_errors.ErrOwnerNotFound, http.StatusNotFound, "OWNER_NOT_FOUND"},
+		{echo_errors.ErrOrganizationNotFound, http.StatusNotFound, "ORGANIZATION_NOT_FOUND"},
+		{echo_errors.ErrDepartmentNotFound, http.StatusNotFound, "DEPARTMENT_NOT_FOUND"},
+		{echo_errors.ErrRoleNotFound, http.StatusNotFound, "ROLE_NOT_FOUND"},
+		{echo_errors.ErrGroupNotFound, http.StatusNotFound, "GROUP_NOT_FOUND"},
+		{echo_errors.ErrPermissionNotFound, http.StatusNotFound, "PERMISSION_NOT_FOUND"},
+		{echo_errors.ErrSoDRuleNotFound, http.StatusNotFound, "SOD_RULE_NOT_FOUND"},
+		{echo_errors.ErrPolicyConflict, http.StatusConflict, "POLICY_CONFLICT"},
+		{echo_errors.ErrResourceConflict, http.StatusConflict, "RESOURCE_CONFLICT"},
+		{echo_errors.ErrAttributeGroupConflict, http.StatusConflict, "ATTRIBUTE_GROUP_CONFLICT"},
+		{echo_errors.ErrAttributeGroupInUse, http.StatusConflict, "ATTRIBUTE_GROUP_IN_USE"},
+		{echo_errors.ErrUserConflict, http.StatusConflict, "USER_CONFLICT"},
+		{echo_errors.ErrOrganizationConflict, http.StatusConflict, "ORGANIZATION_CONFLICT"},
+		{echo_errors.ErrOrganizationInUse, http.StatusConflict, "ORGANIZATION_IN_USE"},
+		{echo_errors.ErrDepartmentConflict, http.StatusConflict, "DEPARTMENT_CONFLICT"},
+		{echo_errors.ErrRoleConflict, http.StatusConflict, "ROLE_CONFLICT"},
+		{echo_errors.ErrRoleInUse, http.StatusConflict, "ROLE_IN_USE"},
+		{echo_errors.ErrGroupConflict, http.StatusConflict, "GROUP_CONFLICT"},
+		{echo_errors.ErrGroupCycle, http.StatusConflict, "GROUP_CYCLE"},
+		{echo_errors.ErrPermissionConflict, http.StatusConflict, "PERMISSION_CONFLICT"},
+		{echo_errors.ErrInvalidUserStatusTransition, http.StatusConflict, "INVALID_USER_STATUS_TRANSITION"},
+		{echo_errors.ErrSoDRuleConflict, http.StatusConflict, "SOD_RULE_CONFLICT"},
+		{echo_errors.ErrSoDViolation, http.StatusConflict, "SOD_VIOLATION"},
+		{echo_errors.ErrInvalidPolicyData, http.StatusBadRequest, "INVALID_POLICY_DATA"},
+		{echo_errors.ErrMissingTemplateVariables, http.StatusBadRequest, "MISSING_TEMPLATE_VARIABLES"},
+		{echo_errors.ErrInvalidResourceData, http.StatusBadRequest, "INVALID_RESOURCE_DATA"},
+		{echo_errors.ErrInvalidResourceType, http.StatusBadRequest, "INVALID_RESOURCE_TYPE"},
+		{echo_errors.ErrInvalidResourceTypeData, http.StatusBadRequest, "INVALID_RESOURCE_TYPE_DATA"},
+		{echo_errors.ErrInvalidAttributeGroupData, http.StatusBadRequest, "INVALID_ATTRIBUTE_GROUP_DATA"},
+		{echo_errors.ErrAttributeSchemaViolation, http.StatusBadRequest, "ATTRIBUTE_SCHEMA_VIOLATION"},
+		{echo_errors.ErrBulkLimitExceeded, http.StatusBadRequest, "BULK_LIMIT_EXCEEDED"},
+		{echo_errors.ErrInvalidUserData, http.StatusBadRequest, "INVALID_USER_DATA"},
+		{echo_errors.ErrInvalidUserStatus, http.StatusBadRequest, "INVALID_USER_STATUS"},
+		{echo_errors.ErrInvalidOrganizationData, http.StatusBadRequest, "INVALID_ORGANIZATION_DATA"},
+		{echo_errors.ErrInvalidOrgDeleteMode, http.StatusBadRequest, "INVALID_ORGANIZATION_DELETE_MODE"},
+		{echo_errors.ErrInvalidDepartmentData, http.StatusBadRequest, "INVALID_DEPARTMENT_DATA"},
+		{echo_errors.ErrInvalidRoleData, http.StatusBadRequest, "INVALID_ROLE_DATA"},
+		{echo_errors.ErrInvalidGroupData, http.StatusBadRequest, "INVALID_GROUP_DATA"},
+		{echo_errors.ErrInvalidPermissionData, http.StatusBadRequest, "INVALID_PERMISSION_DATA"},
+		{echo_errors.ErrInvalidAccessRequest, http.StatusBadRequest, "INVALID_ACCESS_REQUEST"},
+		{echo_errors.ErrInvalidSoDRuleData, http.StatusBadRequest, "INVALID_SOD_RULE_DATA"},
+		{echo_errors.ErrInvalidPagination, http.StatusBadRequest, "INVALID_PAGINATION"},
+		{echo_errors.ErrInvalidSearchCriteria, http.StatusBadRequest, "INVALID_SEARCH_CRITERIA"},
+		{echo_errors.ErrUnauthorized, http.StatusUnauthorized, "UNAUTHORIZED"},
+		{echo_errors.ErrForbidden, http.StatusForbidden, "FORBIDDEN"},
+		{echo_errors.ErrDatabaseOperation, http.StatusInternalServerError, "DATABASE_ERROR"},
+		{echo_errors.ErrInternalServer, http.StatusInternalServerError, util.CodeInternalError},
+		{&util.SchemaValidationError{}, http.StatusBadRequest, util.CodeValidationFailed},
+		{errors.New("connection reset"), http.StatusInternalServerError, util.CodeInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code+"/"+tt.err.Error(), func(t *testing.T) {
+			status, code := util.MapError(tt.err)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, code)
+
+			// Services wrap the sentinels they return
+			status, code = util.MapError(fmt.Errorf("failed to handle request: %w", tt.err))
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, code)
+		})
+	}
+}
+
+func TestRespondWithMappedError(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+	gin.SetMode(gin.TestMode)
+
+	respond := func(err error) (*httptest.ResponseRecorder, util.ErrorResponse) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/policies/p1", nil)
+
+		util.RespondWithMappedError(c, err)
+
+		var response util.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+
+	t.Run("Client errors carry their message", func(t *testing.T) {
+		w, response := respond(fmt.Errorf("failed to update policy: %w", echo_errors.ErrPolicyNotFound))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "POLICY_NOT_FOUND", response.Error.Code)
+		assert.Equal(t, "failed to update policy: policy not found", response.Error.Message)
+	})
+
+	t.Run("Server errors hide their message", func(t *testing.T) {
+		w, response := respond(fmt.Errorf("query failed on 10.0.0.7: %w", echo_errors.ErrDatabaseOperation))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "DATABASE_ERROR", response.Error.Code)
+		assert.Equal(t, "Internal server error", response.Error.Message)
+	})
+}
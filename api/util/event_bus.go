@@ -6,16 +6,20 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
 	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
 // Event represents an event in the system
 type Event struct {
-	Type    string
-	Payload interface{}
+	Type       string
+	Payload    interface{}
+	Actor      string    // User whose request published the event; empty for background work
+	OccurredAt time.Time // When the event was published
 }
 
 // EventHandler is a function that handles an event
@@ -26,6 +30,8 @@ type EventBus struct {
 	subscribers map[string][]EventHandler
 	mu          sync.RWMutex
 	errorChan   chan error
+	inFlight    sync.WaitGroup // Handlers still running
+	closed      bool           // Set by Shutdown; later events are dropped
 }
 
 // NewEventBus creates a new EventBus
@@ -47,20 +53,29 @@ func (eb *EventBus) Subscribe(eventType string, handler EventHandler) {
 // Publish sends an event to all subscribers
 func (eb *EventBus) Publish(ctx context.Context, eventType string, payload interface{}) {
 	eb.mu.RLock()
-	handlers, exists := eb.subscribers[eventType]
-	eb.mu.RUnlock()
+	defer eb.mu.RUnlock()
 
+	if eb.closed {
+		logger.Warn("Event published after shutdown dropped", zap.String("eventType", eventType))
+		return
+	}
+	handlers, exists := eb.subscribers[eventType]
 	if !exists {
 		return
 	}
 
+	actor := helper_util.ActorFromContext(ctx)
 	event := Event{
-		Type:    eventType,
-		Payload: payload,
+		Type:       eventType,
+		Payload:    payload,
+		Actor:      actor,
+		OccurredAt: time.Now().UTC(),
 	}
 
+	eb.inFlight.Add(len(handlers))
 	for _, handler := range handlers {
 		go func(h EventHandler) {
+			defer eb.inFlight.Done()
 			if err := h(ctx, event); err != nil {
 				select {
 				case eb.errorChan <- fmt.Errorf("event handler error: %w", err):
@@ -80,6 +95,36 @@ func (eb *EventBus) Start(ctx context.Context) {
 	go eb.processErrors(ctx)
 }
 
+// Shutdown stops accepting events and waits for the handlers of those already published, logging any
+// errors they report. It gives up when ctx is done, returning its error.
+func (eb *EventBus) Shutdown(ctx context.Context) error {
+	eb.mu.Lock()
+	eb.closed = true
+	eb.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		eb.inFlight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	for {
+		select {
+		case handlerErr := <-eb.errorChan:
+			logger.Error("Event handler error", zap.Error(handlerErr))
+		default:
+			return err
+		}
+	}
+}
+
 // processErrors handles errors from event handlers
 func (eb *EventBus) processErrors(ctx context.Context) {
 	for {
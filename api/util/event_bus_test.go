@@ -0,0 +1,58 @@
+// api/util/event_bus_test.go
+package util_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestEventBusShutdown(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	t.Run("DrainsPublishedEvents", func(t *testing.T) {
+		eventBus := util.NewEventBus()
+		var handled atomic.Int32
+		eventBus.Subscribe("policy.updated", func(ctx context.Context, event util.Event) error {
+			time.Sleep(50 * time.Millisecond)
+			handled.Add(1)
+			return nil
+		})
+
+		for i := 0; i < 3; i++ {
+			eventBus.Publish(context.Background(), "policy.updated", i)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		assert.NoError(t, eventBus.Shutdown(ctx))
+		assert.Equal(t, int32(3), handled.Load())
+
+		eventBus.Publish(context.Background(), "policy.updated", 3)
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, int32(3), handled.Load())
+	})
+
+	t.Run("GivesUpAfterTimeout", func(t *testing.T) {
+		eventBus := util.NewEventBus()
+		release := make(chan struct{})
+		defer close(release)
+		eventBus.Subscribe("policy.updated", func(ctx context.Context, event util.Event) error {
+			<-release
+			return nil
+		})
+
+		eventBus.Publish(context.Background(), "policy.updated", nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, eventBus.Shutdown(ctx), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,103 @@
+// api/util/glob.go
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A glob pattern, such as "project/*" or "read?", matches values the way a shell does: "*" stands for
+// any run of characters, "?" for any one character, and a backslash makes the character after it
+// literal, so "\*" only matches "*".
+
+// globToken is one element of a parsed pattern: a literal character or a wildcard
+type globToken struct {
+	char     rune
+	wildcard rune // '*' or '?' for a wildcard, zero for a literal
+}
+
+func parseGlob(pattern string) []globToken {
+	var tokens []globToken
+	escaped := false
+	for _, char := range pattern {
+		switch {
+		case escaped:
+			tokens = append(tokens, globToken{char: char})
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '*' || char == '?':
+			tokens = append(tokens, globToken{wildcard: char})
+		default:
+			tokens = append(tokens, globToken{char: char})
+		}
+	}
+	// A trailing backslash escapes nothing and stands for itself
+	if escaped {
+		tokens = append(tokens, globToken{char: '\\'})
+	}
+	return tokens
+}
+
+// GlobMatch reports whether value matches the glob pattern
+func GlobMatch(pattern, value string) bool {
+	tokens := parseGlob(pattern)
+	chars := []rune(value)
+
+	// On a mismatch, the last "*" seen takes one more character and matching resumes after it
+	t, c := 0, 0
+	starToken, starChar := -1, 0
+	for c < len(chars) {
+		switch {
+		case t < len(tokens) && tokens[t].wildcard == '*':
+			starToken, starChar = t, c
+			t++
+		case t < len(tokens) && (tokens[t].wildcard == '?' || (tokens[t].wildcard == 0 && tokens[t].char == chars[c])):
+			t++
+			c++
+		case starToken >= 0:
+			starChar++
+			t, c = starToken+1, starChar
+		default:
+			return false
+		}
+	}
+	for t < len(tokens) && tokens[t].wildcard == '*' {
+		t++
+	}
+	return t == len(tokens)
+}
+
+// GlobLiteral returns the one value the pattern matches, unescaped, and false when it holds a wildcard
+func GlobLiteral(pattern string) (string, bool) {
+	var literal strings.Builder
+	for _, token := range parseGlob(pattern) {
+		if token.wildcard != 0 {
+			return "", false
+		}
+		literal.WriteRune(token.char)
+	}
+	return literal.String(), true
+}
+
+// GlobPrefix returns the literal start of the pattern, unescaped, up to its first wildcard. Every value
+// the pattern matches starts with it.
+func GlobPrefix(pattern string) string {
+	var prefix strings.Builder
+	for _, token := range parseGlob(pattern) {
+		if token.wildcard != 0 {
+			break
+		}
+		prefix.WriteRune(token.char)
+	}
+	return prefix.String()
+}
+
+// ValidateGlob returns an error for a pattern ending with a backslash that escapes nothing
+func ValidateGlob(pattern string) error {
+	trailing := len(pattern) - len(strings.TrimRight(pattern, `\`))
+	if trailing%2 == 1 {
+		return fmt.Errorf("pattern %q ends with an unescaped backslash", pattern)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// api/util/glob_test.go
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		value   string
+		matches bool
+	}{
+		{"read", "read", true},
+		{"read", "reads", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"project/*", "project/alpha", true},
+		{"project/*", "project/", true},
+		{"project/*", "team/alpha", false},
+		{"*/plan", "project/alpha/plan", true},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b*c", "aXbYbZ", false},
+		{"rea?", "read", true},
+		{"rea?", "rea", false},
+		{`\*`, "*", true},
+		{`\*`, "x", false},
+		{`re\?d`, "re?d", true},
+		{`re\?d`, "read", false},
+		{`a\\b`, `a\b`, true},
+		{`trailing\`, `trailing\`, true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.matches, util.GlobMatch(c.pattern, c.value), "%q against %q", c.pattern, c.value)
+	}
+}
+
+func TestGlobParts(t *testing.T) {
+	literal, ok := util.GlobLiteral(`re\*ad`)
+	assert.True(t, ok)
+	assert.Equal(t, "re*ad", literal)
+
+	_, ok = util.GlobLiteral("re*ad")
+	assert.False(t, ok)
+
+	assert.Equal(t, "project/", util.GlobPrefix("project/*/plan"))
+	assert.Equal(t, "", util.GlobPrefix("?"))
+
+	assert.NoError(t, util.ValidateGlob(`a\\`))
+	assert.Error(t, util.ValidateGlob(`a\`))
+}
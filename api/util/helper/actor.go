@@ -0,0 +1,21 @@
+package helper_util
+
+import (
+	"context"
+)
+
+// ActorContextKey is the key the authentication middleware stores the requesting user's ID under. It
+// is a plain string so that gin contexts, whose keys are strings, answer it too.
+const ActorContextKey = "requestingUserID"
+
+// WithActor returns a copy of ctx acting on behalf of userID, for changes made outside a request such
+// as the bootstrap command's
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, ActorContextKey, userID)
+}
+
+// ActorFromContext returns the ID of the user ctx acts on behalf of, and "" outside a request
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(ActorContextKey).(string)
+	return actor
+}
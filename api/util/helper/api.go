@@ -7,7 +7,7 @@ import (
 )
 
 func GetPaginationParams(c *gin.Context) (limit int, offset int, err error) {
-	limit, err = strconv.Atoi(c.DefaultQuery("limit", "10"))
+	limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultPageLimit)))
 	if err != nil {
 		return 0, 0, err
 	}
@@ -15,5 +15,5 @@ func GetPaginationParams(c *gin.Context) (limit int, offset int, err error) {
 	if err != nil {
 		return 0, 0, err
 	}
-	return limit, offset, nil
+	return ClampPagination(limit, offset)
 }
@@ -0,0 +1,35 @@
+package helper_util_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+func TestClientIP(t *testing.T) {
+	defer helper_util.SetTrustedProxies(nil)
+
+	request := httptest.NewRequest("GET", "/api/v1/access/evaluate", nil)
+	request.RemoteAddr = "10.0.0.5:51234"
+	request.Header.Set("X-Forwarded-For", "192.168.1.20, 203.0.113.7")
+
+	t.Run("Spoofed header without trusted proxies", func(t *testing.T) {
+		assert.NoError(t, helper_util.SetTrustedProxies(nil))
+
+		assert.Equal(t, "10.0.0.5", helper_util.ClientIP(request))
+	})
+
+	t.Run("Header appended by a trusted proxy", func(t *testing.T) {
+		assert.NoError(t, helper_util.SetTrustedProxies([]string{"10.0.0.0/8"}))
+
+		// 192.168.1.20 was sent by the client itself; only the proxy's entry is believed
+		assert.Equal(t, "203.0.113.7", helper_util.ClientIP(request))
+	})
+
+	t.Run("Invalid proxy", func(t *testing.T) {
+		assert.Error(t, helper_util.SetTrustedProxies([]string{"10.0.0.0/33"}))
+	})
+}
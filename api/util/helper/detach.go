@@ -0,0 +1,22 @@
+package helper_util
+
+import (
+	"context"
+)
+
+// requestKeys are the context keys the middleware identifies a request and its user by
+var requestKeys = []any{ActorContextKey, "requestingUser", "requestingOrganizationID", "requestingRoles", "requestID", dryRunKey{}}
+
+// Detach returns a context for work outliving the request of ctx: it carries the request's user,
+// organization, roles, ID and dry-run mark, but neither its deadline nor its cancellation. Unlike
+// context.WithoutCancel it keeps no reference to ctx, since gin reuses a request's context once the
+// request ends.
+func Detach(ctx context.Context) context.Context {
+	detached := context.Background()
+	for _, key := range requestKeys {
+		if value := ctx.Value(key); value != nil {
+			detached = context.WithValue(detached, key, value)
+		}
+	}
+	return detached
+}
@@ -0,0 +1,38 @@
+package helper_util
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DryRunHeader lets clients ask for a dry run without touching the query string
+const DryRunHeader = "X-Dry-Run"
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context under which writes run all their checks but are rolled back
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx was prepared by WithDryRun
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// DryRunContext returns the context a handler passes to the services: the request itself, marked as
+// a dry run when the client sent ?dryRun=true or the X-Dry-Run header
+func DryRunContext(c *gin.Context) context.Context {
+	if requested(c.Query("dryRun")) || requested(c.GetHeader(DryRunHeader)) {
+		return WithDryRun(c)
+	}
+	return c
+}
+
+func requested(value string) bool {
+	enabled, err := strconv.ParseBool(value)
+	return err == nil && enabled
+}
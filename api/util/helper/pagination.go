@@ -0,0 +1,138 @@
+package helper_util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+)
+
+// DefaultPageLimit is the page size used when a list or search does not ask for one
+const DefaultPageLimit = 20
+
+// DefaultMaxPageLimit is the largest page size clients may request unless configured otherwise
+const DefaultMaxPageLimit = 200
+
+var maxPageLimit = DefaultMaxPageLimit
+
+// SetMaxPageLimit sets the largest page size clients may request. Non-positive values keep the
+// current maximum.
+func SetMaxPageLimit(limit int) {
+	if limit > 0 {
+		maxPageLimit = limit
+	}
+}
+
+// MaxPageLimit returns the largest page size clients may request
+func MaxPageLimit() int {
+	return maxPageLimit
+}
+
+// ClampPagination applies DefaultPageLimit to a missing limit and floors the offset at zero.
+// A limit above MaxPageLimit is rejected with ErrInvalidSearchCriteria rather than silently
+// truncated, so clients learn their page was not what they asked for.
+func ClampPagination(limit, offset int) (int, int, error) {
+	if limit > maxPageLimit {
+		return 0, 0, fmt.Errorf("%w: limit %d exceeds the maximum of %d", echo_errors.ErrInvalidSearchCriteria, limit, maxPageLimit)
+	}
+	if limit < 1 {
+		limit = DefaultPageLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset, nil
+}
+
+// BuildPagination returns the SKIP/LIMIT clause for a page along with its parameters.
+// A limit below 1 falls back to DefaultPageLimit and SKIP is only emitted for a positive offset.
+func BuildPagination(limit, offset int) (string, map[string]interface{}) {
+	if limit < 1 {
+		limit = DefaultPageLimit
+	}
+
+	params := map[string]interface{}{"limit": limit}
+	if offset > 0 {
+		params["offset"] = offset
+		return " SKIP $offset LIMIT $limit", params
+	}
+	return " LIMIT $limit", params
+}
+
+// SafeSortClause returns the ORDER BY clause sorting variable by field. Sort fields cannot be passed
+// as query parameters, so field must be one of allowed; anything else is rejected with
+// ErrInvalidSearchCriteria rather than concatenated into the query. An empty field returns an empty
+// clause so callers can apply their own default ordering.
+func SafeSortClause(variable, field, order string, allowed []string) (string, error) {
+	if field == "" {
+		return "", nil
+	}
+
+	for _, candidate := range allowed {
+		if candidate == field {
+			direction := "ASC"
+			if strings.EqualFold(order, "desc") {
+				direction = "DESC"
+			}
+			return " ORDER BY " + variable + "." + field + " " + direction, nil
+		}
+	}
+
+	return "", echo_errors.ErrInvalidSearchCriteria
+}
+
+// MergeParams copies every entry of src into dst and returns dst
+func MergeParams(dst, src map[string]interface{}) map[string]interface{} {
+	for key, value := range src {
+		dst[key] = value
+	}
+	return dst
+}
+
+// Cursor is the position of the last item of a keyset-paginated page, ordered by createdAt and then id
+type Cursor struct {
+	CreatedAt string `json:"c"`
+	ID        string `json:"i"`
+}
+
+// EncodeCursor turns a cursor into the opaque token handed to clients
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by EncodeCursor. An empty token returns a nil cursor, meaning
+// the first page; a malformed one is rejected with ErrInvalidSearchCriteria.
+func DecodeCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", echo_errors.ErrInvalidSearchCriteria)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.CreatedAt == "" || cursor.ID == "" {
+		return nil, fmt.Errorf("%w: malformed cursor", echo_errors.ErrInvalidSearchCriteria)
+	}
+	return &cursor, nil
+}
+
+// CursorParams returns the $afterCreatedAt, $afterID and $limit parameters of a keyset page query.
+// $limit is one more than limit so the caller can tell whether another page follows.
+func CursorParams(after *Cursor, limit int) map[string]interface{} {
+	params := map[string]interface{}{
+		"afterCreatedAt": nil,
+		"afterID":        nil,
+		"limit":          limit + 1,
+	}
+	if after != nil {
+		params["afterCreatedAt"] = after.CreatedAt
+		params["afterID"] = after.ID
+	}
+	return params
+}
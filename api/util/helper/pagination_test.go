@@ -0,0 +1,161 @@
+package helper_util_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+func TestBuildPagination(t *testing.T) {
+	t.Run("Limit and offset", func(t *testing.T) {
+		clause, params := helper_util.BuildPagination(25, 50)
+
+		assert.Equal(t, " SKIP $offset LIMIT $limit", clause)
+		assert.Equal(t, map[string]interface{}{"limit": 25, "offset": 50}, params)
+	})
+
+	t.Run("Zero offset omits SKIP", func(t *testing.T) {
+		clause, params := helper_util.BuildPagination(25, 0)
+
+		assert.Equal(t, " LIMIT $limit", clause)
+		assert.Equal(t, map[string]interface{}{"limit": 25}, params)
+	})
+
+	t.Run("Negative offset omits SKIP", func(t *testing.T) {
+		clause, params := helper_util.BuildPagination(25, -5)
+
+		assert.Equal(t, " LIMIT $limit", clause)
+		assert.NotContains(t, params, "offset")
+	})
+
+	t.Run("Missing limit uses default", func(t *testing.T) {
+		_, params := helper_util.BuildPagination(0, 0)
+
+		assert.Equal(t, helper_util.DefaultPageLimit, params["limit"])
+	})
+}
+
+func TestClampPagination(t *testing.T) {
+	t.Run("Zero limit uses default", func(t *testing.T) {
+		limit, offset, err := helper_util.ClampPagination(0, 40)
+
+		assert.NoError(t, err)
+		assert.Equal(t, helper_util.DefaultPageLimit, limit)
+		assert.Equal(t, 40, offset)
+	})
+
+	t.Run("Negative offset is floored at zero", func(t *testing.T) {
+		limit, offset, err := helper_util.ClampPagination(15, -3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 15, limit)
+		assert.Equal(t, 0, offset)
+	})
+
+	t.Run("Maximum limit is allowed", func(t *testing.T) {
+		limit, _, err := helper_util.ClampPagination(helper_util.MaxPageLimit(), 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, helper_util.MaxPageLimit(), limit)
+	})
+
+	t.Run("Over-max limit is rejected", func(t *testing.T) {
+		_, _, err := helper_util.ClampPagination(helper_util.MaxPageLimit()+1, 0)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidSearchCriteria))
+	})
+
+	t.Run("Configured maximum applies", func(t *testing.T) {
+		defer helper_util.SetMaxPageLimit(helper_util.MaxPageLimit())
+		helper_util.SetMaxPageLimit(50)
+
+		_, _, err := helper_util.ClampPagination(51, 0)
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidSearchCriteria))
+
+		helper_util.SetMaxPageLimit(0)
+		assert.Equal(t, 50, helper_util.MaxPageLimit())
+	})
+}
+
+func TestSafeSortClause(t *testing.T) {
+	allowed := []string{"name", "createdAt"}
+
+	t.Run("Allowed field ascending by default", func(t *testing.T) {
+		clause, err := helper_util.SafeSortClause("r", "name", "", allowed)
+
+		assert.NoError(t, err)
+		assert.Equal(t, " ORDER BY r.name ASC", clause)
+	})
+
+	t.Run("Descending order is case-insensitive", func(t *testing.T) {
+		clause, err := helper_util.SafeSortClause("r", "createdAt", "DeSc", allowed)
+
+		assert.NoError(t, err)
+		assert.Equal(t, " ORDER BY r.createdAt DESC", clause)
+	})
+
+	t.Run("Unknown order falls back to ascending", func(t *testing.T) {
+		clause, err := helper_util.SafeSortClause("r", "name", "sideways; DROP", allowed)
+
+		assert.NoError(t, err)
+		assert.Equal(t, " ORDER BY r.name ASC", clause)
+	})
+
+	t.Run("Empty field leaves ordering to the caller", func(t *testing.T) {
+		clause, err := helper_util.SafeSortClause("r", "", "desc", allowed)
+
+		assert.NoError(t, err)
+		assert.Empty(t, clause)
+	})
+
+	t.Run("Field outside the allow-list is rejected", func(t *testing.T) {
+		clause, err := helper_util.SafeSortClause("r", "name DETACH DELETE r //", "asc", allowed)
+
+		assert.Empty(t, clause)
+		assert.Equal(t, echo_errors.ErrInvalidSearchCriteria, err)
+	})
+}
+
+func TestMergeParams(t *testing.T) {
+	params := map[string]interface{}{"name": "docs"}
+
+	merged := helper_util.MergeParams(params, map[string]interface{}{"limit": 10})
+
+	assert.Equal(t, map[string]interface{}{"name": "docs", "limit": 10}, merged)
+}
+
+func TestCursor(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		cursor := helper_util.Cursor{CreatedAt: "2024-01-01T00:00:00Z", ID: "r1"}
+
+		decoded, err := helper_util.DecodeCursor(helper_util.EncodeCursor(cursor))
+
+		assert.NoError(t, err)
+		assert.Equal(t, &cursor, decoded)
+	})
+
+	t.Run("Empty token starts from the first page", func(t *testing.T) {
+		decoded, err := helper_util.DecodeCursor("")
+
+		assert.NoError(t, err)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("Malformed token is rejected", func(t *testing.T) {
+		for _, token := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+			_, err := helper_util.DecodeCursor(token)
+
+			assert.True(t, errors.Is(err, echo_errors.ErrInvalidSearchCriteria), token)
+		}
+	})
+
+	t.Run("Params fetch one extra row", func(t *testing.T) {
+		params := helper_util.CursorParams(&helper_util.Cursor{CreatedAt: "2024-01-01T00:00:00Z", ID: "r1"}, 10)
+
+		assert.Equal(t, map[string]interface{}{"afterCreatedAt": "2024-01-01T00:00:00Z", "afterID": "r1", "limit": 11}, params)
+	})
+}
@@ -2,23 +2,88 @@
 package util
 
 import (
-	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
+	"github.com/gin-gonic/gin/binding"
+
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
 )
 
+// RespondWithError answers a failed request with the given status and message. The code in the error
+// envelope is err's when it maps to that status, otherwise one named after the status, and a 400
+// caused by a validation error lists the invalid fields.
 func RespondWithError(c *gin.Context, code int, message string, err error) {
-	logger.Error(message,
-		zap.Error(err),
-		zap.String("path", c.Request.URL.Path),
-		zap.String("method", c.Request.Method))
-	c.JSON(code, gin.H{"error": message})
+	body := ErrorBody{Code: errorCode(code, err), Message: message}
+	if code == http.StatusBadRequest {
+		body.Details, _ = validationDetails(err)
+	}
+	respond(c, code, body, err)
 }
 
+// GetUserIDFromContext returns the ID of the requesting user, whom changes made by the request are
+// recorded as made by
 func GetUserIDFromContext(c *gin.Context) (string, error) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		return "", nil
+	return helper_util.ActorFromContext(c), nil
+}
+
+// BindJSONWithSchema validates the request body against the named embedded schema and then binds it
+// into obj. Schema violations are reported as a *SchemaValidationError.
+func BindJSONWithSchema(c *gin.Context, schema string, obj interface{}) error {
+	if c.Request.Body == nil {
+		return errors.New("request body is empty")
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateSchema(schema, body); err != nil {
+		return err
+	}
+	return binding.JSON.BindBody(body, obj)
+}
+
+// RespondWithBindError answers a request whose body BindJSONWithSchema rejected with 400, listing each
+// invalid field and the reason when the body violated its schema
+func RespondWithBindError(c *gin.Context, message string, err error) {
+	RespondWithError(c, http.StatusBadRequest, message, err)
+}
+
+// RespondWithEntity answers a read of a single entity with 200 and its JSON, tagged with an ETag
+// derived from that JSON. Any change to the entity, such as a new updatedAt or version, changes the
+// tag, so a request whose If-None-Match holds the current tag is answered with 304 and no body.
+func RespondWithEntity(c *gin.Context, entity interface{}) {
+	body, err := json.Marshal(entity)
+	if err != nil {
+		RespondWithError(c, http.StatusInternalServerError, "Failed to encode response", err)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag or is "*". Weak tags compare by
+// their value, as conditional GETs require.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
 	}
-	return userID.(string), nil
+	return false
 }
@@ -0,0 +1,277 @@
+// api/util/job_runner.go
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+// maxJobErrors is how many item failures a job keeps the reasons of; later ones are only counted
+const maxJobErrors = 100
+
+// JobStore keeps the state of background jobs where every instance can read it
+type JobStore interface {
+	SaveJob(ctx context.Context, job *model.Job) error
+	GetJob(ctx context.Context, id string) (*model.Job, error) // nil when there is no such job
+}
+
+// RedisJobStore keeps each job in Redis until TTL after its last update
+type RedisJobStore struct {
+	TTL time.Duration
+}
+
+func (s RedisJobStore) SaveJob(ctx context.Context, job *model.Job) error {
+	return db.SaveJob(ctx, job, s.TTL)
+}
+
+func (s RedisJobStore) GetJob(ctx context.Context, id string) (*model.Job, error) {
+	return db.GetJob(ctx, id)
+}
+
+// JobFunc does the work of a job, reporting its progress to progress, and returns its result. The job
+// fails when it returns an error, and is partial when it reported a failed item.
+type JobFunc func(ctx context.Context, progress *JobProgress) (interface{}, error)
+
+type queuedJob struct {
+	ctx context.Context
+	job *model.Job
+	run JobFunc
+}
+
+// JobRunner runs operations that may outlast an HTTP request, such as bulk imports, in the background.
+// Submitted jobs wait in a queue for one of a fixed number of workers; their state is kept in a
+// JobStore, so clients can poll any instance for it.
+type JobRunner struct {
+	store JobStore
+	queue chan queuedJob
+
+	mu      sync.RWMutex
+	closed  bool // Set by Shutdown; later jobs are refused
+	workers sync.WaitGroup
+}
+
+// NewJobRunner creates a JobRunner keeping jobs in store and starts its workers. Up to queueSize jobs
+// wait for a worker before more are refused.
+func NewJobRunner(store JobStore, workers int, queueSize int) *JobRunner {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	runner := &JobRunner{
+		store: store,
+		queue: make(chan queuedJob, queueSize),
+	}
+	runner.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go runner.work()
+	}
+	return runner
+}
+
+// Submit queues run as a job of jobType on behalf of the user ctx acts for, and returns the job as
+// queued. The job runs under a context detached from ctx, so it goes on once the request has ended.
+// When the queue is full, the job is recorded as failed and ErrJobQueueFull returned.
+func (r *JobRunner) Submit(ctx context.Context, jobType string, run JobFunc) (*model.Job, error) {
+	now := time.Now().UTC()
+	job := &model.Job{
+		ID:        uuid.New().String(),
+		Type:      jobType,
+		Status:    model.JobStatusQueued,
+		CreatedBy: helper_util.ActorFromContext(ctx),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := r.store.SaveJob(ctx, job); err != nil {
+		logger.Error("Error saving submitted job", zap.Error(err), zap.String("jobType", jobType))
+		return nil, fmt.Errorf("failed to submit job: %w", err)
+	}
+	submitted := *job
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	queued := !r.closed
+	if queued {
+		select {
+		case r.queue <- queuedJob{ctx: helper_util.Detach(ctx), job: job, run: run}:
+		default:
+			queued = false
+		}
+	}
+	if !queued {
+		job.Status, job.Errors = model.JobStatusFailure, []string{echo_errors.ErrJobQueueFull.Error()}
+		job.FinishedAt, job.UpdatedAt = &now, now
+		if err := r.store.SaveJob(ctx, job); err != nil {
+			logger.Warn("Failed to save refused job", zap.Error(err), zap.String("jobID", job.ID))
+		}
+		logger.Warn("Job refused", zap.String("jobID", job.ID), zap.String("jobType", jobType))
+		return nil, echo_errors.ErrJobQueueFull
+	}
+
+	logger.Info("Job submitted", zap.String("jobID", job.ID), zap.String("jobType", jobType), zap.String("userID", job.CreatedBy))
+	return &submitted, nil
+}
+
+// Get returns the current state of a job. Jobs are only visible to the user who submitted them, so
+// other users get ErrJobNotFound, as they do for jobs that expired or never existed.
+func (r *JobRunner) Get(ctx context.Context, id string) (*model.Job, error) {
+	job, err := r.store.GetJob(ctx, id)
+	if err != nil {
+		logger.Error("Error getting job", zap.Error(err), zap.String("jobID", id))
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil {
+		return nil, echo_errors.ErrJobNotFound
+	}
+	if actor := helper_util.ActorFromContext(ctx); actor != "" && job.CreatedBy != "" && actor != job.CreatedBy {
+		return nil, echo_errors.ErrJobNotFound
+	}
+	return job, nil
+}
+
+// Shutdown stops accepting jobs and waits for the queued and running ones to end. It gives up when ctx
+// is done, returning its error; the jobs abandoned then stay queued or running in the store.
+func (r *JobRunner) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		r.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *JobRunner) work() {
+	defer r.workers.Done()
+	for queued := range r.queue {
+		r.run(queued)
+	}
+}
+
+// run runs a job to its end, recording it as failed when it panics
+func (r *JobRunner) run(queued queuedJob) {
+	progress := &JobProgress{store: r.store, ctx: queued.ctx, job: queued.job}
+	progress.update(func(job *model.Job) {
+		job.Status = model.JobStatusRunning
+	})
+
+	var (
+		result interface{}
+		err    error
+	)
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("job panicked: %v", recovered)
+			}
+		}()
+		result, err = queued.run(queued.ctx, progress)
+	}()
+
+	var resultJSON json.RawMessage
+	if err == nil && result != nil {
+		resultJSON, err = json.Marshal(result)
+		if err != nil {
+			err = fmt.Errorf("failed to marshal job result: %w", err)
+		}
+	}
+
+	progress.update(func(job *model.Job) {
+		finishedAt := time.Now().UTC()
+		job.FinishedAt = &finishedAt
+		switch {
+		case err != nil:
+			job.Status = model.JobStatusFailure
+			job.Errors = append(job.Errors, err.Error())
+		case job.Failed > 0:
+			job.Status = model.JobStatusPartial
+		default:
+			job.Status = model.JobStatusSuccess
+		}
+		job.Result = resultJSON
+	})
+
+	fields := []zap.Field{zap.String("jobID", queued.job.ID), zap.String("jobType", queued.job.Type), zap.String("status", queued.job.Status)}
+	if err != nil {
+		logger.Error("Job failed", append(fields, zap.Error(err))...)
+	} else {
+		logger.Info("Job finished", append(fields, zap.Int("processed", queued.job.Processed), zap.Int("failed", queued.job.Failed))...)
+	}
+}
+
+// JobProgress is how a running job reports its progress. Every report is saved at once, so pollers
+// see it. A nil JobProgress ignores reports, so the work of a job can also be done outside one.
+type JobProgress struct {
+	store JobStore
+	ctx   context.Context
+
+	mu  sync.Mutex
+	job *model.Job
+}
+
+// SetTotal sets how many items the job works through
+func (p *JobProgress) SetTotal(total int) {
+	p.update(func(job *model.Job) {
+		job.Total = total
+	})
+}
+
+// Succeeded reports that n more items were processed successfully
+func (p *JobProgress) Succeeded(n int) {
+	p.update(func(job *model.Job) {
+		job.Processed += n
+	})
+}
+
+// Failed reports that one more item was processed and failed with err
+func (p *JobProgress) Failed(err error) {
+	p.update(func(job *model.Job) {
+		job.Processed++
+		job.Failed++
+		if len(job.Errors) < maxJobErrors {
+			job.Errors = append(job.Errors, err.Error())
+		}
+	})
+}
+
+// update changes the job and saves it. A failed save is only logged: the job goes on, and the next
+// save catches the store up.
+func (p *JobProgress) update(change func(job *model.Job)) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	change(p.job)
+	p.job.UpdatedAt = time.Now().UTC()
+	if err := p.store.SaveJob(p.ctx, p.job); err != nil {
+		logger.Warn("Failed to save job progress", zap.Error(err), zap.String("jobID", p.job.ID))
+	}
+}
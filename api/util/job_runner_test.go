@@ -0,0 +1,192 @@
+// api/util/job_runner_test.go
+package util_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/test/mock"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+	helper_util "github.com/dev-mohitbeniwal/echo/api/util/helper"
+)
+
+func TestJobRunner(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	redisServer := mock.StartRedisServer(t)
+	ctx := helper_util.WithActor(context.Background(), "alice")
+
+	// finished waits for a job to end and returns it as stored
+	finished := func(t *testing.T, runner *util.JobRunner, id string) *model.Job {
+		var job *model.Job
+		require.Eventually(t, func() bool {
+			var err error
+			job, err = runner.Get(ctx, id)
+			return err == nil && job.Finished()
+		}, time.Second, 5*time.Millisecond)
+		return job
+	}
+
+	t.Run("Submission returns a queued job stored in Redis", func(t *testing.T) {
+		runner := util.NewJobRunner(util.RedisJobStore{TTL: time.Hour}, 1, 10)
+		defer runner.Shutdown(context.Background())
+		release := make(chan struct{})
+
+		job, err := runner.Submit(ctx, "import", func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+			<-release
+			return nil, nil
+		})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, job.ID)
+		assert.Equal(t, "import", job.Type)
+		assert.Equal(t, model.JobStatusQueued, job.Status)
+		assert.Equal(t, "alice", job.CreatedBy)
+		assert.True(t, redisServer.Has("job:"+job.ID))
+		close(release)
+		assert.Equal(t, model.JobStatusSuccess, finished(t, runner, job.ID).Status)
+	})
+
+	t.Run("Progress is visible while the job runs", func(t *testing.T) {
+		runner := util.NewJobRunner(util.RedisJobStore{TTL: time.Hour}, 1, 10)
+		defer runner.Shutdown(context.Background())
+		reported, release := make(chan struct{}), make(chan struct{})
+
+		job, err := runner.Submit(ctx, "import", func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+			progress.SetTotal(3)
+			progress.Succeeded(2)
+			close(reported)
+			<-release
+			progress.Succeeded(1)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		<-reported
+
+		running, err := runner.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.JobStatusRunning, running.Status)
+		assert.Equal(t, 3, running.Total)
+		assert.Equal(t, 2, running.Processed)
+		assert.Nil(t, running.FinishedAt)
+
+		close(release)
+		done := finished(t, runner, job.ID)
+		assert.Equal(t, 3, done.Processed)
+		assert.NotNil(t, done.FinishedAt)
+	})
+
+	t.Run("Terminal status", func(t *testing.T) {
+		runner := util.NewJobRunner(util.RedisJobStore{TTL: time.Hour}, 2, 10)
+		defer runner.Shutdown(context.Background())
+
+		tests := []struct {
+			name   string
+			run    util.JobFunc
+			status string
+			failed int
+			errors []string
+			result string
+		}{
+			{"Success", func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+				progress.Succeeded(2)
+				return map[string]int{"created": 2}, nil
+			}, model.JobStatusSuccess, 0, nil, `{"created":2}`},
+			{"Partial", func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+				progress.Succeeded(1)
+				progress.Failed(errors.New("policy 1 is invalid"))
+				return map[string]int{"created": 1}, nil
+			}, model.JobStatusPartial, 1, []string{"policy 1 is invalid"}, `{"created":1}`},
+			{"Failure", func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+				return nil, errors.New("database unavailable")
+			}, model.JobStatusFailure, 0, []string{"database unavailable"}, ""},
+			{"Panic", func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+				panic("boom")
+			}, model.JobStatusFailure, 0, []string{"job panicked: boom"}, ""},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				job, err := runner.Submit(ctx, "import", tt.run)
+				require.NoError(t, err)
+
+				done := finished(t, runner, job.ID)
+
+				assert.Equal(t, tt.status, done.Status)
+				assert.Equal(t, tt.failed, done.Failed)
+				assert.Equal(t, tt.errors, done.Errors)
+				if tt.result == "" {
+					assert.Empty(t, done.Result)
+				} else {
+					assert.JSONEq(t, tt.result, string(done.Result))
+				}
+			})
+		}
+	})
+
+	t.Run("Jobs outlive the submitting request", func(t *testing.T) {
+		runner := util.NewJobRunner(util.RedisJobStore{TTL: time.Hour}, 1, 10)
+		defer runner.Shutdown(context.Background())
+		requestCtx, cancel := context.WithCancel(ctx)
+
+		job, err := runner.Submit(requestCtx, "review", func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+			time.Sleep(20 * time.Millisecond)
+			return helper_util.ActorFromContext(ctx), ctx.Err()
+		})
+		require.NoError(t, err)
+		cancel()
+
+		done := finished(t, runner, job.ID)
+		assert.Equal(t, model.JobStatusSuccess, done.Status)
+		var actor string
+		require.NoError(t, json.Unmarshal(done.Result, &actor))
+		assert.Equal(t, "alice", actor)
+	})
+
+	t.Run("A full queue refuses jobs", func(t *testing.T) {
+		runner := util.NewJobRunner(util.RedisJobStore{TTL: time.Hour}, 1, 0)
+		release := make(chan struct{})
+		block := func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+			<-release
+			return nil, nil
+		}
+
+		// The only worker takes the first job, so the next finds no room
+		first, err := runner.Submit(ctx, "import", block)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			job, err := runner.Get(ctx, first.ID)
+			return err == nil && job.Status == model.JobStatusRunning
+		}, time.Second, 5*time.Millisecond)
+		_, err = runner.Submit(ctx, "import", block)
+
+		assert.ErrorIs(t, err, echo_errors.ErrJobQueueFull)
+		close(release)
+		assert.NoError(t, runner.Shutdown(context.Background()))
+	})
+
+	t.Run("Jobs are only visible to their submitter", func(t *testing.T) {
+		runner := util.NewJobRunner(util.RedisJobStore{TTL: time.Hour}, 1, 10)
+		defer runner.Shutdown(context.Background())
+		job, err := runner.Submit(ctx, "import", func(ctx context.Context, progress *util.JobProgress) (interface{}, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		_, err = runner.Get(helper_util.WithActor(context.Background(), "bob"), job.ID)
+		assert.ErrorIs(t, err, echo_errors.ErrJobNotFound)
+
+		_, err = runner.Get(ctx, "missing")
+		assert.ErrorIs(t, err, echo_errors.ErrJobNotFound)
+	})
+}
@@ -0,0 +1,65 @@
+// api/util/maintenance.go
+
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dev-mohitbeniwal/echo/api/db"
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// readOnlyRefresh is how long an instance trusts the read-only mode it last read from Redis, so a
+// change made on another instance is observed within it
+const readOnlyRefresh = time.Second
+
+var readOnlyState struct {
+	mu        sync.Mutex
+	enabled   bool
+	checkedAt time.Time
+}
+
+// SetReadOnly turns the read-only mode on or off for every instance
+func SetReadOnly(ctx context.Context, enabled bool) error {
+	if err := db.SetReadOnly(ctx, enabled); err != nil {
+		return err
+	}
+
+	readOnlyState.mu.Lock()
+	defer readOnlyState.mu.Unlock()
+	readOnlyState.enabled, readOnlyState.checkedAt = enabled, time.Now()
+	return nil
+}
+
+// IsReadOnly reports whether the API is read-only for maintenance. When Redis cannot be reached, the
+// mode last read is assumed, so an outage neither blocks writes nor lets them through a maintenance.
+func IsReadOnly(ctx context.Context) bool {
+	readOnlyState.mu.Lock()
+	defer readOnlyState.mu.Unlock()
+
+	if time.Since(readOnlyState.checkedAt) < readOnlyRefresh {
+		return readOnlyState.enabled
+	}
+	enabled, err := db.IsReadOnly(ctx)
+	if err != nil {
+		logger.Warn("Failed to read the read-only mode, keeping the last one", zap.Error(err), zap.Bool("readOnly", readOnlyState.enabled))
+	} else {
+		readOnlyState.enabled = enabled
+	}
+	readOnlyState.checkedAt = time.Now()
+	return readOnlyState.enabled
+}
+
+// CheckWritable returns ErrServiceReadOnly while the API is read-only. Every method changing data calls
+// it first.
+func CheckWritable(ctx context.Context) error {
+	if IsReadOnly(ctx) {
+		return echo_errors.ErrServiceReadOnly
+	}
+	return nil
+}
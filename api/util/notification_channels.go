@@ -0,0 +1,108 @@
+// api/util/notification_channels.go
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+)
+
+// The channels notification routes may name
+const (
+	NotificationChannelLog     = "log"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelChat    = "chat"
+	NotificationChannelEmail   = "email"
+	NotificationChannelNone    = "none" // Routes a type nowhere
+)
+
+// notificationHTTPClient posts notifications to webhooks, giving up on one that does not answer in time
+var notificationHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// NotificationRouter sends each notification to the channels routed for its type, such as
+// resource.deleted to the security team's chat
+type NotificationRouter struct {
+	channels map[string]NotificationSender // By channel name
+	routes   map[string][]string           // Channel names by lowercased notification type
+}
+
+// NewNotificationRouter creates a NotificationRouter sending through channels, by name, as routes maps
+// notification types to channel names. Type matching ignores case, as config keys lose theirs.
+func NewNotificationRouter(channels map[string]NotificationSender, routes map[string][]string) *NotificationRouter {
+	router := &NotificationRouter{
+		channels: channels,
+		routes:   make(map[string][]string, len(routes)),
+	}
+	for notificationType, channelNames := range routes {
+		for _, name := range channelNames {
+			if _, configured := channels[name]; !configured && name != NotificationChannelNone {
+				logger.Warn("Notifications routed to a channel that is not configured", zap.String("type", notificationType), zap.String("channel", name))
+			}
+		}
+		router.routes[strings.ToLower(notificationType)] = channelNames
+	}
+	return router
+}
+
+// Send delivers a notification to each channel routed for its type and returns the errors of those
+// failing, joined. Types without a route go nowhere, as do those routed to "none" or to a channel not
+// configured.
+func (r *NotificationRouter) Send(ctx context.Context, notification Notification) error {
+	var errs []error
+	for _, name := range r.routes[strings.ToLower(notification.Type)] {
+		send, configured := r.channels[name]
+		if !configured {
+			continue
+		}
+		if err := send(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s channel: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WebhookSender posts each notification as JSON to url
+func WebhookSender(url string) NotificationSender {
+	return func(ctx context.Context, notification Notification) error {
+		return postNotificationJSON(ctx, url, notification)
+	}
+}
+
+// ChatSender posts the message of each notification to a chat incoming webhook, such as Slack's, as
+// {"text": message}
+func ChatSender(url string) NotificationSender {
+	return func(ctx context.Context, notification Notification) error {
+		return postNotificationJSON(ctx, url, map[string]string{"text": notification.Message})
+	}
+}
+
+func postNotificationJSON(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := notificationHTTPClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("notification webhook answered %s", response.Status)
+	}
+	return nil
+}
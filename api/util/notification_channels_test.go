@@ -0,0 +1,72 @@
+// api/util/notification_channels_test.go
+package util_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestNotificationRouting(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+	chat, webhook := &notificationRecorder{}, &notificationRecorder{}
+	router := util.NewNotificationRouter(map[string]util.NotificationSender{
+		util.NotificationChannelChat:    chat.send,
+		util.NotificationChannelWebhook: webhook.send,
+	}, map[string][]string{
+		// Viper hands the routes over with lowercased keys
+		"resource.deleted":     {"chat"},
+		"resourcetype.created": {"chat", "webhook"},
+		"department.created":   {"none"},
+	})
+	notifier := util.NewNotificationService(0, router.Send)
+
+	t.Run("RoutesOnlyToConfiguredChannels", func(t *testing.T) {
+		assert.NoError(t, notifier.NotifyResourceChange(ctx, "deleted", model.Resource{ID: "r1"}))
+
+		if assert.Len(t, chat.notifications(), 1) {
+			assert.Equal(t, "resource.deleted", chat.notifications()[0].Type)
+		}
+		assert.Empty(t, webhook.notifications())
+	})
+
+	t.Run("MatchesTypesIgnoringCase", func(t *testing.T) {
+		assert.NoError(t, notifier.NotifyResourceTypeChange(ctx, "created", model.ResourceType{ID: "rt1", Name: "Document"}))
+
+		assert.Len(t, chat.notifications(), 2)
+		assert.Len(t, webhook.notifications(), 1)
+	})
+
+	t.Run("NoneAndUnknownTypesGoNowhere", func(t *testing.T) {
+		assert.NoError(t, notifier.NotifyDepartmentChange(ctx, "created", model.Department{ID: "d1", Name: "Finance"}))
+		assert.NoError(t, notifier.NotifyPolicyChange(ctx, "updated", model.Policy{ID: "p1", Name: "Docs"}))
+
+		assert.Len(t, chat.notifications(), 2)
+		assert.Len(t, webhook.notifications(), 1)
+	})
+}
+
+func TestChatSender(t *testing.T) {
+	var posted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+	}))
+	defer server.Close()
+
+	err := util.ChatSender(server.URL)(context.Background(), util.Notification{Type: "resource.deleted", Message: "Resource r1 deleted"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"text": "Resource r1 deleted"}, posted)
+}
@@ -5,6 +5,10 @@ package util
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -12,36 +16,188 @@ import (
 	"github.com/dev-mohitbeniwal/echo/api/model"
 )
 
+// Notification is a message about one entity change or, in digest mode, about several changes of the
+// same type
+type Notification struct {
+	Type      string   `json:"type"`       // The entity and change, such as "policy.updated"
+	Message   string   `json:"message"`    // Such as `Policy "Docs readers" updated` or "12 policies updated"
+	EntityIDs []string `json:"entity_ids"` // The entities changed, in the order they changed
+	Count     int      `json:"count"`      // How many changes the notification covers
+}
+
+// NotificationSender delivers a notification, such as to a message queue or an external notification
+// service
+type NotificationSender func(ctx context.Context, notification Notification) error
+
+// notificationNouns names each kind of entity notified about, as a message starts with it and as it
+// is counted in a digest
+var notificationNouns = map[string][2]string{
+	"policy":         {"Policy", "policies"},
+	"organization":   {"Organization", "organizations"},
+	"department":     {"Department", "departments"},
+	"user":           {"User", "users"},
+	"role":           {"Role", "roles"},
+	"group":          {"Group", "groups"},
+	"permission":     {"Permission", "permissions"},
+	"resource":       {"Resource", "resources"},
+	"resourceType":   {"Resource type", "resource types"},
+	"attributeGroup": {"Attribute group", "attribute groups"},
+}
+
+// pendingDigest gathers the notifications of one type waiting for their digest to go out
+type pendingDigest struct {
+	notifications []Notification
+	timer         *time.Timer
+}
+
+// NotificationService tells people about entity changes. In digest mode, notifications of the same type
+// arriving within the digest window of the first are coalesced into one, so a bulk operation sends
+// "12 policies updated" rather than twelve notifications. Deletions are critical and always go out at
+// once.
 type NotificationService struct {
-	// You might want to add dependencies here, such as a message queue client
+	send         NotificationSender
+	digestWindow time.Duration // Zero sends every notification at once
+
+	mu       sync.Mutex
+	pending  map[string]*pendingDigest // By notification type
+	flushing sync.WaitGroup            // Digest timers started and not yet stopped or finished
+	closed   bool                      // Set by Shutdown; later notifications go out at once
+}
+
+// NewNotificationService creates a NotificationService delivering through send, such as a
+// NotificationRouter's Send, or logging when send is nil. A digestWindow above zero turns on digest mode.
+func NewNotificationService(digestWindow time.Duration, send NotificationSender) *NotificationService {
+	if send == nil {
+		send = LogNotification
+	}
+	return &NotificationService{
+		send:         send,
+		digestWindow: digestWindow,
+		pending:      make(map[string]*pendingDigest),
+	}
+}
+
+// LogNotification sends a notification to the application log
+func LogNotification(ctx context.Context, notification Notification) error {
+	logger.Info("NOTIFICATION: "+notification.Message,
+		zap.String("type", notification.Type),
+		zap.Strings("entityIDs", notification.EntityIDs),
+		zap.Int("count", notification.Count))
+	return nil
 }
 
-func NewNotificationService() *NotificationService {
-	return &NotificationService{}
+// notify sends the notification about a change to an entity of kind, or adds it to the pending digest
+// of its type. The entity is named by name, or by its ID when the name is unknown.
+func (n *NotificationService) notify(ctx context.Context, kind, action, entityID, name string) error {
+	label := entityID
+	if name != "" {
+		label = strconv.Quote(name)
+	}
+	notification := Notification{
+		Type:      kind + "." + action,
+		Message:   fmt.Sprintf("%s %s %s", notificationNouns[kind][0], label, action),
+		EntityIDs: []string{entityID},
+		Count:     1,
+	}
+
+	if n.digestWindow <= 0 || action == "deleted" {
+		return n.send(ctx, notification)
+	}
+
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return n.send(ctx, notification)
+	}
+	pending, exists := n.pending[notification.Type]
+	if !exists {
+		pending = &pendingDigest{}
+		n.pending[notification.Type] = pending
+		n.flushing.Add(1)
+		pending.timer = time.AfterFunc(n.digestWindow, func() {
+			defer n.flushing.Done()
+			n.flushDigest(notification.Type)
+		})
+	}
+	pending.notifications = append(pending.notifications, notification)
+	n.mu.Unlock()
+	return nil
+}
+
+// flushDigest sends the digest pending for notificationType. It runs once the digest window has
+// passed, after the request that started the digest is gone, so it sends without that request's
+// context.
+func (n *NotificationService) flushDigest(notificationType string) {
+	n.mu.Lock()
+	pending := n.pending[notificationType]
+	delete(n.pending, notificationType)
+	n.mu.Unlock()
+
+	if pending != nil {
+		n.sendDigest(notificationType, pending.notifications)
+	}
+}
+
+func (n *NotificationService) sendDigest(notificationType string, notifications []Notification) {
+	digest := notifications[0]
+	if len(notifications) > 1 {
+		kind, action, _ := strings.Cut(notificationType, ".")
+		digest = Notification{
+			Type:    notificationType,
+			Message: fmt.Sprintf("%d %s %s", len(notifications), notificationNouns[kind][1], action),
+			Count:   len(notifications),
+		}
+		for _, notification := range notifications {
+			digest.EntityIDs = append(digest.EntityIDs, notification.EntityIDs...)
+		}
+	}
+
+	if err := n.send(context.Background(), digest); err != nil {
+		logger.Error("Failed to send notification digest", zap.Error(err), zap.String("type", notificationType), zap.Int("count", digest.Count))
+	}
+}
+
+// Shutdown sends every pending digest without waiting out its window, and waits for those already being
+// sent. Notifications arriving afterwards go out at once. It gives up when ctx is done, returning its
+// error.
+func (n *NotificationService) Shutdown(ctx context.Context) error {
+	n.mu.Lock()
+	n.closed = true
+	due := make(map[string]*pendingDigest)
+	for notificationType, pending := range n.pending {
+		// A timer that already fired is sending its digest itself
+		if pending.timer.Stop() {
+			n.flushing.Done()
+			due[notificationType] = pending
+			delete(n.pending, notificationType)
+		}
+	}
+	n.mu.Unlock()
+
+	for notificationType, pending := range due {
+		n.sendDigest(notificationType, pending.notifications)
+	}
+
+	flushed := make(chan struct{})
+	go func() {
+		n.flushing.Wait()
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (n *NotificationService) NotifyPolicyChange(ctx context.Context, changeType string, policy model.Policy) error {
-	// In a real implementation, you might send this to a message queue or external notification service
 	switch changeType {
-	case "created":
-		logger.Info("NOTIFICATION: New policy created",
-			zap.String("policyID", policy.ID),
-			zap.String("policyName", policy.Name))
-	case "updated":
-		logger.Info("NOTIFICATION: Policy updated",
-			zap.String("policyID", policy.ID),
-			zap.String("policyName", policy.Name))
-	case "deleted":
-		logger.Info("NOTIFICATION: Policy deleted",
-			zap.String("policyID", policy.ID))
+	case "created", "updated", "deleted":
+		return n.notify(ctx, "policy", changeType, policy.ID, policy.Name)
 	default:
 		return fmt.Errorf("unknown change type: %s", changeType)
 	}
-
-	// Here you would implement the actual notification logic
-	// This could involve sending messages to a queue, calling an external API, etc.
-
-	return nil
 }
 
 func (n *NotificationService) SendEmail(ctx context.Context, recipient, subject, body string) error {
@@ -74,85 +230,40 @@ func (n *NotificationService) NotifyAffectedUsers(ctx context.Context, policyID
 }
 
 func (n *NotificationService) NotifyOrganizationChange(ctx context.Context, changeType string, org model.Organization) error {
-	// Logic to notify users about organization changes
-	logger.Info("Notifying organization change",
-		zap.String("changeType", changeType),
-		zap.String("orgID", org.ID),
-		zap.String("orgName", org.Name))
-	return nil
+	return n.notify(ctx, "organization", changeType, org.ID, org.Name)
 }
 
 func (n *NotificationService) NotifyDepartmentChange(ctx context.Context, changeType string, dept model.Department) error {
-	// Logic to notify users about department changes
-	logger.Info("Notifying department change",
-		zap.String("changeType", changeType),
-		zap.String("deptID", dept.ID),
-		zap.String("deptName", dept.Name))
-	return nil
+	return n.notify(ctx, "department", changeType, dept.ID, dept.Name)
 }
 
 func (n *NotificationService) NotifyUserChange(ctx context.Context, changeType string, user model.User) error {
-	// Logic to notify users about user changes
-	logger.Info("Notifying user change",
-		zap.String("changeType", changeType),
-		zap.String("userID", user.ID),
-		zap.String("userName", user.Username))
-	return nil
+	return n.notify(ctx, "user", changeType, user.ID, user.Name)
 }
 
 func (n *NotificationService) NotifyRoleChange(ctx context.Context, changeType string, role model.Role) error {
-	// Logic to notify users about role changes
-	logger.Info("Notifying role change",
-		zap.String("changeType", changeType),
-		zap.String("roleID", role.ID),
-		zap.String("roleName", role.Name))
-	return nil
+	return n.notify(ctx, "role", changeType, role.ID, role.Name)
 }
 
 func (n *NotificationService) NotifyGroupChange(ctx context.Context, changeType string, group model.Group) error {
-	// Logic to notify users about group changes
-	logger.Info("Notifying group change",
-		zap.String("changeType", changeType),
-		zap.String("groupID", group.ID),
-		zap.String("groupName", group.Name))
-	return nil
+	return n.notify(ctx, "group", changeType, group.ID, group.Name)
 }
 
 func (n *NotificationService) NotifyPermissionChange(ctx context.Context, changeType string, permission model.Permission) error {
-	// Logic to notify users about permission changes
-	logger.Info("Notifying permission change",
-		zap.String("changeType", changeType),
-		zap.String("permissionID", permission.ID),
-		zap.String("permissionName", permission.Name))
-	return nil
+	return n.notify(ctx, "permission", changeType, permission.ID, permission.Name)
 }
 
 // NotifyResourceChange
 func (n *NotificationService) NotifyResourceChange(ctx context.Context, changeType string, resource model.Resource) error {
-	// Logic to notify users about resource changes
-	logger.Info("Notifying resource change",
-		zap.String("changeType", changeType),
-		zap.String("resourceID", resource.ID),
-		zap.String("resourceName", resource.Name))
-	return nil
+	return n.notify(ctx, "resource", changeType, resource.ID, resource.Name)
 }
 
 // NotifyResourceTypeChange
 func (n *NotificationService) NotifyResourceTypeChange(ctx context.Context, changeType string, resourceType model.ResourceType) error {
-	// Logic to notify users about resource type changes
-	logger.Info("Notifying resource type change",
-		zap.String("changeType", changeType),
-		zap.String("resourceTypeID", resourceType.ID),
-		zap.String("resourceTypeName", resourceType.Name))
-	return nil
+	return n.notify(ctx, "resourceType", changeType, resourceType.ID, resourceType.Name)
 }
 
 // NotifyAttributeGroupChange
 func (n *NotificationService) NotifyAttributeGroupChange(ctx context.Context, changeType string, attrGroup model.AttributeGroup) error {
-	// Logic to notify users about attribute group changes
-	logger.Info("Notifying attribute group change",
-		zap.String("changeType", changeType),
-		zap.String("attrGroupID", attrGroup.ID),
-		zap.String("attrGroupName", attrGroup.Name))
-	return nil
+	return n.notify(ctx, "attributeGroup", changeType, attrGroup.ID, attrGroup.Name)
 }
@@ -0,0 +1,110 @@
+// api/util/notification_service_test.go
+package util_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "github.com/dev-mohitbeniwal/echo/api/logging"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+// notificationRecorder keeps the notifications a NotificationService sends
+type notificationRecorder struct {
+	mu   sync.Mutex
+	sent []util.Notification
+}
+
+func (r *notificationRecorder) send(ctx context.Context, notification util.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, notification)
+	return nil
+}
+
+func (r *notificationRecorder) notifications() []util.Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]util.Notification(nil), r.sent...)
+}
+
+func TestNotificationDigest(t *testing.T) {
+	logger.InitLogger("../logging")
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	t.Run("CoalescesUpdatesButNotDeletions", func(t *testing.T) {
+		recorder := &notificationRecorder{}
+		notifier := util.NewNotificationService(100*time.Millisecond, recorder.send)
+
+		for i := 1; i <= 10; i++ {
+			assert.NoError(t, notifier.NotifyPolicyChange(ctx, "updated", model.Policy{ID: fmt.Sprintf("p%d", i), Name: "Docs"}))
+		}
+		assert.NoError(t, notifier.NotifyPolicyChange(ctx, "deleted", model.Policy{ID: "p11"}))
+
+		// The deletion is critical, so it goes out before the digest window passes
+		sent := recorder.notifications()
+		if assert.Len(t, sent, 1) {
+			assert.Equal(t, "policy.deleted", sent[0].Type)
+			assert.Equal(t, "Policy p11 deleted", sent[0].Message)
+		}
+
+		assert.Eventually(t, func() bool { return len(recorder.notifications()) == 2 }, time.Second, 10*time.Millisecond)
+		digest := recorder.notifications()[1]
+		assert.Equal(t, "policy.updated", digest.Type)
+		assert.Equal(t, "10 policies updated", digest.Message)
+		assert.Equal(t, 10, digest.Count)
+		assert.Len(t, digest.EntityIDs, 10)
+		assert.Equal(t, "p1", digest.EntityIDs[0])
+
+		assert.NoError(t, notifier.Shutdown(ctx))
+		assert.Len(t, recorder.notifications(), 2)
+	})
+
+	t.Run("SingleNotificationKeepsItsMessage", func(t *testing.T) {
+		recorder := &notificationRecorder{}
+		notifier := util.NewNotificationService(20*time.Millisecond, recorder.send)
+
+		assert.NoError(t, notifier.NotifyResourceChange(ctx, "created", model.Resource{ID: "r1", Name: "Quarterly Report"}))
+
+		assert.Eventually(t, func() bool { return len(recorder.notifications()) == 1 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, `Resource "Quarterly Report" created`, recorder.notifications()[0].Message)
+	})
+
+	t.Run("ShutdownFlushesPendingDigests", func(t *testing.T) {
+		recorder := &notificationRecorder{}
+		notifier := util.NewNotificationService(time.Hour, recorder.send)
+
+		assert.NoError(t, notifier.NotifyUserChange(ctx, "updated", model.User{ID: "u1", Name: "Jane"}))
+		assert.NoError(t, notifier.NotifyUserChange(ctx, "updated", model.User{ID: "u2", Name: "John"}))
+		assert.Empty(t, recorder.notifications())
+
+		assert.NoError(t, notifier.Shutdown(ctx))
+
+		sent := recorder.notifications()
+		if assert.Len(t, sent, 1) {
+			assert.Equal(t, "2 users updated", sent[0].Message)
+		}
+
+		// Once shut down, notifications no longer wait for a digest
+		assert.NoError(t, notifier.NotifyUserChange(ctx, "updated", model.User{ID: "u3", Name: "Ann"}))
+		assert.Len(t, recorder.notifications(), 2)
+	})
+
+	t.Run("ImmediateWithoutWindow", func(t *testing.T) {
+		recorder := &notificationRecorder{}
+		notifier := util.NewNotificationService(0, recorder.send)
+
+		assert.NoError(t, notifier.NotifyPolicyChange(ctx, "updated", model.Policy{ID: "p1", Name: "Docs"}))
+		assert.NoError(t, notifier.NotifyPolicyChange(ctx, "updated", model.Policy{ID: "p2", Name: "Wiki"}))
+
+		assert.Len(t, recorder.notifications(), 2)
+	})
+}
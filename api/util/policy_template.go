@@ -0,0 +1,112 @@
+// api/util/policy_template.go
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+)
+
+var templatePlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// TemplateVariables returns the sorted, distinct names of the ${name} placeholders in a template policy
+func TemplateVariables(policy model.Policy) ([]string, error) {
+	tree, err := policyTree(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	walkStrings(tree, func(value string) string {
+		for _, match := range templatePlaceholder.FindAllStringSubmatch(value, -1) {
+			seen[match[1]] = true
+		}
+		return value
+	})
+
+	variables := make([]string, 0, len(seen))
+	for name := range seen {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+	return variables, nil
+}
+
+// InstantiateTemplate returns the template's policy with every placeholder replaced by its value in
+// vars. Every placeholder must be supplied; the missing ones are reported with
+// ErrMissingTemplateVariables. Values are substituted verbatim and never re-scanned for placeholders.
+func InstantiateTemplate(template model.PolicyTemplate, vars map[string]string) (model.Policy, error) {
+	variables, err := TemplateVariables(template.Policy)
+	if err != nil {
+		return model.Policy{}, err
+	}
+
+	var missing []string
+	for _, name := range variables {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return model.Policy{}, fmt.Errorf("%w: %s", echo_errors.ErrMissingTemplateVariables, strings.Join(missing, ", "))
+	}
+
+	tree, err := policyTree(template.Policy)
+	if err != nil {
+		return model.Policy{}, err
+	}
+	tree = walkStrings(tree, func(value string) string {
+		return templatePlaceholder.ReplaceAllStringFunc(value, func(placeholder string) string {
+			return vars[templatePlaceholder.FindStringSubmatch(placeholder)[1]]
+		})
+	})
+
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return model.Policy{}, fmt.Errorf("failed to marshal instantiated policy: %w", err)
+	}
+	var policy model.Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return model.Policy{}, fmt.Errorf("failed to unmarshal instantiated policy: %w", err)
+	}
+
+	policy.ID = ""
+	policy.TemplateID = template.ID
+	return policy, nil
+}
+
+// policyTree returns the generic JSON representation of a policy, so placeholders can be found in
+// any string it holds, including condition values and subject attributes
+func policyTree(policy model.Policy) (interface{}, error) {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template policy: %w", err)
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template policy: %w", err)
+	}
+	return tree, nil
+}
+
+// walkStrings replaces every string value in a JSON tree with the result of fn. Object keys are left as is.
+func walkStrings(node interface{}, fn func(string) string) interface{} {
+	switch value := node.(type) {
+	case string:
+		return fn(value)
+	case []interface{}:
+		for i, item := range value {
+			value[i] = walkStrings(item, fn)
+		}
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = walkStrings(item, fn)
+		}
+	}
+	return node
+}
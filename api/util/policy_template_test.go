@@ -0,0 +1,69 @@
+package util_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestPolicyTemplates(t *testing.T) {
+	template := model.PolicyTemplate{
+		ID:   "tpl1",
+		Name: "Department document access",
+		Policy: model.Policy{
+			ID:     "ignored",
+			Name:   "${department} documents",
+			Effect: "allow",
+			Subjects: []model.Subject{
+				{Type: "group", Attributes: map[string]string{"department": "${department}"}},
+			},
+			ResourceTypes: []string{"DOCUMENT"},
+			Actions:       []string{"read"},
+			Conditions: []model.Condition{
+				{Attribute: "resource.id", Operator: "equals", Value: "${resourceId}"},
+				{Attribute: "resource.path", Operator: "startsWith", Value: "/${department}/${resourceId}"},
+				{Attribute: "resource.pages", Operator: "lessThan", Value: float64(100)},
+			},
+		},
+	}
+
+	t.Run("Variables are distinct and sorted", func(t *testing.T) {
+		variables, err := util.TemplateVariables(template.Policy)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"department", "resourceId"}, variables)
+	})
+
+	t.Run("Placeholders are substituted everywhere", func(t *testing.T) {
+		policy, err := util.InstantiateTemplate(template, map[string]string{"department": "finance", "resourceId": "r42"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, policy.ID)
+		assert.Equal(t, "tpl1", policy.TemplateID)
+		assert.Equal(t, "finance documents", policy.Name)
+		assert.Equal(t, "finance", policy.Subjects[0].Attributes["department"])
+		assert.Equal(t, "r42", policy.Conditions[0].Value)
+		assert.Equal(t, "/finance/r42", policy.Conditions[1].Value)
+		assert.Equal(t, float64(100), policy.Conditions[2].Value)
+		assert.Equal(t, "${department} documents", template.Policy.Name, "the template itself must not change")
+	})
+
+	t.Run("Values are not re-scanned for placeholders", func(t *testing.T) {
+		policy, err := util.InstantiateTemplate(template, map[string]string{"department": "${resourceId}", "resourceId": "r42"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "${resourceId} documents", policy.Name)
+	})
+
+	t.Run("Missing variables are rejected", func(t *testing.T) {
+		_, err := util.InstantiateTemplate(template, map[string]string{"department": "finance"})
+
+		assert.True(t, errors.Is(err, echo_errors.ErrMissingTemplateVariables))
+		assert.Contains(t, err.Error(), "resourceId")
+	})
+}
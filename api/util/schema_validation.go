@@ -0,0 +1,113 @@
+// api/util/schema_validation.go
+package util
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+)
+
+// Names of the embedded schemas request bodies are validated against
+const (
+	SchemaPolicy     = "policy.json"
+	SchemaPolicyList = "policy_list.json"
+	SchemaResource   = "resource.json"
+	SchemaUser       = "user.json"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+var schemas = mustCompileSchemas(SchemaPolicy, SchemaPolicyList, SchemaResource, SchemaUser)
+
+// FieldError describes why one field of a request body is invalid. For schema violations Field is a
+// JSON pointer into the body, empty for the body as a whole.
+type FieldError = echo_errors.FieldError
+
+// SchemaValidationError lists every field of a request body that violates its schema
+type SchemaValidationError struct {
+	Fields []FieldError
+}
+
+func (e *SchemaValidationError) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		reasons[i] = field.Field + ": " + field.Reason
+	}
+	return "request body violates schema: " + strings.Join(reasons, "; ")
+}
+
+// ValidateSchema checks a JSON document against the named embedded schema. A document that does not
+// match is reported as a *SchemaValidationError.
+func ValidateSchema(name string, body []byte) error {
+	schema, ok := schemas[name]
+	if !ok {
+		return fmt.Errorf("unknown schema %q", name)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return &SchemaValidationError{Fields: []FieldError{{Field: "", Reason: "malformed JSON: " + err.Error()}}}
+	}
+
+	if err := schema.Validate(document); err != nil {
+		validationErr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return fmt.Errorf("failed to validate against schema %q: %w", name, err)
+		}
+		return &SchemaValidationError{Fields: fieldErrors(validationErr)}
+	}
+	return nil
+}
+
+// fieldErrors flattens a validation error into its leaves, which name the offending fields, ordered
+// by field
+func fieldErrors(err *jsonschema.ValidationError) []FieldError {
+	var fields []FieldError
+	var collect func(*jsonschema.ValidationError)
+	collect = func(err *jsonschema.ValidationError) {
+		if len(err.Causes) == 0 {
+			fields = append(fields, FieldError{Field: err.InstanceLocation, Reason: err.Message})
+			return
+		}
+		for _, cause := range err.Causes {
+			collect(cause)
+		}
+	}
+	collect(err)
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+	return fields
+}
+
+// mustCompileSchemas compiles the named schemas from the embedded files. The schemas ship with the
+// binary, so failing to compile them is a programming error.
+func mustCompileSchemas(names ...string) map[string]*jsonschema.Schema {
+	compiler := jsonschema.NewCompiler()
+	for _, name := range names {
+		data, err := schemaFiles.ReadFile("schemas/" + name)
+		if err != nil {
+			panic(fmt.Sprintf("failed to read schema %s: %v", name, err))
+		}
+		if err := compiler.AddResource(name, bytes.NewReader(data)); err != nil {
+			panic(fmt.Sprintf("failed to load schema %s: %v", name, err))
+		}
+	}
+
+	compiled := make(map[string]*jsonschema.Schema, len(names))
+	for _, name := range names {
+		compiled[name] = compiler.MustCompile(name)
+	}
+	return compiled
+}
@@ -0,0 +1,93 @@
+package util_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestValidateSchema(t *testing.T) {
+	t.Run("Valid policy", func(t *testing.T) {
+		body := `{
+			"name": "Docs",
+			"effect": "allow",
+			"subjects": [{"type": "role", "attributes": {"id": "editor"}}],
+			"resource_types": ["DOCUMENT"],
+			"actions": ["read"],
+			"conditions": [{"attribute": "resource.sensitivity", "operator": "equals", "value": "internal"}]
+		}`
+
+		assert.NoError(t, util.ValidateSchema(util.SchemaPolicy, []byte(body)))
+	})
+
+	t.Run("Invalid policy effect", func(t *testing.T) {
+		body := `{"name": "Docs", "effect": "permit", "subjects": [{"type": "role"}], "actions": ["read"]}`
+
+		err := util.ValidateSchema(util.SchemaPolicy, []byte(body))
+
+		var schemaErr *util.SchemaValidationError
+		assert.True(t, errors.As(err, &schemaErr))
+		assert.Len(t, schemaErr.Fields, 1)
+		assert.Equal(t, "/effect", schemaErr.Fields[0].Field)
+		assert.Contains(t, schemaErr.Fields[0].Reason, "allow")
+	})
+
+	t.Run("Unknown policy subject type", func(t *testing.T) {
+		body := `{"name": "Docs", "effect": "deny", "subjects": [{"type": "role"}, {"type": "robot"}], "actions": ["read"]}`
+
+		err := util.ValidateSchema(util.SchemaPolicy, []byte(body))
+
+		var schemaErr *util.SchemaValidationError
+		assert.True(t, errors.As(err, &schemaErr))
+		assert.Len(t, schemaErr.Fields, 1)
+		assert.Equal(t, "/subjects/1/type", schemaErr.Fields[0].Field)
+	})
+
+	t.Run("Every invalid field is listed", func(t *testing.T) {
+		body := `{"effect": "permit", "priority": -1, "conditions": [{"attribute": "subject.level"}]}`
+
+		err := util.ValidateSchema(util.SchemaPolicy, []byte(body))
+
+		var schemaErr *util.SchemaValidationError
+		assert.True(t, errors.As(err, &schemaErr))
+		fields := make([]string, len(schemaErr.Fields))
+		for i, field := range schemaErr.Fields {
+			fields[i] = field.Field
+		}
+		assert.Equal(t, []string{"/conditions/0", "/effect", "/priority"}, fields)
+	})
+
+	t.Run("Policy list items are validated", func(t *testing.T) {
+		body := `[{"name": "Docs", "effect": "allow", "actions": ["read"]}, {"name": "Wiki", "effect": "permit", "actions": ["read"]}]`
+
+		err := util.ValidateSchema(util.SchemaPolicyList, []byte(body))
+
+		var schemaErr *util.SchemaValidationError
+		assert.True(t, errors.As(err, &schemaErr))
+		assert.Len(t, schemaErr.Fields, 1)
+		assert.Equal(t, "/1/effect", schemaErr.Fields[0].Field)
+		assert.Error(t, util.ValidateSchema(util.SchemaPolicyList, []byte(`[]`)))
+	})
+
+	t.Run("Resource and user payloads", func(t *testing.T) {
+		resourceErr := util.ValidateSchema(util.SchemaResource, []byte(`{"name": "Report", "size": "large"}`))
+		userErr := util.ValidateSchema(util.SchemaUser, []byte(`{"name": "Ada", "attributes": {"level": 3}}`))
+
+		var schemaErr *util.SchemaValidationError
+		assert.True(t, errors.As(resourceErr, &schemaErr))
+		assert.Equal(t, "/size", schemaErr.Fields[0].Field)
+		assert.True(t, errors.As(userErr, &schemaErr))
+		assert.Equal(t, "/attributes/level", schemaErr.Fields[0].Field)
+	})
+
+	t.Run("Malformed JSON", func(t *testing.T) {
+		err := util.ValidateSchema(util.SchemaUser, []byte(`{"name": `))
+
+		var schemaErr *util.SchemaValidationError
+		assert.True(t, errors.As(err, &schemaErr))
+		assert.Contains(t, schemaErr.Fields[0].Reason, "malformed JSON")
+	})
+}
@@ -4,165 +4,373 @@ package util
 
 import (
 	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
 	"github.com/dev-mohitbeniwal/echo/api/model"
 )
 
 type ValidationUtil struct{}
 
+// Policy effects and subject types a policy may use
+var (
+	policyEffects      = []string{"allow", "deny"}
+	policySubjectTypes = []string{"user", "role", "group", "department", "organization"}
+)
+
+var allowedPolicyActions map[string]bool
+
+// Statuses an organization may have, and the shape of a contact phone number
+var (
+	organizationStatuses = []string{model.OrgStatusActive, model.OrgStatusSuspended, model.OrgStatusArchived}
+	contactPhonePattern  = regexp.MustCompile(`^\+?[0-9][0-9 ().-]{5,24}$`)
+)
+
+// SetAllowedPolicyActions restricts the actions a policy may grant or deny to actions. An empty set
+// lifts the restriction.
+func SetAllowedPolicyActions(actions []string) {
+	if len(actions) == 0 {
+		allowedPolicyActions = nil
+		return
+	}
+	allowed := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		allowed[action] = true
+	}
+	allowedPolicyActions = allowed
+}
+
 func NewValidationUtil() *ValidationUtil {
 	return &ValidationUtil{}
 }
 
 func (v *ValidationUtil) ValidatePolicy(policy model.Policy) error {
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidPolicyData)
 	if policy.Name == "" {
-		return fmt.Errorf("policy name cannot be empty")
+		invalid.Add("name", "cannot be empty")
 	}
-	if policy.Effect != "allow" && policy.Effect != "deny" {
-		return fmt.Errorf("policy effect must be either 'allow' or 'deny'")
+	if !containsString(policyEffects, policy.Effect) {
+		invalid.Add("effect", fmt.Sprintf("%q must be one of %s", policy.Effect, strings.Join(policyEffects, ", ")))
 	}
 	if policy.Priority < 0 {
-		return fmt.Errorf("policy priority cannot be negative")
+		invalid.Add("priority", "cannot be negative")
 	}
 	if len(policy.Subjects) == 0 {
-		return fmt.Errorf("policy must have at least one subject")
+		invalid.Add("subjects", "must have at least one subject")
 	}
 	if len(policy.ResourceTypes) == 0 {
-		return fmt.Errorf("policy must have at least one resource")
+		invalid.Add("resource_types", "must have at least one resource")
 	}
 	if len(policy.Actions) == 0 {
-		return fmt.Errorf("policy must have at least one action")
+		invalid.Add("actions", "must have at least one action")
+	}
+	for i, subject := range policy.Subjects {
+		if !containsString(policySubjectTypes, subject.Type) {
+			invalid.Add(fmt.Sprintf("subjects[%d].type", i), fmt.Sprintf("%q must be one of %s", subject.Type, strings.Join(policySubjectTypes, ", ")))
+		}
+	}
+	for i, obligation := range policy.Obligations {
+		requireField(invalid, fmt.Sprintf("obligations[%d].id", i), obligation.ID)
+	}
+	for i, resourceType := range policy.ResourceTypes {
+		if err := ValidateGlob(resourceType); err != nil {
+			invalid.Add(fmt.Sprintf("resource_types[%d]", i), err.Error())
+		}
+	}
+	for i, action := range policy.Actions {
+		if err := ValidateGlob(action); err != nil {
+			invalid.Add(fmt.Sprintf("actions[%d]", i), err.Error())
+		} else if allowedPolicyActions != nil && !actionAllowed(action) {
+			invalid.Add(fmt.Sprintf("actions[%d]", i), fmt.Sprintf("%q must be one of %s", action, strings.Join(sortedKeys(allowedPolicyActions), ", ")))
+		}
 	}
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
 }
 
 func (v *ValidationUtil) ValidateOrganization(organization model.Organization) error {
-	if organization.ID == "" {
-		return fmt.Errorf("organization description cannot be empty")
-	}
-	if organization.Name == "" {
-		return fmt.Errorf("organization name cannot be empty")
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidOrganizationData)
+	requireField(invalid, "id", organization.ID)
+	requireField(invalid, "name", organization.Name)
+	if organization.Status != "" && !containsString(organizationStatuses, organization.Status) {
+		invalid.Add("status", fmt.Sprintf("%q must be one of %s", organization.Status, strings.Join(organizationStatuses, ", ")))
+	}
+	if organization.ContactEmail != "" {
+		if address, err := mail.ParseAddress(organization.ContactEmail); err != nil || address.Address != organization.ContactEmail {
+			invalid.Add("contact_email", fmt.Sprintf("%q is not an email address", organization.ContactEmail))
+		}
+	}
+	if organization.ContactPhone != "" && !contactPhonePattern.MatchString(organization.ContactPhone) {
+		invalid.Add("contact_phone", fmt.Sprintf("%q is not a phone number", organization.ContactPhone))
+	}
+	systems := make([]string, 0, len(organization.ExternalIDs))
+	for system := range organization.ExternalIDs {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+	for _, system := range systems {
+		if strings.TrimSpace(system) == "" {
+			invalid.Add("external_ids", "system names cannot be empty")
+		} else if organization.ExternalIDs[system] == "" {
+			invalid.Add(fmt.Sprintf("external_ids.%s", system), "cannot be empty")
+		}
 	}
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
 }
 
 func (v *ValidationUtil) ValidateDepartment(department model.Department) error {
-	if department.ID == "" {
-		return fmt.Errorf("department ID cannot be empty")
-	}
-	if department.Name == "" {
-		return fmt.Errorf("department name cannot be empty")
-	}
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidDepartmentData)
+	requireField(invalid, "id", department.ID)
+	requireField(invalid, "name", department.Name)
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
 }
 
 func (v *ValidationUtil) ValidateUser(user model.User) error {
-	if user.ID == "" {
-		return fmt.Errorf("user ID cannot be empty")
-	}
-	if user.Name == "" {
-		return fmt.Errorf("user name cannot be empty")
-	}
-	if user.Username == "" {
-		return fmt.Errorf("user username cannot be empty")
-	}
-	if user.Email == "" {
-		return fmt.Errorf("user email cannot be empty")
-	}
-	if user.UserType == "" {
-		return fmt.Errorf("user type cannot be empty")
-	}
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidUserData)
+	requireField(invalid, "id", user.ID)
+	requireField(invalid, "name", user.Name)
+	requireField(invalid, "username", user.Username)
+	requireField(invalid, "email", user.Email)
+	requireField(invalid, "user_type", user.UserType)
+	validateClassification(invalid, "clearance", user.Clearance)
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
 }
 
 func (v *ValidationUtil) ValidateRole(role model.Role) error {
-	if role.ID == "" {
-		return fmt.Errorf("role ID cannot be empty")
-	}
-	if role.Name == "" {
-		return fmt.Errorf("role name cannot be empty")
-	}
-	if role.OrganizationID == "" {
-		return fmt.Errorf("role organization ID cannot be empty")
-	}
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidRoleData)
+	requireField(invalid, "id", role.ID)
+	requireField(invalid, "name", role.Name)
+	requireField(invalid, "organization_id", role.OrganizationID)
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
 }
 
 func (v *ValidationUtil) ValidateGroup(group model.Group) error {
-	if group.ID == "" {
-		return fmt.Errorf("group ID cannot be empty")
-	}
-	if group.Name == "" {
-		return fmt.Errorf("group name cannot be empty")
-	}
-	if group.OrganizationID == "" {
-		return fmt.Errorf("group organization ID cannot be empty")
-	}
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidGroupData)
+	requireField(invalid, "id", group.ID)
+	requireField(invalid, "name", group.Name)
+	requireField(invalid, "organization_id", group.OrganizationID)
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
 }
 
 // ValidatePermission
 func (v *ValidationUtil) ValidatePermission(permission model.Permission) error {
-	if permission.ID == "" {
-		return fmt.Errorf("permission ID cannot be empty")
-	}
-	if permission.Name == "" {
-		return fmt.Errorf("permission name cannot be empty")
-	}
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidPermissionData)
+	requireField(invalid, "id", permission.ID)
+	requireField(invalid, "name", permission.Name)
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
+}
+
+// ValidateSoDRule
+func (v *ValidationUtil) ValidateSoDRule(rule model.SoDRule) error {
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidSoDRuleData)
+	requireField(invalid, "role_a", rule.RoleA)
+	requireField(invalid, "role_b", rule.RoleB)
+	if rule.RoleA != "" && rule.RoleA == rule.RoleB {
+		invalid.Add("role_b", "must differ from role_a")
+	}
+	return invalid.Err()
 }
 
 // ValidateResource
 func (v *ValidationUtil) ValidateResource(resource model.Resource) error {
-	if resource.ID == "" {
-		return fmt.Errorf("resource ID cannot be empty")
-	}
-	if resource.Name == "" {
-		return fmt.Errorf("resource name cannot be empty")
-	}
-	if resource.Type == "" {
-		return fmt.Errorf("resource type cannot be empty")
-	}
-	if resource.OrganizationID == "" {
-		return fmt.Errorf("resource organization ID cannot be empty")
-	}
-	if resource.OwnerID == "" {
-		return fmt.Errorf("resource owner ID cannot be empty")
-	}
-	if resource.Status == "" {
-		return fmt.Errorf("resource status cannot be empty")
-	}
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidResourceData)
+	requireField(invalid, "id", resource.ID)
+	requireField(invalid, "name", resource.Name)
+	requireField(invalid, "type", resource.Type)
+	requireField(invalid, "organization_id", resource.OrganizationID)
+	requireField(invalid, "owner_id", resource.OwnerID)
+	requireField(invalid, "status", resource.Status)
+	validateClassification(invalid, "classification", resource.Classification)
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
 }
 
 // ValidateResourceType
 func (v *ValidationUtil) ValidateResourceType(resourceType model.ResourceType) error {
-	if resourceType.ID == "" {
-		return fmt.Errorf("resource type ID cannot be empty")
-	}
-	if resourceType.Name == "" {
-		return fmt.Errorf("resource type name cannot be empty")
-	}
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidResourceTypeData)
+	requireField(invalid, "id", resourceType.ID)
+	requireField(invalid, "name", resourceType.Name)
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
 }
 
 // ValidateAttributeGroup
 func (v *ValidationUtil) ValidateAttributeGroup(attributeGroup model.AttributeGroup) error {
-	if attributeGroup.ID == "" {
-		return fmt.Errorf("attribute group ID cannot be empty")
-	}
-	if attributeGroup.Name == "" {
-		return fmt.Errorf("attribute group name cannot be empty")
+	invalid := echo_errors.NewValidationError(echo_errors.ErrInvalidAttributeGroupData)
+	requireField(invalid, "id", attributeGroup.ID)
+	requireField(invalid, "name", attributeGroup.Name)
+	for _, key := range sortedSchemaKeys(attributeGroup.Schema) {
+		def := attributeGroup.Schema[key]
+		if key == "" {
+			invalid.Add("schema", "keys cannot be empty")
+			continue
+		}
+		switch def.Type {
+		case model.AttributeTypeString, model.AttributeTypeNumber, model.AttributeTypeBoolean, model.AttributeTypeArray, model.AttributeTypeObject:
+		default:
+			invalid.Add("schema."+key+".type", fmt.Sprintf("'%s' is not a supported type", def.Type))
+			continue
+		}
+		for _, allowed := range def.AllowedValues {
+			if !matchesAttributeType(allowed, def.Type) {
+				invalid.Add("schema."+key+".allowed_values", fmt.Sprintf("value %v is not of type '%s'", allowed, def.Type))
+			}
+		}
 	}
 	// Add more validation rules as needed
-	return nil
+	return invalid.Err()
+}
+
+// ValidateAttributesAgainstGroup checks attributes against the schema of the attribute group they reference.
+// Unknown keys, type mismatches, missing required attributes and values outside the allowed set are rejected.
+func (v *ValidationUtil) ValidateAttributesAgainstGroup(attrs map[string]interface{}, group model.AttributeGroup) error {
+	if len(group.Schema) == 0 {
+		// Groups without a schema impose no constraints
+		return nil
+	}
+
+	invalid := echo_errors.NewValidationError(echo_errors.ErrAttributeSchemaViolation)
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := attrs[key]
+		def, ok := group.Schema[key]
+		if !ok {
+			invalid.Add("attributes."+key, fmt.Sprintf("is unknown to attribute group '%s'", group.ID))
+			continue
+		}
+		if !matchesAttributeType(value, def.Type) {
+			invalid.Add("attributes."+key, fmt.Sprintf("must be of type '%s'", def.Type))
+			continue
+		}
+		if len(def.AllowedValues) > 0 && !containsAttributeValue(def.AllowedValues, value) {
+			invalid.Add("attributes."+key, fmt.Sprintf("has value %v which is not allowed", value))
+		}
+	}
+
+	for _, key := range sortedSchemaKeys(group.Schema) {
+		if _, ok := attrs[key]; group.Schema[key].Required && !ok {
+			invalid.Add("attributes."+key, "is required")
+		}
+	}
+
+	return invalid.Err()
+}
+
+// requireField records field as invalid when its value is empty
+func requireField(invalid *echo_errors.ErrValidation, field string, value string) {
+	if value == "" {
+		invalid.Add(field, "cannot be empty")
+	}
+}
+
+// sortedSchemaKeys returns the attribute keys of a schema in order, so validation reports them stably
+func sortedSchemaKeys(schema map[string]model.AttributeDefinition) []string {
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// matchesAttributeType reports whether a decoded JSON value is of the given schema type
+func matchesAttributeType(value interface{}, attrType string) bool {
+	switch attrType {
+	case model.AttributeTypeString:
+		_, ok := value.(string)
+		return ok
+	case model.AttributeTypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case model.AttributeTypeNumber:
+		_, ok := toFloat64(value)
+		return ok
+	case model.AttributeTypeArray:
+		if value == nil {
+			return false
+		}
+		kind := reflect.TypeOf(value).Kind()
+		return kind == reflect.Slice || kind == reflect.Array
+	case model.AttributeTypeObject:
+		if value == nil {
+			return false
+		}
+		return reflect.TypeOf(value).Kind() == reflect.Map
+	}
+	return false
+}
+
+// containsAttributeValue reports whether value is one of the allowed values
+func containsAttributeValue(allowed []interface{}, value interface{}) bool {
+	for _, candidate := range allowed {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+		// JSON numbers decode as float64, so compare numerics by value
+		if a, ok := toFloat64(candidate); ok {
+			if b, ok := toFloat64(value); ok && a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// actionAllowed reports whether a policy action is one of the allowed actions, or a pattern matching at
+// least one of them
+func actionAllowed(action string) bool {
+	if literal, ok := GlobLiteral(action); ok {
+		return allowedPolicyActions[literal]
+	}
+	for allowed := range allowedPolicyActions {
+		if GlobMatch(action, allowed) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,273 @@
+package util_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	echo_errors "github.com/dev-mohitbeniwal/echo/api/errors"
+	"github.com/dev-mohitbeniwal/echo/api/model"
+	"github.com/dev-mohitbeniwal/echo/api/util"
+)
+
+func TestValidateAttributesAgainstGroup(t *testing.T) {
+	validationUtil := util.NewValidationUtil()
+
+	group := model.AttributeGroup{
+		ID:   "ag1",
+		Name: "Document Attributes",
+		Schema: map[string]model.AttributeDefinition{
+			"department": {Type: model.AttributeTypeString, Required: true},
+			"pages":      {Type: model.AttributeTypeNumber},
+			"level":      {Type: model.AttributeTypeString, AllowedValues: []interface{}{"low", "high"}},
+		},
+	}
+
+	t.Run("Valid resource attributes", func(t *testing.T) {
+		resource := model.Resource{
+			ID:               "r1",
+			AttributeGroupID: group.ID,
+			Attributes: map[string]interface{}{
+				"department": "finance",
+				"pages":      float64(12),
+				"level":      "high",
+			},
+		}
+
+		err := validationUtil.ValidateAttributesAgainstGroup(resource.Attributes, group)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown attribute key", func(t *testing.T) {
+		resource := model.Resource{
+			ID:               "r1",
+			AttributeGroupID: group.ID,
+			Attributes: map[string]interface{}{
+				"department": "finance",
+				"color":      "blue",
+			},
+		}
+
+		err := validationUtil.ValidateAttributesAgainstGroup(resource.Attributes, group)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrAttributeSchemaViolation))
+	})
+
+	t.Run("Type mismatch", func(t *testing.T) {
+		resource := model.Resource{
+			ID:               "r1",
+			AttributeGroupID: group.ID,
+			Attributes: map[string]interface{}{
+				"department": "finance",
+				"pages":      "twelve",
+			},
+		}
+
+		err := validationUtil.ValidateAttributesAgainstGroup(resource.Attributes, group)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrAttributeSchemaViolation))
+	})
+
+	t.Run("Missing required attribute", func(t *testing.T) {
+		resource := model.Resource{
+			ID:               "r1",
+			AttributeGroupID: group.ID,
+			Attributes: map[string]interface{}{
+				"pages": float64(3),
+			},
+		}
+
+		err := validationUtil.ValidateAttributesAgainstGroup(resource.Attributes, group)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrAttributeSchemaViolation))
+	})
+
+	t.Run("Value not allowed", func(t *testing.T) {
+		resource := model.Resource{
+			ID:               "r1",
+			AttributeGroupID: group.ID,
+			Attributes: map[string]interface{}{
+				"department": "finance",
+				"level":      "medium",
+			},
+		}
+
+		err := validationUtil.ValidateAttributesAgainstGroup(resource.Attributes, group)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrAttributeSchemaViolation))
+	})
+
+	t.Run("Group without schema", func(t *testing.T) {
+		err := validationUtil.ValidateAttributesAgainstGroup(map[string]interface{}{"anything": 1}, model.AttributeGroup{ID: "ag2"})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestValidatePolicyEnums(t *testing.T) {
+	validationUtil := util.NewValidationUtil()
+	defer util.SetAllowedPolicyActions(nil)
+
+	valid := model.Policy{
+		Name:          "Docs",
+		Effect:        "allow",
+		Subjects:      []model.Subject{{Type: "role", Attributes: map[string]string{"id": "editor"}}},
+		ResourceTypes: []string{"DOCUMENT"},
+		Actions:       []string{"read"},
+	}
+
+	t.Run("Valid policy", func(t *testing.T) {
+		assert.NoError(t, validationUtil.ValidatePolicy(valid))
+	})
+
+	t.Run("Invalid effect", func(t *testing.T) {
+		policy := valid
+		policy.Effect = "permit"
+
+		err := validationUtil.ValidatePolicy(policy)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidPolicyData))
+		assert.Contains(t, err.Error(), `effect "permit"`)
+	})
+
+	t.Run("Invalid subject type", func(t *testing.T) {
+		policy := valid
+		policy.Subjects = []model.Subject{{Type: "user", UserID: "u1"}, {Type: "robot"}}
+
+		err := validationUtil.ValidatePolicy(policy)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidPolicyData))
+		assert.Contains(t, err.Error(), `subjects[1].type "robot"`)
+	})
+
+	t.Run("Every subject type is accepted", func(t *testing.T) {
+		policy := valid
+		policy.Subjects = []model.Subject{{Type: "user"}, {Type: "role"}, {Type: "group"}, {Type: "department"}, {Type: "organization"}}
+
+		assert.NoError(t, validationUtil.ValidatePolicy(policy))
+	})
+
+	t.Run("Invalid action", func(t *testing.T) {
+		util.SetAllowedPolicyActions([]string{"read", "write"})
+		defer util.SetAllowedPolicyActions(nil)
+		policy := valid
+		policy.Actions = []string{"read", "purge"}
+
+		err := validationUtil.ValidatePolicy(policy)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidPolicyData))
+		assert.Contains(t, err.Error(), `actions[1] "purge" must be one of read, write`)
+	})
+
+	t.Run("Action patterns matching an allowed action", func(t *testing.T) {
+		util.SetAllowedPolicyActions([]string{"read", "write"})
+		defer util.SetAllowedPolicyActions(nil)
+		policy := valid
+		policy.Actions = []string{"*", "re*", "pur?e", `wr\`}
+
+		err := validationUtil.ValidatePolicy(policy)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidPolicyData))
+		assert.NotContains(t, err.Error(), "actions[0]")
+		assert.NotContains(t, err.Error(), "actions[1]")
+		assert.Contains(t, err.Error(), `actions[2] "pur?e" must be one of read, write`)
+		assert.Contains(t, err.Error(), "actions[3]")
+		assert.Contains(t, err.Error(), "unescaped backslash")
+	})
+
+	t.Run("Any action when unrestricted", func(t *testing.T) {
+		policy := valid
+		policy.Actions = []string{"purge"}
+
+		assert.NoError(t, validationUtil.ValidatePolicy(policy))
+	})
+}
+
+func TestValidateResourceClassification(t *testing.T) {
+	validationUtil := util.NewValidationUtil()
+	util.SetClassificationLevels([]string{"public", "internal", "confidential"})
+	defer util.SetClassificationLevels(nil)
+
+	valid := model.Resource{
+		ID:             "r1",
+		Name:           "Quarterly Report",
+		Type:           "DOCUMENT",
+		OrganizationID: "org1",
+		OwnerID:        "u1",
+		Status:         "active",
+		Classification: "confidential",
+	}
+
+	t.Run("Known level", func(t *testing.T) {
+		assert.NoError(t, validationUtil.ValidateResource(valid))
+	})
+
+	t.Run("Unclassified", func(t *testing.T) {
+		resource := valid
+		resource.Classification = ""
+
+		assert.NoError(t, validationUtil.ValidateResource(resource))
+	})
+
+	t.Run("Unknown level", func(t *testing.T) {
+		resource := valid
+		resource.Classification = "top-secret"
+
+		err := validationUtil.ValidateResource(resource)
+
+		assert.True(t, errors.Is(err, echo_errors.ErrInvalidResourceData))
+		assert.Contains(t, err.Error(), `classification "top-secret" must be one of public, internal, confidential`)
+	})
+
+	t.Run("Any level without a taxonomy", func(t *testing.T) {
+		util.SetClassificationLevels(nil)
+		defer util.SetClassificationLevels([]string{"public", "internal", "confidential"})
+		resource := valid
+		resource.Classification = "top-secret"
+
+		assert.NoError(t, validationUtil.ValidateResource(resource))
+	})
+}
+
+func TestValidateOrganizationDetails(t *testing.T) {
+	validationUtil := util.NewValidationUtil()
+
+	valid := model.Organization{
+		ID:           "acme",
+		Name:         "Acme",
+		Description:  "Makes everything",
+		Status:       model.OrgStatusActive,
+		ContactEmail: "it@acme.example",
+		ContactPhone: "+1 (555) 010-0199",
+		ExternalIDs:  map[string]string{"salesforce": "0015g00000XyZ"},
+	}
+
+	t.Run("Complete details", func(t *testing.T) {
+		assert.NoError(t, validationUtil.ValidateOrganization(valid))
+	})
+
+	t.Run("Name only", func(t *testing.T) {
+		assert.NoError(t, validationUtil.ValidateOrganization(model.Organization{ID: "acme", Name: "Acme"}))
+	})
+
+	invalid := map[string]func(org *model.Organization){
+		`status "closed" must be one of active, suspended, archived`:   func(org *model.Organization) { org.Status = "closed" },
+		`contact_email "it at acme" is not an email address`:           func(org *model.Organization) { org.ContactEmail = "it at acme" },
+		`contact_email "IT <it@acme.example>" is not an email address`: func(org *model.Organization) { org.ContactEmail = "IT <it@acme.example>" },
+		`contact_phone "call us" is not a phone number`:                func(org *model.Organization) { org.ContactPhone = "call us" },
+		`external_ids.erp cannot be empty`:                             func(org *model.Organization) { org.ExternalIDs = map[string]string{"erp": ""} },
+	}
+	for reason, change := range invalid {
+		t.Run(reason, func(t *testing.T) {
+			org := valid
+			change(&org)
+
+			err := validationUtil.ValidateOrganization(org)
+
+			assert.True(t, errors.Is(err, echo_errors.ErrInvalidOrganizationData))
+			assert.Contains(t, err.Error(), reason)
+		})
+	}
+}